@@ -48,8 +48,29 @@ type WorkspaceSpec struct {
 	// AutoEnroll capability definition. If omitted, no autoenroll features will be added.
 	AutoEnroll WorkspaceAutoenroll `json:"autoEnroll,omitempty"`
 
+	// The invitations allowing tenants to enroll themselves in the Workspace with the user role,
+	// regardless of the AutoEnroll capability, by redeeming the corresponding token.
+	Invitations []WorkspaceInvitation `json:"invitations,omitempty"`
+
+	// The rules allowing tenants to enroll themselves in the Workspace with the user role,
+	// regardless of the AutoEnroll capability, depending on the domain of their email address.
+	EmailDomainRules []WorkspaceEmailDomainRule `json:"emailDomainRules,omitempty"`
+
 	// The amount of resources associated with this workspace, and inherited by enrolled tenants.
 	Quota WorkspaceResourceQuota `json:"quota"`
+
+	// The optional cap on the total amount of resources consumed by the instances of all tenants
+	// referring to the templates of this workspace, enforced when instances are created or started.
+	// If omitted, the consumption is limited only by the quota of each tenant.
+	AggregateQuota *WorkspaceAggregateQuota `json:"aggregateQuota,omitempty"`
+
+	// The optional period during which the Workspace is active (e.g. the duration of a course).
+	// New instances can be created only within the active period. If omitted, the Workspace is always active.
+	ActivePeriod *WorkspaceActivePeriod `json:"activePeriod,omitempty"`
+
+	// The actions performed once the end of the active period is reached, in addition to
+	// stopping all instances and marking the Workspace as archived.
+	EndOfLifePolicy WorkspaceEndOfLifePolicy `json:"endOfLifePolicy,omitempty"`
 }
 
 // WorkspaceStatus reflects the most recently observed status of the Workspace.
@@ -69,6 +90,66 @@ type WorkspaceStatus struct {
 	// occurred. In case of errors, the other status fields provide additional
 	// information about which problem occurred.
 	Ready bool `json:"ready,omitempty"`
+
+	// The resources currently consumed by the instances referring to the templates of this workspace.
+	// It is reported only in case the aggregate quota is configured.
+	Usage *WorkspaceResourceUsage `json:"usage,omitempty"`
+
+	// The number of tenants enrolled through each invitation, which is recorded once
+	// the enrollment has been persisted, to enforce the maximum number of uses.
+	// +listType=map
+	// +listMapKey=name
+	Invitations []WorkspaceInvitationStatus `json:"invitations,omitempty"`
+
+	// Whether the Workspace has been archived, since the end of its active period has been reached.
+	// New instances cannot be created in an archived Workspace.
+	Archived bool `json:"archived,omitempty"`
+
+	// The conditions describing the most recently observed state of the Workspace,
+	// each one associated with a machine-readable reason.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// WorkspaceInvitation defines an invitation token allowing tenants to enroll themselves in a Workspace.
+type WorkspaceInvitation struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// The name identifying the invitation.
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Pattern="^[a-f0-9]{64}$"
+
+	// The hex-encoded SHA-256 digest of the invitation token. The token itself is not stored,
+	// as the Workspace can be read by all the tenants.
+	TokenHash string `json:"tokenHash"`
+
+	// The time after which the invitation can no longer be redeemed. If omitted, the invitation never expires.
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+
+	// The maximum number of tenants which can be enrolled through the invitation. If omitted, the number is unlimited.
+	MaxUses *int32 `json:"maxUses,omitempty"`
+}
+
+// WorkspaceInvitationStatus reports the usage of an invitation of a Workspace.
+type WorkspaceInvitationStatus struct {
+	// The name identifying the invitation.
+	Name string `json:"name"`
+
+	// The number of tenants enrolled through the invitation.
+	Uses int32 `json:"uses"`
+}
+
+// WorkspaceEmailDomainRule defines a rule allowing the tenants with a given email domain to enroll themselves in a Workspace.
+type WorkspaceEmailDomainRule struct {
+	// +kubebuilder:validation:Pattern="^@?[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*$"
+
+	// The email domain, optionally prefixed by the @ character (e.g. @studenti.polito.it).
+	// Only exact matches are considered, excluding subdomains.
+	Domain string `json:"domain"`
 }
 
 // WorkspaceResourceQuota defines the resource quota for each Workspace.
@@ -84,12 +165,65 @@ type WorkspaceResourceQuota struct {
 	Instances int64 `json:"instances"`
 }
 
+// WorkspaceAggregateQuota defines the cap on the resources consumed by all the instances of a Workspace.
+// Each resource is limited only if the corresponding field is specified.
+type WorkspaceAggregateQuota struct {
+	// The maximum amount of CPU cores which can be consumed by the running instances.
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+
+	// The maximum amount of RAM memory which can be consumed by the running instances.
+	Memory *resource.Quantity `json:"memory,omitempty"`
+
+	// The maximum amount of storage which can be consumed by the persistent disks of the instances.
+	Storage *resource.Quantity `json:"storage,omitempty"`
+
+	// +kubebuilder:validation:Minimum:=0
+	// The maximum number of instances (either running or not).
+	Instances *int64 `json:"instances,omitempty"`
+}
+
+// WorkspaceActivePeriod defines the period during which a Workspace is active.
+type WorkspaceActivePeriod struct {
+	// The time the Workspace becomes active. If omitted, the Workspace is active since its creation.
+	Start *metav1.Time `json:"start,omitempty"`
+
+	// The time the Workspace reaches its end of life, and it is archived. If omitted, the Workspace is never archived.
+	End *metav1.Time `json:"end,omitempty"`
+}
+
+// WorkspaceEndOfLifePolicy defines the optional actions performed once a Workspace reaches its end of life.
+type WorkspaceEndOfLifePolicy struct {
+	// Whether to snapshot the persistent instances of the Workspace, once they have been stopped.
+	// The snapshots are created in the namespaces of the tenants owning the instances.
+	SnapshotPersistentInstances bool `json:"snapshotPersistentInstances,omitempty"`
+
+	// Whether to preserve the enrollments of the tenants which are not managers of the Workspace.
+	// If false, they are removed from the list of workspaces of the tenants.
+	PreserveUserEnrollments bool `json:"preserveUserEnrollments,omitempty"`
+}
+
+// WorkspaceResourceUsage defines the resources consumed by the instances of a Workspace.
+type WorkspaceResourceUsage struct {
+	// The amount of CPU cores consumed by the running instances.
+	CPU resource.Quantity `json:"cpu"`
+
+	// The amount of RAM memory consumed by the running instances.
+	Memory resource.Quantity `json:"memory"`
+
+	// The amount of storage consumed by the persistent disks of the instances.
+	Storage resource.Quantity `json:"storage"`
+
+	// The number of instances (either running or not).
+	Instances int64 `json:"instances"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope="Cluster"
 // +kubebuilder:printcolumn:name="Pretty Name",type=string,JSONPath=`.spec.prettyName`
 // +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.status.namespace.name`
 // +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.ready`
+// +kubebuilder:printcolumn:name="Archived",type=string,JSONPath=`.status.archived`,priority=10
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Workspace describes a workspace in CrownLabs.
@@ -20,6 +20,7 @@ package v1alpha1
 
 import (
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -181,6 +182,133 @@ func (in *Workspace) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceActivePeriod) DeepCopyInto(out *WorkspaceActivePeriod) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceActivePeriod.
+func (in *WorkspaceActivePeriod) DeepCopy() *WorkspaceActivePeriod {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceActivePeriod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceAggregateQuota) DeepCopyInto(out *WorkspaceAggregateQuota) {
+	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceAggregateQuota.
+func (in *WorkspaceAggregateQuota) DeepCopy() *WorkspaceAggregateQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceAggregateQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceEmailDomainRule) DeepCopyInto(out *WorkspaceEmailDomainRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceEmailDomainRule.
+func (in *WorkspaceEmailDomainRule) DeepCopy() *WorkspaceEmailDomainRule {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceEmailDomainRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceEndOfLifePolicy) DeepCopyInto(out *WorkspaceEndOfLifePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceEndOfLifePolicy.
+func (in *WorkspaceEndOfLifePolicy) DeepCopy() *WorkspaceEndOfLifePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceEndOfLifePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceInvitation) DeepCopyInto(out *WorkspaceInvitation) {
+	*out = *in
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.MaxUses != nil {
+		in, out := &in.MaxUses, &out.MaxUses
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceInvitation.
+func (in *WorkspaceInvitation) DeepCopy() *WorkspaceInvitation {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceInvitation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceInvitationStatus) DeepCopyInto(out *WorkspaceInvitationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceInvitationStatus.
+func (in *WorkspaceInvitationStatus) DeepCopy() *WorkspaceInvitationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceInvitationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceList) DeepCopyInto(out *WorkspaceList) {
 	*out = *in
@@ -230,10 +358,51 @@ func (in *WorkspaceResourceQuota) DeepCopy() *WorkspaceResourceQuota {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceResourceUsage) DeepCopyInto(out *WorkspaceResourceUsage) {
+	*out = *in
+	out.CPU = in.CPU.DeepCopy()
+	out.Memory = in.Memory.DeepCopy()
+	out.Storage = in.Storage.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceResourceUsage.
+func (in *WorkspaceResourceUsage) DeepCopy() *WorkspaceResourceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceResourceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceSpec) DeepCopyInto(out *WorkspaceSpec) {
 	*out = *in
+	if in.Invitations != nil {
+		in, out := &in.Invitations, &out.Invitations
+		*out = make([]WorkspaceInvitation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EmailDomainRules != nil {
+		in, out := &in.EmailDomainRules, &out.EmailDomainRules
+		*out = make([]WorkspaceEmailDomainRule, len(*in))
+		copy(*out, *in)
+	}
 	in.Quota.DeepCopyInto(&out.Quota)
+	if in.AggregateQuota != nil {
+		in, out := &in.AggregateQuota, &out.AggregateQuota
+		*out = new(WorkspaceAggregateQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActivePeriod != nil {
+		in, out := &in.ActivePeriod, &out.ActivePeriod
+		*out = new(WorkspaceActivePeriod)
+		(*in).DeepCopyInto(*out)
+	}
+	out.EndOfLifePolicy = in.EndOfLifePolicy
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSpec.
@@ -257,6 +426,23 @@ func (in *WorkspaceStatus) DeepCopyInto(out *WorkspaceStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(WorkspaceResourceUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Invitations != nil {
+		in, out := &in.Invitations, &out.Invitations
+		*out = make([]WorkspaceInvitationStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceStatus.
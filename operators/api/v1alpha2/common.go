@@ -63,3 +63,6 @@ const TnOperatorFinalizerName = "crownlabs.polito.it/tenant-operator"
 
 // ShVolCtrlFinalizerName is the name of the finalizer for SharedVolume's PVC protection.
 const ShVolCtrlFinalizerName = "crownlabs.polito.it/shvolctrl-volume-protection"
+
+// InstanceSnapshotImageFinalizerName is the name of the finalizer for the deletion of the image produced by an InstanceSnapshot.
+const InstanceSnapshotImageFinalizerName = "crownlabs.polito.it/instancesnapshot-image-cleanup"
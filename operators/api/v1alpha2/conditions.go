@@ -0,0 +1,128 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+// ConditionType is an enumeration of the types of the conditions reported
+// in the status of the CrownLabs resources.
+type ConditionType string
+
+const (
+	// ConditionReady -> the resource is fully operational. The reason of the condition
+	// summarizes the main problem preventing the resource from being ready, if any.
+	ConditionReady ConditionType = "Ready"
+	// ConditionProvisioned -> the subresources associated with the resource have been enforced.
+	ConditionProvisioned ConditionType = "Provisioned"
+	// ConditionKeycloakSynchronized -> the resource has been synchronized with Keycloak.
+	ConditionKeycloakSynchronized ConditionType = "KeycloakSynchronized"
+	// ConditionNamespaceReady -> the namespace associated with the resource has been enforced.
+	ConditionNamespaceReady ConditionType = "NamespaceReady"
+	// ConditionWorkspacesSubscribed -> the tenant has been subscribed to all the requested workspaces.
+	ConditionWorkspacesSubscribed ConditionType = "WorkspacesSubscribed"
+	// ConditionValidated -> the request conveyed by the resource has been validated.
+	ConditionValidated ConditionType = "Validated"
+	// ConditionImagesAvailable -> the images referenced by the template are available in the registry.
+	ConditionImagesAvailable ConditionType = "ImagesAvailable"
+	// ConditionSharedVolumesReady -> the shared volumes referenced by the template exist and are ready.
+	ConditionSharedVolumesReady ConditionType = "SharedVolumesReady"
+	// ConditionTemplateCreated -> the template requested from the snapshot has been created.
+	ConditionTemplateCreated ConditionType = "TemplateCreated"
+	// ConditionArchived -> the workspace has been archived, since the end of its active period has been reached.
+	ConditionArchived ConditionType = "Archived"
+)
+
+// ConditionReason is an enumeration of the machine-readable reasons associated
+// with the conditions reported in the status of the CrownLabs resources.
+type ConditionReason string
+
+const (
+	// ReasonAvailable -> the resource is ready to be used.
+	ReasonAvailable ConditionReason = "Available"
+	// ReasonSucceeded -> the operation associated with the condition succeeded.
+	ReasonSucceeded ConditionReason = "Succeeded"
+	// ReasonEnforcementFailed -> an error occurred while enforcing the resources associated with the condition.
+	ReasonEnforcementFailed ConditionReason = "EnforcementFailed"
+	// ReasonQuotaExceeded -> the resources could not be created because the resource quota is exceeded.
+	ReasonQuotaExceeded ConditionReason = "QuotaExceeded"
+	// ReasonKeycloakUnreachable -> the interaction with Keycloak failed.
+	ReasonKeycloakUnreachable ConditionReason = "KeycloakUnreachable"
+	// ReasonUserNotVerified -> the tenant has not yet verified his/her email address.
+	ReasonUserNotVerified ConditionReason = "UserNotVerified"
+	// ReasonWorkspaceSubscriptionFailed -> at least one of the requested workspaces does not exist or does not admit the requested role.
+	ReasonWorkspaceSubscriptionFailed ConditionReason = "WorkspaceSubscriptionFailed"
+	// ReasonNamespaceInactive -> the personal namespace has been deleted since the tenant has been inactive for too long.
+	ReasonNamespaceInactive ConditionReason = "NamespaceInactive"
+	// ReasonTemplateNotFound -> the referenced template does not exist.
+	ReasonTemplateNotFound ConditionReason = "TemplateNotFound"
+	// ReasonTenantNotFound -> the referenced tenant does not exist.
+	ReasonTenantNotFound ConditionReason = "TenantNotFound"
+	// ReasonInstanceNotFound -> the referenced instance does not exist.
+	ReasonInstanceNotFound ConditionReason = "InstanceNotFound"
+	// ReasonEnvironmentNotFound -> the referenced environment does not exist.
+	ReasonEnvironmentNotFound ConditionReason = "EnvironmentNotFound"
+	// ReasonEnvironmentNotSupported -> the operation is not supported by the type of the referenced environment.
+	ReasonEnvironmentNotSupported ConditionReason = "EnvironmentNotSupported"
+	// ReasonInstanceRunning -> the operation cannot be performed while the referenced instance is running.
+	ReasonInstanceRunning ConditionReason = "InstanceRunning"
+	// ReasonStarting -> the environments are being created or started.
+	ReasonStarting ConditionReason = "Starting"
+	// ReasonStopping -> the environments are being stopped.
+	ReasonStopping ConditionReason = "Stopping"
+	// ReasonStopped -> the environments are currently shut down.
+	ReasonStopped ConditionReason = "Stopped"
+	// ReasonEnvironmentFailed -> at least one of the environments has failed and cannot be restarted.
+	ReasonEnvironmentFailed ConditionReason = "EnvironmentFailed"
+	// ReasonPVCPending -> the persistent volume claim has not yet been bound.
+	ReasonPVCPending ConditionReason = "PVCPending"
+	// ReasonProvisioning -> the storage is being provisioned.
+	ReasonProvisioning ConditionReason = "Provisioning"
+	// ReasonSizeDecreaseForbidden -> the requested size is smaller than the current one.
+	ReasonSizeDecreaseForbidden ConditionReason = "SizeDecreaseForbidden"
+	// ReasonInvalidVolumeSource -> the bound persistent volume does not expose the expected NFS parameters.
+	ReasonInvalidVolumeSource ConditionReason = "InvalidVolumeSource"
+	// ReasonDeleting -> the resource is being deleted.
+	ReasonDeleting ConditionReason = "Deleting"
+	// ReasonValidationFailed -> the specification of the resource is not valid.
+	ReasonValidationFailed ConditionReason = "ValidationFailed"
+	// ReasonImageNotFound -> at least one of the referenced images is not listed in the ImageLists.
+	ReasonImageNotFound ConditionReason = "ImageNotFound"
+	// ReasonImageNotTracked -> at least one of the referenced images is not hosted on a registry tracked by the ImageLists.
+	ReasonImageNotTracked ConditionReason = "ImageNotTracked"
+	// ReasonSharedVolumeNotFound -> at least one of the referenced shared volumes does not exist.
+	ReasonSharedVolumeNotFound ConditionReason = "SharedVolumeNotFound"
+	// ReasonSharedVolumeNotReady -> at least one of the referenced shared volumes is not ready.
+	ReasonSharedVolumeNotReady ConditionReason = "SharedVolumeNotReady"
+	// ReasonTemplateConflict -> a template with the same name, not created from a snapshot, already exists.
+	ReasonTemplateConflict ConditionReason = "TemplateConflict"
+	// ReasonSnapshotNotFound -> the referenced snapshot does not exist.
+	ReasonSnapshotNotFound ConditionReason = "SnapshotNotFound"
+	// ReasonSnapshotNotReady -> the referenced snapshot has not yet been taken.
+	ReasonSnapshotNotReady ConditionReason = "SnapshotNotReady"
+	// ReasonSnapshotFailed -> the storage system failed taking the snapshot.
+	ReasonSnapshotFailed ConditionReason = "SnapshotFailed"
+	// ReasonSharedVolumeInUse -> the target shared volume is mounted by running instances.
+	ReasonSharedVolumeInUse ConditionReason = "SharedVolumeInUse"
+	// ReasonInsufficientSize -> the target volume is smaller than the content to be restored.
+	ReasonInsufficientSize ConditionReason = "InsufficientSize"
+	// ReasonJobPending -> the job performing the operation has not yet been started.
+	ReasonJobPending ConditionReason = "JobPending"
+	// ReasonJobRunning -> the job performing the operation is running.
+	ReasonJobRunning ConditionReason = "JobRunning"
+	// ReasonJobFailed -> the job performing the operation failed.
+	ReasonJobFailed ConditionReason = "JobFailed"
+	// ReasonActivePeriodEnded -> the end of the active period of the workspace has been reached.
+	ReasonActivePeriodEnded ConditionReason = "ActivePeriodEnded"
+	// ReasonWithinActivePeriod -> the end of the active period of the workspace has not yet been reached.
+	ReasonWithinActivePeriod ConditionReason = "WithinActivePeriod"
+)
@@ -93,9 +93,47 @@ type InstanceSpec struct {
 
 	// Optional urls for advanced integration features.
 	CustomizationUrls *InstanceCustomizationUrls `json:"customizationUrls,omitempty"`
+
+	// +kubebuilder:validation:Pattern="^(never|[0-9]+[mhd])$"
+
+	// The lifetime of the Instance, overriding the one configured in the Template.
+	// It is capped to the maximum value allowed by the Template, and measured
+	// starting from the creation of the Instance.
+	DeleteAfter string `json:"deleteAfter,omitempty"`
+
+	// The time windows during which the Instance is automatically started and stopped.
+	// If set, it overrides the schedule possibly configured in the Template.
+	Schedule *InstanceSchedule `json:"schedule,omitempty"`
+}
+
+// InstanceSchedule defines the time windows during which an Instance is expected to be running.
+// The Instance is started and stopped at the boundaries of each window, while it can still be
+// manually started or stopped in the meanwhile.
+type InstanceSchedule struct {
+	// +kubebuilder:validation:MinItems=1
+
+	// The list of windows, each one identifying when the Instance is started and stopped.
+	Windows []InstanceScheduleWindow `json:"windows"`
+
+	// +kubebuilder:default="UTC"
+
+	// The IANA time zone (e.g. Europe/Rome) the cron expressions are evaluated in.
+	Timezone string `json:"timezone,omitempty"`
 }
 
-// InstanceAutomationStatus reflects the status of the instance's automation (termination and submission).
+// InstanceScheduleWindow defines a single start/stop window, by means of standard cron expressions
+// (minute, hour, day of month, month and day of week), e.g. "30 8 * * mon-fri".
+type InstanceScheduleWindow struct {
+	// The cron expression identifying when the Instance is started.
+	// If not specified, the Instance is never automatically started.
+	Start string `json:"start,omitempty"`
+
+	// The cron expression identifying when the Instance is stopped.
+	// If not specified, the Instance is never automatically stopped.
+	Stop string `json:"stop,omitempty"`
+}
+
+// InstanceAutomationStatus reflects the status of the instance's automation (termination, submission, scheduling, expiration and idle detection).
 type InstanceAutomationStatus struct {
 	// The last time the Instance desired status was checked.
 	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
@@ -105,25 +143,78 @@ type InstanceAutomationStatus struct {
 
 	// The time the Instance content submission has been completed.
 	SubmissionTime metav1.Time `json:"submissionTime,omitempty"`
+
+	// The time the Instance will be automatically started, according to its schedule.
+	NextStartTime metav1.Time `json:"nextStartTime,omitempty"`
+
+	// The time the Instance will be automatically stopped, according to its schedule.
+	NextStopTime metav1.Time `json:"nextStopTime,omitempty"`
+
+	// The time the lifetime of the Instance expires, after which it is
+	// automatically deleted (or stopped, depending on the Template policy).
+	ExpirationTime metav1.Time `json:"expirationTime,omitempty"`
+
+	// The remaining lifetime of the Instance, as of the last check.
+	RemainingLifetime string `json:"remainingLifetime,omitempty"`
+
+	// The last time a warning about the upcoming expiration of the Instance was issued.
+	ExpirationWarningTime metav1.Time `json:"expirationWarningTime,omitempty"`
+
+	// The last time the Instance was observed being used, according to the
+	// signals leveraged to detect idle Instances.
+	LastActivityTime metav1.Time `json:"lastActivityTime,omitempty"`
+
+	// The reason why the Instance was last automatically stopped due to inactivity.
+	StopReason string `json:"stopReason,omitempty"`
+}
+
+// InstanceEnvironmentStatus reflects the most recently observed status of
+// one of the environments composing the Instance.
+type InstanceEnvironmentStatus struct {
+	// The name of the environment, as specified in the Template.
+	Name string `json:"name"`
+
+	// The current phase of the environment (e.g. VM or container).
+	Phase EnvironmentPhase `json:"phase,omitempty"`
+
+	// The URL where it is possible to access the remote desktop of the
+	// environment (in case of graphical environments).
+	URL string `json:"url,omitempty"`
+
+	// The internal IP address associated with the environment.
+	IP string `json:"ip,omitempty"`
+
+	// The public SSH host key of the environment (in authorized_keys format), installed
+	// through cloud-init and verified by the bastion proxy when connecting to it.
+	SSHHostKey string `json:"sshHostKey,omitempty"`
 }
 
 // InstanceStatus reflects the most recently observed status of the Instance.
 type InstanceStatus struct {
-	// The current status Instance, with reference to the associated environment
-	// (e.g. VM). This conveys which resource is being created, as well as
-	// whether the associated VM is being scheduled, is running or ready to
-	// accept incoming connections.
+	// The current status Instance, with reference to the associated environments
+	// (e.g. VMs). This conveys which resource is being created, as well as
+	// whether the associated VMs are being scheduled, are running or ready to
+	// accept incoming connections. In case of multiple environments, it
+	// aggregates the phases of the single environments, reporting the least
+	// advanced one.
 	Phase EnvironmentPhase `json:"phase,omitempty"`
 
 	// The URL where it is possible to access the remote desktop of the instance
-	// (in case of graphical environments)
+	// (in case of graphical environments). In case of multiple environments,
+	// it refers to the first one.
 	URL string `json:"url,omitempty"`
 
 	// The internal IP address associated with the remote environment, which can
 	// be used to access it through the SSH protocol (leveraging the SSH bastion
-	// in case it is not contacted from another CrownLabs Instance).
+	// in case it is not contacted from another CrownLabs Instance). In case of
+	// multiple environments, it refers to the first one.
 	IP string `json:"ip,omitempty"`
 
+	// The status of each of the environments composing the Instance.
+	// +listType=map
+	// +listMapKey=name
+	Environments []InstanceEnvironmentStatus `json:"environments,omitempty"`
+
 	// The amount of time the Instance required to become ready for the first time
 	// upon creation.
 	InitialReadyTime string `json:"initialReadyTime,omitempty"`
@@ -136,6 +227,12 @@ type InstanceStatus struct {
 
 	// The actual nodeSelector assigned to the Instance.
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// The conditions describing the most recently observed state of the Instance,
+	// each one associated with a machine-readable reason.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
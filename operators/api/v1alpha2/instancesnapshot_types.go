@@ -43,7 +43,7 @@ type InstanceSnapshotSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Instance is the reference to the persistent VM instance to be snapshotted.
+	// Instance is the reference to the persistent VM or container instance to be snapshotted.
 	// The instance should not be running, otherwise it won't be possible to
 	// steal the volume and extract its content.
 	Instance GenericRef `json:"instanceRef"`
@@ -56,12 +56,60 @@ type InstanceSnapshotSpec struct {
 
 	// ImageName is the name of the image to pushed in the docker registry.
 	ImageName string `json:"imageName"`
+
+	// CreateTemplate, if specified, requests the creation (or the update) of a Template referring to
+	// the produced image, once the snapshot has been completed. The environment of the Template is
+	// derived from the snapshotted one, copying its resources, mode and shared volume mounts.
+	CreateTemplate *SnapshotTemplate `json:"createTemplate,omitempty"`
+}
+
+// SnapshotTemplate describes the Template to be created from a completed InstanceSnapshot.
+type SnapshotTemplate struct {
+	// WorkspaceRef is the reference to the Workspace the Template belongs to.
+	// The Template is created in the namespace associated with the Workspace.
+	WorkspaceRef GenericRef `json:"workspace"`
+
+	// Name is the name of the Template. If not specified, it defaults to the name of the InstanceSnapshot.
+	// An existing Template is updated only if it has been previously created from an InstanceSnapshot.
+	Name string `json:"name,omitempty"`
+
+	// +kubebuilder:validation:MinLength=1
+
+	// PrettyName is the human-readable name of the Template.
+	PrettyName string `json:"prettyName"`
+
+	// Description is the description of the Template. If not specified, a default one referring to the snapshot is generated.
+	Description string `json:"description,omitempty"`
 }
 
 // InstanceSnapshotStatus defines the observed state of InstanceSnapshot.
 type InstanceSnapshotStatus struct {
 	// Phase represents the current state of the Instance Snapshot.
 	Phase SnapshotStatus `json:"phase"`
+
+	// ImageRef is the full reference (including the registry and the tag) of the image produced by the snapshot.
+	ImageRef string `json:"imageRef,omitempty"`
+
+	// ImageDigest is the digest of the image pushed to the registry, once the snapshot has been completed.
+	ImageDigest string `json:"imageDigest,omitempty"`
+
+	// StartTime is the time the job in charge of creating the snapshot has been started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the creation of the snapshot terminated (either successfully or not).
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// FailureReason is the human-readable explanation of the reason why the creation of the snapshot failed.
+	FailureReason string `json:"failureReason,omitempty"`
+
+	// TemplateRef is the reference to the Template created from the snapshot, if requested.
+	TemplateRef *GenericRef `json:"templateRef,omitempty"`
+
+	// The conditions describing the most recently observed state of the Instance Snapshot,
+	// each one associated with a machine-readable reason.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
@@ -69,6 +117,7 @@ type InstanceSnapshotStatus struct {
 // +kubebuilder:resource:shortName="isnap"
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="ImageName",type=string,JSONPath=`.spec.imageName`
+// +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.status.imageRef`,priority=10
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // InstanceSnapshot is the Schema for the instancesnapshots API.
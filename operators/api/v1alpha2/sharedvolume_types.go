@@ -51,6 +51,32 @@ type SharedVolumeSpec struct {
 
 	// The size of the volume.
 	Size resource.Quantity `json:"size"`
+
+	// The schedule of the automatic backups of the Shared Volume, if any.
+	BackupSchedule *SharedVolumeBackupSchedule `json:"backupSchedule,omitempty"`
+}
+
+// SharedVolumeBackupSchedule defines when the backups of a Shared Volume are automatically
+// taken, by means of SharedVolumeSnapshots, and how many of them are retained.
+type SharedVolumeBackupSchedule struct {
+	// The standard cron expression (minute, hour, day of month, month and day of week)
+	// identifying when a new backup is taken, e.g. "0 3 * * *".
+	Schedule string `json:"schedule"`
+
+	// +kubebuilder:default="UTC"
+
+	// The IANA time zone (e.g. Europe/Rome) the cron expression is evaluated in.
+	Timezone string `json:"timezone,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=7
+
+	// The maximum number of scheduled backups retained, the oldest ones being deleted once exceeded.
+	KeepLast int `json:"keepLast,omitempty"`
+
+	// The name of the VolumeSnapshotClass used to take the backups.
+	// If not specified, the one configured in the operator (or the default one) is used.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
 }
 
 // SharedVolumeStatus reflects the most recently observed status of the Shared Volume.
@@ -63,6 +89,18 @@ type SharedVolumeStatus struct {
 
 	// The current phase of the lifecycle of the Shared Volume.
 	Phase SharedVolumePhase `json:"phase,omitempty"`
+
+	// The time the last scheduled backup of the Shared Volume has been requested.
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// The time the next scheduled backup of the Shared Volume is going to be requested.
+	NextBackupTime *metav1.Time `json:"nextBackupTime,omitempty"`
+
+	// The conditions describing the most recently observed state of the Shared Volume,
+	// each one associated with a machine-readable reason.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,105 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:Enum="";"Pending";"Restoring";"Completed";"Failed"
+
+// SharedVolumeRestorePhase is an enumeration of the different phases associated with a SharedVolumeRestore.
+type SharedVolumeRestorePhase string
+
+const (
+	// SharedVolumeRestorePhaseUnset -> the shared volume restore phase is unknown.
+	SharedVolumeRestorePhaseUnset SharedVolumeRestorePhase = ""
+	// SharedVolumeRestorePhasePending -> the restore is waiting for the snapshot and the target shared volume to be ready.
+	SharedVolumeRestorePhasePending SharedVolumeRestorePhase = "Pending"
+	// SharedVolumeRestorePhaseRestoring -> the content of the snapshot is being copied into the target shared volume.
+	SharedVolumeRestorePhaseRestoring SharedVolumeRestorePhase = "Restoring"
+	// SharedVolumeRestorePhaseCompleted -> the content of the snapshot has been restored into the target shared volume.
+	SharedVolumeRestorePhaseCompleted SharedVolumeRestorePhase = "Completed"
+	// SharedVolumeRestorePhaseFailed -> the restore process failed.
+	SharedVolumeRestorePhaseFailed SharedVolumeRestorePhase = "Failed"
+)
+
+// SharedVolumeRestoreSpec is the specification of the desired state of the Shared Volume Restore.
+type SharedVolumeRestoreSpec struct {
+	// The reference to the Shared Volume Snapshot to be restored, which must belong
+	// to the same namespace of the Shared Volume Restore (the namespace is ignored).
+	SnapshotRef GenericRef `json:"snapshotRef"`
+
+	// The reference to the Shared Volume the snapshot is restored into, which must belong to the same
+	// namespace of the Shared Volume Restore (the namespace is ignored). If it does not exist, a new Shared
+	// Volume is created with the size of the snapshot; otherwise, its current content is replaced.
+	TargetRef GenericRef `json:"targetRef"`
+
+	// The human-readable name of the Shared Volume, in case it is created by the restore process.
+	// If not specified, the one of the snapshotted Shared Volume is used.
+	PrettyName string `json:"prettyName,omitempty"`
+}
+
+// SharedVolumeRestoreStatus reflects the most recently observed status of the Shared Volume Restore.
+type SharedVolumeRestoreStatus struct {
+	// The current phase of the lifecycle of the Shared Volume Restore.
+	Phase SharedVolumeRestorePhase `json:"phase,omitempty"`
+
+	// Whether the target Shared Volume has been created by the restore process.
+	TargetCreated bool `json:"targetCreated,omitempty"`
+
+	// The time the content of the snapshot started being copied into the target Shared Volume.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// The time the restore process terminated, either successfully or not.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The conditions describing the most recently observed state of the Shared Volume Restore,
+	// each one associated with a machine-readable reason.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName="shvolrestore"
+// +kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.spec.snapshotRef.name`
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetRef.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SharedVolumeRestore describes the request to restore a Shared Volume Snapshot into a new or existing Shared Volume.
+type SharedVolumeRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SharedVolumeRestoreSpec   `json:"spec,omitempty"`
+	Status SharedVolumeRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SharedVolumeRestoreList contains a list of SharedVolumeRestore objects.
+type SharedVolumeRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []SharedVolumeRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedVolumeRestore{}, &SharedVolumeRestoreList{})
+}
@@ -0,0 +1,101 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:Enum="";"Pending";"Processing";"Ready";"Failed"
+
+// SharedVolumeSnapshotPhase is an enumeration of the different phases associated with a SharedVolumeSnapshot.
+type SharedVolumeSnapshotPhase string
+
+const (
+	// SharedVolumeSnapshotPhaseUnset -> the shared volume snapshot phase is unknown.
+	SharedVolumeSnapshotPhaseUnset SharedVolumeSnapshotPhase = ""
+	// SharedVolumeSnapshotPhasePending -> the shared volume snapshot is waiting for the shared volume to be ready.
+	SharedVolumeSnapshotPhasePending SharedVolumeSnapshotPhase = "Pending"
+	// SharedVolumeSnapshotPhaseProcessing -> the CSI volume snapshot is being taken.
+	SharedVolumeSnapshotPhaseProcessing SharedVolumeSnapshotPhase = "Processing"
+	// SharedVolumeSnapshotPhaseReady -> the shared volume snapshot has been taken, and it can be restored.
+	SharedVolumeSnapshotPhaseReady SharedVolumeSnapshotPhase = "Ready"
+	// SharedVolumeSnapshotPhaseFailed -> the shared volume snapshot could not be taken.
+	SharedVolumeSnapshotPhaseFailed SharedVolumeSnapshotPhase = "Failed"
+)
+
+// SharedVolumeSnapshotSpec is the specification of the desired state of the Shared Volume Snapshot.
+type SharedVolumeSnapshotSpec struct {
+	// The reference to the Shared Volume to be snapshotted, which must belong
+	// to the same namespace of the Shared Volume Snapshot (the namespace is ignored).
+	SharedVolumeRef GenericRef `json:"sharedVolumeRef"`
+
+	// The name of the VolumeSnapshotClass used to take the snapshot.
+	// If not specified, the one configured in the operator (or the default one) is used.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// SharedVolumeSnapshotStatus reflects the most recently observed status of the Shared Volume Snapshot.
+type SharedVolumeSnapshotStatus struct {
+	// The current phase of the lifecycle of the Shared Volume Snapshot.
+	Phase SharedVolumeSnapshotPhase `json:"phase,omitempty"`
+
+	// The name of the CSI VolumeSnapshot backing the Shared Volume Snapshot.
+	VolumeSnapshotName string `json:"volumeSnapshotName,omitempty"`
+
+	// The time the snapshot has been taken by the storage system.
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// The minimum size of a volume the snapshot can be restored into.
+	RestoreSize *resource.Quantity `json:"restoreSize,omitempty"`
+
+	// The conditions describing the most recently observed state of the Shared Volume Snapshot,
+	// each one associated with a machine-readable reason.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName="shvolsnap"
+// +kubebuilder:printcolumn:name="Shared Volume",type=string,JSONPath=`.spec.sharedVolumeRef.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Restore Size",type=string,JSONPath=`.status.restoreSize`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SharedVolumeSnapshot describes a point-in-time backup of a Shared Volume, backed by a CSI VolumeSnapshot.
+type SharedVolumeSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SharedVolumeSnapshotSpec   `json:"spec,omitempty"`
+	Status SharedVolumeSnapshotStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SharedVolumeSnapshotList contains a list of SharedVolumeSnapshot objects.
+type SharedVolumeSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []SharedVolumeSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedVolumeSnapshot{}, &SharedVolumeSnapshotList{})
+}
@@ -31,6 +31,12 @@ type EnvironmentType string
 // each mode consists in presets for exposition and deployment.
 type EnvironmentMode string
 
+// +kubebuilder:validation:Enum="Delete";"Stop"
+
+// ExpirationPolicy is an enumeration of the actions performed on the Instances
+// whose lifetime is expired.
+type ExpirationPolicy string
+
 const (
 	// ClassContainer -> the environment is constituted by a Docker container exposing a service through a VNC server.
 	ClassContainer EnvironmentType = "Container"
@@ -47,6 +53,11 @@ const (
 	ModeExam EnvironmentMode = "Exam"
 	// ModeExercise -> Restricted access (no authentication, no mydrive access).
 	ModeExercise EnvironmentMode = "Exercise"
+
+	// ExpirationPolicyDelete -> the expired Instances are deleted.
+	ExpirationPolicyDelete ExpirationPolicy = "Delete"
+	// ExpirationPolicyStop -> the expired Instances are stopped if they refer to persistent environments, and deleted otherwise.
+	ExpirationPolicyStop ExpirationPolicy = "Stop"
 )
 
 // TemplateSpec is the specification of the desired state of the Template.
@@ -71,10 +82,121 @@ type TemplateSpec struct {
 	// or stopped to save resources. If set to "never", the instance will not be
 	// automatically terminated.
 	DeleteAfter string `json:"deleteAfter,omitempty"`
+
+	// +kubebuilder:validation:Pattern="^(never|[0-9]+[mhd])$"
+
+	// The maximum lifetime an Instance referencing the current Template can
+	// request through its own deleteAfter field, possibly extending the default
+	// one. If not specified, Instances are only allowed to shorten their lifetime.
+	MaxDeleteAfter string `json:"maxDeleteAfter,omitempty"`
+
+	// +kubebuilder:default="Delete"
+
+	// The action performed once the lifetime of an Instance is expired. If set to
+	// Stop, the Instances referencing persistent environments are stopped rather
+	// than deleted, to prevent data loss, while the other ones are deleted anyway.
+	ExpirationPolicy ExpirationPolicy `json:"expirationPolicy,omitempty"`
+
+	// +kubebuilder:validation:Pattern="^(never|[0-9]+[mhd])$"
+
+	// The period of inactivity after which an Instance referencing the current
+	// Template is automatically stopped, to save resources. The activity is
+	// inferred from the CPU usage and the remote desktop and SSH connections. If
+	// not specified (or set to "never"), Instances are not automatically stopped.
+	IdleTimeout string `json:"idleTimeout,omitempty"`
+
+	// The isolated network to be shared by the environments of each Instance
+	// referencing the current Template. When configured, every environment is
+	// attached to a dedicated, per-Instance network through a secondary interface,
+	// in addition to the default one. The network is unreachable from any other
+	// Instance, hence it can be freely used to configure routing and firewalls.
+	PrivateNetwork *PrivateNetwork `json:"privateNetwork,omitempty"`
+
+	// The time windows during which the Instances referencing the current Template
+	// are automatically started and stopped, unless overridden by the Instance itself.
+	Schedule *InstanceSchedule `json:"schedule,omitempty"`
 }
 
+// PrivateNetwork describes the characteristics of the isolated network shared by
+// the environments of an Instance.
+type PrivateNetwork struct {
+	// +kubebuilder:validation:Pattern="^([0-9]{1,3}\\.){3}[0-9]{1,3}/[0-9]{1,2}$"
+
+	// The IPv4 subnet (in CIDR notation) the addresses of the secondary interfaces
+	// are automatically assigned from. If not specified, no address is configured,
+	// and the network behaves as a plain L2 segment to be configured by the users.
+	CIDR string `json:"cidr,omitempty"`
+}
+
+// +kubebuilder:validation:Enum="";"Available";"NotFound";"Unknown"
+
+// ImageAvailability is an enumeration of the different states of the image
+// associated with an environment, with reference to the ImageLists.
+type ImageAvailability string
+
+const (
+	// ImageAvailable -> the image is listed in one of the ImageLists.
+	ImageAvailable ImageAvailability = "Available"
+	// ImageNotFound -> the image is not listed in any of the ImageLists.
+	ImageNotFound ImageAvailability = "NotFound"
+	// ImageUnknown -> the availability of the image cannot be determined (e.g. CloudVM images
+	// downloaded from HTTP URLs, or no ImageList is present in the cluster).
+	ImageUnknown ImageAvailability = "Unknown"
+)
+
 // TemplateStatus reflects the most recently observed status of the Template.
 type TemplateStatus struct {
+	// The total number of Instances referencing the Template.
+	TotalInstances int32 `json:"totalInstances"`
+
+	// The number of Instances referencing the Template which are currently running.
+	RunningInstances int32 `json:"runningInstances"`
+
+	// The last time the Template has been used, that is the most recent among
+	// the creation of an Instance referencing it and the last activity detected
+	// on one of the running ones.
+	LastUsedTime *metav1.Time `json:"lastUsedTime,omitempty"`
+
+	// The status of each of the environments composing the Template.
+	// +listType=map
+	// +listMapKey=name
+	Environments []TemplateEnvironmentStatus `json:"environments,omitempty"`
+
+	// The list of problems detected in the specification of the Template,
+	// which would prevent the corresponding Instances from working correctly.
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+
+	// The conditions describing the most recently observed state of the Template,
+	// each one associated with a machine-readable reason.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// TemplateEnvironmentStatus reflects the most recently observed status of
+// one of the environments composing the Template.
+type TemplateEnvironmentStatus struct {
+	// The name of the environment, as specified in the Template.
+	Name string `json:"name"`
+
+	// Whether the image of the environment is available in the registry.
+	Image ImageAvailability `json:"image,omitempty"`
+
+	// The status of the Shared Volumes mounted by the environment.
+	SharedVolumes []TemplateSharedVolumeStatus `json:"sharedVolumes,omitempty"`
+}
+
+// TemplateSharedVolumeStatus reflects the most recently observed status of
+// a Shared Volume mounted by an environment of the Template.
+type TemplateSharedVolumeStatus struct {
+	// The reference of the Shared Volume.
+	SharedVolumeRef GenericRef `json:"sharedVolume"`
+
+	// Whether the Shared Volume exists.
+	Exists bool `json:"exists"`
+
+	// Whether the Shared Volume is ready to be mounted.
+	Ready bool `json:"ready"`
 }
 
 // Environment defines the characteristics of an environment composing the Template.
@@ -181,6 +303,11 @@ type ContainerStartupOpts struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=false
 	EnforceWorkdir bool `json:"enforceWorkdir"`
+	// Whether to initialize the volume mounted on contentPath with the content the image provides at the same path
+	// (e.g., in case of images produced by InstanceSnapshots), which would otherwise be hidden. The copy is performed
+	// only if the volume is empty, hence preserving the modifications in case of persistent environments.
+	// +kubebuilder:validation:Optional
+	InitializeFromImage bool `json:"initializeFromImage,omitempty"`
 }
 
 // SharedVolumeMountInfo contains mount information for a Shared Volume.
@@ -192,9 +319,37 @@ type SharedVolumeMountInfo struct {
 	MountPath string `json:"mountPath"`
 
 	// Whether this Shared Volume should be mounted with R/W or R/O permission.
+	// It is ignored in case of the RoleBased access policy.
 	ReadOnly bool `json:"readOnly"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="Fixed"
+
+	// The policy determining the permissions the Shared Volume is mounted with:
+	// Fixed (i.e., according to the ReadOnly field for every tenant) or RoleBased (i.e.,
+	// R/W for the managers of the workspace, and R/O for all the other tenants).
+	AccessPolicy SharedVolumeAccessPolicy `json:"accessPolicy,omitempty"`
+
+	// Whether each tenant, except for the managers of the workspace, mounts a dedicated
+	// subdirectory of the Shared Volume (named after the tenant itself and created if missing),
+	// rather than the entire volume (e.g., to collect the submissions of the students).
+	// The owned subdirectory is always mounted with R/W permission, unless the Fixed access
+	// policy is selected and ReadOnly is set, while managers mount the entire volume.
+	PerTenantSubdirectory bool `json:"perTenantSubdirectory,omitempty"`
 }
 
+// +kubebuilder:validation:Enum="Fixed";"RoleBased"
+
+// SharedVolumeAccessPolicy is an enumeration of the policies determining the permissions a Shared Volume is mounted with.
+type SharedVolumeAccessPolicy string
+
+const (
+	// SharedVolumeAccessFixed -> the Shared Volume is mounted with the same permissions for every tenant.
+	SharedVolumeAccessFixed SharedVolumeAccessPolicy = "Fixed"
+	// SharedVolumeAccessRoleBased -> the Shared Volume is mounted with R/W permission by the managers of the workspace, and R/O by the other tenants.
+	SharedVolumeAccessRoleBased SharedVolumeAccessPolicy = "RoleBased"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName="tmpl"
@@ -205,6 +360,8 @@ type SharedVolumeMountInfo struct {
 // +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.environmentList[0].environmentType`,priority=10
 // +kubebuilder:printcolumn:name="GUI",type=string,JSONPath=`.spec.environmentList[0].guiEnabled`,priority=10
 // +kubebuilder:printcolumn:name="Persistent",type=string,JSONPath=`.spec.environmentList[0].persistent`,priority=10
+// +kubebuilder:printcolumn:name="Instances",type=integer,JSONPath=`.status.totalInstances`,priority=10
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Template describes the template of a CrownLabs environment to be instantiated.
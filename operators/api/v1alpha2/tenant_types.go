@@ -23,7 +23,7 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
-// +kubebuilder:validation:Enum=manager;user;candidate
+// +kubebuilder:validation:Enum=manager;assistant;user;candidate
 
 // WorkspaceUserRole is an enumeration of the different roles that can be
 // associated to a Tenant in a Workspace.
@@ -33,6 +33,10 @@ const (
 	// Manager -> a Tenant with Manager role can interact with all the environments
 	// (i.e. VMs) in a Workspace, as well as add new Tenants to the Workspace.
 	Manager WorkspaceUserRole = "manager"
+	// Assistant -> a Tenant with Assistant role (e.g. a teaching assistant) can interact with
+	// all the environments in a Workspace and approve candidates, but cannot modify its
+	// templates and shared volumes, nor otherwise change the Tenants enrolled in it.
+	Assistant WorkspaceUserRole = "assistant"
 	// User -> a Tenant with User role can only interact with his/her own
 	// environments (e.g. VMs) within that Workspace.
 	User WorkspaceUserRole = "user"
@@ -52,6 +56,11 @@ type TenantWorkspaceEntry struct {
 
 	// The role of the Tenant in the context of the Workspace.
 	Role WorkspaceUserRole `json:"role"`
+
+	// The invitation token redeemed by the Tenant to enroll itself in the Workspace, if any.
+	// It is verified only when the Tenant enrolls itself, cannot be changed afterwards,
+	// and it is removed by the operator once the enrollment has been processed.
+	InvitationToken string `json:"invitationToken,omitempty"`
 }
 
 // TenantSpec is the specification of the desired state of the Tenant.
@@ -154,6 +163,12 @@ type TenantStatus struct {
 
 	// The amount of resources associated with this Tenant, either inherited from the Workspaces in which he/she is enrolled, or manually overridden.
 	Quota TenantResourceQuota `json:"quota,omitempty"`
+
+	// The conditions describing the most recently observed state of the Tenant,
+	// each one associated with a machine-readable reason.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
@@ -19,6 +19,7 @@
 package v1alpha2
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -144,6 +145,11 @@ func (in *InstanceAutomationStatus) DeepCopyInto(out *InstanceAutomationStatus)
 	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
 	in.TerminationTime.DeepCopyInto(&out.TerminationTime)
 	in.SubmissionTime.DeepCopyInto(&out.SubmissionTime)
+	in.NextStartTime.DeepCopyInto(&out.NextStartTime)
+	in.NextStopTime.DeepCopyInto(&out.NextStopTime)
+	in.ExpirationTime.DeepCopyInto(&out.ExpirationTime)
+	in.ExpirationWarningTime.DeepCopyInto(&out.ExpirationWarningTime)
+	in.LastActivityTime.DeepCopyInto(&out.LastActivityTime)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceAutomationStatus.
@@ -171,6 +177,21 @@ func (in *InstanceCustomizationUrls) DeepCopy() *InstanceCustomizationUrls {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceEnvironmentStatus) DeepCopyInto(out *InstanceEnvironmentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceEnvironmentStatus.
+func (in *InstanceEnvironmentStatus) DeepCopy() *InstanceEnvironmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceEnvironmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceList) DeepCopyInto(out *InstanceList) {
 	*out = *in
@@ -203,13 +224,48 @@ func (in *InstanceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceSchedule) DeepCopyInto(out *InstanceSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]InstanceScheduleWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSchedule.
+func (in *InstanceSchedule) DeepCopy() *InstanceSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceScheduleWindow) DeepCopyInto(out *InstanceScheduleWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceScheduleWindow.
+func (in *InstanceScheduleWindow) DeepCopy() *InstanceScheduleWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceScheduleWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceSnapshot) DeepCopyInto(out *InstanceSnapshot) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSnapshot.
@@ -267,6 +323,11 @@ func (in *InstanceSnapshotSpec) DeepCopyInto(out *InstanceSnapshotSpec) {
 	*out = *in
 	out.Instance = in.Instance
 	out.Environment = in.Environment
+	if in.CreateTemplate != nil {
+		in, out := &in.CreateTemplate, &out.CreateTemplate
+		*out = new(SnapshotTemplate)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSnapshotSpec.
@@ -282,6 +343,26 @@ func (in *InstanceSnapshotSpec) DeepCopy() *InstanceSnapshotSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceSnapshotStatus) DeepCopyInto(out *InstanceSnapshotStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(GenericRef)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSnapshotStatus.
@@ -311,6 +392,11 @@ func (in *InstanceSpec) DeepCopyInto(out *InstanceSpec) {
 		*out = new(InstanceCustomizationUrls)
 		**out = **in
 	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(InstanceSchedule)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSpec.
@@ -326,6 +412,11 @@ func (in *InstanceSpec) DeepCopy() *InstanceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceStatus) DeepCopyInto(out *InstanceStatus) {
 	*out = *in
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make([]InstanceEnvironmentStatus, len(*in))
+		copy(*out, *in)
+	}
 	in.Automation.DeepCopyInto(&out.Automation)
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
@@ -334,6 +425,13 @@ func (in *InstanceStatus) DeepCopyInto(out *InstanceStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceStatus.
@@ -377,13 +475,28 @@ func (in *NameCreated) DeepCopy() *NameCreated {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivateNetwork) DeepCopyInto(out *PrivateNetwork) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivateNetwork.
+func (in *PrivateNetwork) DeepCopy() *PrivateNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivateNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SharedVolume) DeepCopyInto(out *SharedVolume) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolume.
@@ -404,6 +517,21 @@ func (in *SharedVolume) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeBackupSchedule) DeepCopyInto(out *SharedVolumeBackupSchedule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeBackupSchedule.
+func (in *SharedVolumeBackupSchedule) DeepCopy() *SharedVolumeBackupSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeBackupSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SharedVolumeList) DeepCopyInto(out *SharedVolumeList) {
 	*out = *in
@@ -452,10 +580,227 @@ func (in *SharedVolumeMountInfo) DeepCopy() *SharedVolumeMountInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeRestore) DeepCopyInto(out *SharedVolumeRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeRestore.
+func (in *SharedVolumeRestore) DeepCopy() *SharedVolumeRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedVolumeRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeRestoreList) DeepCopyInto(out *SharedVolumeRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SharedVolumeRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeRestoreList.
+func (in *SharedVolumeRestoreList) DeepCopy() *SharedVolumeRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedVolumeRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeRestoreSpec) DeepCopyInto(out *SharedVolumeRestoreSpec) {
+	*out = *in
+	out.SnapshotRef = in.SnapshotRef
+	out.TargetRef = in.TargetRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeRestoreSpec.
+func (in *SharedVolumeRestoreSpec) DeepCopy() *SharedVolumeRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeRestoreStatus) DeepCopyInto(out *SharedVolumeRestoreStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeRestoreStatus.
+func (in *SharedVolumeRestoreStatus) DeepCopy() *SharedVolumeRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeSnapshot) DeepCopyInto(out *SharedVolumeSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeSnapshot.
+func (in *SharedVolumeSnapshot) DeepCopy() *SharedVolumeSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedVolumeSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeSnapshotList) DeepCopyInto(out *SharedVolumeSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SharedVolumeSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeSnapshotList.
+func (in *SharedVolumeSnapshotList) DeepCopy() *SharedVolumeSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedVolumeSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeSnapshotSpec) DeepCopyInto(out *SharedVolumeSnapshotSpec) {
+	*out = *in
+	out.SharedVolumeRef = in.SharedVolumeRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeSnapshotSpec.
+func (in *SharedVolumeSnapshotSpec) DeepCopy() *SharedVolumeSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeSnapshotStatus) DeepCopyInto(out *SharedVolumeSnapshotStatus) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RestoreSize != nil {
+		in, out := &in.RestoreSize, &out.RestoreSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeSnapshotStatus.
+func (in *SharedVolumeSnapshotStatus) DeepCopy() *SharedVolumeSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SharedVolumeSpec) DeepCopyInto(out *SharedVolumeSpec) {
 	*out = *in
 	out.Size = in.Size.DeepCopy()
+	if in.BackupSchedule != nil {
+		in, out := &in.BackupSchedule, &out.BackupSchedule
+		*out = new(SharedVolumeBackupSchedule)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeSpec.
@@ -471,6 +816,21 @@ func (in *SharedVolumeSpec) DeepCopy() *SharedVolumeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SharedVolumeStatus) DeepCopyInto(out *SharedVolumeStatus) {
 	*out = *in
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextBackupTime != nil {
+		in, out := &in.NextBackupTime, &out.NextBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeStatus.
@@ -483,13 +843,29 @@ func (in *SharedVolumeStatus) DeepCopy() *SharedVolumeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotTemplate) DeepCopyInto(out *SnapshotTemplate) {
+	*out = *in
+	out.WorkspaceRef = in.WorkspaceRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotTemplate.
+func (in *SnapshotTemplate) DeepCopy() *SnapshotTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Template) DeepCopyInto(out *Template) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Template.
@@ -510,6 +886,26 @@ func (in *Template) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateEnvironmentStatus) DeepCopyInto(out *TemplateEnvironmentStatus) {
+	*out = *in
+	if in.SharedVolumes != nil {
+		in, out := &in.SharedVolumes, &out.SharedVolumes
+		*out = make([]TemplateSharedVolumeStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateEnvironmentStatus.
+func (in *TemplateEnvironmentStatus) DeepCopy() *TemplateEnvironmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateEnvironmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateList) DeepCopyInto(out *TemplateList) {
 	*out = *in
@@ -542,6 +938,22 @@ func (in *TemplateList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSharedVolumeStatus) DeepCopyInto(out *TemplateSharedVolumeStatus) {
+	*out = *in
+	out.SharedVolumeRef = in.SharedVolumeRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSharedVolumeStatus.
+func (in *TemplateSharedVolumeStatus) DeepCopy() *TemplateSharedVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSharedVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateSpec) DeepCopyInto(out *TemplateSpec) {
 	*out = *in
@@ -553,6 +965,16 @@ func (in *TemplateSpec) DeepCopyInto(out *TemplateSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PrivateNetwork != nil {
+		in, out := &in.PrivateNetwork, &out.PrivateNetwork
+		*out = new(PrivateNetwork)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(InstanceSchedule)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSpec.
@@ -568,6 +990,29 @@ func (in *TemplateSpec) DeepCopy() *TemplateSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateStatus) DeepCopyInto(out *TemplateStatus) {
 	*out = *in
+	if in.LastUsedTime != nil {
+		in, out := &in.LastUsedTime, &out.LastUsedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make([]TemplateEnvironmentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ValidationErrors != nil {
+		in, out := &in.ValidationErrors, &out.ValidationErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateStatus.
@@ -706,6 +1151,13 @@ func (in *TenantStatus) DeepCopyInto(out *TenantStatus) {
 	}
 	out.Keycloak = in.Keycloak
 	in.Quota.DeepCopyInto(&out.Quota)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantStatus.
@@ -3,6 +3,10 @@ IMG_DIR=/data
 OUT_DIR=/img-tmp
 IMG_NAME=disk.img
 OUT_IMAGE=vm-snapshot.qcow2
+MODE=vm
+BASE_IMAGE=
+CONTENT_PATH=/media/data
+OWNER=1010:1010
 PROG_NAME=$0
 
 usage(){
@@ -10,6 +14,10 @@ usage(){
 	echo "  -d, --img-dir        Specify the working directory [DEFAULT=$IMG_DIR]"
 	echo "  -o, --out-dir        Specify the output directory  [DEFAULT=$OUT_DIR]"
 	echo "  -n, --img-name       Specify the name of the image [DEFAULT=$OUT_IMAGE]"
+	echo "  -m, --mode           Specify the type of environment (vm or container) [DEFAULT=$MODE]"
+	echo "  -b, --base-image     Specify the image the container content is layered on (container mode only)"
+	echo "  -p, --content-path   Specify the path of the content in the container [DEFAULT=$CONTENT_PATH]"
+	echo "  -u, --owner          Specify the owner (uid:gid) of the container content [DEFAULT=$OWNER]"
 	exit 1
 }
 
@@ -28,6 +36,22 @@ parse_args(){
 				shift
 				IMG_NAME=$1
 				;;
+			"-m" | "--mode")
+				shift
+				MODE=$1
+				;;
+			"-b" | "--base-image")
+				shift
+				BASE_IMAGE=$1
+				;;
+			"-p" | "--content-path")
+				shift
+				CONTENT_PATH=$1
+				;;
+			"-u" | "--owner")
+				shift
+				OWNER=$1
+				;;
 			*)
 				usage
 				;;
@@ -52,12 +76,45 @@ ADD ${OUT_IMAGE} /disk/
 EOF
 }
 
+export_content(){
+	if [ -z "$BASE_IMAGE" ]; then
+		echo "The base image must be specified in container mode"
+		return 1
+	fi
+
+	echo "Copying the content..."
+
+	# Copy the content of the persistent volume (including hidden files)
+	# in the build context, excluding the lost+found directory of the filesystem.
+	mkdir -p "${OUT_DIR}/content"
+	tar -C "$IMG_DIR" --exclude=./lost+found -cf - . | tar -C "${OUT_DIR}/content" -xf - || return 1
+
+	echo "Creating Dockerfile..."
+	# Create the Dockerfile, layering the content on top of the environment image.
+	cat <<EOF > "${OUT_DIR}/Dockerfile"
+FROM ${BASE_IMAGE}
+COPY --chown=${OWNER} content ${CONTENT_PATH}
+EOF
+}
+
 parse_args "$@"
 
-if export_img;
+case "$MODE" in
+	"vm")
+		EXPORT=export_img
+		;;
+	"container")
+		EXPORT=export_content
+		;;
+	*)
+		usage
+		;;
+esac
+
+if $EXPORT;
 then
-	echo "${IMG_DIR}/${IMG_NAME} successully converted"
+	echo "${IMG_DIR} successully exported"
 else
-	echo "Conversion unsuccessfully completed"
+	echo "Export unsuccessfully completed"
 	exit 1
 fi
\ No newline at end of file
@@ -18,6 +18,7 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -76,10 +77,21 @@ func main() {
 		klog.Infof("AUTHORIZED_KEYS_PATH env var found. Using path %v", authorizedKeysPath)
 	}
 
+	var userCAKey string
+	if userCAKeyPath, isEnvSet := os.LookupEnv("SSH_USER_CA_KEY_PATH"); isEnvSet {
+		data, err := os.ReadFile(userCAKeyPath)
+		if err != nil {
+			klog.Fatal("unable to read the public key of the SSH user CA", err)
+		}
+		klog.Infof("SSH_USER_CA_KEY_PATH env var found. Trusting the SSH user CA at path %v", userCAKeyPath)
+		userCAKey = strings.TrimSpace(string(data))
+	}
+
 	if err = (&bastion_controller.BastionReconciler{
 		Client:             mgr.GetClient(),
 		Scheme:             mgr.GetScheme(),
 		AuthorizedKeysPath: authorizedKeysPath,
+		UserCAKey:          userCAKey,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Fatal("unable to create controller", "controller", "Bastion", err)
 	}
@@ -0,0 +1,125 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main contains the entrypoint for the native SSH bastion proxy.
+package main
+
+import (
+	"flag"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/textlogger"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/bastionproxy"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/sshca"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/restcfg"
+)
+
+func main() {
+	listenerAddr := flag.String("address", ":2222", "[address]:port of the SSH server")
+	hostKeyPaths := flag.String("host-key-paths", "/host-keys/ssh_host_ed25519_key", "Comma separated list of paths of the host keys of the bastion")
+	caPrivateKeyPath := flag.String("ca-private-key-path", "/ca-keys/ca_key", "The path of the private key of the certificate authority trusted by the instances")
+	certificateValidity := flag.Duration("certificate-validity", 1*time.Minute, "The validity of the certificates used to connect to the instances")
+	targetUser := flag.String("target-user", "crownlabs", "The user the connections towards the instances are authenticated as")
+	targetPort := flag.Int("target-port", 22, "The SSH port of the instances")
+	dialTimeout := flag.Duration("dial-timeout", 10*time.Second, "The maximum time to establish the connections towards the instances")
+	handshakeTimeout := flag.Duration("handshake-timeout", 30*time.Second, "The maximum time for the clients to complete the SSH handshake")
+	auditLogPath := flag.String("audit-log-path", "", "The path of the file the session records are appended to (standard output if empty)")
+	typescriptDir := flag.String("typescript-dir", "", "The directory where the typescripts of the exam sessions are stored (disabled if empty)")
+
+	restcfg.InitFlags(nil)
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	log := textlogger.NewLogger(textlogger.NewConfig()).WithName("bastion-proxy")
+	ctrl.SetLogger(log)
+	ctx := ctrl.SetupSignalHandler()
+
+	var hostKeys []ssh.Signer
+	for _, path := range strings.Split(*hostKeyPaths, ",") {
+		hostKey, err := readSigner(path)
+		if err != nil {
+			log.Error(err, "unable to read host key", "path", path)
+			os.Exit(1)
+		}
+		hostKeys = append(hostKeys, hostKey)
+	}
+
+	caPrivateKey, err := os.ReadFile(*caPrivateKeyPath)
+	if err != nil {
+		log.Error(err, "unable to read the private key of the certificate authority")
+		os.Exit(1)
+	}
+
+	authority, err := sshca.NewAuthority(caPrivateKey, *certificateValidity)
+	if err != nil {
+		log.Error(err, "invalid configuration")
+		os.Exit(1)
+	}
+
+	auditor := &bastionproxy.Auditor{Output: os.Stdout, TypescriptDir: *typescriptDir}
+	if *auditLogPath != "" {
+		auditLog, err := os.OpenFile(*auditLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			log.Error(err, "unable to open the audit log")
+			os.Exit(1)
+		}
+		auditor.Output = auditLog
+	}
+
+	k8sClient, err := bastionproxy.NewCachedClient(ctx)
+	if err != nil {
+		log.Error(err, "unable to prepare k8s client")
+		os.Exit(1)
+	}
+
+	server := bastionproxy.Server{
+		Client:           k8sClient,
+		Log:              log,
+		HostKeys:         hostKeys,
+		Authority:        authority,
+		Auditor:          auditor,
+		TargetUser:       *targetUser,
+		TargetPort:       *targetPort,
+		DialTimeout:      *dialTimeout,
+		HandshakeTimeout: *handshakeTimeout,
+	}
+
+	listener, err := net.Listen("tcp", *listenerAddr)
+	if err != nil {
+		log.Error(err, "unable to start listener")
+		os.Exit(1)
+	}
+
+	log.Info("CrownLabs SSH bastion proxy started", "bind", *listenerAddr)
+	if err := server.Serve(ctx, listener); err != nil {
+		log.Error(err, "SSH server terminated unexpectedly")
+		os.Exit(1)
+	}
+}
+
+// readSigner reads the private key at the given path, returning the corresponding signer.
+func readSigner(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(data)
+}
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	ctrl "sigs.k8s.io/controller-runtime"
 
 	tracker "github.com/netgroup-polito/CrownLabs/operators/pkg/bastion-ssh-tracker"
 )
@@ -47,11 +48,32 @@ func main() {
 	port := flag.Int("ssh-tracker-port", 22, "The port on which the SSH tracker will listen for connections.")
 	snaplen := flag.Int("ssh-tracker-snaplen", 1600, "The snaplen for the SSH tracker.")
 	metricsAddr := flag.String("ssh-tracker-metrics-addr", ":8082", "The address the metric endpoint binds to.")
+	sessionTimeout := flag.Duration("ssh-tracker-session-timeout", 12*time.Hour, "The time after which a connection with no packets detected is considered terminated.")
+	activityRetention := flag.Duration("ssh-tracker-activity-retention", 7*24*time.Hour, "The time after which the last activity towards a destination with no packets detected is forgotten (it shall exceed the largest idle timeout of the templates).")
+	resolveInstances := flag.Bool("ssh-tracker-resolve-instances", true, "Whether to attribute the connections to the corresponding instances, watching them through the Kubernetes API.")
 
 	flag.Parse()
 
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var resolver *tracker.InstanceResolver
+	if *resolveInstances {
+		cfg, err := ctrl.GetConfig()
+		if err != nil {
+			log.Fatalf("Failed retrieving the Kubernetes configuration: %v", err)
+		}
+
+		resolver = tracker.NewInstanceResolver()
+		if err := resolver.Start(ctx, cfg); err != nil {
+			log.Fatalf("Failed starting the instance resolver: %v", err)
+		}
+	}
+
+	sshTracker := tracker.NewSSHTracker(resolver, *sessionTimeout, *activityRetention)
+
 	metricsHandler := http.NewServeMux()
 	metricsHandler.Handle("/metrics", promhttp.Handler())
+	metricsHandler.Handle("/activity", sshTracker.ActivityHandler())
 	metricsServer := &http.Server{
 		Addr:         *metricsAddr,
 		Handler:      metricsHandler,
@@ -110,7 +132,6 @@ func main() {
 		}
 	}()
 
-	sshTracker := tracker.NewSSHTracker()
 	go func() {
 		trackerRunning.Store(true)
 		log.Printf("Starting SSH tracker on interface %s, port %d, snaplen %d", *iface, *port, *snaplen)
@@ -127,13 +148,14 @@ func main() {
 	log.Println("Signal received, shutting down...")
 	trackerRunning.Store(false)
 	sshTracker.Stop()
+	cancel()
 
 	// Graceful shutdown for HTTP servers
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
 
-	_ = metricsServer.Shutdown(ctx)
-	_ = healthServer.Shutdown(ctx)
+	_ = metricsServer.Shutdown(shutdownCtx)
+	_ = healthServer.Shutdown(shutdownCtx)
 
 	log.Println("Shutdown complete")
 }
@@ -17,6 +17,7 @@ package main
 
 import (
 	"flag"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -41,6 +42,8 @@ import (
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/instautoctrl"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/instctrl"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/shvolctrl"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/tmplctrl"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/registry"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/restcfg"
 )
 
@@ -73,12 +76,28 @@ func main() {
 		"( e.g. key1=value1&key2=value2")
 
 	sharedVolumeStorageClass := flag.String("shared-volume-storage-class", "rook-nfs", "The StorageClass to be used for all SharedVolumes' PVC (if unique can be used to enforce ResourceQuota on Workspaces, about number and size of ShVols)")
+	enableSharedVolumeSnapshots := flag.Bool("enable-shared-volume-snapshots", false, "Enable the snapshots, scheduled backups and restores of SharedVolumes (requires the CSI VolumeSnapshot CRDs)")
+	sharedVolumeSnapshotClass := flag.String("shared-volume-snapshot-class", "", "The VolumeSnapshotClass used for the snapshots of SharedVolumes, if not specified by them (empty for the default one)")
+	maxConcurrentSharedVolumeSnapshotReconciles := flag.Int("max-concurrent-reconciles-shared-volume-snapshot", 1, "The maximum number of concurrent Reconciles which can be run for the SharedVolume snapshot, backup and restore controllers")
 
 	maxConcurrentTerminationReconciles := flag.Int("max-concurrent-reconciles-termination", 1, "The maximum number of concurrent Reconciles which can be run for the Instance Termination controller")
 	instanceTerminationStatusCheckTimeout := flag.Duration("instance-termination-status-check-timeout", 3*time.Second, "The maximum time to wait for the status check for Instances that require it")
 	instanceTerminationStatusCheckInterval := flag.Duration("instance-termination-status-check-interval", 2*time.Minute, "The interval to check the status of Instances that require it")
 	maxConcurrentSubmissionReconciles := flag.Int("max-concurrent-reconciles-submission", 1, "The maximum number of concurrent Reconciles which can be run for the Instance Submission controller")
-
+	maxConcurrentScheduleReconciles := flag.Int("max-concurrent-reconciles-schedule", 1, "The maximum number of concurrent Reconciles which can be run for the Instance Schedule controller")
+	maxConcurrentExpirationReconciles := flag.Int("max-concurrent-reconciles-expiration", 1, "The maximum number of concurrent Reconciles which can be run for the Instance Expiration controller")
+	enableInstanceExpiration := flag.Bool("enable-instance-expiration", true, "Enable the Instance Expiration controller, deleting (or stopping) the Instances whose lifetime is expired")
+	instanceExpirationDryRun := flag.Bool("instance-expiration-dry-run", true, "Only emit an event for the expired Instances, without deleting or stopping them")
+	instanceExpirationCheckInterval := flag.Duration("instance-expiration-check-interval", 15*time.Minute, "The interval to check the expiration of Instances, and refresh their remaining lifetime")
+	maxConcurrentIdleReconciles := flag.Int("max-concurrent-reconciles-idle", 1, "The maximum number of concurrent Reconciles which can be run for the Instance Idle controller")
+	maxConcurrentTemplateReconciles := flag.Int("max-concurrent-reconciles-template", 1, "The maximum number of concurrent Reconciles which can be run for the Template controller")
+	instanceIdleCheckInterval := flag.Duration("instance-idle-check-interval", 5*time.Minute, "The interval to check the activity of running Instances with an idle timeout")
+	instanceIdleRequestTimeout := flag.Duration("instance-idle-request-timeout", 3*time.Second, "The maximum time to wait for the retrieval of each activity signal")
+	instanceIdleCPUThreshold := flag.Float64("instance-idle-cpu-threshold", 5, "The CPU usage (percentage of a core) above which container Instances are considered in use")
+	instanceIdleSSHTrackerURL := flag.String("instance-idle-ssh-tracker-url", "", "The URL of the activity endpoint exposed by the bastion SSH tracker (SSH activity is not considered if empty)")
+	instanceExpirationWarningThresholds := flag.String("instance-expiration-warning-thresholds", "24h,1h", "The comma separated list of remaining lifetimes at which a warning is emitted before the expiration of Instances")
+
+	sshUserCAKey := flag.String("ssh-user-ca-key", "", "The public key of the SSH user certificate authority trusted by the VMs, in place of the public keys of the tenants (disabled if empty)")
 	flag.StringVar(&svcUrls.WebsiteBaseURL, "website-base-url", "crownlabs.polito.it", "Base URL of crownlabs website instance")
 	flag.StringVar(&svcUrls.InstancesAuthURL, "instances-auth-url", "", "The base URL for user instances authentication (i.e., oauth2-proxy)")
 
@@ -92,8 +111,11 @@ func main() {
 	flag.StringVar(&instSnapOpts.VMRegistry, "vm-registry", "", "The registry where VMs should be uploaded")
 	flag.StringVar(&instSnapOpts.RegistrySecretName, "vm-registry-secret", "", "The name of the secret for the VM registry")
 
-	flag.StringVar(&instSnapOpts.ContainerImgExport, "container-export-img", "crownlabs/img-exporter", "The image for the img-exporter (container in charge of exporting the disk of a persistent vm)")
+	flag.StringVar(&instSnapOpts.ContainerImgExport, "container-export-img", "crownlabs/img-exporter", "The image for the img-exporter (container in charge of exporting the disk of a persistent vm, or the content of a persistent container)")
 	flag.StringVar(&instSnapOpts.ContainerKaniko, "container-kaniko-img", "gcr.io/kaniko-project/executor", "The image for the Kaniko container to be deployed")
+	instSnapKeepLast := flag.Int("instance-snapshot-keep-last", 0, "The maximum number of completed InstanceSnapshots retained for each Instance (0 means unlimited)")
+	instSnapDeleteImages := flag.Bool("instance-snapshot-delete-images", false, "Whether to delete the images from the registry once the corresponding InstanceSnapshots are deleted")
+	instSnapRegistryTimeout := flag.Duration("instance-snapshot-registry-timeout", 10*time.Second, "The timeout of the requests towards the registry to delete the images of the InstanceSnapshots")
 
 	restcfg.InitFlags(nil)
 	klog.InitFlags(nil)
@@ -103,6 +125,12 @@ func main() {
 
 	log := ctrl.Log.WithName("setup")
 
+	expirationWarningThresholds, err := parseDurations(*instanceExpirationWarningThresholds)
+	if err != nil {
+		log.Error(err, "invalid instance expiration warning thresholds")
+		os.Exit(1)
+	}
+
 	whiteListMap := parseMap(*namespaceWhiteList)
 	log.Info("restricting reconciled namespaces", "labels", *namespaceWhiteList)
 
@@ -131,6 +159,7 @@ func main() {
 		NamespaceWhitelist: nsWhitelist,
 		ServiceUrls:        svcUrls,
 		ContainerEnvOpts:   containerEnvOpts,
+		SSHUserCAKey:       *sshUserCAKey,
 	}).SetupWithManager(mgr, *maxConcurrentReconciles); err != nil {
 		log.Error(err, "unable to create controller", "controller", instanceCtrlName)
 		os.Exit(1)
@@ -138,12 +167,18 @@ func main() {
 
 	// Configure the InstanceSnapshot controller
 	instanceSnapshotCtrl := "InstanceSnapshot"
+	var imageDeleter instancesnapshot_controller.ImageDeleter
+	if *instSnapDeleteImages {
+		imageDeleter = &registry.Client{HTTPClient: &http.Client{Timeout: *instSnapRegistryTimeout}}
+	}
 	if err = (&instancesnapshot_controller.InstanceSnapshotReconciler{
 		Client:             mgr.GetClient(),
 		Scheme:             mgr.GetScheme(),
 		EventsRecorder:     mgr.GetEventRecorderFor(instanceSnapshotCtrl),
 		NamespaceWhitelist: nsWhitelist,
 		ContainersSnapshot: instSnapOpts,
+		KeepLast:           *instSnapKeepLast,
+		Registry:           imageDeleter,
 	}).SetupWithManager(mgr); err != nil {
 		log.Error(err, "unable to create controller", "controller", instanceSnapshotCtrl)
 		os.Exit(1)
@@ -176,6 +211,62 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Configure the Instance schedule controller
+	instanceSchedule := "InstanceSchedule"
+	if err := (&instautoctrl.InstanceScheduleReconciler{
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		EventsRecorder:     mgr.GetEventRecorderFor(instanceSchedule),
+		NamespaceWhitelist: nsWhitelist,
+	}).SetupWithManager(mgr, *maxConcurrentScheduleReconciles); err != nil {
+		log.Error(err, "unable to create controller", "controller", instanceSchedule)
+		os.Exit(1)
+	}
+
+	// Configure the Instance expiration controller
+	if *enableInstanceExpiration {
+		instanceExpiration := "InstanceExpiration"
+		if err := (&instautoctrl.InstanceExpirationReconciler{
+			Client:             mgr.GetClient(),
+			Scheme:             mgr.GetScheme(),
+			EventsRecorder:     mgr.GetEventRecorderFor(instanceExpiration),
+			NamespaceWhitelist: nsWhitelist,
+			CheckInterval:      *instanceExpirationCheckInterval,
+			WarningThresholds:  expirationWarningThresholds,
+			DryRun:             *instanceExpirationDryRun,
+		}).SetupWithManager(mgr, *maxConcurrentExpirationReconciles); err != nil {
+			log.Error(err, "unable to create controller", "controller", instanceExpiration)
+			os.Exit(1)
+		}
+		log.Info("instance expiration enabled", "dry-run", *instanceExpirationDryRun)
+	}
+
+	// Configure the Instance idle controller
+	instanceIdle := "InstanceIdle"
+	activitySources := []instautoctrl.ActivitySource{
+		&instautoctrl.NoVNCActivitySource{Client: mgr.GetClient(), Timeout: *instanceIdleRequestTimeout},
+	}
+	if cpuSource, err := instautoctrl.NewCPUActivitySource(mgr.GetClient(), containerEnvOpts.InstMetricsEndpoint,
+		float32(*instanceIdleCPUThreshold), *instanceIdleRequestTimeout); err != nil {
+		log.Error(err, "cpu activity signal disabled")
+	} else {
+		activitySources = append(activitySources, cpuSource)
+	}
+	if *instanceIdleSSHTrackerURL != "" {
+		activitySources = append(activitySources, &instautoctrl.SSHActivitySource{URL: *instanceIdleSSHTrackerURL, Timeout: *instanceIdleRequestTimeout})
+	}
+	if err := (&instautoctrl.InstanceIdleReconciler{
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		EventsRecorder:     mgr.GetEventRecorderFor(instanceIdle),
+		NamespaceWhitelist: nsWhitelist,
+		Sources:            activitySources,
+		CheckInterval:      *instanceIdleCheckInterval,
+	}).SetupWithManager(mgr, *maxConcurrentIdleReconciles); err != nil {
+		log.Error(err, "unable to create controller", "controller", instanceIdle)
+		os.Exit(1)
+	}
+
 	// Configure the SharedVolume controller
 	const sharedVolumeCtrl = "SharedVolume"
 	if err := (&shvolctrl.SharedVolumeReconciler{
@@ -188,6 +279,53 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *enableSharedVolumeSnapshots {
+		// Configure the SharedVolumeSnapshot controller
+		const sharedVolumeSnapshotCtrl = "SharedVolumeSnapshot"
+		if err := (&shvolctrl.SharedVolumeSnapshotReconciler{
+			Client:              mgr.GetClient(),
+			EventsRecorder:      mgr.GetEventRecorderFor(sharedVolumeSnapshotCtrl),
+			NamespaceWhitelist:  nsWhitelist,
+			VolumeSnapshotClass: *sharedVolumeSnapshotClass,
+		}).SetupWithManager(mgr, *maxConcurrentSharedVolumeSnapshotReconciles); err != nil {
+			log.Error(err, "unable to create controller", "controller", sharedVolumeSnapshotCtrl)
+			os.Exit(1)
+		}
+
+		// Configure the SharedVolumeRestore controller
+		const sharedVolumeRestoreCtrl = "SharedVolumeRestore"
+		if err := (&shvolctrl.SharedVolumeRestoreReconciler{
+			Client:             mgr.GetClient(),
+			EventsRecorder:     mgr.GetEventRecorderFor(sharedVolumeRestoreCtrl),
+			NamespaceWhitelist: nsWhitelist,
+			PVCStorageClass:    *sharedVolumeStorageClass,
+		}).SetupWithManager(mgr, *maxConcurrentSharedVolumeSnapshotReconciles); err != nil {
+			log.Error(err, "unable to create controller", "controller", sharedVolumeRestoreCtrl)
+			os.Exit(1)
+		}
+
+		// Configure the SharedVolume backup controller
+		const sharedVolumeBackupCtrl = "SharedVolumeBackup"
+		if err := (&shvolctrl.SharedVolumeBackupReconciler{
+			Client:             mgr.GetClient(),
+			EventsRecorder:     mgr.GetEventRecorderFor(sharedVolumeBackupCtrl),
+			NamespaceWhitelist: nsWhitelist,
+		}).SetupWithManager(mgr, *maxConcurrentSharedVolumeSnapshotReconciles); err != nil {
+			log.Error(err, "unable to create controller", "controller", sharedVolumeBackupCtrl)
+			os.Exit(1)
+		}
+	}
+
+	// Configure the Template controller
+	const templateCtrl = "Template"
+	if err := (&tmplctrl.TemplateReconciler{
+		Client:             mgr.GetClient(),
+		NamespaceWhitelist: nsWhitelist,
+	}).SetupWithManager(mgr, *maxConcurrentTemplateReconciles); err != nil {
+		log.Error(err, "unable to create controller", "controller", templateCtrl)
+		os.Exit(1)
+	}
+
 	// Add readiness probe
 	err = mgr.AddReadyzCheck("ready-ping", healthz.Ping)
 	if err != nil {
@@ -219,3 +357,19 @@ func parseMap(raw string) map[string]string {
 	}
 	return m
 }
+
+// This method parses a comma separated list of durations.
+func parseDurations(raw string) ([]time.Duration, error) {
+	var durations []time.Duration
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(item)
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, duration)
+	}
+	return durations, nil
+}
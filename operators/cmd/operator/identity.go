@@ -0,0 +1,71 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main contains the entrypoint for the Crownlabs unified operator.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/common"
+)
+
+var (
+	identityProvider string
+
+	scimURL           string
+	scimToken         string
+	scimActivateUsers bool
+
+	memoryAutoVerify bool // If true, the users created by the in-memory provider are immediately verified.
+)
+
+func init() {
+	flag.StringVar(&identityProvider, "identity-provider", string(common.IdentityProviderKeycloak), "The identity provider managing users and roles (keycloak, scim or memory)")
+	flag.StringVar(&scimURL, "scim-url", "", "The base URL of the SCIM 2.0 endpoint")
+	flag.StringVar(&scimToken, "scim-token", "", "The bearer token to authenticate towards the SCIM 2.0 endpoint")
+	flag.BoolVar(&scimActivateUsers, "scim-activate-users", false, "Create the users as already active (hence verified) in the SCIM 2.0 endpoint")
+	flag.BoolVar(&memoryAutoVerify, "memory-auto-verify", true, "Consider the users created by the in-memory identity provider as immediately verified")
+}
+
+func setupIdentityProvider(
+	ctx context.Context,
+	log logr.Logger,
+) error {
+	provider, err := common.ParseIdentityProviderType(identityProvider)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Initializing identity provider", "provider", provider)
+
+	switch provider {
+	case common.IdentityProviderSCIM:
+		if scimURL == "" {
+			err := fmt.Errorf("missing parameters for SCIM configuration")
+			log.Error(err, "SCIM identity provider will not be initialized (settings not provided)")
+			return err
+		}
+		return common.SetupSCIMIdentityProvider(ctx, scimURL, scimToken, scimActivateUsers, log)
+	case common.IdentityProviderMemory:
+		common.SetupMemoryIdentityProvider(memoryAutoVerify, log)
+		return nil
+	default:
+		return setupKeycloak(ctx, log)
+	}
+}
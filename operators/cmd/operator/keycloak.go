@@ -19,6 +19,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 
@@ -34,6 +35,9 @@ var (
 	keycloakRolesClientID string // The client ID of the client in which the roles are defined.
 
 	keycloakCompatibilityMode bool // If true, the Keycloak actor will use the compatibility mode for Keycloak old clients.
+
+	keycloakCacheTTL  time.Duration // The lifetime of the cached users, roles and role mappings.
+	keycloakGroupMode bool          // If true, the workspaces are mapped to Keycloak groups rather than client roles.
 )
 
 func init() {
@@ -43,6 +47,8 @@ func init() {
 	flag.StringVar(&keycloakClientSecret, "keycloak-client-secret", "", "Keycloak Client Secret")
 	flag.StringVar(&keycloakRolesClientID, "keycloak-roles-client-id", "", "Keycloak Roles Client ID (the client in which the roles are defined)")
 	flag.BoolVar(&keycloakCompatibilityMode, "keycloak-compatibility-mode", false, "Enable Keycloak compatibility mode for old clients")
+	flag.DurationVar(&keycloakCacheTTL, "keycloak-cache-ttl", 5*time.Minute, "The lifetime of the cached Keycloak users, roles and role mappings (0 to disable caching)")
+	flag.BoolVar(&keycloakGroupMode, "keycloak-group-mode", false, "Map the workspaces to Keycloak groups, rather than client roles")
 }
 
 func setupKeycloak(
@@ -64,6 +70,10 @@ func setupKeycloak(
 	if keycloakCompatibilityMode {
 		log.Info("WARNING: Keycloak compatibility mode is enabled")
 
+		if keycloakGroupMode {
+			return fmt.Errorf("the Keycloak group mode is not supported in compatibility mode")
+		}
+
 		err := common.SetupKeycloakActorCompatibility(
 			ctx,
 			keycloakURL,
@@ -77,6 +87,9 @@ func setupKeycloak(
 			return err
 		}
 	} else {
+		log.Info("Configuring Keycloak actor", "cacheTTL", keycloakCacheTTL, "groupMode", keycloakGroupMode)
+		common.ConfigureKeycloakActor(keycloakCacheTTL, keycloakGroupMode)
+
 		err := common.SetupKeycloakActor(
 			ctx,
 			keycloakURL,
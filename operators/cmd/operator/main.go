@@ -77,7 +77,7 @@ func main() {
 	var enableKeycloak bool
 	flag.BoolVar(&enableTenant, "enable-tenant", true, "Enable the tenant controller.")
 	flag.BoolVar(&enableWorkspace, "enable-workspace", true, "Enable the workspace controller.")
-	flag.BoolVar(&enableKeycloak, "enable-keycloak", true, "Enable the Keycloak integration.")
+	flag.BoolVar(&enableKeycloak, "enable-keycloak", true, "Enable the integration with the identity provider (Keycloak by default).")
 
 	flag.BoolVar(&enableWebhooks, "enable-webhooks", true, "Enable the webhooks server.")
 
@@ -109,15 +109,15 @@ func main() {
 	}
 	log.Info("Selecting resources with label", "label", targetLabelStr)
 
-	// enabling Keycloak if modules that needs it are enabled
+	// enabling the identity provider if modules that needs it are enabled
 	enableKeycloak = enableKeycloak && (enableTenant || enableWorkspace)
 	if enableKeycloak {
-		err := setupKeycloak(ctx, log)
+		err := setupIdentityProvider(ctx, log)
 		if err != nil {
-			klog.Fatal(err, "Unable to setup Keycloak actor")
+			klog.Fatal(err, "Unable to setup identity provider")
 		}
 	} else {
-		log.Info("Keycloak actor will not be initialized (not needed)")
+		log.Info("Identity provider will not be initialized (not needed)")
 	}
 
 	if enableTenant {
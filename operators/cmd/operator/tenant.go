@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -47,6 +48,13 @@ var (
 	mydrivePVCsStorageClassName   string
 	myDrivePVCsNamespace          string
 	waitUserVerification          bool // If true, the reconciliation will wait for the user to be verified in Keycloak before creating resources.
+
+	keycloakEventsSecret         string
+	keycloakEventsMaxAge         time.Duration
+	keycloakEventsRateLimit      float64
+	keycloakEventsRateBurst      int
+	keycloakEventsQueuePath      string
+	keycloakEventsDedupRetention time.Duration
 )
 
 const (
@@ -77,6 +85,13 @@ func init() {
 	flag.IntVar(&forge.CapCPU, "cap-cpu", 25, "The cap amount of CPU cores that can be requested by a Tenant.")
 	flag.IntVar(&forge.CapMemoryGiga, "cap-memory-giga", 50, "The cap amount of RAM memory in gigabytes that can be requested by a Tenant.")
 
+	flag.StringVar(&keycloakEventsSecret, "keycloak-events-secret", "", "The secret shared with Keycloak to authenticate the events (HMAC signature or bearer token). If empty, the events are not authenticated")
+	flag.DurationVar(&keycloakEventsMaxAge, "keycloak-events-max-age", 5*time.Minute, "The maximum age of the accepted Keycloak events, to prevent replays (0 to disable the check)")
+	flag.Float64Var(&keycloakEventsRateLimit, "keycloak-events-rate-limit", 10, "The maximum number of Keycloak events per second accepted from each source (0 to disable the limit)")
+	flag.IntVar(&keycloakEventsRateBurst, "keycloak-events-rate-burst", 50, "The maximum burst of Keycloak events accepted from each source")
+	flag.StringVar(&keycloakEventsQueuePath, "keycloak-events-queue-path", "", "The file the queue of the Keycloak events is persisted to. If empty, the queue is kept in memory only")
+	flag.DurationVar(&keycloakEventsDedupRetention, "keycloak-events-dedup-retention", time.Hour, "The time the identifiers of the Keycloak events are retained to discard duplicates")
+
 	flag.IntVar(&tenantMaxConcurrentReconciles, "max-concurrent-reconciles", 1, "The maximum number of concurrent Reconciles which can be run")
 }
 
@@ -91,6 +106,16 @@ func setupTenant(
 		log.Info("Base workspaces for tenants to be enforced", "workspaces", baseWorkspacesList)
 	}
 
+	keycloakEventsQueue, err := tenant.NewKeycloakEventsQueue(keycloakEventsQueuePath, max(keycloakEventsDedupRetention, keycloakEventsMaxAge))
+	if err != nil {
+		log.Error(err, "Unable to restore the Keycloak events queue", "path", keycloakEventsQueuePath)
+		return err
+	}
+
+	if keycloakEventsSecret == "" {
+		log.Info("WARNING: the Keycloak events are not authenticated (secret not provided)")
+	}
+
 	tn := &tenant.Reconciler{
 		Client:                      mgr.GetClient(),
 		Scheme:                      mgr.GetScheme(),
@@ -100,18 +125,32 @@ func setupTenant(
 		MyDrivePVCsSize:             mydrivePVCsSize.Quantity,
 		MyDrivePVCsStorageClassName: mydrivePVCsStorageClassName,
 		MyDrivePVCsNamespace:        myDrivePVCsNamespace,
-		KeycloakActor:               common.GetKeycloakActor(),
+		IdentityProvider:            common.GetIdentityProvider(),
 		WaitUserVerification:        waitUserVerification,
 		SandboxClusterRole:          sandboxClusterRole,
 		BaseWorkspaces:              baseWorkspacesList,
 		Concurrency:                 tenantMaxConcurrentReconciles,
 		Reschedule:                  reschedule,
+		KeycloakEventsGuard: &tenant.KeycloakEventsGuard{
+			Secret:      []byte(keycloakEventsSecret),
+			MaxEventAge: keycloakEventsMaxAge,
+			RateLimit:   rate.Limit(keycloakEventsRateLimit),
+			RateBurst:   keycloakEventsRateBurst,
+		},
+		KeycloakEventsQueue: keycloakEventsQueue,
 	}
 
 	if err := tn.SetupWithManager(mgr, log); err != nil {
 		return err
 	}
 
+	// Trigger the reconciliations requested by the Keycloak events
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return keycloakEventsQueue.Run(ctx, log.WithName("keycloak-events-queue"), tn.TriggerReconcileChannel)
+	})); err != nil {
+		return err
+	}
+
 	// Register the Keycloak event handler for tenant webhook events
 	startKeycloakWebhookHTTPServer(tn, log, mgr)
 
@@ -16,11 +16,30 @@
 package main
 
 import (
+	"strings"
+
 	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/common"
+	instwebhook "github.com/netgroup-polito/CrownLabs/operators/pkg/controller/instance/webhook"
+	tmplwebhook "github.com/netgroup-polito/CrownLabs/operators/pkg/controller/template/webhook"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/workspace"
+	wswebhook "github.com/netgroup-polito/CrownLabs/operators/pkg/controller/workspace/webhook"
+)
+
+const (
+	// InstanceValidatorWebhookPath -> path on which the instance validator webhook will be bound.
+	InstanceValidatorWebhookPath = "/validator-v1alpha2-instance"
+	// InstanceDefaulterWebhookPath -> path on which the instance defaulter webhook will be bound.
+	InstanceDefaulterWebhookPath = "/defaulter-v1alpha2-instance"
+	// TemplateValidatorWebhookPath -> path on which the template validator webhook will be bound.
+	TemplateValidatorWebhookPath = "/validator-v1alpha2-template"
+	// WorkspaceValidatorWebhookPath -> path on which the workspace validator webhook will be bound.
+	WorkspaceValidatorWebhookPath = "/validator-v1alpha1-workspace"
 )
 
 func init() {}
@@ -32,13 +51,78 @@ func setupWorkspace(
 ) error {
 	// Create the Workspace Reconciler
 	wr := &workspace.Reconciler{
-		Client:        mgr.GetClient(),
-		Scheme:        mgr.GetScheme(),
-		TargetLabel:   targetLabel,
-		KeycloakActor: common.GetKeycloakActor(),
-		Reschedule:    reschedule,
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		TargetLabel:      targetLabel,
+		IdentityProvider: common.GetIdentityProvider(),
+		Reschedule:       reschedule,
 	}
 
 	// Register the WorkspaceReconciler with the manager
-	return wr.SetupWithManager(mgr, log)
+	if err := wr.SetupWithManager(mgr, log); err != nil {
+		return err
+	}
+
+	// Create the reconciler tracking the resources consumed by the instances of each workspace
+	ur := &workspace.UsageReconciler{
+		Client:      mgr.GetClient(),
+		TargetLabel: targetLabel,
+	}
+	if err := ur.SetupWithManager(mgr, log); err != nil {
+		return err
+	}
+
+	// Setup the webhooks validating (e.g. template access and quotas) and defaulting instances,
+	// as well as the ones validating templates and workspaces
+	if enableWebhooks {
+		if err := setupInstanceWebhook(mgr); err != nil {
+			return err
+		}
+		if err := setupTemplateWebhook(mgr); err != nil {
+			return err
+		}
+		return setupWorkspaceWebhook(mgr)
+	}
+
+	return nil
+}
+
+func setupInstanceWebhook(mgr manager.Manager) error {
+	instWh := instwebhook.InstanceWebhook{
+		Client:       mgr.GetClient(),
+		BypassGroups: strings.Split(tenantWebhookBypassGroups, ","),
+	}
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha2.Instance{}).
+		WithValidator(&instwebhook.InstanceValidator{InstanceWebhook: instWh}).
+		WithValidatorCustomPath(InstanceValidatorWebhookPath).
+		WithDefaulter(&instwebhook.InstanceDefaulter{InstanceWebhook: instWh}).
+		WithDefaulterCustomPath(InstanceDefaulterWebhookPath).
+		Complete()
+}
+
+func setupTemplateWebhook(mgr manager.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha2.Template{}).
+		WithValidator(&tmplwebhook.TemplateValidator{
+			TemplateWebhook: tmplwebhook.TemplateWebhook{
+				BypassGroups: strings.Split(tenantWebhookBypassGroups, ","),
+			},
+		}).
+		WithValidatorCustomPath(TemplateValidatorWebhookPath).
+		Complete()
+}
+
+func setupWorkspaceWebhook(mgr manager.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Workspace{}).
+		WithValidator(&wswebhook.WorkspaceValidator{
+			WorkspaceWebhook: wswebhook.WorkspaceWebhook{
+				Client:       mgr.GetClient(),
+				BypassGroups: strings.Split(tenantWebhookBypassGroups, ","),
+			},
+		}).
+		WithValidatorCustomPath(WorkspaceValidatorWebhookPath).
+		Complete()
 }
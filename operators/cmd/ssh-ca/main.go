@@ -0,0 +1,87 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main contains the entrypoint for the SSH certificate authority.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/textlogger"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/sshca"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/restcfg"
+)
+
+func main() {
+	listenerAddr := flag.String("address", ":8080", "[address]:port of the signing server")
+	caPrivateKeyPath := flag.String("ca-private-key-path", "/ca-keys/ca_key", "The path of the private key of the certificate authority")
+	certificateValidity := flag.Duration("certificate-validity", 1*time.Hour, "The validity of the issued certificates")
+
+	restcfg.InitFlags(nil)
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	log := textlogger.NewLogger(textlogger.NewConfig()).WithName("ssh-ca")
+
+	caPrivateKey, err := os.ReadFile(*caPrivateKeyPath)
+	if err != nil {
+		log.Error(err, "unable to read the private key of the certificate authority")
+		os.Exit(1)
+	}
+
+	authority, err := sshca.NewAuthority(caPrivateKey, *certificateValidity)
+	if err != nil {
+		log.Error(err, "invalid configuration")
+		os.Exit(1)
+	}
+
+	k8sClient, err := sshca.NewK8sClient()
+	if err != nil {
+		log.Error(err, "unable to prepare k8s client")
+		os.Exit(1)
+	}
+
+	handler := http.NewServeMux()
+	server := &http.Server{
+		Addr:              *listenerAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: 2 * time.Second, // Required to limit the effects of the Slowloris attack.
+	}
+
+	handler.HandleFunc("/healthz", healthzHandler)
+	handler.HandleFunc("/ca.pub", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, authority.PublicKey())
+	})
+	handler.Handle("/sign", &sshca.SignHandler{Log: log.WithName("sign"), Client: k8sClient, Authority: authority})
+
+	log.Info("CrownLabs SSH certificate authority started", "bind", *listenerAddr, "validity", *certificateValidity)
+	log.Error(server.ListenAndServe(), "unable to start http server")
+}
+
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "Method not allowed")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "OK")
+}
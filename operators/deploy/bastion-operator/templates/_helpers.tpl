@@ -66,3 +66,11 @@ Metrics selector additional labels
 {{- define "bastion-operator.metricsAdditionalLabels" -}}
 app.kubernetes.io/component: metrics
 {{- end }}
+
+{{/*
+Selector labels of the SSH certificate authority
+*/}}
+{{- define "bastion-operator.sshCASelectorLabels" -}}
+app.kubernetes.io/name: {{ include "bastion-operator.name" . }}-ssh-ca
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
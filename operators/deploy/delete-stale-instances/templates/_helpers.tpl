@@ -1,61 +0,0 @@
-{{/* vim: set filetype=mustache: */}}
-{{/*
-Expand the name of the chart.
-*/}}
-{{- define "delete-stale-instances.name" -}}
-{{- default .Chart.Name .Values.nameOverride | trunc 63 | trimSuffix "-" }}
-{{- end }}
-
-{{/*
-Create a default fully qualified app name.
-We truncate at 63 chars because some Kubernetes name fields are limited to this (by the DNS naming spec).
-If release name contains chart name it will be used as a full name.
-*/}}
-{{- define "delete-stale-instances.fullname" -}}
-{{- if .Values.fullnameOverride }}
-{{- .Values.fullnameOverride | trunc 63 | trimSuffix "-" }}
-{{- else }}
-{{- $name := default .Chart.Name .Values.nameOverride }}
-{{- if contains $name .Release.Name }}
-{{- .Release.Name | trunc 63 | trimSuffix "-" }}
-{{- else }}
-{{- printf "%s-%s" .Release.Name $name | trunc 63 | trimSuffix "-" }}
-{{- end }}
-{{- end }}
-{{- end }}
-
-{{/*
-The version of the application to be deployed
-*/}}
-{{- define "delete-stale-instances.version" -}}
-{{- if .Values.global }}
-{{- .Values.image.tag | default .Values.global.version | default .Chart.AppVersion }}
-{{- else }}
-{{- .Values.image.tag | default .Chart.AppVersion }}
-{{- end }}
-{{- end }}
-
-{{/*
-Create chart name and version as used by the chart label.
-*/}}
-{{- define "delete-stale-instances.chart" -}}
-{{- printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" | trunc 63 | trimSuffix "-" }}
-{{- end }}
-
-{{/*
-Common labels
-*/}}
-{{- define "delete-stale-instances.labels" -}}
-helm.sh/chart: {{ include "delete-stale-instances.chart" . }}
-{{ include "delete-stale-instances.selectorLabels" . }}
-app.kubernetes.io/version: {{ include "delete-stale-instances.version" . | quote }}
-app.kubernetes.io/managed-by: {{ .Release.Service }}
-{{- end }}
-
-{{/*
-Selector labels
-*/}}
-{{- define "delete-stale-instances.selectorLabels" -}}
-app.kubernetes.io/name: {{ include "delete-stale-instances.name" . }}
-app.kubernetes.io/instance: {{ .Release.Name }}
-{{- end }}
@@ -18,15 +18,24 @@ package bastion_controller
 import (
 	"context"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	crownlabsalpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
 )
 
 // BastionReconciler reconciles a Bastion object.
@@ -35,13 +44,22 @@ type BastionReconciler struct {
 	Scheme             *runtime.Scheme
 	AuthorizedKeysPath string
 
+	// UserCAKey is the public key of the SSH user certificate authority. If configured, the bastion
+	// trusts the certificates it issues to the tenants, rather than the public keys of the tenants.
+	UserCAKey string
+
 	// This function, if configured, is deferred at the beginning of the Reconcile.
 	// Specifically, it is meant to be set to GinkgoRecover during the tests,
 	// in order to lead to a controlled failure in case the Reconcile panics.
 	ReconcileDeferHook func()
+
+	// mutex serializes the updates of the authorized_keys file, which is shared among all tenants.
+	mutex sync.Mutex
 }
 
-// Reconcile reconciles the SSH keys of a Tenant resource.
+// Reconcile reconciles the SSH keys of a Tenant resource, restricting them
+// to the sole forwarding towards the Instances the Tenant can access, i.e.,
+// the ones it owns and the ones of the Workspaces it manages or assists.
 func (r *BastionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	if r.ReconcileDeferHook != nil {
 		defer r.ReconcileDeferHook()
@@ -58,6 +76,19 @@ func (r *BastionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
+	var options []string
+	if !deleted {
+		destinations, err := r.tenantDestinations(ctx, tenant)
+		if err != nil {
+			klog.Errorf("unable to retrieve the instances accessible by tenant %s: %v", req.Name, err)
+			return ctrl.Result{}, err
+		}
+		options = EntryOptions(destinations)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	var keys []string
 
 	if _, err := os.Stat(r.AuthorizedKeysPath); err == nil {
@@ -75,31 +106,109 @@ func (r *BastionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	if !deleted {
 		// if the event was NOT a deletion, add the tenant's keys. Otherwise nothing to do.
-		keys = composeAndMarkEntries(keys, tenant.Spec.PublicKeys, req.Name)
+		if r.UserCAKey != "" {
+			// the tenant is granted access with the certificates issued by the CA for its principal.
+			options = append(CertAuthorityOptions(forge.SSHTenantPrincipal(req.Name)), options...)
+			keys = composeAndMarkEntries(keys, []string{r.UserCAKey}, req.Name, options)
+		} else {
+			keys = composeAndMarkEntries(keys, tenant.Spec.PublicKeys, req.Name, options)
+		}
 	}
 
-	f, err := os.Create(r.AuthorizedKeysPath)
-	if err != nil {
-		klog.Errorf("unable to create the file authorized_keys: %v", err)
-		return ctrl.Result{}, nil
+	if err := writeFileAtomically(r.AuthorizedKeysPath, []byte(strings.Join(keys, string("\n")))); err != nil {
+		klog.Errorf("unable to write to authorized_keys: %v", err)
+		return ctrl.Result{}, err
 	}
 
-	defer closeFile(f)
+	return ctrl.Result{}, nil
+}
 
-	if len(keys) > 0 {
-		_, err = f.WriteString(strings.Join(keys, string("\n")))
-		if err != nil {
-			klog.Errorf("unable to write to authorized_keys: %v", err)
-			return ctrl.Result{}, nil
+// tenantDestinations returns the sorted IP addresses of the running Instances the given tenant can access,
+// according to the same rules enforced by the SSH certificate authority and by the bastion proxy.
+func (r *BastionReconciler) tenantDestinations(ctx context.Context, tenant *crownlabsalpha2.Tenant) ([]string, error) {
+	var instances crownlabsalpha2.InstanceList
+	if err := r.List(ctx, &instances); err != nil {
+		return nil, err
+	}
+
+	var destinations []string
+	for i := range instances.Items {
+		if forge.TenantCanAccessInstance(tenant, &instances.Items[i]) {
+			destinations = append(destinations, instanceDestinations(&instances.Items[i])...)
 		}
 	}
 
-	return ctrl.Result{}, nil
+	slices.Sort(destinations)
+	return slices.Compact(destinations), nil
+}
+
+// instanceDestinations returns the IP addresses of the environments of the given Instance, if running.
+func instanceDestinations(instance *crownlabsalpha2.Instance) []string {
+	if !instance.Spec.Running {
+		return nil
+	}
+
+	var destinations []string
+	if instance.Status.IP != "" {
+		destinations = append(destinations, instance.Status.IP)
+	}
+	for i := range instance.Status.Environments {
+		if ip := instance.Status.Environments[i].IP; ip != "" && ip != instance.Status.IP {
+			destinations = append(destinations, ip)
+		}
+	}
+	return destinations
+}
+
+// instanceToTenants maps an Instance to the reconcile requests of the Tenants which can access it,
+// i.e., the one owning it and the managers and assistants of the corresponding Workspace.
+func (r *BastionReconciler) instanceToTenants(ctx context.Context, obj client.Object) []reconcile.Request {
+	instance, ok := obj.(*crownlabsalpha2.Instance)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	if instance.Spec.Tenant.Name != "" {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: instance.Spec.Tenant.Name}})
+	}
+
+	workspace := forge.InstanceWorkspaceName(instance)
+	if workspace == "" {
+		return requests
+	}
+
+	var tenants crownlabsalpha2.TenantList
+	if err := r.List(ctx, &tenants, client.HasLabels{forge.GetWorkspaceTargetLabel(workspace)}); err != nil {
+		klog.Errorf("unable to retrieve the tenants of workspace %s: %v", workspace, err)
+		return requests
+	}
+
+	for i := range tenants.Items {
+		if tenants.Items[i].Name != instance.Spec.Tenant.Name && forge.TenantCanAccessInstance(&tenants.Items[i], instance) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: tenants.Items[i].Name}})
+		}
+	}
+	return requests
+}
+
+// instanceDestinationsChanged is a predicate filtering out the Instance updates not affecting the reachable destinations.
+var instanceDestinationsChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldInstance, oldOk := e.ObjectOld.(*crownlabsalpha2.Instance)
+		newInstance, newOk := e.ObjectNew.(*crownlabsalpha2.Instance)
+		return !oldOk || !newOk || oldInstance.Spec.Tenant.Name != newInstance.Spec.Tenant.Name ||
+			forge.InstanceWorkspaceName(oldInstance) != forge.InstanceWorkspaceName(newInstance) ||
+			!slices.Equal(instanceDestinations(oldInstance), instanceDestinations(newInstance))
+	},
 }
 
-// SetupWithManager registers a new controller for Tenant resources.
+// SetupWithManager registers a new controller for Tenant resources,
+// which is also triggered by the changes of the IP addresses of the Instances they can access.
 func (r *BastionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&crownlabsalpha2.Tenant{}).
+		Watches(&crownlabsalpha2.Instance{}, handler.EnqueueRequestsFromMapFunc(r.instanceToTenants),
+			builder.WithPredicates(instanceDestinationsChanged)).
 		Complete(r)
 }
@@ -17,13 +17,16 @@ package bastion_controller
 import (
 	"bytes"
 	"os"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	crownlabsalpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
 )
@@ -77,13 +80,13 @@ var _ = Describe("Bastion controller - creating two tenants", func() {
 		PubKeysToBeChecked = make(map[string][]string)
 
 		PublicKeysTenant1 = []string{
-			"ssh-ed25519 publicKeyString_1 comment_1",
-			"ssh-ed25519 publicKeyString_2 comment_2 with spaces",
-			"ssh-rsa publicKeyString_3",
+			testKeyEd25519A + " comment_1",
+			testKeyEd25519B + " comment_2 with spaces",
+			testKeyECDSA,
 			"invalid_entry",
 		}
 		PublicKeysTenant2 = []string{
-			"ssh-rsa abcdefghi comment",
+			testKeyEd25519D + " comment",
 		}
 
 		tenant1 := &crownlabsalpha2.Tenant{}
@@ -196,7 +199,7 @@ var _ = Describe("Bastion controller - creating two tenants", func() {
 				if err != nil {
 					return err
 				}
-				PublicKeysTenant1[0] = "ecdsa-sha2-nistp256 yet_another_public_key comment_3"
+				PublicKeysTenant1[0] = testKeyEd25519C + " comment_3"
 
 				createdTenant.Spec.PublicKeys = PublicKeysTenant1
 				return k8sClient.Update(ctx, createdTenant)
@@ -247,3 +250,164 @@ var _ = Describe("Bastion controller - creating two tenants", func() {
 	})
 
 })
+
+var _ = Describe("Bastion controller - restricting the destinations of a tenant", func() {
+
+	const (
+		tenantName   = "s33333"
+		namespace    = "tenant-s33333"
+		instanceName = "instance"
+		instanceIP   = "10.0.0.1"
+
+		testFile = "./authorized_keys_test"
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	var instance *crownlabsalpha2.Instance
+
+	// entryOf returns the entry of the tenant in the file, if any.
+	entryOf := func() string {
+		data, err := os.ReadFile(testFile)
+		if err != nil {
+			return ""
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasSuffix(line, " "+tenantName) {
+				return line
+			}
+		}
+		return ""
+	}
+
+	BeforeEach(func() {
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &crownlabsalpha2.Tenant{
+			ObjectMeta: metav1.ObjectMeta{Name: tenantName},
+			Spec: crownlabsalpha2.TenantSpec{
+				FirstName:  "Luigi",
+				LastName:   "Verdi",
+				Email:      "luigi.verdi@fakemail.com",
+				Workspaces: []crownlabsalpha2.TenantWorkspaceEntry{},
+				PublicKeys: []string{testKeyEd25519C + " comment_4"},
+			},
+		})).To(Succeed())
+
+		instance = &crownlabsalpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: instanceName, Namespace: namespace},
+			Spec: crownlabsalpha2.InstanceSpec{
+				Template: crownlabsalpha2.GenericRef{Name: "template", Namespace: "workspace-test"},
+				Tenant:   crownlabsalpha2.GenericRef{Name: tenantName},
+				Running:  true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).To(Succeed())
+	})
+
+	It("Should restrict the keys to the forwarding towards the running instances of the tenant", func() {
+		By("Checking that the forwarding is disabled while the instance has no IP address")
+		Eventually(entryOf, timeout, interval).Should(Equal("restrict " + testKeyEd25519C + " " + tenantName))
+
+		By("Checking that the forwarding is permitted once the instance gets an IP address")
+		instance.Status.IP = instanceIP
+		Expect(k8sClient.Status().Update(ctx, instance)).To(Succeed())
+		Eventually(entryOf, timeout, interval).Should(Equal(
+			`restrict,port-forwarding,permitopen="` + instanceIP + `:22" ` + testKeyEd25519C + " " + tenantName))
+
+		By("Checking that the forwarding is disabled again once the instance is stopped")
+		Expect(retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+				return err
+			}
+			instance.Spec.Running = false
+			return k8sClient.Update(ctx, instance)
+		})).To(Succeed())
+		Eventually(entryOf, timeout, interval).Should(Equal("restrict " + testKeyEd25519C + " " + tenantName))
+	})
+})
+
+var _ = Describe("Bastion controller - granting the access to the managers of a workspace", func() {
+
+	const (
+		ownerName    = "s44444"
+		managerName  = "s55555"
+		namespace    = "tenant-s44444"
+		instanceName = "instance"
+		instanceIP   = "10.0.0.2"
+
+		testFile = "./authorized_keys_test"
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	var instance *crownlabsalpha2.Instance
+
+	// entryOf returns the entry of the given tenant in the file, if any.
+	entryOf := func(tenantName string) func() string {
+		return func() string {
+			data, err := os.ReadFile(testFile)
+			if err != nil {
+				return ""
+			}
+			for _, line := range strings.Split(string(data), "\n") {
+				if strings.HasSuffix(line, " "+tenantName) {
+					return line
+				}
+			}
+			return ""
+		}
+	}
+
+	BeforeEach(func() {
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &crownlabsalpha2.Tenant{
+			ObjectMeta: metav1.ObjectMeta{Name: ownerName},
+			Spec: crownlabsalpha2.TenantSpec{
+				FirstName:  "Anna",
+				LastName:   "Neri",
+				Email:      "anna.neri@fakemail.com",
+				Workspaces: []crownlabsalpha2.TenantWorkspaceEntry{{Name: "test", Role: crownlabsalpha2.User}},
+				PublicKeys: []string{testKeyEd25519A + " comment_5"},
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &crownlabsalpha2.Tenant{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   managerName,
+				Labels: map[string]string{crownlabsalpha2.WorkspaceLabelPrefix + "test": string(crownlabsalpha2.Manager)},
+			},
+			Spec: crownlabsalpha2.TenantSpec{
+				FirstName:  "Paolo",
+				LastName:   "Gialli",
+				Email:      "paolo.gialli@fakemail.com",
+				Workspaces: []crownlabsalpha2.TenantWorkspaceEntry{{Name: "test", Role: crownlabsalpha2.Manager}},
+				PublicKeys: []string{testKeyEd25519B + " comment_6"},
+			},
+		})).To(Succeed())
+
+		instance = &crownlabsalpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instanceName,
+				Namespace: namespace,
+				Labels:    map[string]string{"crownlabs.polito.it/workspace": "test"},
+			},
+			Spec: crownlabsalpha2.InstanceSpec{
+				Template: crownlabsalpha2.GenericRef{Name: "template", Namespace: "workspace-test"},
+				Tenant:   crownlabsalpha2.GenericRef{Name: ownerName},
+				Running:  true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).To(Succeed())
+	})
+
+	It("Should permit the forwarding towards the instances of the workspace to its managers", func() {
+		Eventually(entryOf(managerName), timeout, interval).Should(Equal("restrict " + testKeyEd25519B + " " + managerName))
+
+		instance.Status.IP = instanceIP
+		Expect(k8sClient.Status().Update(ctx, instance)).To(Succeed())
+
+		Eventually(entryOf(ownerName), timeout, interval).Should(Equal(
+			`restrict,port-forwarding,permitopen="` + instanceIP + `:22" ` + testKeyEd25519A + " " + ownerName))
+		Eventually(entryOf(managerName), timeout, interval).Should(Equal(
+			`restrict,port-forwarding,permitopen="` + instanceIP + `:22" ` + testKeyEd25519B + " " + managerName))
+	})
+})
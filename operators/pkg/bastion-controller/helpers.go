@@ -16,12 +16,20 @@ package bastion_controller
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"golang.org/x/crypto/ssh"
 	"k8s.io/klog/v2"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
 )
 
+// sshPort is the port the tenants are allowed to reach on their instances through the bastion.
+const sshPort = 22
+
 func closeFile(f *os.File) {
 	if err := f.Close(); err != nil {
 		klog.Errorf("unable to close the file authorized_keys: %v", err)
@@ -37,43 +45,135 @@ func removeKey(s []string, i int) []string {
 	return s[:len(s)-1]
 }
 
-// AuthorizedKeysEntry is a structure containing the three different fields
+// AuthorizedKeysEntry is a structure containing the different fields
 // of an entry of the .ssh/authorized_keys file.
 type AuthorizedKeysEntry struct {
+	// Options are the (optional) OpenSSH options restricting the usage of the key.
+	Options []string
+
 	Algo, Key, ID string
 }
 
 // Decompose converts a string into an AuthorizedKeysEntry object.
 func Decompose(entry string) (AuthorizedKeysEntry, error) {
+	var options []string
+	if fields := splitOptions(entry); len(fields) > 0 && !isKeyType(fields[0]) {
+		// the entry starts with the options field, which is then followed by the key.
+		options = fields[1:]
+		entry = strings.TrimPrefix(entry[len(fields[0]):], " ")
+	}
+
 	entryComponents := strings.SplitN(entry, string(" "), 3)
 	if len(entryComponents) == 3 {
 		return AuthorizedKeysEntry{
-			Algo: entryComponents[0],
-			Key:  entryComponents[1],
-			ID:   entryComponents[2],
+			Options: options,
+			Algo:    entryComponents[0],
+			Key:     entryComponents[1],
+			ID:      entryComponents[2],
 		}, nil
 	}
 
 	return AuthorizedKeysEntry{}, errors.New("invalid entry")
 }
 
-// Create converts a string and an id into an AuthorizedKeysEntry object.
+// Create converts a public key of a tenant and an id into an AuthorizedKeysEntry object.
+// The key is parsed and re-encoded, so that its content cannot inject options or further entries,
+// while the keys which are not valid, carry options or contain multiple entries are rejected.
 func Create(entry, id string) (AuthorizedKeysEntry, error) {
-	entryComponents := strings.SplitN(entry, string(" "), 3)
-	if len(entryComponents) == 3 || len(entryComponents) == 2 {
-		return AuthorizedKeysEntry{
-			Algo: entryComponents[0],
-			Key:  entryComponents[1],
-			ID:   id,
-		}, nil
+	key, err := forge.ParseSSHPublicKey(entry)
+	if err != nil {
+		return AuthorizedKeysEntry{}, err
 	}
 
-	return AuthorizedKeysEntry{}, errors.New("invalid entry")
+	// MarshalAuthorizedKey returns the "algo key" pair, followed by a newline.
+	entryComponents := strings.Fields(string(ssh.MarshalAuthorizedKey(key)))
+	return AuthorizedKeysEntry{
+		Algo: entryComponents[0],
+		Key:  entryComponents[1],
+		ID:   id,
+	}, nil
 }
 
 // Compose an AuthorizedKeysEntry object into a string.
 func (e *AuthorizedKeysEntry) Compose() string {
-	return e.Algo + " " + e.Key + " " + e.ID
+	entry := e.Algo + " " + e.Key + " " + e.ID
+	if len(e.Options) > 0 {
+		entry = strings.Join(e.Options, ",") + " " + entry
+	}
+	return entry
+}
+
+// EntryOptions returns the OpenSSH options restricting a key to the sole forwarding
+// towards the SSH port of the given destinations. The restrict option disables, among
+// others, the allocation of a pty and the execution of commands on the bastion, and if
+// no destinations are given the forwarding is disabled as well.
+func EntryOptions(destinations []string) []string {
+	options := []string{"restrict"}
+	if len(destinations) == 0 {
+		return options
+	}
+
+	options = append(options, "port-forwarding")
+	for _, destination := range destinations {
+		options = append(options, fmt.Sprintf("permitopen=%q", fmt.Sprintf("%s:%d", destination, sshPort)))
+	}
+	return options
+}
+
+// CertAuthorityOptions returns the OpenSSH options marking a key as a certificate authority,
+// trusted for the certificates issued to the given principal.
+func CertAuthorityOptions(principal string) []string {
+	return []string{"cert-authority", fmt.Sprintf("principals=%q", principal)}
+}
+
+// isKeyType returns whether the given field corresponds to a key algorithm, rather than to the options.
+func isKeyType(field string) bool {
+	return strings.HasPrefix(field, "ssh-") || strings.HasPrefix(field, "ecdsa-") || strings.HasPrefix(field, "sk-")
+}
+
+// splitOptions splits the first field of an entry at the commas not enclosed in double quotes.
+// The first element of the returned slice is the whole field, followed by the single options.
+func splitOptions(entry string) []string {
+	var options []string
+	quoted, start := false, 0
+	for i, c := range entry {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			options = append(options, entry[start:i])
+			start = i + 1
+		case c == ' ' && !quoted:
+			return append([]string{entry[:i]}, append(options, entry[start:i])...)
+		}
+	}
+	return nil
+}
+
+// writeFileAtomically replaces the content of the given file, writing a temporary file
+// in the same directory and then renaming it, so that readers never observe a partial content.
+func writeFileAtomically(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the removal fails if the file has already been renamed, hence the error is ignored.
+		_ = os.Remove(f.Name())
+	}()
+	defer closeFile(f)
+
+	// the file must be readable by sshd, which runs as a different user.
+	if err := f.Chmod(0o644); err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
 }
 
 func decomposeAndPurgeEntries(keys []string, tenantID string) []string {
@@ -96,13 +196,14 @@ func decomposeAndPurgeEntries(keys []string, tenantID string) []string {
 	return keys
 }
 
-func composeAndMarkEntries(keys, tenantKeys []string, tenantID string) []string {
+func composeAndMarkEntries(keys, tenantKeys []string, tenantID string, options []string) []string {
 	for i := range tenantKeys {
 		entry, err := Create(tenantKeys[i], tenantID)
 		if err != nil {
 			klog.Warningf("Skipping key %s: %s", tenantKeys[i], err.Error())
 			continue
 		}
+		entry.Options = options
 		keys = append(keys, entry.Compose())
 	}
 	return keys
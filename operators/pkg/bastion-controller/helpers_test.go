@@ -0,0 +1,112 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion_controller
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Valid public keys of the tenants, used throughout the tests.
+const (
+	testKeyEd25519A = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILTt8yqcYTpoHYckGUirDXAVbKUiURms7F9xsz6Z7HXK"
+	testKeyEd25519B = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIJkCGuJOEnWtakV0VdYdHzzz/NRKrotDln0ZWFJgeQVc"
+	testKeyEd25519C = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGvjgJUwA7H9D2E9cq9ovaHZS/1ykQoAs+joUmTdVGTA"
+	testKeyEd25519D = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBJzK9uJKcQSdaJcwGaUG/mWkot0q2w5602MGVgHbdWb"
+	testKeyECDSA    = "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBKJuq31MIZaqGRTsOqWkY51JQfYlHWYNFr1C0q2qZCN2unOYzV7oTEcvvZ0s4TLUWlqDSh1lWSFRT36Cc0+oVx4="
+)
+
+var _ = Describe("Authorized keys entries", func() {
+	DescribeTable("Decomposing an entry",
+		func(entry string, expected AuthorizedKeysEntry) {
+			decomposed, err := Decompose(entry)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decomposed).To(Equal(expected))
+			Expect(decomposed.Compose()).To(Equal(entry))
+		},
+		Entry("without options", "ssh-ed25519 key s11111",
+			AuthorizedKeysEntry{Algo: "ssh-ed25519", Key: "key", ID: "s11111"}),
+		Entry("with a single option", "restrict ssh-rsa key s11111",
+			AuthorizedKeysEntry{Options: []string{"restrict"}, Algo: "ssh-rsa", Key: "key", ID: "s11111"}),
+		Entry("with multiple options", `restrict,port-forwarding,permitopen="10.0.0.1:22",permitopen="10.0.0.2:22" ecdsa-sha2-nistp256 key s11111`,
+			AuthorizedKeysEntry{
+				Options: []string{"restrict", "port-forwarding", `permitopen="10.0.0.1:22"`, `permitopen="10.0.0.2:22"`},
+				Algo:    "ecdsa-sha2-nistp256", Key: "key", ID: "s11111",
+			}),
+		Entry("trusting a certificate authority", `cert-authority,principals="tenant:s11111",restrict ssh-ed25519 ca-key s11111`,
+			AuthorizedKeysEntry{
+				Options: []string{"cert-authority", `principals="tenant:s11111"`, "restrict"},
+				Algo:    "ssh-ed25519", Key: "ca-key", ID: "s11111",
+			}),
+	)
+
+	It("Should reject the invalid entries", func() {
+		_, err := Decompose("invalid_entry")
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("Creating an entry from a public key",
+		func(key string, expected AuthorizedKeysEntry) {
+			Expect(Create(key, "s11111")).To(Equal(expected))
+		},
+		Entry("without comment", testKeyEd25519A,
+			AuthorizedKeysEntry{Algo: "ssh-ed25519", Key: strings.Fields(testKeyEd25519A)[1], ID: "s11111"}),
+		Entry("with a comment, which is replaced by the id", testKeyECDSA+" comment with spaces",
+			AuthorizedKeysEntry{Algo: "ecdsa-sha2-nistp256", Key: strings.Fields(testKeyECDSA)[1], ID: "s11111"}),
+	)
+
+	DescribeTable("Rejecting the public keys which could inject options or entries",
+		func(key string) {
+			_, err := Create(key, "s11111")
+			Expect(err).To(HaveOccurred())
+
+			keys := composeAndMarkEntries(nil, []string{key}, "s11111", EntryOptions([]string{"10.0.0.1"}))
+			Expect(keys).To(BeEmpty())
+		},
+		Entry("when not a valid key", "ssh-ed25519 publicKeyString comment"),
+		Entry("when carrying options", `command="/bin/sh" `+testKeyEd25519A),
+		Entry("when prefixed by a fake algorithm", `permitopen="*:*" `+testKeyEd25519A+" comment"),
+		Entry("when followed by a further entry", testKeyEd25519A+" comment\n"+testKeyEd25519B+" s22222"),
+	)
+
+	It("Should restrict the forwarding to the given destinations", func() {
+		Expect(EntryOptions(nil)).To(Equal([]string{"restrict"}))
+		Expect(EntryOptions([]string{"10.0.0.1"})).To(Equal([]string{"restrict", "port-forwarding", `permitopen="10.0.0.1:22"`}))
+	})
+
+	It("Should trust the certificate authority for the given principal", func() {
+		Expect(CertAuthorityOptions("tenant:s11111")).To(Equal([]string{"cert-authority", `principals="tenant:s11111"`}))
+	})
+
+	It("Should atomically replace the content of the file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "authorized_keys")
+		Expect(os.WriteFile(path, []byte("old content"), 0o600)).To(Succeed())
+
+		Expect(writeFileAtomically(path, []byte("new content"))).To(Succeed())
+		Expect(os.ReadFile(path)).To(Equal([]byte("new content")))
+
+		info, err := os.Stat(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o644)))
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,64 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion_ssh_tracker
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// activityRecorder stores the last time SSH traffic was detected towards each destination IP.
+type activityRecorder struct {
+	mutex sync.RWMutex
+	last  map[string]time.Time
+}
+
+// newActivityRecorder creates a new, empty, activityRecorder.
+func newActivityRecorder() *activityRecorder {
+	return &activityRecorder{last: map[string]time.Time{}}
+}
+
+// record records the detection of SSH traffic towards the given destination.
+func (r *activityRecorder) record(destinationIP string, timestamp time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.last[destinationIP] = timestamp
+}
+
+// prune forgets the destinations with no SSH traffic detected since the given time,
+// to prevent the entries of the IPs no longer in use from accumulating.
+func (r *activityRecorder) prune(before time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for ip, timestamp := range r.last {
+		if timestamp.Before(before) {
+			delete(r.last, ip)
+		}
+	}
+}
+
+// ServeHTTP exposes, in JSON format, the last time SSH traffic was detected towards each destination IP.
+func (r *activityRecorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(r.last); err != nil {
+		log.Printf("Failed writing activity response: %v", err)
+	}
+}
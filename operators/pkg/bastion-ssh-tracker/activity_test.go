@@ -0,0 +1,61 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion_ssh_tracker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("The activity recorder", func() {
+	var (
+		recorder *activityRecorder
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		recorder = newActivityRecorder()
+		now = time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+		recorder.record("10.0.0.1", now.Add(-time.Hour))
+		recorder.record("10.0.0.2", now.Add(-48*time.Hour))
+	})
+
+	It("Should expose the last activity towards each destination", func() {
+		response := httptest.NewRecorder()
+		recorder.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/activity", http.NoBody))
+		Expect(response.Code).To(Equal(http.StatusOK))
+
+		var last map[string]time.Time
+		Expect(json.Unmarshal(response.Body.Bytes(), &last)).To(Succeed())
+		Expect(last).To(HaveLen(2))
+		Expect(last).To(HaveKeyWithValue("10.0.0.1", BeTemporally("==", now.Add(-time.Hour))))
+	})
+
+	It("Should update the last activity towards a known destination", func() {
+		recorder.record("10.0.0.2", now)
+		Expect(recorder.last).To(HaveKeyWithValue("10.0.0.2", now))
+	})
+
+	It("Should forget the destinations with no activity since the given time", func() {
+		recorder.prune(now.Add(-24 * time.Hour))
+		Expect(recorder.last).To(HaveKey("10.0.0.1"))
+		Expect(recorder.last).ToNot(HaveKey("10.0.0.2"))
+	})
+})
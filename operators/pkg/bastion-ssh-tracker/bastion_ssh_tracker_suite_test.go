@@ -0,0 +1,36 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion_ssh_tracker
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBastionSSHTracker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bastion SSH Tracker Suite")
+}
+
+// resetMetrics clears the metrics exposed by the tracker, to make the tests independent of each other.
+func resetMetrics() {
+	sshConnections.Reset()
+	sshActiveSessions.Reset()
+	sshSessionBytes.Reset()
+	sshSessionDuration.Reset()
+	sshInstanceLastActivity.Reset()
+}
@@ -18,16 +18,74 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// instanceLabels are the labels identifying the instance a connection is directed to (empty if unknown).
+// They are prefixed to avoid clashing with the "namespace" and "instance" target labels added by Prometheus.
+var instanceLabels = []string{"instance_namespace", "instance_name", "tenant", "workspace"}
+
 var (
 	sshConnections = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "bastion_ssh_connections",
 			Help: "SSH connections detected from bastion to a target",
 		},
-		[]string{"destination_ip", "destination_port"},
+		append([]string{"destination_ip", "destination_port"}, instanceLabels...),
+	)
+
+	sshActiveSessions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bastion_ssh_active_sessions",
+			Help: "SSH sessions currently open from bastion to a target instance",
+		},
+		instanceLabels,
+	)
+
+	sshSessionBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bastion_ssh_session_bytes_total",
+			Help: "Bytes exchanged within the SSH sessions between bastion and a target instance, by direction (sent or received by the bastion)",
+		},
+		append([]string{"direction"}, instanceLabels...),
+	)
+
+	sshSessionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "bastion_ssh_session_duration_seconds",
+			Help:    "Duration of the terminated SSH sessions from bastion to a target instance",
+			Buckets: []float64{10, 60, 300, 900, 1800, 3600, 7200, 14400, 28800},
+		},
+		[]string{"workspace"},
+	)
+
+	sshInstanceLastActivity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bastion_ssh_instance_last_activity_timestamp_seconds",
+			Help: "Last time SSH traffic from bastion to a target instance was detected, as unix timestamp",
+		},
+		instanceLabels,
+	)
+
+	sshDroppedEvents = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "bastion_ssh_tracker_dropped_events_total",
+			Help: "Packets detected by the SSH tracker and dropped because the event queue was full",
+		},
 	)
 )
 
 func init() {
-	prometheus.MustRegister(sshConnections)
+	prometheus.MustRegister(sshConnections, sshActiveSessions, sshSessionBytes, sshSessionDuration, sshInstanceLastActivity, sshDroppedEvents)
+}
+
+// instanceLabelValues returns the values of the instanceLabels for the given instance.
+func instanceLabelValues(ref InstanceRef) []string {
+	return []string{ref.Namespace, ref.Name, ref.Tenant, ref.Workspace}
+}
+
+// deleteInstanceMetrics removes the per-instance metrics of the given instance, once deleted.
+func deleteInstanceMetrics(ref InstanceRef) {
+	labels := prometheus.Labels{"instance_namespace": ref.Namespace, "instance_name": ref.Name}
+	sshConnections.DeletePartialMatch(labels)
+	sshActiveSessions.DeletePartialMatch(labels)
+	sshSessionBytes.DeletePartialMatch(labels)
+	sshInstanceLastActivity.DeletePartialMatch(labels)
 }
@@ -0,0 +1,179 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion_ssh_tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+// InstanceRef identifies the Instance an IP address belongs to.
+type InstanceRef struct {
+	Namespace string
+	Name      string
+	Tenant    string
+	Workspace string
+}
+
+// InstanceResolver maps the IP addresses of the instances (and of their environments) to the
+// corresponding Instance, keeping the association up to date through an informer.
+type InstanceResolver struct {
+	mutex sync.RWMutex
+	// byIP maps each IP address to the instance it belongs to.
+	byIP map[string]InstanceRef
+	// byInstance maps each instance (namespace/name) to the IP addresses it currently owns.
+	byInstance map[string][]string
+}
+
+// NewInstanceResolver creates a new, empty, InstanceResolver.
+func NewInstanceResolver() *InstanceResolver {
+	return &InstanceResolver{
+		byIP:       map[string]InstanceRef{},
+		byInstance: map[string][]string{},
+	}
+}
+
+// Start starts the informer watching the instances, and waits for the initial synchronization.
+// The informer is stopped when the given context is canceled.
+func (r *InstanceResolver) Start(ctx context.Context, cfg *rest.Config) error {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clv1alpha2.AddToScheme(scheme))
+
+	instanceCache, err := cache.New(cfg, cache.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed creating cache: %w", err)
+	}
+
+	informer, err := instanceCache.GetInformer(ctx, &clv1alpha2.Instance{})
+	if err != nil {
+		return fmt.Errorf("failed retrieving instances informer: %w", err)
+	}
+
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { r.update(obj) },
+		UpdateFunc: func(_, obj interface{}) { r.update(obj) },
+		DeleteFunc: r.delete,
+	}); err != nil {
+		return fmt.Errorf("failed registering instances event handler: %w", err)
+	}
+
+	go func() {
+		if err := instanceCache.Start(ctx); err != nil {
+			utilruntime.HandleError(fmt.Errorf("instances cache terminated: %w", err))
+		}
+	}()
+
+	if !instanceCache.WaitForCacheSync(ctx) {
+		return errors.New("failed waiting for instances cache sync")
+	}
+	return nil
+}
+
+// Resolve returns the instance the given IP address belongs to, if any.
+func (r *InstanceResolver) Resolve(ip string) (InstanceRef, bool) {
+	if r == nil {
+		return InstanceRef{}, false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	ref, found := r.byIP[ip]
+	return ref, found
+}
+
+// update refreshes the IP addresses associated with the given instance.
+func (r *InstanceResolver) update(obj interface{}) {
+	instance, ok := obj.(*clv1alpha2.Instance)
+	if !ok {
+		return
+	}
+
+	ref := newInstanceRef(instance)
+
+	ips := make([]string, 0, len(instance.Status.Environments)+1)
+	if instance.Status.IP != "" {
+		ips = append(ips, instance.Status.IP)
+	}
+	for i := range instance.Status.Environments {
+		if instance.Status.Environments[i].IP != "" {
+			ips = append(ips, instance.Status.Environments[i].IP)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.removeLocked(instanceKey(ref))
+	for _, ip := range ips {
+		r.byIP[ip] = ref
+	}
+	r.byInstance[instanceKey(ref)] = ips
+}
+
+// delete removes the IP addresses and the metrics associated with the given instance.
+func (r *InstanceResolver) delete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	instance, ok := obj.(*clv1alpha2.Instance)
+	if !ok {
+		return
+	}
+
+	ref := newInstanceRef(instance)
+
+	r.mutex.Lock()
+	r.removeLocked(instanceKey(ref))
+	r.mutex.Unlock()
+
+	deleteInstanceMetrics(ref)
+}
+
+// removeLocked removes the IP addresses associated with the given instance. It must be called with the lock held.
+func (r *InstanceResolver) removeLocked(key string) {
+	for _, ip := range r.byInstance[key] {
+		// The IP address might have been already reassigned to a different instance.
+		if instanceKey(r.byIP[ip]) == key {
+			delete(r.byIP, ip)
+		}
+	}
+	delete(r.byInstance, key)
+}
+
+// newInstanceRef returns the InstanceRef corresponding to the given instance.
+func newInstanceRef(instance *clv1alpha2.Instance) InstanceRef {
+	return InstanceRef{
+		Namespace: instance.GetNamespace(),
+		Name:      instance.GetName(),
+		Tenant:    instance.Spec.Tenant.Name,
+		Workspace: forge.InstanceWorkspaceName(instance),
+	}
+}
+
+// instanceKey returns the key identifying the given instance.
+func instanceKey(ref InstanceRef) string {
+	return ref.Namespace + "/" + ref.Name
+}
@@ -0,0 +1,124 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion_ssh_tracker
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+var _ = Describe("The instance resolver", func() {
+	var (
+		resolver *InstanceResolver
+		instance *clv1alpha2.Instance
+		ref      InstanceRef
+	)
+
+	BeforeEach(func() {
+		resetMetrics()
+		resolver = NewInstanceResolver()
+		instance = &clv1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "instance", Namespace: "tenant-tester",
+				Labels: map[string]string{"crownlabs.polito.it/workspace": "netgroup"},
+			},
+			Spec: clv1alpha2.InstanceSpec{Tenant: clv1alpha2.GenericRef{Name: "tester"}},
+			Status: clv1alpha2.InstanceStatus{
+				IP: "10.0.0.1",
+				Environments: []clv1alpha2.InstanceEnvironmentStatus{
+					{Name: "app", IP: "10.0.0.1"}, {Name: "db", IP: "10.0.0.2"}, {Name: "pending"},
+				},
+			},
+		}
+		ref = InstanceRef{Namespace: "tenant-tester", Name: "instance", Tenant: "tester", Workspace: "netgroup"}
+	})
+
+	// resolved returns the instance the given IP address is resolved to, or nil if not found.
+	resolved := func(ip string) *InstanceRef {
+		if ref, found := resolver.Resolve(ip); found {
+			return &ref
+		}
+		return nil
+	}
+
+	JustBeforeEach(func() { resolver.update(instance) })
+
+	It("Should resolve the IP addresses of the instance and of its environments", func() {
+		Expect(resolved("10.0.0.1")).To(Equal(&ref))
+		Expect(resolved("10.0.0.2")).To(Equal(&ref))
+	})
+
+	It("Should not resolve unknown IP addresses", func() {
+		Expect(resolved("10.0.0.3")).To(BeNil())
+	})
+
+	It("Should forget the IP addresses no longer owned by the instance", func() {
+		instance.Status.IP = "10.0.0.3"
+		instance.Status.Environments = nil
+		resolver.update(instance)
+
+		Expect(resolved("10.0.0.3")).To(Equal(&ref))
+		Expect(resolved("10.0.0.1")).To(BeNil())
+		Expect(resolved("10.0.0.2")).To(BeNil())
+	})
+
+	It("Should not forget the IP addresses reassigned to a different instance", func() {
+		other := instance.DeepCopy()
+		other.Name = "other"
+		other.Status.IP = "10.0.0.2"
+		other.Status.Environments = nil
+		resolver.update(other)
+
+		resolver.delete(instance)
+		Expect(resolved("10.0.0.2")).To(HaveField("Name", "other"))
+		Expect(resolved("10.0.0.1")).To(BeNil())
+	})
+
+	It("Should ignore the objects which are not instances", func() {
+		resolver.update(&clv1alpha2.Template{})
+		resolver.delete(&clv1alpha2.Template{})
+		Expect(resolved("10.0.0.1")).To(Equal(&ref))
+	})
+
+	DescribeTable("Should forget the instance once deleted",
+		func(deleted func(*clv1alpha2.Instance) interface{}) {
+			labels := instanceLabelValues(ref)
+			sshActiveSessions.WithLabelValues(labels...).Inc()
+			sshInstanceLastActivity.WithLabelValues(instanceLabelValues(InstanceRef{})...).Inc()
+
+			resolver.delete(deleted(instance))
+
+			Expect(resolved("10.0.0.1")).To(BeNil())
+			Expect(resolver.byInstance).To(BeEmpty())
+			Expect(testutil.CollectAndCount(sshActiveSessions)).To(Equal(0))
+			// The metrics not referring to the instance are preserved.
+			Expect(testutil.CollectAndCount(sshInstanceLastActivity)).To(Equal(1))
+		},
+		Entry("When the instance is received", func(instance *clv1alpha2.Instance) interface{} { return instance }),
+		Entry("When a tombstone is received", func(instance *clv1alpha2.Instance) interface{} {
+			return toolscache.DeletedFinalStateUnknown{Key: "tenant-tester/instance", Obj: instance}
+		}),
+	)
+
+	It("Should not resolve any IP address when nil", func() {
+		resolver = nil
+		Expect(resolved("10.0.0.1")).To(BeNil())
+	})
+})
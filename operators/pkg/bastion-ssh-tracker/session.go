@@ -0,0 +1,189 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion_ssh_tracker
+
+import (
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// activityResolution is the minimum interval between two consecutive updates of the
+// last activity of a session, to avoid updating it for every packet.
+const activityResolution = 10 * time.Second
+
+// ConnectionKey identifies a TCP connection, oriented from the bastion (source) towards the target (destination).
+type ConnectionKey struct {
+	SourceIP   string
+	SourcePort uint16
+	DestIP     string
+	DestPort   uint16
+}
+
+// SSHConnection represents an SSH connection, tracked from the SYN until the FIN/RST packets.
+type SSHConnection struct {
+	ConnectionKey
+	StartTime time.Time
+	// LastSeen is the time the last packet belonging to the connection was detected.
+	LastSeen time.Time
+	// BytesSent and BytesReceived are the amount of TCP payload sent by the bastion and by the target.
+	BytesSent     uint64
+	BytesReceived uint64
+	// Instance is the instance the destination IP belongs to, if known.
+	Instance *InstanceRef
+
+	finSent, finReceived bool
+	lastRecorded         time.Time
+
+	active                   prometheus.Gauge
+	lastActivity             prometheus.Gauge
+	sentCounter, recvCounter prometheus.Counter
+}
+
+// sessionTable stores the SSH connections currently tracked. It is not safe for concurrent use,
+// since all the events are processed by the same goroutine.
+type sessionTable struct {
+	sessions map[ConnectionKey]*SSHConnection
+	resolver *InstanceResolver
+	activity *activityRecorder
+}
+
+// newSessionTable creates a new sessionTable, resolving the destinations through the given resolver (possibly nil),
+// and recording the last activity towards each of them in the given recorder.
+func newSessionTable(resolver *InstanceResolver, activity *activityRecorder) *sessionTable {
+	return &sessionTable{sessions: map[ConnectionKey]*SSHConnection{}, resolver: resolver, activity: activity}
+}
+
+// handle updates the state of the connection the given event belongs to.
+func (t *sessionTable) handle(event *ConnectionEvent) {
+	conn, found := t.sessions[event.Key]
+
+	switch {
+	case event.SYN && !event.ACK && event.Outbound:
+		// A new connection is being established (possibly reusing the same ports of a terminated one).
+		if found {
+			if !conn.finSent && !conn.finReceived {
+				// SYN retransmission.
+				return
+			}
+			t.close(conn, event.Timestamp)
+		}
+		conn = t.open(event, true)
+	case !found:
+		if event.FIN || event.RST {
+			return
+		}
+		// The connection has been established before the tracker started.
+		conn = t.open(event, false)
+	}
+
+	conn.LastSeen = event.Timestamp
+	if event.Outbound {
+		conn.BytesSent += uint64(event.PayloadLength)
+		conn.sentCounter.Add(float64(event.PayloadLength))
+		conn.finSent = conn.finSent || event.FIN
+	} else {
+		conn.BytesReceived += uint64(event.PayloadLength)
+		conn.recvCounter.Add(float64(event.PayloadLength))
+		conn.finReceived = conn.finReceived || event.FIN
+	}
+
+	if event.RST || (conn.finSent && conn.finReceived) {
+		t.close(conn, event.Timestamp)
+		return
+	}
+
+	if event.Timestamp.Sub(conn.lastRecorded) >= activityResolution {
+		t.recordActivity(conn, event.Timestamp)
+	}
+}
+
+// open starts tracking a new connection. The connections counter is incremented only for
+// the connections observed since the beginning (i.e., since the SYN packet).
+func (t *sessionTable) open(event *ConnectionEvent, established bool) *SSHConnection {
+	conn := &SSHConnection{ConnectionKey: event.Key, StartTime: event.Timestamp}
+
+	var ref InstanceRef
+	if resolved, found := t.resolver.Resolve(event.Key.DestIP); found {
+		ref = resolved
+		conn.Instance = &resolved
+	}
+
+	labels := instanceLabelValues(ref)
+	conn.active = sshActiveSessions.WithLabelValues(labels...)
+	conn.lastActivity = sshInstanceLastActivity.WithLabelValues(labels...)
+	conn.sentCounter = sshSessionBytes.WithLabelValues(append([]string{"sent"}, labels...)...)
+	conn.recvCounter = sshSessionBytes.WithLabelValues(append([]string{"received"}, labels...)...)
+
+	if established {
+		sshConnections.WithLabelValues(append([]string{conn.DestIP, strconv.Itoa(int(conn.DestPort))}, labels...)...).Inc()
+	}
+
+	log.Printf("New connection detected towards: %s (%s)", conn.destination(), conn.target())
+	conn.active.Inc()
+	t.sessions[conn.ConnectionKey] = conn
+	return conn
+}
+
+// close stops tracking the given connection, recording its duration.
+func (t *sessionTable) close(conn *SSHConnection, timestamp time.Time) {
+	delete(t.sessions, conn.ConnectionKey)
+
+	var workspace string
+	if conn.Instance != nil {
+		workspace = conn.Instance.Workspace
+	}
+
+	duration := conn.LastSeen.Sub(conn.StartTime)
+	sshSessionDuration.WithLabelValues(workspace).Observe(duration.Seconds())
+	conn.active.Dec()
+	t.recordActivity(conn, timestamp)
+
+	log.Printf("Connection terminated towards: %s (%s), duration %v, sent %d bytes, received %d bytes",
+		conn.destination(), conn.target(), duration.Round(time.Second), conn.BytesSent, conn.BytesReceived)
+}
+
+// expire closes the connections with no packets detected since longer than the given timeout
+// (e.g., because the FIN/RST packets have been missed).
+func (t *sessionTable) expire(now time.Time, timeout time.Duration) {
+	for _, conn := range t.sessions {
+		if now.Sub(conn.LastSeen) > timeout {
+			t.close(conn, conn.LastSeen)
+		}
+	}
+}
+
+// recordActivity records the last activity of the given connection.
+func (t *sessionTable) recordActivity(conn *SSHConnection, timestamp time.Time) {
+	conn.lastRecorded = timestamp
+	conn.lastActivity.Set(float64(timestamp.Unix()))
+	t.activity.record(conn.DestIP, timestamp)
+}
+
+// destination returns the address the connection is directed to.
+func (c *SSHConnection) destination() string {
+	return net.JoinHostPort(c.DestIP, strconv.Itoa(int(c.DestPort)))
+}
+
+// target returns a human-readable representation of the instance the connection is directed to.
+func (c *SSHConnection) target() string {
+	if c.Instance == nil {
+		return "unknown instance"
+	}
+	return "instance " + instanceKey(*c.Instance) + ", tenant " + c.Instance.Tenant
+}
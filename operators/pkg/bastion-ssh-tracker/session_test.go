@@ -0,0 +1,185 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion_ssh_tracker
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("The session table", func() {
+	const (
+		destIP  = "10.0.0.1"
+		timeout = time.Hour
+	)
+
+	var (
+		table    *sessionTable
+		resolver *InstanceResolver
+		activity *activityRecorder
+		start    time.Time
+		key      ConnectionKey
+		ref      InstanceRef
+	)
+
+	// event forges a new event of the tracked connection, detected the given time after the start.
+	event := func(outbound bool, offset time.Duration, payload int) *ConnectionEvent {
+		return &ConnectionEvent{Key: key, Outbound: outbound, ACK: true, PayloadLength: payload, Timestamp: start.Add(offset)}
+	}
+	syn := func() *ConnectionEvent {
+		ev := event(true, 0, 0)
+		ev.SYN, ev.ACK = true, false
+		return ev
+	}
+	fin := func(outbound bool, offset time.Duration) *ConnectionEvent {
+		ev := event(outbound, offset, 0)
+		ev.FIN = true
+		return ev
+	}
+
+	labels := func() []string { return instanceLabelValues(ref) }
+	active := func() float64 { return testutil.ToFloat64(sshActiveSessions.WithLabelValues(labels()...)) }
+	connections := func() float64 {
+		return testutil.ToFloat64(sshConnections.WithLabelValues(append([]string{destIP, "22"}, labels()...)...))
+	}
+	bytes := func(direction string) float64 {
+		return testutil.ToFloat64(sshSessionBytes.WithLabelValues(append([]string{direction}, labels()...)...))
+	}
+
+	BeforeEach(func() {
+		resetMetrics()
+		resolver = nil
+		activity = newActivityRecorder()
+		start = time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+		key = ConnectionKey{SourceIP: "10.0.0.100", SourcePort: 40000, DestIP: destIP, DestPort: 22}
+		ref = InstanceRef{}
+	})
+
+	JustBeforeEach(func() { table = newSessionTable(resolver, activity) })
+
+	Context("A connection tracked from the SYN packet", func() {
+		JustBeforeEach(func() {
+			table.handle(syn())
+			table.handle(event(true, time.Second, 100))
+			table.handle(event(false, 2*time.Second, 300))
+		})
+
+		It("Should be tracked", func() {
+			Expect(table.sessions).To(HaveKey(key))
+			Expect(table.sessions[key].BytesSent).To(BeNumerically("==", 100))
+			Expect(table.sessions[key].BytesReceived).To(BeNumerically("==", 300))
+		})
+
+		It("Should update the metrics", func() {
+			Expect(connections()).To(BeNumerically("==", 1))
+			Expect(active()).To(BeNumerically("==", 1))
+			Expect(bytes("sent")).To(BeNumerically("==", 100))
+			Expect(bytes("received")).To(BeNumerically("==", 300))
+		})
+
+		It("Should record the last activity", func() {
+			Expect(testutil.ToFloat64(sshInstanceLastActivity.WithLabelValues(labels()...))).To(BeNumerically("==", start.Unix()))
+			Expect(activity.last).To(HaveKeyWithValue(destIP, start))
+		})
+
+		It("Should ignore the SYN retransmissions", func() {
+			table.handle(syn())
+			Expect(connections()).To(BeNumerically("==", 1))
+			Expect(active()).To(BeNumerically("==", 1))
+		})
+
+		It("Should not be closed by the FIN packet of one side only", func() {
+			table.handle(fin(true, 3*time.Second))
+			Expect(table.sessions).To(HaveKey(key))
+			Expect(active()).To(BeNumerically("==", 1))
+		})
+
+		It("Should be closed by the FIN packets of both sides", func() {
+			table.handle(fin(true, 3*time.Second))
+			table.handle(fin(false, 4*time.Second))
+			Expect(table.sessions).ToNot(HaveKey(key))
+			Expect(active()).To(BeNumerically("==", 0))
+			Expect(testutil.CollectAndCount(sshSessionDuration)).To(Equal(1))
+		})
+
+		It("Should be closed by the RST packet", func() {
+			ev := event(false, 3*time.Second, 0)
+			ev.RST = true
+			table.handle(ev)
+			Expect(table.sessions).ToNot(HaveKey(key))
+			Expect(active()).To(BeNumerically("==", 0))
+		})
+
+		It("Should be replaced by a new connection reusing the same ports, once terminated", func() {
+			table.handle(fin(true, 3*time.Second))
+			table.handle(syn())
+			Expect(table.sessions).To(HaveKey(key))
+			Expect(connections()).To(BeNumerically("==", 2))
+			Expect(active()).To(BeNumerically("==", 1))
+		})
+
+		It("Should be expired once no packets are detected for longer than the timeout", func() {
+			table.expire(start.Add(timeout), timeout)
+			Expect(table.sessions).To(HaveKey(key))
+
+			table.expire(start.Add(timeout+time.Minute), timeout)
+			Expect(table.sessions).ToNot(HaveKey(key))
+			Expect(active()).To(BeNumerically("==", 0))
+		})
+	})
+
+	Context("A connection established before the tracker started", func() {
+		JustBeforeEach(func() { table.handle(event(false, 0, 50)) })
+
+		It("Should be tracked", func() {
+			Expect(table.sessions).To(HaveKey(key))
+			Expect(active()).To(BeNumerically("==", 1))
+			Expect(bytes("received")).To(BeNumerically("==", 50))
+		})
+
+		It("Should not be counted as a new connection", func() {
+			Expect(connections()).To(BeNumerically("==", 0))
+		})
+	})
+
+	Context("A FIN packet of a connection not tracked", func() {
+		JustBeforeEach(func() { table.handle(fin(true, 0)) })
+
+		It("Should be ignored", func() {
+			Expect(table.sessions).To(BeEmpty())
+			Expect(testutil.CollectAndCount(sshActiveSessions)).To(Equal(0))
+		})
+	})
+
+	Context("A connection towards an instance", func() {
+		BeforeEach(func() {
+			ref = InstanceRef{Namespace: "tenant-tester", Name: "instance", Tenant: "tester", Workspace: "netgroup"}
+			resolver = NewInstanceResolver()
+			resolver.byIP[destIP] = ref
+			resolver.byInstance[instanceKey(ref)] = []string{destIP}
+		})
+
+		JustBeforeEach(func() { table.handle(syn()) })
+
+		It("Should be attributed to the instance", func() {
+			Expect(table.sessions[key].Instance).To(Equal(&ref))
+			Expect(connections()).To(BeNumerically("==", 1))
+			Expect(active()).To(BeNumerically("==", 1))
+		})
+	})
+})
@@ -16,49 +16,73 @@
 package bastion_ssh_tracker
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/google/gopacket"
+	"github.com/google/gopacket/afpacket"
 	"github.com/google/gopacket/layers"
 )
 
+const (
+	// controlEventTimeout is the maximum time to wait for the event queue to accept the events carrying
+	// the SYN, FIN or RST flags, which are required to track the lifecycle of the connections.
+	controlEventTimeout = 100 * time.Millisecond
+	// readErrorBackoff is the time to wait before reading again after an unexpected capture error.
+	readErrorBackoff = 100 * time.Millisecond
+)
+
 // SSHTracker tracks SSH connections and emits metrics.
 type SSHTracker struct {
 	stopCh chan struct{}
 	done   chan struct{}
-}
 
-// SSHConnection represents an SSH connection.
-type SSHConnection struct {
-	SourceIP   string
-	SourcePort uint16
-	DestIP     string
-	DestPort   uint16
-	StartTime  time.Time
+	resolver       *InstanceResolver
+	sessionTimeout time.Duration
+
+	activity          *activityRecorder
+	activityRetention time.Duration
 }
 
-// ConnectionEvent represents an event related to SSH connection.
+// ConnectionEvent represents a TCP packet belonging to an SSH connection.
 type ConnectionEvent struct {
-	Conn *SSHConnection
+	Key ConnectionKey
+	// Outbound is true for the packets sent by the bastion towards the target.
+	Outbound bool
+	// The TCP flags relevant to track the connection lifecycle.
+	SYN, ACK, FIN, RST bool
+	// PayloadLength is the length of the TCP payload (independently of the snaplen).
+	PayloadLength int
+	Timestamp     time.Time
 }
 
-// processPacket is the handler called each time the BPF filter identifies a new
-// TCP session (TCP packet with only the SYN flag set). It extracts SSH
-// connection information from the packet layers and creates a ConnectionEvent
-// that will be processed by handleEvent to update Prometheus metrics.
+// processPacket is the handler called each time the BPF filter identifies a TCP
+// packet from or to the SSH port. It extracts the connection information from the
+// packet layers (either IPv4 or IPv6), orienting it from the bastion towards the
+// target, and creates a ConnectionEvent that will be processed by the session table
+// to track the connection and update Prometheus metrics.
 // This function acts as the bridge between raw network packet data and the
 // metrics collection system.
-func processPacket(packet gopacket.Packet, eventQueue chan ConnectionEvent) {
+func processPacket(packet gopacket.Packet, port uint16, timestamp time.Time, eventQueue chan ConnectionEvent) {
+	var srcIP, dstIP string
+	var ipPayloadLength int
+
 	// Get IP layer
-	ipLayer := packet.Layer(layers.LayerTypeIPv4)
-	if ipLayer == nil {
+	switch ip := packet.NetworkLayer().(type) {
+	case *layers.IPv4:
+		srcIP, dstIP = ip.SrcIP.String(), ip.DstIP.String()
+		ipPayloadLength = int(ip.Length) - int(ip.IHL)*4
+	case *layers.IPv6:
+		srcIP, dstIP = ip.SrcIP.String(), ip.DstIP.String()
+		ipPayloadLength = int(ip.Length)
+	default:
 		return
 	}
-	ip, _ := ipLayer.(*layers.IPv4)
 
 	// Get TCP layer
 	tcpLayer := packet.Layer(layers.LayerTypeTCP)
@@ -67,43 +91,105 @@ func processPacket(packet gopacket.Packet, eventQueue chan ConnectionEvent) {
 	}
 	tcp, _ := tcpLayer.(*layers.TCP)
 
-	srcIP := ip.SrcIP.String()
-	dstIP := ip.DstIP.String()
-	srcPort := uint16(tcp.SrcPort)
-	dstPort := uint16(tcp.DstPort)
-
-	newConn := &SSHConnection{
-		SourceIP:   srcIP,
-		SourcePort: srcPort,
-		DestIP:     dstIP,
-		DestPort:   dstPort,
-		StartTime:  time.Now(),
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	event := ConnectionEvent{
+		SYN:       tcp.SYN,
+		ACK:       tcp.ACK,
+		FIN:       tcp.FIN,
+		RST:       tcp.RST,
+		Timestamp: timestamp,
+		// The payload length is computed from the headers, since the packet may be truncated to the snaplen.
+		PayloadLength: max(ipPayloadLength-int(tcp.DataOffset)*4, 0),
+	}
+
+	switch {
+	case uint16(tcp.DstPort) == port:
+		event.Outbound = true
+		event.Key = ConnectionKey{SourceIP: srcIP, SourcePort: uint16(tcp.SrcPort), DestIP: dstIP, DestPort: uint16(tcp.DstPort)}
+	case uint16(tcp.SrcPort) == port:
+		event.Key = ConnectionKey{SourceIP: dstIP, SourcePort: uint16(tcp.DstPort), DestIP: srcIP, DestPort: uint16(tcp.SrcPort)}
+	default:
+		return
+	}
+
+	// Send to event queue, dropping (and accounting for) the data events in case the queue is full,
+	// to avoid stalling the capture loop when the session table lags behind. The control events are
+	// instead given some time to be accepted, as losing them would leave the connections open (until
+	// they expire) or would prevent tracking them altogether.
+	select {
+	case eventQueue <- event:
+		return
+	default:
+	}
+
+	if !event.SYN && !event.FIN && !event.RST {
+		sshDroppedEvents.Inc()
+		return
 	}
 
-	// Send to event queue
-	eventQueue <- ConnectionEvent{
-		Conn: newConn,
+	timer := time.NewTimer(controlEventTimeout)
+	defer timer.Stop()
+	select {
+	case eventQueue <- event:
+	case <-timer.C:
+		sshDroppedEvents.Inc()
 	}
 }
 
-// handleEvent function is called by the event processing goroutine whenever a
-// ConnectionEvent is received from the eventQueue channel. The eventQueue is
-// populated by the processPacket function when new SSH connections are detected
-// from network packet analysis.
-// handleEvent processes an SSH connection event and updates corresponding metrics.
-func handleEvent(event ConnectionEvent) {
-	fmt.Print("New connection detected towards: ", event.Conn.DestIP, ":", event.Conn.DestPort, "\n")
-	sshConnections.WithLabelValues(event.Conn.DestIP, strconv.Itoa(int(event.Conn.DestPort))).Inc()
+// readPackets reads the packets from the given source, and sends the corresponding events to the
+// event queue, until the stop channel is closed. The poll timeouts are expected, as the source waits
+// for the packets only up to the configured timeout to allow checking the stop channel, while the
+// reading is retried after a backoff in case of unexpected errors, to avoid spinning on a failing source.
+func readPackets(source gopacket.ZeroCopyPacketDataSource, port uint16, eventQueue chan ConnectionEvent, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		data, ci, err := source.ZeroCopyReadPacketData()
+		switch {
+		case err == nil:
+			packet := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+			processPacket(packet, port, ci.Timestamp, eventQueue)
+		case errors.Is(err, afpacket.ErrTimeout):
+			// No packets received within the poll timeout.
+		default:
+			log.Printf("Failed reading packet data: %v", err)
+			select {
+			case <-stop:
+				return
+			case <-time.After(readErrorBackoff):
+			}
+		}
+	}
 }
 
-// NewSSHTracker creates and initializes a new SSH tracker.
-func NewSSHTracker() *SSHTracker {
+// NewSSHTracker creates and initializes a new SSH tracker. The destination IPs are attributed to the
+// corresponding instances through the given resolver (if not nil), while the connections with no packets
+// detected for longer than sessionTimeout are considered terminated. The last activity towards each
+// destination is forgotten once no traffic is detected for longer than activityRetention.
+func NewSSHTracker(resolver *InstanceResolver, sessionTimeout, activityRetention time.Duration) *SSHTracker {
 	return &SSHTracker{
-		stopCh: make(chan struct{}),
-		done:   make(chan struct{}),
+		stopCh:            make(chan struct{}),
+		done:              make(chan struct{}),
+		resolver:          resolver,
+		sessionTimeout:    sessionTimeout,
+		activity:          newActivityRecorder(),
+		activityRetention: activityRetention,
 	}
 }
 
+// ActivityHandler returns an HTTP handler exposing, in JSON format, the last time SSH traffic
+// was detected towards each destination IP, to allow detecting idle instances.
+func (t *SSHTracker) ActivityHandler() http.Handler {
+	return t.activity
+}
+
 // Start begins tracking SSH connections on the specified interface, port, and snaplen.
 func (t *SSHTracker) Start(iface string, port, snaplen int) error {
 	defer close(t.done)
@@ -121,60 +207,54 @@ func (t *SSHTracker) Start(iface string, port, snaplen int) error {
 	}
 	defer afHandle.Close()
 
-	// Filter for new outbound TCP packets on the specified port
-	// Explicitly check for SYN packets to identify new connections
-	// We want to track when a connection is established
-	filter := fmt.Sprintf("tcp dst port %d and tcp[tcpflags] & tcp-syn != 0", port)
+	// Filter for the TCP packets (both IPv4 and IPv6) from and to the specified port,
+	// to track the whole lifecycle of the connections (from SYN to FIN/RST)
+	filter := fmt.Sprintf("tcp port %d", port)
 	if err := afHandle.SetBPFFilter(filter, snaplen); err != nil {
 		return fmt.Errorf("error setting BPF filter: %w", err)
 	}
 
 	source := gopacket.ZeroCopyPacketDataSource(afHandle)
 
-	eventQueue := make(chan ConnectionEvent, 100)
+	eventQueue := make(chan ConnectionEvent, 1000)
+	sessions := newSessionTable(t.resolver, t.activity)
+	expiration := time.NewTicker(time.Minute)
+	defer expiration.Stop()
 
-	var wg sync.WaitGroup
+	var workers, reader sync.WaitGroup
 	stopWorkers := make(chan struct{})
 	stopPackets := make(chan struct{})
 
-	wg.Add(1)
+	workers.Add(1)
 	go func() {
-		defer wg.Done()
+		defer workers.Done()
 		for {
 			select {
 			case event := <-eventQueue:
-				handleEvent(event)
+				sessions.handle(&event)
+			case now := <-expiration.C:
+				sessions.expire(now, t.sessionTimeout)
+				t.activity.prune(now.Add(-t.activityRetention))
 			case <-stopWorkers:
 				return
 			}
 		}
 	}()
 
-	wg.Add(1)
+	reader.Add(1)
 	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-stopPackets:
-				return
-			default:
-				data, _, err := source.ZeroCopyReadPacketData()
-				if err != nil {
-					continue
-				}
-				packet := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
-				processPacket(packet, eventQueue)
-			}
-		}
+		defer reader.Done()
+		readPackets(source, uint16(port), eventQueue, stopPackets)
 	}()
 
 	<-t.stopCh
+	// The reader returns within the poll timeout, and the handle is closed only afterwards,
+	// as closing it while a read is in progress would release the ring buffer being accessed.
 	close(stopPackets)
-	// Let the packet processing finish
-	time.Sleep(2 * timeout)
+	reader.Wait()
 	afHandle.Close()
 	close(stopWorkers)
-	wg.Wait()
+	workers.Wait()
 
 	return nil
 }
@@ -0,0 +1,202 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion_ssh_tracker
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/afpacket"
+	"github.com/google/gopacket/layers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("The processPacket function", func() {
+	const port = 22
+
+	var (
+		eventQueue chan ConnectionEvent
+		timestamp  time.Time
+		payload    []byte
+		fin        bool
+	)
+
+	// packet forges an IPv4 TCP packet between the given ports, carrying the payload.
+	packet := func(srcPort, dstPort layers.TCPPort) gopacket.Packet {
+		ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP,
+			SrcIP: net.ParseIP("10.0.0.100"), DstIP: net.ParseIP("10.0.0.1")}
+		tcp := &layers.TCP{SrcPort: srcPort, DstPort: dstPort, ACK: true, PSH: !fin, FIN: fin}
+		Expect(tcp.SetNetworkLayerForChecksum(ip)).To(Succeed())
+
+		buffer := gopacket.NewSerializeBuffer()
+		Expect(gopacket.SerializeLayers(buffer, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+			ip, tcp, gopacket.Payload(payload))).To(Succeed())
+		return gopacket.NewPacket(buffer.Bytes(), layers.LayerTypeIPv4, gopacket.Default)
+	}
+
+	BeforeEach(func() {
+		eventQueue = make(chan ConnectionEvent, 1)
+		timestamp = time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+		payload = []byte("SSH-2.0-OpenSSH")
+		fin = false
+	})
+
+	It("Should forge the event of an outbound packet", func() {
+		processPacket(packet(40000, port), port, timestamp, eventQueue)
+		Expect(eventQueue).To(Receive(Equal(ConnectionEvent{
+			Key:      ConnectionKey{SourceIP: "10.0.0.100", SourcePort: 40000, DestIP: "10.0.0.1", DestPort: port},
+			Outbound: true, ACK: true, PayloadLength: len(payload), Timestamp: timestamp,
+		})))
+	})
+
+	It("Should orient the event of an inbound packet from the bastion towards the target", func() {
+		processPacket(packet(port, 40000), port, timestamp, eventQueue)
+		Expect(eventQueue).To(Receive(And(
+			HaveField("Key", ConnectionKey{SourceIP: "10.0.0.1", SourcePort: 40000, DestIP: "10.0.0.100", DestPort: port}),
+			HaveField("Outbound", BeFalse()),
+		)))
+	})
+
+	It("Should ignore the packets not involving the given port", func() {
+		processPacket(packet(40000, 8080), port, timestamp, eventQueue)
+		Expect(eventQueue).ToNot(Receive())
+	})
+
+	It("Should drop the event without blocking when the queue is full", func() {
+		dropped := testutil.ToFloat64(sshDroppedEvents)
+		processPacket(packet(40000, port), port, timestamp, eventQueue)
+		processPacket(packet(40000, port), port, timestamp, eventQueue)
+
+		Expect(eventQueue).To(HaveLen(1))
+		Expect(testutil.ToFloat64(sshDroppedEvents)).To(BeNumerically("==", dropped+1))
+	})
+
+	When("the packet carries a control flag", func() {
+		BeforeEach(func() {
+			processPacket(packet(40000, port), port, timestamp, eventQueue)
+			fin = true
+		})
+
+		It("Should wait for the queue to accept the event", func() {
+			go func() {
+				defer GinkgoRecover()
+				time.Sleep(controlEventTimeout / 4)
+				Eventually(eventQueue).Should(Receive(HaveField("FIN", BeFalse())))
+			}()
+
+			dropped := testutil.ToFloat64(sshDroppedEvents)
+			processPacket(packet(40000, port), port, timestamp, eventQueue)
+
+			Expect(eventQueue).To(Receive(HaveField("FIN", BeTrue())))
+			Expect(testutil.ToFloat64(sshDroppedEvents)).To(BeNumerically("==", dropped))
+		})
+
+		It("Should drop the event once the timeout expires", func() {
+			dropped := testutil.ToFloat64(sshDroppedEvents)
+			start := time.Now()
+			processPacket(packet(40000, port), port, timestamp, eventQueue)
+
+			Expect(time.Since(start)).To(BeNumerically(">=", controlEventTimeout))
+			Expect(eventQueue).To(Receive(HaveField("FIN", BeFalse())))
+			Expect(testutil.ToFloat64(sshDroppedEvents)).To(BeNumerically("==", dropped+1))
+		})
+	})
+})
+
+// fakePacketSource is a packet source returning the configured packet data or error.
+type fakePacketSource struct {
+	data  []byte
+	err   error
+	reads atomic.Int64
+}
+
+func (s *fakePacketSource) ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	s.reads.Add(1)
+	if s.err != nil {
+		return nil, gopacket.CaptureInfo{}, s.err
+	}
+	return s.data, gopacket.CaptureInfo{Timestamp: time.Now()}, nil
+}
+
+var _ = Describe("The readPackets function", func() {
+	const port = 22
+
+	var (
+		source     *fakePacketSource
+		eventQueue chan ConnectionEvent
+		stop       chan struct{}
+		done       chan struct{}
+	)
+
+	BeforeEach(func() {
+		source = &fakePacketSource{}
+		eventQueue = make(chan ConnectionEvent, 1000)
+		stop = make(chan struct{})
+	})
+
+	JustBeforeEach(func() {
+		done = make(chan struct{})
+		go func() {
+			defer close(done)
+			readPackets(source, port, eventQueue, stop)
+		}()
+	})
+
+	AfterEach(func() {
+		close(stop)
+		Eventually(done).Should(BeClosed())
+	})
+
+	When("the packets are read successfully", func() {
+		BeforeEach(func() {
+			eth := &layers.Ethernet{SrcMAC: net.HardwareAddr{0, 0, 0, 0, 0, 1}, DstMAC: net.HardwareAddr{0, 0, 0, 0, 0, 2},
+				EthernetType: layers.EthernetTypeIPv4}
+			ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP,
+				SrcIP: net.ParseIP("10.0.0.100"), DstIP: net.ParseIP("10.0.0.1")}
+			tcp := &layers.TCP{SrcPort: 40000, DstPort: port, SYN: true}
+			Expect(tcp.SetNetworkLayerForChecksum(ip)).To(Succeed())
+
+			buffer := gopacket.NewSerializeBuffer()
+			Expect(gopacket.SerializeLayers(buffer, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+				eth, ip, tcp)).To(Succeed())
+			source.data = buffer.Bytes()
+		})
+
+		It("Should send the corresponding events", func() {
+			Eventually(eventQueue).Should(Receive(HaveField("SYN", BeTrue())))
+		})
+	})
+
+	When("the source fails", func() {
+		BeforeEach(func() { source.err = errors.New("poll failed") })
+
+		It("Should back off before reading again", func() {
+			Consistently(source.reads.Load, 3*readErrorBackoff, readErrorBackoff/10).Should(BeNumerically("<=", 4))
+		})
+	})
+
+	When("no packets are received within the poll timeout", func() {
+		BeforeEach(func() { source.err = afpacket.ErrTimeout })
+
+		It("Should keep reading until stopped", func() {
+			Eventually(source.reads.Load).Should(BeNumerically(">", 10))
+		})
+	})
+})
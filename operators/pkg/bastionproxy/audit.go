@@ -0,0 +1,165 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastionproxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SessionRecord contains the metadata of a connection proxied towards an instance.
+type SessionRecord struct {
+	Tenant      string    `json:"tenant"`
+	Namespace   string    `json:"namespace"`
+	Instance    string    `json:"instance"`
+	Environment string    `json:"environment,omitempty"`
+	RemoteAddr  string    `json:"remoteAddr"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	// BytesIn is the amount of data sent by the client towards the instance.
+	BytesIn int64 `json:"bytesIn"`
+	// BytesOut is the amount of data sent by the instance towards the client.
+	BytesOut int64 `json:"bytesOut"`
+	// Typescript is the path of the file containing the data exchanged in the session, if recorded.
+	Typescript string `json:"typescript,omitempty"`
+}
+
+// The streams of a channel recorded in the typescripts.
+const (
+	// StreamIn identifies the data sent by the client towards the instance.
+	StreamIn = "in"
+	// StreamOut identifies the data sent by the instance on the standard output.
+	StreamOut = "out"
+	// StreamErr identifies the data sent by the instance on the standard error.
+	StreamErr = "err"
+)
+
+// TypescriptEvent is a chunk of data exchanged in a session, as recorded in the typescript.
+type TypescriptEvent struct {
+	Time time.Time `json:"time"`
+	// Channel is the sequence number of the channel the data was exchanged through, within the session.
+	Channel int `json:"channel"`
+	// Stream is the stream the data was exchanged through (i.e., StreamIn, StreamOut or StreamErr).
+	Stream string `json:"stream"`
+	Data   string `json:"data"`
+}
+
+// Typescript records the data exchanged in a session, one TypescriptEvent per line. Since the
+// channels and streams of a session are proxied concurrently, the events are serialized.
+type Typescript struct {
+	file  io.WriteCloser
+	mutex sync.Mutex
+}
+
+// Writer returns the writer recording the data exchanged through the given channel and stream.
+// A nil typescript discards the data.
+func (t *Typescript) Writer(channel int, stream string) io.Writer {
+	if t == nil {
+		return io.Discard
+	}
+	return typescriptWriter{typescript: t, channel: channel, stream: stream}
+}
+
+// Close closes the underlying file.
+func (t *Typescript) Close() error {
+	return t.file.Close()
+}
+
+// record writes the given event.
+func (t *Typescript) record(event *TypescriptEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	_, err = t.file.Write(append(data, '\n'))
+	return err
+}
+
+// typescriptWriter records the data written through it as events of the given channel and stream.
+type typescriptWriter struct {
+	typescript *Typescript
+	channel    int
+	stream     string
+}
+
+func (w typescriptWriter) Write(p []byte) (int, error) {
+	event := TypescriptEvent{Time: time.Now(), Channel: w.channel, Stream: w.stream, Data: string(p)}
+	if err := w.typescript.record(&event); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Auditor records the metadata of the proxied sessions, as well as the typescripts of the exam sessions.
+type Auditor struct {
+	// Output is where the session records are written, one JSON object per line.
+	Output io.Writer
+	// TypescriptDir is the directory where the typescripts are stored (not recorded if empty).
+	TypescriptDir string
+
+	mutex sync.Mutex
+}
+
+// Record writes the given session record.
+func (a *Auditor) Record(record *SessionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	_, err = a.Output.Write(append(data, '\n'))
+	return err
+}
+
+// Typescript creates the file recording the data exchanged in the given session, returning nil
+// in case typescripts are not enabled. The path of the file is stored in the record.
+func (a *Auditor) Typescript(record *SessionRecord) (*Typescript, error) {
+	if a.TypescriptDir == "" {
+		return nil, nil
+	}
+
+	name := fmt.Sprintf("%s_%s_%s_%s.typescript", record.Namespace, record.Instance, record.Tenant, record.Start.UTC().Format("20060102T150405Z"))
+	path := filepath.Join(a.TypescriptDir, name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Typescript = path
+	return &Typescript{file: file}, nil
+}
+
+// countingWriter wraps a writer, counting the bytes written through it.
+type countingWriter struct {
+	writer io.Writer
+	count  *atomic.Int64
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.count.Add(int64(n))
+	return n, err
+}
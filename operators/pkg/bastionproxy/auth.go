@@ -0,0 +1,73 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastionproxy
+
+import (
+	"golang.org/x/crypto/ssh"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+// TenantPublicKeysIndex is the name of the field index mapping the fingerprints
+// of the public keys of the tenants to the tenants themselves.
+const TenantPublicKeysIndex = "spec.publicKeys.fingerprint"
+
+// Keys of the permissions extensions carrying the outcome of the authentication to the connection handler.
+const (
+	extensionTenant      = "crownlabs-tenant"
+	extensionNamespace   = "crownlabs-namespace"
+	extensionInstance    = "crownlabs-instance"
+	extensionEnvironment = "crownlabs-environment"
+)
+
+// IndexTenantPublicKeys returns the SHA256 fingerprints of the valid public keys of the given tenant,
+// to be used as field index (TenantPublicKeysIndex) to efficiently authenticate the tenants.
+func IndexTenantPublicKeys(obj client.Object) []string {
+	tenant, ok := obj.(*clv1alpha2.Tenant)
+	if !ok {
+		return nil
+	}
+
+	fingerprints := make([]string, 0, len(tenant.Spec.PublicKeys))
+	for _, key := range tenant.Spec.PublicKeys {
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+		if err != nil {
+			// invalid keys are skipped, since they cannot be used to authenticate in any case.
+			continue
+		}
+		fingerprints = append(fingerprints, ssh.FingerprintSHA256(publicKey))
+	}
+	return fingerprints
+}
+
+// permissionsTarget returns the tenant and the target stored in the permissions by the authentication.
+func permissionsTarget(permissions *ssh.Permissions) (tenant string, target Target) {
+	return permissions.Extensions[extensionTenant], Target{
+		Namespace:   permissions.Extensions[extensionNamespace],
+		Instance:    permissions.Extensions[extensionInstance],
+		Environment: permissions.Extensions[extensionEnvironment],
+	}
+}
+
+// targetPermissions returns the permissions storing the authenticated tenant and the resolved target.
+func targetPermissions(tenant string, target Target) *ssh.Permissions {
+	return &ssh.Permissions{Extensions: map[string]string{
+		extensionTenant:      tenant,
+		extensionNamespace:   target.Namespace,
+		extensionInstance:    target.Instance,
+		extensionEnvironment: target.Environment,
+	}}
+}
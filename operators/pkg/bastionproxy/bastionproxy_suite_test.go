@@ -0,0 +1,48 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastionproxy_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+func TestBastionProxy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bastion Proxy Suite")
+}
+
+var _ = BeforeSuite(func() {
+	// The scheme is registered once, as the servers started by the previous specs may still be accessing it.
+	Expect(clv1alpha2.AddToScheme(scheme.Scheme)).To(Succeed())
+})
+
+// generateSigner generates a new ed25519 key pair, returning the corresponding signer.
+func generateSigner() ssh.Signer {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	signer, err := ssh.NewSignerFromKey(private)
+	Expect(err).ToNot(HaveOccurred())
+	return signer
+}
@@ -0,0 +1,65 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastionproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/restcfg"
+)
+
+// NewCachedClient initializes the k8s client used to authenticate the tenants and retrieve the instances.
+// The client is backed by a cache, which is kept in sync until the given context is canceled, and
+// indexes the tenants by the fingerprints of their public keys.
+func NewCachedClient(ctx context.Context) (client.Client, error) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(clv1alpha2.AddToScheme(scheme))
+
+	kubeconfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("k8s config error: %w", err)
+	}
+
+	cl, err := cluster.New(restcfg.SetRateLimiter(kubeconfig), func(o *cluster.Options) { o.Scheme = scheme })
+	if err != nil {
+		return nil, fmt.Errorf("failed creating cluster: %w", err)
+	}
+
+	if err := cl.GetFieldIndexer().IndexField(ctx, &clv1alpha2.Tenant{}, TenantPublicKeysIndex, IndexTenantPublicKeys); err != nil {
+		return nil, fmt.Errorf("failed indexing tenants: %w", err)
+	}
+
+	go func() {
+		if err := cl.Start(ctx); err != nil {
+			ctrl.Log.Error(err, "cache terminated unexpectedly")
+		}
+	}()
+
+	if !cl.GetCache().WaitForCacheSync(ctx) {
+		return nil, errors.New("failed waiting for cache sync")
+	}
+	return cl.GetClient(), nil
+}
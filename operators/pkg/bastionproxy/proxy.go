@@ -0,0 +1,353 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastionproxy
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/crypto/ssh"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/sshca"
+)
+
+const (
+	// maxAuthTries is the maximum number of authentication attempts allowed for each connection.
+	maxAuthTries = 3
+	// authenticationTimeout is the maximum time to retrieve the resources needed to authenticate a connection.
+	authenticationTimeout = 10 * time.Second
+)
+
+// errAccessDenied is returned to the clients failing the authentication, without disclosing the reason.
+var errAccessDenied = errors.New("access denied")
+
+// Server is the SSH bastion proxy. It authenticates the tenants through the public keys listed in their
+// Tenant resource, and routes each connection to the instance specified as username. The connections
+// towards the instances are authenticated with short-lived certificates issued for the instance principal.
+type Server struct {
+	Client client.Client
+	Log    logr.Logger
+
+	// HostKeys are the host keys of the bastion.
+	HostKeys []ssh.Signer
+	// Authority issues the certificates used to authenticate towards the instances.
+	Authority *sshca.Authority
+	// Auditor records the metadata of the sessions.
+	Auditor *Auditor
+
+	// TargetUser is the user the connections towards the instances are authenticated as.
+	TargetUser string
+	// TargetPort is the SSH port of the instances.
+	TargetPort int
+	// DialTimeout is the maximum time to establish the connections towards the instances.
+	DialTimeout time.Duration
+	// HandshakeTimeout is the maximum time for the clients to complete the handshake (disabled if zero).
+	HandshakeTimeout time.Duration
+}
+
+// ServerConfig returns the configuration of the SSH server.
+func (s *Server) ServerConfig() *ssh.ServerConfig {
+	config := &ssh.ServerConfig{PublicKeyCallback: s.authenticate, MaxAuthTries: maxAuthTries}
+	for _, key := range s.HostKeys {
+		config.AddHostKey(key)
+	}
+	return config
+}
+
+// Serve accepts the connections from the given listener, until it is closed or the context is canceled.
+// In the latter case, the listener and the connections in progress are closed, and nil is returned.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { _ = listener.Close() })
+	defer stop()
+
+	config := s.ServerConfig()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(ctx, conn, config)
+	}
+}
+
+// authenticate authenticates the tenant owning the given public key, and checks whether it is
+// allowed to access the requested instance.
+func (s *Server) authenticate(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), authenticationTimeout)
+	defer cancel()
+	log := s.Log.WithValues("remote-addr", conn.RemoteAddr().String(), "user", conn.User())
+
+	target, err := ParseTarget(conn.User())
+	if err != nil {
+		log.Info("authentication failed", "reason", err.Error())
+		return nil, errAccessDenied
+	}
+
+	var tenants clv1alpha2.TenantList
+	if err := s.Client.List(ctx, &tenants, client.MatchingFields{TenantPublicKeysIndex: ssh.FingerprintSHA256(key)}); err != nil {
+		log.Error(err, "failed retrieving tenants")
+		return nil, errAccessDenied
+	}
+
+	for i := range tenants.Items {
+		resolved := target
+		if _, err := ResolveInstance(ctx, s.Client, &tenants.Items[i], &resolved); err != nil {
+			log.Info("authorization failed", "tenant", tenants.Items[i].Name, "reason", err.Error())
+			continue
+		}
+		return targetPermissions(tenants.Items[i].Name, resolved), nil
+	}
+
+	log.Info("authentication failed", "reason", "no tenant allowed to access the target with the given key")
+	return nil, errAccessDenied
+}
+
+// handleConn handles a connection from a client, proxying its channels towards the target instance.
+func (s *Server) handleConn(ctx context.Context, nConn net.Conn, config *ssh.ServerConfig) {
+	log := s.Log.WithValues("remote-addr", nConn.RemoteAddr().String())
+
+	// The connection is closed when the server is stopped, which in turn terminates the session, if any.
+	stop := context.AfterFunc(ctx, func() { _ = nConn.Close() })
+	defer stop()
+
+	// The deadline prevents the clients which never complete the handshake from holding the connection indefinitely.
+	if s.HandshakeTimeout > 0 {
+		_ = nConn.SetDeadline(time.Now().Add(s.HandshakeTimeout))
+	}
+	conn, channels, requests, err := ssh.NewServerConn(nConn, config)
+	if err != nil {
+		log.V(1).Info("handshake failed", "reason", err.Error())
+		return
+	}
+	defer conn.Close()
+	_ = nConn.SetDeadline(time.Time{})
+	go ssh.DiscardRequests(requests)
+
+	tenant, target := permissionsTarget(conn.Permissions)
+	log = log.WithValues("tenant", tenant, "target", target)
+
+	upstream, exam, err := s.dialTarget(ctx, tenant, target)
+	if err != nil {
+		log.Error(err, "failed connecting to the instance")
+		for newChannel := range channels {
+			_ = newChannel.Reject(ssh.ConnectionFailed, "unable to connect to the instance")
+		}
+		return
+	}
+	defer upstream.Close()
+
+	record := SessionRecord{
+		Tenant:      tenant,
+		Namespace:   target.Namespace,
+		Instance:    target.Instance,
+		Environment: target.Environment,
+		RemoteAddr:  nConn.RemoteAddr().String(),
+		Start:       time.Now(),
+	}
+
+	var typescript *Typescript
+	if exam {
+		if typescript, err = s.Auditor.Typescript(&record); err != nil {
+			log.Error(err, "failed creating typescript, rejecting the connection")
+			return
+		}
+		if typescript != nil {
+			defer typescript.Close()
+		}
+	}
+
+	log.Info("session started", "exam", exam)
+	var bytesIn, bytesOut atomic.Int64
+	var wg sync.WaitGroup
+	var channelID int
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only session channels are supported")
+			continue
+		}
+
+		streams := channelStreams{
+			in:     countingWriter{writer: typescript.Writer(channelID, StreamIn), count: &bytesIn},
+			out:    countingWriter{writer: typescript.Writer(channelID, StreamOut), count: &bytesOut},
+			stderr: countingWriter{writer: typescript.Writer(channelID, StreamErr), count: &bytesOut},
+		}
+		channelID++
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.proxyChannel(log, newChannel, upstream, streams)
+		}()
+	}
+	wg.Wait()
+
+	record.End = time.Now()
+	record.BytesIn, record.BytesOut = bytesIn.Load(), bytesOut.Load()
+	if err := s.Auditor.Record(&record); err != nil {
+		log.Error(err, "failed recording session")
+	}
+	log.Info("session terminated", "duration", record.End.Sub(record.Start), "bytes-in", record.BytesIn, "bytes-out", record.BytesOut)
+}
+
+// dialTarget establishes the connection towards the target instance, authenticating with a certificate
+// issued for the instance principal, and verifying the host key recorded in the status of the instance.
+// It also returns whether the instance is in exam mode.
+func (s *Server) dialTarget(ctx context.Context, tenant string, target Target) (*ssh.Client, bool, error) {
+	var tn clv1alpha2.Tenant
+	if err := s.Client.Get(ctx, client.ObjectKey{Name: tenant}, &tn); err != nil {
+		return nil, false, err
+	}
+
+	// The instance is resolved again, to account for changes since the authentication.
+	instance, err := ResolveInstance(ctx, s.Client, &tn, &target)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ip, hostKey, err := Destination(instance, target)
+	if err != nil {
+		return nil, false, err
+	}
+
+	exam, err := IsExamInstance(ctx, s.Client, instance)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed retrieving template: %w", err)
+	}
+
+	signer, err := s.instanceSigner(tenant, instance)
+	if err != nil {
+		return nil, false, err
+	}
+
+	upstream, err := ssh.Dial("tcp", net.JoinHostPort(ip, strconv.Itoa(s.TargetPort)), &ssh.ClientConfig{
+		User: s.TargetUser,
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		// The host key is the one installed through cloud-init, and recorded in the status of the instance.
+		HostKeyCallback:   ssh.FixedHostKey(hostKey),
+		HostKeyAlgorithms: []string{hostKey.Type()},
+		Timeout:           s.DialTimeout,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return upstream, exam, nil
+}
+
+// instanceSigner generates an ephemeral key, and returns a signer using the certificate issued for it.
+func (s *Server) instanceSigner(tenant string, instance *clv1alpha2.Instance) (ssh.Signer, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := fmt.Sprintf("%s@%s/%s", tenant, instance.GetNamespace(), instance.GetName())
+	certificate, err := s.Authority.SignUserKey(signer.PublicKey(), keyID, []string{forge.SSHInstancePrincipal(instance)})
+	if err != nil {
+		return nil, err
+	}
+	return ssh.NewCertSigner(certificate, signer)
+}
+
+// channelStreams are the writers the data exchanged through a channel is copied to, for accounting and recording.
+type channelStreams struct {
+	// in receives the data sent by the client.
+	in io.Writer
+	// out and stderr receive the data sent by the instance on the corresponding streams.
+	out, stderr io.Writer
+}
+
+// proxyChannel opens the same channel towards the instance, and forwards the data and the requests in both directions.
+// The data exchanged in each direction is copied to the corresponding stream.
+func (s *Server) proxyChannel(log logr.Logger, newChannel ssh.NewChannel, upstream *ssh.Client, streams channelStreams) {
+	upstreamChannel, upstreamRequests, err := upstream.OpenChannel(newChannel.ChannelType(), newChannel.ExtraData())
+	if err != nil {
+		var openErr *ssh.OpenChannelError
+		if errors.As(err, &openErr) {
+			_ = newChannel.Reject(openErr.Reason, openErr.Message)
+		} else {
+			_ = newChannel.Reject(ssh.ConnectionFailed, "unable to open the channel towards the instance")
+		}
+		return
+	}
+	defer upstreamChannel.Close()
+
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		log.Error(err, "failed accepting channel")
+		return
+	}
+	defer channel.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		// The upstream channel is closed as soon as the client closes the channel.
+		forwardRequests(requests, upstreamChannel)
+		_ = upstreamChannel.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		forwardRequests(upstreamRequests, channel)
+	}()
+	go func() {
+		_, _ = io.Copy(upstreamChannel, io.TeeReader(channel, streams.in))
+		_ = upstreamChannel.CloseWrite()
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(channel, io.TeeReader(upstreamChannel, streams.out))
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(channel.Stderr(), io.TeeReader(upstreamChannel.Stderr(), streams.stderr))
+	}()
+
+	// Wait until the instance has sent all the data and requests (e.g., the exit status).
+	wg.Wait()
+}
+
+// forwardRequests forwards the requests to the given channel, until the requests channel is closed.
+func forwardRequests(requests <-chan *ssh.Request, channel ssh.Channel) {
+	for request := range requests {
+		ok, err := channel.SendRequest(request.Type, request.WantReply, request.Payload)
+		if err != nil {
+			ok = false
+		}
+		if request.WantReply {
+			_ = request.Reply(ok, nil)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastionproxy_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"golang.org/x/crypto/ssh"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/bastionproxy"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/sshca"
+)
+
+// serveInstance runs a minimal SSH server emulating an instance, which trusts the certificates issued by the
+// given authority for the given principal, and replies to the exec requests echoing the command and the data
+// received from the client.
+func serveInstance(listener net.Listener, hostKey ssh.Signer, authority ssh.PublicKey, principal string) {
+	checker := ssh.CertChecker{IsUserAuthority: func(auth ssh.PublicKey) bool {
+		return bytes.Equal(auth.Marshal(), authority.Marshal())
+	}}
+
+	// The principal is checked in place of the username, as done by sshd with the AuthorizedPrincipalsFile.
+	config := &ssh.ServerConfig{PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+		cert, ok := key.(*ssh.Certificate)
+		if !ok || !checker.IsUserAuthority(cert.SignatureKey) {
+			return nil, errors.New("unauthorized")
+		}
+		return &cert.Permissions, checker.CheckCert(principal, cert)
+	}}
+	config.AddHostKey(hostKey)
+
+	for {
+		nConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer GinkgoRecover()
+			_, channels, requests, err := ssh.NewServerConn(nConn, config)
+			if err != nil {
+				return
+			}
+			go ssh.DiscardRequests(requests)
+
+			for newChannel := range channels {
+				channel, requests, err := newChannel.Accept()
+				Expect(err).ToNot(HaveOccurred())
+
+				for request := range requests {
+					if request.Type != "exec" {
+						_ = request.Reply(false, nil)
+						continue
+					}
+
+					var payload struct{ Command string }
+					Expect(ssh.Unmarshal(request.Payload, &payload)).To(Succeed())
+					Expect(request.Reply(true, nil)).To(Succeed())
+
+					input, _ := io.ReadAll(channel)
+					_, _ = channel.Write([]byte("hello " + payload.Command + "\n"))
+					_, _ = channel.Stderr().Write(input)
+					_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+					_ = channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+var _ = Describe("The bastion proxy", func() {
+	const (
+		tenantName   = "tester"
+		namespace    = "tenant-tester"
+		instanceName = "instance"
+	)
+
+	var (
+		ctx              context.Context
+		cancel           context.CancelFunc
+		served           chan error
+		authority        *sshca.Authority
+		instanceListener net.Listener
+		instanceHostKey  ssh.Signer
+		recordedHostKey  ssh.PublicKey
+		phase            clv1alpha2.EnvironmentPhase
+		proxyListener    net.Listener
+		userKey          ssh.Signer
+		user             string
+		template         clv1alpha2.Template
+		audit            *gbytes.Buffer
+		auditor          *bastionproxy.Auditor
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+
+		_, caKey, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		block, err := ssh.MarshalPrivateKey(caKey, "")
+		Expect(err).ToNot(HaveOccurred())
+		authority, err = sshca.NewAuthority(pem.EncodeToMemory(block), time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		authorityKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authority.PublicKey()))
+		Expect(err).ToNot(HaveOccurred())
+
+		instanceListener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(instanceListener.Close)
+		instanceHostKey = generateSigner()
+		recordedHostKey = instanceHostKey.PublicKey()
+		phase = clv1alpha2.EnvironmentPhaseReady
+		go serveInstance(instanceListener, instanceHostKey, authorityKey, "instance:"+namespace+"/"+instanceName)
+
+		proxyListener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		// The listener is also closed by the server when stopped.
+		DeferCleanup(func() { _ = proxyListener.Close() })
+
+		userKey = generateSigner()
+		user = instanceName
+		template = clv1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "workspace-netgroup"},
+			Spec: clv1alpha2.TemplateSpec{EnvironmentList: []clv1alpha2.Environment{
+				{Name: "vm", Mode: clv1alpha2.ModeStandard},
+			}},
+		}
+
+		audit = gbytes.NewBuffer()
+		auditor = &bastionproxy.Auditor{Output: audit}
+	})
+
+	JustBeforeEach(func() {
+		tenant := clv1alpha2.Tenant{
+			ObjectMeta: metav1.ObjectMeta{Name: tenantName},
+			Spec:       clv1alpha2.TenantSpec{PublicKeys: []string{string(ssh.MarshalAuthorizedKey(userKey.PublicKey()))}},
+		}
+		instance := clv1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: instanceName, Namespace: namespace},
+			Spec: clv1alpha2.InstanceSpec{
+				Running:  true,
+				Template: clv1alpha2.GenericRef{Name: template.Name, Namespace: template.Namespace},
+				Tenant:   clv1alpha2.GenericRef{Name: tenantName},
+			},
+			Status: clv1alpha2.InstanceStatus{IP: "127.0.0.1", Environments: []clv1alpha2.InstanceEnvironmentStatus{{
+				Name: "vm", Phase: phase, IP: "127.0.0.1",
+				SSHHostKey: string(ssh.MarshalAuthorizedKey(recordedHostKey)),
+			}}},
+		}
+
+		server := bastionproxy.Server{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithObjects(&tenant, &instance, &template).
+				WithIndex(&clv1alpha2.Tenant{}, bastionproxy.TenantPublicKeysIndex, bastionproxy.IndexTenantPublicKeys).
+				Build(),
+			Log:              logr.Discard(),
+			HostKeys:         []ssh.Signer{generateSigner()},
+			Authority:        authority,
+			Auditor:          auditor,
+			TargetUser:       "crownlabs",
+			TargetPort:       instanceListener.Addr().(*net.TCPAddr).Port,
+			DialTimeout:      time.Second,
+			HandshakeTimeout: 500 * time.Millisecond,
+		}
+		result := make(chan error, 1)
+		served = result
+		go func() { result <- server.Serve(ctx, proxyListener) }()
+	})
+
+	// dial connects to the bastion proxy as the configured user, authenticating with the given keys.
+	dial := func(keys ...ssh.Signer) (*ssh.Client, error) {
+		return ssh.Dial("tcp", proxyListener.Addr().String(), &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(keys...)},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // Test server.
+			Timeout:         time.Second,
+		})
+	}
+
+	// run connects to the bastion proxy as the configured user, returning the output of the given command
+	// executed with the given input.
+	run := func(command, input string) (string, error) {
+		conn, err := dial(userKey)
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+
+		session, err := conn.NewSession()
+		if err != nil {
+			return "", err
+		}
+		defer session.Close()
+
+		session.Stdin = strings.NewReader(input)
+		output, err := session.Output(command)
+		return string(output), err
+	}
+
+	// connect connects to the bastion proxy as the configured user, returning the output of the given command.
+	connect := func(command string) (string, error) {
+		return run(command, "")
+	}
+
+	// record waits for the session record to be written, and returns it.
+	record := func() bastionproxy.SessionRecord {
+		Eventually(audit).Should(gbytes.Say("\n"))
+		var record bastionproxy.SessionRecord
+		Expect(json.Unmarshal(audit.Contents(), &record)).To(Succeed())
+		return record
+	}
+
+	When("the tenant connects to its own instance", func() {
+		It("should proxy the session towards the instance", func() {
+			Expect(connect("whoami")).To(Equal("hello whoami\n"))
+		})
+
+		It("should record the session metadata", func() {
+			_, err := connect("whoami")
+			Expect(err).ToNot(HaveOccurred())
+
+			rec := record()
+			Expect(rec.Tenant).To(Equal(tenantName))
+			Expect(rec.Namespace).To(Equal(namespace))
+			Expect(rec.Instance).To(Equal(instanceName))
+			Expect(rec.BytesOut).To(BeNumerically("==", len("hello whoami\n")))
+			Expect(rec.End).ToNot(BeTemporally("<", rec.Start))
+			Expect(rec.Typescript).To(BeEmpty())
+		})
+	})
+
+	When("the key does not belong to any tenant", func() {
+		JustBeforeEach(func() { userKey = generateSigner() })
+
+		It("should deny the access", func() {
+			_, err := connect("whoami")
+			Expect(err).To(MatchError(ContainSubstring("unable to authenticate")))
+		})
+	})
+
+	When("the client exceeds the maximum number of authentication attempts", func() {
+		It("should deny the access, even if the last key is valid", func() {
+			_, err := dial(generateSigner(), generateSigner(), generateSigner(), userKey)
+			Expect(err).To(MatchError(ContainSubstring("too many authentication failures")))
+		})
+	})
+
+	When("the client does not complete the handshake", func() {
+		It("should close the connection after the timeout", func() {
+			conn, err := net.Dial("tcp", proxyListener.Addr().String())
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.Close()
+
+			Expect(conn.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+			_, err = io.Copy(io.Discard, conn)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("the server is stopped", func() {
+		It("should close the listener and the established connections", func() {
+			conn, err := dial(userKey)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.Close()
+
+			cancel()
+			Eventually(served).Should(Receive(BeNil()))
+			Eventually(conn.Wait).Should(HaveOccurred())
+
+			_, err = dial(userKey)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the target instance does not exist", func() {
+		BeforeEach(func() { user = "missing" })
+
+		It("should deny the access", func() {
+			_, err := connect("whoami")
+			Expect(err).To(MatchError(ContainSubstring("unable to authenticate")))
+		})
+	})
+
+	When("the environment is not ready", func() {
+		BeforeEach(func() { phase = clv1alpha2.EnvironmentPhaseStarting })
+
+		It("should refuse to connect to the instance", func() {
+			_, err := connect("whoami")
+			Expect(err).To(MatchError(ContainSubstring("unable to connect to the instance")))
+		})
+	})
+
+	When("the host key of the instance does not match the recorded one", func() {
+		BeforeEach(func() { recordedHostKey = generateSigner().PublicKey() })
+
+		It("should refuse to connect to the instance", func() {
+			_, err := connect("whoami")
+			Expect(err).To(MatchError(ContainSubstring("unable to connect to the instance")))
+		})
+	})
+
+	When("the instance is in exam mode", func() {
+		BeforeEach(func() {
+			template.Spec.EnvironmentList[0].Mode = clv1alpha2.ModeExam
+			auditor.TypescriptDir = GinkgoT().TempDir()
+		})
+
+		It("should record the typescript of the session", func() {
+			_, err := run("whoami", "some input")
+			Expect(err).ToNot(HaveOccurred())
+
+			rec := record()
+			Expect(filepath.Dir(rec.Typescript)).To(Equal(auditor.TypescriptDir))
+			Expect(rec.BytesIn).To(BeNumerically("==", len("some input")))
+
+			data, err := os.ReadFile(rec.Typescript)
+			Expect(err).ToNot(HaveOccurred())
+
+			recorded := map[string]string{}
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				var event bastionproxy.TypescriptEvent
+				Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+				Expect(event.Channel).To(BeZero())
+				recorded[event.Stream] += event.Data
+			}
+			Expect(recorded).To(Equal(map[string]string{
+				bastionproxy.StreamIn:  "some input",
+				bastionproxy.StreamOut: "hello whoami\n",
+				bastionproxy.StreamErr: "some input",
+			}))
+		})
+	})
+})
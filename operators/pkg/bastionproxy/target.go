@@ -0,0 +1,135 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bastionproxy implements a native SSH bastion, which authenticates the tenants through
+// their public keys and routes their connections to the requested instances.
+package bastionproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+// Target identifies the environment of an Instance a connection is routed to.
+type Target struct {
+	// Namespace is the namespace of the Instance (the one of the tenant, if empty).
+	Namespace string
+	// Instance is the name of the Instance.
+	Instance string
+	// Environment is the name of the environment (the first one, if empty).
+	Environment string
+}
+
+// ParseTarget parses the username specified by the client, in the form [<namespace>/]<instance>[:<environment>].
+func ParseTarget(user string) (Target, error) {
+	var target Target
+
+	rest := user
+	if namespace, instance, found := strings.Cut(rest, "/"); found {
+		target.Namespace, rest = namespace, instance
+	}
+	target.Instance, target.Environment, _ = strings.Cut(rest, ":")
+
+	if target.Instance == "" || (strings.Contains(user, "/") && target.Namespace == "") ||
+		(strings.Contains(rest, ":") && target.Environment == "") {
+		return Target{}, fmt.Errorf("invalid target %q, expected [<namespace>/]<instance>[:<environment>]", user)
+	}
+	return target, nil
+}
+
+// String returns the representation of the target, in the same form accepted by ParseTarget.
+func (t Target) String() string {
+	target := t.Namespace + "/" + t.Instance
+	if t.Environment != "" {
+		target += ":" + t.Environment
+	}
+	return target
+}
+
+// ResolveInstance retrieves the Instance corresponding to the target, on behalf of the given tenant,
+// and checks whether the tenant is allowed to access it. Access is granted to the owner of the Instance,
+// as well as to the managers and assistants of the corresponding workspace.
+func ResolveInstance(ctx context.Context, c client.Client, tenant *clv1alpha2.Tenant, target *Target) (*clv1alpha2.Instance, error) {
+	if target.Namespace == "" {
+		target.Namespace = forge.GetTenantNamespaceName(tenant)
+	}
+
+	var instance clv1alpha2.Instance
+	if err := c.Get(ctx, types.NamespacedName{Namespace: target.Namespace, Name: target.Instance}, &instance); err != nil {
+		return nil, err
+	}
+
+	if !forge.TenantCanAccessInstance(tenant, &instance) {
+		return nil, fmt.Errorf("tenant %s is not allowed to access instance %s", tenant.Name, target)
+	}
+	return &instance, nil
+}
+
+// Destination returns the IP address and the SSH host key of the environment of the Instance selected
+// by the target. The environment shall be ready, and its host key shall have been recorded in the status.
+func Destination(instance *clv1alpha2.Instance, target Target) (string, ssh.PublicKey, error) {
+	if !instance.Spec.Running {
+		return "", nil, errors.New("the instance is not running")
+	}
+
+	// The first environment is selected in case it is not explicitly specified.
+	idx := slices.IndexFunc(instance.Status.Environments, func(status clv1alpha2.InstanceEnvironmentStatus) bool {
+		return target.Environment == "" || status.Name == target.Environment
+	})
+	if idx < 0 {
+		return "", nil, fmt.Errorf("the instance has no environment %q", target.Environment)
+	}
+
+	status := &instance.Status.Environments[idx]
+	if status.Phase != clv1alpha2.EnvironmentPhaseReady {
+		return "", nil, fmt.Errorf("the environment %q is not ready", status.Name)
+	}
+	if status.IP == "" {
+		return "", nil, fmt.Errorf("the environment %q has no IP address yet", status.Name)
+	}
+	if status.SSHHostKey == "" {
+		return "", nil, fmt.Errorf("the host key of the environment %q has not been recorded", status.Name)
+	}
+
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(status.SSHHostKey))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid host key of the environment %q: %w", status.Name, err)
+	}
+	return status.IP, hostKey, nil
+}
+
+// IsExamInstance returns whether the Instance refers to a Template including environments in exam mode.
+func IsExamInstance(ctx context.Context, c client.Client, instance *clv1alpha2.Instance) (bool, error) {
+	var template clv1alpha2.Template
+	if err := c.Get(ctx, types.NamespacedName{Namespace: instance.Spec.Template.Namespace, Name: instance.Spec.Template.Name}, &template); err != nil {
+		return false, err
+	}
+
+	for i := range template.Spec.EnvironmentList {
+		if template.Spec.EnvironmentList[i].Mode == clv1alpha2.ModeExam {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastionproxy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/bastionproxy"
+)
+
+var _ = Describe("Targets", func() {
+	const hostKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILTt8yqcYTpoHYckGUirDXAVbKUiURms7F9xsz6Z7HXK"
+
+	DescribeTable("Parsing the target from the username",
+		func(user string, expected bastionproxy.Target, valid bool) {
+			target, err := bastionproxy.ParseTarget(user)
+			if !valid {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target).To(Equal(expected))
+		},
+		Entry("instance only", "instance", bastionproxy.Target{Instance: "instance"}, true),
+		Entry("with namespace", "tenant-foo/instance", bastionproxy.Target{Namespace: "tenant-foo", Instance: "instance"}, true),
+		Entry("with environment", "instance:app", bastionproxy.Target{Instance: "instance", Environment: "app"}, true),
+		Entry("with namespace and environment", "tenant-foo/instance:app",
+			bastionproxy.Target{Namespace: "tenant-foo", Instance: "instance", Environment: "app"}, true),
+		Entry("empty", "", bastionproxy.Target{}, false),
+		Entry("empty namespace", "/instance", bastionproxy.Target{}, false),
+		Entry("empty environment", "instance:", bastionproxy.Target{}, false),
+	)
+
+	Describe("Resolving the instance", func() {
+		const workspace = "netgroup"
+
+		var (
+			ctx      context.Context
+			c        client.Client
+			tenant   clv1alpha2.Tenant
+			target   bastionproxy.Target
+			instance *clv1alpha2.Instance
+			err      error
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			tenant = clv1alpha2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "tester"}}
+			target = bastionproxy.Target{Instance: "instance"}
+
+			c = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				&clv1alpha2.Instance{
+					ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "tenant-tester"},
+					Spec:       clv1alpha2.InstanceSpec{Tenant: clv1alpha2.GenericRef{Name: "tester"}},
+				},
+				&clv1alpha2.Instance{
+					ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "tenant-other",
+						Labels: map[string]string{"crownlabs.polito.it/workspace": workspace}},
+					Spec: clv1alpha2.InstanceSpec{Tenant: clv1alpha2.GenericRef{Name: "other"}},
+				},
+			).Build()
+		})
+
+		JustBeforeEach(func() {
+			instance, err = bastionproxy.ResolveInstance(ctx, c, &tenant, &target)
+		})
+
+		When("the instance is owned by the tenant", func() {
+			It("should default the namespace and return the instance", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(target.Namespace).To(Equal("tenant-tester"))
+				Expect(instance.GetNamespace()).To(Equal("tenant-tester"))
+			})
+		})
+
+		When("the instance is owned by another tenant", func() {
+			BeforeEach(func() { target.Namespace = "tenant-other" })
+
+			It("should deny the access to plain users", func() {
+				Expect(err).To(HaveOccurred())
+			})
+
+			When("the tenant manages the workspace", func() {
+				BeforeEach(func() {
+					tenant.Spec.Workspaces = []clv1alpha2.TenantWorkspaceEntry{{Name: workspace, Role: clv1alpha2.Manager}}
+				})
+
+				It("should return the instance", func() {
+					Expect(err).ToNot(HaveOccurred())
+					Expect(instance.GetNamespace()).To(Equal("tenant-other"))
+				})
+			})
+		})
+
+		When("the instance does not exist", func() {
+			BeforeEach(func() { target.Instance = "missing" })
+
+			It("should return an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	DescribeTable("Retrieving the destination",
+		func(running bool, target bastionproxy.Target, expected string) {
+			instance := clv1alpha2.Instance{
+				Spec: clv1alpha2.InstanceSpec{Running: running},
+				Status: clv1alpha2.InstanceStatus{IP: "10.0.0.1", Environments: []clv1alpha2.InstanceEnvironmentStatus{
+					{Name: "vm", Phase: clv1alpha2.EnvironmentPhaseReady, IP: "10.0.0.1", SSHHostKey: hostKey},
+					{Name: "app", Phase: clv1alpha2.EnvironmentPhaseReady, IP: "10.0.0.2", SSHHostKey: hostKey},
+					{Name: "starting", Phase: clv1alpha2.EnvironmentPhaseStarting, IP: "10.0.0.3", SSHHostKey: hostKey},
+					{Name: "unknown-key", Phase: clv1alpha2.EnvironmentPhaseReady, IP: "10.0.0.4"},
+					{Name: "invalid-key", Phase: clv1alpha2.EnvironmentPhaseReady, IP: "10.0.0.5", SSHHostKey: "invalid"},
+				}},
+			}
+
+			ip, key, err := bastionproxy.Destination(&instance, target)
+			if expected == "" {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ip).To(Equal(expected))
+			Expect(string(ssh.MarshalAuthorizedKey(key))).To(Equal(hostKey + "\n"))
+		},
+		Entry("default environment", true, bastionproxy.Target{}, "10.0.0.1"),
+		Entry("named environment", true, bastionproxy.Target{Environment: "app"}, "10.0.0.2"),
+		Entry("unknown environment", true, bastionproxy.Target{Environment: "missing"}, ""),
+		Entry("environment not ready", true, bastionproxy.Target{Environment: "starting"}, ""),
+		Entry("host key not recorded", true, bastionproxy.Target{Environment: "unknown-key"}, ""),
+		Entry("host key not valid", true, bastionproxy.Target{Environment: "invalid-key"}, ""),
+		Entry("stopped instance", false, bastionproxy.Target{}, ""),
+	)
+})
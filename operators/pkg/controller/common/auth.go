@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -43,9 +44,22 @@ type KeycloakActor struct {
 	RolesClientID string            // The client ID of the client in which the roles are defined.
 	clientIDCache map[string]string // Cache for client IDs to avoid multiple requests to Keycloak
 	cacheMutex    sync.RWMutex
+
+	// CacheTTL is the lifetime of the cached users, roles and role mappings. If zero, they are
+	// not cached, while the internal client identifiers are cached indefinitely.
+	CacheTTL time.Duration
+	// GroupMode configures the actor to map the roles to Keycloak groups, rather than client roles.
+	GroupMode bool
+
+	clientIDCacheExpiry map[string]time.Time // Expiration of the cached client IDs (no expiration if missing)
+	cache               keycloakCache
 }
 
-const tokenRefreshBuffer = 30 // the token is considered about to expire if it has less than this many seconds left
+const (
+	tokenRefreshBuffer = 30 // the token is considered about to expire if it has less than this many seconds left
+
+	roleMutationBatchSize = 50 // the maximum number of roles added to (or removed from) a user with a single request
+)
 
 var actor KeycloakActor
 var actorIface KeycloakActorIface = &actor
@@ -84,6 +98,12 @@ func SetupKeycloakActor(
 	return nil
 }
 
+// ConfigureKeycloakActor configures the caching and the role mapping mode of the KeycloakActor.
+func ConfigureKeycloakActor(cacheTTL time.Duration, groupMode bool) {
+	actor.CacheTTL = cacheTTL
+	actor.GroupMode = groupMode
+}
+
 // GetKeycloakActor returns the KcActor currently used.
 func GetKeycloakActor() KeycloakActorIface {
 	return actorIface
@@ -109,6 +129,8 @@ func (a *KeycloakActor) Reset(log logr.Logger) {
 	a.credentials.ClientID = ""
 	a.credentials.ClientSecret = ""
 	a.clientIDCache = nil
+	a.clientIDCacheExpiry = nil
+	a.cache.clear()
 	log.Info("Keycloak actor has been reset")
 }
 
@@ -147,6 +169,12 @@ func (a *KeycloakActor) GetUser(
 ) (*gocloak.User, error) {
 	log := klog.FromContext(ctx)
 
+	if a.CacheTTL > 0 {
+		if user, found := a.cache.getUser(username); found {
+			return user, nil
+		}
+	}
+
 	users, err := a.Client.GetUsers(ctx, a.GetAccessToken(ctx), a.Realm, gocloak.GetUsersParams{
 		Username: &username,
 	})
@@ -178,9 +206,17 @@ func (a *KeycloakActor) GetUser(
 		user = users[0]
 	}
 
+	if a.CacheTTL > 0 {
+		a.cache.setUser(username, user, a.CacheTTL)
+	}
 	return user, nil
 }
 
+// InvalidateUser discards the cached information about the given user.
+func (a *KeycloakActor) InvalidateUser(username string) {
+	a.cache.invalidateUser(username)
+}
+
 // CreateUser creates a user in Keycloak.
 func (a *KeycloakActor) CreateUser(
 	ctx context.Context,
@@ -216,6 +252,7 @@ func (a *KeycloakActor) CreateUser(
 		return "", err
 	}
 
+	a.cache.invalidateUser(username)
 	return userID, nil
 }
 
@@ -224,6 +261,7 @@ func (a *KeycloakActor) DeleteUser(
 	ctx context.Context,
 	userID string,
 ) error {
+	defer a.cache.invalidateUserID(userID)
 	return a.Client.DeleteUser(ctx, a.GetAccessToken(ctx), a.Realm, userID)
 }
 
@@ -236,7 +274,8 @@ func (a *KeycloakActor) getClientInternalIdentifierByClientID(
 
 	// Check if the client ID is already in cache
 	a.cacheMutex.RLock()
-	if internalID, exists := a.clientIDCache[clientID]; exists {
+	expiration, expires := a.clientIDCacheExpiry[clientID]
+	if internalID, exists := a.clientIDCache[clientID]; exists && (!expires || time.Now().Before(expiration)) {
 		a.cacheMutex.RUnlock()
 		return internalID, nil
 	}
@@ -258,6 +297,12 @@ func (a *KeycloakActor) getClientInternalIdentifierByClientID(
 	// Store the client ID in the cache
 	a.cacheMutex.Lock()
 	a.clientIDCache[clientID] = *clients[0].ID
+	if a.CacheTTL > 0 {
+		if a.clientIDCacheExpiry == nil {
+			a.clientIDCacheExpiry = make(map[string]time.Time)
+		}
+		a.clientIDCacheExpiry[clientID] = time.Now().Add(a.CacheTTL)
+	}
 	a.cacheMutex.Unlock()
 
 	return *clients[0].ID, nil
@@ -270,6 +315,16 @@ func (a *KeycloakActor) GetRole(
 ) (*gocloak.Role, error) {
 	log := klog.FromContext(ctx)
 
+	if a.CacheTTL > 0 {
+		if role, found := a.cache.getRole(roleName); found {
+			return role, nil
+		}
+	}
+
+	if a.GroupMode {
+		return a.getGroupRole(ctx, roleName)
+	}
+
 	clientID, err := a.getClientInternalIdentifierByClientID(ctx, a.RolesClientID)
 	if err != nil {
 		log.Error(err, "Unable to get client internal identifier from keycloak")
@@ -292,6 +347,9 @@ func (a *KeycloakActor) GetRole(
 		return nil, err
 	}
 
+	if a.CacheTTL > 0 {
+		a.cache.setRole(roleName, role, a.CacheTTL)
+	}
 	return role, nil
 }
 
@@ -303,6 +361,11 @@ func (a *KeycloakActor) CreateRole(
 ) (string, error) {
 	log := klog.FromContext(ctx)
 
+	defer a.cache.invalidateRole(roleName)
+	if a.GroupMode {
+		return a.createGroupRole(ctx, roleName, roleDescription)
+	}
+
 	role := gocloak.Role{
 		Name:        &roleName,
 		Description: &roleDescription,
@@ -336,6 +399,11 @@ func (a *KeycloakActor) DeleteRole(
 ) error {
 	log := klog.FromContext(ctx)
 
+	defer a.cache.invalidateRole(roleName)
+	if a.GroupMode {
+		return a.deleteGroupRole(ctx, roleName)
+	}
+
 	clientID, err := a.getClientInternalIdentifierByClientID(ctx, a.RolesClientID)
 	if err != nil {
 		log.Error(err, "Unable to get client internal identifier from keycloak")
@@ -367,6 +435,20 @@ func (a *KeycloakActor) GetUserRoles(
 ) ([]*gocloak.Role, error) {
 	log := klog.FromContext(ctx)
 
+	if a.CacheTTL > 0 {
+		if roles, found := a.cache.getUserRoles(userID); found {
+			return roles, nil
+		}
+	}
+
+	if a.GroupMode {
+		roles, err := a.getUserGroupRoles(ctx, userID)
+		if err == nil && a.CacheTTL > 0 {
+			a.cache.setUserRoles(userID, roles, a.CacheTTL)
+		}
+		return roles, err
+	}
+
 	clientID, err := a.getClientInternalIdentifierByClientID(ctx, a.RolesClientID)
 	if err != nil {
 		log.Error(err, "Unable to get client internal identifier from keycloak")
@@ -389,10 +471,14 @@ func (a *KeycloakActor) GetUserRoles(
 		return nil, err
 	}
 
+	if a.CacheTTL > 0 {
+		a.cache.setUserRoles(userID, roles, a.CacheTTL)
+	}
 	return roles, nil
 }
 
 // AddUserToRoles adds a user to the specified roles in Keycloak.
+// The roles are assigned in batches, to limit the number of requests.
 func (a *KeycloakActor) AddUserToRoles(
 	ctx context.Context,
 	userID string,
@@ -400,38 +486,54 @@ func (a *KeycloakActor) AddUserToRoles(
 ) error {
 	log := klog.FromContext(ctx)
 
-	clientID, err := a.getClientInternalIdentifierByClientID(ctx, a.RolesClientID)
-	if err != nil {
-		log.Error(err, "Unable to get client internal identifier from keycloak")
-		return err
+	rolesVal := uniqueRoles(roles)
+	if len(rolesVal) == 0 {
+		return nil
 	}
 
-	// Convert []*gocloak.Role to []gocloak.Role
-	rolesVal := make([]gocloak.Role, len(roles))
-	for i, r := range roles {
-		if r != nil {
-			rolesVal[i] = *r
+	if a.GroupMode {
+		if err := a.addUserToGroups(ctx, userID, rolesVal); err != nil {
+			a.cache.invalidateUserID(userID)
+			return err
+		}
+	} else {
+		clientID, err := a.getClientInternalIdentifierByClientID(ctx, a.RolesClientID)
+		if err != nil {
+			log.Error(err, "Unable to get client internal identifier from keycloak")
+			return err
 		}
-	}
-
-	err = a.Client.AddClientRolesToUser(
-		ctx,
-		a.GetAccessToken(ctx),
-		a.Realm,
-		clientID,
-		userID,
-		rolesVal,
-	)
 
-	if err != nil {
-		log.Error(err, "Unable to add user to role in keycloak")
-		return err
+		for batch := range slices.Chunk(rolesVal, roleMutationBatchSize) {
+			err = a.Client.AddClientRolesToUser(
+				ctx,
+				a.GetAccessToken(ctx),
+				a.Realm,
+				clientID,
+				userID,
+				batch,
+			)
+
+			if err != nil {
+				log.Error(err, "Unable to add user to role in keycloak")
+				a.cache.invalidateUserID(userID)
+				return err
+			}
+		}
 	}
 
+	a.cache.updateUserRoles(userID, func(current []gocloak.Role) []gocloak.Role {
+		for _, role := range rolesVal {
+			if !slices.ContainsFunc(current, func(r gocloak.Role) bool { return sameRole(&r, &role) }) {
+				current = append(current, role)
+			}
+		}
+		return current
+	})
 	return nil
 }
 
 // RemoveUserFromRoles removes a user from the specified roles in Keycloak.
+// The roles are removed in batches, to limit the number of requests.
 func (a *KeycloakActor) RemoveUserFromRoles(
 	ctx context.Context,
 	userID string,
@@ -439,33 +541,61 @@ func (a *KeycloakActor) RemoveUserFromRoles(
 ) error {
 	log := klog.FromContext(ctx)
 
-	clientID, err := a.getClientInternalIdentifierByClientID(ctx, a.RolesClientID)
-	if err != nil {
-		log.Error(err, "Unable to get client internal identifier from keycloak")
-		return err
+	rolesVal := uniqueRoles(roles)
+	if len(rolesVal) == 0 {
+		return nil
 	}
 
-	// Convert []*gocloak.Role to []gocloak.Role
-	rolesVal := make([]gocloak.Role, len(roles))
-	for i, r := range roles {
-		if r != nil {
-			rolesVal[i] = *r
+	if a.GroupMode {
+		if err := a.removeUserFromGroups(ctx, userID, rolesVal); err != nil {
+			a.cache.invalidateUserID(userID)
+			return err
+		}
+	} else {
+		clientID, err := a.getClientInternalIdentifierByClientID(ctx, a.RolesClientID)
+		if err != nil {
+			log.Error(err, "Unable to get client internal identifier from keycloak")
+			return err
+		}
+
+		for batch := range slices.Chunk(rolesVal, roleMutationBatchSize) {
+			err = a.Client.DeleteClientRolesFromUser(
+				ctx,
+				a.GetAccessToken(ctx),
+				a.Realm,
+				clientID,
+				userID,
+				batch,
+			)
+
+			if err != nil {
+				log.Error(err, "Unable to remove user from role in keycloak")
+				a.cache.invalidateUserID(userID)
+				return err
+			}
 		}
 	}
 
-	err = a.Client.DeleteClientRolesFromUser(
-		ctx,
-		a.GetAccessToken(ctx),
-		a.Realm,
-		clientID,
-		userID,
-		rolesVal,
-	)
+	a.cache.updateUserRoles(userID, func(current []gocloak.Role) []gocloak.Role {
+		return slices.DeleteFunc(current, func(r gocloak.Role) bool {
+			return slices.ContainsFunc(rolesVal, func(role gocloak.Role) bool { return sameRole(&r, &role) })
+		})
+	})
+	return nil
+}
 
-	if err != nil {
-		log.Error(err, "Unable to remove user from role in keycloak")
-		return err
+// uniqueRoles converts the given roles to values, discarding the nil and the duplicated ones.
+func uniqueRoles(roles []*gocloak.Role) []gocloak.Role {
+	unique := make([]gocloak.Role, 0, len(roles))
+	for _, role := range roles {
+		if role != nil && !slices.ContainsFunc(unique, func(r gocloak.Role) bool { return sameRole(&r, role) }) {
+			unique = append(unique, *role)
+		}
 	}
+	return unique
+}
 
-	return nil
+// sameRole returns whether the two roles are the same, comparing their names.
+func sameRole(a, b *gocloak.Role) bool {
+	return a.Name != nil && b.Name != nil && *a.Name == *b.Name
 }
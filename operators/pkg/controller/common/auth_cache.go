@@ -0,0 +1,174 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// IdentityCacheInvalidator is implemented by the identity providers caching the information
+// about the users, to allow the invalidation of the stale entries (e.g., upon Keycloak events).
+type IdentityCacheInvalidator interface {
+	// InvalidateUser discards the cached information about the given user.
+	InvalidateUser(username string)
+}
+
+// keycloakCache caches the users, the roles and the role mappings retrieved from Keycloak,
+// to avoid issuing the same requests at every reconciliation. The zero value is ready to use.
+type keycloakCache struct {
+	mutex     sync.Mutex
+	users     map[string]keycloakCacheEntry[gocloak.User]   // indexed by username
+	roles     map[string]keycloakCacheEntry[gocloak.Role]   // indexed by role name
+	userRoles map[string]keycloakCacheEntry[[]gocloak.Role] // indexed by user ID
+}
+
+type keycloakCacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func cacheLookup[T any](entries map[string]keycloakCacheEntry[T], key string) (T, bool) {
+	entry, found := entries[key]
+	if !found || !time.Now().Before(entry.expiresAt) {
+		var zero T
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func cacheStore[T any](entries *map[string]keycloakCacheEntry[T], key string, value T, ttl time.Duration) {
+	if *entries == nil {
+		*entries = make(map[string]keycloakCacheEntry[T])
+	}
+	(*entries)[key] = keycloakCacheEntry[T]{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+// getUser returns a copy of the cached user with the given username, if any.
+func (c *keycloakCache) getUser(username string) (*gocloak.User, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	user, found := cacheLookup(c.users, username)
+	return &user, found
+}
+
+func (c *keycloakCache) setUser(username string, user *gocloak.User, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	cacheStore(&c.users, username, *user, ttl)
+}
+
+// invalidateUser discards the cached user with the given username, as well as its role mappings.
+func (c *keycloakCache) invalidateUser(username string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if entry, found := c.users[username]; found && entry.value.ID != nil {
+		delete(c.userRoles, *entry.value.ID)
+	}
+	delete(c.users, username)
+}
+
+// invalidateUserID discards the cached user with the given identifier, as well as its role mappings.
+func (c *keycloakCache) invalidateUserID(userID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for username, entry := range c.users {
+		if entry.value.ID != nil && *entry.value.ID == userID {
+			delete(c.users, username)
+		}
+	}
+	delete(c.userRoles, userID)
+}
+
+// getRole returns a copy of the cached role with the given name, if any.
+func (c *keycloakCache) getRole(name string) (*gocloak.Role, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	role, found := cacheLookup(c.roles, name)
+	return &role, found
+}
+
+func (c *keycloakCache) setRole(name string, role *gocloak.Role, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	cacheStore(&c.roles, name, *role, ttl)
+}
+
+// invalidateRole discards the cached role with the given name, as well as all the cached
+// role mappings, since they may refer to it.
+func (c *keycloakCache) invalidateRole(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.roles, name)
+	c.userRoles = nil
+}
+
+// getUserRoles returns a copy of the cached roles assigned to the given user, if any.
+func (c *keycloakCache) getUserRoles(userID string) ([]*gocloak.Role, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	roles, found := cacheLookup(c.userRoles, userID)
+	if !found {
+		return nil, false
+	}
+	return toRolePointers(roles), true
+}
+
+func (c *keycloakCache) setUserRoles(userID string, roles []*gocloak.Role, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	cacheStore(&c.userRoles, userID, toRoleValues(roles), ttl)
+}
+
+// updateUserRoles applies the given change to the cached roles of the user, if present,
+// so that they do not need to be retrieved again after a mutation performed by the operator.
+func (c *keycloakCache) updateUserRoles(userID string, update func([]gocloak.Role) []gocloak.Role) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if entry, found := c.userRoles[userID]; found {
+		entry.value = update(entry.value)
+		c.userRoles[userID] = entry
+	}
+}
+
+// clear discards all the cached entries.
+func (c *keycloakCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.users = nil
+	c.roles = nil
+	c.userRoles = nil
+}
+
+func toRoleValues(roles []*gocloak.Role) []gocloak.Role {
+	values := make([]gocloak.Role, 0, len(roles))
+	for _, role := range roles {
+		if role != nil {
+			values = append(values, *role)
+		}
+	}
+	return values
+}
+
+func toRolePointers(roles []gocloak.Role) []*gocloak.Role {
+	pointers := make([]*gocloak.Role, len(roles))
+	for i := range roles {
+		role := roles[i]
+		pointers[i] = &role
+	}
+	return pointers
+}
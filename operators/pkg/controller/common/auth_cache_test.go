@@ -0,0 +1,245 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/mock"
+)
+
+var _ = Describe("Auth cache and group mode", func() {
+	var (
+		ctx       context.Context
+		mockCtrl  *gomock.Controller
+		mKcClient *mock.MockGoCloakIface
+	)
+
+	const (
+		userID   = "test-user-id"
+		username = "test-user"
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockCtrl = gomock.NewController(GinkgoT())
+		mKcClient = mock.NewMockGoCloakIface(mockCtrl)
+
+		actor = KeycloakActor{
+			Client:         mKcClient,
+			Realm:          "test-realm",
+			token:          &gocloak.JWT{AccessToken: "test-token"},
+			tokenExpiresAt: time.Now().Unix() + 3600, // valid for 1 hour
+			RolesClientID:  "roles-client-id",
+			clientIDCache:  map[string]string{"roles-client-id": "internal-roles-client-id"},
+			CacheTTL:       time.Minute,
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Describe("Caching", func() {
+		It("should cache the users until invalidated", func() {
+			mKcClient.EXPECT().GetUsers(gomock.Any(), "test-token", "test-realm", gomock.Any()).Return([]*gocloak.User{{
+				ID:            gocloak.StringP(userID),
+				Username:      gocloak.StringP(username),
+				EmailVerified: gocloak.BoolP(false),
+			}}, nil).Times(2)
+
+			for range 3 {
+				user, err := actor.GetUser(ctx, username)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*user.ID).To(Equal(userID))
+			}
+
+			actor.InvalidateUser(username)
+			_, err := actor.GetUser(ctx, username)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should not cache the users if the TTL is zero", func() {
+			actor.CacheTTL = 0
+			mKcClient.EXPECT().GetUsers(gomock.Any(), "test-token", "test-realm", gomock.Any()).Return([]*gocloak.User{{
+				ID:       gocloak.StringP(userID),
+				Username: gocloak.StringP(username),
+			}}, nil).Times(2)
+
+			for range 2 {
+				_, err := actor.GetUser(ctx, username)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("should cache the roles, and invalidate them when deleted", func() {
+			mKcClient.EXPECT().GetClientRole(gomock.Any(), "test-token", "test-realm", "internal-roles-client-id", "role1").
+				Return(&gocloak.Role{ID: gocloak.StringP("id1"), Name: gocloak.StringP("role1")}, nil).Times(2)
+			mKcClient.EXPECT().DeleteClientRole(gomock.Any(), "test-token", "test-realm", "internal-roles-client-id", "role1").Return(nil)
+
+			for range 2 {
+				role, err := actor.GetRole(ctx, "role1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*role.ID).To(Equal("id1"))
+			}
+
+			Expect(actor.DeleteRole(ctx, "role1")).To(Succeed())
+			_, err := actor.GetRole(ctx, "role1")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should keep the cached user roles up to date with the mutations", func() {
+			mKcClient.EXPECT().GetClientRolesByUserID(gomock.Any(), "test-token", "test-realm", "internal-roles-client-id", userID).
+				Return([]*gocloak.Role{{Name: gocloak.StringP("role1")}, {Name: gocloak.StringP("role2")}}, nil)
+			mKcClient.EXPECT().AddClientRolesToUser(gomock.Any(), "test-token", "test-realm", "internal-roles-client-id", userID,
+				[]gocloak.Role{{Name: gocloak.StringP("role3")}}).Return(nil)
+			mKcClient.EXPECT().DeleteClientRolesFromUser(gomock.Any(), "test-token", "test-realm", "internal-roles-client-id", userID,
+				[]gocloak.Role{{Name: gocloak.StringP("role1")}}).Return(nil)
+
+			_, err := actor.GetUserRoles(ctx, userID)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(actor.AddUserToRoles(ctx, userID, []*gocloak.Role{{Name: gocloak.StringP("role3")}})).To(Succeed())
+			Expect(actor.RemoveUserFromRoles(ctx, userID, []*gocloak.Role{{Name: gocloak.StringP("role1")}})).To(Succeed())
+
+			roles, err := actor.GetUserRoles(ctx, userID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roles).To(HaveLen(2))
+			Expect(*roles[0].Name).To(Equal("role2"))
+			Expect(*roles[1].Name).To(Equal("role3"))
+		})
+
+		It("should discard the cached user roles if a mutation fails", func() {
+			mKcClient.EXPECT().GetClientRolesByUserID(gomock.Any(), "test-token", "test-realm", "internal-roles-client-id", userID).
+				Return([]*gocloak.Role{}, nil).Times(2)
+			mKcClient.EXPECT().AddClientRolesToUser(gomock.Any(), "test-token", "test-realm", "internal-roles-client-id", userID, gomock.Any()).
+				Return(fmt.Errorf("error"))
+
+			_, err := actor.GetUserRoles(ctx, userID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actor.AddUserToRoles(ctx, userID, []*gocloak.Role{{Name: gocloak.StringP("role1")}})).NotTo(Succeed())
+			_, err = actor.GetUserRoles(ctx, userID)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should expire the cached client identifiers", func() {
+			actor.clientIDCacheExpiry = map[string]time.Time{"roles-client-id": time.Now().Add(-time.Second)}
+			mKcClient.EXPECT().GetClients(gomock.Any(), "test-token", "test-realm", gomock.Any()).
+				Return([]*gocloak.Client{{ID: gocloak.StringP("new-internal-id")}}, nil)
+
+			id, err := actor.getClientInternalIdentifierByClientID(ctx, "roles-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal("new-internal-id"))
+		})
+	})
+
+	Describe("Batching", func() {
+		It("should add the roles in batches, discarding duplicates", func() {
+			roles := []*gocloak.Role{}
+			for i := range 2*roleMutationBatchSize + 10 {
+				roles = append(roles, &gocloak.Role{Name: gocloak.StringP(fmt.Sprintf("role%d", i))})
+			}
+			roles = append(roles, roles[0], nil)
+
+			sizes := []int{}
+			mKcClient.EXPECT().AddClientRolesToUser(gomock.Any(), "test-token", "test-realm", "internal-roles-client-id", userID, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _, _, _, _ string, batch []gocloak.Role) error {
+					sizes = append(sizes, len(batch))
+					return nil
+				}).Times(3)
+
+			Expect(actor.AddUserToRoles(ctx, userID, roles)).To(Succeed())
+			Expect(sizes).To(Equal([]int{roleMutationBatchSize, roleMutationBatchSize, 10}))
+		})
+
+		It("should not perform any request if no roles are provided", func() {
+			Expect(actor.AddUserToRoles(ctx, userID, nil)).To(Succeed())
+			Expect(actor.RemoveUserFromRoles(ctx, userID, []*gocloak.Role{})).To(Succeed())
+		})
+	})
+
+	Describe("Group mode", func() {
+		BeforeEach(func() {
+			actor.GroupMode = true
+			actor.CacheTTL = 0
+		})
+
+		It("should map the roles to top-level groups", func() {
+			mKcClient.EXPECT().GetGroupByPath(gomock.Any(), "test-token", "test-realm", "/workspace-ws:user").
+				Return(&gocloak.Group{
+					ID:         gocloak.StringP("group-id"),
+					Name:       gocloak.StringP("workspace-ws:user"),
+					Attributes: &map[string][]string{"description": {"Workspace ws"}},
+				}, nil)
+
+			role, err := actor.GetRole(ctx, "workspace-ws:user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*role.ID).To(Equal("group-id"))
+			Expect(*role.Name).To(Equal("workspace-ws:user"))
+			Expect(*role.Description).To(Equal("Workspace ws"))
+		})
+
+		It("should report the missing groups as not found", func() {
+			mKcClient.EXPECT().GetGroupByPath(gomock.Any(), "test-token", "test-realm", "/missing").
+				Return(nil, fmt.Errorf("404 Not Found: Could not find group by path"))
+
+			_, err := actor.GetRole(ctx, "missing")
+			Expect(IsIdentityNotFound(err)).To(BeTrue())
+		})
+
+		It("should create and delete the groups", func() {
+			mKcClient.EXPECT().CreateGroup(gomock.Any(), "test-token", "test-realm", gocloak.Group{
+				Name:       gocloak.StringP("workspace-ws:user"),
+				Attributes: &map[string][]string{"description": {"Workspace ws"}},
+			}).Return("group-id", nil)
+			mKcClient.EXPECT().GetGroupByPath(gomock.Any(), "test-token", "test-realm", "/workspace-ws:user").
+				Return(&gocloak.Group{ID: gocloak.StringP("group-id"), Name: gocloak.StringP("workspace-ws:user")}, nil)
+			mKcClient.EXPECT().DeleteGroup(gomock.Any(), "test-token", "test-realm", "group-id").Return(nil)
+
+			name, err := actor.CreateRole(ctx, "workspace-ws:user", "Workspace ws")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("workspace-ws:user"))
+			Expect(actor.DeleteRole(ctx, "workspace-ws:user")).To(Succeed())
+		})
+
+		It("should manage the group memberships of the users", func() {
+			mKcClient.EXPECT().GetUserGroups(gomock.Any(), "test-token", "test-realm", userID, gomock.Any()).
+				Return([]*gocloak.Group{{ID: gocloak.StringP("g1"), Name: gocloak.StringP("workspace-ws:user")}}, nil)
+			mKcClient.EXPECT().AddUserToGroup(gomock.Any(), "test-token", "test-realm", userID, "g2").Return(nil)
+			mKcClient.EXPECT().GetGroupByPath(gomock.Any(), "test-token", "test-realm", "/workspace-other:user").
+				Return(&gocloak.Group{ID: gocloak.StringP("g3"), Name: gocloak.StringP("workspace-other:user")}, nil)
+			mKcClient.EXPECT().AddUserToGroup(gomock.Any(), "test-token", "test-realm", userID, "g3").Return(nil)
+			mKcClient.EXPECT().DeleteUserFromGroup(gomock.Any(), "test-token", "test-realm", userID, "g1").Return(nil)
+
+			roles, err := actor.GetUserRoles(ctx, userID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roles).To(HaveLen(1))
+			Expect(*roles[0].Name).To(Equal("workspace-ws:user"))
+
+			Expect(actor.AddUserToRoles(ctx, userID, []*gocloak.Role{
+				{ID: gocloak.StringP("g2"), Name: gocloak.StringP("workspace-ws:manager")},
+				{Name: gocloak.StringP("workspace-other:user")},
+			})).To(Succeed())
+			Expect(actor.RemoveUserFromRoles(ctx, userID, roles)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,177 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Nerzal/gocloak/v13"
+	"k8s.io/klog/v2"
+)
+
+// groupDescriptionAttribute -> the attribute of the Keycloak groups storing the description of the corresponding role.
+const groupDescriptionAttribute = "description"
+
+// getGroupRole returns the top-level group with the given name, represented as a role.
+func (a *KeycloakActor) getGroupRole(
+	ctx context.Context,
+	roleName string,
+) (*gocloak.Role, error) {
+	log := klog.FromContext(ctx)
+
+	group, err := a.Client.GetGroupByPath(ctx, a.GetAccessToken(ctx), a.Realm, "/"+roleName)
+	if err != nil && strings.Contains(err.Error(), "404") {
+		log.Info("Group not found in Keycloak", "groupName", roleName)
+		return nil, errIdentityNotFound()
+	} else if err != nil {
+		log.Error(err, "Unable to get group from keycloak")
+		return nil, err
+	}
+
+	role := groupToRole(group)
+	if a.CacheTTL > 0 {
+		a.cache.setRole(roleName, role, a.CacheTTL)
+	}
+	return role, nil
+}
+
+// createGroupRole creates a new top-level group corresponding to the given role.
+func (a *KeycloakActor) createGroupRole(
+	ctx context.Context,
+	roleName string,
+	roleDescription string,
+) (string, error) {
+	group := gocloak.Group{
+		Name:       &roleName,
+		Attributes: &map[string][]string{groupDescriptionAttribute: {roleDescription}},
+	}
+
+	if _, err := a.Client.CreateGroup(ctx, a.GetAccessToken(ctx), a.Realm, group); err != nil {
+		klog.FromContext(ctx).Error(err, "Unable to create group in keycloak")
+		return "", err
+	}
+	return roleName, nil
+}
+
+// deleteGroupRole removes the top-level group corresponding to the given role, if it exists.
+func (a *KeycloakActor) deleteGroupRole(
+	ctx context.Context,
+	roleName string,
+) error {
+	role, err := a.getGroupRole(ctx, roleName)
+	if IsIdentityNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	err = a.Client.DeleteGroup(ctx, a.GetAccessToken(ctx), a.Realm, *role.ID)
+	if err != nil && !strings.Contains(err.Error(), "404") {
+		klog.FromContext(ctx).Error(err, "Unable to delete group from keycloak")
+		return err
+	}
+	return nil
+}
+
+// getUserGroupRoles returns the groups the user is member of, represented as roles.
+func (a *KeycloakActor) getUserGroupRoles(
+	ctx context.Context,
+	userID string,
+) ([]*gocloak.Role, error) {
+	log := klog.FromContext(ctx)
+
+	groups, err := a.Client.GetUserGroups(ctx, a.GetAccessToken(ctx), a.Realm, userID, gocloak.GetGroupsParams{
+		BriefRepresentation: gocloak.BoolP(true),
+	})
+	if err != nil && strings.Contains(err.Error(), "404") {
+		log.Info("User not found in Keycloak", "userID", userID)
+		return nil, errIdentityNotFound()
+	} else if err != nil {
+		log.Error(err, "Unable to get user groups from keycloak")
+		return nil, err
+	}
+
+	roles := make([]*gocloak.Role, 0, len(groups))
+	for _, group := range groups {
+		if group != nil {
+			roles = append(roles, groupToRole(group))
+		}
+	}
+	return roles, nil
+}
+
+// addUserToGroups adds the user as member of the groups corresponding to the given roles.
+// Keycloak does not support batched group membership changes, hence one request per group is performed.
+func (a *KeycloakActor) addUserToGroups(
+	ctx context.Context,
+	userID string,
+	roles []gocloak.Role,
+) error {
+	return a.forEachGroup(ctx, roles, func(groupID string) error {
+		return a.Client.AddUserToGroup(ctx, a.GetAccessToken(ctx), a.Realm, userID, groupID)
+	})
+}
+
+// removeUserFromGroups removes the user from the groups corresponding to the given roles.
+func (a *KeycloakActor) removeUserFromGroups(
+	ctx context.Context,
+	userID string,
+	roles []gocloak.Role,
+) error {
+	return a.forEachGroup(ctx, roles, func(groupID string) error {
+		return a.Client.DeleteUserFromGroup(ctx, a.GetAccessToken(ctx), a.Realm, userID, groupID)
+	})
+}
+
+// forEachGroup invokes the given function with the identifier of each of the groups corresponding to the given roles.
+func (a *KeycloakActor) forEachGroup(
+	ctx context.Context,
+	roles []gocloak.Role,
+	fn func(groupID string) error,
+) error {
+	log := klog.FromContext(ctx)
+
+	for i := range roles {
+		groupID, name := roles[i].ID, gocloak.PString(roles[i].Name)
+		if groupID == nil {
+			if name == "" {
+				continue
+			}
+			role, err := a.GetRole(ctx, name)
+			if err != nil {
+				return err
+			}
+			groupID = role.ID
+		}
+
+		if err := fn(*groupID); err != nil {
+			log.Error(err, "Unable to update group membership in keycloak", "group", name)
+			return err
+		}
+	}
+	return nil
+}
+
+// groupToRole converts a Keycloak group into the corresponding role representation.
+func groupToRole(group *gocloak.Group) *gocloak.Role {
+	role := &gocloak.Role{ID: group.ID, Name: group.Name}
+	if group.Attributes != nil {
+		if description := (*group.Attributes)[groupDescriptionAttribute]; len(description) > 0 {
+			role.Description = gocloak.StringP(description[0])
+		}
+	}
+	return role
+}
@@ -0,0 +1,160 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// KeycloakIdentityProvider adapts a KeycloakActorIface to the provider-neutral IdentityProviderIface,
+// converting the gocloak representations of users and roles, which are confined to the Keycloak actors.
+type KeycloakIdentityProvider struct {
+	Actor KeycloakActorIface
+}
+
+// NewKeycloakIdentityProvider creates a new KeycloakIdentityProvider backed by the given actor.
+func NewKeycloakIdentityProvider(actor KeycloakActorIface) *KeycloakIdentityProvider {
+	return &KeycloakIdentityProvider{Actor: actor}
+}
+
+// IsInitialized checks if the underlying Keycloak actor has been initialized.
+func (p *KeycloakIdentityProvider) IsInitialized() bool {
+	return p.Actor.IsInitialized()
+}
+
+// GetUser returns the user associated with the given username.
+func (p *KeycloakIdentityProvider) GetUser(ctx context.Context, username string) (*User, error) {
+	user, err := p.Actor.GetUser(ctx, username)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return userFromGocloak(user), nil
+}
+
+// CreateUser creates a user in Keycloak, returning its identifier.
+func (p *KeycloakIdentityProvider) CreateUser(
+	ctx context.Context,
+	username string,
+	email string,
+	firstName string,
+	lastName string,
+) (string, error) {
+	return p.Actor.CreateUser(ctx, username, email, firstName, lastName)
+}
+
+// DeleteUser removes a user from Keycloak.
+func (p *KeycloakIdentityProvider) DeleteUser(ctx context.Context, userID string) error {
+	return p.Actor.DeleteUser(ctx, userID)
+}
+
+// GetRole returns the role with the given name.
+func (p *KeycloakIdentityProvider) GetRole(ctx context.Context, roleName string) (*Role, error) {
+	role, err := p.Actor.GetRole(ctx, roleName)
+	if err != nil || role == nil {
+		return nil, err
+	}
+	return roleFromGocloak(role), nil
+}
+
+// CreateRole creates a new role in Keycloak, returning its name.
+func (p *KeycloakIdentityProvider) CreateRole(ctx context.Context, roleName, roleDescription string) (string, error) {
+	return p.Actor.CreateRole(ctx, roleName, roleDescription)
+}
+
+// DeleteRole removes a role from Keycloak.
+func (p *KeycloakIdentityProvider) DeleteRole(ctx context.Context, roleName string) error {
+	return p.Actor.DeleteRole(ctx, roleName)
+}
+
+// GetUserRoles returns the roles assigned to the given user.
+func (p *KeycloakIdentityProvider) GetUserRoles(ctx context.Context, userID string) ([]*Role, error) {
+	gocloakRoles, err := p.Actor.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]*Role, 0, len(gocloakRoles))
+	for _, role := range gocloakRoles {
+		if role != nil {
+			roles = append(roles, roleFromGocloak(role))
+		}
+	}
+	return roles, nil
+}
+
+// AddUserToRoles assigns the given roles to the user.
+func (p *KeycloakIdentityProvider) AddUserToRoles(ctx context.Context, userID string, roles []*Role) error {
+	return p.Actor.AddUserToRoles(ctx, userID, rolesToGocloak(roles))
+}
+
+// RemoveUserFromRoles removes the given roles from the user.
+func (p *KeycloakIdentityProvider) RemoveUserFromRoles(ctx context.Context, userID string, roles []*Role) error {
+	return p.Actor.RemoveUserFromRoles(ctx, userID, rolesToGocloak(roles))
+}
+
+// InvalidateUser discards the cached information about the given user, if the underlying actor caches it.
+func (p *KeycloakIdentityProvider) InvalidateUser(username string) {
+	if invalidator, ok := p.Actor.(IdentityCacheInvalidator); ok {
+		invalidator.InvalidateUser(username)
+	}
+}
+
+// userFromGocloak converts the gocloak representation of a user into the provider-neutral one.
+func userFromGocloak(user *gocloak.User) *User {
+	return &User{
+		ID:            gocloak.PString(user.ID),
+		Username:      gocloak.PString(user.Username),
+		Email:         gocloak.PString(user.Email),
+		FirstName:     gocloak.PString(user.FirstName),
+		LastName:      gocloak.PString(user.LastName),
+		Enabled:       gocloak.PBool(user.Enabled),
+		EmailVerified: gocloak.PBool(user.EmailVerified),
+	}
+}
+
+// roleFromGocloak converts the gocloak representation of a role into the provider-neutral one.
+func roleFromGocloak(role *gocloak.Role) *Role {
+	return &Role{
+		ID:          gocloak.PString(role.ID),
+		Name:        gocloak.PString(role.Name),
+		Description: gocloak.PString(role.Description),
+	}
+}
+
+// rolesToGocloak converts the provider-neutral representations of the given roles into the gocloak ones.
+func rolesToGocloak(roles []*Role) []*gocloak.Role {
+	converted := make([]*gocloak.Role, 0, len(roles))
+	for _, role := range roles {
+		if role == nil {
+			continue
+		}
+		converted = append(converted, &gocloak.Role{
+			ID:          optionalString(role.ID),
+			Name:        gocloak.StringP(role.Name),
+			Description: optionalString(role.Description),
+		})
+	}
+	return converted
+}
+
+// optionalString returns a pointer to the given string, or nil if it is empty.
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
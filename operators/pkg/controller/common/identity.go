@@ -0,0 +1,119 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+)
+
+// User is the provider-neutral representation of a user managed by the identity provider.
+type User struct {
+	ID            string
+	Username      string
+	Email         string
+	FirstName     string
+	LastName      string
+	Enabled       bool
+	EmailVerified bool
+}
+
+// Role is the provider-neutral representation of a role managed by the identity provider.
+type Role struct {
+	ID          string
+	Name        string
+	Description string
+}
+
+// IdentityProviderIface is the provider-neutral interface implemented by the
+// identity providers the operator can rely on to manage users and roles.
+// Each implementation is responsible for converting its own representations
+// of users and roles into the User and Role structures.
+type IdentityProviderIface interface {
+	// IsInitialized checks if the identity provider has been initialized.
+	IsInitialized() bool
+	// GetUser returns the user associated with the given username.
+	GetUser(ctx context.Context, username string) (*User, error)
+	// CreateUser creates a user, returning its identifier.
+	CreateUser(ctx context.Context, username string, email string, firstName string, lastName string) (string, error)
+	// DeleteUser removes the user with the given identifier.
+	DeleteUser(ctx context.Context, userID string) error
+	// GetRole returns the role with the given name.
+	GetRole(ctx context.Context, roleName string) (*Role, error)
+	// CreateRole creates a new role, returning its name.
+	CreateRole(ctx context.Context, roleName string, roleDescription string) (string, error)
+	// DeleteRole removes the role with the given name.
+	DeleteRole(ctx context.Context, roleName string) error
+	// GetUserRoles returns the roles assigned to the given user.
+	GetUserRoles(ctx context.Context, userID string) ([]*Role, error)
+	// AddUserToRoles assigns the given roles to the user.
+	AddUserToRoles(ctx context.Context, userID string, roles []*Role) error
+	// RemoveUserFromRoles removes the given roles from the user.
+	RemoveUserFromRoles(ctx context.Context, userID string, roles []*Role) error
+}
+
+// IdentityProviderType is an enumeration of the supported identity providers.
+type IdentityProviderType string
+
+const (
+	// IdentityProviderKeycloak -> users and roles are managed through the Keycloak admin APIs.
+	IdentityProviderKeycloak IdentityProviderType = "keycloak"
+	// IdentityProviderSCIM -> users and roles (as groups) are managed through a SCIM 2.0 endpoint.
+	IdentityProviderSCIM IdentityProviderType = "scim"
+	// IdentityProviderMemory -> users and roles are kept in memory, for local development only.
+	IdentityProviderMemory IdentityProviderType = "memory"
+)
+
+// ParseIdentityProviderType validates the given identity provider type.
+func ParseIdentityProviderType(value string) (IdentityProviderType, error) {
+	switch provider := IdentityProviderType(value); provider {
+	case IdentityProviderKeycloak, IdentityProviderSCIM, IdentityProviderMemory:
+		return provider, nil
+	default:
+		return "", fmt.Errorf("unsupported identity provider %q", value)
+	}
+}
+
+// identityProvider is the identity provider configured through SetIdentityProvider, if any.
+var identityProvider IdentityProviderIface
+
+// SetIdentityProvider configures the identity provider returned by GetIdentityProvider.
+func SetIdentityProvider(provider IdentityProviderIface, log logr.Logger) {
+	identityProvider = provider
+	log.Info("Identity provider configured", "provider", fmt.Sprintf("%T", provider))
+}
+
+// GetIdentityProvider returns the identity provider currently used.
+// Unless a different one has been configured, it is backed by the current Keycloak actor.
+func GetIdentityProvider() IdentityProviderIface {
+	if identityProvider != nil {
+		return identityProvider
+	}
+	return NewKeycloakIdentityProvider(GetKeycloakActor())
+}
+
+// errIdentityNotFound returns the error conventionally used by the identity
+// providers to signal that the requested user or role does not exist.
+func errIdentityNotFound() error {
+	return fmt.Errorf("%d", http.StatusNotFound)
+}
+
+// IsIdentityNotFound returns whether the given error signals that the requested user or role does not exist.
+func IsIdentityNotFound(err error) bool {
+	return err != nil && err.Error() == errIdentityNotFound().Error()
+}
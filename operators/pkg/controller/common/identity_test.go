@@ -0,0 +1,195 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/mock"
+)
+
+var _ = Describe("Identity providers", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Describe("ParseIdentityProviderType", func() {
+		It("should accept the supported identity providers", func() {
+			for _, value := range []string{"keycloak", "scim", "memory"} {
+				provider, err := ParseIdentityProviderType(value)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(provider).To(BeEquivalentTo(value))
+			}
+		})
+
+		It("should reject unknown identity providers", func() {
+			_, err := ParseIdentityProviderType("ldap")
+			Expect(err).To(MatchError(ContainSubstring("unsupported identity provider")))
+		})
+	})
+
+	Describe("SetIdentityProvider", func() {
+		It("should replace the provider returned by GetIdentityProvider", func() {
+			previous := identityProvider
+			DeferCleanup(func() { identityProvider = previous })
+
+			identityProvider = nil
+			Expect(GetIdentityProvider()).To(BeAssignableToTypeOf(&KeycloakIdentityProvider{}))
+
+			provider := SetupMemoryIdentityProvider(true, logr.Discard())
+			Expect(GetIdentityProvider()).To(BeIdenticalTo(provider))
+		})
+	})
+
+	Describe("KeycloakIdentityProvider", func() {
+		var (
+			actor    *mock.MockKeycloakActorIface
+			provider *KeycloakIdentityProvider
+		)
+
+		BeforeEach(func() {
+			actor = mock.NewMockKeycloakActorIface(gomock.NewController(GinkgoT()))
+			provider = NewKeycloakIdentityProvider(actor)
+		})
+
+		It("should convert the users returned by the actor", func() {
+			actor.EXPECT().GetUser(gomock.Any(), "john").Return(&gocloak.User{
+				ID:            gocloak.StringP("user-id"),
+				Username:      gocloak.StringP("john"),
+				Email:         gocloak.StringP("john@example.com"),
+				EmailVerified: gocloak.BoolP(true),
+			}, nil)
+
+			user, err := provider.GetUser(ctx, "john")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user).To(Equal(&User{ID: "user-id", Username: "john", Email: "john@example.com", EmailVerified: true}))
+		})
+
+		It("should convert the roles from and to the ones handled by the actor", func() {
+			actor.EXPECT().GetUserRoles(gomock.Any(), "user-id").Return([]*gocloak.Role{
+				{ID: gocloak.StringP("role-id"), Name: gocloak.StringP("workspace-ws:user")},
+			}, nil)
+			actor.EXPECT().RemoveUserFromRoles(gomock.Any(), "user-id", []*gocloak.Role{
+				{ID: gocloak.StringP("role-id"), Name: gocloak.StringP("workspace-ws:user")},
+			}).Return(nil)
+
+			roles, err := provider.GetUserRoles(ctx, "user-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roles).To(ConsistOf(&Role{ID: "role-id", Name: "workspace-ws:user"}))
+			Expect(provider.RemoveUserFromRoles(ctx, "user-id", roles)).To(Succeed())
+		})
+
+		It("should propagate the errors returned by the actor", func() {
+			actor.EXPECT().GetRole(gomock.Any(), "missing").Return(nil, errIdentityNotFound())
+
+			_, err := provider.GetRole(ctx, "missing")
+			Expect(IsIdentityNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("MemoryIdentityProvider", func() {
+		var provider *MemoryIdentityProvider
+
+		BeforeEach(func() {
+			provider = NewMemoryIdentityProvider(false)
+		})
+
+		It("should be initialized", func() {
+			Expect(provider.IsInitialized()).To(BeTrue())
+		})
+
+		It("should report missing users as not found", func() {
+			_, err := provider.GetUser(ctx, "missing")
+			Expect(IsIdentityNotFound(err)).To(BeTrue())
+		})
+
+		It("should create users and track their verification", func() {
+			userID, err := provider.CreateUser(ctx, "john", "john@example.com", "John", "Doe")
+			Expect(err).NotTo(HaveOccurred())
+
+			user, err := provider.GetUser(ctx, "john")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user.ID).To(Equal(userID))
+			Expect(user.Email).To(Equal("john@example.com"))
+			Expect(user.EmailVerified).To(BeFalse())
+
+			Expect(provider.SetUserVerified("john", true)).To(Succeed())
+			user, err = provider.GetUser(ctx, "john")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user.EmailVerified).To(BeTrue())
+
+			Expect(provider.DeleteUser(ctx, userID)).To(Succeed())
+			_, err = provider.GetUser(ctx, "john")
+			Expect(IsIdentityNotFound(err)).To(BeTrue())
+		})
+
+		It("should verify the users upon creation if configured", func() {
+			provider.AutoVerify = true
+			_, err := provider.CreateUser(ctx, "john", "john@example.com", "John", "Doe")
+			Expect(err).NotTo(HaveOccurred())
+
+			user, err := provider.GetUser(ctx, "john")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user.EmailVerified).To(BeTrue())
+		})
+
+		It("should manage the roles assigned to the users", func() {
+			userID, err := provider.CreateUser(ctx, "john", "john@example.com", "John", "Doe")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = provider.GetRole(ctx, "workspace-ws:user")
+			Expect(IsIdentityNotFound(err)).To(BeTrue())
+
+			for _, name := range []string{"workspace-ws:user", "workspace-ws:manager"} {
+				_, err = provider.CreateRole(ctx, name, "description")
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			user, err := provider.GetRole(ctx, "workspace-ws:user")
+			Expect(err).NotTo(HaveOccurred())
+			manager, err := provider.GetRole(ctx, "workspace-ws:manager")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(provider.AddUserToRoles(ctx, userID, []*Role{user, manager})).To(Succeed())
+			Expect(provider.RemoveUserFromRoles(ctx, userID, []*Role{manager})).To(Succeed())
+
+			roles, err := provider.GetUserRoles(ctx, userID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roles).To(HaveLen(1))
+			Expect(roles[0].Name).To(Equal("workspace-ws:user"))
+
+			Expect(provider.DeleteRole(ctx, "workspace-ws:user")).To(Succeed())
+			roles, err = provider.GetUserRoles(ctx, userID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roles).To(BeEmpty())
+		})
+
+		It("should reject the assignment of missing roles", func() {
+			userID, err := provider.CreateUser(ctx, "john", "john@example.com", "John", "Doe")
+			Expect(err).NotTo(HaveOccurred())
+
+			err = provider.AddUserToRoles(ctx, userID, []*Role{{Name: "missing"}})
+			Expect(IsIdentityNotFound(err)).To(BeTrue())
+		})
+	})
+})
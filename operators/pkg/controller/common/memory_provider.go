@@ -0,0 +1,251 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// MemoryIdentityProvider is an identity provider keeping users and roles in memory.
+// It is meant for local development and testing only, as the state is lost at every restart.
+type MemoryIdentityProvider struct {
+	// AutoVerify configures whether newly created users are considered verified.
+	AutoVerify bool
+
+	mutex     sync.RWMutex
+	users     map[string]*User    // indexed by username
+	roles     map[string]*Role    // indexed by role name
+	userRoles map[string][]string // role names, indexed by user ID
+}
+
+// NewMemoryIdentityProvider creates a new, empty, MemoryIdentityProvider.
+func NewMemoryIdentityProvider(autoVerify bool) *MemoryIdentityProvider {
+	return &MemoryIdentityProvider{
+		AutoVerify: autoVerify,
+		users:      make(map[string]*User),
+		roles:      make(map[string]*Role),
+		userRoles:  make(map[string][]string),
+	}
+}
+
+// SetupMemoryIdentityProvider configures a new MemoryIdentityProvider as the current identity provider.
+func SetupMemoryIdentityProvider(autoVerify bool, log logr.Logger) *MemoryIdentityProvider {
+	log.Info("WARNING: the in-memory identity provider is meant for development only")
+	provider := NewMemoryIdentityProvider(autoVerify)
+	SetIdentityProvider(provider, log)
+	return provider
+}
+
+// IsInitialized checks if the MemoryIdentityProvider has been initialized.
+func (p *MemoryIdentityProvider) IsInitialized() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.users != nil
+}
+
+// Reset clears all the users and roles.
+func (p *MemoryIdentityProvider) Reset(log logr.Logger) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.users = make(map[string]*User)
+	p.roles = make(map[string]*Role)
+	p.userRoles = make(map[string][]string)
+	log.Info("In-memory identity provider has been reset")
+}
+
+// GetUser returns the user associated with the given username.
+func (p *MemoryIdentityProvider) GetUser(_ context.Context, username string) (*User, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	user, found := p.users[username]
+	if !found {
+		return nil, errIdentityNotFound()
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+// CreateUser creates a new user, returning its identifier.
+func (p *MemoryIdentityProvider) CreateUser(
+	_ context.Context,
+	username string,
+	email string,
+	firstName string,
+	lastName string,
+) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if user, found := p.users[username]; found {
+		return user.ID, nil
+	}
+
+	userID := string(uuid.NewUUID())
+	p.users[username] = &User{
+		ID:            userID,
+		Username:      username,
+		Email:         email,
+		FirstName:     firstName,
+		LastName:      lastName,
+		Enabled:       true,
+		EmailVerified: p.AutoVerify,
+	}
+	return userID, nil
+}
+
+// SetUserVerified sets the verification status of the given user, emulating the confirmation of the email address.
+func (p *MemoryIdentityProvider) SetUserVerified(username string, verified bool) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	user, found := p.users[username]
+	if !found {
+		return errIdentityNotFound()
+	}
+	user.EmailVerified = verified
+	return nil
+}
+
+// DeleteUser removes the user with the given identifier.
+func (p *MemoryIdentityProvider) DeleteUser(_ context.Context, userID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for username, user := range p.users {
+		if user.ID == userID {
+			delete(p.users, username)
+			delete(p.userRoles, userID)
+			return nil
+		}
+	}
+	return errIdentityNotFound()
+}
+
+// GetRole returns the role with the given name.
+func (p *MemoryIdentityProvider) GetRole(_ context.Context, roleName string) (*Role, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	role, found := p.roles[roleName]
+	if !found {
+		return nil, errIdentityNotFound()
+	}
+	copied := *role
+	return &copied, nil
+}
+
+// CreateRole creates a new role, returning its name.
+func (p *MemoryIdentityProvider) CreateRole(_ context.Context, roleName, roleDescription string) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, found := p.roles[roleName]; !found {
+		p.roles[roleName] = &Role{
+			ID:          string(uuid.NewUUID()),
+			Name:        roleName,
+			Description: roleDescription,
+		}
+	}
+	return roleName, nil
+}
+
+// DeleteRole removes the role with the given name, if it exists.
+func (p *MemoryIdentityProvider) DeleteRole(_ context.Context, roleName string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.roles, roleName)
+	for userID, roles := range p.userRoles {
+		p.userRoles[userID] = slices.DeleteFunc(roles, func(name string) bool { return name == roleName })
+	}
+	return nil
+}
+
+// GetUserRoles returns the roles assigned to the given user.
+func (p *MemoryIdentityProvider) GetUserRoles(_ context.Context, userID string) ([]*Role, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if !p.userExists(userID) {
+		return nil, errIdentityNotFound()
+	}
+
+	roles := make([]*Role, 0, len(p.userRoles[userID]))
+	for _, name := range p.userRoles[userID] {
+		if role, found := p.roles[name]; found {
+			copied := *role
+			roles = append(roles, &copied)
+		}
+	}
+	return roles, nil
+}
+
+// AddUserToRoles assigns the given roles to the user.
+func (p *MemoryIdentityProvider) AddUserToRoles(_ context.Context, userID string, roles []*Role) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.userExists(userID) {
+		return errIdentityNotFound()
+	}
+
+	for _, role := range roles {
+		if role == nil || role.Name == "" {
+			continue
+		}
+		if _, found := p.roles[role.Name]; !found {
+			return errIdentityNotFound()
+		}
+		if !slices.Contains(p.userRoles[userID], role.Name) {
+			p.userRoles[userID] = append(p.userRoles[userID], role.Name)
+		}
+	}
+	return nil
+}
+
+// RemoveUserFromRoles removes the given roles from the user.
+func (p *MemoryIdentityProvider) RemoveUserFromRoles(_ context.Context, userID string, roles []*Role) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.userExists(userID) {
+		return errIdentityNotFound()
+	}
+
+	p.userRoles[userID] = slices.DeleteFunc(p.userRoles[userID], func(name string) bool {
+		return slices.ContainsFunc(roles, func(role *Role) bool {
+			return role != nil && role.Name == name
+		})
+	})
+	return nil
+}
+
+// userExists returns whether a user with the given identifier exists. It must be called with the mutex held.
+func (p *MemoryIdentityProvider) userExists(userID string) bool {
+	for _, user := range p.users {
+		if user.ID == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,452 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	scimUserSchema    = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema   = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimPatchOpSchema = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	scimContentType   = "application/scim+json"
+
+	scimRequestTimeout = 30 * time.Second
+)
+
+// SCIMIdentityProvider is an identity provider interacting with a SCIM 2.0 (RFC 7643/7644) endpoint.
+// Users are mapped to SCIM Users, while roles are mapped to SCIM Groups, identified by their display name.
+// A user is considered verified once it is active and, if exposed by the provider, its primary email is verified.
+type SCIMIdentityProvider struct {
+	initialized bool
+	BaseURL     string
+	HTTPClient  *http.Client
+	token       string
+
+	// ActivateUsers configures whether the users are created as already active,
+	// rather than waiting for the provider to activate them upon enrollment.
+	ActivateUsers bool
+}
+
+type scimName struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type scimEmail struct {
+	Value    string `json:"value"`
+	Primary  bool   `json:"primary,omitempty"`
+	Verified *bool  `json:"verified,omitempty"`
+}
+
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type scimUser struct {
+	Schemas  []string     `json:"schemas,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	UserName string       `json:"userName"`
+	Name     *scimName    `json:"name,omitempty"`
+	Emails   []scimEmail  `json:"emails,omitempty"`
+	Active   *bool        `json:"active,omitempty"`
+	Groups   []scimMember `json:"groups,omitempty"`
+}
+
+type scimGroup struct {
+	Schemas     []string     `json:"schemas,omitempty"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members,omitempty"`
+}
+
+type scimListResponse[T any] struct {
+	TotalResults int `json:"totalResults"`
+	Resources    []T `json:"Resources"`
+}
+
+type scimOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+type scimPatchRequest struct {
+	Schemas    []string        `json:"schemas"`
+	Operations []scimOperation `json:"Operations"`
+}
+
+// NewSCIMIdentityProvider creates a new SCIMIdentityProvider targeting the given endpoint.
+func NewSCIMIdentityProvider(baseURL, token string, activateUsers bool) *SCIMIdentityProvider {
+	return &SCIMIdentityProvider{
+		initialized:   true,
+		BaseURL:       strings.TrimSuffix(baseURL, "/"),
+		HTTPClient:    &http.Client{Timeout: scimRequestTimeout},
+		token:         token,
+		ActivateUsers: activateUsers,
+	}
+}
+
+// SetupSCIMIdentityProvider creates a new SCIMIdentityProvider, checks that the endpoint
+// is reachable with the given credentials and configures it as the current identity provider.
+func SetupSCIMIdentityProvider(
+	ctx context.Context,
+	baseURL string,
+	token string,
+	activateUsers bool,
+	log logr.Logger,
+) error {
+	provider := NewSCIMIdentityProvider(baseURL, token, activateUsers)
+
+	if err := provider.do(ctx, http.MethodGet, "/ServiceProviderConfig", nil, nil, nil); err != nil {
+		log.Error(err, "Unable to contact the SCIM endpoint", "url", baseURL)
+		return err
+	}
+
+	SetIdentityProvider(provider, log)
+	return nil
+}
+
+// IsInitialized checks if the SCIMIdentityProvider has been initialized.
+func (p *SCIMIdentityProvider) IsInitialized() bool {
+	return p.initialized
+}
+
+// Reset clears the SCIMIdentityProvider's configuration.
+func (p *SCIMIdentityProvider) Reset(log logr.Logger) {
+	p.initialized = false
+	p.BaseURL = ""
+	p.token = ""
+	log.Info("SCIM identity provider has been reset")
+}
+
+// GetUser returns the user associated with the given username.
+func (p *SCIMIdentityProvider) GetUser(
+	ctx context.Context,
+	username string,
+) (*User, error) {
+	log := klog.FromContext(ctx)
+
+	var users scimListResponse[scimUser]
+	query := url.Values{"filter": []string{scimEqualityFilter("userName", username)}}
+	if err := p.do(ctx, http.MethodGet, "/Users", query, nil, &users); err != nil {
+		log.Error(err, "Unable to get user from the SCIM endpoint")
+		return nil, err
+	}
+
+	for i := range users.Resources {
+		// The userName attribute is case insensitive, according to the SCIM specification.
+		if strings.EqualFold(users.Resources[i].UserName, username) {
+			return users.Resources[i].toUser(), nil
+		}
+	}
+
+	log.Info("User not found in the SCIM endpoint", "username", username)
+	return nil, errIdentityNotFound()
+}
+
+// CreateUser creates a user through the SCIM endpoint, returning its identifier.
+func (p *SCIMIdentityProvider) CreateUser(
+	ctx context.Context,
+	username string,
+	email string,
+	firstName string,
+	lastName string,
+) (string, error) {
+	user := scimUser{
+		Schemas:  []string{scimUserSchema},
+		UserName: username,
+		Name:     &scimName{GivenName: firstName, FamilyName: lastName},
+		Emails:   []scimEmail{{Value: email, Primary: true}},
+		Active:   ptr.To(p.ActivateUsers),
+	}
+
+	var created scimUser
+	if err := p.do(ctx, http.MethodPost, "/Users", nil, &user, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// DeleteUser removes a user through the SCIM endpoint.
+func (p *SCIMIdentityProvider) DeleteUser(
+	ctx context.Context,
+	userID string,
+) error {
+	return p.do(ctx, http.MethodDelete, "/Users/"+url.PathEscape(userID), nil, nil, nil)
+}
+
+// GetRole returns the role (i.e. the SCIM Group) with the given name.
+func (p *SCIMIdentityProvider) GetRole(
+	ctx context.Context,
+	roleName string,
+) (*Role, error) {
+	log := klog.FromContext(ctx)
+
+	var groups scimListResponse[scimGroup]
+	query := url.Values{
+		"filter":             []string{scimEqualityFilter("displayName", roleName)},
+		"excludedAttributes": []string{"members"},
+	}
+	if err := p.do(ctx, http.MethodGet, "/Groups", query, nil, &groups); err != nil {
+		log.Error(err, "Unable to get group from the SCIM endpoint")
+		return nil, err
+	}
+
+	for i := range groups.Resources {
+		if groups.Resources[i].DisplayName == roleName {
+			return &Role{
+				ID:   groups.Resources[i].ID,
+				Name: roleName,
+			}, nil
+		}
+	}
+
+	log.Info("Group not found in the SCIM endpoint", "roleName", roleName)
+	return nil, errIdentityNotFound()
+}
+
+// CreateRole creates a new role (i.e. a SCIM Group), returning its name.
+// The description is discarded, since it is not part of the SCIM Group schema.
+func (p *SCIMIdentityProvider) CreateRole(
+	ctx context.Context,
+	roleName string,
+	_ string,
+) (string, error) {
+	group := scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		DisplayName: roleName,
+	}
+
+	if err := p.do(ctx, http.MethodPost, "/Groups", nil, &group, nil); err != nil {
+		klog.FromContext(ctx).Error(err, "Unable to create group in the SCIM endpoint")
+		return "", err
+	}
+	return roleName, nil
+}
+
+// DeleteRole removes a role (i.e. a SCIM Group), if it exists.
+func (p *SCIMIdentityProvider) DeleteRole(
+	ctx context.Context,
+	roleName string,
+) error {
+	role, err := p.GetRole(ctx, roleName)
+	if IsIdentityNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	err = p.do(ctx, http.MethodDelete, "/Groups/"+url.PathEscape(role.ID), nil, nil, nil)
+	if err != nil && !IsIdentityNotFound(err) {
+		klog.FromContext(ctx).Error(err, "Unable to delete group from the SCIM endpoint")
+		return err
+	}
+	return nil
+}
+
+// GetUserRoles returns the roles (i.e. the SCIM Groups) the given user is member of.
+func (p *SCIMIdentityProvider) GetUserRoles(
+	ctx context.Context,
+	userID string,
+) ([]*Role, error) {
+	var user scimUser
+	query := url.Values{"attributes": []string{"groups"}}
+	if err := p.do(ctx, http.MethodGet, "/Users/"+url.PathEscape(userID), query, nil, &user); err != nil {
+		return nil, err
+	}
+
+	roles := make([]*Role, 0, len(user.Groups))
+	for _, membership := range user.Groups {
+		name := membership.Display
+		if name == "" {
+			// The display attribute is optional, hence retrieve the group to obtain its name.
+			var group scimGroup
+			query := url.Values{"attributes": []string{"displayName"}}
+			if err := p.do(ctx, http.MethodGet, "/Groups/"+url.PathEscape(membership.Value), query, nil, &group); err != nil {
+				return nil, err
+			}
+			name = group.DisplayName
+		}
+		roles = append(roles, &Role{ID: membership.Value, Name: name})
+	}
+	return roles, nil
+}
+
+// AddUserToRoles adds the user as member of the given roles (i.e. SCIM Groups).
+func (p *SCIMIdentityProvider) AddUserToRoles(
+	ctx context.Context,
+	userID string,
+	roles []*Role,
+) error {
+	return p.patchMemberships(ctx, roles, scimOperation{
+		Op:    "add",
+		Path:  "members",
+		Value: []scimMember{{Value: userID}},
+	})
+}
+
+// RemoveUserFromRoles removes the user from the members of the given roles (i.e. SCIM Groups).
+func (p *SCIMIdentityProvider) RemoveUserFromRoles(
+	ctx context.Context,
+	userID string,
+	roles []*Role,
+) error {
+	return p.patchMemberships(ctx, roles, scimOperation{
+		Op:   "remove",
+		Path: fmt.Sprintf("members[%s]", scimEqualityFilter("value", userID)),
+	})
+}
+
+// patchMemberships applies the given operation to each of the groups corresponding to the given roles.
+func (p *SCIMIdentityProvider) patchMemberships(
+	ctx context.Context,
+	roles []*Role,
+	operation scimOperation,
+) error {
+	log := klog.FromContext(ctx)
+
+	patch := scimPatchRequest{
+		Schemas:    []string{scimPatchOpSchema},
+		Operations: []scimOperation{operation},
+	}
+
+	for _, role := range roles {
+		if role == nil || role.Name == "" {
+			continue
+		}
+
+		groupID := role.ID
+		if groupID == "" {
+			group, err := p.GetRole(ctx, role.Name)
+			if err != nil {
+				return err
+			}
+			groupID = group.ID
+		}
+
+		if err := p.do(ctx, http.MethodPatch, "/Groups/"+url.PathEscape(groupID), nil, &patch, nil); err != nil {
+			log.Error(err, "Unable to update group members in the SCIM endpoint", "group", role.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+// do performs a request towards the SCIM endpoint, decoding the response into out (if not nil).
+func (p *SCIMIdentityProvider) do(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	in any,
+	out any,
+) error {
+	target := p.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", scimContentType)
+	if in != nil {
+		req.Header.Set("Content-Type", scimContentType)
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	res, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return errIdentityNotFound()
+	case res.StatusCode < 200 || res.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("SCIM request %s %s failed with status %d: %s", method, path, res.StatusCode, strings.TrimSpace(string(detail)))
+	case out == nil || res.StatusCode == http.StatusNoContent:
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// toUser converts the SCIM User into the corresponding provider-neutral representation.
+func (u *scimUser) toUser() *User {
+	user := &User{
+		ID:            u.ID,
+		Username:      u.UserName,
+		Enabled:       u.Active == nil || *u.Active,
+		EmailVerified: u.Active != nil && *u.Active,
+	}
+
+	if u.Name != nil {
+		user.FirstName = u.Name.GivenName
+		user.LastName = u.Name.FamilyName
+	}
+
+	if len(u.Emails) > 0 {
+		email := u.Emails[0]
+		for i := range u.Emails {
+			if u.Emails[i].Primary {
+				email = u.Emails[i]
+				break
+			}
+		}
+
+		user.Email = email.Value
+		if email.Verified != nil && !*email.Verified {
+			user.EmailVerified = false
+		}
+	}
+
+	return user
+}
+
+// scimEqualityFilter returns a SCIM filter matching the resources whose attribute equals the given value.
+func scimEqualityFilter(attribute, value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return fmt.Sprintf(`%s eq "%s"`, attribute, escaped)
+}
@@ -0,0 +1,282 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+)
+
+// fakeSCIMServer is a minimal in-memory SCIM endpoint, supporting the subset of the protocol used by the provider.
+type fakeSCIMServer struct {
+	mutex   sync.Mutex
+	counter int
+	users   map[string]*scimUser
+	groups  map[string]*scimGroup
+	tokens  []string
+}
+
+func newFakeSCIMServer() *fakeSCIMServer {
+	return &fakeSCIMServer{users: map[string]*scimUser{}, groups: map[string]*scimGroup{}}
+}
+
+func (f *fakeSCIMServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, status int, body any) {
+		w.Header().Set("Content-Type", scimContentType)
+		w.WriteHeader(status)
+		if body != nil {
+			_ = json.NewEncoder(w).Encode(body)
+		}
+	}
+	filterValue := func(r *http.Request) string {
+		_, value, _ := strings.Cut(r.URL.Query().Get("filter"), ` eq "`)
+		return strings.TrimSuffix(value, `"`)
+	}
+	nextID := func() string {
+		f.counter++
+		return fmt.Sprintf("id-%d", f.counter)
+	}
+
+	mux.HandleFunc("GET /ServiceProviderConfig", func(w http.ResponseWriter, _ *http.Request) {
+		reply(w, http.StatusOK, map[string]any{})
+	})
+	mux.HandleFunc("GET /Users", func(w http.ResponseWriter, r *http.Request) {
+		list := scimListResponse[scimUser]{}
+		for _, user := range f.users {
+			if user.UserName == filterValue(r) {
+				list.Resources = append(list.Resources, *user)
+			}
+		}
+		list.TotalResults = len(list.Resources)
+		reply(w, http.StatusOK, list)
+	})
+	mux.HandleFunc("POST /Users", func(w http.ResponseWriter, r *http.Request) {
+		var user scimUser
+		Expect(json.NewDecoder(r.Body).Decode(&user)).To(Succeed())
+		user.ID = nextID()
+		f.users[user.ID] = &user
+		reply(w, http.StatusCreated, user)
+	})
+	mux.HandleFunc("GET /Users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		user, found := f.users[r.PathValue("id")]
+		if !found {
+			reply(w, http.StatusNotFound, nil)
+			return
+		}
+		copied := *user
+		for _, group := range f.groups {
+			if slices.ContainsFunc(group.Members, func(m scimMember) bool { return m.Value == user.ID }) {
+				copied.Groups = append(copied.Groups, scimMember{Value: group.ID})
+			}
+		}
+		reply(w, http.StatusOK, copied)
+	})
+	mux.HandleFunc("DELETE /Users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		delete(f.users, r.PathValue("id"))
+		reply(w, http.StatusNoContent, nil)
+	})
+	mux.HandleFunc("GET /Groups", func(w http.ResponseWriter, r *http.Request) {
+		list := scimListResponse[scimGroup]{}
+		for _, group := range f.groups {
+			if group.DisplayName == filterValue(r) {
+				list.Resources = append(list.Resources, *group)
+			}
+		}
+		list.TotalResults = len(list.Resources)
+		reply(w, http.StatusOK, list)
+	})
+	mux.HandleFunc("GET /Groups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		group, found := f.groups[r.PathValue("id")]
+		if !found {
+			reply(w, http.StatusNotFound, nil)
+			return
+		}
+		reply(w, http.StatusOK, scimGroup{ID: group.ID, DisplayName: group.DisplayName})
+	})
+	mux.HandleFunc("POST /Groups", func(w http.ResponseWriter, r *http.Request) {
+		var group scimGroup
+		Expect(json.NewDecoder(r.Body).Decode(&group)).To(Succeed())
+		group.ID = nextID()
+		f.groups[group.ID] = &group
+		reply(w, http.StatusCreated, group)
+	})
+	mux.HandleFunc("PATCH /Groups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		group, found := f.groups[r.PathValue("id")]
+		if !found {
+			reply(w, http.StatusNotFound, nil)
+			return
+		}
+		var patch struct {
+			Operations []struct {
+				Op    string       `json:"op"`
+				Path  string       `json:"path"`
+				Value []scimMember `json:"value"`
+			} `json:"Operations"`
+		}
+		Expect(json.NewDecoder(r.Body).Decode(&patch)).To(Succeed())
+		for _, op := range patch.Operations {
+			switch op.Op {
+			case "add":
+				group.Members = append(group.Members, op.Value...)
+			case "remove":
+				_, member, _ := strings.Cut(op.Path, `value eq "`)
+				member = strings.TrimSuffix(member, `"]`)
+				group.Members = slices.DeleteFunc(group.Members, func(m scimMember) bool { return m.Value == member })
+			}
+		}
+		reply(w, http.StatusNoContent, nil)
+	})
+	mux.HandleFunc("DELETE /Groups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		delete(f.groups, r.PathValue("id"))
+		reply(w, http.StatusNoContent, nil)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		f.tokens = append(f.tokens, r.Header.Get("Authorization"))
+		mux.ServeHTTP(w, r)
+	})
+}
+
+var _ = Describe("SCIMIdentityProvider", func() {
+	var (
+		ctx      context.Context
+		fake     *fakeSCIMServer
+		server   *httptest.Server
+		provider *SCIMIdentityProvider
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = newFakeSCIMServer()
+		server = httptest.NewServer(fake.handler())
+		DeferCleanup(server.Close)
+		provider = NewSCIMIdentityProvider(server.URL+"/", "secret-token", false)
+	})
+
+	Describe("SetupSCIMIdentityProvider", func() {
+		It("should configure the SCIM provider if the endpoint is reachable", func() {
+			previous := actorIface
+			DeferCleanup(func() { actorIface = previous })
+
+			Expect(SetupSCIMIdentityProvider(ctx, server.URL, "secret-token", false, logr.Discard())).To(Succeed())
+			Expect(GetIdentityProvider()).To(BeAssignableToTypeOf(&SCIMIdentityProvider{}))
+			Expect(GetIdentityProvider().IsInitialized()).To(BeTrue())
+		})
+
+		It("should fail if the endpoint is not reachable", func() {
+			server.Close()
+			Expect(SetupSCIMIdentityProvider(ctx, server.URL, "secret-token", false, logr.Discard())).NotTo(Succeed())
+		})
+	})
+
+	It("should authenticate the requests with the bearer token", func() {
+		_, err := provider.GetUser(ctx, "john")
+		Expect(IsIdentityNotFound(err)).To(BeTrue())
+		Expect(fake.tokens).To(ConsistOf("Bearer secret-token"))
+	})
+
+	It("should create users, initially not verified", func() {
+		userID, err := provider.CreateUser(ctx, "john", "john@example.com", "John", "Doe")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.users).To(HaveKey(userID))
+		Expect(*fake.users[userID].Active).To(BeFalse())
+
+		user, err := provider.GetUser(ctx, "john")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user.ID).To(Equal(userID))
+		Expect(user.Email).To(Equal("john@example.com"))
+		Expect(user.FirstName).To(Equal("John"))
+		Expect(user.EmailVerified).To(BeFalse())
+
+		Expect(provider.DeleteUser(ctx, userID)).To(Succeed())
+		Expect(fake.users).To(BeEmpty())
+	})
+
+	It("should consider the active users as verified, unless their email is explicitly unverified", func() {
+		fake.users["u1"] = &scimUser{ID: "u1", UserName: "john", Active: ptr.To(true),
+			Emails: []scimEmail{{Value: "john@example.com", Primary: true}}}
+		fake.users["u2"] = &scimUser{ID: "u2", UserName: "jane", Active: ptr.To(true),
+			Emails: []scimEmail{{Value: "jane@example.com", Primary: true, Verified: ptr.To(false)}}}
+
+		user, err := provider.GetUser(ctx, "john")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user.EmailVerified).To(BeTrue())
+
+		user, err = provider.GetUser(ctx, "jane")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user.EmailVerified).To(BeFalse())
+	})
+
+	It("should map the roles to groups and manage their members", func() {
+		userID, err := provider.CreateUser(ctx, "john", "john@example.com", "John", "Doe")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = provider.GetRole(ctx, "workspace-ws:user")
+		Expect(IsIdentityNotFound(err)).To(BeTrue())
+
+		name, err := provider.CreateRole(ctx, "workspace-ws:user", "description")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("workspace-ws:user"))
+
+		role, err := provider.GetRole(ctx, "workspace-ws:user")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.groups).To(HaveKey(role.ID))
+
+		// The role is intentionally provided without identifier, to check it is looked up by name.
+		Expect(provider.AddUserToRoles(ctx, userID, []*Role{{Name: role.Name}})).To(Succeed())
+
+		roles, err := provider.GetUserRoles(ctx, userID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(roles).To(HaveLen(1))
+		Expect(roles[0].Name).To(Equal("workspace-ws:user"))
+
+		Expect(provider.RemoveUserFromRoles(ctx, userID, roles)).To(Succeed())
+		roles, err = provider.GetUserRoles(ctx, userID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(roles).To(BeEmpty())
+
+		Expect(provider.DeleteRole(ctx, "workspace-ws:user")).To(Succeed())
+		Expect(fake.groups).To(BeEmpty())
+		Expect(provider.DeleteRole(ctx, "workspace-ws:user")).To(Succeed())
+	})
+
+	It("should report the unexpected responses", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+		})
+		_, err := provider.GetUser(ctx, "john")
+		Expect(err).To(MatchError(ContainSubstring("failed with status 403")))
+	})
+})
+
+var _ = Describe("scimEqualityFilter", func() {
+	It("should escape the quotes in the value", func() {
+		Expect(scimEqualityFilter("userName", `jo"hn`)).To(Equal(`userName eq "jo\"hn"`))
+	})
+})
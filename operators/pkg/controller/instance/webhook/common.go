@@ -0,0 +1,34 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook implements the webhook handlers for instance resources.
+package webhook
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+// InstanceWebhook holds data needed by webhooks.
+type InstanceWebhook struct {
+	Client       client.Client
+	BypassGroups []string
+}
+
+// CheckWebhookOverride verifies the subject who triggered the request can override the webhooks behavior.
+func (iwh *InstanceWebhook) CheckWebhookOverride(req *admission.Request) bool {
+	return utils.MatchOneInStringSlices(iwh.BypassGroups, req.UserInfo.Groups)
+}
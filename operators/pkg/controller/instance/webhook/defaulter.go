@@ -0,0 +1,71 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+// InstanceDefaulter implements a defaulting webhook for Instance resources.
+type InstanceDefaulter struct {
+	admission.CustomDefaulter
+	InstanceWebhook
+}
+
+// Default configures the pretty name of new instances, if not specified, and the labels
+// depending on the referenced template - this method is used by controller runtime.
+// The same configuration is enforced by the instance operator, in case the webhook is not enabled.
+func (id *InstanceDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	instance, ok := obj.(*v1alpha2.Instance)
+	if !ok {
+		return fmt.Errorf("expected an Instance object, got %T", obj)
+	}
+
+	log := ctrl.LoggerFrom(ctx).WithName("defaulter").WithValues("instance", client.ObjectKeyFromObject(instance))
+
+	if instance.Spec.PrettyName == "" {
+		instance.Spec.PrettyName = forge.RandomInstancePrettyName()
+		log.Info("pretty name defaulted", "pretty-name", instance.Spec.PrettyName)
+	}
+
+	var template v1alpha2.Template
+	templateName := types.NamespacedName{Namespace: instance.Spec.Template.Namespace, Name: instance.Spec.Template.Name}
+	if err := id.Client.Get(ctx, templateName, &template); err != nil {
+		if errors.IsNotFound(err) {
+			// The request is rejected by the validating webhook.
+			log.Info("template not found, skipping labels configuration", "template", templateName)
+			return nil
+		}
+		log.Error(err, "failed retrieving the template", "template", templateName)
+		return errors.NewInternalError(fmt.Errorf("failed retrieving template %s: %w", templateName, err))
+	}
+
+	labels, _ := forge.InstanceLabels(instance.GetLabels(), &template, instance)
+	instance.SetLabels(labels)
+	log.Info("labels configured")
+
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/instance/webhook"
+)
+
+var _ = Describe("Defaulter webhook", func() {
+	const (
+		workspaceName = "netgroup"
+		workspaceNs   = "workspace-netgroup"
+		templateName  = "green-tea"
+	)
+
+	var (
+		template *v1alpha2.Template
+		instance *v1alpha2.Instance
+		err      error
+	)
+
+	BeforeEach(func() {
+		template = &v1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: templateName, Namespace: workspaceNs},
+			Spec: v1alpha2.TemplateSpec{
+				WorkspaceRef:    v1alpha2.GenericRef{Name: workspaceName},
+				EnvironmentList: []v1alpha2.Environment{{Name: "app", Persistent: true}},
+			},
+		}
+		instance = &v1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "tenant-tester", Labels: map[string]string{"custom": "label"}},
+			Spec: v1alpha2.InstanceSpec{
+				Template: v1alpha2.GenericRef{Name: templateName, Namespace: workspaceNs},
+				Tenant:   v1alpha2.GenericRef{Name: "tester"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		defaulter := &webhook.InstanceDefaulter{
+			InstanceWebhook: webhook.InstanceWebhook{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build(),
+			},
+		}
+		err = defaulter.Default(ctx, instance)
+	})
+
+	When("the pretty name is not specified", func() {
+		It("Should succeed", func() { Expect(err).ToNot(HaveOccurred()) })
+		It("Should configure a random pretty name", func() { Expect(instance.Spec.PrettyName).ToNot(BeEmpty()) })
+		It("Should configure the labels depending on the template", func() {
+			Expect(instance.GetLabels()).To(HaveKeyWithValue("crownlabs.polito.it/managed-by", "instance"))
+			Expect(instance.GetLabels()).To(HaveKeyWithValue("crownlabs.polito.it/workspace", workspaceName))
+			Expect(instance.GetLabels()).To(HaveKeyWithValue("crownlabs.polito.it/template", templateName))
+			Expect(instance.GetLabels()).To(HaveKeyWithValue("crownlabs.polito.it/persistent", "true"))
+			Expect(instance.GetLabels()).To(HaveKeyWithValue("custom", "label"))
+		})
+	})
+
+	When("the pretty name is specified", func() {
+		BeforeEach(func() { instance.Spec.PrettyName = "My instance" })
+
+		It("Should succeed", func() { Expect(err).ToNot(HaveOccurred()) })
+		It("Should preserve the pretty name", func() { Expect(instance.Spec.PrettyName).To(Equal("My instance")) })
+	})
+
+	When("the template does not exist", func() {
+		BeforeEach(func() { instance.Spec.Template.Name = "black-tea" })
+
+		It("Should succeed", func() { Expect(err).ToNot(HaveOccurred()) })
+		It("Should configure a random pretty name", func() { Expect(instance.Spec.PrettyName).ToNot(BeEmpty()) })
+		It("Should not configure the labels", func() {
+			Expect(instance.GetLabels()).To(Equal(map[string]string{"custom": "label"}))
+		})
+	})
+})
@@ -0,0 +1,360 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/workspace"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+// instancesResource identifies the Instance resources in the errors returned by the webhooks.
+var instancesResource = schema.GroupResource{Group: v1alpha2.GroupVersion.Group, Resource: "instances"}
+
+// InstanceValidator implements a validating webhook for Instance resources.
+type InstanceValidator struct {
+	admission.CustomValidator
+	InstanceWebhook
+}
+
+// ValidateCreate validates a new instance creation request.
+func (iv *InstanceValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	instance, ok := obj.(*v1alpha2.Instance)
+	if !ok {
+		return nil, fmt.Errorf("expected an Instance object, got %T", obj)
+	}
+
+	ctx, req, skip, err := iv.preflight(ctx, instance, "create")
+	if err != nil || skip {
+		return iv.overrideWarnings(skip), err
+	}
+
+	if err := iv.CheckActorAccess(ctx, instance, req.UserInfo.Username); err != nil {
+		return nil, err
+	}
+
+	template, tenant, err := iv.CheckTemplateAccess(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := iv.CheckTenantQuota(ctx, instance, template, tenant, false); err != nil {
+		return nil, err
+	}
+
+	return nil, iv.CheckWorkspaceConstraints(ctx, instance, template, false)
+}
+
+// ValidateUpdate validates an instance update request.
+func (iv *InstanceValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldInstance, ok := oldObj.(*v1alpha2.Instance)
+	if !ok {
+		return nil, fmt.Errorf("expected an Instance object, got %T", oldObj)
+	}
+	newInstance, ok := newObj.(*v1alpha2.Instance)
+	if !ok {
+		return nil, fmt.Errorf("expected an Instance object, got %T", newObj)
+	}
+
+	ctx, req, skip, err := iv.preflight(ctx, newInstance, "update")
+	if err != nil {
+		return nil, err
+	}
+
+	// The immutable fields are enforced also in case of override, since they are never expected to change.
+	if err := iv.CheckImmutableFields(ctx, newInstance, oldInstance); err != nil {
+		return nil, err
+	}
+
+	if skip {
+		return iv.overrideWarnings(skip), nil
+	}
+
+	if err := iv.CheckActorAccess(ctx, newInstance, req.UserInfo.Username); err != nil {
+		return nil, err
+	}
+
+	// Only starting an instance increases the consumed resources.
+	if !oldInstance.Spec.Running && newInstance.Spec.Running {
+		template, tenant, err := iv.CheckTemplateAccess(ctx, newInstance)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := iv.CheckTenantQuota(ctx, newInstance, template, tenant, true); err != nil {
+			return nil, err
+		}
+
+		return nil, iv.CheckWorkspaceConstraints(ctx, newInstance, template, true)
+	}
+
+	ctrl.LoggerFrom(ctx).Info("allowed")
+	return nil, nil
+}
+
+// ValidateDelete validates an instance deletion request.
+func (iv *InstanceValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	instance, ok := obj.(*v1alpha2.Instance)
+	if !ok {
+		return nil, fmt.Errorf("expected an Instance object, got %T", obj)
+	}
+
+	ctrl.LoggerFrom(ctx).WithValues("instance", client.ObjectKeyFromObject(instance), "operation", "delete").Info("allowed")
+	return nil, nil
+}
+
+// preflight configures the logger, retrieves the admission request and checks whether it can skip the validation.
+func (iv *InstanceValidator) preflight(ctx context.Context, instance *v1alpha2.Instance, op string) (context.Context, *admission.Request, bool, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("instance", client.ObjectKeyFromObject(instance), "operation", op)
+	log.Info("processing admission request")
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return ctx, nil, false, fmt.Errorf("failed to get admission request from context: %w", err)
+	}
+
+	if iv.CheckWebhookOverride(&req) {
+		log.Info("admitted: successful override")
+		return ctx, &req, true, nil
+	}
+	return ctx, &req, false, nil
+}
+
+func (iv *InstanceValidator) overrideWarnings(overridden bool) admission.Warnings {
+	if overridden {
+		return admission.Warnings{"webhook check overridden"}
+	}
+	return nil
+}
+
+// CheckImmutableFields verifies that the references to the template and to the tenant of the instance have not been modified.
+func (iv *InstanceValidator) CheckImmutableFields(ctx context.Context, newInstance, oldInstance *v1alpha2.Instance) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if newInstance.Spec.Template != oldInstance.Spec.Template {
+		log.Info("denied: template reference changed")
+		return errors.NewForbidden(instancesResource, newInstance.Name, fmt.Errorf("the template reference of an instance cannot be changed"))
+	}
+
+	if newInstance.Spec.Tenant != oldInstance.Spec.Tenant {
+		log.Info("denied: tenant reference changed")
+		return errors.NewForbidden(instancesResource, newInstance.Name, fmt.Errorf("the tenant reference of an instance cannot be changed"))
+	}
+
+	return nil
+}
+
+// CheckActorAccess verifies that the subject performing the request is either the tenant owning the instance,
+// or a manager (or an assistant) of the workspace the instance belongs to. Indeed, the permissions to manage
+// (and control) instances are granted at the cluster level, hence they need to be restricted to the given workspace.
+func (iv *InstanceValidator) CheckActorAccess(ctx context.Context, instance *v1alpha2.Instance, username string) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if username == instance.Spec.Tenant.Name {
+		return nil
+	}
+
+	var template v1alpha2.Template
+	templateName := types.NamespacedName{Namespace: instance.Spec.Template.Namespace, Name: instance.Spec.Template.Name}
+	if err := iv.Client.Get(ctx, templateName, &template); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("denied: template not found", "template", templateName)
+			return errors.NewForbidden(instancesResource, instance.Name, fmt.Errorf("template %s does not exist", templateName))
+		}
+		log.Error(err, "failed retrieving the template", "template", templateName)
+		return errors.NewInternalError(fmt.Errorf("failed retrieving template %s: %w", templateName, err))
+	}
+
+	var actor v1alpha2.Tenant
+	if err := iv.Client.Get(ctx, types.NamespacedName{Name: username}, &actor); client.IgnoreNotFound(err) != nil {
+		log.Error(err, "failed retrieving the tenant associated to the current actor", "tenant", username)
+		return errors.NewInternalError(fmt.Errorf("failed retrieving tenant %s: %w", username, err))
+	}
+
+	workspaceName := template.Spec.WorkspaceRef.Name
+	if role, found := forge.TenantWorkspaceRole(&actor, workspaceName); found && (role == v1alpha2.Manager || role == v1alpha2.Assistant) {
+		return nil
+	}
+
+	log.Info("denied: actor neither owner nor manager of the instance", "actor", username, "workspace", workspaceName)
+	return errors.NewForbidden(instancesResource, instance.Name,
+		fmt.Errorf("you are neither the owner of the instance nor a manager or an assistant of workspace %s", workspaceName))
+}
+
+// CheckTemplateAccess verifies that the template referenced by the instance exists, and that the tenant owning
+// the instance is enrolled in the corresponding workspace (candidates are not allowed to use the templates).
+// It returns the retrieved template and tenant, to be used for the subsequent checks.
+func (iv *InstanceValidator) CheckTemplateAccess(ctx context.Context, instance *v1alpha2.Instance) (*v1alpha2.Template, *v1alpha2.Tenant, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var template v1alpha2.Template
+	templateName := types.NamespacedName{Namespace: instance.Spec.Template.Namespace, Name: instance.Spec.Template.Name}
+	if err := iv.Client.Get(ctx, templateName, &template); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("denied: template not found", "template", templateName)
+			return nil, nil, errors.NewForbidden(instancesResource, instance.Name, fmt.Errorf("template %s does not exist", templateName))
+		}
+		log.Error(err, "failed retrieving the template", "template", templateName)
+		return nil, nil, errors.NewInternalError(fmt.Errorf("failed retrieving template %s: %w", templateName, err))
+	}
+
+	var tenant v1alpha2.Tenant
+	if err := iv.Client.Get(ctx, types.NamespacedName{Name: instance.Spec.Tenant.Name}, &tenant); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("denied: tenant not found", "tenant", instance.Spec.Tenant.Name)
+			return nil, nil, errors.NewForbidden(instancesResource, instance.Name, fmt.Errorf("tenant %s does not exist", instance.Spec.Tenant.Name))
+		}
+		log.Error(err, "failed retrieving the tenant", "tenant", instance.Spec.Tenant.Name)
+		return nil, nil, errors.NewInternalError(fmt.Errorf("failed retrieving tenant %s: %w", instance.Spec.Tenant.Name, err))
+	}
+
+	workspaceName := template.Spec.WorkspaceRef.Name
+	for i := range tenant.Spec.Workspaces {
+		if tenant.Spec.Workspaces[i].Name == workspaceName && tenant.Spec.Workspaces[i].Role != v1alpha2.Candidate {
+			return &template, &tenant, nil
+		}
+	}
+
+	log.Info("denied: tenant not enrolled in the workspace", "tenant", tenant.Name, "workspace", workspaceName)
+	return nil, nil, errors.NewForbidden(instancesResource, instance.Name,
+		fmt.Errorf("tenant %s is not enrolled in workspace %s, hence it cannot use template %s", tenant.Name, workspaceName, templateName))
+}
+
+// CheckTenantQuota verifies that the instance can be created (or started) without exceeding the quota of the tenant owning it,
+// considering the other instances of the same tenant in the same namespace. In case the instance is being started, only the
+// CPU and memory resources are checked, since the instance is already accounted. Tenants whose quota has not yet been computed are skipped.
+func (iv *InstanceValidator) CheckTenantQuota(ctx context.Context, instance *v1alpha2.Instance, template *v1alpha2.Template,
+	tenant *v1alpha2.Tenant, starting bool) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	tenantQuota := &tenant.Status.Quota
+	if tenantQuota.CPU.IsZero() && tenantQuota.Memory.IsZero() && tenantQuota.Instances == 0 {
+		log.Info("tenant quota not yet computed, skipping check")
+		return nil
+	}
+
+	var instances v1alpha2.InstanceList
+	if err := iv.Client.List(ctx, &instances, client.InNamespace(instance.Namespace)); err != nil {
+		log.Error(err, "failed listing the instances", "namespace", instance.Namespace)
+		return errors.NewInternalError(fmt.Errorf("failed listing the instances in namespace %s: %w", instance.Namespace, err))
+	}
+
+	templates := map[types.NamespacedName]*v1alpha2.Template{{Namespace: template.Namespace, Name: template.Name}: template}
+	usage := forge.InstanceResourceUsage(template, instance.Spec.Running)
+	for i := range instances.Items {
+		other := &instances.Items[i]
+		if other.Name == instance.Name || other.Spec.Tenant.Name != tenant.Name {
+			continue
+		}
+
+		otherTemplateName := types.NamespacedName{Namespace: other.Spec.Template.Namespace, Name: other.Spec.Template.Name}
+		otherTemplate, found := templates[otherTemplateName]
+		if !found {
+			otherTemplate = &v1alpha2.Template{}
+			if err := iv.Client.Get(ctx, otherTemplateName, otherTemplate); err != nil {
+				if errors.IsNotFound(err) {
+					// Instances referring to non-existing templates do not consume any resource.
+					continue
+				}
+				log.Error(err, "failed retrieving the template", "template", otherTemplateName)
+				return errors.NewInternalError(fmt.Errorf("failed retrieving template %s: %w", otherTemplateName, err))
+			}
+			templates[otherTemplateName] = otherTemplate
+		}
+		forge.AddResourceUsage(&usage, forge.InstanceResourceUsage(otherTemplate, other.Spec.Running))
+	}
+
+	quota := &v1alpha1.WorkspaceAggregateQuota{CPU: &tenantQuota.CPU, Memory: &tenantQuota.Memory, Instances: &tenantQuota.Instances}
+	if starting {
+		quota.Instances = nil
+	}
+
+	if violations := forge.WorkspaceAggregateQuotaViolations(quota, &usage); len(violations) > 0 {
+		log.Info("denied: tenant quota exceeded", "violations", violations)
+		return errors.NewForbidden(instancesResource, instance.Name,
+			fmt.Errorf("the quota of tenant %s would be exceeded: %s", tenant.Name, strings.Join(violations, ", ")))
+	}
+
+	return nil
+}
+
+// CheckWorkspaceConstraints verifies that the instance can be created (or started) in the workspace it belongs to,
+// that is the workspace is within its active period and its aggregate quota is not exceeded. In case the instance is
+// being started, only the CPU and memory resources are checked, since the others are already accounted.
+// Instances referring to non-existing workspaces are admitted, as the corresponding errors are reported by the instance operator.
+func (iv *InstanceValidator) CheckWorkspaceConstraints(ctx context.Context, instance *v1alpha2.Instance, template *v1alpha2.Template, starting bool) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	var ws v1alpha1.Workspace
+	if err := iv.Client.Get(ctx, types.NamespacedName{Name: template.Spec.WorkspaceRef.Name}, &ws); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("allowed: workspace not found", "workspace", template.Spec.WorkspaceRef.Name)
+			return nil
+		}
+		log.Error(err, "failed retrieving the workspace", "workspace", template.Spec.WorkspaceRef.Name)
+		return errors.NewInternalError(fmt.Errorf("failed retrieving workspace %s: %w", template.Spec.WorkspaceRef.Name, err))
+	}
+
+	if !forge.IsWorkspaceActive(&ws, time.Now()) {
+		log.Info("denied: workspace not active")
+		reason := "it has been archived"
+		if !forge.WorkspaceStarted(&ws, time.Now()) {
+			reason = fmt.Sprintf("its active period starts at %s", ws.Spec.ActivePeriod.Start.Format(time.RFC3339))
+		}
+		return errors.NewForbidden(instancesResource, instance.Name,
+			fmt.Errorf("workspace %s does not accept new instances, since %s", ws.Name, reason))
+	}
+
+	if ws.Spec.AggregateQuota == nil {
+		log.Info("allowed: no aggregate quota configured")
+		return nil
+	}
+
+	usage, err := workspace.ComputeResourceUsage(ctx, iv.Client, &ws, client.ObjectKeyFromObject(instance))
+	if err != nil {
+		log.Error(err, "failed computing the workspace resource usage")
+		return errors.NewInternalError(err)
+	}
+	forge.AddResourceUsage(&usage, forge.InstanceResourceUsage(template, instance.Spec.Running))
+
+	quota := ws.Spec.AggregateQuota.DeepCopy()
+	if starting {
+		quota.Storage, quota.Instances = nil, nil
+	}
+
+	if violations := forge.WorkspaceAggregateQuotaViolations(quota, &usage); len(violations) > 0 {
+		log.Info("denied: workspace aggregate quota exceeded", "violations", violations)
+		return errors.NewForbidden(instancesResource, instance.Name,
+			fmt.Errorf("the aggregate quota of workspace %s would be exceeded: %s", ws.Name, strings.Join(violations, ", ")))
+	}
+
+	log.Info("allowed")
+	return nil
+}
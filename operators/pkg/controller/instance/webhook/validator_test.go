@@ -0,0 +1,396 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/instance/webhook"
+)
+
+var _ = Describe("Validator webhook", func() {
+	const (
+		workspaceName     = "netgroup"
+		workspaceNs       = "workspace-netgroup"
+		templateName      = "green-tea"
+		instanceNamespace = "tenant-tester"
+	)
+
+	var (
+		workspace *v1alpha1.Workspace
+		template  *v1alpha2.Template
+		tenant    *v1alpha2.Tenant
+		actor     *v1alpha2.Tenant
+		existing  *v1alpha2.Instance
+		instance  *v1alpha2.Instance
+
+		request   admission.Request
+		response  admission.Response
+		validator admission.Handler
+	)
+
+	forgeInstance := func(name string, running bool) *v1alpha2.Instance {
+		return &v1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: instanceNamespace},
+			Spec: v1alpha2.InstanceSpec{
+				Template: v1alpha2.GenericRef{Name: templateName, Namespace: workspaceNs},
+				Tenant:   v1alpha2.GenericRef{Name: "tester"},
+				Running:  running,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		workspace = &v1alpha1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{Name: workspaceName},
+			Spec: v1alpha1.WorkspaceSpec{
+				PrettyName: "Netgroup",
+				AggregateQuota: &v1alpha1.WorkspaceAggregateQuota{
+					CPU:       ptr.To(resource.MustParse("3")),
+					Instances: ptr.To[int64](2),
+				},
+			},
+		}
+		template = &v1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: templateName, Namespace: workspaceNs},
+			Spec: v1alpha2.TemplateSpec{
+				WorkspaceRef: v1alpha2.GenericRef{Name: workspaceName},
+				EnvironmentList: []v1alpha2.Environment{{
+					Name:      "app",
+					Resources: v1alpha2.EnvironmentResources{CPU: 2, Memory: resource.MustParse("1Gi")},
+				}},
+			},
+		}
+		tenant = &v1alpha2.Tenant{
+			ObjectMeta: metav1.ObjectMeta{Name: "tester"},
+			Spec: v1alpha2.TenantSpec{
+				Workspaces: []v1alpha2.TenantWorkspaceEntry{{Name: workspaceName, Role: v1alpha2.User}},
+			},
+			Status: v1alpha2.TenantStatus{
+				Quota: v1alpha2.TenantResourceQuota{CPU: resource.MustParse("10"), Memory: resource.MustParse("10Gi"), Instances: 5},
+			},
+		}
+		actor = &v1alpha2.Tenant{
+			ObjectMeta: metav1.ObjectMeta{Name: "actor"},
+			Spec: v1alpha2.TenantSpec{
+				Workspaces: []v1alpha2.TenantWorkspaceEntry{{Name: workspaceName, Role: v1alpha2.Assistant}},
+			},
+		}
+		existing = forgeInstance("existing", true)
+		instance = forgeInstance("new", false)
+	})
+
+	JustBeforeEach(func() {
+		objects := []client.Object{template, tenant, actor, existing}
+		if workspace != nil {
+			objects = append(objects, workspace)
+		}
+
+		validator = admission.WithCustomValidator(scheme, &v1alpha2.Instance{}, &webhook.InstanceValidator{
+			InstanceWebhook: webhook.InstanceWebhook{
+				Client:       fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+				BypassGroups: bypassGroups,
+			},
+		})
+		response = validator.Handle(ctx, request)
+	})
+
+	When("a stopped instance is created within the quota", func() {
+		BeforeEach(func() { request = forgeRequest(admissionv1.Create, instance, nil) })
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	When("a running instance exceeding the CPU quota is created", func() {
+		BeforeEach(func() { request = forgeRequest(admissionv1.Create, forgeInstance("new", true), nil) })
+
+		It("Should deny the request, with a clear message", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+			Expect(response.Result.Message).To(ContainSubstring("aggregate quota of workspace netgroup"))
+			Expect(response.Result.Message).To(ContainSubstring("cpu (4 requested, 3 available)"))
+		})
+	})
+
+	When("the instance exceeds the instances quota", func() {
+		BeforeEach(func() {
+			workspace.Spec.AggregateQuota.Instances = ptr.To[int64](1)
+			request = forgeRequest(admissionv1.Create, instance, nil)
+		})
+
+		It("Should deny the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("instances (2 requested, 1 available)"))
+		})
+	})
+
+	When("the workspace does not configure the aggregate quota", func() {
+		BeforeEach(func() {
+			workspace.Spec.AggregateQuota = nil
+			request = forgeRequest(admissionv1.Create, forgeInstance("new", true), nil)
+		})
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	When("the template does not exist", func() {
+		BeforeEach(func() {
+			instance.Spec.Template.Name = "black-tea"
+			request = forgeRequest(admissionv1.Create, instance, nil)
+		})
+
+		It("Should deny the request, with a clear message", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+			Expect(response.Result.Message).To(ContainSubstring("template workspace-netgroup/black-tea does not exist"))
+		})
+	})
+
+	When("the tenant does not exist", func() {
+		BeforeEach(func() {
+			instance.Spec.Tenant.Name = "ghost"
+			request = forgeRequest(admissionv1.Create, instance, nil)
+			request.UserInfo.Username = "ghost"
+		})
+
+		It("Should deny the request, with a clear message", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("tenant ghost does not exist"))
+		})
+	})
+
+	When("the tenant is not enrolled in the workspace", func() {
+		BeforeEach(func() {
+			tenant.Spec.Workspaces = []v1alpha2.TenantWorkspaceEntry{{Name: "another", Role: v1alpha2.User}}
+			request = forgeRequest(admissionv1.Create, instance, nil)
+		})
+
+		It("Should deny the request, with a clear message", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("tenant tester is not enrolled in workspace netgroup"))
+		})
+	})
+
+	When("the tenant is a candidate of the workspace", func() {
+		BeforeEach(func() {
+			tenant.Spec.Workspaces[0].Role = v1alpha2.Candidate
+			request = forgeRequest(admissionv1.Create, instance, nil)
+		})
+
+		It("Should deny the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("is not enrolled in workspace netgroup"))
+		})
+	})
+
+	When("the tenant quota would be exceeded", func() {
+		BeforeEach(func() {
+			workspace.Spec.AggregateQuota = nil
+			tenant.Status.Quota.CPU = resource.MustParse("3")
+			request = forgeRequest(admissionv1.Create, forgeInstance("new", true), nil)
+		})
+
+		It("Should deny the request, with a clear message", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+			Expect(response.Result.Message).To(ContainSubstring("quota of tenant tester would be exceeded: cpu (4 requested, 3 available)"))
+		})
+	})
+
+	When("the tenant quota has not yet been computed", func() {
+		BeforeEach(func() {
+			workspace.Spec.AggregateQuota = nil
+			tenant.Status.Quota = v1alpha2.TenantResourceQuota{}
+			request = forgeRequest(admissionv1.Create, forgeInstance("new", true), nil)
+		})
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	When("the workspace does not exist", func() {
+		BeforeEach(func() {
+			workspace = nil
+			request = forgeRequest(admissionv1.Create, forgeInstance("new", true), nil)
+		})
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	When("the request comes from a bypass group", func() {
+		BeforeEach(func() {
+			request = forgeRequest(admissionv1.Create, forgeInstance("new", true), nil)
+			request.UserInfo.Groups = bypassGroups
+		})
+
+		It("Should admit the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Warnings).To(ContainElement("webhook check overridden"))
+		})
+	})
+
+	When("an instance is started", func() {
+		BeforeEach(func() {
+			// The instances quota is already exceeded, but it is not affected by starting the instance.
+			workspace.Spec.AggregateQuota.Instances = ptr.To[int64](1)
+		})
+
+		When("the CPU quota is not exceeded", func() {
+			BeforeEach(func() {
+				workspace.Spec.AggregateQuota.CPU = ptr.To(resource.MustParse("4"))
+				request = forgeRequest(admissionv1.Update, forgeInstance("new", true), instance)
+			})
+
+			It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+		})
+
+		When("the CPU quota is exceeded", func() {
+			BeforeEach(func() { request = forgeRequest(admissionv1.Update, forgeInstance("new", true), instance) })
+
+			It("Should deny the request", func() {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(response.Result.Message).ToNot(ContainSubstring("instances ("))
+			})
+		})
+	})
+
+	When("the active period of the workspace has ended", func() {
+		BeforeEach(func() {
+			workspace.Spec.ActivePeriod = &v1alpha1.WorkspaceActivePeriod{End: &metav1.Time{Time: time.Now().Add(-time.Hour)}}
+			request = forgeRequest(admissionv1.Create, instance, nil)
+		})
+
+		It("Should deny the request, with a clear message", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("it has been archived"))
+		})
+	})
+
+	When("the active period of the workspace has not yet started", func() {
+		BeforeEach(func() {
+			workspace.Spec.ActivePeriod = &v1alpha1.WorkspaceActivePeriod{Start: &metav1.Time{Time: time.Now().Add(time.Hour)}}
+			request = forgeRequest(admissionv1.Update, forgeInstance("new", true), instance)
+		})
+
+		It("Should deny the request, with a clear message", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("its active period starts at"))
+		})
+	})
+
+	When("an already running instance is updated", func() {
+		BeforeEach(func() {
+			updated := existing.DeepCopy()
+			updated.Spec.PrettyName = "Updated"
+			workspace.Spec.AggregateQuota.CPU = ptr.To(resource.MustParse("1"))
+			request = forgeRequest(admissionv1.Update, updated, existing)
+		})
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	When("the template reference of an instance is changed", func() {
+		BeforeEach(func() {
+			updated := existing.DeepCopy()
+			updated.Spec.Template.Name = "black-tea"
+			request = forgeRequest(admissionv1.Update, updated, existing)
+		})
+
+		It("Should deny the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+			Expect(response.Result.Message).To(ContainSubstring("template reference of an instance cannot be changed"))
+		})
+	})
+
+	When("the tenant reference of an instance is changed by a bypass group", func() {
+		BeforeEach(func() {
+			updated := existing.DeepCopy()
+			updated.Spec.Tenant.Name = "another"
+			request = forgeRequest(admissionv1.Update, updated, existing)
+			request.UserInfo.Groups = bypassGroups
+		})
+
+		It("Should deny the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("tenant reference of an instance cannot be changed"))
+		})
+	})
+
+	When("the request comes from a tenant other than the owner", func() {
+		BeforeEach(func() {
+			stopped := existing.DeepCopy()
+			stopped.Spec.Running = false
+			request = forgeRequest(admissionv1.Update, stopped, existing)
+			request.UserInfo.Username = actor.Name
+		})
+
+		When("the tenant is an assistant of the workspace", func() {
+			It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+		})
+
+		When("the tenant is a manager of the workspace", func() {
+			BeforeEach(func() { actor.Spec.Workspaces[0].Role = v1alpha2.Manager })
+
+			It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+		})
+
+		When("the tenant is an assistant of another workspace", func() {
+			BeforeEach(func() { actor.Spec.Workspaces[0].Name = "another" })
+
+			It("Should deny the request, with a clear message", func() {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+				Expect(response.Result.Message).To(ContainSubstring("neither the owner of the instance nor a manager or an assistant of workspace netgroup"))
+			})
+		})
+
+		When("the tenant is a user of the workspace", func() {
+			BeforeEach(func() { actor.Spec.Workspaces[0].Role = v1alpha2.User })
+
+			It("Should deny the request", func() { Expect(response.Allowed).To(BeFalse()) })
+		})
+
+		When("the tenant creates an instance on behalf of the owner, being an assistant of another workspace", func() {
+			BeforeEach(func() {
+				actor.Spec.Workspaces[0].Name = "another"
+				request = forgeRequest(admissionv1.Create, instance, nil)
+				request.UserInfo.Username = actor.Name
+			})
+
+			It("Should deny the request", func() { Expect(response.Allowed).To(BeFalse()) })
+		})
+	})
+
+	When("an instance is deleted", func() {
+		BeforeEach(func() {
+			request = forgeRequest(admissionv1.Delete, nil, existing)
+		})
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+})
@@ -0,0 +1,107 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/tmplctrl"
+)
+
+// templatesResource identifies the Template resources in the errors returned by the webhooks.
+var templatesResource = schema.GroupResource{Group: v1alpha2.GroupVersion.Group, Resource: "templates"}
+
+// TemplateValidator implements a validating webhook for Template resources.
+type TemplateValidator struct {
+	admission.CustomValidator
+	TemplateWebhook
+}
+
+// ValidateCreate validates a new template creation request.
+func (tv *TemplateValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	template, ok := obj.(*v1alpha2.Template)
+	if !ok {
+		return nil, fmt.Errorf("expected a Template object, got %T", obj)
+	}
+
+	return tv.validate(ctx, template, "create")
+}
+
+// ValidateUpdate validates a template update request.
+func (tv *TemplateValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldTemplate, ok := oldObj.(*v1alpha2.Template)
+	if !ok {
+		return nil, fmt.Errorf("expected a Template object, got %T", oldObj)
+	}
+	template, ok := newObj.(*v1alpha2.Template)
+	if !ok {
+		return nil, fmt.Errorf("expected a Template object, got %T", newObj)
+	}
+
+	// Do not prevent other changes (e.g. to the finalizers) of templates which were already invalid.
+	if equality.Semantic.DeepEqual(oldTemplate.Spec, template.Spec) {
+		ctrl.LoggerFrom(ctx).WithValues("template", client.ObjectKeyFromObject(template), "operation", "update").Info("allowed: specification unchanged")
+		return nil, nil
+	}
+
+	return tv.validate(ctx, template, "update")
+}
+
+// ValidateDelete validates a template deletion request.
+func (tv *TemplateValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	template, ok := obj.(*v1alpha2.Template)
+	if !ok {
+		return nil, fmt.Errorf("expected a Template object, got %T", obj)
+	}
+
+	ctrl.LoggerFrom(ctx).WithValues("template", client.ObjectKeyFromObject(template), "operation", "delete").Info("allowed")
+	return nil, nil
+}
+
+// validate checks the specification of the template, through the same validation performed by the template controller,
+// in order to reject at admission time the templates which would prevent the corresponding instances from working correctly.
+func (tv *TemplateValidator) validate(ctx context.Context, template *v1alpha2.Template, op string) (admission.Warnings, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("template", client.ObjectKeyFromObject(template), "operation", op)
+	log.Info("processing admission request")
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admission request from context: %w", err)
+	}
+
+	if tv.CheckWebhookOverride(&req) {
+		log.Info("admitted: successful override")
+		return admission.Warnings{"webhook check overridden"}, nil
+	}
+
+	if problems := tmplctrl.ValidateTemplate(template); len(problems) > 0 {
+		log.Info("denied: invalid template", "problems", problems)
+		return nil, errors.NewForbidden(templatesResource, template.Name, fmt.Errorf("invalid template: %s", strings.Join(problems, "; ")))
+	}
+
+	log.Info("allowed")
+	return nil, nil
+}
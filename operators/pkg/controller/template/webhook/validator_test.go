@@ -0,0 +1,156 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/template/webhook"
+)
+
+var _ = Describe("Validator webhook", func() {
+	var (
+		template *v1alpha2.Template
+		request  admission.Request
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		template = &v1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: "green-tea", Namespace: "workspace-netgroup"},
+			Spec: v1alpha2.TemplateSpec{
+				WorkspaceRef: v1alpha2.GenericRef{Name: "netgroup"},
+				EnvironmentList: []v1alpha2.Environment{{
+					Name:            "app",
+					EnvironmentType: v1alpha2.ClassContainer,
+					Image:           "registry.example.com/app:v1",
+					Resources:       v1alpha2.EnvironmentResources{CPU: 2, ReservedCPUPercentage: 50, Memory: resource.MustParse("1Gi")},
+					SharedVolumeMounts: []v1alpha2.SharedVolumeMountInfo{
+						{MountPath: "/mnt/data"},
+						{MountPath: "/mnt/scripts"},
+					},
+				}},
+				DeleteAfter: "7d",
+			},
+		}
+	})
+
+	// handle submits the request to the validator, which is invoked explicitly rather than in a JustBeforeEach,
+	// since the entries of the table configure the request in the body of the spec.
+	handle := func() {
+		validator := admission.WithCustomValidator(scheme, &v1alpha2.Template{}, &webhook.TemplateValidator{
+			TemplateWebhook: webhook.TemplateWebhook{BypassGroups: bypassGroups},
+		})
+		response = validator.Handle(ctx, request)
+	}
+
+	When("a valid template is created", func() {
+		BeforeEach(func() { request = forgeRequest(admissionv1.Create, template, nil) })
+		JustBeforeEach(handle)
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	When("a template with multiple environments is created", func() {
+		BeforeEach(func() {
+			second := template.Spec.EnvironmentList[0].DeepCopy()
+			second.Name = "db"
+			template.Spec.EnvironmentList = append(template.Spec.EnvironmentList, *second)
+			request = forgeRequest(admissionv1.Create, template, nil)
+		})
+		JustBeforeEach(handle)
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	DescribeTable("Should deny the creation of an invalid template, with a clear message",
+		func(mutate func(*v1alpha2.Template), expected string) {
+			mutate(template)
+			request = forgeRequest(admissionv1.Create, template, nil)
+			handle()
+
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+			Expect(response.Result.Message).To(ContainSubstring(expected))
+		},
+		Entry("When the CPU is zero", func(t *v1alpha2.Template) {
+			t.Spec.EnvironmentList[0].Resources.CPU = 0
+		}, "does not request any CPU core"),
+		Entry("When the reserved CPU percentage exceeds 100", func(t *v1alpha2.Template) {
+			t.Spec.EnvironmentList[0].Resources.ReservedCPUPercentage = 120
+		}, "invalid reserved CPU percentage (120)"),
+		Entry("When deleteAfter is not valid", func(t *v1alpha2.Template) {
+			t.Spec.DeleteAfter = "forever"
+		}, "invalid deleteAfter"),
+		Entry("When the shared volume mount paths overlap", func(t *v1alpha2.Template) {
+			t.Spec.EnvironmentList[0].SharedVolumeMounts[1].MountPath = "/mnt/data/scripts"
+		}, "overlapping paths"),
+	)
+
+	When("an invalid template is updated", func() {
+		BeforeEach(func() {
+			updated := template.DeepCopy()
+			updated.Spec.EnvironmentList[0].Resources.CPU = 0
+			request = forgeRequest(admissionv1.Update, updated, template)
+		})
+		JustBeforeEach(handle)
+
+		It("Should deny the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+		})
+	})
+
+	When("an invalid template is created by a bypass group", func() {
+		BeforeEach(func() {
+			template.Spec.EnvironmentList[0].Resources.CPU = 0
+			request = forgeRequest(admissionv1.Create, template, nil)
+			request.UserInfo.Groups = bypassGroups
+		})
+		JustBeforeEach(handle)
+
+		It("Should admit the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Warnings).To(ContainElement("webhook check overridden"))
+		})
+	})
+
+	When("the metadata of an invalid template are updated", func() {
+		BeforeEach(func() {
+			template.Spec.EnvironmentList[0].Resources.CPU = 0
+			updated := template.DeepCopy()
+			updated.SetLabels(map[string]string{"foo": "bar"})
+			request = forgeRequest(admissionv1.Update, updated, template)
+		})
+		JustBeforeEach(handle)
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	When("a template is deleted", func() {
+		BeforeEach(func() { request = forgeRequest(admissionv1.Delete, nil, template) })
+		JustBeforeEach(handle)
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+})
@@ -18,6 +18,7 @@ package tenant
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -27,6 +28,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/common"
 )
 
 // CheckKeycloakUserVerified checks if the Tenant has already been created in Keycloak
@@ -38,15 +40,15 @@ func (r *Reconciler) CheckKeycloakUserVerified(
 	log logr.Logger,
 	tenant *v1alpha2.Tenant,
 ) (bool, error) {
-	if !r.KeycloakActor.IsInitialized() {
+	if !r.IdentityProvider.IsInitialized() {
 		log.Info("Keycloak actor not initialized, skipping Keycloak status check")
 		return true, nil
 	}
 
 	// Check if the tenant exists in Keycloak
-	user, err := r.KeycloakActor.GetUser(ctx, tenant.Name)
+	user, err := r.IdentityProvider.GetUser(ctx, tenant.Name)
 	if err != nil {
-		if err.Error() == fmt.Sprintf("%d", http.StatusNotFound) {
+		if common.IsIdentityNotFound(err) {
 			log.Info("Tenant not found in Keycloak, creating it")
 
 			// Create the tenant in Keycloak
@@ -59,7 +61,7 @@ func (r *Reconciler) CheckKeycloakUserVerified(
 			log.Info("Tenant created in Keycloak")
 
 			// retrieve newly created user
-			user, err = r.KeycloakActor.GetUser(ctx, tenant.Name)
+			user, err = r.IdentityProvider.GetUser(ctx, tenant.Name)
 			if err != nil {
 				log.Error(err, "Error retrieving newly created tenant in Keycloak")
 				return false, err
@@ -68,23 +70,23 @@ func (r *Reconciler) CheckKeycloakUserVerified(
 			log.Error(err, "Error checking Keycloak status")
 			return false, err
 		}
-	} else if tenant.Status.Keycloak.UserCreated.Name != *user.ID {
-		log.Info("Tenant exists in Keycloak but with a different ID, updating status", "id", *user.ID)
+	} else if tenant.Status.Keycloak.UserCreated.Name != user.ID {
+		log.Info("Tenant exists in Keycloak but with a different ID, updating status", "id", user.ID)
 		// Update the tenant status in the cluster
 		tenant.Status.Keycloak.UserCreated = v1alpha2.NameCreated{
-			Name:    *user.ID,
+			Name:    user.ID,
 			Created: true,
 		}
 	}
 
-	if *user.EmailVerified != tenant.Status.Keycloak.UserConfirmed {
-		log.Info("Email verification status updated in Keycloak", "verified", *user.EmailVerified)
+	if user.EmailVerified != tenant.Status.Keycloak.UserConfirmed {
+		log.Info("Email verification status updated in Keycloak", "verified", user.EmailVerified)
 
 		// Update the tenant status in the cluster
-		tenant.Status.Keycloak.UserConfirmed = *user.EmailVerified
+		tenant.Status.Keycloak.UserConfirmed = user.EmailVerified
 	}
 
-	return *user.EmailVerified, nil
+	return user.EmailVerified, nil
 }
 
 func (r *Reconciler) createTenantInKeycloak(
@@ -92,13 +94,13 @@ func (r *Reconciler) createTenantInKeycloak(
 	log logr.Logger,
 	tenant *v1alpha2.Tenant,
 ) error {
-	if !r.KeycloakActor.IsInitialized() {
+	if !r.IdentityProvider.IsInitialized() {
 		log.Info("Keycloak actor not initialized, skipping Keycloak creation")
 		return nil
 	}
 
 	// Create the tenant in Keycloak
-	userID, err := r.KeycloakActor.CreateUser(
+	userID, err := r.IdentityProvider.CreateUser(
 		ctx,
 		tenant.Name,
 		tenant.Spec.Email,
@@ -126,7 +128,7 @@ func (r *Reconciler) deleteTenantInKeycloak(
 	log logr.Logger,
 	tenant *v1alpha2.Tenant,
 ) error {
-	if !r.KeycloakActor.IsInitialized() {
+	if !r.IdentityProvider.IsInitialized() {
 		log.Info("Keycloak actor not initialized, skipping Keycloak deletion")
 		return nil
 	}
@@ -137,7 +139,7 @@ func (r *Reconciler) deleteTenantInKeycloak(
 	}
 
 	// Delete the tenant in Keycloak
-	if err := r.KeycloakActor.DeleteUser(ctx, tenant.Status.Keycloak.UserCreated.Name); err != nil {
+	if err := r.IdentityProvider.DeleteUser(ctx, tenant.Status.Keycloak.UserCreated.Name); err != nil {
 		log.Error(err, "Error deleting tenant in Keycloak")
 		return fmt.Errorf("error deleting tenant %s in Keycloak: %w", tenant.Name, err)
 	}
@@ -155,38 +157,105 @@ func (r *Reconciler) deleteTenantInKeycloak(
 }
 
 // KeycloakEventHandler handles Keycloak webhook events for tenant resources.
+// If configured, the deliveries are authenticated, rate limited and checked for replays through the
+// KeycloakEventsGuard, while the KeycloakEventsQueue deduplicates them and reliably triggers the reconciliations.
 func (r *Reconciler) KeycloakEventHandler(
 	log logr.Logger,
 	hw http.ResponseWriter,
 	hr *http.Request,
 ) {
-	body, err := io.ReadAll(hr.Body)
+	reject := func(err error) {
+		var rejection *keycloakEventRejection
+		if !errors.As(err, &rejection) {
+			rejection = &keycloakEventRejection{status: http.StatusBadRequest, reason: rejectReasonMalformed, err: err}
+		}
+		log.Info("Rejected Keycloak event", "reason", rejection.Error())
+		keycloakEventsRejected.WithLabelValues(rejection.reason).Inc()
+		hw.WriteHeader(rejection.status)
+	}
+
+	if r.KeycloakEventsGuard != nil {
+		if err := r.KeycloakEventsGuard.CheckSource(hr); err != nil {
+			reject(err)
+			return
+		}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(hw, hr.Body, keycloakEventsMaxBodySize))
 	if err != nil {
-		hw.WriteHeader(http.StatusInternalServerError)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			reject(&keycloakEventRejection{status: http.StatusRequestEntityTooLarge, reason: rejectReasonTooLarge, err: err})
+			return
+		}
+		reject(&keycloakEventRejection{status: http.StatusInternalServerError, reason: rejectReasonInternal, err: err})
 		return
 	}
 	defer hr.Body.Close()
 
+	metadata, err := extractKeycloakEventMetadata(body)
+	if err != nil {
+		reject(err)
+		return
+	}
+
+	// Only the known event types are processed (and counted), to bound the cardinality of the metric labels.
+	if metadata.Type != keycloakEventCustomRequiredAction && metadata.Type != keycloakEventUserUpdate {
+		reject(rejectKeycloakEvent(http.StatusBadRequest, rejectReasonUnsupportedType, "unsupported event type %q", metadata.Type))
+		return
+	}
+
+	if r.KeycloakEventsGuard != nil {
+		if err := r.KeycloakEventsGuard.CheckAuthentication(hr, body); err != nil {
+			reject(err)
+			return
+		}
+		if err := r.KeycloakEventsGuard.CheckFreshness(metadata.Time); err != nil {
+			reject(err)
+			return
+		}
+	}
+
 	username, err := extractUsernameFromKeycloakEvent(body)
 	if err != nil {
-		hw.WriteHeader(http.StatusBadRequest)
+		reject(err)
 		return
 	}
 
 	if username == "" {
-		log.Info("Received Keycloak event with empty username")
-		hw.WriteHeader(http.StatusBadRequest)
+		reject(fmt.Errorf("received Keycloak event with empty username"))
 		return
 	}
 
-	r.TriggerReconcileChannel <- event.GenericEvent{
-		Object: &v1alpha2.Tenant{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: username,
+	// Discard the cached information about the user, which may have been changed by the event.
+	if invalidator, ok := r.IdentityProvider.(common.IdentityCacheInvalidator); ok {
+		invalidator.InvalidateUser(username)
+	}
+
+	if r.KeycloakEventsQueue != nil {
+		duplicate, err := r.KeycloakEventsQueue.Enqueue(metadata.ID, username)
+		if err != nil {
+			log.Error(err, "Error enqueueing Keycloak event")
+			reject(&keycloakEventRejection{status: http.StatusInternalServerError, reason: rejectReasonInternal, err: err})
+			return
+		}
+		if duplicate {
+			log.Info("Discarded duplicated Keycloak event", "id", metadata.ID)
+			keycloakEventsDuplicated.Inc()
+			hw.WriteHeader(http.StatusOK)
+			return
+		}
+	} else {
+		r.TriggerReconcileChannel <- event.GenericEvent{
+			Object: &v1alpha2.Tenant{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: username,
+				},
 			},
-		},
+		}
 	}
 
+	keycloakEventsAccepted.WithLabelValues(metadata.Type).Inc()
 	hw.WriteHeader(http.StatusOK)
 }
 
@@ -203,13 +272,13 @@ func extractUsernameFromKeycloakEvent(
 	}
 
 	switch baseEvent.Type {
-	case "access.CUSTOM_REQUIRED_ACTION":
+	case keycloakEventCustomRequiredAction:
 		username, err := extractUsernameFromCustomRequiredActionEvent(body)
 		if err != nil {
 			return "", fmt.Errorf("error extracting username from custom required action event: %w", err)
 		}
 		return username, nil
-	case "admin.USER-UPDATE":
+	case keycloakEventUserUpdate:
 		username, err := extractUsernameFromUserUpdateEvent(body)
 		if err != nil {
 			return "", fmt.Errorf("error extracting username from user update event: %w", err)
@@ -26,6 +26,8 @@ import (
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
@@ -362,6 +364,15 @@ var _ = Describe("Authentication Reconciler", func() {
 				namespace := &corev1.Namespace{}
 				DoesEventuallyExists(ctx, cl, client.ObjectKey{Name: "tenant-" + tnName}, namespace, BeFalse(), 10*time.Second, 250*time.Millisecond)
 			})
+
+			It("Should report the namespace as waiting for the user verification", func() {
+				tn := &v1alpha2.Tenant{}
+				DoesEventuallyExists(ctx, cl, client.ObjectKey{Name: tnName}, tn, BeTrue(), 10*time.Second, 250*time.Millisecond)
+				condition := meta.FindStatusCondition(tn.Status.Conditions, string(v1alpha2.ConditionNamespaceReady))
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				Expect(condition.Reason).To(BeEquivalentTo(v1alpha2.ReasonUserNotVerified))
+			})
 		})
 
 		Context("When the Tenant is created and confirmed in Keycloak", func() {
@@ -416,6 +427,18 @@ var _ = Describe("Authentication Reconciler", func() {
 				Expect(tn.Status.Ready).To(BeFalse())
 				Expect(tn.Status.Keycloak.UserSynchronized).To(BeFalse())
 			})
+
+			It("Should report Keycloak as unreachable in the Tenant conditions", func() {
+				tn := &v1alpha2.Tenant{}
+				DoesEventuallyExists(ctx, cl, client.ObjectKey{Name: tnName}, tn, BeTrue(), 10*time.Second, 250*time.Millisecond)
+				for _, conditionType := range []v1alpha2.ConditionType{v1alpha2.ConditionKeycloakSynchronized, v1alpha2.ConditionReady} {
+					condition := meta.FindStatusCondition(tn.Status.Conditions, string(conditionType))
+					Expect(condition).NotTo(BeNil())
+					Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+					Expect(condition.Reason).To(BeEquivalentTo(v1alpha2.ReasonKeycloakUnreachable))
+					Expect(condition.ObservedGeneration).To(Equal(tn.Generation))
+				}
+			})
 		})
 
 		Context("When the user-id in Keycloak does not match the one in Tenant status", func() {
@@ -20,10 +20,10 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/Nerzal/gocloak/v13"
 	"github.com/go-logr/logr"
 
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/common"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
 )
 
@@ -32,7 +32,7 @@ func (r *Reconciler) syncWorkspacesAuthorizationRoles(
 	log logr.Logger,
 	tn *v1alpha2.Tenant,
 ) error {
-	if !r.KeycloakActor.IsInitialized() {
+	if !r.IdentityProvider.IsInitialized() {
 		log.Info("Keycloak actor is not initialized, skipping workspace authorization roles update")
 		return nil
 	}
@@ -58,7 +58,7 @@ func (r *Reconciler) syncWorkspacesAuthorizationRoles(
 
 	if len(addRoles) > 0 {
 		// add the missing roles to Keycloak
-		if err := r.KeycloakActor.AddUserToRoles(ctx, tn.Status.Keycloak.UserCreated.Name, addRoles); err != nil {
+		if err := r.IdentityProvider.AddUserToRoles(ctx, tn.Status.Keycloak.UserCreated.Name, addRoles); err != nil {
 			log.Error(err, "Error adding roles to Keycloak for tenant", "tenant", tn.Name)
 			return err
 		}
@@ -69,7 +69,7 @@ func (r *Reconciler) syncWorkspacesAuthorizationRoles(
 
 	if len(deleteRoles) > 0 {
 		// remove the unwanted roles from Keycloak
-		if err := r.KeycloakActor.RemoveUserFromRoles(ctx, tn.Status.Keycloak.UserCreated.Name, deleteRoles); err != nil {
+		if err := r.IdentityProvider.RemoveUserFromRoles(ctx, tn.Status.Keycloak.UserCreated.Name, deleteRoles); err != nil {
 			log.Error(err, "Error removing roles from Keycloak for tenant", "tenant", tn.Name)
 			return err
 		}
@@ -94,17 +94,17 @@ func (r *Reconciler) obtainCurrentRoles(
 	ctx context.Context,
 	log logr.Logger,
 	tn *v1alpha2.Tenant,
-) ([]*gocloak.Role, error) {
-	currentRoles, err := r.KeycloakActor.GetUserRoles(ctx, tn.Status.Keycloak.UserCreated.Name)
+) ([]*common.Role, error) {
+	currentRoles, err := r.IdentityProvider.GetUserRoles(ctx, tn.Status.Keycloak.UserCreated.Name)
 	if err != nil {
 		log.Error(err, "Error getting roles from Keycloak")
 		return nil, err
 	}
 
 	// filter roles to only those related to workspaces
-	filteredRoles := make([]*gocloak.Role, 0)
+	filteredRoles := make([]*common.Role, 0)
 	for _, role := range currentRoles {
-		if strings.HasPrefix(*role.Name, "workspace-") {
+		if strings.HasPrefix(role.Name, "workspace-") {
 			filteredRoles = append(filteredRoles, role)
 		}
 	}
@@ -115,13 +115,13 @@ func (r *Reconciler) obtainCurrentRoles(
 func (r *Reconciler) getRolesToAdd(
 	ctx context.Context,
 	wantedRoles []string,
-	currentRoles []*gocloak.Role,
-) ([]*gocloak.Role, error) {
+	currentRoles []*common.Role,
+) ([]*common.Role, error) {
 	rolesToAdd := make([]string, 0)
 
 	for _, wantedRole := range wantedRoles {
-		if !slices.ContainsFunc(currentRoles, func(role *gocloak.Role) bool {
-			return *role.Name == wantedRole
+		if !slices.ContainsFunc(currentRoles, func(role *common.Role) bool {
+			return role.Name == wantedRole
 		}) {
 			rolesToAdd = append(rolesToAdd, wantedRole)
 		}
@@ -133,26 +133,26 @@ func (r *Reconciler) getRolesToAdd(
 func (r *Reconciler) convertRoleNamesToRoles(
 	ctx context.Context,
 	roles []string,
-) ([]*gocloak.Role, error) {
-	gocloakRoles := make([]*gocloak.Role, len(roles))
+) ([]*common.Role, error) {
+	identityRoles := make([]*common.Role, len(roles))
 	for i, roleName := range roles {
-		role, err := r.KeycloakActor.GetRole(ctx, roleName)
+		role, err := r.IdentityProvider.GetRole(ctx, roleName)
 		if err != nil {
 			return nil, err
 		}
-		gocloakRoles[i] = role
+		identityRoles[i] = role
 	}
-	return gocloakRoles, nil
+	return identityRoles, nil
 }
 
 func (r *Reconciler) getRolesToDelete(
 	wantedRoles []string,
-	currentRoles []*gocloak.Role,
-) []*gocloak.Role {
-	rolesToDelete := make([]*gocloak.Role, 0)
+	currentRoles []*common.Role,
+) []*common.Role {
+	rolesToDelete := make([]*common.Role, 0)
 
 	for _, currentRole := range currentRoles {
-		if !slices.Contains(wantedRoles, *currentRole.Name) {
+		if !slices.Contains(wantedRoles, currentRole.Name) {
 			// if the current role is not in the wanted roles, we add it to the list to delete
 			rolesToDelete = append(rolesToDelete, currentRole)
 		}
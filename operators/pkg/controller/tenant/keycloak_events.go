@@ -0,0 +1,223 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// KeycloakEventSignatureHeader -> the header carrying the hex-encoded HMAC-SHA256 signature of the body of the Keycloak events.
+	KeycloakEventSignatureHeader = "X-Keycloak-Signature"
+
+	// keycloakEventsLimiterIdleTime -> the time after which the rate limiter of an idle source is discarded.
+	keycloakEventsLimiterIdleTime = 10 * time.Minute
+	// keycloakEventsMaxBodySize -> the maximum size of the body of the Keycloak events.
+	keycloakEventsMaxBodySize = 1 << 20
+)
+
+// Types of the Keycloak events processed by the operator, also used as metric labels.
+const (
+	keycloakEventCustomRequiredAction = "access.CUSTOM_REQUIRED_ACTION"
+	keycloakEventUserUpdate           = "admin.USER-UPDATE"
+)
+
+// Reasons for which the Keycloak events are rejected, used as metric labels.
+const (
+	rejectReasonRateLimited        = "rate_limited"
+	rejectReasonMissingCredentials = "missing_credentials"
+	rejectReasonInvalidSignature   = "invalid_signature"
+	rejectReasonStale              = "stale"
+	rejectReasonMalformed          = "malformed"
+	rejectReasonTooLarge           = "too_large"
+	rejectReasonUnsupportedType    = "unsupported_type"
+	rejectReasonInternal           = "internal_error"
+)
+
+// KeycloakEventsGuard protects the endpoint receiving the Keycloak events, authenticating
+// the deliveries and rejecting the stale and the excessive ones.
+type KeycloakEventsGuard struct {
+	// Secret is the secret shared with Keycloak, used either to verify the HMAC signature of the
+	// events, or compared with the bearer token of the request. If empty, the events are not authenticated.
+	Secret []byte
+	// MaxEventAge is the maximum difference between the time an event has been generated and the time
+	// it is received, to prevent the replay of old events. Zero disables the check.
+	MaxEventAge time.Duration
+	// RateLimit is the maximum number of events per second accepted from each source, with bursts
+	// up to RateBurst events. Zero disables rate limiting.
+	RateLimit rate.Limit
+	RateBurst int
+
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+
+	mutex    sync.Mutex
+	limiters map[string]*keycloakEventsSource
+}
+
+type keycloakEventsSource struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// keycloakEventRejection describes the reason why a Keycloak event has been rejected.
+type keycloakEventRejection struct {
+	status int
+	reason string
+	err    error
+}
+
+func (r *keycloakEventRejection) Error() string {
+	return fmt.Sprintf("%s: %v", r.reason, r.err)
+}
+
+func rejectKeycloakEvent(status int, reason string, format string, args ...any) error {
+	return &keycloakEventRejection{status: status, reason: reason, err: fmt.Errorf(format, args...)}
+}
+
+// keycloakEventMetadata contains the fields, common to all Keycloak events, used to detect replays.
+type keycloakEventMetadata struct {
+	Type string
+	ID   string
+	Time time.Time
+}
+
+func (g *KeycloakEventsGuard) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
+
+// CheckSource enforces the rate limit associated with the source of the request.
+func (g *KeycloakEventsGuard) CheckSource(hr *http.Request) error {
+	if g.RateLimit <= 0 {
+		return nil
+	}
+
+	source, _, err := net.SplitHostPort(hr.RemoteAddr)
+	if err != nil {
+		source = hr.RemoteAddr
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := g.now()
+	if g.limiters == nil {
+		g.limiters = make(map[string]*keycloakEventsSource)
+	}
+
+	// Garbage collect the limiters associated with the sources which have been idle for a while.
+	for key, src := range g.limiters {
+		if now.Sub(src.lastSeen) > keycloakEventsLimiterIdleTime {
+			delete(g.limiters, key)
+		}
+	}
+
+	src, found := g.limiters[source]
+	if !found {
+		src = &keycloakEventsSource{limiter: rate.NewLimiter(g.RateLimit, max(g.RateBurst, 1))}
+		g.limiters[source] = src
+	}
+	src.lastSeen = now
+
+	if !src.limiter.AllowN(now, 1) {
+		return rejectKeycloakEvent(http.StatusTooManyRequests, rejectReasonRateLimited, "too many events from %s", source)
+	}
+	return nil
+}
+
+// CheckAuthentication verifies that the event has been sent by Keycloak, either checking the HMAC
+// signature of the body, or comparing the bearer token with the shared secret.
+func (g *KeycloakEventsGuard) CheckAuthentication(hr *http.Request, body []byte) error {
+	if len(g.Secret) == 0 {
+		return nil
+	}
+
+	if signature := hr.Header.Get(KeycloakEventSignatureHeader); signature != "" {
+		provided, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+		if err != nil {
+			return rejectKeycloakEvent(http.StatusUnauthorized, rejectReasonInvalidSignature, "malformed signature")
+		}
+
+		mac := hmac.New(sha256.New, g.Secret)
+		mac.Write(body)
+		if !hmac.Equal(provided, mac.Sum(nil)) {
+			return rejectKeycloakEvent(http.StatusUnauthorized, rejectReasonInvalidSignature, "signature mismatch")
+		}
+		return nil
+	}
+
+	if token, found := strings.CutPrefix(hr.Header.Get("Authorization"), "Bearer "); found {
+		if subtle.ConstantTimeCompare([]byte(token), g.Secret) != 1 {
+			return rejectKeycloakEvent(http.StatusUnauthorized, rejectReasonInvalidSignature, "shared secret mismatch")
+		}
+		return nil
+	}
+
+	return rejectKeycloakEvent(http.StatusUnauthorized, rejectReasonMissingCredentials, "neither signature nor shared secret provided")
+}
+
+// CheckFreshness rejects the events generated too far in the past (or in the future).
+func (g *KeycloakEventsGuard) CheckFreshness(eventTime time.Time) error {
+	if g.MaxEventAge <= 0 {
+		return nil
+	}
+
+	if eventTime.IsZero() {
+		return rejectKeycloakEvent(http.StatusBadRequest, rejectReasonStale, "event timestamp not provided")
+	}
+
+	if age := g.now().Sub(eventTime).Abs(); age > g.MaxEventAge {
+		return rejectKeycloakEvent(http.StatusBadRequest, rejectReasonStale, "event generated %s away from now", age.Round(time.Second))
+	}
+	return nil
+}
+
+// extractKeycloakEventMetadata extracts the identifier and the generation time of a Keycloak event.
+func extractKeycloakEventMetadata(body []byte) (keycloakEventMetadata, error) {
+	var baseEvent struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+		UID  string `json:"uid"`
+		Time int64  `json:"time"` // milliseconds since epoch
+	}
+
+	if err := json.Unmarshal(body, &baseEvent); err != nil {
+		return keycloakEventMetadata{}, err
+	}
+
+	metadata := keycloakEventMetadata{Type: baseEvent.Type, ID: baseEvent.UID}
+	if metadata.ID == "" {
+		metadata.ID = baseEvent.ID
+	}
+	if baseEvent.Time > 0 {
+		metadata.Time = time.UnixMilli(baseEvent.Time)
+	}
+	return metadata, nil
+}
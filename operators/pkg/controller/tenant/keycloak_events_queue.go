@@ -0,0 +1,302 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+const (
+	// keycloakEventsDeliveryTimeout -> the maximum time waited to trigger a reconciliation, before retrying later.
+	keycloakEventsDeliveryTimeout = 5 * time.Second
+	// keycloakEventsMaxBackoff -> the maximum delay between two attempts to trigger the reconciliation of the same tenant.
+	keycloakEventsMaxBackoff = 5 * time.Minute
+	// keycloakEventsGCInterval -> the interval between two cleanups of the expired event identifiers.
+	keycloakEventsGCInterval = time.Minute
+)
+
+// KeycloakEventsQueue is a small queue decoupling the reception of the Keycloak events from the
+// reconciliation of the corresponding tenants. Events carrying an already seen identifier are discarded,
+// and multiple events concerning the same tenant are coalesced into a single reconciliation, which
+// is retried until it can be triggered. If a path is configured, the queue is persisted to survive restarts.
+type KeycloakEventsQueue struct {
+	// Path is the file the queue is persisted to. If empty, the queue is kept in memory only.
+	Path string
+	// Retention is the time the identifiers of the received events are retained for deduplication.
+	// It should be greater than the maximum age of the accepted events.
+	Retention time.Duration
+
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+
+	mutex  sync.Mutex
+	state  keycloakEventsQueueState
+	wakeup chan struct{}
+}
+
+type keycloakEventsQueueState struct {
+	Pending []keycloakEventsQueueItem `json:"pending"`
+	Seen    map[string]time.Time      `json:"seen"` // expiration time, indexed by event identifier
+}
+
+type keycloakEventsQueueItem struct {
+	Username  string    `json:"username"`
+	Attempts  int       `json:"attempts,omitempty"`
+	NotBefore time.Time `json:"notBefore,omitempty"`
+}
+
+// NewKeycloakEventsQueue creates a new KeycloakEventsQueue, restoring the persisted state, if any.
+func NewKeycloakEventsQueue(path string, retention time.Duration) (*KeycloakEventsQueue, error) {
+	q := &KeycloakEventsQueue{
+		Path:      path,
+		Retention: retention,
+		state:     keycloakEventsQueueState{Seen: make(map[string]time.Time)},
+		wakeup:    make(chan struct{}, 1),
+	}
+
+	if path == "" {
+		return q, nil
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return q, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &q.state); err != nil {
+		return nil, err
+	}
+	if q.state.Seen == nil {
+		q.state.Seen = make(map[string]time.Time)
+	}
+	keycloakEventsPending.Set(float64(len(q.state.Pending)))
+	return q, nil
+}
+
+func (q *KeycloakEventsQueue) now() time.Time {
+	if q.Now != nil {
+		return q.Now()
+	}
+	return time.Now()
+}
+
+// Enqueue records the reception of an event concerning the given tenant, and returns whether it is
+// a duplicate of an event already received. Events without identifier are never considered duplicates.
+func (q *KeycloakEventsQueue) Enqueue(eventID, username string) (duplicate bool, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := q.now()
+	if expiration, found := q.state.Seen[eventID]; eventID != "" && found && now.Before(expiration) {
+		return true, nil
+	}
+
+	previousExpiration, seen := q.state.Seen[eventID]
+	if eventID != "" {
+		q.state.Seen[eventID] = now.Add(q.Retention)
+	}
+
+	// Coalesce the events concerning a tenant whose reconciliation has not yet been triggered.
+	idx := slices.IndexFunc(q.state.Pending, func(item keycloakEventsQueueItem) bool { return item.Username == username })
+	var previousItem keycloakEventsQueueItem
+	if idx >= 0 {
+		previousItem = q.state.Pending[idx]
+		q.state.Pending[idx].NotBefore = time.Time{}
+	} else {
+		q.state.Pending = append(q.state.Pending, keycloakEventsQueueItem{Username: username})
+	}
+
+	if err := q.persist(); err != nil {
+		// Restore the previous state, so that the delivery is not considered a duplicate when retried.
+		switch {
+		case eventID != "" && seen:
+			q.state.Seen[eventID] = previousExpiration
+		case eventID != "":
+			delete(q.state.Seen, eventID)
+		}
+		if idx >= 0 {
+			q.state.Pending[idx] = previousItem
+		} else {
+			q.state.Pending = q.state.Pending[:len(q.state.Pending)-1]
+		}
+		return false, err
+	}
+
+	keycloakEventsPending.Set(float64(len(q.state.Pending)))
+	q.notify()
+	return false, nil
+}
+
+// Len returns the number of tenants whose reconciliation has not yet been triggered.
+func (q *KeycloakEventsQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.state.Pending)
+}
+
+// Run triggers the reconciliation of the queued tenants, through the given channel, until the context is canceled.
+func (q *KeycloakEventsQueue) Run(ctx context.Context, log logr.Logger, trigger chan<- event.GenericEvent) error {
+	gcTicker := time.NewTicker(keycloakEventsGCInterval)
+	defer gcTicker.Stop()
+
+	for {
+		username, wait := q.next()
+		if username == "" {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-q.wakeup:
+			case <-timer.C:
+			case <-gcTicker.C:
+				q.collectGarbage(log)
+			}
+			timer.Stop()
+			continue
+		}
+
+		timer := time.NewTimer(keycloakEventsDeliveryTimeout)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case trigger <- event.GenericEvent{Object: &v1alpha2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: username}}}:
+			q.complete(log, username)
+		case <-timer.C:
+			log.Info("Unable to trigger the reconciliation of the tenant, retrying later", "tenant", username)
+			q.retry(log, username)
+		}
+		timer.Stop()
+	}
+}
+
+// next returns the first tenant whose reconciliation can be triggered, or the time to wait otherwise.
+func (q *KeycloakEventsQueue) next() (username string, wait time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := q.now()
+	wait = keycloakEventsGCInterval
+	for _, item := range q.state.Pending {
+		if !now.Before(item.NotBefore) {
+			return item.Username, 0
+		}
+		wait = min(wait, item.NotBefore.Sub(now))
+	}
+	return "", wait
+}
+
+// complete removes the given tenant from the queue, once its reconciliation has been triggered.
+func (q *KeycloakEventsQueue) complete(log logr.Logger, username string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.state.Pending = slices.DeleteFunc(q.state.Pending, func(item keycloakEventsQueueItem) bool { return item.Username == username })
+	keycloakEventsPending.Set(float64(len(q.state.Pending)))
+	if err := q.persist(); err != nil {
+		log.Error(err, "Error persisting the Keycloak events queue")
+	}
+}
+
+// retry postpones the reconciliation of the given tenant, with an exponential backoff.
+func (q *KeycloakEventsQueue) retry(log logr.Logger, username string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	keycloakEventsRetries.Inc()
+	for i := range q.state.Pending {
+		if q.state.Pending[i].Username == username {
+			q.state.Pending[i].Attempts++
+			backoff := min(time.Second<<min(q.state.Pending[i].Attempts, 16), keycloakEventsMaxBackoff)
+			q.state.Pending[i].NotBefore = q.now().Add(backoff)
+		}
+	}
+	if err := q.persist(); err != nil {
+		log.Error(err, "Error persisting the Keycloak events queue")
+	}
+}
+
+// collectGarbage removes the expired event identifiers.
+func (q *KeycloakEventsQueue) collectGarbage(log logr.Logger) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := q.now()
+	removed := false
+	for id, expiration := range q.state.Seen {
+		if !now.Before(expiration) {
+			delete(q.state.Seen, id)
+			removed = true
+		}
+	}
+
+	if removed {
+		if err := q.persist(); err != nil {
+			log.Error(err, "Error persisting the Keycloak events queue")
+		}
+	}
+}
+
+// notify wakes up the Run loop, if waiting.
+func (q *KeycloakEventsQueue) notify() {
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// persist atomically writes the state of the queue to the configured path. It must be called with the mutex held.
+func (q *KeycloakEventsQueue) persist() error {
+	if q.Path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(&q.state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(q.Path), filepath.Base(q.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), q.Path)
+}
@@ -0,0 +1,283 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/common"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/mock"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/tenant"
+)
+
+var _ = Describe("Keycloak events", func() {
+	const secret = "shared-secret"
+
+	var (
+		now   time.Time
+		guard *tenant.KeycloakEventsGuard
+		queue *tenant.KeycloakEventsQueue
+	)
+
+	forgeEvent := func(id string, at time.Time) string {
+		return fmt.Sprintf(`{"uid": %q, "time": %d, "type": "access.CUSTOM_REQUIRED_ACTION", "authDetails": {"username": %q}}`,
+			id, at.UnixMilli(), tnName)
+	}
+
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	deliver := func(body string, headers map[string]string) int {
+		req := httptest.NewRequest(http.MethodPost, "/tenant-webhook", strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:12345"
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		tenantReconciler.KeycloakEventHandler(log, w, req)
+		return w.Code
+	}
+
+	BeforeEach(func() {
+		runReconcile = false
+		now = time.Now()
+
+		guard = &tenant.KeycloakEventsGuard{
+			Secret:      []byte(secret),
+			MaxEventAge: 5 * time.Minute,
+			RateLimit:   1,
+			RateBurst:   3,
+			Now:         func() time.Time { return now },
+		}
+
+		var err error
+		queue, err = tenant.NewKeycloakEventsQueue("", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		runReconcile = true
+	})
+
+	JustBeforeEach(func() {
+		tenantReconciler.KeycloakEventsGuard = guard
+		tenantReconciler.KeycloakEventsQueue = queue
+	})
+
+	Describe("Authentication", func() {
+		It("should accept the events with a valid signature", func() {
+			body := forgeEvent("evt-1", now)
+			Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)})).To(Equal(http.StatusOK))
+			Expect(queue.Len()).To(Equal(1))
+		})
+
+		It("should accept the events with the shared secret as bearer token", func() {
+			body := forgeEvent("evt-1", now)
+			Expect(deliver(body, map[string]string{"Authorization": "Bearer " + secret})).To(Equal(http.StatusOK))
+		})
+
+		It("should reject the events with an invalid signature", func() {
+			body := forgeEvent("evt-1", now)
+			Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body + " ")})).To(Equal(http.StatusUnauthorized))
+			Expect(queue.Len()).To(BeZero())
+		})
+
+		It("should reject the events with a wrong shared secret", func() {
+			body := forgeEvent("evt-1", now)
+			Expect(deliver(body, map[string]string{"Authorization": "Bearer wrong"})).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should reject the events without credentials", func() {
+			Expect(deliver(forgeEvent("evt-1", now), nil)).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Describe("Cache invalidation", func() {
+		It("should invalidate the cached information about the user", func() {
+			actor := &invalidatingKeycloakActor{MockKeycloakActorIface: keycloakActor}
+			tenantReconciler.IdentityProvider = common.NewKeycloakIdentityProvider(actor)
+
+			body := forgeEvent("evt-1", now)
+			Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)})).To(Equal(http.StatusOK))
+			Expect(actor.invalidated).To(ConsistOf(tnName))
+		})
+	})
+
+	Describe("Replay protection", func() {
+		It("should reject the stale events", func() {
+			body := forgeEvent("evt-1", now.Add(-10*time.Minute))
+			Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)})).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should reject the events without timestamp", func() {
+			body := `{"uid": "evt-1", "type": "access.CUSTOM_REQUIRED_ACTION", "authDetails": {"username": "testuser"}}`
+			Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)})).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should acknowledge, but discard, the duplicated events", func() {
+			body := forgeEvent("evt-1", now)
+			headers := map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)}
+			Expect(deliver(body, headers)).To(Equal(http.StatusOK))
+			Expect(deliver(body, headers)).To(Equal(http.StatusOK))
+			Expect(queue.Len()).To(Equal(1))
+		})
+	})
+
+	Describe("Validation", func() {
+		It("should reject the events exceeding the maximum size", func() {
+			body := fmt.Sprintf(`{"uid": "evt-1", "type": "admin.USER-UPDATE", "representation": %q}`, strings.Repeat("x", 2<<20))
+			Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)})).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(queue.Len()).To(BeZero())
+		})
+
+		It("should reject the events of unsupported types", func() {
+			body := fmt.Sprintf(`{"uid": "evt-1", "time": %d, "type": "access.LOGIN", "authDetails": {"username": %q}}`, now.UnixMilli(), tnName)
+			Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)})).To(Equal(http.StatusBadRequest))
+			Expect(queue.Len()).To(BeZero())
+		})
+	})
+
+	Describe("Rate limiting", func() {
+		It("should reject the events exceeding the rate limit of the source", func() {
+			for i := range 3 {
+				body := forgeEvent(fmt.Sprintf("evt-%d", i), now)
+				Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)})).To(Equal(http.StatusOK))
+			}
+
+			body := forgeEvent("evt-3", now)
+			Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)})).To(Equal(http.StatusTooManyRequests))
+
+			now = now.Add(time.Second)
+			Expect(deliver(body, map[string]string{tenant.KeycloakEventSignatureHeader: sign(body)})).To(Equal(http.StatusOK))
+		})
+	})
+})
+
+var _ = Describe("KeycloakEventsQueue", func() {
+	It("should coalesce the events concerning the same tenant", func() {
+		queue, err := tenant.NewKeycloakEventsQueue("", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, id := range []string{"evt-1", "evt-2", ""} {
+			duplicate, err := queue.Enqueue(id, "john")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(duplicate).To(BeFalse())
+		}
+		_, err = queue.Enqueue("evt-3", "jane")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queue.Len()).To(Equal(2))
+	})
+
+	It("should consider duplicates only the events seen within the retention period", func() {
+		now := time.Now()
+		queue, err := tenant.NewKeycloakEventsQueue("", time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		queue.Now = func() time.Time { return now }
+
+		Expect(queue.Enqueue("evt-1", "john")).To(BeFalse())
+		Expect(queue.Enqueue("evt-1", "john")).To(BeTrue())
+
+		now = now.Add(2 * time.Minute)
+		Expect(queue.Enqueue("evt-1", "john")).To(BeFalse())
+	})
+
+	It("should persist the pending events and the seen identifiers", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "queue.json")
+
+		queue, err := tenant.NewKeycloakEventsQueue(path, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queue.Enqueue("evt-1", "john")).To(BeFalse())
+		Expect(path).To(BeAnExistingFile())
+
+		restored, err := tenant.NewKeycloakEventsQueue(path, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restored.Len()).To(Equal(1))
+		Expect(restored.Enqueue("evt-1", "john")).To(BeTrue())
+	})
+
+	It("should not record the events which cannot be persisted", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "missing", "queue.json")
+
+		queue, err := tenant.NewKeycloakEventsQueue(path, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = queue.Enqueue("evt-1", "john")
+		Expect(err).To(HaveOccurred())
+		Expect(queue.Len()).To(BeZero())
+
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(queue.Enqueue("evt-1", "john")).To(BeFalse())
+	})
+
+	It("should fail restoring a corrupted queue", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "queue.json")
+		Expect(os.WriteFile(path, []byte("corrupted"), 0o600)).To(Succeed())
+
+		_, err := tenant.NewKeycloakEventsQueue(path, time.Hour)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should trigger the reconciliation of the queued tenants", func() {
+		queue, err := tenant.NewKeycloakEventsQueue("", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queue.Enqueue("evt-1", "john")).To(BeFalse())
+		Expect(queue.Enqueue("evt-2", "jane")).To(BeFalse())
+
+		runCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+
+		trigger := make(chan event.GenericEvent)
+		go func() {
+			defer GinkgoRecover()
+			Expect(queue.Run(runCtx, log, trigger)).To(Succeed())
+		}()
+
+		names := []string{}
+		for range 2 {
+			var evt event.GenericEvent
+			Eventually(trigger, timeout, interval).Should(Receive(&evt))
+			names = append(names, evt.Object.(*v1alpha2.Tenant).Name)
+		}
+		Expect(names).To(ConsistOf("john", "jane"))
+		Eventually(queue.Len, timeout, interval).Should(BeZero())
+	})
+})
+
+// invalidatingKeycloakActor wraps the Keycloak actor mock, recording the invalidated users.
+type invalidatingKeycloakActor struct {
+	*mock.MockKeycloakActorIface
+	invalidated []string
+}
+
+func (a *invalidatingKeycloakActor) InvalidateUser(username string) {
+	a.invalidated = append(a.invalidated, username)
+}
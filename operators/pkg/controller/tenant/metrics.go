@@ -26,9 +26,41 @@ var (
 	},
 		[]string{"controller", "reason"},
 	)
+
+	keycloakEventsAccepted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tenant_operator_keycloak_events_accepted",
+		Help: "The number of Keycloak events accepted by the tenant operator",
+	},
+		[]string{"type"},
+	)
+	keycloakEventsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tenant_operator_keycloak_events_rejected",
+		Help: "The number of Keycloak events rejected by the tenant operator",
+	},
+		[]string{"reason"},
+	)
+	keycloakEventsDuplicated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tenant_operator_keycloak_events_duplicated",
+		Help: "The number of Keycloak events discarded since already received",
+	})
+	keycloakEventsPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "tenant_operator_keycloak_events_pending",
+		Help: "The number of tenants whose reconciliation, requested by Keycloak events, has not yet been triggered",
+	})
+	keycloakEventsRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tenant_operator_keycloak_events_retries",
+		Help: "The number of times the reconciliation requested by Keycloak events had to be retried",
+	})
 )
 
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(tnOpinternalErrors)
+	metrics.Registry.MustRegister(
+		tnOpinternalErrors,
+		keycloakEventsAccepted,
+		keycloakEventsRejected,
+		keycloakEventsDuplicated,
+		keycloakEventsPending,
+		keycloakEventsRetries,
+	)
 }
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -27,6 +28,7 @@ import (
 	netv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -55,12 +57,14 @@ type Reconciler struct {
 	MyDrivePVCsSize             resource.Quantity
 	MyDrivePVCsStorageClassName string
 	MyDrivePVCsNamespace        string
-	KeycloakActor               common.KeycloakActorIface
+	IdentityProvider            common.IdentityProviderIface
 	WaitUserVerification        bool // If true, the reconciliation will wait for the user to be verified in Keycloak before creating resources.
 	SandboxClusterRole          string
 	BaseWorkspaces              []string
 	Concurrency                 int
 	Reschedule                  common.Rescheduler
+	KeycloakEventsGuard         *KeycloakEventsGuard // If not nil, protects the endpoint receiving the Keycloak events.
+	KeycloakEventsQueue         *KeycloakEventsQueue // If not nil, deduplicates the Keycloak events and triggers the reconciliations.
 }
 
 // Reconcile reconciles the state of a tenant resource.
@@ -129,24 +133,35 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	// enforce generic labels
 	if err := r.enforceTenantBaseLabels(ctx, log, &tn); err != nil {
+		setTenantReadyCondition(&tn, v1alpha2.ReasonEnforcementFailed, err)
 		return reschedule, fmt.Errorf("error enforcing tenant base labels: %w", err)
 	}
 
 	// manage workspaces subscription (and related labels)
 	if err := r.syncWorkspaces(ctx, log, &tn); err != nil {
 		log.Error(err, "Error enforcing workspaces for tenant", "tenant", tn.Name)
+		setTenantReadyCondition(&tn, v1alpha2.ReasonEnforcementFailed, err)
 		return reschedule, fmt.Errorf("error enforcing workspaces for tenant %s: %w", tn.Name, err)
 	}
+	if len(tn.Status.FailingWorkspaces) > 0 {
+		utils.SetCondition(&tn.Status.Conditions, &tn, v1alpha2.ConditionWorkspacesSubscribed, metav1.ConditionFalse,
+			v1alpha2.ReasonWorkspaceSubscriptionFailed, fmt.Sprintf("Failed subscribing to workspaces: %s", strings.Join(tn.Status.FailingWorkspaces, ", ")))
+	} else {
+		utils.SetCondition(&tn.Status.Conditions, &tn, v1alpha2.ConditionWorkspacesSubscribed, metav1.ConditionTrue,
+			v1alpha2.ReasonSucceeded, "Subscribed to all the requested workspaces")
+	}
 
 	// check if the tenant is already been provisioned in Keycloak
 	// - if not, create the tenant in Keycloak
 	// - if yes, check if the tenant is verified
+	var keycloakErr error
 	verified, err := r.CheckKeycloakUserVerified(ctx, log, &tn)
 	if err != nil {
 		log.Error(err, "Error checking Keycloak status for tenant", "tenant", tn.Name)
 		tn.Status.Keycloak.UserSynchronized = false
 		tn.Status.Ready = false
 		hasErrors = true
+		keycloakErr = err
 	} else {
 		tn.Status.Keycloak.UserSynchronized = true
 	}
@@ -157,14 +172,26 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		tn.Status.Keycloak.UserSynchronized = false
 		tn.Status.Ready = false
 		hasErrors = true
+		keycloakErr = err
 	}
 	log.Info("Updated tenant authorization roles for tenant", "tenant", tn.Name)
 
+	if keycloakErr != nil {
+		utils.SetCondition(&tn.Status.Conditions, &tn, v1alpha2.ConditionKeycloakSynchronized, metav1.ConditionFalse,
+			v1alpha2.ReasonKeycloakUnreachable, keycloakErr.Error())
+	} else {
+		utils.SetCondition(&tn.Status.Conditions, &tn, v1alpha2.ConditionKeycloakSynchronized, metav1.ConditionTrue,
+			v1alpha2.ReasonSucceeded, "Tenant synchronized with Keycloak")
+	}
+
 	if r.WaitUserVerification && !verified {
 		// if the Tenant has not been verified, we can skip the reconciliation
 		// and wait for the next reconcile loop
 		log.Info("Tenant not verified, skipping resource creation")
 		tn.Status.Ready = !hasErrors
+		utils.SetCondition(&tn.Status.Conditions, &tn, v1alpha2.ConditionNamespaceReady, metav1.ConditionFalse,
+			v1alpha2.ReasonUserNotVerified, "Waiting for the tenant to verify the email address")
+		setTenantReadyCondition(&tn, v1alpha2.ReasonKeycloakUnreachable, keycloakErr)
 		return reschedule, nil
 	}
 
@@ -174,6 +201,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err := r.enforceTenantClusterResources(ctx, log, &tn); err != nil {
 		log.Error(err, "Error creating tenant cluster resources for tenant", "tenant", tn.Name)
 		tnOpinternalErrors.WithLabelValues("tenant", "cluster-resources").Inc()
+		setTenantReadyCondition(&tn, v1alpha2.ReasonEnforcementFailed, err)
 		return reschedule, err
 	}
 
@@ -181,6 +209,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err := r.enforceServiceQuota(ctx, log, &tn); err != nil {
 		log.Error(err, "Error forging service quota for tenant", "tenant", tn.Name)
 		tnOpinternalErrors.WithLabelValues("tenant", "quota-forge").Inc()
+		setTenantReadyCondition(&tn, v1alpha2.ReasonEnforcementFailed, err)
 		return reschedule, fmt.Errorf("error forging service quota for tenant %s: %w", tn.Name, err)
 	}
 
@@ -191,6 +220,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err != nil {
 		log.Error(err, "Error checking whether tenant namespace should be kept alive")
 		tnOpinternalErrors.WithLabelValues("tenant", "check-keep-alive").Inc()
+		setTenantReadyCondition(&tn, v1alpha2.ReasonEnforcementFailed, err)
 		return reschedule, err
 	}
 
@@ -200,15 +230,23 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		if err := r.enforceResourcesRelatedToPersonalNamespace(ctx, log, &tn); err != nil {
 			log.Error(err, "Error creating or updating resources related to personal namespace for tenant %s: %v", tn.Name, err)
 			tnOpinternalErrors.WithLabelValues("tenant", "create-personal-namespace").Inc()
+			utils.SetCondition(&tn.Status.Conditions, &tn, v1alpha2.ConditionNamespaceReady, metav1.ConditionFalse,
+				v1alpha2.ReasonEnforcementFailed, err.Error())
+			setTenantReadyCondition(&tn, v1alpha2.ReasonEnforcementFailed, err)
 			return reschedule, fmt.Errorf("error creating or updating resources related to personal namespace for tenant %s: %w", tn.Name, err)
 		}
+		utils.SetCondition(&tn.Status.Conditions, &tn, v1alpha2.ConditionNamespaceReady, metav1.ConditionTrue,
+			v1alpha2.ReasonSucceeded, fmt.Sprintf("Personal namespace %s enforced", tn.Status.PersonalNamespace.Name))
 	} else {
 		// Namespace should not be kept open, so we delete all the resources related to the tenant
 		if err := r.enforceResourcesRelatedToPersonalNamespaceAbsence(ctx, log, &tn); err != nil {
 			log.Error(err, "Error deleting resources related to personal namespace for tenant", "tenant", tn.Name)
 			tnOpinternalErrors.WithLabelValues("tenant", "delete-personal-namespace").Inc()
+			setTenantReadyCondition(&tn, v1alpha2.ReasonEnforcementFailed, err)
 			return reschedule, fmt.Errorf("error deleting resources related to personal namespace for tenant %s: %w", tn.Name, err)
 		}
+		utils.SetCondition(&tn.Status.Conditions, &tn, v1alpha2.ConditionNamespaceReady, metav1.ConditionFalse,
+			v1alpha2.ReasonNamespaceInactive, "Personal namespace deleted due to inactivity")
 	}
 
 	// esporta/disesporta tutto
@@ -216,6 +254,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		log.Error(err, "Failed checking sandbox for tenant", "tenant", tn.Name)
 		tn.Status.SandboxNamespace.Created = false
 		tnOpinternalErrors.WithLabelValues("tenant", "sandbox-resources").Inc()
+		setTenantReadyCondition(&tn, v1alpha2.ReasonEnforcementFailed, err)
 		return reschedule, err
 	}
 
@@ -224,14 +263,27 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// (otherwise the user will not be able to access the PVC)
 	if err := r.enforceMyDrivePVC(ctx, log, &tn); err != nil {
 		log.Error(err, "Error creating MyDrive PVC for tenant", "tenant", tn.Name)
+		setTenantReadyCondition(&tn, v1alpha2.ReasonEnforcementFailed, err)
 		return reschedule, err
 	}
 
 	tn.Status.Ready = !hasErrors
+	setTenantReadyCondition(&tn, v1alpha2.ReasonKeycloakUnreachable, keycloakErr)
 
 	return reschedule, nil
 }
 
+// setTenantReadyCondition configures the Ready condition of the Tenant, reporting the given reason
+// in case an error prevented it from being ready.
+func setTenantReadyCondition(tn *v1alpha2.Tenant, reason v1alpha2.ConditionReason, err error) {
+	if err != nil {
+		utils.SetCondition(&tn.Status.Conditions, tn, v1alpha2.ConditionReady, metav1.ConditionFalse, reason, err.Error())
+		return
+	}
+	utils.SetCondition(&tn.Status.Conditions, tn, v1alpha2.ConditionReady, metav1.ConditionTrue,
+		v1alpha2.ReasonAvailable, "Tenant correctly configured")
+}
+
 // SetupWithManager registers a new controller for Tenant resources.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, log logr.Logger) error {
 	pred, err := r.TargetLabel.GetPredicate()
@@ -115,7 +115,7 @@ var _ = JustBeforeEach(func() {
 	tenantReconciler = tenant.Reconciler{
 		Client:                      cl,
 		Scheme:                      scheme.Scheme,
-		KeycloakActor:               keycloakActor,
+		IdentityProvider:            common.NewKeycloakIdentityProvider(keycloakActor),
 		TargetLabel:                 common.NewLabel("crownlabs.polito.it/operator-selector", "test"),
 		TenantNSKeepAlive:           24 * time.Hour,
 		WaitUserVerification:        true,
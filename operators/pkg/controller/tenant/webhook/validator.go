@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"time"
 
 	admissionv1 "k8s.io/api/admission/v1"
@@ -32,6 +33,7 @@ import (
 
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
 )
 
@@ -151,6 +153,11 @@ func (tv *TenantValidator) ValidateCreate(
 		return validate.warnings, nil
 	}
 
+	if err := checkPublicKeys(validate.newTenant, nil); err != nil {
+		log.Info("denied: invalid public keys", "reason", err)
+		return nil, err
+	}
+
 	manager, err := tv.GetClusterTenant(ctx, validate.req.UserInfo.Username)
 	if err != nil {
 		log.Error(err, "failed fetching a (manager) tenant associated to the current actor")
@@ -182,6 +189,11 @@ func (tv *TenantValidator) ValidateUpdate(
 		return validate.warnings, nil
 	}
 
+	if err := checkPublicKeys(validate.newTenant, validate.oldTenant); err != nil {
+		log.Info("denied: invalid public keys", "reason", err)
+		return nil, err
+	}
+
 	if validate.req.UserInfo.Username == validate.req.Name {
 		ctx = ctrl.LoggerInto(ctx, log.WithValues("operation", "self-edit"))
 		return tv.HandleSelfEdit(ctx, validate.newTenant, validate.oldTenant)
@@ -216,6 +228,21 @@ func (tv *TenantValidator) ValidateDelete(
 	return nil, nil
 }
 
+// checkPublicKeys denies the public keys which are not valid, or which carry authorized_keys options
+// or multiple entries, as they could escape the restrictions enforced by the bastion.
+// The keys already present in the previous version of the tenant are not checked again.
+func checkPublicKeys(newTenant, oldTenant *v1alpha2.Tenant) error {
+	for _, key := range newTenant.Spec.PublicKeys {
+		if oldTenant != nil && slices.Contains(oldTenant.Spec.PublicKeys, key) {
+			continue
+		}
+		if _, err := forge.ParseSSHPublicKey(key); err != nil {
+			return errors.NewForbidden(schema.GroupResource{}, newTenant.Name, fmt.Errorf("invalid public key %q: %w", key, err))
+		}
+	}
+	return nil
+}
+
 // HandleSelfEdit checks every field but public keys for changes:
 // - LastLogin must be within a certain tolerance;
 // - Workspaces can be changed only if autoenroll is enabled and within the allowed roles;
@@ -249,14 +276,14 @@ func (tv *TenantValidator) HandleSelfEdit(
 	newTenant.Spec.Workspaces = newWorkspaces
 	oldTenant.Spec.Workspaces = oldWorkspaces
 
-	res, err := tv.checkValidWorkspaces(ctx, newTenant, oldTenant)
+	denial, err := tv.checkValidWorkspaces(ctx, newTenant, oldTenant)
 	if err != nil {
 		log.Error(err, "failed to check workspace changes")
 		return nil, errors.NewInternalError(fmt.Errorf("failed to check workspace changes: %w", err))
 	}
-	if !res {
-		log.Info("denied: workspaces validation failed")
-		return nil, errors.NewForbidden(schema.GroupResource{}, newTenant.Name, fmt.Errorf("you are not allowed to change your own workspaces"))
+	if denial != "" {
+		log.Info("denied: workspaces validation failed", "reason", denial)
+		return nil, errors.NewForbidden(schema.GroupResource{}, newTenant.Name, fmt.Errorf("you are not allowed to change your own workspaces: %s", denial))
 	}
 
 	log.Info("allowed")
@@ -264,45 +291,115 @@ func (tv *TenantValidator) HandleSelfEdit(
 }
 
 // checkValidWorkspaces checks that the user is not changing workspaces they are not allowed to change.
+// It returns the human-readable reason why the change is not allowed, or an empty string if it is.
 func (tv *TenantValidator) checkValidWorkspaces(
 	ctx context.Context,
 	newTenant, oldTenant *v1alpha2.Tenant,
-) (bool, error) {
+) (string, error) {
 	workspaceDiff := CalculateWorkspacesDiff(newTenant, oldTenant)
-	newWorkspacesMap := mapFromWorkspacesList(newTenant)
+	newEntries := entriesFromWorkspacesList(newTenant)
+	oldEntries := entriesFromWorkspacesList(oldTenant)
 
 	for ws, changed := range workspaceDiff {
 		if !changed {
 			// it's always ok to keep the same role
 			continue
 		}
+		newEntry, enrolled := newEntries[ws]
+		oldEntry, wasEnrolled := oldEntries[ws]
+		if enrolled && wasEnrolled {
+			if newEntry.InvitationToken != "" && newEntry.InvitationToken != oldEntry.InvitationToken {
+				// invitation tokens can be redeemed only when enrolling, hence they are immutable afterwards
+				return fmt.Sprintf("the invitation token of workspace %s cannot be changed after the enrollment", ws), nil
+			}
+			if newEntry.Role == oldEntry.Role {
+				// it's always ok to drop the invitation token already redeemed
+				continue
+			}
+		}
+
 		wsObj := v1alpha1.Workspace{}
 		err := tv.Client.Get(ctx, client.ObjectKey{Name: ws}, &wsObj)
 		if err != nil {
-			return false, fmt.Errorf("failed to fetch workspace %s: %w", ws, err)
+			return "", fmt.Errorf("failed to fetch workspace %s: %w", ws, err)
 		}
-		if !utils.AutoEnrollEnabled(wsObj.Spec.AutoEnroll) {
+		if !selfEnrollmentEnabled(&wsObj) {
 			// Tenant cannot change workspaces with autoenroll disabled
-			return false, nil
+			return fmt.Sprintf("workspace %s does not allow self-enrollment", ws), nil
 		}
-		if _, ok := newWorkspacesMap[ws]; !ok {
+		if !enrolled {
 			// it's always possible to remove a Workspace from the Tenant if the target Workspace has autoenroll enabled
 			continue
 		}
-		if wsObj.Spec.AutoEnroll == v1alpha1.AutoenrollImmediate && newWorkspacesMap[ws] != v1alpha2.User {
-			// if AutoEnroll is Immediate, then the user has to enroll with User role
-			return false, nil
-		}
-		if wsObj.Spec.AutoEnroll == v1alpha1.AutoenrollWithApproval && newWorkspacesMap[ws] != v1alpha2.Candidate {
-			// if AutoEnroll is WithApproval, then the user has to enroll with Candidate role (to be approved by a Manager)
-			return false, nil
+		if denial := checkSelfEnrollment(&wsObj, newTenant, &newEntry, !wasEnrolled); denial != "" {
+			return denial, nil
 		}
 	}
 
-	return true, nil
+	return "", nil
+}
+
+// selfEnrollmentEnabled returns whether tenants can enroll themselves in the given workspace, through any of the supported means.
+func selfEnrollmentEnabled(ws *v1alpha1.Workspace) bool {
+	return utils.AutoEnrollEnabled(ws.Spec.AutoEnroll) || len(ws.Spec.Invitations) > 0 || len(ws.Spec.EmailDomainRules) > 0
+}
+
+// checkSelfEnrollment checks that the tenant is allowed to enroll itself in the given workspace with the requested role,
+// depending on the invitation token redeemed, on the autoenroll mode and on the email domain rules of the workspace.
+// Invitation tokens are considered only in case the tenant is enrolling, as they are redeemed once.
+func checkSelfEnrollment(
+	ws *v1alpha1.Workspace,
+	tenant *v1alpha2.Tenant,
+	entry *v1alpha2.TenantWorkspaceEntry,
+	enrolling bool,
+) string {
+	if forge.WorkspaceEnded(ws, time.Now()) || ws.Status.Archived {
+		return fmt.Sprintf("workspace %s has been archived", ws.Name)
+	}
+
+	if entry.InvitationToken != "" && enrolling {
+		return checkInvitation(ws, entry)
+	}
+
+	switch {
+	case ws.Spec.AutoEnroll == v1alpha1.AutoenrollImmediate && entry.Role == v1alpha2.User:
+		// if AutoEnroll is Immediate, then the user has to enroll with User role
+		return ""
+	case ws.Spec.AutoEnroll == v1alpha1.AutoenrollWithApproval && entry.Role == v1alpha2.Candidate:
+		// if AutoEnroll is WithApproval, then the user has to enroll with Candidate role (to be approved by a Manager)
+		return ""
+	case entry.Role == v1alpha2.User && forge.EmailDomainRuleMatches(ws, tenant.Spec.Email):
+		// the email domain rules allow the user to enroll with User role
+		return ""
+	}
+
+	return fmt.Sprintf("workspace %s does not allow self-enrollment with role %s", ws.Name, entry.Role)
+}
+
+// checkInvitation checks that the invitation token redeemed by the tenant is valid, not expired and not exhausted.
+// The use of the invitation is not recorded here, since the request might still be rejected (or be a dry-run),
+// but by the tenant controller once the tenant has been persisted.
+func checkInvitation(
+	ws *v1alpha1.Workspace,
+	entry *v1alpha2.TenantWorkspaceEntry,
+) string {
+	invitation := forge.WorkspaceInvitationForToken(ws, entry.InvitationToken)
+	switch {
+	case invitation == nil:
+		return fmt.Sprintf("the invitation token is not valid for workspace %s", ws.Name)
+	case forge.IsInvitationExpired(invitation, time.Now()):
+		return fmt.Sprintf("the invitation %s of workspace %s has expired", invitation.Name, ws.Name)
+	case entry.Role != v1alpha2.User:
+		return fmt.Sprintf("the invitation %s of workspace %s allows to enroll only with role %s", invitation.Name, ws.Name, v1alpha2.User)
+	case invitation.MaxUses != nil && forge.WorkspaceInvitationUses(ws, invitation.Name) >= *invitation.MaxUses:
+		return fmt.Sprintf("the invitation %s of workspace %s has already been redeemed the maximum number of times", invitation.Name, ws.Name)
+	}
+
+	return ""
 }
 
 // HandleWorkspaceEdit checks that changes made to the workspaces have been made by a valid manager, then checks other fields not to have been modified through DeepEqual.
+// Assistants of a workspace are only allowed to approve (i.e., promote to user) or reject (i.e., remove) the candidates of that workspace.
 func (tv *TenantValidator) HandleWorkspaceEdit(
 	ctx context.Context,
 	newTenant, oldTenant,
@@ -313,9 +410,29 @@ func (tv *TenantValidator) HandleWorkspaceEdit(
 
 	workspacesDiff := CalculateWorkspacesDiff(newTenant, oldTenant)
 	managerWorkspaces := mapFromWorkspacesList(manager)
+	newWorkspaces := mapFromWorkspacesList(newTenant)
+	oldWorkspaces := mapFromWorkspacesList(oldTenant)
+
+	// Assistants are granted the permissions to create tenants at the cluster level, although they are
+	// only expected to review the candidates of their workspaces, hence creations are restricted to managers.
+	isManager := func(entry v1alpha2.TenantWorkspaceEntry) bool { return entry.Role == v1alpha2.Manager }
+	if operation == admissionv1.Create && !slices.ContainsFunc(manager.Spec.Workspaces, isManager) {
+		log.Info("denied: tenant creation by non-manager")
+		return nil, errors.NewForbidden(schema.GroupResource{}, newTenant.Name, fmt.Errorf("you are not a manager of any workspace, so you cannot create tenants"))
+	}
 
 	for ws, changed := range workspacesDiff {
-		if changed && managerWorkspaces[ws] != v1alpha2.Manager {
+		if !changed {
+			continue
+		}
+		switch managerWorkspaces[ws] {
+		case v1alpha2.Manager:
+		case v1alpha2.Assistant:
+			if !isCandidateReview(oldWorkspaces[ws], newWorkspaces[ws]) {
+				log.Info("denied: unexpected tenant spec change", "assistant-for", ws)
+				return nil, errors.NewForbidden(schema.GroupResource{}, newTenant.Name, fmt.Errorf("you are an assistant for workspace %s, so you can only approve or reject its candidates", ws))
+			}
+		default:
 			log.Info("denied: unexpected tenant spec change", "not-a-manager-for", ws)
 			return nil, errors.NewForbidden(schema.GroupResource{}, newTenant.Name, fmt.Errorf("you are not a manager for workspace %s, so you cannot change it in the tenant", ws))
 		}
@@ -332,10 +449,16 @@ func (tv *TenantValidator) HandleWorkspaceEdit(
 	return nil, nil
 }
 
+// isCandidateReview returns whether the role change corresponds to the approval
+// (i.e., candidate to user) or to the rejection (i.e., removal) of a candidate.
+func isCandidateReview(oldRole, newRole v1alpha2.WorkspaceUserRole) bool {
+	return oldRole == v1alpha2.Candidate && (newRole == v1alpha2.User || newRole == "")
+}
+
 func calculateWorkspacesOneWayDiff(a, b *v1alpha2.Tenant, changes map[string]bool) map[string]bool {
-	aAsMap := mapFromWorkspacesList(a)
+	aAsMap := entriesFromWorkspacesList(a)
 	for _, v := range b.Spec.Workspaces {
-		if aAsMap[v.Name] != v.Role {
+		if entry, ok := aAsMap[v.Name]; !ok || entry.Role != v.Role || entry.InvitationToken != v.InvitationToken {
 			changes[v.Name] = true
 		}
 	}
@@ -358,3 +481,13 @@ func mapFromWorkspacesList(tenant *v1alpha2.Tenant) map[string]v1alpha2.Workspac
 
 	return wss
 }
+
+func entriesFromWorkspacesList(tenant *v1alpha2.Tenant) map[string]v1alpha2.TenantWorkspaceEntry {
+	wss := make(map[string]v1alpha2.TenantWorkspaceEntry, len(tenant.Spec.Workspaces))
+
+	for _, v := range tenant.Spec.Workspaces {
+		wss[v.Name] = v
+	}
+
+	return wss
+}
@@ -22,12 +22,15 @@ import (
 	. "github.com/onsi/gomega"
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/tenant/webhook"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
 )
 
 var _ = Describe("Validator webhook", func() {
@@ -60,6 +63,8 @@ var _ = Describe("Validator webhook", func() {
 		workspaceNAName = "test-workspace-noAutoenroll"
 		workspaceIM     *v1alpha1.Workspace
 		workspaceIMName = "test-workspace-immediate"
+		workspaceIV     *v1alpha1.Workspace
+		workspaceIVName = "test-workspace-invitations"
 	)
 
 	BeforeEach(func() {
@@ -110,13 +115,34 @@ var _ = Describe("Validator webhook", func() {
 			},
 		}
 
+		workspaceIV = &v1alpha1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{Name: workspaceIVName},
+			Spec: v1alpha1.WorkspaceSpec{
+				PrettyName: "test-workspace",
+				Quota: v1alpha1.WorkspaceResourceQuota{
+					Instances: 1,
+				},
+				Invitations: []v1alpha1.WorkspaceInvitation{
+					{Name: "valid", TokenHash: forge.InvitationTokenHash("valid-token")},
+					{Name: "expired", TokenHash: forge.InvitationTokenHash("expired-token"),
+						ExpirationTime: &metav1.Time{Time: time.Now().Add(-time.Hour)}},
+					{Name: "limited", TokenHash: forge.InvitationTokenHash("limited-token"), MaxUses: ptr.To[int32](1)},
+				},
+				EmailDomainRules: []v1alpha1.WorkspaceEmailDomainRule{{Domain: "@studenti.polito.it"}},
+			},
+			Status: v1alpha1.WorkspaceStatus{
+				Invitations: []v1alpha1.WorkspaceInvitationStatus{{Name: "limited", Uses: 1}},
+			},
+		}
+
 		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
 			manager,
 			fakeManager,
 			workspaceWA,
 			workspaceNA,
 			workspaceIM,
-		).Build()
+			workspaceIV,
+		).WithStatusSubresource(workspaceIV).Build()
 
 		tnValidator = &webhook.TenantValidator{
 			TenantWebhook: webhook.TenantWebhook{
@@ -201,6 +227,57 @@ var _ = Describe("Validator webhook", func() {
 		})
 	})
 
+	Describe("The validation of the public keys", func() {
+		const validKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILTt8yqcYTpoHYckGUirDXAVbKUiURms7F9xsz6Z7HXK"
+
+		var newTenant, oldTenant *v1alpha2.Tenant
+
+		BeforeEach(func() {
+			oldTenant = &v1alpha2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: manager.Name}}
+			newTenant = &v1alpha2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: manager.Name}}
+		})
+
+		JustBeforeEach(func() {
+			request = forgeRequest(admissionv1.Update, newTenant, oldTenant)
+			request.UserInfo.Username = manager.Name
+			response = tnWebhook.Handle(ctx, request)
+		})
+
+		When("a valid public key is added", func() {
+			BeforeEach(func() { newTenant.Spec.PublicKeys = []string{validKey + " john@laptop"} })
+			It("should allow the change", func() {
+				Expect(response.Allowed).To(BeTrue())
+			})
+		})
+
+		When("a public key carrying options is added", func() {
+			BeforeEach(func() { newTenant.Spec.PublicKeys = []string{`permitopen="*:*" ` + validKey} })
+			It("should deny the change", func() {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+				Expect(response.Result.Message).To(ContainSubstring("must not carry authorized_keys options"))
+			})
+		})
+
+		When("a public key injecting a further entry is added", func() {
+			BeforeEach(func() { newTenant.Spec.PublicKeys = []string{validKey + " comment\nrestrict " + validKey + " other"} })
+			It("should deny the change", func() {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+			})
+		})
+
+		When("an invalid public key was already present", func() {
+			BeforeEach(func() {
+				oldTenant.Spec.PublicKeys = []string{"invalid-key"}
+				newTenant.Spec.PublicKeys = []string{"invalid-key", validKey}
+			})
+			It("should allow the change", func() {
+				Expect(response.Allowed).To(BeTrue())
+			})
+		})
+	})
+
 	Describe("The TenantValidator.HandleSelfEdit method", func() {
 		var newTenant, oldTenant *v1alpha2.Tenant
 		JustBeforeEach(func() {
@@ -249,7 +326,7 @@ var _ = Describe("Validator webhook", func() {
 			WhenBody := func(nt *v1alpha2.Tenant, shouldSucceed bool) func() {
 				return func() {
 					BeforeEach(func() {
-						oldTenant = &v1alpha2.Tenant{}
+						oldTenant = &v1alpha2.Tenant{Spec: v1alpha2.TenantSpec{Email: nt.Spec.Email}}
 						newTenant = nt
 					})
 
@@ -305,6 +382,112 @@ var _ = Describe("Validator webhook", func() {
 				}),
 				false,
 			))
+
+			forgeTenantWithInvitation := func(token string) *v1alpha2.Tenant {
+				return forgeTenantWithWorkspace(v1alpha2.TenantWorkspaceEntry{
+					Name:            workspaceIVName,
+					Role:            v1alpha2.User,
+					InvitationToken: token,
+				})
+			}
+
+			When("no autoenroll and a valid invitation token", WhenBody(
+				forgeTenantWithInvitation("valid-token"),
+				true,
+			))
+
+			When("no autoenroll and an invalid invitation token", WhenBody(
+				forgeTenantWithInvitation("invalid-token"),
+				false,
+			))
+
+			When("no autoenroll and an expired invitation token", WhenBody(
+				forgeTenantWithInvitation("expired-token"),
+				false,
+			))
+
+			When("no autoenroll and an exhausted invitation token", WhenBody(
+				forgeTenantWithInvitation("limited-token"),
+				false,
+			))
+
+			When("no autoenroll and a valid invitation token with candidate role", WhenBody(
+				forgeTenantWithWorkspace(v1alpha2.TenantWorkspaceEntry{
+					Name:            workspaceIVName,
+					Role:            v1alpha2.Candidate,
+					InvitationToken: "valid-token",
+				}),
+				false,
+			))
+
+			When("no autoenroll and an email matching a domain rule", WhenBody(
+				&v1alpha2.Tenant{Spec: v1alpha2.TenantSpec{
+					Email:      "s123456@studenti.polito.it",
+					Workspaces: []v1alpha2.TenantWorkspaceEntry{{Name: workspaceIVName, Role: v1alpha2.User}},
+				}},
+				true,
+			))
+
+			When("no autoenroll and an email not matching any domain rule", WhenBody(
+				&v1alpha2.Tenant{Spec: v1alpha2.TenantSpec{
+					Email:      "john.doe@example.com",
+					Workspaces: []v1alpha2.TenantWorkspaceEntry{{Name: workspaceIVName, Role: v1alpha2.User}},
+				}},
+				false,
+			))
+		})
+
+		When("a valid invitation token is redeemed", func() {
+			BeforeEach(func() {
+				oldTenant = &v1alpha2.Tenant{}
+				newTenant = forgeTenantWithWorkspace(v1alpha2.TenantWorkspaceEntry{
+					Name:            workspaceIVName,
+					Role:            v1alpha2.User,
+					InvitationToken: "valid-token",
+				})
+			})
+
+			It("should allow the change", func() {
+				Expect(response.Allowed).To(BeTrue())
+			})
+
+			It("should not record the use of the invitation in the workspace status", func() {
+				var ws v1alpha1.Workspace
+				Expect(tnValidator.Client.Get(ctx, client.ObjectKeyFromObject(workspaceIV), &ws)).To(Succeed())
+				Expect(ws.Status.Invitations).To(ConsistOf(v1alpha1.WorkspaceInvitationStatus{Name: "limited", Uses: 1}))
+			})
+		})
+
+		When("the invitation token of an existing enrollment is changed", func() {
+			BeforeEach(func() {
+				oldTenant = forgeTenantWithWorkspaceUser(workspaceIVName)
+				newTenant = forgeTenantWithWorkspace(v1alpha2.TenantWorkspaceEntry{
+					Name:            workspaceIVName,
+					Role:            v1alpha2.User,
+					InvitationToken: "valid-token",
+				})
+			})
+
+			It("should deny the change", func() {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+				Expect(response.Result.Message).To(ContainSubstring("cannot be changed after the enrollment"))
+			})
+		})
+
+		When("the invitation token of an existing enrollment is dropped", func() {
+			BeforeEach(func() {
+				oldTenant = forgeTenantWithWorkspace(v1alpha2.TenantWorkspaceEntry{
+					Name:            workspaceIVName,
+					Role:            v1alpha2.User,
+					InvitationToken: "valid-token",
+				})
+				newTenant = forgeTenantWithWorkspaceUser(workspaceIVName)
+			})
+
+			It("should allow the change", func() {
+				Expect(response.Allowed).To(BeTrue())
+			})
 		})
 
 		When("other fields are changed", func() {
@@ -398,6 +581,85 @@ var _ = Describe("Validator webhook", func() {
 				Expect(response.Allowed).To(BeTrue())
 			})
 		})
+
+		Context("The actor is an assistant of the workspace", func() {
+			forgeTenantWithRole := func(role v1alpha2.WorkspaceUserRole) *v1alpha2.Tenant {
+				return &v1alpha2.Tenant{Spec: v1alpha2.TenantSpec{
+					Workspaces: []v1alpha2.TenantWorkspaceEntry{{Name: testWorkspace, Role: role}},
+				}}
+			}
+
+			BeforeEach(func() {
+				manager.Spec.Workspaces[0].Role = v1alpha2.Assistant
+				operation = admissionv1.Update
+			})
+
+			When("assistant approves a candidate", func() {
+				BeforeEach(func() {
+					oldTenant = forgeTenantWithRole(v1alpha2.Candidate)
+					newTenant = forgeTenantWithRole(v1alpha2.User)
+				})
+				It("Should allow the change", func() {
+					Expect(response.Allowed).To(BeTrue())
+				})
+			})
+
+			When("assistant rejects a candidate", func() {
+				BeforeEach(func() {
+					oldTenant = forgeTenantWithRole(v1alpha2.Candidate)
+					newTenant = &v1alpha2.Tenant{}
+				})
+				It("Should allow the change", func() {
+					Expect(response.Allowed).To(BeTrue())
+				})
+			})
+
+			When("assistant adds a user", func() {
+				BeforeEach(func() {
+					oldTenant = &v1alpha2.Tenant{}
+					newTenant = forgeTenantWithRole(v1alpha2.User)
+				})
+				It("Should deny the change", func() {
+					Expect(response.Allowed).To(BeFalse())
+					Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+					Expect(response.Result.Reason).NotTo(BeEmpty())
+				})
+			})
+
+			When("assistant removes a user", func() {
+				BeforeEach(func() {
+					oldTenant = forgeTenantWithRole(v1alpha2.User)
+					newTenant = &v1alpha2.Tenant{}
+				})
+				It("Should deny the change", func() {
+					Expect(response.Allowed).To(BeFalse())
+					Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+				})
+			})
+
+			When("assistant creates a new tenant", func() {
+				BeforeEach(func() {
+					oldTenant = &v1alpha2.Tenant{}
+					newTenant = &v1alpha2.Tenant{Spec: v1alpha2.TenantSpec{Email: "other"}}
+					operation = admissionv1.Create
+				})
+				It("Should deny the change", func() {
+					Expect(response.Allowed).To(BeFalse())
+					Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+				})
+			})
+
+			When("assistant promotes a candidate to manager", func() {
+				BeforeEach(func() {
+					oldTenant = forgeTenantWithRole(v1alpha2.Candidate)
+					newTenant = forgeTenantWithRole(v1alpha2.Manager)
+				})
+				It("Should deny the change", func() {
+					Expect(response.Allowed).To(BeFalse())
+					Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+				})
+			})
+		})
 	})
 
 	Describe("The CalculateWorkspacesDiff function", func() {
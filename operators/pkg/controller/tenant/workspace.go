@@ -16,12 +16,15 @@ package tenant
 
 import (
 	"context"
+	"fmt"
 	"maps"
 	"slices"
 	"strings"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
@@ -53,9 +56,18 @@ func (r *Reconciler) syncWorkspaces(
 		r.removeNoWorkspaceLabel(labels)
 	}
 
-	// update labels
+	// record the uses of the invitation tokens redeemed, before dropping them from the tenant
+	if err := r.recordInvitationUses(ctx, log, tn); err != nil {
+		log.Error(err, "Error recording the uses of the invitations redeemed by tenant", "tenant", tn.Name)
+		return err
+	}
+
+	// update labels, and drop the invitation tokens already redeemed, as they are no longer needed
 	if err := r.enforcePreservingStatus(ctx, log, tn, func(t *v1alpha2.Tenant) *v1alpha2.Tenant {
 		t.Labels = labels
+		for i := range t.Spec.Workspaces {
+			t.Spec.Workspaces[i].InvitationToken = ""
+		}
 		return t
 	}); err != nil {
 		log.Error(err, "Error updating tenant with workspaces labels", "tenant", tn.Name)
@@ -67,6 +79,48 @@ func (r *Reconciler) syncWorkspaces(
 	return nil
 }
 
+// recordInvitationUses records, in the status of the corresponding workspaces, the uses of the invitation tokens redeemed
+// by the tenant. This is performed once the tenant has been persisted (rather than by the validating webhook), to avoid
+// consuming the invitations in case of dry-run or rejected requests. Since the tokens are dropped from the tenant only
+// afterwards, a failure in between leads to counting the same use twice, which is the safe direction for usage limits.
+func (r *Reconciler) recordInvitationUses(
+	ctx context.Context,
+	log logr.Logger,
+	tn *v1alpha2.Tenant,
+) error {
+	for i := range tn.Spec.Workspaces {
+		entry := &tn.Spec.Workspaces[i]
+		if entry.InvitationToken == "" {
+			continue
+		}
+
+		if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			var workspace v1alpha1.Workspace
+			if err := r.Get(ctx, types.NamespacedName{Name: entry.Name}, &workspace); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+
+			// the invitation might have been removed in the meanwhile, hence there is nothing to record
+			invitation := forge.WorkspaceInvitationForToken(&workspace, entry.InvitationToken)
+			if invitation == nil {
+				return nil
+			}
+
+			forge.RecordWorkspaceInvitationUse(&workspace, invitation.Name)
+			if err := r.Status().Update(ctx, &workspace); err != nil {
+				return err
+			}
+
+			log.Info("Recorded the use of invitation", "workspace", workspace.Name, "invitation", invitation.Name, "tenant", tn.Name)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed recording the use of the invitation of workspace %s: %w", entry.Name, err)
+		}
+	}
+
+	return nil
+}
+
 func (r *Reconciler) syncSingleWorkspace(
 	ctx context.Context,
 	log logr.Logger,
@@ -18,10 +18,12 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
 )
 
 var _ = Describe("Workspace management", func() {
@@ -43,17 +45,32 @@ var _ = Describe("Workspace management", func() {
 			Name: "base-ws1",
 		},
 	}
+	wsIV := &v1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ws-invitations",
+		},
+		Spec: v1alpha1.WorkspaceSpec{
+			Invitations: []v1alpha1.WorkspaceInvitation{
+				{Name: "valid", TokenHash: forge.InvitationTokenHash("valid-token"), MaxUses: ptr.To[int32](10)},
+			},
+		},
+		Status: v1alpha1.WorkspaceStatus{
+			Invitations: []v1alpha1.WorkspaceInvitationStatus{{Name: "valid", Uses: 2}},
+		},
+	}
 
 	BeforeEach(func() {
 		addObjToObjectsList(ws1)
 		addObjToObjectsList(ws2)
 		addObjToObjectsList(bws1)
+		addObjToObjectsList(wsIV)
 	})
 
 	AfterEach(func() {
 		removeObjFromObjectsList(ws1)
 		removeObjFromObjectsList(ws2)
 		removeObjFromObjectsList(bws1)
+		removeObjFromObjectsList(wsIV)
 	})
 
 	Context("When a workspace is present and valid", func() {
@@ -89,6 +106,47 @@ var _ = Describe("Workspace management", func() {
 			})
 		})
 
+		Context("and an invitation token has been redeemed", func() {
+			BeforeEach(func() {
+				tnResource.Spec.Workspaces = []v1alpha2.TenantWorkspaceEntry{{
+					Name:            "ws1",
+					Role:            v1alpha2.User,
+					InvitationToken: "some-token",
+				}}
+			})
+
+			It("Should drop the invitation token, preserving the enrollment", func() {
+				tn := &v1alpha2.Tenant{}
+				DoesEventuallyExists(ctx, cl, client.ObjectKey{Name: tnName}, tn, BeTrue(), timeout, interval)
+
+				Expect(tn.Spec.Workspaces).To(ConsistOf(v1alpha2.TenantWorkspaceEntry{Name: "ws1", Role: v1alpha2.User}))
+			})
+		})
+
+		Context("and a valid invitation token has been redeemed", func() {
+			BeforeEach(func() {
+				tnResource.Spec.Workspaces = []v1alpha2.TenantWorkspaceEntry{{
+					Name:            "ws-invitations",
+					Role:            v1alpha2.User,
+					InvitationToken: "valid-token",
+				}}
+			})
+
+			It("Should record the use of the invitation in the workspace status", func() {
+				ws := &v1alpha1.Workspace{}
+				DoesEventuallyExists(ctx, cl, client.ObjectKey{Name: "ws-invitations"}, ws, BeTrue(), timeout, interval)
+
+				Expect(ws.Status.Invitations).To(ConsistOf(v1alpha1.WorkspaceInvitationStatus{Name: "valid", Uses: 3}))
+			})
+
+			It("Should drop the invitation token, preserving the enrollment", func() {
+				tn := &v1alpha2.Tenant{}
+				DoesEventuallyExists(ctx, cl, client.ObjectKey{Name: tnName}, tn, BeTrue(), timeout, interval)
+
+				Expect(tn.Spec.Workspaces).To(ConsistOf(v1alpha2.TenantWorkspaceEntry{Name: "ws-invitations", Role: v1alpha2.User}))
+			})
+		})
+
 		Context("and the role is candidate", func() {
 			Context("and the workspace is auto-enrollable", func() {
 				BeforeEach(func() {
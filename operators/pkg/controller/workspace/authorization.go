@@ -17,13 +17,12 @@ package workspace
 
 import (
 	"context"
-	"fmt"
-	"net/http"
 
 	"github.com/go-logr/logr"
 
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
 	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/common"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
 )
 
@@ -32,7 +31,7 @@ func (r *Reconciler) createKeycloakRoles(
 	ws *v1alpha1.Workspace,
 	log logr.Logger,
 ) error {
-	if !r.KeycloakActor.IsInitialized() {
+	if !r.IdentityProvider.IsInitialized() {
 		ws.Status.Subscriptions["keycloak"] = v1alpha2.SubscrFailed
 		log.Info("Keycloak actor is not initialized, skipping role creation for workspace", "workspace", ws.Name)
 		return nil
@@ -47,6 +46,15 @@ func (r *Reconciler) createKeycloakRoles(
 		return err
 	}
 
+	// Create assistant role
+	assistantRoleName := forge.GetWorkspaceAssistantRoleName(ws)
+	assistantRoleDesc := forge.GetWorkspaceAssistantRoleDescription(ws)
+	if err := r.createKeycloakRole(ctx, ws, assistantRoleName, assistantRoleDesc, log); err != nil {
+		ws.Status.Subscriptions["keycloak"] = v1alpha2.SubscrFailed
+		log.Error(err, "Error when creating Keycloak assistant role", "role", assistantRoleName, "workspace", ws.Name)
+		return err
+	}
+
 	// Create user role
 	userRoleName := forge.GetWorkspaceUserRoleName(ws)
 	userRoleDesc := forge.GetWorkspaceUserRoleDescription(ws)
@@ -68,12 +76,12 @@ func (r *Reconciler) createKeycloakRole(
 	roleDescription string,
 	log logr.Logger,
 ) error {
-	if !r.KeycloakActor.IsInitialized() {
+	if !r.IdentityProvider.IsInitialized() {
 		log.Info("Keycloak actor is not initialized, skipping role creation for workspace", "workspace", ws.Name)
 		return nil
 	}
 
-	if role, err := r.KeycloakActor.GetRole(ctx, roleName); err != nil && err.Error() != fmt.Sprintf("%d", http.StatusNotFound) {
+	if role, err := r.IdentityProvider.GetRole(ctx, roleName); err != nil && !common.IsIdentityNotFound(err) {
 		log.Error(err, "Error when getting Keycloak role", "role", roleName, "workspace", ws.Name)
 		return err
 	} else if role != nil {
@@ -81,7 +89,7 @@ func (r *Reconciler) createKeycloakRole(
 		return nil
 	}
 
-	if _, err := r.KeycloakActor.CreateRole(ctx, roleName, roleDescription); err != nil {
+	if _, err := r.IdentityProvider.CreateRole(ctx, roleName, roleDescription); err != nil {
 		log.Error(err, "Error when creating Keycloak role", "role", roleName, "workspace", ws.Name)
 		return err
 	}
@@ -95,7 +103,7 @@ func (r *Reconciler) deleteKeycloakRoles(
 	ws *v1alpha1.Workspace,
 	log logr.Logger,
 ) error {
-	if !r.KeycloakActor.IsInitialized() {
+	if !r.IdentityProvider.IsInitialized() {
 		log.Info("Keycloak actor is not initialized, skipping role deletion for workspace", "workspace", ws.Name)
 		return nil
 	}
@@ -107,6 +115,13 @@ func (r *Reconciler) deleteKeycloakRoles(
 		return err
 	}
 
+	// Delete assistant role
+	assistantRoleName := forge.GetWorkspaceAssistantRoleName(ws)
+	if err := r.deleteKeycloakRole(ctx, ws, assistantRoleName, log); err != nil {
+		log.Error(err, "Error when deleting Keycloak assistant role", "role", assistantRoleName, "workspace", ws.Name)
+		return err
+	}
+
 	// Delete user role
 	userRoleName := forge.GetWorkspaceUserRoleName(ws)
 	if err := r.deleteKeycloakRole(ctx, ws, userRoleName, log); err != nil {
@@ -123,12 +138,12 @@ func (r *Reconciler) deleteKeycloakRole(
 	roleName string,
 	log logr.Logger,
 ) error {
-	if !r.KeycloakActor.IsInitialized() {
+	if !r.IdentityProvider.IsInitialized() {
 		log.Info("Keycloak actor is not initialized, skipping role deletion for workspace", "workspace", ws.Name)
 		return nil
 	}
 
-	if err := r.KeycloakActor.DeleteRole(ctx, roleName); err != nil {
+	if err := r.IdentityProvider.DeleteRole(ctx, roleName); err != nil {
 		log.Error(err, "Error when deleting Keycloak role", "role", roleName, "workspace", ws.Name)
 		return err
 	}
@@ -44,8 +44,10 @@ var _ = Describe("Authorization", func() {
 		Context("When Keycloak roles are not yet present", func() {
 			BeforeEach(func() {
 				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":manager").Return(nil, nil).Times(1)
+				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":assistant").Return(nil, nil).Times(1)
 				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":user").Return(nil, nil).Times(1)
 				keycloakActor.EXPECT().CreateRole(gomock.Any(), "workspace-"+wsName+":manager", wsPrettyName+" Manager Role").Times(1)
+				keycloakActor.EXPECT().CreateRole(gomock.Any(), "workspace-"+wsName+":assistant", wsPrettyName+" Assistant Role").Times(1)
 				keycloakActor.EXPECT().CreateRole(gomock.Any(), "workspace-"+wsName+":user", wsPrettyName+" User Role").Times(1)
 			})
 
@@ -63,6 +65,7 @@ var _ = Describe("Authorization", func() {
 		Context("When Keycloak roles are already present", func() {
 			BeforeEach(func() {
 				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":manager").Return(&gocloak.Role{Name: gocloak.StringP("workspace-" + wsName + ":manager")}, nil).Times(1)
+				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":assistant").Return(&gocloak.Role{Name: gocloak.StringP("workspace-" + wsName + ":assistant")}, nil).Times(1)
 				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":user").Return(&gocloak.Role{Name: gocloak.StringP("workspace-" + wsName + ":user")}, nil).Times(1)
 				keycloakActor.EXPECT().CreateRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			})
@@ -85,6 +88,7 @@ var _ = Describe("Authorization", func() {
 					"keycloak": v1alpha2.SubscrOk,
 				}
 				keycloakActor.EXPECT().DeleteRole(gomock.Any(), "workspace-"+wsName+":manager").Return(nil).Times(1)
+				keycloakActor.EXPECT().DeleteRole(gomock.Any(), "workspace-"+wsName+":assistant").Return(nil).Times(1)
 				keycloakActor.EXPECT().DeleteRole(gomock.Any(), "workspace-"+wsName+":user").Return(nil).Times(1)
 			})
 
@@ -96,6 +100,7 @@ var _ = Describe("Authorization", func() {
 		Context("When an error occurs in the getRole call", func() {
 			BeforeEach(func() {
 				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":manager").Return(nil, fmt.Errorf("error getting role")).Times(1)
+				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":assistant").Return(&gocloak.Role{Name: gocloak.StringP("workspace-" + wsName + ":assistant")}, nil).AnyTimes()
 				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":user").Return(&gocloak.Role{Name: gocloak.StringP("workspace-" + wsName + ":user")}, nil).AnyTimes()
 			})
 
@@ -109,6 +114,7 @@ var _ = Describe("Authorization", func() {
 		Context("When an error occurs in the createRole call", func() {
 			BeforeEach(func() {
 				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":manager").Return(nil, nil).AnyTimes()
+				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":assistant").Return(&gocloak.Role{Name: gocloak.StringP("workspace-" + wsName + ":assistant")}, nil).AnyTimes()
 				keycloakActor.EXPECT().GetRole(gomock.Any(), "workspace-"+wsName+":user").Return(&gocloak.Role{Name: gocloak.StringP("workspace-" + wsName + ":user")}, nil).AnyTimes()
 				keycloakActor.EXPECT().CreateRole(gomock.Any(), "workspace-"+wsName+":manager", wsPrettyName+" Manager Role").Return("", fmt.Errorf("error creating role")).Times(1)
 			})
@@ -133,6 +139,7 @@ var _ = Describe("Authorization", func() {
 				}
 				keycloakActor.EXPECT().DeleteRole(gomock.Any(), "workspace-"+wsName+":user").Return(fmt.Errorf("error deleting role")).AnyTimes()
 				keycloakActor.EXPECT().DeleteRole(gomock.Any(), "workspace-"+wsName+":manager").Return(fmt.Errorf("error deleting role")).AnyTimes()
+				keycloakActor.EXPECT().DeleteRole(gomock.Any(), "workspace-"+wsName+":assistant").Return(fmt.Errorf("error deleting role")).AnyTimes()
 				wsReconcileErrExpected = HaveOccurred()
 			})
 
@@ -38,6 +38,11 @@ func (r *Reconciler) enforceClusterRoleBindings(
 		return err
 	}
 
+	// Create or update the ClusterRoleBinding for controlling instances
+	if err := r.enforceInstancesControllerBinding(ctx, ws); err != nil {
+		return err
+	}
+
 	// Create or update the ClusterRoleBinding for managing tenants
 	if err := r.enforceTenantsManagerBinding(ctx, ws); err != nil {
 		return err
@@ -76,6 +81,36 @@ func (r *Reconciler) enforceInstancesManagerBinding(
 	return nil
 }
 
+// enforceInstancesControllerBinding ensures that the ClusterRoleBinding for controlling instances exists.
+func (r *Reconciler) enforceInstancesControllerBinding(
+	ctx context.Context,
+	ws *v1alpha1.Workspace,
+) error {
+	// Create only the skeleton of the ClusterRoleBinding with immutable information
+	name := forge.GetWorkspaceInstancesControllerBindingName(ws)
+	crb := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+
+	// Update or create the resource, setting all mutable values in the callback
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, crb, func() error {
+		// Update labels
+		crb.Labels = forge.UpdateWorkspaceResourceCommonLabels(crb.Labels, r.TargetLabel)
+
+		// Configure subjects and roleRef
+		forge.ConfigureWorkspaceInstancesControllerBinding(ws, crb)
+
+		return controllerutil.SetControllerReference(ws, crb, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("error while creating/updating instances controller ClusterRoleBinding for workspace %s: %w",
+			ws.Name, err)
+	}
+
+	return nil
+}
+
 // enforceTenantsManagerBinding ensures that the ClusterRoleBinding for managing tenants exists.
 func (r *Reconciler) enforceTenantsManagerBinding(
 	ctx context.Context,
@@ -116,6 +151,11 @@ func (r *Reconciler) enforceClusterRoleBindingsAbsence(
 		return err
 	}
 
+	// Delete the ClusterRoleBinding for controlling instances
+	if err := r.enforceInstancesControllerBindingAbsence(ctx, ws); err != nil {
+		return err
+	}
+
 	// Delete the ClusterRoleBinding for managing tenants
 	if err := r.enforceTenantsManagerBindingAbsence(ctx, ws); err != nil {
 		return err
@@ -144,6 +184,26 @@ func (r *Reconciler) enforceInstancesManagerBindingAbsence(
 	return nil
 }
 
+// enforceInstancesControllerBindingAbsence deletes the ClusterRoleBinding for controlling instances.
+func (r *Reconciler) enforceInstancesControllerBindingAbsence(
+	ctx context.Context,
+	ws *v1alpha1.Workspace,
+) error {
+	// Create only the skeleton of the ClusterRoleBinding needed for deletion
+	name := forge.GetWorkspaceInstancesControllerBindingName(ws)
+	crb := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+
+	if err := utils.EnforceObjectAbsence(ctx, r.Client, crb, "ClusterRoleBinding"); err != nil {
+		return fmt.Errorf("error while deleting instances controller ClusterRoleBinding for workspace %s: %w",
+			ws.Name, err)
+	}
+	return nil
+}
+
 // deleteTenantsManagerBinding deletes the ClusterRoleBinding for managing tenants.
 func (r *Reconciler) enforceTenantsManagerBindingAbsence(
 	ctx context.Context,
@@ -87,13 +87,26 @@ var _ = Describe("ClusterRoleBinding", func() {
 			Expect(crb.RoleRef.Name).To(Equal("crownlabs-manage-instances"))
 		})
 
+		It("Should create a ClusterRoleBinding to control instances", func() {
+			crb := &rbacv1.ClusterRoleBinding{}
+			DoesEventuallyExists(ctx, cl, client.ObjectKey{Name: "crownlabs-control-instances-" + wsName}, crb, BeTrue(), timeout, interval)
+			Expect(crb.Subjects).To(HaveLen(1))
+			Expect(crb.Subjects[0].Kind).To(Equal("Group"))
+			Expect(crb.Subjects[0].Name).To(Equal("kubernetes:workspace-" + wsName + ":assistant"))
+			Expect(crb.Subjects[0].APIGroup).To(Equal("rbac.authorization.k8s.io"))
+			Expect(crb.RoleRef.Kind).To(Equal("ClusterRole"))
+			Expect(crb.RoleRef.APIGroup).To(Equal("rbac.authorization.k8s.io"))
+			Expect(crb.RoleRef.Name).To(Equal("crownlabs-control-instances"))
+		})
+
 		It("Should create a ClusterRoleBinding to manage tenants", func() {
 			crb := &rbacv1.ClusterRoleBinding{}
 			DoesEventuallyExists(ctx, cl, client.ObjectKey{Name: "crownlabs-manage-tenants-" + wsName}, crb, BeTrue(), timeout, interval)
-			Expect(crb.Subjects).To(HaveLen(1))
+			Expect(crb.Subjects).To(HaveLen(2))
 			Expect(crb.Subjects[0].Kind).To(Equal("Group"))
 			Expect(crb.Subjects[0].Name).To(Equal("kubernetes:workspace-" + wsName + ":manager"))
 			Expect(crb.Subjects[0].APIGroup).To(Equal("rbac.authorization.k8s.io"))
+			Expect(crb.Subjects[1].Name).To(Equal("kubernetes:workspace-" + wsName + ":assistant"))
 			Expect(crb.RoleRef.Kind).To(Equal("ClusterRole"))
 			Expect(crb.RoleRef.APIGroup).To(Equal("rbac.authorization.k8s.io"))
 			Expect(crb.RoleRef.Name).To(Equal("crownlabs-manage-tenants"))
@@ -0,0 +1,241 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+const (
+	// endOfLifeRequeueInterval is the interval after which the workspace is reconciled again,
+	// while waiting for its persistent instances to be stopped and snapshotted.
+	endOfLifeRequeueInterval = time.Minute
+	// endOfLifeSnapshotTimeout is the maximum time, after the end of the active period, the workspace
+	// waits for its persistent instances to be stopped. Instances still running afterwards are not snapshotted.
+	endOfLifeSnapshotTimeout = time.Hour
+)
+
+// enforceLifecycle applies the end-of-life policy to the workspace, once the end of its active period has been reached.
+// It returns the interval after which the workspace shall be reconciled again to progress in its lifecycle, if any.
+func (r *Reconciler) enforceLifecycle(
+	ctx context.Context,
+	ws *v1alpha1.Workspace,
+	log logr.Logger,
+) (time.Duration, error) {
+	now := time.Now()
+	if !forge.WorkspaceEnded(ws, now) {
+		if ws.Status.Archived || ws.Spec.ActivePeriod != nil {
+			ws.Status.Archived = false
+			utils.SetCondition(&ws.Status.Conditions, ws, v1alpha2.ConditionArchived, metav1.ConditionFalse,
+				v1alpha2.ReasonWithinActivePeriod, "Workspace within its active period")
+		}
+		if ws.Spec.ActivePeriod != nil && ws.Spec.ActivePeriod.End != nil {
+			return ws.Spec.ActivePeriod.End.Sub(now), nil
+		}
+		return 0, nil
+	}
+
+	instances, err := ListWorkspaceInstances(ctx, r.Client, ws)
+	if err != nil {
+		return 0, err
+	}
+
+	// Instances are stopped also after the archival, in case they have been restarted bypassing the webhook.
+	if err := r.stopInstances(ctx, instances, log); err != nil {
+		return 0, err
+	}
+
+	if ws.Status.Archived {
+		return 0, nil
+	}
+
+	if ws.Spec.EndOfLifePolicy.SnapshotPersistentInstances {
+		waitStop := now.Before(ws.Spec.ActivePeriod.End.Add(endOfLifeSnapshotTimeout))
+		pendingInstances, pendingSnapshots, err := r.enforceEndOfLifeSnapshots(ctx, ws, instances, waitStop, log)
+		if err != nil {
+			return 0, err
+		}
+		// Users are unenrolled only once the snapshots have been completed, to avoid losing their data.
+		switch {
+		case pendingInstances > 0:
+			utils.SetCondition(&ws.Status.Conditions, ws, v1alpha2.ConditionArchived, metav1.ConditionFalse, v1alpha2.ReasonActivePeriodEnded,
+				fmt.Sprintf("Waiting for %d persistent instances to be stopped before snapshotting them", pendingInstances))
+			return endOfLifeRequeueInterval, nil
+		case pendingSnapshots > 0:
+			utils.SetCondition(&ws.Status.Conditions, ws, v1alpha2.ConditionArchived, metav1.ConditionFalse, v1alpha2.ReasonActivePeriodEnded,
+				fmt.Sprintf("Waiting for %d end-of-life snapshots to be completed", pendingSnapshots))
+			return endOfLifeRequeueInterval, nil
+		}
+	}
+
+	if !ws.Spec.EndOfLifePolicy.PreserveUserEnrollments {
+		if err := r.unenrollUsers(ctx, ws, log); err != nil {
+			return 0, err
+		}
+	}
+
+	ws.Status.Archived = true
+	utils.SetCondition(&ws.Status.Conditions, ws, v1alpha2.ConditionArchived, metav1.ConditionTrue, v1alpha2.ReasonActivePeriodEnded,
+		fmt.Sprintf("Workspace archived, since its active period ended at %s", ws.Spec.ActivePeriod.End.Format(time.RFC3339)))
+	log.Info("Workspace archived")
+
+	return 0, nil
+}
+
+// stopInstances stops the given instances, if running.
+func (r *Reconciler) stopInstances(
+	ctx context.Context,
+	instances []v1alpha2.Instance,
+	log logr.Logger,
+) error {
+	for i := range instances {
+		instance := &instances[i]
+		if !instance.Spec.Running {
+			continue
+		}
+
+		if err := utils.PatchObject(ctx, r.Client, instance, func(inst *v1alpha2.Instance) *v1alpha2.Instance {
+			inst.Spec.Running = false
+			return inst
+		}); err != nil {
+			log.Error(err, "Error when stopping instance", "instance", client.ObjectKeyFromObject(instance))
+			return fmt.Errorf("error stopping instance %s: %w", client.ObjectKeyFromObject(instance), err)
+		}
+		log.Info("Instance stopped due to the end of the workspace active period", "instance", client.ObjectKeyFromObject(instance))
+	}
+	return nil
+}
+
+// enforceEndOfLifeSnapshots creates an InstanceSnapshot for each persistent environment of the given instances,
+// once they have been shut down. It returns the number of instances not yet shut down, and the number of snapshots
+// not yet terminated. Failed instances, which cannot reach the off phase, are skipped, as well as all the instances
+// not yet shut down in case waitStop is false.
+func (r *Reconciler) enforceEndOfLifeSnapshots(
+	ctx context.Context,
+	ws *v1alpha1.Workspace,
+	instances []v1alpha2.Instance,
+	waitStop bool,
+	log logr.Logger,
+) (pendingInstances, pendingSnapshots int, err error) {
+	templates := make(map[string]*v1alpha2.Template)
+
+	for i := range instances {
+		instance := &instances[i]
+
+		template, found := templates[instance.Spec.Template.Name]
+		if !found {
+			template = &v1alpha2.Template{}
+			name := types.NamespacedName{Namespace: instance.Spec.Template.Namespace, Name: instance.Spec.Template.Name}
+			if err := r.Get(ctx, name, template); client.IgnoreNotFound(err) != nil {
+				log.Error(err, "Error when retrieving template", "template", name)
+				return 0, 0, fmt.Errorf("error retrieving template %s: %w", name, err)
+			} else if err != nil {
+				template = nil
+			}
+			templates[instance.Spec.Template.Name] = template
+		}
+
+		// Instances referring to non-existing templates cannot be snapshotted.
+		if template == nil {
+			continue
+		}
+
+		var persistent []*v1alpha2.Environment
+		for j := range template.Spec.EnvironmentList {
+			if template.Spec.EnvironmentList[j].Persistent {
+				persistent = append(persistent, &template.Spec.EnvironmentList[j])
+			}
+		}
+		if len(persistent) == 0 {
+			continue
+		}
+
+		switch {
+		case instance.Status.Phase == v1alpha2.EnvironmentPhaseOff:
+		case instance.Status.Phase == v1alpha2.EnvironmentPhaseFailed || instance.Status.Phase == v1alpha2.EnvironmentPhaseCreationLoopBackoff:
+			log.Info("Skipping end-of-life snapshot of failed instance", "instance", client.ObjectKeyFromObject(instance))
+			continue
+		case !waitStop:
+			log.Info("Skipping end-of-life snapshot of instance not shut down in time", "instance", client.ObjectKeyFromObject(instance))
+			continue
+		default:
+			pendingInstances++
+			continue
+		}
+
+		for _, environment := range persistent {
+			isnap := &v1alpha2.InstanceSnapshot{ObjectMeta: metav1.ObjectMeta{
+				Name:      forge.EndOfLifeSnapshotName(instance, environment),
+				Namespace: instance.Namespace,
+			}}
+			if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, isnap, func() error {
+				forge.ConfigureEndOfLifeSnapshot(isnap, ws, instance, environment)
+				return nil
+			}); err != nil {
+				log.Error(err, "Error when creating end-of-life snapshot", "snapshot", client.ObjectKeyFromObject(isnap))
+				return 0, 0, fmt.Errorf("error creating snapshot %s: %w", client.ObjectKeyFromObject(isnap), err)
+			}
+			if isnap.Status.Phase != v1alpha2.Completed && isnap.Status.Phase != v1alpha2.Failed {
+				pendingSnapshots++
+			}
+		}
+	}
+
+	return pendingInstances, pendingSnapshots, nil
+}
+
+// unenrollUsers removes the workspace from the tenants subscribed to it without being managers.
+func (r *Reconciler) unenrollUsers(
+	ctx context.Context,
+	ws *v1alpha1.Workspace,
+	log logr.Logger,
+) error {
+	var tenants v1alpha2.TenantList
+	if err := r.List(ctx, &tenants, &client.HasLabels{forge.GetWorkspaceTargetLabel(ws.Name)}); err != nil {
+		log.Error(err, "Error when listing tenants subscribed to workspace", "workspace", ws.Name)
+		return err
+	}
+
+	for i := range tenants.Items {
+		tn := &tenants.Items[i]
+		if role, found := forge.TenantWorkspaceRole(tn, ws.Name); !found || role == v1alpha2.Manager {
+			continue
+		}
+
+		if err := utils.PatchObject(ctx, r.Client, tn, func(t *v1alpha2.Tenant) *v1alpha2.Tenant {
+			removeWorkspaceFromTenant(&t.Spec.Workspaces, ws.Name)
+			return t
+		}); err != nil {
+			log.Error(err, "Error when unsubscribing tenant from workspace", "tenant", tn.Name, "workspace", ws.Name)
+			return err
+		}
+		log.Info("Tenant unsubscribed from archived workspace", "tenant", tn.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+var _ = Describe("Lifecycle", func() {
+	var (
+		template  *v1alpha2.Template
+		instances []*v1alpha2.Instance
+		ws        v1alpha1.Workspace
+	)
+
+	forgeInstance := func(name string, running bool, phase v1alpha2.EnvironmentPhase) *v1alpha2.Instance {
+		return &v1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "tenant-tester"},
+			Spec: v1alpha2.InstanceSpec{
+				Template: v1alpha2.GenericRef{Name: "template", Namespace: "workspace-" + wsName},
+				Running:  running,
+			},
+			Status: v1alpha2.InstanceStatus{Phase: phase},
+		}
+	}
+
+	getInstance := func(name string) *v1alpha2.Instance {
+		var instance v1alpha2.Instance
+		Expect(cl.Get(ctx, client.ObjectKey{Namespace: "tenant-tester", Name: name}, &instance)).To(Succeed())
+		return &instance
+	}
+
+	BeforeEach(func() {
+		template = &v1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "workspace-" + wsName},
+			Spec: v1alpha2.TemplateSpec{
+				WorkspaceRef:    v1alpha2.GenericRef{Name: wsName},
+				EnvironmentList: []v1alpha2.Environment{{Name: "vm", Persistent: true}},
+			},
+		}
+		instances = []*v1alpha2.Instance{
+			forgeInstance("running", true, v1alpha2.EnvironmentPhaseReady),
+			forgeInstance("stopped", false, v1alpha2.EnvironmentPhaseOff),
+		}
+
+		addObjToObjectsList(template)
+		for _, instance := range instances {
+			addObjToObjectsList(instance)
+		}
+		for _, tenant := range tenantResources {
+			addObjToObjectsList(tenant)
+		}
+	})
+
+	AfterEach(func() {
+		removeObjFromObjectsList(template)
+		for _, instance := range instances {
+			removeObjFromObjectsList(instance)
+		}
+		for _, tenant := range tenantResources {
+			removeObjFromObjectsList(tenant)
+		}
+	})
+
+	JustBeforeEach(func() {
+		Expect(cl.Get(ctx, client.ObjectKey{Name: wsName}, &ws)).To(Succeed())
+	})
+
+	When("the active period has not yet ended", func() {
+		BeforeEach(func() {
+			wsResource.Spec.ActivePeriod = &v1alpha1.WorkspaceActivePeriod{End: &metav1.Time{Time: time.Now().Add(time.Hour)}}
+		})
+
+		It("Should not archive the workspace", func() {
+			Expect(ws.Status.Archived).To(BeFalse())
+			Expect(meta.IsStatusConditionFalse(ws.Status.Conditions, string(v1alpha2.ConditionArchived))).To(BeTrue())
+		})
+
+		It("Should not stop the instances", func() {
+			Expect(getInstance("running").Spec.Running).To(BeTrue())
+		})
+	})
+
+	When("the active period has ended", func() {
+		BeforeEach(func() {
+			wsResource.Spec.ActivePeriod = &v1alpha1.WorkspaceActivePeriod{End: &metav1.Time{Time: time.Now().Add(-time.Hour)}}
+		})
+
+		It("Should stop the running instances", func() {
+			Expect(getInstance("running").Spec.Running).To(BeFalse())
+		})
+
+		It("Should archive the workspace", func() {
+			Expect(ws.Status.Archived).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(ws.Status.Conditions, string(v1alpha2.ConditionArchived))).To(BeTrue())
+		})
+
+		It("Should unenroll the users, preserving the managers", func() {
+			var user, manager v1alpha2.Tenant
+			Expect(cl.Get(ctx, client.ObjectKey{Name: "test-user"}, &user)).To(Succeed())
+			Expect(cl.Get(ctx, client.ObjectKey{Name: "test-manager"}, &manager)).To(Succeed())
+			Expect(user.Spec.Workspaces).To(BeEmpty())
+			Expect(manager.Spec.Workspaces).To(ConsistOf(v1alpha2.TenantWorkspaceEntry{Name: wsName, Role: v1alpha2.Manager}))
+		})
+
+		When("the user enrollments shall be preserved", func() {
+			BeforeEach(func() { wsResource.Spec.EndOfLifePolicy.PreserveUserEnrollments = true })
+
+			It("Should not unenroll the users", func() {
+				var user v1alpha2.Tenant
+				Expect(cl.Get(ctx, client.ObjectKey{Name: "test-user"}, &user)).To(Succeed())
+				Expect(user.Spec.Workspaces).To(ConsistOf(v1alpha2.TenantWorkspaceEntry{Name: wsName, Role: v1alpha2.User}))
+			})
+		})
+
+		When("the persistent instances shall be snapshotted", func() {
+			BeforeEach(func() { wsResource.Spec.EndOfLifePolicy.SnapshotPersistentInstances = true })
+
+			It("Should snapshot the instances already shut down", func() {
+				var isnap v1alpha2.InstanceSnapshot
+				Expect(cl.Get(ctx, client.ObjectKey{Namespace: "tenant-tester", Name: "stopped-vm-eol"}, &isnap)).To(Succeed())
+				Expect(isnap.Spec.Instance).To(Equal(v1alpha2.GenericRef{Name: "stopped", Namespace: "tenant-tester"}))
+				Expect(isnap.Spec.Environment.Name).To(Equal("vm"))
+			})
+
+			It("Should wait for the other instances to be shut down before archiving the workspace", func() {
+				var isnap v1alpha2.InstanceSnapshot
+				err := cl.Get(ctx, client.ObjectKey{Namespace: "tenant-tester", Name: "running-vm-eol"}, &isnap)
+				Expect(err).To(HaveOccurred())
+				Expect(ws.Status.Archived).To(BeFalse())
+			})
+
+			When("the instances not shut down have failed", func() {
+				BeforeEach(func() { instances[0].Status.Phase = v1alpha2.EnvironmentPhaseFailed })
+
+				It("Should not snapshot the failed instances", func() {
+					var isnap v1alpha2.InstanceSnapshot
+					err := cl.Get(ctx, client.ObjectKey{Namespace: "tenant-tester", Name: "running-vm-eol"}, &isnap)
+					Expect(err).To(HaveOccurred())
+				})
+
+				It("Should wait for the snapshots to be completed before unenrolling the users", func() {
+					var user v1alpha2.Tenant
+					Expect(cl.Get(ctx, client.ObjectKey{Name: "test-user"}, &user)).To(Succeed())
+					Expect(user.Spec.Workspaces).To(ConsistOf(v1alpha2.TenantWorkspaceEntry{Name: wsName, Role: v1alpha2.User}))
+					Expect(ws.Status.Archived).To(BeFalse())
+				})
+
+				When("the snapshots have been completed", func() {
+					var isnap *v1alpha2.InstanceSnapshot
+
+					BeforeEach(func() {
+						isnap = &v1alpha2.InstanceSnapshot{
+							ObjectMeta: metav1.ObjectMeta{Name: "stopped-vm-eol", Namespace: "tenant-tester"},
+							Status:     v1alpha2.InstanceSnapshotStatus{Phase: v1alpha2.Completed},
+						}
+						addObjToObjectsList(isnap)
+					})
+
+					AfterEach(func() { removeObjFromObjectsList(isnap) })
+
+					It("Should archive the workspace", func() {
+						Expect(ws.Status.Archived).To(BeTrue())
+					})
+
+					It("Should unenroll the users", func() {
+						var user v1alpha2.Tenant
+						Expect(cl.Get(ctx, client.ObjectKey{Name: "test-user"}, &user)).To(Succeed())
+						Expect(user.Spec.Workspaces).To(BeEmpty())
+					})
+				})
+			})
+
+			When("the instances have not been shut down in time", func() {
+				BeforeEach(func() {
+					wsResource.Spec.ActivePeriod.End = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+				})
+
+				It("Should not snapshot the instances not shut down", func() {
+					var isnap v1alpha2.InstanceSnapshot
+					err := cl.Get(ctx, client.ObjectKey{Namespace: "tenant-tester", Name: "running-vm-eol"}, &isnap)
+					Expect(err).To(HaveOccurred())
+				})
+
+				It("Should only wait for the snapshots of the instances shut down", func() {
+					Expect(ws.Status.Archived).To(BeFalse())
+					Expect(meta.FindStatusCondition(ws.Status.Conditions, string(v1alpha2.ConditionArchived)).Message).
+						To(ContainSubstring("Waiting for 1 end-of-life snapshots to be completed"))
+				})
+			})
+		})
+	})
+})
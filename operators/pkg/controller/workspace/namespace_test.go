@@ -21,6 +21,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
@@ -94,6 +95,19 @@ var _ = Describe("Namespace", func() {
 
 				Expect(ws.Status.Ready).To(BeFalse())
 			})
+
+			It("Should report the namespace enforcement failure in the workspace conditions", func() {
+				ws := &v1alpha1.Workspace{}
+
+				DoesEventuallyExists(ctx, cl, client.ObjectKey{Name: wsName}, ws, BeTrue(), timeout, interval)
+
+				for _, conditionType := range []v1alpha2.ConditionType{v1alpha2.ConditionNamespaceReady, v1alpha2.ConditionReady} {
+					condition := meta.FindStatusCondition(ws.Status.Conditions, string(conditionType))
+					Expect(condition).NotTo(BeNil())
+					Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+					Expect(condition.Reason).To(BeEquivalentTo(v1alpha2.ReasonEnforcementFailed))
+				}
+			})
 		})
 	})
 
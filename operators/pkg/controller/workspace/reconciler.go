@@ -23,6 +23,7 @@ import (
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -38,10 +39,10 @@ import (
 // Reconciler reconciles Workspace objects.
 type Reconciler struct {
 	client.Client
-	Scheme        *runtime.Scheme
-	TargetLabel   common.KVLabel
-	KeycloakActor common.KeycloakActorIface
-	Reschedule    common.Rescheduler
+	Scheme           *runtime.Scheme
+	TargetLabel      common.KVLabel
+	IdentityProvider common.IdentityProviderIface
+	Reschedule       common.Rescheduler
 }
 
 // Reconcile reconciles the state of a Workspace resource.
@@ -113,34 +114,69 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	err := r.enforceSubresources(ctx, log, &ws)
 	if err != nil {
 		log.Error(err, "Error enforcing subresources for workspace")
+		utils.SetCondition(&ws.Status.Conditions, &ws, v1alpha2.ConditionNamespaceReady, metav1.ConditionFalse,
+			v1alpha2.ReasonEnforcementFailed, err.Error())
+		setWorkspaceReadyCondition(&ws, v1alpha2.ReasonEnforcementFailed, err)
 		return reschedule, fmt.Errorf("error enforcing subresources for workspace %s: %w", ws.Name, err)
 	}
 
+	utils.SetCondition(&ws.Status.Conditions, &ws, v1alpha2.ConditionNamespaceReady, metav1.ConditionTrue,
+		v1alpha2.ReasonSucceeded, fmt.Sprintf("Namespace %s enforced", ws.Status.Namespace.Name))
+
 	// enforce AutoEnrollment for the Workspace
 	err = r.enforceAutoEnrollment(ctx, &ws, log)
 	if err != nil {
 		log.Error(err, "Error enforcing AutoEnrollment for workspace")
+		setWorkspaceReadyCondition(&ws, v1alpha2.ReasonEnforcementFailed, err)
 		return reschedule, fmt.Errorf("error enforcing AutoEnrollment for workspace %s: %w", ws.Name, err)
 	}
 	log.Info("AutoEnrollment enforced for workspace")
 
+	// enforce the end-of-life policy for the Workspace
+	requeueAfter, err := r.enforceLifecycle(ctx, &ws, log)
+	if err != nil {
+		log.Error(err, "Error enforcing lifecycle for workspace")
+		setWorkspaceReadyCondition(&ws, v1alpha2.ReasonEnforcementFailed, err)
+		return reschedule, fmt.Errorf("error enforcing lifecycle for workspace %s: %w", ws.Name, err)
+	}
+	if requeueAfter > 0 && (reschedule.RequeueAfter == 0 || requeueAfter < reschedule.RequeueAfter) {
+		reschedule.RequeueAfter = requeueAfter
+	}
+
 	if ws.Status.Subscriptions == nil {
 		ws.Status.Subscriptions = make(map[string]v1alpha2.SubscriptionStatus)
 	}
 
 	// setup roles in Keycloak
-	if err := r.createKeycloakRoles(ctx, &ws, log); err != nil {
-		log.Error(err, "Error managing Keycloak roles for workspace")
+	var keycloakErr error
+	if keycloakErr = r.createKeycloakRoles(ctx, &ws, log); keycloakErr != nil {
+		log.Error(keycloakErr, "Error managing Keycloak roles for workspace")
+		utils.SetCondition(&ws.Status.Conditions, &ws, v1alpha2.ConditionKeycloakSynchronized, metav1.ConditionFalse,
+			v1alpha2.ReasonKeycloakUnreachable, keycloakErr.Error())
 		hasErrors = true
 	} else {
 		log.Info("Keycloak roles updated/created for workspace")
+		utils.SetCondition(&ws.Status.Conditions, &ws, v1alpha2.ConditionKeycloakSynchronized, metav1.ConditionTrue,
+			v1alpha2.ReasonSucceeded, "Workspace roles synchronized with Keycloak")
 	}
 
 	ws.Status.Ready = !hasErrors
+	setWorkspaceReadyCondition(&ws, v1alpha2.ReasonKeycloakUnreachable, keycloakErr)
 
 	return reschedule, nil
 }
 
+// setWorkspaceReadyCondition configures the Ready condition of the Workspace, reporting the given reason
+// in case an error prevented it from being ready.
+func setWorkspaceReadyCondition(ws *v1alpha1.Workspace, reason v1alpha2.ConditionReason, err error) {
+	if err != nil {
+		utils.SetCondition(&ws.Status.Conditions, ws, v1alpha2.ConditionReady, metav1.ConditionFalse, reason, err.Error())
+		return
+	}
+	utils.SetCondition(&ws.Status.Conditions, ws, v1alpha2.ConditionReady, metav1.ConditionTrue,
+		v1alpha2.ReasonAvailable, "Workspace correctly configured")
+}
+
 // SetupWithManager registers a new controller for Workspace resources.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, log logr.Logger) error {
 	pred, err := r.TargetLabel.GetPredicate()
@@ -110,12 +110,17 @@ var _ = Describe("RoleBinding", func() {
 			Expect(rb.RoleRef.Name).To(Equal("crownlabs-view-templates"))
 			Expect(rb.RoleRef.Kind).To(Equal("ClusterRole"))
 			Expect(rb.RoleRef.APIGroup).To(Equal("rbac.authorization.k8s.io"))
-			Expect(rb.Subjects).To(HaveLen(1))
+			Expect(rb.Subjects).To(HaveLen(2))
 			Expect(rb.Subjects).To(ContainElement(rbacv1.Subject{
 				Kind:     "Group",
 				Name:     "kubernetes:workspace-" + wsName + ":user",
 				APIGroup: "rbac.authorization.k8s.io",
 			}))
+			Expect(rb.Subjects).To(ContainElement(rbacv1.Subject{
+				Kind:     "Group",
+				Name:     "kubernetes:workspace-" + wsName + ":assistant",
+				APIGroup: "rbac.authorization.k8s.io",
+			}))
 		})
 
 		It("Should create a rolebinding for the workspace managers to manage templates", func() {
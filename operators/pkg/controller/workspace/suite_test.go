@@ -103,10 +103,10 @@ var _ = JustBeforeEach(func() {
 	cl = builder.WithObjects(objects...).WithStatusSubresource(objects...).Build()
 
 	workspaceReconciler = workspace.Reconciler{
-		Client:        cl,
-		Scheme:        scheme.Scheme,
-		KeycloakActor: keycloakActor,
-		TargetLabel:   common.NewLabel("crownlabs.polito.it/operator-selector", "test"),
+		Client:           cl,
+		Scheme:           scheme.Scheme,
+		IdentityProvider: common.NewKeycloakIdentityProvider(keycloakActor),
+		TargetLabel:      common.NewLabel("crownlabs.polito.it/operator-selector", "test"),
 	}
 
 	_, err := workspaceReconciler.Reconcile(ctx, ctrl.Request{
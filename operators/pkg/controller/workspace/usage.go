@@ -0,0 +1,177 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/common"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+// UsageReconciler reports the resources consumed by the instances of each Workspace configuring an aggregate quota.
+// It is separate from the main Reconciler, to prevent triggering the interactions with Keycloak on every instance change.
+type UsageReconciler struct {
+	client.Client
+	TargetLabel common.KVLabel
+}
+
+// Reconcile updates the resource usage reported in the status of the Workspace.
+func (r *UsageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("workspace", req.Name)
+
+	var ws v1alpha1.Workspace
+	if err := r.Get(ctx, req.NamespacedName, &ws); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !r.TargetLabel.IsIncluded(ws.Labels) || !ws.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	original := ws.DeepCopy()
+	if ws.Spec.AggregateQuota == nil {
+		ws.Status.Usage = nil
+	} else {
+		usage, err := ComputeResourceUsage(ctx, r.Client, &ws, types.NamespacedName{})
+		if err != nil {
+			log.Error(err, "failed computing the workspace resource usage")
+			return ctrl.Result{}, err
+		}
+		ws.Status.Usage = &usage
+
+		if violations := forge.WorkspaceAggregateQuotaViolations(ws.Spec.AggregateQuota, &usage); len(violations) > 0 {
+			log.Info("workspace aggregate quota exceeded", "violations", violations)
+		}
+	}
+
+	if reflect.DeepEqual(original.Status.Usage, ws.Status.Usage) {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.Status().Patch(ctx, &ws, client.MergeFrom(original)); err != nil {
+		log.Error(err, "failed updating the workspace resource usage")
+		return ctrl.Result{}, err
+	}
+	log.Info("workspace resource usage updated")
+	return ctrl.Result{}, nil
+}
+
+// ComputeResourceUsage computes the resources currently consumed by the instances referring to the templates of the given workspace.
+// The instance identified by the exclude parameter (if any) is not taken into account, e.g., since it is being validated.
+func ComputeResourceUsage(ctx context.Context, c client.Reader, ws *v1alpha1.Workspace, exclude types.NamespacedName) (v1alpha1.WorkspaceResourceUsage, error) {
+	namespace := forge.GetWorkspaceNamespaceName(ws)
+
+	var templates v1alpha2.TemplateList
+	if err := c.List(ctx, &templates, client.InNamespace(namespace)); err != nil {
+		return v1alpha1.WorkspaceResourceUsage{}, fmt.Errorf("failed listing the templates of workspace %s: %w", ws.Name, err)
+	}
+
+	templatesMap := make(map[string]*v1alpha2.Template, len(templates.Items))
+	for i := range templates.Items {
+		templatesMap[templates.Items[i].Name] = &templates.Items[i]
+	}
+
+	instances, err := ListWorkspaceInstances(ctx, c, ws)
+	if err != nil {
+		return v1alpha1.WorkspaceResourceUsage{}, err
+	}
+
+	filtered := make([]v1alpha2.Instance, 0, len(instances))
+	for i := range instances {
+		if client.ObjectKeyFromObject(&instances[i]) != exclude {
+			filtered = append(filtered, instances[i])
+		}
+	}
+
+	return forge.WorkspaceResourceUsage(filtered, templatesMap), nil
+}
+
+// ListWorkspaceInstances returns the instances referring to the templates of the given workspace.
+func ListWorkspaceInstances(ctx context.Context, c client.Reader, ws *v1alpha1.Workspace) ([]v1alpha2.Instance, error) {
+	namespace := forge.GetWorkspaceNamespaceName(ws)
+
+	// Instances live in the namespaces of the tenants, hence they cannot be selected by namespace.
+	var instances v1alpha2.InstanceList
+	if err := c.List(ctx, &instances); err != nil {
+		return nil, fmt.Errorf("failed listing the instances: %w", err)
+	}
+
+	filtered := make([]v1alpha2.Instance, 0, len(instances.Items))
+	for i := range instances.Items {
+		if instances.Items[i].Spec.Template.Namespace == namespace {
+			filtered = append(filtered, instances.Items[i])
+		}
+	}
+	return filtered, nil
+}
+
+// instanceToWorkspace maps an Instance to the reconcile request for the Workspace it belongs to.
+func instanceToWorkspace(_ context.Context, obj client.Object) []reconcile.Request {
+	instance, ok := obj.(*v1alpha2.Instance)
+	if !ok {
+		return nil
+	}
+
+	if workspace := forge.InstanceWorkspaceName(instance); workspace != "" {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: workspace}}}
+	}
+	return nil
+}
+
+// instanceUsageChangedPredicate filters the Instance updates which may affect the resource usage of the workspace,
+// i.e., the configuration of the labels and the changes of the running state.
+func instanceUsageChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldInstance, oldOk := e.ObjectOld.(*v1alpha2.Instance)
+			newInstance, newOk := e.ObjectNew.(*v1alpha2.Instance)
+			return !oldOk || !newOk || oldInstance.Spec.Running != newInstance.Spec.Running ||
+				forge.InstanceWorkspaceName(oldInstance) != forge.InstanceWorkspaceName(newInstance)
+		},
+	}
+}
+
+// SetupWithManager registers a new controller reporting the resource usage of the Workspace resources.
+func (r *UsageReconciler) SetupWithManager(mgr ctrl.Manager, log logr.Logger) error {
+	pred, err := r.TargetLabel.GetPredicate()
+	if err != nil {
+		log.Error(err, "Error creating predicate for workspace usage controller")
+		return fmt.Errorf("error creating predicate for workspace usage controller: %w", err)
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("workspace-usage").
+		For(&v1alpha1.Workspace{}, builder.WithPredicates(pred, predicate.GenerationChangedPredicate{})).
+		Watches(&v1alpha2.Instance{}, handler.EnqueueRequestsFromMapFunc(instanceToWorkspace),
+			builder.WithPredicates(instanceUsageChangedPredicate())).
+		WithLogConstructor(utils.LogConstructor(mgr.GetLogger(), "WorkspaceUsage")).
+		Complete(r)
+}
@@ -0,0 +1,113 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/common"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/workspace"
+)
+
+var _ = Describe("Resource usage", func() {
+	var (
+		template  *v1alpha2.Template
+		instances []*v1alpha2.Instance
+		ws        v1alpha1.Workspace
+	)
+
+	forgeInstance := func(name, templateNamespace string, running bool) *v1alpha2.Instance {
+		return &v1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "tenant-tester"},
+			Spec: v1alpha2.InstanceSpec{
+				Template: v1alpha2.GenericRef{Name: "template", Namespace: templateNamespace},
+				Running:  running,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		template = &v1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "workspace-" + wsName},
+			Spec: v1alpha2.TemplateSpec{
+				WorkspaceRef: v1alpha2.GenericRef{Name: wsName},
+				EnvironmentList: []v1alpha2.Environment{{
+					Name:       "vm",
+					Persistent: true,
+					Resources: v1alpha2.EnvironmentResources{
+						CPU: 2, Memory: resource.MustParse("2Gi"), Disk: resource.MustParse("10Gi"),
+					},
+				}},
+			},
+		}
+		instances = []*v1alpha2.Instance{
+			forgeInstance("running", "workspace-"+wsName, true),
+			forgeInstance("stopped", "workspace-"+wsName, false),
+			forgeInstance("other", "workspace-other", true),
+		}
+
+		addObjToObjectsList(template)
+		for _, instance := range instances {
+			addObjToObjectsList(instance)
+		}
+	})
+
+	AfterEach(func() {
+		removeObjFromObjectsList(template)
+		for _, instance := range instances {
+			removeObjFromObjectsList(instance)
+		}
+	})
+
+	JustBeforeEach(func() {
+		reconciler := workspace.UsageReconciler{
+			Client:      cl,
+			TargetLabel: common.NewLabel("crownlabs.polito.it/operator-selector", "test"),
+		}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: wsName}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cl.Get(ctx, client.ObjectKey{Name: wsName}, &ws)).To(Succeed())
+	})
+
+	When("the aggregate quota is not configured", func() {
+		It("Should not report the usage", func() {
+			Expect(ws.Status.Usage).To(BeNil())
+		})
+	})
+
+	When("the aggregate quota is configured", func() {
+		BeforeEach(func() {
+			wsResource.Spec.AggregateQuota = &v1alpha1.WorkspaceAggregateQuota{Instances: ptr.To[int64](10)}
+		})
+
+		It("Should report the resources consumed by the instances of the workspace", func() {
+			Expect(ws.Status.Usage).ToNot(BeNil())
+			Expect(ws.Status.Usage.Instances).To(BeNumerically("==", 2))
+			Expect(ws.Status.Usage.CPU.Value()).To(BeNumerically("==", 2))
+			Expect(ws.Status.Usage.Memory.Equal(resource.MustParse("2Gi"))).To(BeTrue())
+			Expect(ws.Status.Usage.Storage.Equal(resource.MustParse("20Gi"))).To(BeTrue())
+		})
+	})
+})
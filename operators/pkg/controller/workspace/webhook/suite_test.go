@@ -0,0 +1,68 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+var (
+	scheme *runtime.Scheme
+	ctx    = context.Background()
+
+	bypassGroups = []string{"admins"}
+)
+
+func TestWorkspaceWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Workspace Webhook Suite")
+}
+
+var _ = BeforeSuite(func() {
+	scheme = runtime.NewScheme()
+	Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(v1alpha2.AddToScheme(scheme)).To(Succeed())
+})
+
+func serialize(obj client.Object) runtime.RawExtension {
+	data, err := json.Marshal(obj)
+	Expect(err).ToNot(HaveOccurred())
+	return runtime.RawExtension{Raw: data}
+}
+
+func forgeRequest(op admissionv1.Operation, newObj, oldObj client.Object) admission.Request {
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: op}}
+	if newObj != nil {
+		req.Object = serialize(newObj)
+		req.Name = newObj.GetName()
+		req.Namespace = newObj.GetNamespace()
+	}
+	if oldObj != nil {
+		req.OldObject = serialize(oldObj)
+	}
+	return req
+}
@@ -0,0 +1,170 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/workspace"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+// workspacesResource identifies the Workspace resources in the errors returned by the webhooks.
+var workspacesResource = schema.GroupResource{Group: v1alpha1.GroupVersion.Group, Resource: "workspaces"}
+
+// WorkspaceValidator implements a validating webhook for Workspace resources.
+type WorkspaceValidator struct {
+	admission.CustomValidator
+	WorkspaceWebhook
+}
+
+// ValidateCreate validates a new workspace creation request.
+func (wv *WorkspaceValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	ws, ok := obj.(*v1alpha1.Workspace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Workspace object, got %T", obj)
+	}
+
+	ctx, skip, err := wv.preflight(ctx, ws, "create")
+	if err != nil || skip {
+		return wv.overrideWarnings(skip), err
+	}
+
+	return nil, wv.CheckQuotaCaps(ctx, ws)
+}
+
+// ValidateUpdate validates a workspace update request.
+func (wv *WorkspaceValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldWs, ok := oldObj.(*v1alpha1.Workspace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Workspace object, got %T", oldObj)
+	}
+	ws, ok := newObj.(*v1alpha1.Workspace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Workspace object, got %T", newObj)
+	}
+
+	ctx, skip, err := wv.preflight(ctx, ws, "update")
+	if err != nil || skip {
+		return wv.overrideWarnings(skip), err
+	}
+
+	// Do not prevent other changes (e.g. to the finalizers) of workspaces already exceeding the caps.
+	if equality.Semantic.DeepEqual(oldWs.Spec.Quota, ws.Spec.Quota) {
+		ctrl.LoggerFrom(ctx).Info("allowed: quota unchanged")
+		return nil, nil
+	}
+
+	return nil, wv.CheckQuotaCaps(ctx, ws)
+}
+
+// ValidateDelete validates a workspace deletion request.
+func (wv *WorkspaceValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	ws, ok := obj.(*v1alpha1.Workspace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Workspace object, got %T", obj)
+	}
+
+	ctx, skip, err := wv.preflight(ctx, ws, "delete")
+	if err != nil || skip {
+		return wv.overrideWarnings(skip), err
+	}
+
+	return nil, wv.CheckDeletion(ctx, ws)
+}
+
+// preflight configures the logger and checks whether the request can skip the validation.
+func (wv *WorkspaceValidator) preflight(ctx context.Context, ws *v1alpha1.Workspace, op string) (context.Context, bool, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("workspace", ws.Name, "operation", op)
+	log.Info("processing admission request")
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return ctx, false, fmt.Errorf("failed to get admission request from context: %w", err)
+	}
+
+	if wv.CheckWebhookOverride(&req) {
+		log.Info("admitted: successful override")
+		return ctx, true, nil
+	}
+	return ctx, false, nil
+}
+
+func (wv *WorkspaceValidator) overrideWarnings(overridden bool) admission.Warnings {
+	if overridden {
+		return admission.Warnings{"webhook check overridden"}
+	}
+	return nil
+}
+
+// CheckQuotaCaps verifies that the quota granted by the workspace to each enrolled tenant does not exceed the caps enforced on tenants.
+func (wv *WorkspaceValidator) CheckQuotaCaps(ctx context.Context, ws *v1alpha1.Workspace) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if violations := forge.WorkspaceQuotaCapViolations(&ws.Spec.Quota); len(violations) > 0 {
+		log.Info("denied: workspace quota exceeding the caps", "violations", violations)
+		return errors.NewForbidden(workspacesResource, ws.Name,
+			fmt.Errorf("the quota of the workspace exceeds the maximum allowed for a tenant: %s", strings.Join(violations, ", ")))
+	}
+
+	log.Info("allowed")
+	return nil
+}
+
+// CheckDeletion verifies that the workspace can be deleted, that is no instances of its templates and no shared volumes exist,
+// unless the deletion is forced through the corresponding annotation.
+func (wv *WorkspaceValidator) CheckDeletion(ctx context.Context, ws *v1alpha1.Workspace) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if ws.GetAnnotations()[forge.WorkspaceForceDeleteAnnotation] == "true" {
+		log.Info("allowed: forced deletion")
+		return nil
+	}
+
+	instances, err := workspace.ListWorkspaceInstances(ctx, wv.Client, ws)
+	if err != nil {
+		log.Error(err, "failed listing the workspace instances")
+		return errors.NewInternalError(err)
+	}
+
+	var shvols v1alpha2.SharedVolumeList
+	if err := wv.Client.List(ctx, &shvols, client.InNamespace(forge.GetWorkspaceNamespaceName(ws))); err != nil {
+		log.Error(err, "failed listing the workspace shared volumes")
+		return errors.NewInternalError(fmt.Errorf("failed listing the shared volumes of workspace %s: %w", ws.Name, err))
+	}
+
+	if len(instances) > 0 || len(shvols.Items) > 0 {
+		log.Info("denied: workspace still in use", "instances", len(instances), "sharedvolumes", len(shvols.Items))
+		return errors.NewForbidden(workspacesResource, ws.Name,
+			fmt.Errorf("the workspace still contains %d instance(s) and %d shared volume(s), delete them first or set the %s=true annotation to force the deletion",
+				len(instances), len(shvols.Items), forge.WorkspaceForceDeleteAnnotation))
+	}
+
+	log.Info("allowed")
+	return nil
+}
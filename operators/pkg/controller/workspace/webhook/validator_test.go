@@ -0,0 +1,190 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/controller/workspace/webhook"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("Validator webhook", func() {
+	const (
+		workspaceName = "netgroup"
+		workspaceNs   = "workspace-netgroup"
+	)
+
+	var (
+		workspace *v1alpha1.Workspace
+		objects   []client.Object
+		request   admission.Request
+		response  admission.Response
+	)
+
+	BeforeEach(func() {
+		workspace = &v1alpha1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{Name: workspaceName},
+			Spec: v1alpha1.WorkspaceSpec{
+				PrettyName: "Netgroup",
+				Quota: v1alpha1.WorkspaceResourceQuota{
+					CPU:       resource.MustParse("10"),
+					Memory:    resource.MustParse("20G"),
+					Instances: 2,
+				},
+			},
+		}
+		objects = nil
+		forge.CapCPU, forge.CapMemoryGiga, forge.CapInstance = 25, 50, 5
+	})
+
+	AfterEach(func() {
+		forge.CapCPU, forge.CapMemoryGiga, forge.CapInstance = 0, 0, 0
+	})
+
+	JustBeforeEach(func() {
+		validator := admission.WithCustomValidator(scheme, &v1alpha1.Workspace{}, &webhook.WorkspaceValidator{
+			WorkspaceWebhook: webhook.WorkspaceWebhook{
+				Client:       fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+				BypassGroups: bypassGroups,
+			},
+		})
+		response = validator.Handle(ctx, request)
+	})
+
+	When("a workspace within the caps is created", func() {
+		BeforeEach(func() { request = forgeRequest(admissionv1.Create, workspace, nil) })
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	When("a workspace exceeding the caps is created", func() {
+		BeforeEach(func() {
+			workspace.Spec.Quota.Memory = resource.MustParse("64G")
+			request = forgeRequest(admissionv1.Create, workspace, nil)
+		})
+
+		It("Should deny the request, with a clear message", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+			Expect(response.Result.Message).To(ContainSubstring("memory (64G requested, 50G allowed)"))
+		})
+	})
+
+	When("a workspace is updated to exceed the caps", func() {
+		BeforeEach(func() {
+			updated := workspace.DeepCopy()
+			updated.Spec.Quota.Instances = 10
+			request = forgeRequest(admissionv1.Update, updated, workspace)
+		})
+
+		It("Should deny the request, with a clear message", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("instances (10 requested, 5 allowed)"))
+		})
+	})
+
+	When("the metadata of a workspace exceeding the caps are updated", func() {
+		BeforeEach(func() {
+			workspace.Spec.Quota.Instances = 10
+			updated := workspace.DeepCopy()
+			updated.SetFinalizers([]string{"crownlabs.polito.it/tenant-operator"})
+			request = forgeRequest(admissionv1.Update, updated, workspace)
+		})
+
+		It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+	})
+
+	When("a workspace exceeding the caps is created by a bypass group", func() {
+		BeforeEach(func() {
+			workspace.Spec.Quota.CPU = resource.MustParse("100")
+			request = forgeRequest(admissionv1.Create, workspace, nil)
+			request.UserInfo.Groups = bypassGroups
+		})
+
+		It("Should admit the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Warnings).To(ContainElement("webhook check overridden"))
+		})
+	})
+
+	Context("The workspace is deleted", func() {
+		BeforeEach(func() { request = forgeRequest(admissionv1.Delete, nil, workspace) })
+
+		When("it does not contain any resource", func() {
+			It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+		})
+
+		When("instances of its templates still exist", func() {
+			BeforeEach(func() {
+				objects = append(objects, &v1alpha2.Instance{
+					ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "tenant-tester"},
+					Spec:       v1alpha2.InstanceSpec{Template: v1alpha2.GenericRef{Name: "green-tea", Namespace: workspaceNs}},
+				})
+			})
+
+			It("Should deny the request, with a clear message", func() {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(response.Result.Code).To(BeNumerically("==", http.StatusForbidden))
+				Expect(response.Result.Message).To(ContainSubstring("still contains 1 instance(s) and 0 shared volume(s)"))
+			})
+		})
+
+		When("shared volumes still exist", func() {
+			BeforeEach(func() {
+				objects = append(objects, &v1alpha2.SharedVolume{ObjectMeta: metav1.ObjectMeta{Name: "shvol", Namespace: workspaceNs}})
+			})
+
+			It("Should deny the request", func() {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(response.Result.Message).To(ContainSubstring("0 instance(s) and 1 shared volume(s)"))
+			})
+
+			When("the force annotation is set", func() {
+				BeforeEach(func() {
+					workspace.SetAnnotations(map[string]string{forge.WorkspaceForceDeleteAnnotation: "true"})
+					request = forgeRequest(admissionv1.Delete, nil, workspace)
+				})
+
+				It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+			})
+		})
+
+		When("resources of other workspaces exist", func() {
+			BeforeEach(func() {
+				objects = append(objects,
+					&v1alpha2.Instance{
+						ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "tenant-tester"},
+						Spec:       v1alpha2.InstanceSpec{Template: v1alpha2.GenericRef{Name: "green-tea", Namespace: "workspace-other"}},
+					},
+					&v1alpha2.SharedVolume{ObjectMeta: metav1.ObjectMeta{Name: "shvol", Namespace: "workspace-other"}},
+				)
+			})
+
+			It("Should admit the request", func() { Expect(response.Allowed).To(BeTrue()) })
+		})
+	})
+})
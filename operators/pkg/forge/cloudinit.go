@@ -17,16 +17,56 @@ package forge
 import (
 	"bytes"
 	_ "embed"
+	"fmt"
+	"path"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	// SSHUserCAKeyPath -> the path where the public key of the SSH user certificate authority is stored in VMs.
+	SSHUserCAKeyPath = "/etc/ssh/crownlabs_user_ca.pub"
+	// SSHAuthorizedPrincipalsDir -> the directory containing the principals accepted for each user in VMs.
+	SSHAuthorizedPrincipalsDir = "/etc/ssh/auth_principals"
+	// SSHDConfigCAPath -> the path of the sshd configuration file enabling the SSH user certificate authority in VMs.
+	SSHDConfigCAPath = "/etc/ssh/sshd_config.d/10-crownlabs-ca.conf"
+
+	// cloudInitUser -> the name of the user configured in VMs through cloud-init.
+	cloudInitUser = "crownlabs"
+	// cloudInitUserID -> the UID and GID of the user configured in VMs through cloud-init.
+	cloudInitUserID = 1000
+	// nfsSubdirCreationPath -> the directory where the NFS volumes are temporarily mounted to create the subdirectories in VMs.
+	nfsSubdirCreationPath = "/run/crownlabs"
+)
+
 // userdata is a helper structure to marshal the userdata configuration.
 type userdata struct {
-	Users             []user     `yaml:"users"`
-	Network           network    `yaml:"network"`
-	Mounts            [][]string `yaml:"mounts"`
-	SSHAuthorizedKeys []string   `yaml:"ssh_authorized_keys,omitempty"`
+	Users             []user            `yaml:"users"`
+	Network           network           `yaml:"network"`
+	Mounts            [][]string        `yaml:"mounts"`
+	SSHAuthorizedKeys []string          `yaml:"ssh_authorized_keys,omitempty"`
+	SSHKeys           map[string]string `yaml:"ssh_keys,omitempty"`
+	BootCmd           [][]string        `yaml:"bootcmd,omitempty"`
+	WriteFiles        []writeFile       `yaml:"write_files,omitempty"`
+}
+
+// writeFile is a helper structure to marshal the userdata configuration to write arbitrary files.
+type writeFile struct {
+	Path        string `yaml:"path"`
+	Content     string `yaml:"content"`
+	Permissions string `yaml:"permissions"`
+}
+
+// TrustedUserCA describes the SSH user certificate authority trusted by VMs,
+// which replaces the installation of the public keys of the single users.
+type TrustedUserCA struct {
+	// PublicKey is the public key of the certificate authority, in authorized_keys format.
+	PublicKey string
+	// Principals are the principals which shall be listed in the certificates granting access to the VM.
+	Principals []string
+	// HostKey is the host key installed in the VM, which is verified when connecting through the bastion proxy.
+	HostKey *SSHHostKey
 }
 
 // user is a helper structure to marshal the userdata configuration to configure users.
@@ -59,11 +99,49 @@ func CloudInitUserScriptData() ([]byte, error) {
 	return userScriptData, nil
 }
 
+// NFSSubdirCreationCommand forges the command creating the subdirectory of the NFS volume to be mounted, in case it does not exist yet,
+// owned by the user configured in the VM. It is executed at boot time (i.e., before the configured mounts are processed), temporarily
+// mounting the entire exported path, which is then unmounted so that only the subdirectory remains accessible.
+func NFSSubdirCreationCommand(mountInfo NFSVolumeMountInfo) []string {
+	tmpPath := path.Join(nfsSubdirCreationPath, mountInfo.VolumeName)
+	subdir := path.Join(tmpPath, mountInfo.SubPath)
+	return []string{"sh", "-c", fmt.Sprintf(
+		`mkdir -p '%[1]s' && mount -t nfs -o rw,tcp '%[2]s:%[3]s' '%[1]s' && `+
+			`{ [ -d '%[4]s' ] || { mkdir -m %[5]s '%[4]s' && chown %[6]d:%[6]d '%[4]s'; }; umount '%[1]s'; }`,
+		tmpPath, mountInfo.ServerAddress, mountInfo.ExportPath, subdir, NFSSubdirMode, cloudInitUserID)}
+}
+
+// trustedUserCAFiles forges the files configuring sshd to trust the given certificate authority.
+// The files are written by cloud-init before sshd is started, hence no restart is required.
+func trustedUserCAFiles(trustedCA *TrustedUserCA) []writeFile {
+	return []writeFile{{
+		Path:        SSHUserCAKeyPath,
+		Content:     strings.TrimSpace(trustedCA.PublicKey) + "\n",
+		Permissions: "0644",
+	}, {
+		Path:        path.Join(SSHAuthorizedPrincipalsDir, cloudInitUser),
+		Content:     strings.Join(trustedCA.Principals, "\n") + "\n",
+		Permissions: "0644",
+	}, {
+		Path: SSHDConfigCAPath,
+		Content: fmt.Sprintf("TrustedUserCAKeys %s\nAuthorizedPrincipalsFile %s\n",
+			SSHUserCAKeyPath, path.Join(SSHAuthorizedPrincipalsDir, "%u")),
+		Permissions: "0644",
+	}}
+}
+
 // CloudInitUserData forges the yaml manifest representing the cloud-init userdata configuration.
-func CloudInitUserData(publicKeys []string, mountInfos []NFSVolumeMountInfo) ([]byte, error) {
+// The NFS volumes are mounted according to the given permissions, and only the subdirectory is mounted if specified.
+// In case a trusted user CA is specified, sshd is configured to accept the certificates it issues
+// for the given principals (as well as to use the given host key, if any), and the public keys are ignored.
+func CloudInitUserData(publicKeys []string, mountInfos []NFSVolumeMountInfo, trustedCA *TrustedUserCA) ([]byte, error) {
+	if trustedCA != nil {
+		publicKeys = nil
+	}
+
 	config := userdata{
 		Users: []user{{
-			Name:       "crownlabs",
+			Name:       cloudInitUser,
 			LockPasswd: false,
 			// The hash of the password ("crownlabs").
 			// You can generate this hash via: "mkpasswd --method=SHA-512 --rounds=4096".
@@ -81,10 +159,23 @@ func CloudInitUserData(publicKeys []string, mountInfos []NFSVolumeMountInfo) ([]
 
 	config.Mounts = [][]string{}
 	for _, mountInfo := range mountInfos {
-		config.Mounts = append(config.Mounts, NFSVolumeMount(mountInfo.ServerAddress, mountInfo.ExportPath, mountInfo.MountPath, mountInfo.ReadOnly))
+		config.Mounts = append(config.Mounts, NFSVolumeMount(mountInfo.ServerAddress, NFSMountExportPath(mountInfo), mountInfo.MountPath, mountInfo.ReadOnly))
+		if mountInfo.SubPath != "" {
+			config.BootCmd = append(config.BootCmd, NFSSubdirCreationCommand(mountInfo))
+		}
 	}
 	config.Mounts = append(config.Mounts, CommentMount("If you change mount options from here, not even Santa will give you 18."))
 
+	if trustedCA != nil {
+		config.WriteFiles = trustedUserCAFiles(trustedCA)
+		if trustedCA.HostKey != nil {
+			config.SSHKeys = map[string]string{
+				"ed25519_private": trustedCA.HostKey.PrivateKey,
+				"ed25519_public":  trustedCA.HostKey.PublicKey,
+			}
+		}
+	}
+
 	output, err := yaml.Marshal(config)
 	if err != nil {
 		return []byte{}, err
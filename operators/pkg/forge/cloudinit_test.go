@@ -93,13 +93,124 @@ ssh_authorized_keys:
 					MountPath:     nfsShVolMountPath,
 					ReadOnly:      nfsShVolReadOnly,
 				},
-			})
+			}, nil)
 		})
 
 		It("Should succeed", func() { Expect(err).ToNot(HaveOccurred()) })
 		It("Should match the expected output", func() { Expect(output).To(WithTransform(Transformer, Equal(Transformer([]byte(expected))))) })
 	})
 
+	Context("The CloudInitUserData function, in case a subdirectory of a shared volume is mounted", func() {
+		var (
+			mountInfo forge.NFSVolumeMountInfo
+			output    []byte
+			err       error
+		)
+
+		BeforeEach(func() {
+			mountInfo = forge.NFSVolumeMountInfo{
+				VolumeName:    "nfs0",
+				ServerAddress: "nfs.example.com",
+				ExportPath:    "/nfs/shvol",
+				MountPath:     "/mnt/path",
+				ReadOnly:      true,
+				SubPath:       "tester",
+			}
+		})
+
+		JustBeforeEach(func() {
+			output, err = forge.CloudInitUserData(nil, []forge.NFSVolumeMountInfo{mountInfo}, nil)
+		})
+
+		It("Should succeed", func() { Expect(err).ToNot(HaveOccurred()) })
+		It("Should mount only the subdirectory, with the given permissions", func() {
+			Expect(string(output)).To(ContainSubstring("- - nfs.example.com:/nfs/shvol/tester\n      - /mnt/path\n      - nfs\n      - ro,"))
+		})
+		It("Should create the subdirectory at boot time", func() {
+			Expect(string(output)).To(ContainSubstring("bootcmd:"))
+			Expect(forge.NFSSubdirCreationCommand(mountInfo)).To(Equal([]string{"sh", "-c",
+				"mkdir -p '/run/crownlabs/nfs0' && mount -t nfs -o rw,tcp 'nfs.example.com:/nfs/shvol' '/run/crownlabs/nfs0' && " +
+					"{ [ -d '/run/crownlabs/nfs0/tester' ] || { mkdir -m 0770 '/run/crownlabs/nfs0/tester' && chown 1000:1000 '/run/crownlabs/nfs0/tester'; }; " +
+					"umount '/run/crownlabs/nfs0'; }"}))
+		})
+	})
+
+	Context("The CloudInitUserData function, when the entire shared volume is mounted", func() {
+		var (
+			output []byte
+			err    error
+		)
+
+		JustBeforeEach(func() {
+			output, err = forge.CloudInitUserData(nil, []forge.NFSVolumeMountInfo{{
+				VolumeName: "nfs0", ServerAddress: "nfs.example.com", ExportPath: "/nfs/shvol", MountPath: "/mnt/path",
+			}}, nil)
+		})
+
+		It("Should succeed", func() { Expect(err).ToNot(HaveOccurred()) })
+		It("Should mount the entire volume", func() { Expect(string(output)).To(ContainSubstring("- - nfs.example.com:/nfs/shvol\n")) })
+		It("Should not configure any boot command", func() { Expect(string(output)).ToNot(ContainSubstring("bootcmd:")) })
+	})
+
+	Context("The CloudInitUserData function, in case a trusted user CA is configured", func() {
+		const expected = `
+write_files:
+    - path: /etc/ssh/crownlabs_user_ca.pub
+      content: |
+        ssh-ed25519 ca-key
+      permissions: "0644"
+    - path: /etc/ssh/auth_principals/crownlabs
+      content: |
+        instance:tenant-tester/instance
+      permissions: "0644"
+    - path: /etc/ssh/sshd_config.d/10-crownlabs-ca.conf
+      content: |
+        TrustedUserCAKeys /etc/ssh/crownlabs_user_ca.pub
+        AuthorizedPrincipalsFile /etc/ssh/auth_principals/%u
+      permissions: "0644"
+`
+
+		var (
+			output []byte
+			err    error
+		)
+
+		JustBeforeEach(func() {
+			output, err = forge.CloudInitUserData([]string{"tenant-key-1"}, nil, &forge.TrustedUserCA{
+				PublicKey:  "ssh-ed25519 ca-key\n",
+				Principals: []string{"instance:tenant-tester/instance"},
+			})
+		})
+
+		It("Should succeed", func() { Expect(err).ToNot(HaveOccurred()) })
+		It("Should not install the public keys", func() { Expect(string(output)).ToNot(ContainSubstring("tenant-key-1")) })
+		It("Should configure sshd to trust the CA", func() { Expect(string(output)).To(HaveSuffix(expected[1:])) })
+	})
+
+	Context("The CloudInitUserData function, in case a host key is configured", func() {
+		const expected = `
+ssh_keys:
+    ed25519_private: private-key
+    ed25519_public: ssh-ed25519 host-key
+`
+
+		var (
+			output []byte
+			err    error
+		)
+
+		JustBeforeEach(func() {
+			output, err = forge.CloudInitUserData(nil, nil, &forge.TrustedUserCA{
+				PublicKey:  "ssh-ed25519 ca-key",
+				Principals: []string{"instance:tenant-tester/instance"},
+				HostKey:    &forge.SSHHostKey{PrivateKey: "private-key", PublicKey: "ssh-ed25519 host-key"},
+			})
+		})
+
+		It("Should succeed", func() { Expect(err).ToNot(HaveOccurred()) })
+		It("Should install the host key", func() { Expect(string(output)).To(ContainSubstring(expected[1:])) })
+	})
+
 	Context("The CloudInitUserScriptData function", func() {
 		const expected = `#!/bin/bash
 mkdir -p "/media/mydrive"
@@ -18,6 +18,7 @@ package forge
 
 import (
 	"fmt"
+	"path"
 	"strconv"
 	"strings"
 
@@ -43,6 +44,14 @@ const (
 	ContentDownloaderName = "content-downloader"
 	// ContentUploaderName -> name of the uploader initcontainer.
 	ContentUploaderName = "content-uploader"
+	// ContentSeederName -> name of the initcontainer copying the content of the image into the persistent volume.
+	ContentSeederName = "content-seeder"
+	// ContentSeederMountPath -> path the persistent volume is mounted on in the content seeder initcontainer.
+	ContentSeederMountPath = "/media/crownlabs-seed"
+	// SubdirsCreatorName -> name of the initcontainer creating the shared volume subdirectories to be mounted.
+	SubdirsCreatorName = "subdirs-creator"
+	// SubdirsCreatorMountPath -> path the shared volumes are mounted on in the subdirectories creator initcontainer.
+	SubdirsCreatorMountPath = "/media/crownlabs-shvols"
 	// PersistentDefaultMountPath -> default path for the container's pvc or persistent storage.
 	PersistentDefaultMountPath = "/media/data"
 	// HealthzEndpoint -> default endpoint for HTTP probes.
@@ -149,13 +158,16 @@ func ReplicasCount(instance *clv1alpha2.Instance, environment *clv1alpha2.Enviro
 // containing the needed sidecars for X-VNC based container instances.
 func DeploymentSpec(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment, mountInfos []NFSVolumeMountInfo, opts *ContainerEnvOpts) appsv1.DeploymentSpec {
 	return appsv1.DeploymentSpec{
-		Selector: &metav1.LabelSelector{MatchLabels: InstanceSelectorLabels(instance)},
+		Selector: &metav1.LabelSelector{MatchLabels: EnvironmentSelectorLabels(instance, environment)},
 		Strategy: appsv1.DeploymentStrategy{
 			Type: appsv1.RecreateDeploymentStrategyType,
 		},
 		Template: corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: InstanceSelectorLabels(instance)},
-			Spec:       PodSpec(instance, environment, mountInfos, opts),
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      EnvironmentSelectorLabels(instance, environment),
+				Annotations: PrivateNetworkPodAnnotations(instance),
+			},
+			Spec: PodSpec(instance, environment, mountInfos, opts),
 		},
 	}
 }
@@ -168,7 +180,7 @@ func PodSpec(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment,
 		SecurityContext:               PodSecurityContext(),
 		AutomountServiceAccountToken:  ptr.To(false),
 		TerminationGracePeriodSeconds: ptr.To[int64](containersTerminationGracePeriod),
-		InitContainers:                InitContainers(instance, environment, opts),
+		InitContainers:                InitContainers(instance, environment, mountInfos, opts),
 		EnableServiceLinks:            ptr.To(false),
 		Hostname:                      InstanceHostname(environment),
 		NodeSelector:                  NodeSelectorLabels(instance, environment),
@@ -183,7 +195,7 @@ func SubmissionJobSpec(instance *clv1alpha2.Instance, environment *clv1alpha2.En
 		Template: corev1.PodTemplateSpec{
 			Spec: corev1.PodSpec{
 				Containers: []corev1.Container{
-					ContentUploaderJobContainer(instance.Spec.CustomizationUrls.ContentDestination, instance.Name, opts),
+					ContentUploaderJobContainer(instance.Spec.CustomizationUrls.ContentDestination, SubmissionFileName(instance, environment), opts),
 				},
 				Volumes:                      ContainerVolumes(instance, environment, nil),
 				SecurityContext:              PodSecurityContext(),
@@ -194,6 +206,14 @@ func SubmissionJobSpec(instance *clv1alpha2.Instance, environment *clv1alpha2.En
 	}
 }
 
+// SubmissionFileName returns the name of the file containing the content submitted for the given environment of the instance.
+func SubmissionFileName(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) string {
+	if IsMultiEnvironment(instance) {
+		return instance.Name + StringSeparator + environment.Name
+	}
+	return instance.Name
+}
+
 // ContainersSpec returns the Containers obj based on Environment Type.
 func ContainersSpec(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment, mountInfos []NFSVolumeMountInfo, opts *ContainerEnvOpts) []corev1.Container {
 	var containers []corev1.Container
@@ -219,7 +239,7 @@ func WebsockifyContainer(opts *ContainerEnvOpts, environment *clv1alpha2.Environ
 	AddTCPPortToContainer(&websockifyContainer, GUIPortName, GUIPortNumber)
 	AddTCPPortToContainer(&websockifyContainer, MetricsPortName, MetricsPortNumber)
 	AddContainerArg(&websockifyContainer, "http-addr", fmt.Sprintf(":%d", GUIPortNumber))
-	AddContainerArg(&websockifyContainer, "base-path", IngressGUICleanPath(instance))
+	AddContainerArg(&websockifyContainer, "base-path", IngressGUICleanPath(instance, environment))
 	AddContainerArg(&websockifyContainer, "metrics-addr", fmt.Sprintf(":%d", MetricsPortNumber))
 	AddContainerArg(&websockifyContainer, "show-controls", fmt.Sprint(!environment.DisableControls))
 	AddContainerArg(&websockifyContainer, "instmetrics-server-endpoint", opts.InstMetricsEndpoint)
@@ -244,7 +264,7 @@ func StandaloneContainer(instance *clv1alpha2.Instance, environment *clv1alpha2.
 	standaloneContainer := AppContainer(environment, volumeMountPath, mountInfos)
 	AddTCPPortToContainer(&standaloneContainer, GUIPortName, GUIPortNumber)
 
-	AddEnvVariableToContainer(&standaloneContainer, "CROWNLABS_BASE_PATH", IngressGUICleanPath(instance))
+	AddEnvVariableToContainer(&standaloneContainer, "CROWNLABS_BASE_PATH", IngressGUICleanPath(instance, environment))
 	AddEnvVariableToContainer(&standaloneContainer, "CROWNLABS_LISTEN_PORT", strconv.Itoa(GUIPortNumber))
 
 	if environment.RewriteURL {
@@ -264,7 +284,7 @@ func AppContainer(environment *clv1alpha2.Environment, volumeMountPath string, m
 	AddEnvVariableFromResourcesToContainer(&appContainer, "CROWNLABS_CPU_LIMITS", appContainer.Name, corev1.ResourceLimitsCPU, DefaultDivisor)
 	AddContainerVolumeMount(&appContainer, PersistentVolumeName, volumeMountPath)
 	for _, mountInfo := range mountInfos {
-		AddContainerVolumeMount(&appContainer, mountInfo.VolumeName, mountInfo.MountPath)
+		AddContainerNFSVolumeMount(&appContainer, mountInfo)
 	}
 	if environment.ContainerStartupOptions != nil {
 		appContainer.Args = environment.ContainerStartupOptions.StartupArgs
@@ -276,11 +296,49 @@ func AppContainer(environment *clv1alpha2.Environment, volumeMountPath string, m
 }
 
 // InitContainers forges the list of initcontainers for the container based environment.
-func InitContainers(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment, opts *ContainerEnvOpts) []corev1.Container {
+func InitContainers(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment, mountInfos []NFSVolumeMountInfo, opts *ContainerEnvOpts) []corev1.Container {
+	var containers []corev1.Container
+	if subdirsCreator, needed := SubdirsCreatorInitContainer(mountInfos); needed {
+		containers = append(containers, subdirsCreator)
+	}
+	if cso := environment.ContainerStartupOptions; cso != nil && cso.InitializeFromImage {
+		containers = append(containers, ContentSeederInitContainer(environment))
+	}
 	if check, origin := NeedsInitContainer(instance, environment); check {
-		return []corev1.Container{ContentDownloaderInitContainer(origin, opts)}
+		containers = append(containers, ContentDownloaderInitContainer(origin, opts))
 	}
-	return nil
+	return containers
+}
+
+// ContentSeederInitContainer forges a Container to be used as initContainer for copying the content the environment image
+// provides at the persistent mount path into the <PersistentVolumeName> volume, which would otherwise hide it. The copy is
+// performed only if the volume is empty (except for the lost+found directory), to preserve the modifications performed in persistent environments.
+func ContentSeederInitContainer(environment *clv1alpha2.Environment) corev1.Container {
+	contentSeeder := GenericContainer(ContentSeederName, environment.Image)
+	SetContainerResources(&contentSeeder, 0.5, 1, 256, 1024)
+	AddContainerVolumeMount(&contentSeeder, PersistentVolumeName, ContentSeederMountPath)
+	AddEnvVariableToContainer(&contentSeeder, "SOURCE_PATH", PersistentMountPath(environment))
+	AddEnvVariableToContainer(&contentSeeder, "DESTINATION_PATH", ContentSeederMountPath)
+	contentSeeder.Command = []string{"/bin/sh", "-c",
+		`if [ -d "$SOURCE_PATH" ] && [ -z "$(ls -A "$DESTINATION_PATH" | grep -vx lost+found)" ]; then cp -a "$SOURCE_PATH/." "$DESTINATION_PATH/"; fi`}
+	return contentSeeder
+}
+
+// SubdirsCreatorInitContainer forges a Container to be used as initContainer for creating the subdirectories of the NFS volumes
+// to be mounted in the application container (with NFSSubdirMode permissions), in case they do not exist yet. It returns false if no subdirectory is mounted.
+func SubdirsCreatorInitContainer(mountInfos []NFSVolumeMountInfo) (corev1.Container, bool) {
+	subdirsCreator := GenericContainer(SubdirsCreatorName, ProvisionJobBaseImage)
+	SetContainerResources(&subdirsCreator, 0.1, 0.1, 32, 32)
+	subdirsCreator.Command = []string{"mkdir", "-p", "-m", NFSSubdirMode}
+	for _, mountInfo := range mountInfos {
+		if mountInfo.SubPath == "" {
+			continue
+		}
+		mountPath := path.Join(SubdirsCreatorMountPath, mountInfo.VolumeName)
+		AddContainerVolumeMount(&subdirsCreator, mountInfo.VolumeName, mountPath)
+		subdirsCreator.Command = append(subdirsCreator.Command, path.Join(mountPath, mountInfo.SubPath))
+	}
+	return subdirsCreator, len(subdirsCreator.VolumeMounts) > 0
 }
 
 // ContentDownloaderInitContainer forges a Container to be used as initContainer for downloading and decompressing an archive file into the <MyDriveName> volume.
@@ -378,6 +436,17 @@ func AddContainerVolumeMount(c *corev1.Container, name, path string) {
 	})
 }
 
+// AddContainerNFSVolumeMount appends a VolumeMount to the given container's VolumeMounts, given the NFS volume specification.
+// In case a subdirectory is mounted, the permissions are enforced at the mount level, since the volume itself needs to be
+// writable to create the subdirectory.
+func AddContainerNFSVolumeMount(c *corev1.Container, mountInfo NFSVolumeMountInfo) {
+	AddContainerVolumeMount(c, mountInfo.VolumeName, mountInfo.MountPath)
+	if mountInfo.SubPath != "" {
+		c.VolumeMounts[len(c.VolumeMounts)-1].SubPath = mountInfo.SubPath
+		c.VolumeMounts[len(c.VolumeMounts)-1].ReadOnly = mountInfo.ReadOnly
+	}
+}
+
 // AddContainerArg appends an argument to the given container's args in the format of --name=value.
 func AddContainerArg(c *corev1.Container, name, value string) {
 	c.Args = append(c.Args, fmt.Sprintf("--%s=%s", name, value))
@@ -444,7 +513,7 @@ func SetContainerResourcesFromEnvironment(c *corev1.Container, env *clv1alpha2.E
 // ContainerVolumes forges the list of volumes for the deployment spec, possibly returning an empty
 // list in case the environment is not standard and not persistent.
 func ContainerVolumes(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment, mountInfos []NFSVolumeMountInfo) []corev1.Volume {
-	vols := []corev1.Volume{ContainerVolume(PersistentVolumeName, NamespacedName(instance).Name, environment)}
+	vols := []corev1.Volume{ContainerVolume(PersistentVolumeName, EnvironmentNamespacedName(instance, environment).Name, environment)}
 
 	for _, mountInfo := range mountInfos {
 		vols = append(vols, NFSVolume(mountInfo))
@@ -483,7 +552,7 @@ func NFSVolume(mountInfo NFSVolumeMountInfo) corev1.Volume {
 			NFS: &corev1.NFSVolumeSource{
 				Server:   mountInfo.ServerAddress,
 				Path:     mountInfo.ExportPath,
-				ReadOnly: mountInfo.ReadOnly,
+				ReadOnly: mountInfo.ReadOnly && mountInfo.SubPath == "",
 			},
 		},
 	}
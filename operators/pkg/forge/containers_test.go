@@ -376,7 +376,7 @@ var _ = Describe("Containers and Deployment spec forging", func() {
 
 		It("Should set the env variables", func() {
 			expected.Name = envName
-			forge.AddEnvVariableToContainer(&expected, "CROWNLABS_BASE_PATH", forge.IngressGUICleanPath(&instance))
+			forge.AddEnvVariableToContainer(&expected, "CROWNLABS_BASE_PATH", forge.IngressGUICleanPath(&instance, &environment))
 			forge.AddEnvVariableToContainer(&expected, "CROWNLABS_LISTEN_PORT", "6080")
 			forge.AddEnvVariableFromResourcesToContainer(&expected, "CROWNLABS_CPU_REQUESTS", expected.Name, corev1.ResourceRequestsCPU, forge.DefaultDivisor)
 			forge.AddEnvVariableFromResourcesToContainer(&expected, "CROWNLABS_CPU_LIMITS", expected.Name, corev1.ResourceLimitsCPU, forge.DefaultDivisor)
@@ -444,7 +444,7 @@ var _ = Describe("Containers and Deployment spec forging", func() {
 			It("Should set the correct arguments", func() {
 				Expect(actual.Args).To(ConsistOf([]string{
 					fmt.Sprintf("--http-addr=:%d", forge.GUIPortNumber),
-					fmt.Sprintf("--base-path=%s", forge.IngressGUICleanPath(&instance)),
+					fmt.Sprintf("--base-path=%s", forge.IngressGUICleanPath(&instance, &environment)),
 					fmt.Sprintf("--metrics-addr=:%d", forge.MetricsPortNumber),
 					fmt.Sprintf("--show-controls=%v", !environment.DisableControls),
 					fmt.Sprintf("--instmetrics-server-endpoint=%s", opts.InstMetricsEndpoint),
@@ -463,7 +463,7 @@ var _ = Describe("Containers and Deployment spec forging", func() {
 			It("Should set the correct arguments", func() {
 				Expect(actual.Args).To(ConsistOf([]string{
 					fmt.Sprintf("--http-addr=:%d", forge.GUIPortNumber),
-					fmt.Sprintf("--base-path=%s", forge.IngressGUICleanPath(&instance)),
+					fmt.Sprintf("--base-path=%s", forge.IngressGUICleanPath(&instance, &environment)),
 					fmt.Sprintf("--metrics-addr=:%d", forge.MetricsPortNumber),
 					fmt.Sprintf("--show-controls=%v", !environment.DisableControls),
 					fmt.Sprintf("--instmetrics-server-endpoint=%s", opts.InstMetricsEndpoint),
@@ -685,7 +685,7 @@ var _ = Describe("Containers and Deployment spec forging", func() {
 		var actual []corev1.Container
 
 		JustBeforeEach(func() {
-			actual = forge.InitContainers(&instance, &environment, &opts)
+			actual = forge.InitContainers(&instance, &environment, mountInfos, &opts)
 		})
 
 		type InitContainersCase struct {
@@ -724,6 +724,114 @@ var _ = Describe("Containers and Deployment spec forging", func() {
 				return []corev1.Container{forge.ContentDownloaderInitContainer(val, &opts)}
 			},
 		}))
+		When("the initialization from the image is requested", WhenBody(InitContainersCase{
+			StartupOpts: &clv1alpha2.ContainerStartupOpts{InitializeFromImage: true},
+			ExpectedOutput: func(_ *clv1alpha2.Instance, e *clv1alpha2.Environment) []corev1.Container {
+				return []corev1.Container{forge.ContentSeederInitContainer(e)}
+			},
+		}))
+		When("both the initialization from the image and an archive source are specified", WhenBody(InitContainersCase{
+			StartupOpts: &clv1alpha2.ContainerStartupOpts{InitializeFromImage: true, SourceArchiveURL: httpPath},
+			ExpectedOutput: func(i *clv1alpha2.Instance, e *clv1alpha2.Environment) []corev1.Container {
+				_, val := forge.NeedsInitContainer(i, e)
+				return []corev1.Container{forge.ContentSeederInitContainer(e), forge.ContentDownloaderInitContainer(val, &opts)}
+			},
+		}))
+
+		When("a subdirectory of a shared volume is mounted", func() {
+			BeforeEach(func() { mountInfos[1].SubPath = "tester" })
+
+			It("Should include the subdirectories creator", func() {
+				creator, _ := forge.SubdirsCreatorInitContainer(mountInfos)
+				Expect(actual).To(Equal([]corev1.Container{creator}))
+			})
+		})
+	})
+
+	Describe("The forge.SubdirsCreatorInitContainer function", func() {
+		var (
+			actual corev1.Container
+			needed bool
+		)
+
+		JustBeforeEach(func() {
+			actual, needed = forge.SubdirsCreatorInitContainer(mountInfos)
+		})
+
+		When("no subdirectory is mounted", func() {
+			It("Should not be needed", func() { Expect(needed).To(BeFalse()) })
+		})
+
+		When("a subdirectory is mounted", func() {
+			BeforeEach(func() { mountInfos[1].SubPath = "tester" })
+
+			It("Should be needed", func() { Expect(needed).To(BeTrue()) })
+			It("Should mount only the volumes whose subdirectory is mounted", func() {
+				Expect(actual.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: nfsShVolName, MountPath: "/media/crownlabs-shvols/nfs0"}))
+			})
+			It("Should create the subdirectories", func() {
+				Expect(actual.Command).To(Equal([]string{"mkdir", "-p", "-m", forge.NFSSubdirMode, "/media/crownlabs-shvols/nfs0/tester"}))
+			})
+		})
+	})
+
+	Describe("The forge.AddContainerNFSVolumeMount function", func() {
+		var actual corev1.Container
+
+		JustBeforeEach(func() {
+			actual = corev1.Container{}
+			forge.AddContainerNFSVolumeMount(&actual, mountInfoShVol)
+		})
+
+		When("the entire volume is mounted", func() {
+			It("Should leave the permissions to the volume", func() {
+				Expect(actual.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: nfsShVolName, MountPath: nfsShVolMountPath}))
+			})
+		})
+
+		When("a subdirectory is mounted", func() {
+			BeforeEach(func() { mountInfoShVol.SubPath = "tester" })
+			AfterEach(func() { mountInfoShVol.SubPath = "" })
+
+			It("Should mount the subdirectory, enforcing the permissions", func() {
+				Expect(actual.VolumeMounts).To(ConsistOf(corev1.VolumeMount{
+					Name: nfsShVolName, MountPath: nfsShVolMountPath, SubPath: "tester", ReadOnly: nfsShVolReadOnly,
+				}))
+			})
+			It("Should mount the volume with write permissions, to allow creating the subdirectory", func() {
+				Expect(forge.NFSVolume(mountInfoShVol).NFS.ReadOnly).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("The forge.ContentSeederInitContainer function forges the initContainer for volume initialization from the image", func() {
+		var actual, expected corev1.Container
+
+		BeforeEach(func() {
+			environment.ContainerStartupOptions = &clv1alpha2.ContainerStartupOpts{ContentPath: "/home/user/workspace", InitializeFromImage: true}
+		})
+
+		JustBeforeEach(func() {
+			actual = forge.ContentSeederInitContainer(&environment)
+		})
+
+		It("Should set the correct container name and image", func() {
+			Expect(actual.Name).To(Equal(forge.ContentSeederName))
+			Expect(actual.Image).To(Equal(environment.Image))
+		})
+		It("Should set the volume mount", func() {
+			forge.AddContainerVolumeMount(&expected, forge.PersistentVolumeName, forge.ContentSeederMountPath)
+			Expect(actual.VolumeMounts).To(Equal(expected.VolumeMounts))
+		})
+		It("Should set the correct environment variables", func() {
+			forge.AddEnvVariableToContainer(&expected, "SOURCE_PATH", "/home/user/workspace")
+			forge.AddEnvVariableToContainer(&expected, "DESTINATION_PATH", forge.ContentSeederMountPath)
+			Expect(actual.Env).To(ConsistOf(expected.Env))
+		})
+		It("Should copy the content only if the volume is empty", func() {
+			Expect(actual.Command).To(HaveLen(3))
+			Expect(actual.Command[2]).To(ContainSubstring(`-z "$(ls -A "$DESTINATION_PATH" | grep -vx lost+found)"`))
+		})
 	})
 
 	Describe("The forge.ContentDownloaderInitContainer function forges the initContainer for volume pre-population", func() {
@@ -0,0 +1,95 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+const (
+	// LifetimeNever -> the lifetime value identifying instances which are never automatically terminated.
+	LifetimeNever = "never"
+)
+
+// lifetimeRegex matches the lifetime values expressed in minutes, hours or days (e.g., 30m, 12h, 7d).
+var lifetimeRegex = regexp.MustCompile(`^([0-9]+)([mhd])$`)
+
+// ParseLifetime parses a lifetime value, in the [0-9]+[mhd] format, returning the corresponding duration.
+// The second return value is false in case the value corresponds to an unlimited lifetime (i.e., "never" or empty).
+func ParseLifetime(value string) (lifetime time.Duration, limited bool, err error) {
+	if value == "" || value == LifetimeNever {
+		return 0, false, nil
+	}
+
+	match := lifetimeRegex.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false, fmt.Errorf("invalid lifetime %q: expected format is [0-9]+[mhd] or %q", value, LifetimeNever)
+	}
+
+	amount, err := strconv.ParseInt(match[1], 10, 32)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid lifetime %q: %w", value, err)
+	}
+
+	unit := map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour}[match[2]]
+	if amount > int64(math.MaxInt64/unit) {
+		// The corresponding duration would overflow, possibly turning into a negative value.
+		return 0, false, fmt.Errorf("invalid lifetime %q: value out of range", value)
+	}
+	return time.Duration(amount) * unit, true, nil
+}
+
+// InstanceLifetime returns the lifetime of the given instance, measured starting from its creation. It corresponds to
+// the one configured in the template, unless overridden by the instance itself, in which case it is capped to the maximum
+// value allowed by the template (or to the default one, if no maximum is configured). The second return value is false
+// in case the instance is never automatically terminated.
+func InstanceLifetime(template *clv1alpha2.Template, instance *clv1alpha2.Instance) (lifetime time.Duration, limited bool, err error) {
+	lifetime, limited, err = ParseLifetime(template.Spec.DeleteAfter)
+	if err != nil || instance.Spec.DeleteAfter == "" {
+		return lifetime, limited, err
+	}
+
+	maxLifetime, maxLimited := lifetime, limited
+	if template.Spec.MaxDeleteAfter != "" {
+		if maxLifetime, maxLimited, err = ParseLifetime(template.Spec.MaxDeleteAfter); err != nil {
+			return 0, false, err
+		}
+	}
+
+	if lifetime, limited, err = ParseLifetime(instance.Spec.DeleteAfter); err != nil {
+		return 0, false, err
+	}
+
+	if maxLimited && (!limited || lifetime > maxLifetime) {
+		return maxLifetime, true, nil
+	}
+	return lifetime, limited, nil
+}
+
+// InstanceExpirationPolicy returns the action to be performed once the lifetime of the given instance is expired.
+// Instances are stopped rather than deleted only if requested by the template and at least one environment is persistent.
+func InstanceExpirationPolicy(template *clv1alpha2.Template) clv1alpha2.ExpirationPolicy {
+	if template.Spec.ExpirationPolicy == clv1alpha2.ExpirationPolicyStop &&
+		persistentLabelValue(template.Spec.EnvironmentList) == strconv.FormatBool(true) {
+		return clv1alpha2.ExpirationPolicyStop
+	}
+	return clv1alpha2.ExpirationPolicyDelete
+}
@@ -0,0 +1,149 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("Expiration forging", func() {
+
+	Describe("The forge.ParseLifetime function", func() {
+		type ParseLifetimeCase struct {
+			Value            string
+			ExpectedLifetime time.Duration
+			ExpectedLimited  bool
+		}
+
+		DescribeTable("Correctly parses the lifetime",
+			func(c ParseLifetimeCase) {
+				lifetime, limited, err := forge.ParseLifetime(c.Value)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lifetime).To(Equal(c.ExpectedLifetime))
+				Expect(limited).To(Equal(c.ExpectedLimited))
+			},
+			Entry("When the value is empty", ParseLifetimeCase{Value: "", ExpectedLimited: false}),
+			Entry("When the value is never", ParseLifetimeCase{Value: forge.LifetimeNever, ExpectedLimited: false}),
+			Entry("When the value is expressed in minutes", ParseLifetimeCase{Value: "30m", ExpectedLifetime: 30 * time.Minute, ExpectedLimited: true}),
+			Entry("When the value is expressed in hours", ParseLifetimeCase{Value: "12h", ExpectedLifetime: 12 * time.Hour, ExpectedLimited: true}),
+			Entry("When the value is expressed in days", ParseLifetimeCase{Value: "7d", ExpectedLifetime: 7 * 24 * time.Hour, ExpectedLimited: true}),
+			Entry("When the value is the largest representable", ParseLifetimeCase{Value: "106751d", ExpectedLifetime: 106751 * 24 * time.Hour, ExpectedLimited: true}),
+		)
+
+		DescribeTable("Returns an error when the lifetime is invalid",
+			func(value string) {
+				_, _, err := forge.ParseLifetime(value)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("When the unit is missing", "30"),
+			Entry("When the unit is not supported", "30s"),
+			Entry("When the amount is missing", "d"),
+			Entry("When the amount is too large", "99999999999d"),
+			Entry("When the duration would overflow", "200000d"),
+		)
+	})
+
+	Describe("The forge.InstanceLifetime function", func() {
+		type InstanceLifetimeCase struct {
+			TemplateDeleteAfter    string
+			TemplateMaxDeleteAfter string
+			InstanceDeleteAfter    string
+			ExpectedLifetime       time.Duration
+			ExpectedLimited        bool
+		}
+
+		DescribeTable("Correctly computes the instance lifetime",
+			func(c InstanceLifetimeCase) {
+				template := clv1alpha2.Template{Spec: clv1alpha2.TemplateSpec{DeleteAfter: c.TemplateDeleteAfter, MaxDeleteAfter: c.TemplateMaxDeleteAfter}}
+				instance := clv1alpha2.Instance{Spec: clv1alpha2.InstanceSpec{DeleteAfter: c.InstanceDeleteAfter}}
+
+				lifetime, limited, err := forge.InstanceLifetime(&template, &instance)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lifetime).To(Equal(c.ExpectedLifetime))
+				Expect(limited).To(Equal(c.ExpectedLimited))
+			},
+			Entry("When neither the template nor the instance configure a lifetime", InstanceLifetimeCase{
+				TemplateDeleteAfter: forge.LifetimeNever, ExpectedLimited: false,
+			}),
+			Entry("When only the template configures a lifetime", InstanceLifetimeCase{
+				TemplateDeleteAfter: "7d", ExpectedLifetime: 7 * 24 * time.Hour, ExpectedLimited: true,
+			}),
+			Entry("When the instance shortens the template lifetime", InstanceLifetimeCase{
+				TemplateDeleteAfter: "7d", InstanceDeleteAfter: "2h", ExpectedLifetime: 2 * time.Hour, ExpectedLimited: true,
+			}),
+			Entry("When the instance extends the template lifetime, without a maximum", InstanceLifetimeCase{
+				TemplateDeleteAfter: "7d", InstanceDeleteAfter: "10d", ExpectedLifetime: 7 * 24 * time.Hour, ExpectedLimited: true,
+			}),
+			Entry("When the instance extends the template lifetime, within the maximum", InstanceLifetimeCase{
+				TemplateDeleteAfter: "7d", TemplateMaxDeleteAfter: "30d", InstanceDeleteAfter: "10d", ExpectedLifetime: 10 * 24 * time.Hour, ExpectedLimited: true,
+			}),
+			Entry("When the instance extends the template lifetime, beyond the maximum", InstanceLifetimeCase{
+				TemplateDeleteAfter: "7d", TemplateMaxDeleteAfter: "30d", InstanceDeleteAfter: "60d", ExpectedLifetime: 30 * 24 * time.Hour, ExpectedLimited: true,
+			}),
+			Entry("When the instance requests an unlimited lifetime, with a maximum", InstanceLifetimeCase{
+				TemplateDeleteAfter: "7d", TemplateMaxDeleteAfter: "30d", InstanceDeleteAfter: forge.LifetimeNever, ExpectedLifetime: 30 * 24 * time.Hour, ExpectedLimited: true,
+			}),
+			Entry("When the instance requests an unlimited lifetime, with an unlimited maximum", InstanceLifetimeCase{
+				TemplateDeleteAfter: "7d", TemplateMaxDeleteAfter: forge.LifetimeNever, InstanceDeleteAfter: forge.LifetimeNever, ExpectedLimited: false,
+			}),
+			Entry("When the instance limits an otherwise unlimited lifetime", InstanceLifetimeCase{
+				TemplateDeleteAfter: forge.LifetimeNever, InstanceDeleteAfter: "1d", ExpectedLifetime: 24 * time.Hour, ExpectedLimited: true,
+			}),
+		)
+
+		It("Returns an error when the instance lifetime is invalid", func() {
+			template := clv1alpha2.Template{Spec: clv1alpha2.TemplateSpec{DeleteAfter: "7d"}}
+			instance := clv1alpha2.Instance{Spec: clv1alpha2.InstanceSpec{DeleteAfter: "1w"}}
+			_, _, err := forge.InstanceLifetime(&template, &instance)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("The forge.InstanceExpirationPolicy function", func() {
+		type InstanceExpirationPolicyCase struct {
+			Policy         clv1alpha2.ExpirationPolicy
+			Persistent     bool
+			ExpectedPolicy clv1alpha2.ExpirationPolicy
+		}
+
+		DescribeTable("Correctly returns the expected policy",
+			func(c InstanceExpirationPolicyCase) {
+				template := clv1alpha2.Template{Spec: clv1alpha2.TemplateSpec{
+					ExpirationPolicy: c.Policy,
+					EnvironmentList:  []clv1alpha2.Environment{{Name: "app"}, {Name: "db", Persistent: c.Persistent}},
+				}}
+				Expect(forge.InstanceExpirationPolicy(&template)).To(Equal(c.ExpectedPolicy))
+			},
+			Entry("When the policy is not set", InstanceExpirationPolicyCase{
+				Persistent: true, ExpectedPolicy: clv1alpha2.ExpirationPolicyDelete,
+			}),
+			Entry("When the policy is Delete", InstanceExpirationPolicyCase{
+				Policy: clv1alpha2.ExpirationPolicyDelete, Persistent: true, ExpectedPolicy: clv1alpha2.ExpirationPolicyDelete,
+			}),
+			Entry("When the policy is Stop and an environment is persistent", InstanceExpirationPolicyCase{
+				Policy: clv1alpha2.ExpirationPolicyStop, Persistent: true, ExpectedPolicy: clv1alpha2.ExpirationPolicyStop,
+			}),
+			Entry("When the policy is Stop and no environment is persistent", InstanceExpirationPolicyCase{
+				Policy: clv1alpha2.ExpirationPolicyStop, Persistent: false, ExpectedPolicy: clv1alpha2.ExpirationPolicyDelete,
+			}),
+		)
+	})
+})
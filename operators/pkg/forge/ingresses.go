@@ -131,29 +131,29 @@ func IngressGUIPath(instance *clv1alpha2.Instance, environment *clv1alpha2.Envir
 	switch environment.EnvironmentType {
 	case clv1alpha2.ClassStandalone:
 		if environment.RewriteURL {
-			return strings.TrimRight(fmt.Sprintf("%v/%v/%v", IngressInstancePrefix, instance.UID, IngressAppSuffix+"(/|$)(.*)"), "/")
+			return strings.TrimRight(fmt.Sprintf("%v/%v", ingressInstancePath(instance, environment), IngressAppSuffix+"(/|$)(.*)"), "/")
 		}
-		return strings.TrimRight(fmt.Sprintf("%v/%v/%v", IngressInstancePrefix, instance.UID, IngressAppSuffix), "/")
+		return strings.TrimRight(fmt.Sprintf("%v/%v", ingressInstancePath(instance, environment), IngressAppSuffix), "/")
 	case clv1alpha2.ClassContainer:
-		return strings.TrimRight(fmt.Sprintf("%v/%v/%v", IngressInstancePrefix, instance.UID, IngressAppSuffix), "/")
+		return strings.TrimRight(fmt.Sprintf("%v/%v", ingressInstancePath(instance, environment), IngressAppSuffix), "/")
 	case clv1alpha2.ClassCloudVM, clv1alpha2.ClassVM:
-		return strings.TrimRight(fmt.Sprintf("%v/%v/%v", IngressInstancePrefix, instance.UID, IngressVNCGUIPathSuffix), "/")
+		return strings.TrimRight(fmt.Sprintf("%v/%v", ingressInstancePath(instance, environment), IngressVNCGUIPathSuffix), "/")
 	}
 	return ""
 }
 
 // IngressGUICleanPath returns the path of the ingress targeting the environment GUI vnc or Standalone, without the url-rewrite's regex.
-func IngressGUICleanPath(instance *clv1alpha2.Instance) string {
-	return strings.TrimRight(fmt.Sprintf("%v/%v/%v", IngressInstancePrefix, instance.UID, IngressAppSuffix), "/")
+func IngressGUICleanPath(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) string {
+	return strings.TrimRight(fmt.Sprintf("%v/%v", ingressInstancePath(instance, environment), IngressAppSuffix), "/")
 }
 
 // IngressGuiStatusURL returns the path of the ingress targeting the environment.
 func IngressGuiStatusURL(host string, environment *clv1alpha2.Environment, instance *clv1alpha2.Instance) string {
 	switch environment.EnvironmentType {
 	case clv1alpha2.ClassStandalone, clv1alpha2.ClassContainer:
-		return fmt.Sprintf("https://%v%v/%v/%v/", host, IngressInstancePrefix, instance.UID, IngressAppSuffix)
+		return fmt.Sprintf("https://%v%v/%v/", host, ingressInstancePath(instance, environment), IngressAppSuffix)
 	case clv1alpha2.ClassVM, clv1alpha2.ClassCloudVM:
-		return fmt.Sprintf("https://%v%v/%v/", host, IngressInstancePrefix, instance.UID)
+		return fmt.Sprintf("https://%v%v/", host, ingressInstancePath(instance, environment))
 	}
 	return ""
 }
@@ -168,3 +168,12 @@ func IngressGUIName(environment *clv1alpha2.Environment) string {
 	}
 	return ""
 }
+
+// ingressInstancePath returns the base path of the ingresses targeting the given environment of the instance.
+// In case of multi-environment instances, the name of the environment is appended to disambiguate the paths.
+func ingressInstancePath(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) string {
+	if IsMultiEnvironment(instance) {
+		return fmt.Sprintf("%v/%v/%v", IngressInstancePrefix, instance.UID, environment.Name)
+	}
+	return fmt.Sprintf("%v/%v", IngressInstancePrefix, instance.UID)
+}
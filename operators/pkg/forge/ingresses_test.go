@@ -359,6 +359,15 @@ var _ = Describe("Ingresses", func() {
 						Expect(path).To(BeIdenticalTo("/instance/" + instanceUID + "/app"))
 					})
 				})
+				Context("The instance is composed of multiple environments", func() {
+					BeforeEach(func() {
+						environment.Name = "control-plane"
+						instance.SetLabels(map[string]string{forge.InstanceMultiEnvironmentLabel: "true"})
+					})
+					It("Should generate a path based on the instance UID and the environment name", func() {
+						Expect(path).To(BeIdenticalTo("/instance/" + instanceUID + "/control-plane/app"))
+					})
+				})
 			})
 
 		})
@@ -39,6 +39,16 @@ func GetWorkspaceManagerRoleDescription(ws *v1alpha1.Workspace) string {
 	return fmt.Sprintf("%s Manager Role", ws.Spec.PrettyName)
 }
 
+// GetWorkspaceAssistantRoleName returns the Keycloak role name for workspace assistants.
+func GetWorkspaceAssistantRoleName(ws *v1alpha1.Workspace) string {
+	return WorkspaceRoleName(ws.Name, v1alpha2.Assistant)
+}
+
+// GetWorkspaceAssistantRoleDescription returns the Keycloak role description for workspace assistants.
+func GetWorkspaceAssistantRoleDescription(ws *v1alpha1.Workspace) string {
+	return fmt.Sprintf("%s Assistant Role", ws.Spec.PrettyName)
+}
+
 // GetWorkspaceUserRoleName returns the Keycloak role name for workspace users.
 func GetWorkspaceUserRoleName(ws *v1alpha1.Workspace) string {
 	return WorkspaceRoleName(ws.Name, v1alpha2.User)
@@ -33,6 +33,12 @@ const (
 	labelTypeKey         = "crownlabs.polito.it/type"
 	labelVolumeTypeKey   = "crownlabs.polito.it/volume-type"
 	labelNodeSelectorKey = "crownlabs.polito.it/has-node-selector"
+	labelEnvironmentKey  = "crownlabs.polito.it/environment"
+
+	// InstanceMultiEnvironmentLabel -> label for Instances composed of multiple environments.
+	InstanceMultiEnvironmentLabel = "crownlabs.polito.it/multi-environment"
+	// InstancePrivateNetworkLabel -> label for Instances whose environments are attached to a private network.
+	InstancePrivateNetworkLabel = "crownlabs.polito.it/private-network"
 
 	// InstanceTerminationSelectorLabel -> label for Instances which have to be be checked for termination.
 	InstanceTerminationSelectorLabel = "crownlabs.polito.it/watch-for-instance-termination"
@@ -81,6 +87,10 @@ func InstanceLabels(labels map[string]string, template *clv1alpha2.Template, ins
 	update = updateLabel(labels, labelPersistentKey, persistentLabelValue(template.Spec.EnvironmentList)) || update
 	update = updateLabel(labels, labelNodeSelectorKey, nodeSelectorLabelValue(template.Spec.EnvironmentList, instance)) || update
 
+	// The following labels are set only when the corresponding feature is enabled, to avoid modifying the existing instances.
+	update = toggleLabel(labels, InstanceMultiEnvironmentLabel, len(template.Spec.EnvironmentList) > 1) || update
+	update = toggleLabel(labels, InstancePrivateNetworkLabel, template.Spec.PrivateNetwork != nil) || update
+
 	if instance != nil {
 		instCustomizationUrls := instance.Spec.CustomizationUrls
 
@@ -92,6 +102,12 @@ func InstanceLabels(labels map[string]string, template *clv1alpha2.Template, ins
 	return labels, update
 }
 
+// InstanceWorkspaceName returns the name of the workspace the given instance belongs to, as stored in the corresponding
+// label. It is empty in case the labels of the instance have not yet been configured by the instance operator.
+func InstanceWorkspaceName(instance *clv1alpha2.Instance) string {
+	return instance.GetLabels()[labelWorkspaceKey]
+}
+
 // InstanceObjectLabels receives in input a set of labels and returns the updated set depending on the specified instance.
 func InstanceObjectLabels(labels map[string]string, instance *clv1alpha2.Instance) map[string]string {
 	labels = deepCopyLabels(labels)
@@ -104,6 +120,13 @@ func InstanceObjectLabels(labels map[string]string, instance *clv1alpha2.Instanc
 	return labels
 }
 
+// EnvironmentObjectLabels receives in input a set of labels and returns the updated set depending on the specified instance and environment.
+func EnvironmentObjectLabels(labels map[string]string, instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) map[string]string {
+	labels = InstanceObjectLabels(labels, instance)
+	labels[labelEnvironmentKey] = environment.Name
+	return labels
+}
+
 // SandboxObjectLabels receives in input a set of labels and the tenant name, returns the updated set.
 func SandboxObjectLabels(labels map[string]string, name string) map[string]string {
 	labels = deepCopyLabels(labels)
@@ -124,6 +147,35 @@ func InstanceSelectorLabels(instance *clv1alpha2.Instance) map[string]string {
 	}
 }
 
+// EnvironmentSelectorLabels returns a set of selector labels depending on the specified instance and environment.
+// The environment label is added only in case of multi-environment instances, since the selectors of the
+// objects already existing for single-environment instances cannot be modified.
+func EnvironmentSelectorLabels(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) map[string]string {
+	labels := InstanceSelectorLabels(instance)
+	if IsMultiEnvironment(instance) {
+		labels[labelEnvironmentKey] = environment.Name
+	}
+	return labels
+}
+
+// TemplateInstancesSelectorLabels returns a set of labels selecting all the instances referencing the given template.
+func TemplateInstancesSelectorLabels(template *clv1alpha2.Template) map[string]string {
+	return map[string]string{
+		labelWorkspaceKey: template.Spec.WorkspaceRef.Name,
+		labelTemplateKey:  template.Name,
+	}
+}
+
+// IsMultiEnvironment returns whether the given instance is composed of multiple environments.
+func IsMultiEnvironment(instance *clv1alpha2.Instance) bool {
+	return instance.GetLabels()[InstanceMultiEnvironmentLabel] == strconv.FormatBool(true)
+}
+
+// HasPrivateNetwork returns whether the environments of the given instance are attached to a private network.
+func HasPrivateNetwork(instance *clv1alpha2.Instance) bool {
+	return instance.GetLabels()[InstancePrivateNetworkLabel] == strconv.FormatBool(true)
+}
+
 // InstanceAutomationLabelsOnTermination returns a set of labels to be set on an instance when it is terminated.
 func InstanceAutomationLabelsOnTermination(labels map[string]string, submissionRequired bool) map[string]string {
 	labels = deepCopyLabels(labels)
@@ -203,6 +255,18 @@ func updateLabel(labels map[string]string, key, value string) bool {
 	return false
 }
 
+// toggleLabel configures a map entry to "true" if enabled, or removes it otherwise, and returns whether a change was performed.
+func toggleLabel(labels map[string]string, key string, enabled bool) bool {
+	if enabled {
+		return updateLabel(labels, key, strconv.FormatBool(true))
+	}
+	if _, found := labels[key]; found {
+		delete(labels, key)
+		return true
+	}
+	return false
+}
+
 // persistentLabelValue returns the value to be assigned to the persistent label, depending on the environment list.
 func persistentLabelValue(environmentList []clv1alpha2.Environment) string {
 	for i := range environmentList {
@@ -157,6 +157,34 @@ var _ = Describe("Labels forging", func() {
 			}),
 		)
 
+		DescribeTable("Correctly configures the multi-environment label",
+			func(environmentList []clv1alpha2.Environment, expected bool) {
+				template.Spec.EnvironmentList = environmentList
+				output, _ := forge.InstanceLabels(map[string]string{forge.InstanceMultiEnvironmentLabel: "true"}, &template, nil)
+				if expected {
+					Expect(output).To(HaveKeyWithValue(forge.InstanceMultiEnvironmentLabel, "true"))
+				} else {
+					Expect(output).ToNot(HaveKey(forge.InstanceMultiEnvironmentLabel))
+				}
+			},
+			Entry("When a single environment is present", []clv1alpha2.Environment{{Name: "first"}}, false),
+			Entry("When multiple environments are present", []clv1alpha2.Environment{{Name: "first"}, {Name: "second"}}, true),
+		)
+
+		DescribeTable("Correctly configures the private network label",
+			func(network *clv1alpha2.PrivateNetwork, expected bool) {
+				template.Spec.PrivateNetwork = network
+				output, _ := forge.InstanceLabels(map[string]string{forge.InstancePrivateNetworkLabel: "true"}, &template, nil)
+				if expected {
+					Expect(output).To(HaveKeyWithValue(forge.InstancePrivateNetworkLabel, "true"))
+				} else {
+					Expect(output).ToNot(HaveKey(forge.InstancePrivateNetworkLabel))
+				}
+			},
+			Entry("When the private network is not requested", nil, false),
+			Entry("When the private network is requested", &clv1alpha2.PrivateNetwork{CIDR: "10.10.0.0/24"}, true),
+		)
+
 		DescribeTable("Correctly configures the node selection presence label",
 			func(c NodeSelectorEnabledLabelCase) {
 				template.Spec.EnvironmentList = c.EnvironmentList
@@ -437,6 +465,76 @@ var _ = Describe("Labels forging", func() {
 		})
 	})
 
+	Describe("The forge.EnvironmentSelectorLabels function", func() {
+		var instance clv1alpha2.Instance
+		var environment clv1alpha2.Environment
+
+		BeforeEach(func() {
+			instance = clv1alpha2.Instance{
+				ObjectMeta: metav1.ObjectMeta{Name: instanceName, Namespace: instanceNamespace},
+				Spec: clv1alpha2.InstanceSpec{
+					Template: clv1alpha2.GenericRef{Name: templateName, Namespace: templateNamespace},
+					Tenant:   clv1alpha2.GenericRef{Name: tenantName},
+				},
+			}
+			environment = clv1alpha2.Environment{Name: environmentName}
+		})
+
+		When("the instance is composed of a single environment", func() {
+			It("Should match the instance selector labels", func() {
+				Expect(forge.EnvironmentSelectorLabels(&instance, &environment)).To(Equal(forge.InstanceSelectorLabels(&instance)))
+			})
+		})
+
+		When("the instance is composed of multiple environments", func() {
+			BeforeEach(func() {
+				instance.SetLabels(map[string]string{forge.InstanceMultiEnvironmentLabel: "true"})
+			})
+
+			It("Should have the correct values", func() {
+				Expect(forge.EnvironmentSelectorLabels(&instance, &environment)).To(Equal(map[string]string{
+					"crownlabs.polito.it/instance":    instanceName,
+					"crownlabs.polito.it/template":    templateName,
+					"crownlabs.polito.it/tenant":      tenantName,
+					"crownlabs.polito.it/environment": environmentName,
+				}))
+			})
+
+			It("Should be a subset of the object labels", func() {
+				selectorLabels := forge.EnvironmentSelectorLabels(&instance, &environment)
+				objectLabels := forge.EnvironmentObjectLabels(nil, &instance, &environment)
+				for key, value := range selectorLabels {
+					Expect(objectLabels).To(HaveKeyWithValue(key, value))
+				}
+			})
+		})
+	})
+
+	Describe("The forge.TemplateInstancesSelectorLabels function", func() {
+		var template clv1alpha2.Template
+
+		BeforeEach(func() {
+			template = clv1alpha2.Template{
+				ObjectMeta: metav1.ObjectMeta{Name: templateName, Namespace: templateNamespace},
+				Spec:       clv1alpha2.TemplateSpec{WorkspaceRef: clv1alpha2.GenericRef{Name: workspaceName}},
+			}
+		})
+
+		It("Should have the correct values", func() {
+			Expect(forge.TemplateInstancesSelectorLabels(&template)).To(Equal(map[string]string{
+				"crownlabs.polito.it/workspace": workspaceName,
+				"crownlabs.polito.it/template":  templateName,
+			}))
+		})
+
+		It("Should be a subset of the instance labels", func() {
+			instanceLabels, _ := forge.InstanceLabels(nil, &template, nil)
+			for key, value := range forge.TemplateInstancesSelectorLabels(&template) {
+				Expect(instanceLabels).To(HaveKeyWithValue(key, value))
+			}
+		})
+	})
+
 	Describe("The forge.InstanceAutomationLabelsOnTermination function", func() {
 		type AutomationLabelsOnTerminationCase struct {
 			Input                 map[string]string
@@ -19,6 +19,7 @@ package forge
 import (
 	"fmt"
 	"maps"
+	"path"
 	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -43,6 +44,9 @@ const (
 	NFSSecretServerNameKey = "server-name"
 	// NFSSecretPathKey -> NFS path key in NFS secret.
 	NFSSecretPathKey = "path"
+	// NFSSubdirMode -> Permissions of the subdirectories of the NFS volumes created to be mounted,
+	// which are owned by the user the environment creating them runs as.
+	NFSSubdirMode = "0770"
 )
 
 // NFSVolumeMountInfo contains information about a volume that has to be mounted through NFS.
@@ -52,6 +56,8 @@ type NFSVolumeMountInfo struct {
 	ExportPath    string
 	MountPath     string
 	ReadOnly      bool
+	// SubPath is the subdirectory of the exported path to be mounted (if any), which is created if missing.
+	SubPath string
 }
 
 // NFSVolumeMount forges the mount string array for a generic NFS volume.
@@ -110,16 +116,44 @@ func MyDriveNFSVolumeMountInfo(serverAddress, exportPath string) NFSVolumeMountI
 }
 
 // ShVolNFSVolumeMountInfo forges the NFSVolumeMountInfo given a SharedVolume and SharedVolumeMountInfo, its name will be nfs{i}.
-func ShVolNFSVolumeMountInfo(i int, shvol *clv1alpha2.SharedVolume, mount clv1alpha2.SharedVolumeMountInfo) NFSVolumeMountInfo {
+// The permissions and the mounted subdirectory depend on the tenant the volume is mounted for, and on its role in the workspace.
+func ShVolNFSVolumeMountInfo(i int, shvol *clv1alpha2.SharedVolume, mount clv1alpha2.SharedVolumeMountInfo,
+	tenantName string, role clv1alpha2.WorkspaceUserRole) NFSVolumeMountInfo {
+	subPath, readOnly := SharedVolumeMountAccess(mount, tenantName, role)
 	return NFSVolumeMountInfo{
 		VolumeName:    fmt.Sprintf("nfs%d", i),
 		ServerAddress: shvol.Status.ServerAddress,
 		ExportPath:    shvol.Status.ExportPath,
 		MountPath:     mount.MountPath,
-		ReadOnly:      mount.ReadOnly,
+		ReadOnly:      readOnly,
+		SubPath:       subPath,
 	}
 }
 
+// SharedVolumeMountAccess returns the subdirectory to be mounted (empty for the entire volume) and whether
+// the mount is read-only, given the SharedVolumeMountInfo, the name of the tenant and its role in the workspace.
+func SharedVolumeMountAccess(mount clv1alpha2.SharedVolumeMountInfo, tenantName string, role clv1alpha2.WorkspaceUserRole) (subPath string, readOnly bool) {
+	roleBased := mount.AccessPolicy == clv1alpha2.SharedVolumeAccessRoleBased
+
+	switch {
+	case mount.PerTenantSubdirectory && role != clv1alpha2.Manager:
+		// The tenant owns its subdirectory, which is read-only only if explicitly requested.
+		return tenantName, !roleBased && mount.ReadOnly
+	case roleBased:
+		return "", role != clv1alpha2.Manager
+	default:
+		return "", mount.ReadOnly
+	}
+}
+
+// NFSMountExportPath returns the exported path to be mounted, including the subdirectory (if any).
+func NFSMountExportPath(mountInfo NFSVolumeMountInfo) string {
+	if mountInfo.SubPath == "" {
+		return mountInfo.ExportPath
+	}
+	return path.Join(mountInfo.ExportPath, mountInfo.SubPath)
+}
+
 // NFSShVolSpec obtains the NFS server address and the export path from the passed Persistent Volume.
 func NFSShVolSpec(pv *v1.PersistentVolume) (serverAddress, exportPath string) {
 	serverAddress = ""
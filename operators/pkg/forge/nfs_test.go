@@ -194,7 +194,66 @@ var _ = Describe("NFS Mounts and Provisioning Job forging", func() {
 				MountPath:     "/mnt/path",
 				ReadOnly:      true,
 			}
-			Expect(forge.ShVolNFSVolumeMountInfo(7, &shvol, mountInfo)).To(Equal(expected))
+			Expect(forge.ShVolNFSVolumeMountInfo(7, &shvol, mountInfo, "tester", clv1alpha2.User)).To(Equal(expected))
+		})
+
+		When("the shared volume is mounted in per-tenant subdirectory mode", func() {
+			It("Should return the NFSVolumeMountInfo referring to the subdirectory of the tenant", func() {
+				mountInfo.PerTenantSubdirectory = true
+				mountInfo.ReadOnly = false
+				expected := forge.NFSVolumeMountInfo{
+					VolumeName:    "nfs7",
+					ServerAddress: "nfs.example.com",
+					ExportPath:    "/nfs/path",
+					MountPath:     "/mnt/path",
+					SubPath:       "tester",
+				}
+				Expect(forge.ShVolNFSVolumeMountInfo(7, &shvol, mountInfo, "tester", clv1alpha2.User)).To(Equal(expected))
+			})
+		})
+	})
+
+	Describe("The forge.SharedVolumeMountAccess function", func() {
+		type AccessCase struct {
+			Policy           clv1alpha2.SharedVolumeAccessPolicy
+			ReadOnly         bool
+			Subdirectory     bool
+			Role             clv1alpha2.WorkspaceUserRole
+			ExpectedSubPath  string
+			ExpectedReadOnly bool
+		}
+
+		DescribeTable("Correctly computing the access to the shared volume",
+			func(c AccessCase) {
+				mount := clv1alpha2.SharedVolumeMountInfo{AccessPolicy: c.Policy, ReadOnly: c.ReadOnly, PerTenantSubdirectory: c.Subdirectory}
+				subPath, readOnly := forge.SharedVolumeMountAccess(mount, "tester", c.Role)
+				Expect(subPath).To(Equal(c.ExpectedSubPath))
+				Expect(readOnly).To(Equal(c.ExpectedReadOnly))
+			},
+			Entry("Fixed read-write, user", AccessCase{Policy: clv1alpha2.SharedVolumeAccessFixed, Role: clv1alpha2.User}),
+			Entry("Fixed read-only, manager", AccessCase{Policy: clv1alpha2.SharedVolumeAccessFixed, ReadOnly: true, Role: clv1alpha2.Manager, ExpectedReadOnly: true}),
+			Entry("Unspecified policy, read-only", AccessCase{ReadOnly: true, Role: clv1alpha2.User, ExpectedReadOnly: true}),
+			Entry("RoleBased, manager", AccessCase{Policy: clv1alpha2.SharedVolumeAccessRoleBased, ReadOnly: true, Role: clv1alpha2.Manager}),
+			Entry("RoleBased, user", AccessCase{Policy: clv1alpha2.SharedVolumeAccessRoleBased, Role: clv1alpha2.User, ExpectedReadOnly: true}),
+			Entry("RoleBased, not subscribed", AccessCase{Policy: clv1alpha2.SharedVolumeAccessRoleBased, ExpectedReadOnly: true}),
+			Entry("Subdirectory, RoleBased, user", AccessCase{Policy: clv1alpha2.SharedVolumeAccessRoleBased, Subdirectory: true,
+				Role: clv1alpha2.User, ExpectedSubPath: "tester"}),
+			Entry("Subdirectory, RoleBased, manager", AccessCase{Policy: clv1alpha2.SharedVolumeAccessRoleBased, Subdirectory: true,
+				Role: clv1alpha2.Manager}),
+			Entry("Subdirectory, Fixed read-only, user", AccessCase{Policy: clv1alpha2.SharedVolumeAccessFixed, Subdirectory: true, ReadOnly: true,
+				Role: clv1alpha2.User, ExpectedSubPath: "tester", ExpectedReadOnly: true}),
+			Entry("Subdirectory, Fixed read-only, manager", AccessCase{Policy: clv1alpha2.SharedVolumeAccessFixed, Subdirectory: true, ReadOnly: true,
+				Role: clv1alpha2.Manager, ExpectedReadOnly: true}),
+		)
+	})
+
+	Describe("The forge.NFSMountExportPath function", func() {
+		It("Should return the export path, if the entire volume is mounted", func() {
+			Expect(forge.NFSMountExportPath(forge.NFSVolumeMountInfo{ExportPath: "/nfs/path"})).To(Equal("/nfs/path"))
+		})
+
+		It("Should return the path of the subdirectory, if specified", func() {
+			Expect(forge.NFSMountExportPath(forge.NFSVolumeMountInfo{ExportPath: "/nfs/path/", SubPath: "tester"})).To(Equal("/nfs/path/tester"))
 		})
 	})
 
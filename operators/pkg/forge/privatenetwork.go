@@ -0,0 +1,119 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+const (
+	// PrivateNetworkName -> the name of the secondary interface attached to the private network of an instance.
+	PrivateNetworkName = "lab"
+
+	// PrivateNetworkAttachmentAnnotation -> the annotation used by Multus to attach pods to secondary networks.
+	PrivateNetworkAttachmentAnnotation = "k8s.v1.cni.cncf.io/networks"
+	// PrivateNetworkPolicyForAnnotation -> the annotation specifying the secondary network a MultiNetworkPolicy applies to.
+	PrivateNetworkPolicyForAnnotation = "k8s.v1.cni.cncf.io/policy-for"
+
+	// privateNetworkCNIVersion -> the version of the CNI specification of the private network configuration.
+	privateNetworkCNIVersion = "0.3.1"
+	// privateNetworkCNIType -> the CNI plugin implementing the private networks (i.e. OVN-Kubernetes secondary networks).
+	privateNetworkCNIType = "ovn-k8s-cni-overlay"
+	// privateNetworkTopology -> the topology of the private networks, which behave as an L2 segment spanning multiple nodes.
+	privateNetworkTopology = "layer2"
+)
+
+var (
+	// NetworkAttachmentDefinitionGVK -> the GroupVersionKind of the Multus NetworkAttachmentDefinition resource.
+	NetworkAttachmentDefinitionGVK = schema.GroupVersionKind{Group: "k8s.cni.cncf.io", Version: "v1", Kind: "NetworkAttachmentDefinition"}
+	// MultiNetworkPolicyGVK -> the GroupVersionKind of the MultiNetworkPolicy resource, guarding secondary networks.
+	MultiNetworkPolicyGVK = schema.GroupVersionKind{Group: "k8s.cni.cncf.io", Version: "v1beta1", Kind: "MultiNetworkPolicy"}
+)
+
+// privateNetworkConfig represents the CNI configuration of a private network.
+type privateNetworkConfig struct {
+	CNIVersion       string `json:"cniVersion"`
+	Name             string `json:"name"`
+	Type             string `json:"type"`
+	Topology         string `json:"topology"`
+	NetAttachDefName string `json:"netAttachDefName"`
+	Subnets          string `json:"subnets,omitempty"`
+}
+
+// PrivateNetworkNamespacedName returns the namespaced name of the NetworkAttachmentDefinition
+// (and of the associated MultiNetworkPolicy) representing the private network of the given instance.
+func PrivateNetworkNamespacedName(instance *clv1alpha2.Instance) types.NamespacedName {
+	return NamespacedNameWithSuffix(instance, PrivateNetworkName)
+}
+
+// PrivateNetworkConfig forges the CNI configuration of the private network of the given instance.
+func PrivateNetworkConfig(instance *clv1alpha2.Instance, network *clv1alpha2.PrivateNetwork) string {
+	name := PrivateNetworkNamespacedName(instance)
+	config, _ := json.Marshal(privateNetworkConfig{
+		CNIVersion: privateNetworkCNIVersion,
+		// Namespace names cannot contain dots, hence guaranteeing the uniqueness of the network name.
+		Name:             fmt.Sprintf("%s.%s", name.Namespace, name.Name),
+		Type:             privateNetworkCNIType,
+		Topology:         privateNetworkTopology,
+		NetAttachDefName: name.String(),
+		Subnets:          network.CIDR,
+	})
+	return string(config)
+}
+
+// PrivateNetworkSpec forges the specification of the NetworkAttachmentDefinition representing
+// the private network of the given instance, in unstructured form.
+func PrivateNetworkSpec(instance *clv1alpha2.Instance, network *clv1alpha2.PrivateNetwork) map[string]interface{} {
+	return map[string]interface{}{"config": PrivateNetworkConfig(instance, network)}
+}
+
+// PrivateNetworkPodAnnotations forges the annotations to attach the pods of the given instance to its private network, if any.
+func PrivateNetworkPodAnnotations(instance *clv1alpha2.Instance) map[string]string {
+	if !HasPrivateNetwork(instance) {
+		return nil
+	}
+	return map[string]string{
+		PrivateNetworkAttachmentAnnotation: fmt.Sprintf("%s@%s", PrivateNetworkNamespacedName(instance).Name, PrivateNetworkName),
+	}
+}
+
+// PrivateNetworkPolicyAnnotations forges the annotations binding the MultiNetworkPolicy to the private network of the given instance.
+func PrivateNetworkPolicyAnnotations(annotations map[string]string, instance *clv1alpha2.Instance) map[string]string {
+	annotations = deepCopyLabels(annotations)
+	annotations[PrivateNetworkPolicyForAnnotation] = PrivateNetworkNamespacedName(instance).String()
+	return annotations
+}
+
+// PrivateNetworkPolicySpec forges the specification of the policy guarding the private network of the given instance,
+// which allows only the traffic exchanged between the environments of the instance itself.
+func PrivateNetworkPolicySpec(instance *clv1alpha2.Instance) netv1.NetworkPolicySpec {
+	selector := metav1.LabelSelector{MatchLabels: InstanceSelectorLabels(instance)}
+	peers := []netv1.NetworkPolicyPeer{{PodSelector: &selector}}
+
+	return netv1.NetworkPolicySpec{
+		PodSelector: selector,
+		PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeIngress, netv1.PolicyTypeEgress},
+		Ingress:     []netv1.NetworkPolicyIngressRule{{From: peers}},
+		Egress:      []netv1.NetworkPolicyEgressRule{{To: peers}},
+	}
+}
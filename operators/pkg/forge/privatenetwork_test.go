@@ -0,0 +1,142 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("Private networks forging", func() {
+	var instance clv1alpha2.Instance
+
+	const (
+		instanceName      = "kubernetes.0000"
+		instanceNamespace = "tenant-tester"
+		templateName      = "kubernetes"
+		templateNamespace = "workspace-netgroup"
+		tenantName        = "tester"
+	)
+
+	BeforeEach(func() {
+		instance = clv1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: instanceName, Namespace: instanceNamespace},
+			Spec: clv1alpha2.InstanceSpec{
+				Template: clv1alpha2.GenericRef{Name: templateName, Namespace: templateNamespace},
+				Tenant:   clv1alpha2.GenericRef{Name: tenantName},
+			},
+		}
+	})
+
+	Describe("The forge.PrivateNetworkNamespacedName function", func() {
+		It("Should return the correct namespaced name", func() {
+			Expect(forge.PrivateNetworkNamespacedName(&instance)).To(Equal(
+				types.NamespacedName{Namespace: instanceNamespace, Name: "kubernetes-0000-lab"}))
+		})
+	})
+
+	Describe("The forge.PrivateNetworkConfig function", func() {
+		DescribeTable("Correctly forges the CNI configuration",
+			func(network clv1alpha2.PrivateNetwork, expected string) {
+				Expect(forge.PrivateNetworkConfig(&instance, &network)).To(MatchJSON(expected))
+			},
+			Entry("When the CIDR is not specified", clv1alpha2.PrivateNetwork{}, `{
+				"cniVersion": "0.3.1",
+				"name": "tenant-tester.kubernetes-0000-lab",
+				"type": "ovn-k8s-cni-overlay",
+				"topology": "layer2",
+				"netAttachDefName": "tenant-tester/kubernetes-0000-lab"
+			}`),
+			Entry("When the CIDR is specified", clv1alpha2.PrivateNetwork{CIDR: "10.10.0.0/24"}, `{
+				"cniVersion": "0.3.1",
+				"name": "tenant-tester.kubernetes-0000-lab",
+				"type": "ovn-k8s-cni-overlay",
+				"topology": "layer2",
+				"netAttachDefName": "tenant-tester/kubernetes-0000-lab",
+				"subnets": "10.10.0.0/24"
+			}`),
+		)
+	})
+
+	Describe("The forge.PrivateNetworkPodAnnotations function", func() {
+		When("the instance is not attached to a private network", func() {
+			It("Should return no annotations", func() {
+				Expect(forge.PrivateNetworkPodAnnotations(&instance)).To(BeNil())
+			})
+		})
+
+		When("the instance is attached to a private network", func() {
+			BeforeEach(func() {
+				instance.SetLabels(map[string]string{forge.InstancePrivateNetworkLabel: "true"})
+			})
+
+			It("Should return the network attachment annotation", func() {
+				Expect(forge.PrivateNetworkPodAnnotations(&instance)).To(Equal(map[string]string{
+					"k8s.v1.cni.cncf.io/networks": "kubernetes-0000-lab@lab",
+				}))
+			})
+		})
+	})
+
+	Describe("The forge.PrivateNetworkPolicyAnnotations function", func() {
+		var input, expectedInput map[string]string
+
+		BeforeEach(func() {
+			input = map[string]string{"user/key": "user/value"}
+			expectedInput = map[string]string{"user/key": "user/value"}
+		})
+
+		It("Should add the policy-for annotation", func() {
+			Expect(forge.PrivateNetworkPolicyAnnotations(input, &instance)).To(Equal(map[string]string{
+				"user/key":                      "user/value",
+				"k8s.v1.cni.cncf.io/policy-for": "tenant-tester/kubernetes-0000-lab",
+			}))
+		})
+
+		It("The original annotations map is not modified", func() {
+			forge.PrivateNetworkPolicyAnnotations(input, &instance)
+			Expect(input).To(Equal(expectedInput))
+		})
+	})
+
+	Describe("The forge.PrivateNetworkPolicySpec function", func() {
+		var spec netv1.NetworkPolicySpec
+
+		JustBeforeEach(func() {
+			spec = forge.PrivateNetworkPolicySpec(&instance)
+		})
+
+		It("Should select the pods of the instance", func() {
+			Expect(spec.PodSelector.MatchLabels).To(Equal(forge.InstanceSelectorLabels(&instance)))
+		})
+
+		It("Should guard both ingress and egress traffic", func() {
+			Expect(spec.PolicyTypes).To(ConsistOf(netv1.PolicyTypeIngress, netv1.PolicyTypeEgress))
+		})
+
+		It("Should allow only the traffic between the pods of the instance", func() {
+			Expect(spec.Ingress).To(HaveLen(1))
+			Expect(spec.Ingress[0].From).To(ConsistOf(netv1.NetworkPolicyPeer{PodSelector: &spec.PodSelector}))
+			Expect(spec.Egress).To(HaveLen(1))
+			Expect(spec.Egress[0].To).To(ConsistOf(netv1.NetworkPolicyPeer{PodSelector: &spec.PodSelector}))
+		})
+	})
+})
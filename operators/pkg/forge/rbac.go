@@ -29,6 +29,9 @@ const (
 	// WorkspaceInstancesManagerRoleName -> the name of the ClusterRole for managing instances in workspaces.
 	WorkspaceInstancesManagerRoleName = "crownlabs-manage-instances"
 
+	// WorkspaceInstancesControllerRoleName -> the name of the ClusterRole for controlling (but not creating or deleting) instances in workspaces.
+	WorkspaceInstancesControllerRoleName = "crownlabs-control-instances"
+
 	// WorkspaceTenantsManagerRoleName -> the name of the ClusterRole for managing tenants in workspaces.
 	WorkspaceTenantsManagerRoleName = "crownlabs-manage-tenants"
 
@@ -44,6 +47,11 @@ func GetWorkspaceInstancesManagerBindingName(ws *v1alpha1.Workspace) string {
 	return fmt.Sprintf("%s-%s", WorkspaceInstancesManagerRoleName, ws.Name)
 }
 
+// GetWorkspaceInstancesControllerBindingName returns the name of the ClusterRoleBinding for controlling instances in a workspace.
+func GetWorkspaceInstancesControllerBindingName(ws *v1alpha1.Workspace) string {
+	return fmt.Sprintf("%s-%s", WorkspaceInstancesControllerRoleName, ws.Name)
+}
+
 // GetWorkspaceTenantsManagerBindingName returns the name of the ClusterRoleBinding for managing tenants in a workspace.
 func GetWorkspaceTenantsManagerBindingName(ws *v1alpha1.Workspace) string {
 	return fmt.Sprintf("%s-%s", WorkspaceTenantsManagerRoleName, ws.Name)
@@ -69,8 +77,31 @@ func ConfigureWorkspaceInstancesManagerBinding(ws *v1alpha1.Workspace, crb *rbac
 	}
 }
 
+// ConfigureWorkspaceInstancesControllerBinding configures the RoleRef and Subjects for a ClusterRoleBinding
+// that grants permissions to control (e.g. start and stop) the instances in a workspace. The permissions are
+// granted at the cluster level, while the instance webhook restricts them to the instances of the workspace.
+func ConfigureWorkspaceInstancesControllerBinding(ws *v1alpha1.Workspace, crb *rbacv1.ClusterRoleBinding) {
+	// Configure the RoleRef for instances control
+	crb.RoleRef = rbacv1.RoleRef{
+		Kind:     "ClusterRole",
+		Name:     WorkspaceInstancesControllerRoleName,
+		APIGroup: rbacv1.GroupName,
+	}
+
+	// Set the subjects (Workspace Assistants)
+	crb.Subjects = []rbacv1.Subject{
+		{
+			Kind:     rbacv1.GroupKind,
+			Name:     fmt.Sprintf("kubernetes:%s", WorkspaceRoleName(ws.Name, clv1alpha2.Assistant)),
+			APIGroup: rbacv1.GroupName,
+		},
+	}
+}
+
 // ConfigureWorkspaceTenantsManagerBinding configures the RoleRef and Subjects for a ClusterRoleBinding
-// that grants permissions to manage tenants in a workspace.
+// that grants permissions to manage tenants in a workspace. Assistants are included as well, since
+// they can approve candidates, while the tenant webhook restricts them to reviewing the candidates
+// of the workspace, and prevents them from creating tenants.
 func ConfigureWorkspaceTenantsManagerBinding(ws *v1alpha1.Workspace, crb *rbacv1.ClusterRoleBinding) {
 	// Configure the RoleRef for tenants management
 	crb.RoleRef = rbacv1.RoleRef{
@@ -79,13 +110,18 @@ func ConfigureWorkspaceTenantsManagerBinding(ws *v1alpha1.Workspace, crb *rbacv1
 		APIGroup: rbacv1.GroupName,
 	}
 
-	// Set the subjects (Workspace Managers)
+	// Set the subjects (Workspace Managers and Assistants)
 	crb.Subjects = []rbacv1.Subject{
 		{
 			Kind:     rbacv1.GroupKind,
 			Name:     fmt.Sprintf("kubernetes:%s", WorkspaceRoleName(ws.Name, clv1alpha2.Manager)),
 			APIGroup: rbacv1.GroupName,
 		},
+		{
+			Kind:     rbacv1.GroupKind,
+			Name:     fmt.Sprintf("kubernetes:%s", WorkspaceRoleName(ws.Name, clv1alpha2.Assistant)),
+			APIGroup: rbacv1.GroupName,
+		},
 	}
 }
 
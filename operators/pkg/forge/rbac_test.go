@@ -84,6 +84,35 @@ var _ = Describe("RBAC forging", func() {
 		})
 	})
 
+	Describe("The forge.ConfigureWorkspaceInstancesControllerBinding function", func() {
+		var (
+			crb *rbacv1.ClusterRoleBinding
+		)
+
+		BeforeEach(func() {
+			crb = &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: forge.GetWorkspaceInstancesControllerBindingName(workspace),
+				},
+			}
+		})
+
+		It("Should configure the correct RoleRef and Subject", func() {
+			forge.ConfigureWorkspaceInstancesControllerBinding(workspace, crb)
+
+			// Check RoleRef
+			Expect(crb.RoleRef.Kind).To(Equal("ClusterRole"))
+			Expect(crb.RoleRef.Name).To(Equal(forge.WorkspaceInstancesControllerRoleName))
+			Expect(crb.RoleRef.APIGroup).To(Equal("rbac.authorization.k8s.io"))
+
+			// Check Subject
+			Expect(crb.Subjects).To(HaveLen(1))
+			Expect(crb.Subjects[0].Kind).To(Equal("Group"))
+			Expect(crb.Subjects[0].Name).To(Equal("kubernetes:workspace-test-workspace:assistant"))
+			Expect(crb.Subjects[0].APIGroup).To(Equal("rbac.authorization.k8s.io"))
+		})
+	})
+
 	Describe("The forge.ConfigureWorkspaceTenantsManagerBinding function", func() {
 		var (
 			crb *rbacv1.ClusterRoleBinding
@@ -105,11 +134,14 @@ var _ = Describe("RBAC forging", func() {
 			Expect(crb.RoleRef.Name).To(Equal(forge.WorkspaceTenantsManagerRoleName))
 			Expect(crb.RoleRef.APIGroup).To(Equal("rbac.authorization.k8s.io"))
 
-			// Check Subject
-			Expect(crb.Subjects).To(HaveLen(1))
+			// Check Subjects
+			Expect(crb.Subjects).To(HaveLen(2))
 			Expect(crb.Subjects[0].Kind).To(Equal("Group"))
 			Expect(crb.Subjects[0].Name).To(Equal("kubernetes:workspace-test-workspace:manager"))
 			Expect(crb.Subjects[0].APIGroup).To(Equal("rbac.authorization.k8s.io"))
+			Expect(crb.Subjects[1].Kind).To(Equal("Group"))
+			Expect(crb.Subjects[1].Name).To(Equal("kubernetes:workspace-test-workspace:assistant"))
+			Expect(crb.Subjects[1].APIGroup).To(Equal("rbac.authorization.k8s.io"))
 		})
 	})
 
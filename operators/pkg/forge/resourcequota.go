@@ -15,6 +15,8 @@
 package forge
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 
@@ -75,6 +77,24 @@ func TenantResourceList(workspaces []clv1alpha1.Workspace, override *clv1alpha2.
 	return quota
 }
 
+// WorkspaceQuotaCapViolations returns the human-readable descriptions of the resources of the given
+// workspace quota exceeding the caps enforced on each tenant (if configured), if any.
+func WorkspaceQuotaCapViolations(quota *clv1alpha1.WorkspaceResourceQuota) []string {
+	var violations []string
+	check := func(name string, cap int, value, capValue resource.Quantity) {
+		if cap > 0 && value.Cmp(capValue) > 0 {
+			violations = append(violations, fmt.Sprintf("%s (%s requested, %s allowed)", name, value.String(), capValue.String()))
+		}
+	}
+
+	check("cpu", CapCPU, quota.CPU, *resource.NewQuantity(int64(CapCPU), resource.DecimalSI))
+	check("memory", CapMemoryGiga, quota.Memory, *resource.NewScaledQuantity(int64(CapMemoryGiga), resource.Giga))
+	if CapInstance > 0 && quota.Instances > CapInstance {
+		violations = append(violations, fmt.Sprintf("instances (%d requested, %d allowed)", quota.Instances, CapInstance))
+	}
+	return violations
+}
+
 // TenantResourceQuotaSpec forges the Resource Quota spec as the value defined in TenantStatus.
 func TenantResourceQuotaSpec(quota *clv1alpha2.TenantResourceQuota) corev1.ResourceList {
 	return corev1.ResourceList{
@@ -95,3 +115,61 @@ func SandboxResourceQuotaSpec() corev1.ResourceList {
 		corev1.ResourceRequestsMemory: SandboxMemoryQuota,
 	}
 }
+
+// InstanceResourceUsage returns the resources consumed by an instance of the given template, depending on whether it is running:
+// CPU and memory are consumed only by running instances, while storage is consumed by the persistent environments in any case.
+func InstanceResourceUsage(template *clv1alpha2.Template, running bool) clv1alpha1.WorkspaceResourceUsage {
+	usage := clv1alpha1.WorkspaceResourceUsage{Instances: 1}
+	for i := range template.Spec.EnvironmentList {
+		environment := &template.Spec.EnvironmentList[i]
+		if running {
+			usage.CPU.Add(*resource.NewQuantity(int64(environment.Resources.CPU), resource.DecimalSI))
+			usage.Memory.Add(environment.Resources.Memory)
+		}
+		if environment.Persistent {
+			usage.Storage.Add(environment.Resources.Disk)
+		}
+	}
+	return usage
+}
+
+// WorkspaceResourceUsage returns the resources consumed by the given instances, given the templates they refer to (indexed by name).
+// The instances referring to templates not included in the map are ignored.
+func WorkspaceResourceUsage(instances []clv1alpha2.Instance, templates map[string]*clv1alpha2.Template) clv1alpha1.WorkspaceResourceUsage {
+	var usage clv1alpha1.WorkspaceResourceUsage
+	for i := range instances {
+		template, found := templates[instances[i].Spec.Template.Name]
+		if !found {
+			continue
+		}
+		AddResourceUsage(&usage, InstanceResourceUsage(template, instances[i].Spec.Running))
+	}
+	return usage
+}
+
+// AddResourceUsage adds the delta usage to the given one.
+func AddResourceUsage(usage *clv1alpha1.WorkspaceResourceUsage, delta clv1alpha1.WorkspaceResourceUsage) {
+	usage.CPU.Add(delta.CPU)
+	usage.Memory.Add(delta.Memory)
+	usage.Storage.Add(delta.Storage)
+	usage.Instances += delta.Instances
+}
+
+// WorkspaceAggregateQuotaViolations returns the human-readable descriptions of the resources
+// whose usage exceeds the given aggregate quota, if any.
+func WorkspaceAggregateQuotaViolations(quota *clv1alpha1.WorkspaceAggregateQuota, usage *clv1alpha1.WorkspaceResourceUsage) []string {
+	var violations []string
+	check := func(name string, limit *resource.Quantity, used resource.Quantity) {
+		if limit != nil && used.Cmp(*limit) > 0 {
+			violations = append(violations, fmt.Sprintf("%s (%s requested, %s available)", name, used.String(), limit.String()))
+		}
+	}
+
+	check("cpu", quota.CPU, usage.CPU)
+	check("memory", quota.Memory, usage.Memory)
+	check("storage", quota.Storage, usage.Storage)
+	if quota.Instances != nil && usage.Instances > *quota.Instances {
+		violations = append(violations, fmt.Sprintf("instances (%d requested, %d available)", usage.Instances, *quota.Instances))
+	}
+	return violations
+}
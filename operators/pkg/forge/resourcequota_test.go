@@ -104,6 +104,39 @@ var _ = Describe("Resource quota spec forging", func() {
 		})
 	})
 
+	Describe("The forge.WorkspaceQuotaCapViolations function", func() {
+		var quota clv1alpha1.WorkspaceResourceQuota
+
+		BeforeEach(func() {
+			quota = clv1alpha1.WorkspaceResourceQuota{
+				CPU:       *resource.NewQuantity(10, resource.DecimalSI),
+				Memory:    *resource.NewScaledQuantity(15, resource.Giga),
+				Instances: 2,
+			}
+			forge.CapCPU, forge.CapMemoryGiga, forge.CapInstance = 8, 20, 5
+		})
+
+		AfterEach(func() {
+			forge.CapCPU, forge.CapMemoryGiga, forge.CapInstance = 0, 0, 0
+		})
+
+		It("Should report the resources exceeding the caps", func() {
+			quota.Instances = 6
+			Expect(forge.WorkspaceQuotaCapViolations(&quota)).To(ConsistOf(
+				"cpu (10 requested, 8 allowed)", "instances (6 requested, 5 allowed)"))
+		})
+
+		It("Should not report any violation within the caps", func() {
+			forge.CapCPU = 10
+			Expect(forge.WorkspaceQuotaCapViolations(&quota)).To(BeEmpty())
+		})
+
+		It("Should not report any violation if the caps are not configured", func() {
+			forge.CapCPU, forge.CapMemoryGiga, forge.CapInstance = 0, 0, 0
+			Expect(forge.WorkspaceQuotaCapViolations(&quota)).To(BeEmpty())
+		})
+	})
+
 	Describe("The forge.TenantResourceQuotaSpec function", func() {
 		var (
 			spec   corev1.ResourceList
@@ -165,4 +198,74 @@ var _ = Describe("Resource quota spec forging", func() {
 
 		})
 	})
+
+	Describe("The forge.WorkspaceResourceUsage function", func() {
+		var (
+			templates map[string]*clv1alpha2.Template
+			instances []clv1alpha2.Instance
+			usage     clv1alpha1.WorkspaceResourceUsage
+		)
+
+		BeforeEach(func() {
+			templates = map[string]*clv1alpha2.Template{
+				"persistent": {Spec: clv1alpha2.TemplateSpec{EnvironmentList: []clv1alpha2.Environment{{
+					Persistent: true,
+					Resources:  clv1alpha2.EnvironmentResources{CPU: 2, Memory: resource.MustParse("4Gi"), Disk: resource.MustParse("20Gi")},
+				}}}},
+				"ephemeral": {Spec: clv1alpha2.TemplateSpec{EnvironmentList: []clv1alpha2.Environment{{
+					Resources: clv1alpha2.EnvironmentResources{CPU: 1, Memory: resource.MustParse("1Gi"), Disk: resource.MustParse("10Gi")},
+				}}}},
+			}
+			instances = []clv1alpha2.Instance{
+				{Spec: clv1alpha2.InstanceSpec{Template: clv1alpha2.GenericRef{Name: "persistent"}, Running: true}},
+				{Spec: clv1alpha2.InstanceSpec{Template: clv1alpha2.GenericRef{Name: "persistent"}, Running: false}},
+				{Spec: clv1alpha2.InstanceSpec{Template: clv1alpha2.GenericRef{Name: "ephemeral"}, Running: true}},
+				{Spec: clv1alpha2.InstanceSpec{Template: clv1alpha2.GenericRef{Name: "not-existing"}, Running: true}},
+			}
+		})
+
+		JustBeforeEach(func() {
+			usage = forge.WorkspaceResourceUsage(instances, templates)
+		})
+
+		It("Should count the instances referring to known templates", func() {
+			Expect(usage.Instances).To(BeNumerically("==", 3))
+		})
+
+		It("Should account CPU and memory only for the running instances", func() {
+			Expect(usage.CPU.Cmp(*resource.NewQuantity(3, resource.DecimalSI))).To(BeZero())
+			Expect(usage.Memory.Cmp(resource.MustParse("5Gi"))).To(BeZero())
+		})
+
+		It("Should account storage only for the persistent environments", func() {
+			Expect(usage.Storage.Cmp(resource.MustParse("40Gi"))).To(BeZero())
+		})
+	})
+
+	Describe("The forge.WorkspaceAggregateQuotaViolations function", func() {
+		var (
+			quota clv1alpha1.WorkspaceAggregateQuota
+			usage clv1alpha1.WorkspaceResourceUsage
+		)
+
+		BeforeEach(func() {
+			cpu, storage, instances := resource.MustParse("4"), resource.MustParse("50Gi"), int64(3)
+			quota = clv1alpha1.WorkspaceAggregateQuota{CPU: &cpu, Storage: &storage, Instances: &instances}
+			usage = clv1alpha1.WorkspaceResourceUsage{
+				CPU: resource.MustParse("6"), Memory: resource.MustParse("100Gi"), Storage: resource.MustParse("50Gi"), Instances: 4,
+			}
+		})
+
+		It("Should report only the resources exceeding the configured limits", func() {
+			Expect(forge.WorkspaceAggregateQuotaViolations(&quota, &usage)).To(ConsistOf(
+				"cpu (6 requested, 4 available)",
+				"instances (4 requested, 3 available)",
+			))
+		})
+
+		It("Should report no violations when the usage is within the limits", func() {
+			usage.CPU, usage.Instances = resource.MustParse("4"), 3
+			Expect(forge.WorkspaceAggregateQuotaViolations(&quota, &usage)).To(BeEmpty())
+		})
+	})
 })
@@ -35,7 +35,7 @@ const (
 	ManageSharedVolumesRoleName = "crownlabs-manage-sharedvolumes"
 )
 
-// ConfigureWorkspaceUserViewTemplatesBinding configures a RoleBinding for a workspace user to view templates.
+// ConfigureWorkspaceUserViewTemplatesBinding configures a RoleBinding for workspace users and assistants to view templates.
 func ConfigureWorkspaceUserViewTemplatesBinding(ws *v1alpha1.Workspace, rb *rbacv1.RoleBinding, labels map[string]string) {
 	// Set labels
 	if rb.Labels == nil {
@@ -57,6 +57,11 @@ func ConfigureWorkspaceUserViewTemplatesBinding(ws *v1alpha1.Workspace, rb *rbac
 			Name:     fmt.Sprintf("kubernetes:%s", WorkspaceRoleName(ws.Name, v1alpha2.User)),
 			APIGroup: rbacv1.GroupName,
 		},
+		{
+			Kind:     rbacv1.GroupKind,
+			Name:     fmt.Sprintf("kubernetes:%s", WorkspaceRoleName(ws.Name, v1alpha2.Assistant)),
+			APIGroup: rbacv1.GroupName,
+		},
 	}
 }
 
@@ -75,10 +75,11 @@ var _ = Describe("RoleBinding forging", func() {
 				Expect(rb.RoleRef.APIGroup).To(Equal("rbac.authorization.k8s.io"))
 
 				// Check Subject
-				Expect(rb.Subjects).To(HaveLen(1))
+				Expect(rb.Subjects).To(HaveLen(2))
 				Expect(rb.Subjects[0].Kind).To(Equal("Group"))
 				Expect(rb.Subjects[0].Name).To(Equal("kubernetes:workspace-test-workspace:user"))
 				Expect(rb.Subjects[0].APIGroup).To(Equal("rbac.authorization.k8s.io"))
+				Expect(rb.Subjects[1].Name).To(Equal("kubernetes:workspace-test-workspace:assistant"))
 			})
 		})
 
@@ -0,0 +1,173 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+const (
+	// SharedVolumeScheduledBackupLabel -> the label identifying the SharedVolumeSnapshots created according to
+	// the backup schedule of a SharedVolume, whose value is the name of the SharedVolume.
+	SharedVolumeScheduledBackupLabel = "crownlabs.polito.it/scheduled-backup"
+
+	// SharedVolumeRestoreContainerName -> the name of the container copying the content of a snapshot into the target SharedVolume.
+	SharedVolumeRestoreContainerName = "restore"
+	// SharedVolumeRestoreMaxRetries -> the maximum number of retries of the restore job.
+	SharedVolumeRestoreMaxRetries = 3
+
+	sharedVolumeRestoreSourcePath = "/media/source"
+	sharedVolumeRestoreTargetPath = "/media/target"
+)
+
+// VolumeSnapshotGVK -> the GroupVersionKind of the CSI VolumeSnapshot resource.
+var VolumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// VolumeSnapshotState summarizes the status of a CSI VolumeSnapshot.
+type VolumeSnapshotState struct {
+	ReadyToUse   bool
+	CreationTime *metav1.Time
+	RestoreSize  *resource.Quantity
+	Error        string
+}
+
+// SharedVolumePVCName returns the name of the PVC backing the SharedVolume with the given name.
+func SharedVolumePVCName(name string) string {
+	return "shvol-" + name
+}
+
+// SharedVolumeSnapshotVolumeSnapshotName returns the name of the CSI VolumeSnapshot backing the given SharedVolumeSnapshot.
+func SharedVolumeSnapshotVolumeSnapshotName(shvolsnap *clv1alpha2.SharedVolumeSnapshot) string {
+	return "shvolsnap-" + shvolsnap.GetName()
+}
+
+// SharedVolumeRestoreObjectName returns the name of the temporary PVC and of the job used by the given SharedVolumeRestore.
+func SharedVolumeRestoreObjectName(restore *clv1alpha2.SharedVolumeRestore) string {
+	return "shvolrestore-" + restore.GetName()
+}
+
+// VolumeSnapshotSpec forges the specification of the CSI VolumeSnapshot of the given PVC, in unstructured form.
+// The default VolumeSnapshotClass is used if the class name is empty.
+func VolumeSnapshotSpec(pvcName, className string) map[string]interface{} {
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"persistentVolumeClaimName": pvcName},
+	}
+	if className != "" {
+		spec["volumeSnapshotClassName"] = className
+	}
+	return spec
+}
+
+// VolumeSnapshotStatus extracts the relevant information from the status of the given CSI VolumeSnapshot.
+// Malformed fields are ignored, as if they were not yet set.
+func VolumeSnapshotStatus(snapshot *unstructured.Unstructured) VolumeSnapshotState {
+	var state VolumeSnapshotState
+	state.ReadyToUse, _, _ = unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	state.Error, _, _ = unstructured.NestedString(snapshot.Object, "status", "error", "message")
+
+	if value, found, _ := unstructured.NestedString(snapshot.Object, "status", "creationTime"); found {
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			state.CreationTime = &metav1.Time{Time: parsed}
+		}
+	}
+	if value, found, _ := unstructured.NestedString(snapshot.Object, "status", "restoreSize"); found {
+		if parsed, err := resource.ParseQuantity(value); err == nil {
+			state.RestoreSize = &parsed
+		}
+	}
+	return state
+}
+
+// ScheduledSharedVolumeSnapshot forges the SharedVolumeSnapshot corresponding to the backup of the given SharedVolume
+// scheduled at the given time.
+func ScheduledSharedVolumeSnapshot(shvol *clv1alpha2.SharedVolume, scheduled time.Time) clv1alpha2.SharedVolumeSnapshot {
+	return clv1alpha2.SharedVolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", shvol.GetName(), scheduled.UTC().Format("20060102t150405")),
+			Namespace: shvol.GetNamespace(),
+			Labels:    map[string]string{SharedVolumeScheduledBackupLabel: shvol.GetName()},
+		},
+		Spec: clv1alpha2.SharedVolumeSnapshotSpec{
+			SharedVolumeRef:         clv1alpha2.GenericRef{Name: shvol.GetName()},
+			VolumeSnapshotClassName: shvol.Spec.BackupSchedule.VolumeSnapshotClassName,
+		},
+	}
+}
+
+// SharedVolumeRestorePVCSpec forges the specification of the temporary PVC populated with the content of the given CSI VolumeSnapshot.
+func SharedVolumeRestorePVCSpec(storageClass *string, size resource.Quantity, snapshotName string) corev1.PersistentVolumeClaimSpec {
+	spec := PVCSpec(corev1.ReadWriteMany, storageClass, &size)
+	spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: ptr.To(VolumeSnapshotGVK.Group),
+		Kind:     VolumeSnapshotGVK.Kind,
+		Name:     snapshotName,
+	}
+	return spec
+}
+
+// SharedVolumeRestoreJobSpec forges the specification of the job replacing the content of the target PVC with the one of the source PVC.
+func SharedVolumeRestoreJobSpec(sourcePVC, targetPVC string) batchv1.JobSpec {
+	return batchv1.JobSpec{
+		BackoffLimit: ptr.To[int32](SharedVolumeRestoreMaxRetries),
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers: []corev1.Container{{
+					Name:  SharedVolumeRestoreContainerName,
+					Image: ProvisionJobBaseImage,
+					Command: []string{"/bin/sh", "-c", `find "$TARGET_PATH" -mindepth 1 -maxdepth 1 ! -name lost+found -exec rm -rf {} \; && ` +
+						`cp -a "$SOURCE_PATH/." "$TARGET_PATH/"`},
+					Env: []corev1.EnvVar{
+						{Name: "SOURCE_PATH", Value: sharedVolumeRestoreSourcePath},
+						{Name: "TARGET_PATH", Value: sharedVolumeRestoreTargetPath},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "source", MountPath: sharedVolumeRestoreSourcePath, ReadOnly: true},
+						{Name: "target", MountPath: sharedVolumeRestoreTargetPath},
+					},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("100m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("256Mi"),
+						},
+					},
+				}},
+				Volumes: []corev1.Volume{
+					{Name: "source", VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: sourcePVC, ReadOnly: true},
+					}},
+					{Name: "target", VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: targetPVC},
+					}},
+				},
+			},
+		},
+	}
+}
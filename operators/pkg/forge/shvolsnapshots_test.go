@@ -0,0 +1,141 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("SharedVolume snapshots forging", func() {
+	Describe("The forge.VolumeSnapshotSpec function", func() {
+		It("Should refer to the given PVC and class", func() {
+			Expect(forge.VolumeSnapshotSpec("shvol-test", "csi-snapclass")).To(Equal(map[string]interface{}{
+				"source":                  map[string]interface{}{"persistentVolumeClaimName": "shvol-test"},
+				"volumeSnapshotClassName": "csi-snapclass",
+			}))
+		})
+
+		It("Should omit the class, if not specified", func() {
+			Expect(forge.VolumeSnapshotSpec("shvol-test", "")).ToNot(HaveKey("volumeSnapshotClassName"))
+		})
+	})
+
+	Describe("The forge.VolumeSnapshotStatus function", func() {
+		var snapshot unstructured.Unstructured
+
+		BeforeEach(func() {
+			snapshot = unstructured.Unstructured{Object: map[string]interface{}{}}
+		})
+
+		When("the status is not yet set", func() {
+			It("Should return an empty state", func() {
+				Expect(forge.VolumeSnapshotStatus(&snapshot)).To(Equal(forge.VolumeSnapshotState{}))
+			})
+		})
+
+		When("the snapshot is ready", func() {
+			BeforeEach(func() {
+				snapshot.Object["status"] = map[string]interface{}{
+					"readyToUse":   true,
+					"creationTime": "2025-01-01T10:00:00Z",
+					"restoreSize":  "2Gi",
+				}
+			})
+
+			It("Should return the corresponding state", func() {
+				state := forge.VolumeSnapshotStatus(&snapshot)
+				Expect(state.ReadyToUse).To(BeTrue())
+				Expect(state.CreationTime.Time).To(Equal(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)))
+				Expect(state.RestoreSize.String()).To(Equal("2Gi"))
+				Expect(state.Error).To(BeEmpty())
+			})
+		})
+
+		When("the snapshot failed", func() {
+			BeforeEach(func() {
+				snapshot.Object["status"] = map[string]interface{}{
+					"readyToUse":  false,
+					"restoreSize": "invalid",
+					"error":       map[string]interface{}{"message": "snapshot failed"},
+				}
+			})
+
+			It("Should return the error, ignoring the malformed fields", func() {
+				state := forge.VolumeSnapshotStatus(&snapshot)
+				Expect(state.ReadyToUse).To(BeFalse())
+				Expect(state.RestoreSize).To(BeNil())
+				Expect(state.Error).To(Equal("snapshot failed"))
+			})
+		})
+	})
+
+	Describe("The forge.ScheduledSharedVolumeSnapshot function", func() {
+		It("Should forge the snapshot of the given shared volume", func() {
+			shvol := clv1alpha2.SharedVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "material", Namespace: "workspace-netgroup"},
+				Spec: clv1alpha2.SharedVolumeSpec{BackupSchedule: &clv1alpha2.SharedVolumeBackupSchedule{
+					Schedule: "0 3 * * *", VolumeSnapshotClassName: "csi-snapclass",
+				}},
+			}
+
+			snapshot := forge.ScheduledSharedVolumeSnapshot(&shvol, time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC))
+			Expect(snapshot.GetName()).To(Equal("material-20250101t030000"))
+			Expect(snapshot.GetNamespace()).To(Equal("workspace-netgroup"))
+			Expect(snapshot.GetLabels()).To(HaveKeyWithValue(forge.SharedVolumeScheduledBackupLabel, "material"))
+			Expect(snapshot.Spec).To(Equal(clv1alpha2.SharedVolumeSnapshotSpec{
+				SharedVolumeRef:         clv1alpha2.GenericRef{Name: "material"},
+				VolumeSnapshotClassName: "csi-snapclass",
+			}))
+		})
+	})
+
+	Describe("The forge.SharedVolumeRestorePVCSpec function", func() {
+		It("Should forge a PVC populated from the given snapshot", func() {
+			spec := forge.SharedVolumeRestorePVCSpec(ptr.To("rook-nfs"), resource.MustParse("2Gi"), "shvolsnap-test")
+			Expect(spec.AccessModes).To(ConsistOf(corev1.ReadWriteMany))
+			Expect(spec.StorageClassName).To(HaveValue(Equal("rook-nfs")))
+			Expect(spec.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceStorage, resource.MustParse("2Gi")))
+			Expect(spec.DataSource).To(Equal(&corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To("snapshot.storage.k8s.io"), Kind: "VolumeSnapshot", Name: "shvolsnap-test",
+			}))
+		})
+	})
+
+	Describe("The forge.SharedVolumeRestoreJobSpec function", func() {
+		It("Should mount the source PVC in read-only mode, and the target one in read-write mode", func() {
+			spec := forge.SharedVolumeRestoreJobSpec("shvolrestore-test", "shvol-test")
+			Expect(spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			Expect(spec.Template.Spec.Containers).To(HaveLen(1))
+			Expect(spec.Template.Spec.Volumes).To(ConsistOf(
+				corev1.Volume{Name: "source", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shvolrestore-test", ReadOnly: true}}},
+				corev1.Volume{Name: "target", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shvol-test"}}},
+			))
+			Expect(spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(HaveField("ReadOnly", true)))
+		})
+	})
+})
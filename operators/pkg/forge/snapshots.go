@@ -0,0 +1,88 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+const (
+	// SnapshotTemplateLabel -> the label identifying the templates created from an InstanceSnapshot, whose value is the name of the snapshot.
+	SnapshotTemplateLabel = "crownlabs.polito.it/instance-snapshot"
+)
+
+// SnapshotTemplateNamespacedName returns the namespace/name pair of the template to be created from the given InstanceSnapshot.
+func SnapshotTemplateNamespacedName(isnap *clv1alpha2.InstanceSnapshot) types.NamespacedName {
+	workspace := v1alpha1.Workspace{ObjectMeta: metav1.ObjectMeta{Name: isnap.Spec.CreateTemplate.WorkspaceRef.Name}}
+	name := isnap.Spec.CreateTemplate.Name
+	if name == "" {
+		name = isnap.GetName()
+	}
+	return types.NamespacedName{Namespace: GetWorkspaceNamespaceName(&workspace), Name: name}
+}
+
+// IsSnapshotTemplate returns whether the given template has been created from an InstanceSnapshot.
+func IsSnapshotTemplate(template *clv1alpha2.Template) bool {
+	_, found := template.GetLabels()[SnapshotTemplateLabel]
+	return found
+}
+
+// ConfigureSnapshotTemplate configures the given template to refer to the image produced by the given InstanceSnapshot,
+// deriving the environment from the snapshotted one. The other fields of an existing template are preserved.
+func ConfigureSnapshotTemplate(template *clv1alpha2.Template, isnap *clv1alpha2.InstanceSnapshot, source *clv1alpha2.Environment) {
+	labels := deepCopyLabels(template.GetLabels())
+	labels[SnapshotTemplateLabel] = isnap.GetName()
+	template.SetLabels(labels)
+
+	template.Spec.WorkspaceRef = clv1alpha2.GenericRef{Name: isnap.Spec.CreateTemplate.WorkspaceRef.Name}
+	template.Spec.PrettyName = isnap.Spec.CreateTemplate.PrettyName
+	template.Spec.Description = isnap.Spec.CreateTemplate.Description
+	if template.Spec.Description == "" {
+		template.Spec.Description = fmt.Sprintf("Template created from the snapshot %s of instance %s", isnap.GetName(), isnap.Spec.Instance.Name)
+	}
+	if template.Spec.DeleteAfter == "" {
+		template.Spec.DeleteAfter = LifetimeNever
+	}
+
+	template.Spec.EnvironmentList = []clv1alpha2.Environment{SnapshotEnvironment(source, isnap.Status.ImageRef)}
+}
+
+// SnapshotEnvironment returns a copy of the given (snapshotted) environment, configured to refer to the given image.
+func SnapshotEnvironment(source *clv1alpha2.Environment, image string) clv1alpha2.Environment {
+	environment := *source.DeepCopy()
+	environment.Image = image
+
+	switch environment.EnvironmentType {
+	case clv1alpha2.ClassCloudVM:
+		// The disk of the VM has been converted into a container disk image.
+		environment.EnvironmentType = clv1alpha2.ClassVM
+	case clv1alpha2.ClassContainer, clv1alpha2.ClassStandalone:
+		// The content of the volume is included in the image, hence it is no longer downloaded,
+		// but copied into the volume mounted on top of it.
+		if environment.ContainerStartupOptions == nil {
+			environment.ContainerStartupOptions = &clv1alpha2.ContainerStartupOpts{}
+		}
+		environment.ContainerStartupOptions.SourceArchiveURL = ""
+		environment.ContainerStartupOptions.InitializeFromImage = true
+	}
+
+	return environment
+}
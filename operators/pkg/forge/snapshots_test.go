@@ -0,0 +1,136 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("Snapshot templates forging", func() {
+	const image = "registry.crownlabs.polito.it/tester/snapshot:20250101t100000"
+
+	var (
+		isnap  clv1alpha2.InstanceSnapshot
+		source clv1alpha2.Environment
+	)
+
+	BeforeEach(func() {
+		isnap = clv1alpha2.InstanceSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: "tenant-tester"},
+			Spec: clv1alpha2.InstanceSnapshotSpec{
+				Instance:  clv1alpha2.GenericRef{Name: "kubernetes-0000", Namespace: "tenant-tester"},
+				ImageName: "snapshot",
+				CreateTemplate: &clv1alpha2.SnapshotTemplate{
+					WorkspaceRef: clv1alpha2.GenericRef{Name: "netgroup"},
+					PrettyName:   "Prepared environment",
+				},
+			},
+			Status: clv1alpha2.InstanceSnapshotStatus{ImageRef: image},
+		}
+
+		source = clv1alpha2.Environment{
+			Name:            "app",
+			Image:           "crownlabs/vscode:v1",
+			EnvironmentType: clv1alpha2.ClassContainer,
+			Mode:            clv1alpha2.ModeExam,
+			Persistent:      true,
+			Resources: clv1alpha2.EnvironmentResources{
+				CPU: 2, ReservedCPUPercentage: 50, Memory: resource.MustParse("2Gi"), Disk: resource.MustParse("10Gi"),
+			},
+			ContainerStartupOptions: &clv1alpha2.ContainerStartupOpts{SourceArchiveURL: "https://example.com/content.tar.gz", ContentPath: "/workspace"},
+			SharedVolumeMounts: []clv1alpha2.SharedVolumeMountInfo{{
+				SharedVolumeRef: clv1alpha2.GenericRef{Name: "data", Namespace: "workspace-netgroup"}, MountPath: "/mnt/data", ReadOnly: true,
+			}},
+		}
+	})
+
+	Describe("The forge.SnapshotTemplateNamespacedName function", func() {
+		It("Should default to the name of the snapshot, in the workspace namespace", func() {
+			Expect(forge.SnapshotTemplateNamespacedName(&isnap)).To(Equal(types.NamespacedName{Namespace: "workspace-netgroup", Name: "snapshot"}))
+		})
+
+		It("Should use the requested name, if specified", func() {
+			isnap.Spec.CreateTemplate.Name = "prepared"
+			Expect(forge.SnapshotTemplateNamespacedName(&isnap)).To(Equal(types.NamespacedName{Namespace: "workspace-netgroup", Name: "prepared"}))
+		})
+	})
+
+	Describe("The forge.SnapshotEnvironment function", func() {
+		It("Should copy the container environment, initializing the volume from the image", func() {
+			environment := forge.SnapshotEnvironment(&source, image)
+
+			expected := *source.DeepCopy()
+			expected.Image = image
+			expected.ContainerStartupOptions = &clv1alpha2.ContainerStartupOpts{ContentPath: "/workspace", InitializeFromImage: true}
+			Expect(environment).To(Equal(expected))
+		})
+
+		It("Should not modify the source environment", func() {
+			forge.SnapshotEnvironment(&source, image)
+			Expect(source.Image).To(Equal("crownlabs/vscode:v1"))
+			Expect(source.ContainerStartupOptions.InitializeFromImage).To(BeFalse())
+		})
+
+		It("Should convert CloudVMs into VMs", func() {
+			source.EnvironmentType = clv1alpha2.ClassCloudVM
+			source.ContainerStartupOptions = nil
+			environment := forge.SnapshotEnvironment(&source, image)
+			Expect(environment.EnvironmentType).To(Equal(clv1alpha2.ClassVM))
+			Expect(environment.Image).To(Equal(image))
+			Expect(environment.ContainerStartupOptions).To(BeNil())
+		})
+	})
+
+	Describe("The forge.ConfigureSnapshotTemplate function", func() {
+		var template clv1alpha2.Template
+
+		BeforeEach(func() {
+			template = clv1alpha2.Template{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "bar"}}}
+		})
+
+		JustBeforeEach(func() {
+			forge.ConfigureSnapshotTemplate(&template, &isnap, &source)
+		})
+
+		It("Should configure the template", func() {
+			Expect(template.GetLabels()).To(Equal(map[string]string{"foo": "bar", forge.SnapshotTemplateLabel: "snapshot"}))
+			Expect(forge.IsSnapshotTemplate(&template)).To(BeTrue())
+			Expect(template.Spec.WorkspaceRef).To(Equal(clv1alpha2.GenericRef{Name: "netgroup"}))
+			Expect(template.Spec.PrettyName).To(Equal("Prepared environment"))
+			Expect(template.Spec.Description).To(ContainSubstring("snapshot"))
+			Expect(template.Spec.DeleteAfter).To(Equal(forge.LifetimeNever))
+			Expect(template.Spec.EnvironmentList).To(ConsistOf(forge.SnapshotEnvironment(&source, image)))
+		})
+
+		When("the template already exists", func() {
+			BeforeEach(func() {
+				template.Spec.DeleteAfter = "7d"
+				template.Spec.IdleTimeout = "2h"
+			})
+
+			It("Should preserve the other fields", func() {
+				Expect(template.Spec.DeleteAfter).To(Equal("7d"))
+				Expect(template.Spec.IdleTimeout).To(Equal("2h"))
+			})
+		})
+	})
+})
@@ -0,0 +1,104 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+const (
+	// SSHTenantPrincipalPrefix -> the prefix of the SSH certificate principals identifying a tenant.
+	SSHTenantPrincipalPrefix = "tenant:"
+	// SSHInstancePrincipalPrefix -> the prefix of the SSH certificate principals granting access to an instance.
+	SSHInstancePrincipalPrefix = "instance:"
+)
+
+// SSHTenantPrincipal returns the principal identifying the given tenant in the SSH certificates,
+// which is accepted by the bastion to let the tenant reach its instances.
+func SSHTenantPrincipal(tenantName string) string {
+	return SSHTenantPrincipalPrefix + tenantName
+}
+
+// SSHInstancePrincipal returns the principal granting access to the given instance in the SSH certificates,
+// which is accepted by the environments of the instance.
+func SSHInstancePrincipal(instance *clv1alpha2.Instance) string {
+	return SSHInstancePrincipalPrefix + instance.GetNamespace() + "/" + instance.GetName()
+}
+
+// ParseSSHPublicKey parses a public key of a tenant, in authorized_keys format. The keys carrying
+// options, or followed by further entries, are rejected, as they would allow to escape the
+// restrictions applied when the key is installed (e.g., on the bastion).
+func ParseSSHPublicKey(entry string) (ssh.PublicKey, error) {
+	key, _, options, rest, err := ssh.ParseAuthorizedKey([]byte(entry))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(options) > 0 {
+		return nil, errors.New("public keys must not carry authorized_keys options")
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, errors.New("each entry must contain a single public key")
+	}
+	return key, nil
+}
+
+// SSHHostKey is the host key installed in a VM through cloud-init, in place of the ones generated at boot time,
+// so that it is known in advance and can be verified by the bastion proxy.
+type SSHHostKey struct {
+	// PrivateKey is the private key, in OpenSSH PEM format.
+	PrivateKey string
+	// PublicKey is the public key, in authorized_keys format.
+	PublicKey string
+}
+
+// GenerateSSHHostKey generates a new ed25519 host key.
+func GenerateSSHHostKey() (*SSHHostKey, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSSHHostKey(pem.EncodeToMemory(block))
+}
+
+// ParseSSHHostKey parses a private ed25519 host key, in OpenSSH PEM format.
+func ParseSSHHostKey(privateKey []byte) (*SSHHostKey, error) {
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host key: %w", err)
+	}
+	if signer.PublicKey().Type() != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("invalid host key: unsupported type %v", signer.PublicKey().Type())
+	}
+
+	return &SSHHostKey{
+		PrivateKey: string(privateKey),
+		PublicKey:  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))),
+	}, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("SSH principals forging", func() {
+	It("Should forge the principal of a tenant", func() {
+		Expect(forge.SSHTenantPrincipal("s12345")).To(Equal("tenant:s12345"))
+	})
+
+	It("Should forge the principal of an instance", func() {
+		instance := clv1alpha2.Instance{ObjectMeta: metav1.ObjectMeta{Name: "kubernetes-0000", Namespace: "tenant-s12345"}}
+		Expect(forge.SSHInstancePrincipal(&instance)).To(Equal("instance:tenant-s12345/kubernetes-0000"))
+	})
+})
+
+var _ = Describe("SSH public keys parsing", func() {
+	const key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILTt8yqcYTpoHYckGUirDXAVbKUiURms7F9xsz6Z7HXK"
+
+	DescribeTable("Should accept the valid public keys",
+		func(entry string) {
+			parsed, err := forge.ParseSSHPublicKey(entry)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Type()).To(Equal("ssh-ed25519"))
+		},
+		Entry("without comment", key),
+		Entry("with a comment", key+" john@laptop"),
+		Entry("with a trailing newline", key+" john@laptop\n"),
+	)
+
+	DescribeTable("Should reject the invalid public keys",
+		func(entry, expected string) {
+			_, err := forge.ParseSSHPublicKey(entry)
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("when not a key", "invalid_entry", "invalid public key"),
+		Entry("when the key is corrupted", "ssh-ed25519 publicKeyString comment", "invalid public key"),
+		Entry("when carrying options", `command="/bin/sh",no-pty `+key, "must not carry authorized_keys options"),
+		Entry("when followed by further entries", key+" comment\n"+key+" injected", "single public key"),
+	)
+})
+
+var _ = Describe("SSH host keys generation", func() {
+	It("Should generate an ed25519 host key", func() {
+		hostKey, err := forge.GenerateSSHHostKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		signer, err := ssh.ParsePrivateKey([]byte(hostKey.PrivateKey))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hostKey.PublicKey).To(HavePrefix("ssh-ed25519 "))
+		Expect(hostKey.PublicKey + "\n").To(BeEquivalentTo(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	})
+
+	It("Should parse a previously generated host key", func() {
+		hostKey, err := forge.GenerateSSHHostKey()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(forge.ParseSSHHostKey([]byte(hostKey.PrivateKey))).To(Equal(hostKey))
+	})
+
+	It("Should reject the host keys which are not valid", func() {
+		_, err := forge.ParseSSHHostKey([]byte("invalid"))
+		Expect(err).To(MatchError(ContainSubstring("invalid host key")))
+	})
+
+	It("Should reject the host keys of other types", func() {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		block, err := ssh.MarshalPrivateKey(privateKey, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = forge.ParseSSHHostKey(pem.EncodeToMemory(block))
+		Expect(err).To(MatchError(ContainSubstring("unsupported type")))
+	})
+})
@@ -44,6 +44,27 @@ func UpdateTenantResourceCommonLabels(labels map[string]string, targetLabel comm
 	return labels
 }
 
+// TenantWorkspaceRole returns the role of the tenant in the given workspace, and whether it is subscribed to it.
+func TenantWorkspaceRole(tenant *v1alpha2.Tenant, workspaceName string) (v1alpha2.WorkspaceUserRole, bool) {
+	for i := range tenant.Spec.Workspaces {
+		if tenant.Spec.Workspaces[i].Name == workspaceName {
+			return tenant.Spec.Workspaces[i].Role, true
+		}
+	}
+	return "", false
+}
+
+// TenantCanAccessInstance returns whether the tenant is allowed to connect to the given instance,
+// i.e., in case it owns the instance, or it is a manager or an assistant of the corresponding workspace.
+func TenantCanAccessInstance(tenant *v1alpha2.Tenant, instance *v1alpha2.Instance) bool {
+	if instance.Spec.Tenant.Name == tenant.Name {
+		return true
+	}
+
+	role, _ := TenantWorkspaceRole(tenant, InstanceWorkspaceName(instance))
+	return role == v1alpha2.Manager || role == v1alpha2.Assistant
+}
+
 // CleanTenantName sanitizes a tenant name by replacing spaces with underscores and removing
 // any characters that are not alphanumeric or underscores. It also trims leading
 // and trailing underscores.
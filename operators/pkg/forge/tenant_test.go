@@ -237,4 +237,44 @@ var _ = Describe("Tenant forging", func() {
 			Expect(resultLabels).To(HaveKeyWithValue("crownlabs.polito.it/managed-by", "tenant"))
 		})
 	})
+
+	Describe("The forge.TenantCanAccessInstance function", func() {
+		var tenant v1alpha2.Tenant
+		var instance v1alpha2.Instance
+
+		BeforeEach(func() {
+			tenant = v1alpha2.Tenant{
+				ObjectMeta: metav1.ObjectMeta{Name: "tester"},
+				Spec: v1alpha2.TenantSpec{Workspaces: []v1alpha2.TenantWorkspaceEntry{
+					{Name: "netgroup", Role: v1alpha2.Manager},
+					{Name: "sid", Role: v1alpha2.User},
+				}},
+			}
+			instance = v1alpha2.Instance{
+				ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "tenant-owner",
+					Labels: map[string]string{"crownlabs.polito.it/workspace": "netgroup"}},
+				Spec: v1alpha2.InstanceSpec{Tenant: v1alpha2.GenericRef{Name: "owner"}},
+			}
+		})
+
+		It("Should allow the owner of the instance", func() {
+			instance.Spec.Tenant.Name = tenant.Name
+			instance.Labels["crownlabs.polito.it/workspace"] = "other"
+			Expect(forge.TenantCanAccessInstance(&tenant, &instance)).To(BeTrue())
+		})
+
+		It("Should allow a manager of the workspace of the instance", func() {
+			Expect(forge.TenantCanAccessInstance(&tenant, &instance)).To(BeTrue())
+		})
+
+		It("Should not allow a user of the workspace of the instance", func() {
+			instance.Labels["crownlabs.polito.it/workspace"] = "sid"
+			Expect(forge.TenantCanAccessInstance(&tenant, &instance)).To(BeFalse())
+		})
+
+		It("Should not allow a manager of a different workspace", func() {
+			instance.Labels["crownlabs.polito.it/workspace"] = "other"
+			Expect(forge.TenantCanAccessInstance(&tenant, &instance)).To(BeFalse())
+		})
+	})
 })
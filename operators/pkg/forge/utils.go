@@ -69,6 +69,34 @@ func NamespacedNameWithSuffix(instance *clv1alpha2.Instance, suffix string) type
 	}
 }
 
+// EnvironmentObjectMeta returns the namespace/name pair given an instance object and one of its environments.
+func EnvironmentObjectMeta(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) metav1.ObjectMeta {
+	return NamespacedNameToObjectMeta(EnvironmentNamespacedName(instance, environment))
+}
+
+// EnvironmentObjectMetaWithSuffix returns the namespace/name pair given an instance object, one of its environments and a name suffix.
+func EnvironmentObjectMetaWithSuffix(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment, suffix string) metav1.ObjectMeta {
+	return NamespacedNameToObjectMeta(EnvironmentNamespacedNameWithSuffix(instance, environment, suffix))
+}
+
+// EnvironmentNamespacedName returns the namespace/name pair given an instance object and one of its environments.
+// In case of single-environment instances, the name corresponds to the one of the instance (for backward compatibility),
+// while it is suffixed with the name of the environment in case of multi-environment instances.
+func EnvironmentNamespacedName(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) types.NamespacedName {
+	return types.NamespacedName{
+		Name:      environmentCanonicalName(instance, environment),
+		Namespace: instance.GetNamespace(),
+	}
+}
+
+// EnvironmentNamespacedNameWithSuffix returns the namespace/name pair given an instance object, one of its environments and a name suffix.
+func EnvironmentNamespacedNameWithSuffix(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment, suffix string) types.NamespacedName {
+	return types.NamespacedName{
+		Name:      environmentCanonicalName(instance, environment) + StringSeparator + suffix,
+		Namespace: instance.GetNamespace(),
+	}
+}
+
 // NamespacedNameToObjectMeta returns the ObjectMeta corresponding to a NamespacedName.
 func NamespacedNameToObjectMeta(namespacedName types.NamespacedName) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
@@ -99,6 +127,14 @@ func canonicalName(name string) string {
 	return strings.ReplaceAll(name, ".", StringSeparator)
 }
 
+// environmentCanonicalName returns the canonical name of the objects associated with a given environment of the instance.
+func environmentCanonicalName(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) string {
+	if !IsMultiEnvironment(instance) {
+		return canonicalName(instance.GetName())
+	}
+	return canonicalName(instance.GetName()) + StringSeparator + canonicalName(environment.Name)
+}
+
 // CanonicalSandboxName returns a name given a tenant name.
 func CanonicalSandboxName(name string) string {
 	return fmt.Sprintf("sandbox-%s", canonicalName(name))
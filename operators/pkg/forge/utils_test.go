@@ -130,6 +130,43 @@ var _ = Describe("Utils forging", func() {
 		)
 	})
 
+	Describe("The forge.EnvironmentNamespacedName function", func() {
+		type EnvironmentNamespacedNameCase struct {
+			InstanceName     string
+			MultiEnvironment bool
+			EnvironmentName  string
+			ExpectedOutput   types.NamespacedName
+		}
+
+		DescribeTable("Correctly returns the expected namespaced name",
+			func(c EnvironmentNamespacedNameCase) {
+				instance := ForgeInstance("workspace-netgroup", c.InstanceName)
+				if c.MultiEnvironment {
+					instance.SetLabels(map[string]string{forge.InstanceMultiEnvironmentLabel: "true"})
+				}
+				environment := &clv1alpha2.Environment{Name: c.EnvironmentName}
+				Expect(forge.EnvironmentNamespacedName(instance, environment)).To(Equal(c.ExpectedOutput))
+			},
+			Entry("When the instance is composed of a single environment", EnvironmentNamespacedNameCase{
+				InstanceName:    "kuber.netes.1234",
+				EnvironmentName: "control-plane",
+				ExpectedOutput:  types.NamespacedName{Namespace: "workspace-netgroup", Name: "kuber-netes-1234"},
+			}),
+			Entry("When the instance is composed of multiple environments", EnvironmentNamespacedNameCase{
+				InstanceName:     "kuber.netes.1234",
+				MultiEnvironment: true,
+				EnvironmentName:  "control-plane",
+				ExpectedOutput:   types.NamespacedName{Namespace: "workspace-netgroup", Name: "kuber-netes-1234-control-plane"},
+			}),
+			Entry("When the environment name does contain dots", EnvironmentNamespacedNameCase{
+				InstanceName:     "kubernetes-1234",
+				MultiEnvironment: true,
+				EnvironmentName:  "worker.1",
+				ExpectedOutput:   types.NamespacedName{Namespace: "workspace-netgroup", Name: "kubernetes-1234-worker-1"},
+			}),
+		)
+	})
+
 	Describe("The forge.NamespacedNameToObjectMeta function", func() {
 		var (
 			namespacedName types.NamespacedName
@@ -54,11 +54,11 @@ var (
 func VirtualMachineSpec(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) virtv1.VirtualMachineSpec {
 	return virtv1.VirtualMachineSpec{
 		Template: &virtv1.VirtualMachineInstanceTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: InstanceSelectorLabels(instance)},
+			ObjectMeta: metav1.ObjectMeta{Labels: EnvironmentSelectorLabels(instance, environment)},
 			Spec:       VirtualMachineInstanceSpec(instance, environment),
 		},
 		DataVolumeTemplates: []virtv1.DataVolumeTemplateSpec{
-			DataVolumeTemplate(NamespacedName(instance).Name, environment),
+			DataVolumeTemplate(EnvironmentNamespacedName(instance, environment).Name, environment),
 		},
 	}
 }
@@ -67,10 +67,10 @@ func VirtualMachineSpec(instance *clv1alpha2.Instance, environment *clv1alpha2.E
 // object representing the definition of the VMI corresponding to a non-persistent CrownLabs Environment.
 func VirtualMachineInstanceSpec(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) virtv1.VirtualMachineInstanceSpec {
 	return virtv1.VirtualMachineInstanceSpec{
-		Domain:                        VirtualMachineDomain(environment),
+		Domain:                        VirtualMachineDomain(instance, environment),
 		Volumes:                       Volumes(instance, environment),
 		ReadinessProbe:                VirtualMachineReadinessProbe(environment),
-		Networks:                      []virtv1.Network{*virtv1.DefaultPodNetwork()},
+		Networks:                      VirtualMachineNetworks(instance),
 		TerminationGracePeriodSeconds: ptr.To[int64](terminationGracePeriod),
 		NodeSelector:                  NodeSelectorLabels(instance, environment),
 	}
@@ -78,24 +78,50 @@ func VirtualMachineInstanceSpec(instance *clv1alpha2.Instance, environment *clv1
 
 // VirtualMachineDomain forges the specification of the domain of a Kubevirt VirtualMachineInstance
 // object representing the definition of the VM corresponding to a given CrownLabs Environment.
-func VirtualMachineDomain(environment *clv1alpha2.Environment) virtv1.DomainSpec {
+func VirtualMachineDomain(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) virtv1.DomainSpec {
 	return virtv1.DomainSpec{
 		CPU:       &virtv1.CPU{Cores: environment.Resources.CPU},
 		Memory:    &virtv1.Memory{Guest: &environment.Resources.Memory},
 		Resources: VirtualMachineResources(environment),
 		Devices: virtv1.Devices{
 			Disks:      VolumeDiskTargets(environment),
-			Interfaces: []virtv1.Interface{*virtv1.DefaultBridgeNetworkInterface()},
+			Interfaces: VirtualMachineInterfaces(instance),
 		},
 	}
 }
 
+// VirtualMachineNetworks forges the list of networks the VM corresponding to a given CrownLabs Environment is attached to.
+func VirtualMachineNetworks(instance *clv1alpha2.Instance) []virtv1.Network {
+	networks := []virtv1.Network{*virtv1.DefaultPodNetwork()}
+	if HasPrivateNetwork(instance) {
+		networks = append(networks, virtv1.Network{
+			Name: PrivateNetworkName,
+			NetworkSource: virtv1.NetworkSource{
+				Multus: &virtv1.MultusNetwork{NetworkName: PrivateNetworkNamespacedName(instance).Name},
+			},
+		})
+	}
+	return networks
+}
+
+// VirtualMachineInterfaces forges the list of network interfaces of the VM corresponding to a given CrownLabs Environment.
+func VirtualMachineInterfaces(instance *clv1alpha2.Instance) []virtv1.Interface {
+	interfaces := []virtv1.Interface{*virtv1.DefaultBridgeNetworkInterface()}
+	if HasPrivateNetwork(instance) {
+		interfaces = append(interfaces, virtv1.Interface{
+			Name:                   PrivateNetworkName,
+			InterfaceBindingMethod: virtv1.InterfaceBindingMethod{Bridge: &virtv1.InterfaceBridge{}},
+		})
+	}
+	return interfaces
+}
+
 // Volumes forges the array of volumes to be mounted onto the VMI specification.
 func Volumes(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) []virtv1.Volume {
 	volumes := []virtv1.Volume{VolumeRootDisk(instance, environment)}
 	// Attach cloudinit volume on non-restricted environments
 	if environment.Mode == clv1alpha2.ModeStandard {
-		volumes = append(volumes, VolumeCloudInit(NamespacedName(instance).Name))
+		volumes = append(volumes, VolumeCloudInit(EnvironmentNamespacedName(instance, environment).Name))
 	}
 	return volumes
 }
@@ -104,7 +130,7 @@ func Volumes(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment)
 // the environment characteristics.
 func VolumeRootDisk(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) virtv1.Volume {
 	if environment.Persistent {
-		return VolumePersistentDisk(NamespacedName(instance).Name)
+		return VolumePersistentDisk(EnvironmentNamespacedName(instance, environment).Name)
 	}
 	return VolumeContainerDisk(environment.Image)
 }
@@ -87,7 +87,7 @@ var _ = Describe("VirtualMachines and VirtualMachineInstances forging", func() {
 		})
 
 		It("Should set the correct domain", func() {
-			Expect(spec.Domain).To(Equal(forge.VirtualMachineDomain(&environment)))
+			Expect(spec.Domain).To(Equal(forge.VirtualMachineDomain(&instance, &environment)))
 		})
 		It("Should set the cloud-init volumes", func() {
 			Expect(spec.Volumes).To(ContainElement(forge.VolumeCloudInit(forge.NamespacedName(&instance).Name)))
@@ -98,6 +98,24 @@ var _ = Describe("VirtualMachines and VirtualMachineInstances forging", func() {
 		It("Should set the correct networks", func() {
 			Expect(spec.Networks).To(ContainElement(*virtv1.DefaultPodNetwork()))
 		})
+
+		When("the instance is attached to a private network", func() {
+			BeforeEach(func() {
+				instance.SetLabels(map[string]string{forge.InstancePrivateNetworkLabel: "true"})
+			})
+			It("Should attach the VM to the private network", func() {
+				Expect(spec.Networks).To(ContainElement(virtv1.Network{
+					Name: "lab",
+					NetworkSource: virtv1.NetworkSource{
+						Multus: &virtv1.MultusNetwork{NetworkName: forge.PrivateNetworkNamespacedName(&instance).Name},
+					},
+				}))
+				Expect(spec.Domain.Devices.Interfaces).To(ContainElement(virtv1.Interface{
+					Name:                   "lab",
+					InterfaceBindingMethod: virtv1.InterfaceBindingMethod{Bridge: &virtv1.InterfaceBridge{}},
+				}))
+			})
+		})
 		It("Should set the correct termination grace period", func() {
 			Expect(*spec.TerminationGracePeriodSeconds).To(BeNumerically("==", 60))
 		})
@@ -121,7 +139,7 @@ var _ = Describe("VirtualMachines and VirtualMachineInstances forging", func() {
 		var domain virtv1.DomainSpec
 
 		JustBeforeEach(func() {
-			domain = forge.VirtualMachineDomain(&environment)
+			domain = forge.VirtualMachineDomain(&instance, &environment)
 		})
 
 		It("Should set the correct CPU value", func() {
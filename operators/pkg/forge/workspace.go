@@ -0,0 +1,129 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+const (
+	// EndOfLifeSnapshotLabel -> the label identifying the InstanceSnapshots created at the end of life of a workspace, whose value is the name of the workspace.
+	EndOfLifeSnapshotLabel = "crownlabs.polito.it/end-of-life-workspace"
+	// WorkspaceForceDeleteAnnotation -> the annotation which, if set to "true", allows to delete a workspace
+	// even though instances of its templates or shared volumes still exist.
+	WorkspaceForceDeleteAnnotation = "crownlabs.polito.it/force-delete"
+)
+
+// WorkspaceEnded returns whether the given workspace reached the end of its active period at the given time.
+func WorkspaceEnded(ws *v1alpha1.Workspace, now time.Time) bool {
+	period := ws.Spec.ActivePeriod
+	return period != nil && period.End != nil && !now.Before(period.End.Time)
+}
+
+// WorkspaceStarted returns whether the given workspace reached the beginning of its active period at the given time.
+func WorkspaceStarted(ws *v1alpha1.Workspace, now time.Time) bool {
+	period := ws.Spec.ActivePeriod
+	return period == nil || period.Start == nil || !now.Before(period.Start.Time)
+}
+
+// IsWorkspaceActive returns whether new instances can be created in the given workspace at the given time,
+// that is it is within its active period and it has not been archived.
+func IsWorkspaceActive(ws *v1alpha1.Workspace, now time.Time) bool {
+	return WorkspaceStarted(ws, now) && !WorkspaceEnded(ws, now) && !ws.Status.Archived
+}
+
+// EndOfLifeSnapshotName returns the name of the InstanceSnapshot of the given environment, created at the end of life of the workspace.
+func EndOfLifeSnapshotName(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) string {
+	return fmt.Sprintf("%s-%s-eol", instance.GetName(), environment.Name)
+}
+
+// ConfigureEndOfLifeSnapshot configures the given InstanceSnapshot to snapshot the given environment of the instance,
+// at the end of life of the workspace it belongs to.
+func ConfigureEndOfLifeSnapshot(isnap *clv1alpha2.InstanceSnapshot, ws *v1alpha1.Workspace,
+	instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) {
+	labels := deepCopyLabels(isnap.GetLabels())
+	labels[EndOfLifeSnapshotLabel] = ws.GetName()
+	isnap.SetLabels(labels)
+
+	isnap.Spec.Instance = clv1alpha2.GenericRef{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+	isnap.Spec.Environment = clv1alpha2.GenericRef{Name: environment.Name}
+	isnap.Spec.ImageName = fmt.Sprintf("%s-%s", ws.GetName(), EndOfLifeSnapshotName(instance, environment))
+}
+
+// InvitationTokenHash returns the hex-encoded SHA-256 digest of the given invitation token, as stored in the workspace.
+func InvitationTokenHash(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+// WorkspaceInvitationForToken returns the invitation of the given workspace matching the given token, if any.
+func WorkspaceInvitationForToken(ws *v1alpha1.Workspace, token string) *v1alpha1.WorkspaceInvitation {
+	hash := []byte(InvitationTokenHash(token))
+	for i := range ws.Spec.Invitations {
+		if subtle.ConstantTimeCompare(hash, []byte(strings.ToLower(ws.Spec.Invitations[i].TokenHash))) == 1 {
+			return &ws.Spec.Invitations[i]
+		}
+	}
+	return nil
+}
+
+// IsInvitationExpired returns whether the given invitation can no longer be redeemed at the given time.
+func IsInvitationExpired(invitation *v1alpha1.WorkspaceInvitation, now time.Time) bool {
+	return invitation.ExpirationTime != nil && !now.Before(invitation.ExpirationTime.Time)
+}
+
+// WorkspaceInvitationUses returns the number of tenants enrolled through the given invitation, as recorded in the workspace status.
+func WorkspaceInvitationUses(ws *v1alpha1.Workspace, invitation string) int32 {
+	for i := range ws.Status.Invitations {
+		if ws.Status.Invitations[i].Name == invitation {
+			return ws.Status.Invitations[i].Uses
+		}
+	}
+	return 0
+}
+
+// RecordWorkspaceInvitationUse increments the number of uses of the given invitation in the workspace status.
+func RecordWorkspaceInvitationUse(ws *v1alpha1.Workspace, invitation string) {
+	for i := range ws.Status.Invitations {
+		if ws.Status.Invitations[i].Name == invitation {
+			ws.Status.Invitations[i].Uses++
+			return
+		}
+	}
+	ws.Status.Invitations = append(ws.Status.Invitations, v1alpha1.WorkspaceInvitationStatus{Name: invitation, Uses: 1})
+}
+
+// EmailDomainRuleMatches returns whether the given email address matches one of the email domain rules of the workspace.
+func EmailDomainRuleMatches(ws *v1alpha1.Workspace, email string) bool {
+	_, domain, found := strings.Cut(email, "@")
+	if !found {
+		return false
+	}
+
+	for i := range ws.Spec.EmailDomainRules {
+		if strings.EqualFold(strings.TrimPrefix(ws.Spec.EmailDomainRules[i].Domain, "@"), domain) {
+			return true
+		}
+	}
+	return false
+}
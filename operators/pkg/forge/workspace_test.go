@@ -0,0 +1,153 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clv1alpha1 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("Workspaces forging", func() {
+	now := time.Now()
+	past := &metav1.Time{Time: now.Add(-time.Hour)}
+	future := &metav1.Time{Time: now.Add(time.Hour)}
+
+	Describe("The forge.IsWorkspaceActive function", func() {
+		type IsWorkspaceActiveCase struct {
+			Period   *clv1alpha1.WorkspaceActivePeriod
+			Archived bool
+			Expected bool
+		}
+
+		DescribeTable("Correctly determines whether the workspace is active",
+			func(c IsWorkspaceActiveCase) {
+				ws := clv1alpha1.Workspace{
+					Spec:   clv1alpha1.WorkspaceSpec{ActivePeriod: c.Period},
+					Status: clv1alpha1.WorkspaceStatus{Archived: c.Archived},
+				}
+				Expect(forge.IsWorkspaceActive(&ws, now)).To(Equal(c.Expected))
+			},
+			Entry("When the active period is not configured", IsWorkspaceActiveCase{Expected: true}),
+			Entry("When within the active period", IsWorkspaceActiveCase{
+				Period: &clv1alpha1.WorkspaceActivePeriod{Start: past, End: future}, Expected: true,
+			}),
+			Entry("When the active period has not yet started", IsWorkspaceActiveCase{
+				Period: &clv1alpha1.WorkspaceActivePeriod{Start: future}, Expected: false,
+			}),
+			Entry("When the active period has ended", IsWorkspaceActiveCase{
+				Period: &clv1alpha1.WorkspaceActivePeriod{End: past}, Expected: false,
+			}),
+			Entry("When the workspace has been archived", IsWorkspaceActiveCase{Archived: true, Expected: false}),
+		)
+	})
+
+	Describe("The forge.ConfigureEndOfLifeSnapshot function", func() {
+		var isnap clv1alpha2.InstanceSnapshot
+
+		BeforeEach(func() {
+			ws := clv1alpha1.Workspace{ObjectMeta: metav1.ObjectMeta{Name: "netgroup"}}
+			instance := clv1alpha2.Instance{ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "tenant-tester"}}
+			environment := clv1alpha2.Environment{Name: "vm"}
+
+			isnap = clv1alpha2.InstanceSnapshot{}
+			forge.ConfigureEndOfLifeSnapshot(&isnap, &ws, &instance, &environment)
+		})
+
+		It("Should refer to the given environment of the instance", func() {
+			Expect(isnap.Spec.Instance).To(Equal(clv1alpha2.GenericRef{Name: "instance", Namespace: "tenant-tester"}))
+			Expect(isnap.Spec.Environment).To(Equal(clv1alpha2.GenericRef{Name: "vm"}))
+		})
+
+		It("Should configure an image name identifying the workspace", func() {
+			Expect(isnap.Spec.ImageName).To(Equal("netgroup-instance-vm-eol"))
+		})
+
+		It("Should configure the end-of-life label", func() {
+			Expect(isnap.GetLabels()).To(HaveKeyWithValue(forge.EndOfLifeSnapshotLabel, "netgroup"))
+		})
+	})
+
+	Describe("The forge.WorkspaceInvitationForToken function", func() {
+		var ws clv1alpha1.Workspace
+
+		BeforeEach(func() {
+			ws = clv1alpha1.Workspace{Spec: clv1alpha1.WorkspaceSpec{Invitations: []clv1alpha1.WorkspaceInvitation{
+				{Name: "first", TokenHash: forge.InvitationTokenHash("first-token")},
+				{Name: "second", TokenHash: strings.ToUpper(forge.InvitationTokenHash("second-token"))},
+			}}}
+		})
+
+		It("Should return the invitation matching the token", func() {
+			Expect(forge.WorkspaceInvitationForToken(&ws, "first-token")).To(PointTo(HaveField("Name", "first")))
+		})
+
+		It("Should ignore the case of the stored hash", func() {
+			Expect(forge.WorkspaceInvitationForToken(&ws, "second-token")).To(PointTo(HaveField("Name", "second")))
+		})
+
+		It("Should return nil if no invitation matches the token", func() {
+			Expect(forge.WorkspaceInvitationForToken(&ws, "other-token")).To(BeNil())
+		})
+	})
+
+	Describe("The forge.RecordWorkspaceInvitationUse function", func() {
+		var ws clv1alpha1.Workspace
+
+		BeforeEach(func() {
+			ws = clv1alpha1.Workspace{Status: clv1alpha1.WorkspaceStatus{Invitations: []clv1alpha1.WorkspaceInvitationStatus{
+				{Name: "first", Uses: 2},
+			}}}
+		})
+
+		It("Should increment the uses of an invitation already redeemed", func() {
+			forge.RecordWorkspaceInvitationUse(&ws, "first")
+			Expect(forge.WorkspaceInvitationUses(&ws, "first")).To(BeNumerically("==", 3))
+		})
+
+		It("Should record the first use of an invitation", func() {
+			Expect(forge.WorkspaceInvitationUses(&ws, "second")).To(BeZero())
+			forge.RecordWorkspaceInvitationUse(&ws, "second")
+			Expect(forge.WorkspaceInvitationUses(&ws, "second")).To(BeNumerically("==", 1))
+			Expect(forge.WorkspaceInvitationUses(&ws, "first")).To(BeNumerically("==", 2))
+		})
+	})
+
+	Describe("The forge.EmailDomainRuleMatches function", func() {
+		ws := clv1alpha1.Workspace{Spec: clv1alpha1.WorkspaceSpec{EmailDomainRules: []clv1alpha1.WorkspaceEmailDomainRule{
+			{Domain: "@studenti.polito.it"}, {Domain: "polito.it"},
+		}}}
+
+		DescribeTable("Correctly matches the email domain",
+			func(email string, expected bool) {
+				Expect(forge.EmailDomainRuleMatches(&ws, email)).To(Equal(expected))
+			},
+			Entry("When the domain matches a rule with the @ prefix", "s123456@studenti.polito.it", true),
+			Entry("When the domain matches a rule without the @ prefix", "john.doe@polito.it", true),
+			Entry("When the domain differs only in case", "john.doe@PoliTo.it", true),
+			Entry("When the domain is a subdomain of a rule", "john.doe@mail.polito.it", false),
+			Entry("When the domain does not match any rule", "john.doe@example.com", false),
+			Entry("When the email is not valid", "john.doe", false),
+		)
+	})
+})
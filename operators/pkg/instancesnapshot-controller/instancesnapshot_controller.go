@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package instancesnapshot_controller groups the functionalities related to the creation of a persistent VM snapshot.
+// Package instancesnapshot_controller groups the functionalities related to the creation of snapshots of persistent VMs and containers.
 package instancesnapshot_controller
 
 import (
@@ -29,13 +29,15 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlUtil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	crownlabsv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/registry"
 )
 
-// ContainersSnapshotOpts contains image names and tags of the containers needed for the VM snapshot, along with VM registry access data.
+// ContainersSnapshotOpts contains image names and tags of the containers needed for the snapshots, along with VM registry access data.
 type ContainersSnapshotOpts struct {
 	ContainerKaniko    string
 	ContainerImgExport string
@@ -43,6 +45,12 @@ type ContainersSnapshotOpts struct {
 	RegistrySecretName string
 }
 
+// ImageDeleter abstracts the interactions with the registry required to safely delete the images.
+type ImageDeleter interface {
+	Digest(ctx context.Context, ref registry.Reference, credentials registry.Credentials) (string, error)
+	DeleteImage(ctx context.Context, ref registry.Reference, digest string, credentials registry.Credentials) error
+}
+
 // InstanceSnapshotReconciler reconciles a InstanceSnapshot object.
 type InstanceSnapshotReconciler struct {
 	client.Client
@@ -51,6 +59,13 @@ type InstanceSnapshotReconciler struct {
 	NamespaceWhitelist metav1.LabelSelector
 	ContainersSnapshot ContainersSnapshotOpts
 
+	// The maximum number of completed snapshots retained for each instance, the oldest ones being
+	// deleted once exceeded. Zero means that snapshots are never deleted automatically.
+	KeepLast int
+	// The client used to delete the images of the deleted snapshots from the registry.
+	// If nil, the images are retained in the registry.
+	Registry ImageDeleter
+
 	// This function, if configured, is deferred at the beginning of the Reconcile.
 	// Specifically, it is meant to be set to GinkgoRecover during the tests,
 	// in order to lead to a controlled failure in case the Reconcile panics.
@@ -83,6 +98,16 @@ func (r *InstanceSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, nil
 	}
 
+	if !isnap.GetDeletionTimestamp().IsZero() {
+		if ctrlUtil.ContainsFinalizer(isnap, crownlabsv1alpha2.InstanceSnapshotImageFinalizerName) {
+			if err := r.HandleDeletion(ctx, isnap); err != nil {
+				klog.Error(err)
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
 	klog.Infof("Start InstanceSnapshot reconciliation of %s in %s namespace", isnap.Name, isnap.Namespace)
 
 	// Check the current status of the InstanceSnapshot by checking
@@ -95,6 +120,9 @@ func (r *InstanceSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	err := r.Get(ctx, jobName, found)
 
 	switch {
+	case err != nil && errors.IsNotFound(err) && isTerminated(isnap):
+		// The job has already been deleted after the termination of the snapshot.
+		found = nil
 	case err != nil && errors.IsNotFound(err):
 		if retry, err1 := r.CreateSnapshottingJob(ctx, isnap); err1 != nil {
 			klog.Error(err1)
@@ -112,6 +140,8 @@ func (r *InstanceSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	case err != nil:
 		klog.Errorf("Unable to retrieve the job of InstanceSnapshot %s -> %s", isnap.Name, err)
 		return ctrl.Result{}, err
+	case isTerminated(isnap):
+		// The outcome of the job has already been recorded.
 	default:
 		// Check the current state of the job and log according to its state
 		jstatus, err1 := r.HandleExistingJob(ctx, isnap, found)
@@ -137,6 +167,23 @@ func (r *InstanceSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		}
 	}
 
+	switch isnap.Status.Phase {
+	case crownlabsv1alpha2.Completed:
+		if err := r.EnforceSnapshotTemplate(ctx, isnap); err != nil {
+			klog.Error(err)
+			return ctrl.Result{}, err
+		}
+		if err := r.CleanupCompletedSnapshot(ctx, isnap, found); err != nil {
+			klog.Error(err)
+			return ctrl.Result{}, err
+		}
+	case crownlabsv1alpha2.Failed:
+		if err := r.DeleteSnapshotJob(ctx, isnap, found); err != nil {
+			klog.Error(err)
+			return ctrl.Result{}, err
+		}
+	}
+
 	return ctrl.Result{}, nil
 }
 
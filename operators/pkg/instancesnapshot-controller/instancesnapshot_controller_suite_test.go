@@ -88,6 +88,7 @@ var _ = BeforeSuite(func() {
 			ContainerKaniko:    "kaniko",
 			ContainerImgExport: "crownlabs/img-export",
 		},
+		KeepLast: 2,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
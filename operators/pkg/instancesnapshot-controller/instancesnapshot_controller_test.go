@@ -25,12 +25,17 @@ import (
 	batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	crownlabsv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	instancesnapshot_controller "github.com/netgroup-polito/CrownLabs/operators/pkg/instancesnapshot-controller"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/registry"
 )
 
 var _ = Describe("InstancesnapshotController", func() {
@@ -183,6 +188,20 @@ var _ = Describe("InstancesnapshotController", func() {
 
 			By("Checking if the InstanceSnapshot status is Completed")
 			checkIsnapStatus(ctx, newInstanceSnapshot.Name, WorkingNamespace, crownlabsv1alpha2.Completed, timeout, interval)
+
+			By("Checking that the InstanceSnapshot status reports the outcome of the job")
+			isnapLookupKey := types.NamespacedName{Name: newInstanceSnapshot.Name, Namespace: WorkingNamespace}
+			createdIsnap := &crownlabsv1alpha2.InstanceSnapshot{}
+			Expect(k8sClient.Get(ctx, isnapLookupKey, createdIsnap)).Should(Succeed())
+			Expect(createdIsnap.Status.ImageRef).To(HavePrefix("my-registry/testtenant/test-image:"))
+			Expect(createdIsnap.Status.StartTime).ToNot(BeNil())
+			Expect(createdIsnap.Status.CompletionTime).ToNot(BeNil())
+			Expect(createdIsnap.Status.FailureReason).To(BeEmpty())
+
+			By("Checking that the job has been deleted")
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, jobLookupKey, &batch.Job{}))
+			}, timeout, interval).Should(BeTrue())
 		})
 
 		It("Should start snapshot creation given an environment name", func() {
@@ -205,6 +224,33 @@ var _ = Describe("InstancesnapshotController", func() {
 		})
 	})
 
+	Context("Creating a snapshot of a persistent container", func() {
+		It("Should start snapshot creation, layering the content on the environment image", func() {
+			By("Getting current Template")
+			currentTemplate := &crownlabsv1alpha2.Template{}
+			templateLookupKey := types.NamespacedName{Name: TemplateName, Namespace: WorkingNamespace}
+			Expect(k8sClient.Get(ctx, templateLookupKey, currentTemplate)).Should(Succeed())
+
+			By("Setting environment as a persistent Container")
+			currentTemplate.Spec.EnvironmentList[0].EnvironmentType = crownlabsv1alpha2.ClassContainer
+			currentTemplate.Spec.EnvironmentList[0].Image = "crownlabs/vscode:v1"
+			Expect(k8sClient.Update(ctx, currentTemplate)).Should(Succeed())
+
+			newInstanceSnapshot := instanceSnapshot.DeepCopy()
+			newInstanceSnapshot.Name = fmt.Sprintf("isnap-sample-%v", rand.Int())
+			checkIsnapSuccessfulCreation(ctx, newInstanceSnapshot, WorkingNamespace, timeout, interval)
+
+			By("Checking the arguments of the exporter container")
+			jobLookupKey := types.NamespacedName{Name: newInstanceSnapshot.Name, Namespace: WorkingNamespace}
+			snapjob := &batch.Job{}
+			Expect(k8sClient.Get(ctx, jobLookupKey, snapjob)).Should(Succeed())
+			Expect(snapjob.Spec.Template.Spec.InitContainers).To(HaveLen(1))
+			Expect(snapjob.Spec.Template.Spec.InitContainers[0].Args).To(Equal([]string{
+				"--mode", "container", "--base-image", "crownlabs/vscode:v1", "--content-path", "/media/data", "--owner", "1010:1010",
+			}))
+		})
+	})
+
 	Context("Testing incorrect environment configurations", func() {
 		It("Should fail: the VM is running", func() {
 			By("Getting current instance")
@@ -236,14 +282,15 @@ var _ = Describe("InstancesnapshotController", func() {
 			checkIsnapCreationFailure(ctx, newInstanceSnapshot, WorkingNamespace, timeout, interval)
 		})
 
-		It("Should fail: environment is a container", func() {
+		It("Should fail: container is not persistent", func() {
 			By("Getting current Template")
 			currentTemplate := &crownlabsv1alpha2.Template{}
 			templateLookupKey := types.NamespacedName{Name: TemplateName, Namespace: WorkingNamespace}
 			Expect(k8sClient.Get(ctx, templateLookupKey, currentTemplate)).Should(Succeed())
 
-			By("Setting environment as Container")
+			By("Setting environment as a non persistent Container")
 			currentTemplate.Spec.EnvironmentList[0].EnvironmentType = crownlabsv1alpha2.ClassContainer
+			currentTemplate.Spec.EnvironmentList[0].Persistent = false
 			Expect(k8sClient.Update(ctx, currentTemplate)).Should(Succeed())
 
 			newInstanceSnapshot := instanceSnapshot.DeepCopy()
@@ -292,10 +339,216 @@ var _ = Describe("InstancesnapshotController", func() {
 
 			By("Checking if the InstanceSnapshot status is Failed")
 			checkIsnapStatus(ctx, newInstanceSnapshot.Name, WorkingNamespace, crownlabsv1alpha2.Failed, timeout, interval)
+
+			By("Checking that the failure reason is reported")
+			isnapLookupKey := types.NamespacedName{Name: newInstanceSnapshot.Name, Namespace: WorkingNamespace}
+			createdIsnap := &crownlabsv1alpha2.InstanceSnapshot{}
+			Expect(k8sClient.Get(ctx, isnapLookupKey, createdIsnap)).Should(Succeed())
+			Expect(createdIsnap.Status.FailureReason).ToNot(BeEmpty())
+
+			By("Checking that the failed job has been deleted")
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, jobLookupKey, &batch.Job{}))
+			}, timeout, interval).Should(BeTrue())
 		})
 	})
+
+	Context("Creating a template from the snapshot", func() {
+		It("Should create the template in the target workspace, once the snapshot is completed", func() {
+			By("Creating the namespace of the workspace")
+			workspaceNs := v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "workspace-snapshots"}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, &workspaceNs))).Should(Succeed())
+
+			newInstanceSnapshot := instanceSnapshot.DeepCopy()
+			newInstanceSnapshot.Name = fmt.Sprintf("isnap-template-%v", rand.Int())
+			newInstanceSnapshot.Spec.CreateTemplate = &crownlabsv1alpha2.SnapshotTemplate{
+				WorkspaceRef: crownlabsv1alpha2.GenericRef{Name: "snapshots"},
+				PrettyName:   "Prepared VM",
+			}
+			checkIsnapSuccessfulCreation(ctx, newInstanceSnapshot, WorkingNamespace, timeout, interval)
+
+			By("Changing the job status to completed")
+			jobLookupKey := types.NamespacedName{Name: newInstanceSnapshot.Name, Namespace: WorkingNamespace}
+			snapjob := &batch.Job{}
+			Expect(k8sClient.Get(ctx, jobLookupKey, snapjob)).Should(Succeed())
+			snapjob.Status.Conditions = []batch.JobCondition{
+				{Type: batch.JobComplete, Status: v1.ConditionTrue},
+			}
+			Expect(k8sClient.Status().Update(ctx, snapjob)).Should(Succeed())
+			checkIsnapStatus(ctx, newInstanceSnapshot.Name, WorkingNamespace, crownlabsv1alpha2.Completed, timeout, interval)
+
+			By("Checking that the template has been created")
+			createdIsnap := &crownlabsv1alpha2.InstanceSnapshot{}
+			isnapLookupKey := types.NamespacedName{Name: newInstanceSnapshot.Name, Namespace: WorkingNamespace}
+			Eventually(func() *crownlabsv1alpha2.GenericRef {
+				Expect(k8sClient.Get(ctx, isnapLookupKey, createdIsnap)).Should(Succeed())
+				return createdIsnap.Status.TemplateRef
+			}, timeout, interval).ShouldNot(BeNil())
+			Expect(*createdIsnap.Status.TemplateRef).To(Equal(crownlabsv1alpha2.GenericRef{Name: newInstanceSnapshot.Name, Namespace: "workspace-snapshots"}))
+
+			createdTemplate := &crownlabsv1alpha2.Template{}
+			templateLookupKey := types.NamespacedName{Name: newInstanceSnapshot.Name, Namespace: "workspace-snapshots"}
+			Expect(k8sClient.Get(ctx, templateLookupKey, createdTemplate)).Should(Succeed())
+			Expect(createdTemplate.Spec.PrettyName).To(Equal("Prepared VM"))
+			Expect(createdTemplate.Spec.WorkspaceRef.Name).To(Equal("snapshots"))
+			Expect(createdTemplate.Spec.EnvironmentList).To(HaveLen(1))
+			Expect(createdTemplate.Spec.EnvironmentList[0].Image).To(Equal(createdIsnap.Status.ImageRef))
+			Expect(createdTemplate.Spec.EnvironmentList[0].Resources).To(Equal(template.Spec.EnvironmentList[0].Resources))
+		})
+
+		It("Should not overwrite an existing template not created from a snapshot", func() {
+			By("Creating the namespace of the workspace")
+			workspaceNs := v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "workspace-snapshots"}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, &workspaceNs))).Should(Succeed())
+
+			By("Creating the conflicting template")
+			existing := template.DeepCopy()
+			existing.Name = fmt.Sprintf("existing-%v", rand.Int())
+			existing.Namespace = "workspace-snapshots"
+			Expect(k8sClient.Create(ctx, existing)).Should(Succeed())
+
+			newInstanceSnapshot := instanceSnapshot.DeepCopy()
+			newInstanceSnapshot.Name = fmt.Sprintf("isnap-conflict-%v", rand.Int())
+			newInstanceSnapshot.Spec.CreateTemplate = &crownlabsv1alpha2.SnapshotTemplate{
+				WorkspaceRef: crownlabsv1alpha2.GenericRef{Name: "snapshots"},
+				Name:         existing.Name,
+				PrettyName:   "Prepared VM",
+			}
+			checkIsnapSuccessfulCreation(ctx, newInstanceSnapshot, WorkingNamespace, timeout, interval)
+
+			By("Changing the job status to completed")
+			jobLookupKey := types.NamespacedName{Name: newInstanceSnapshot.Name, Namespace: WorkingNamespace}
+			snapjob := &batch.Job{}
+			Expect(k8sClient.Get(ctx, jobLookupKey, snapjob)).Should(Succeed())
+			snapjob.Status.Conditions = []batch.JobCondition{
+				{Type: batch.JobComplete, Status: v1.ConditionTrue},
+			}
+			Expect(k8sClient.Status().Update(ctx, snapjob)).Should(Succeed())
+
+			By("Checking that the conflict is reported")
+			isnapLookupKey := types.NamespacedName{Name: newInstanceSnapshot.Name, Namespace: WorkingNamespace}
+			Eventually(func() bool {
+				createdIsnap := &crownlabsv1alpha2.InstanceSnapshot{}
+				Expect(k8sClient.Get(ctx, isnapLookupKey, createdIsnap)).Should(Succeed())
+				return meta.IsStatusConditionFalse(createdIsnap.Status.Conditions, string(crownlabsv1alpha2.ConditionTemplateCreated))
+			}, timeout, interval).Should(BeTrue())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(existing), existing)).Should(Succeed())
+			Expect(existing.Spec.PrettyName).To(Equal(template.Spec.PrettyName))
+		})
+	})
+
+	Context("Testing the retention policy", func() {
+		It("Should delete the oldest completed snapshots of the same instance", func() {
+			By("Creating a dedicated instance")
+			retentionInstance := instance.DeepCopy()
+			retentionInstance.Name = fmt.Sprintf("retention-instance-%v", rand.Int())
+			Expect(k8sClient.Create(ctx, retentionInstance)).Should(Succeed())
+
+			var names []string
+			for i := range 3 {
+				newInstanceSnapshot := instanceSnapshot.DeepCopy()
+				newInstanceSnapshot.Name = fmt.Sprintf("isnap-retention-%v", rand.Int())
+				newInstanceSnapshot.Spec.Instance.Name = retentionInstance.Name
+				checkIsnapSuccessfulCreation(ctx, newInstanceSnapshot, WorkingNamespace, timeout, interval)
+
+				By("Changing the job status to completed")
+				jobLookupKey := types.NamespacedName{Name: newInstanceSnapshot.Name, Namespace: WorkingNamespace}
+				snapjob := &batch.Job{}
+				Expect(k8sClient.Get(ctx, jobLookupKey, snapjob)).Should(Succeed())
+				snapjob.Status.Conditions = []batch.JobCondition{
+					{Type: batch.JobComplete, Status: v1.ConditionTrue},
+				}
+				snapjob.Status.StartTime = &metav1.Time{Time: time.Now().Add(time.Duration(i) * time.Hour)}
+				snapjob.Status.CompletionTime = &metav1.Time{Time: time.Now().Add(time.Duration(i)*time.Hour + time.Minute)}
+				Expect(k8sClient.Status().Update(ctx, snapjob)).Should(Succeed())
+
+				checkIsnapStatus(ctx, newInstanceSnapshot.Name, WorkingNamespace, crownlabsv1alpha2.Completed, timeout, interval)
+				names = append(names, newInstanceSnapshot.Name)
+			}
+
+			By("Checking that only the oldest snapshot has been deleted")
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: names[0], Namespace: WorkingNamespace}, &crownlabsv1alpha2.InstanceSnapshot{}))
+			}, timeout, interval).Should(BeTrue())
+			for _, name := range names[1:] {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: WorkingNamespace}, &crownlabsv1alpha2.InstanceSnapshot{})).To(Succeed())
+			}
+		})
+	})
+
+	Context("Checking the templates referencing the image of a snapshot", func() {
+		const (
+			image  = "registry.example.com/tenant/snapshot:v1"
+			digest = "sha256:0123456789"
+		)
+
+		var (
+			reconciler    instancesnapshot_controller.InstanceSnapshotReconciler
+			snapshot      crownlabsv1alpha2.InstanceSnapshot
+			templateImage string
+		)
+
+		BeforeEach(func() {
+			// A non cached client is used, to observe the templates as soon as they are created.
+			directClient, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+			Expect(err).ToNot(HaveOccurred())
+
+			reconciler = instancesnapshot_controller.InstanceSnapshotReconciler{
+				Client:   directClient,
+				Registry: &fakeRegistry{digests: map[string]string{image: digest, "registry.example.com/tenant/snapshot:stable": digest}},
+			}
+			snapshot = crownlabsv1alpha2.InstanceSnapshot{
+				ObjectMeta: metav1.ObjectMeta{Name: "isnap-references", Namespace: WorkingNamespace},
+				Status:     crownlabsv1alpha2.InstanceSnapshotStatus{ImageRef: image, ImageDigest: digest},
+			}
+		})
+
+		JustBeforeEach(func() {
+			referencing := template.DeepCopy()
+			referencing.ObjectMeta = metav1.ObjectMeta{Name: fmt.Sprintf("references-%v", rand.Int()), Namespace: WorkingNamespace}
+			referencing.Spec.EnvironmentList[0].Image = templateImage
+			Expect(k8sClient.Create(ctx, referencing)).Should(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, referencing)).Should(Succeed()) })
+		})
+
+		DescribeTable("Should detect the references to the same image",
+			func(reference string, expected bool) {
+				templateImage = reference
+				templates, err := reconciler.ImageReferencingTemplates(ctx, &snapshot)
+				Expect(err).ToNot(HaveOccurred())
+				if expected {
+					Expect(templates).ToNot(BeEmpty())
+				} else {
+					Expect(templates).To(BeEmpty())
+				}
+			},
+			Entry("When the same tag is referenced", image, true),
+			Entry("When another tag of the same image is referenced", "registry.example.com/tenant/snapshot:stable", true),
+			Entry("When the image is referenced by digest", "registry.example.com/tenant/snapshot@"+digest, true),
+			Entry("When a different digest is referenced", "registry.example.com/tenant/snapshot@sha256:abcdef", false),
+			Entry("When a non existing tag is referenced", "registry.example.com/tenant/snapshot:missing", false),
+			Entry("When another registry is referenced", "other.example.com/tenant/snapshot:v1", false),
+		)
+	})
 })
 
+// fakeRegistry is an ImageDeleter resolving the digests of a predefined set of images.
+type fakeRegistry struct {
+	digests map[string]string
+}
+
+func (f *fakeRegistry) Digest(_ context.Context, ref registry.Reference, _ registry.Credentials) (string, error) {
+	if digest, found := f.digests[ref.String()]; found {
+		return digest, nil
+	}
+	return "", registry.ErrNotFound
+}
+
+func (f *fakeRegistry) DeleteImage(_ context.Context, _ registry.Reference, _ string, _ registry.Credentials) error {
+	return nil
+}
+
 func checkIsnapStatus(ctx context.Context, isnapName, workingNamespace string, desiredStatus crownlabsv1alpha2.SnapshotStatus, timeout, interval time.Duration) {
 	isnapLookupKey := types.NamespacedName{Name: isnapName, Namespace: workingNamespace}
 	retrievedIsnap := &crownlabsv1alpha2.InstanceSnapshot{}
@@ -17,7 +17,6 @@ package instancesnapshot_controller
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	batch "k8s.io/api/batch/v1"
@@ -28,9 +27,30 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	crownlabsv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
 )
 
+// pusherContainerName is the name of the container in charge of building the image and pushing it to the registry.
+const pusherContainerName = "docker-pusher"
+
+// ValidationError is returned by ValidateRequest in case the InstanceSnapshot request is not valid,
+// and it conveys the reason of the failure to be reported in the status conditions.
+type ValidationError struct {
+	Reason  crownlabsv1alpha2.ConditionReason
+	Message string
+}
+
+// Error returns the message associated with the validation error.
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// newValidationError returns a new ValidationError with the given reason and formatted message.
+func newValidationError(reason crownlabsv1alpha2.ConditionReason, format string, args ...interface{}) error {
+	return &ValidationError{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
 // ValidateRequest validates the InstanceSnapshot request, returns an error and if there's the need to try again.
 func (r *InstanceSnapshotReconciler) ValidateRequest(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot) (bool, error) {
 	// First it is needed to check if the instance actually exists.
@@ -42,7 +62,7 @@ func (r *InstanceSnapshotReconciler) ValidateRequest(ctx context.Context, isnap
 
 	if err := r.Get(ctx, instanceName, instance); err != nil && errors.IsNotFound(err) {
 		// The declared instance does not exist so don't try again.
-		return false, fmt.Errorf("instance %s not found in namespace %s. It is not possible to complete the InstanceSnapshot %s",
+		return false, newValidationError(crownlabsv1alpha2.ReasonInstanceNotFound, "instance %s not found in namespace %s. It is not possible to complete the InstanceSnapshot %s",
 			instanceName.Name, instanceName.Namespace, isnap.Name)
 	} else if err != nil {
 		return true, fmt.Errorf("error in retrieving the instance for InstanceSnapshot %s -> %w", isnap.Name, err)
@@ -61,47 +81,62 @@ func (r *InstanceSnapshotReconciler) ValidateRequest(ctx context.Context, isnap
 
 	if err := r.Get(ctx, templateName, template); err != nil && errors.IsNotFound(err) {
 		// The declared template does not exist set the phase as failed and don't try again.
-		return false, fmt.Errorf("template %s not found in namespace %s. It is not possible to complete the InstanceSnapshot %s",
+		return false, newValidationError(crownlabsv1alpha2.ReasonTemplateNotFound, "template %s not found in namespace %s. It is not possible to complete the InstanceSnapshot %s",
 			templateName.Name, templateName.Namespace, isnap.Name)
 	} else if err != nil {
 		return true, fmt.Errorf("error in retrieving the template for InstanceSnapshot %s -> %w", isnap.Name, err)
 	}
 
 	// Retrieve the environment from the template.
-	var env *crownlabsv1alpha2.Environment
-
-	if isnap.Spec.Environment.Name != "" {
-		for i := range template.Spec.EnvironmentList {
-			if template.Spec.EnvironmentList[i].Name == isnap.Spec.Environment.Name {
-				env = &template.Spec.EnvironmentList[i]
-				break
-			}
-		}
-
-		// Check if the specified environment was found.
-		if env == nil {
-			return false, fmt.Errorf("environment %s not found in template %s. It is not possible to complete the InstanceSnapshot %s",
-				isnap.Spec.Environment.Name, template.Name, isnap.Name)
-		}
-	} else {
-		// If the environment is not explicitly declared, take the first one.
-		env = &template.Spec.EnvironmentList[0]
+	env := snapshotEnvironment(template, isnap)
+	if env == nil {
+		return false, newValidationError(crownlabsv1alpha2.ReasonEnvironmentNotFound, "environment %s not found in template %s. It is not possible to complete the InstanceSnapshot %s",
+			isnap.Spec.Environment.Name, template.Name, isnap.Name)
 	}
 
-	// Check if the environment is a persistent VM.
-	if (env.EnvironmentType != crownlabsv1alpha2.ClassVM && env.EnvironmentType != crownlabsv1alpha2.ClassCloudVM) || !env.Persistent {
-		return false, fmt.Errorf("environment %s is not a persistent VM. It is not possible to complete the InstanceSnapshot %s",
+	// Check if the environment is persistent, and it is either a VM or a container.
+	if !isSnapshottable(env) {
+		return false, newValidationError(crownlabsv1alpha2.ReasonEnvironmentNotSupported, "environment %s is neither a persistent VM nor a persistent container. It is not possible to complete the InstanceSnapshot %s",
 			env.Name, isnap.Name)
 	}
 
-	// Check if the VM is running.
+	// Check if the instance is running.
 	if instance.Spec.Running {
-		return false, fmt.Errorf("the vm is running. It is not possible to complete the InstanceSnapshot %s", isnap.Name)
+		return false, newValidationError(crownlabsv1alpha2.ReasonInstanceRunning, "the instance is running. It is not possible to complete the InstanceSnapshot %s", isnap.Name)
 	}
 
 	return false, nil
 }
 
+// snapshotEnvironment returns the environment of the template targeted by the given InstanceSnapshot,
+// or nil if it is not found. If the environment is not explicitly declared, the first one is selected.
+func snapshotEnvironment(template *crownlabsv1alpha2.Template, isnap *crownlabsv1alpha2.InstanceSnapshot) *crownlabsv1alpha2.Environment {
+	if isnap.Spec.Environment.Name == "" {
+		if len(template.Spec.EnvironmentList) == 0 {
+			return nil
+		}
+		return &template.Spec.EnvironmentList[0]
+	}
+
+	for i := range template.Spec.EnvironmentList {
+		if template.Spec.EnvironmentList[i].Name == isnap.Spec.Environment.Name {
+			return &template.Spec.EnvironmentList[i]
+		}
+	}
+	return nil
+}
+
+// isSnapshottable returns whether a snapshot of the given environment can be created.
+func isSnapshottable(env *crownlabsv1alpha2.Environment) bool {
+	switch env.EnvironmentType {
+	case crownlabsv1alpha2.ClassVM, crownlabsv1alpha2.ClassCloudVM,
+		crownlabsv1alpha2.ClassContainer, crownlabsv1alpha2.ClassStandalone:
+		return env.Persistent
+	default:
+		return false
+	}
+}
+
 // GetJobStatus sets a Job and returns its status.
 func (r *InstanceSnapshotReconciler) GetJobStatus(job *batch.Job) (bool, batch.JobConditionType) {
 	for _, c := range job.Status.Conditions {
@@ -128,15 +163,34 @@ func (r *InstanceSnapshotReconciler) CreateSnapshottingJobDefinition(ctx context
 		return batch.Job{}, fmt.Errorf("error in retrieving the instance for InstanceSnapshot %s -> %w", isnap.Name, err)
 	}
 
+	templateName := types.NamespacedName{
+		Namespace: instance.Spec.Template.Namespace,
+		Name:      instance.Spec.Template.Name,
+	}
+	template := &crownlabsv1alpha2.Template{}
+
+	if err := r.Get(ctx, templateName, template); err != nil {
+		return batch.Job{}, fmt.Errorf("error in retrieving the template for InstanceSnapshot %s -> %w", isnap.Name, err)
+	}
+
+	env := snapshotEnvironment(template, isnap)
+	if env == nil {
+		return batch.Job{}, fmt.Errorf("environment %s not found in template %s for InstanceSnapshot %s",
+			isnap.Spec.Environment.Name, template.Name, isnap.Name)
+	}
+
 	var backoff int32 = 2
 	imagetag := time.Now().Format("20060102t150405")
-	// Volume name does not accept dots, replace them with dashes
-	volumename := strings.ReplaceAll(isnap.Spec.Instance.Name, ".", "-")
+	// The volume is named after the environment it belongs to (which already accounts for invalid characters)
+	volumename := forge.EnvironmentNamespacedName(instance, env).Name
 	imagedir := utils.ParseDockerDirectory(instance.Spec.Tenant.Name)
 
+	// Record the reference of the image to be pushed, as it is not possible to reconstruct it afterwards.
+	isnap.Status.ImageRef = fmt.Sprintf("%s/%s/%s:%s", r.ContainersSnapshot.VMRegistry, imagedir, isnap.Spec.ImageName, imagetag)
+
 	// Define volumes.
 
-	// Define VM VolumeSource.
+	// Define the VolumeSource of the persistent volume (i.e., the VM disk or the container content).
 	vmvolume := corev1.VolumeSource{
 		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
 			ClaimName: volumename,
@@ -180,10 +234,12 @@ func (r *InstanceSnapshotReconciler) CreateSnapshottingJobDefinition(ctx context
 
 	// Define Docker pusher container.
 	pushcontainer := corev1.Container{
-		Name:  "docker-pusher",
+		Name:  pusherContainerName,
 		Image: r.ContainersSnapshot.ContainerKaniko,
 		Args: []string{"--dockerfile=/workspace/Dockerfile",
-			fmt.Sprintf("--destination=%s/%s/%s:%s", r.ContainersSnapshot.VMRegistry, imagedir, isnap.Spec.ImageName, imagetag)},
+			fmt.Sprintf("--destination=%s", isnap.Status.ImageRef),
+			// The digest of the pushed image is reported through the termination message of the container.
+			fmt.Sprintf("--digest-file=%s", corev1.TerminationMessagePathDefault)},
 		VolumeMounts: []corev1.VolumeMount{
 			{
 				Name:      "tmp-vol",
@@ -210,6 +266,7 @@ func (r *InstanceSnapshotReconciler) CreateSnapshottingJobDefinition(ctx context
 	exportcontainer := corev1.Container{
 		Name:  "img-generator",
 		Image: r.ContainersSnapshot.ContainerImgExport,
+		Args:  exporterArgs(env),
 		VolumeMounts: []corev1.VolumeMount{
 			{
 				Name:      volumename,
@@ -256,3 +313,20 @@ func (r *InstanceSnapshotReconciler) CreateSnapshottingJobDefinition(ctx context
 
 	return snapjob, nil
 }
+
+// exporterArgs returns the arguments of the image exporter container, depending on the type of the environment.
+// In case of VMs, the disk is converted into a qcow2 image, while in case of containers the content of the
+// persistent volume is layered on top of the environment image, at the same path it is mounted in the instance.
+func exporterArgs(env *crownlabsv1alpha2.Environment) []string {
+	switch env.EnvironmentType {
+	case crownlabsv1alpha2.ClassContainer, crownlabsv1alpha2.ClassStandalone:
+		return []string{
+			"--mode", "container",
+			"--base-image", env.Image,
+			"--content-path", forge.PersistentMountPath(env),
+			"--owner", fmt.Sprintf("%d:%d", forge.CrownLabsUserID, forge.CrownLabsUserID),
+		}
+	default:
+		return nil
+	}
+}
@@ -16,12 +16,21 @@ package instancesnapshot_controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlUtil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	crownlabsv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
 )
 
 // CreateSnapshottingJob creates the job in charge of creating the snapshot.
@@ -29,6 +38,7 @@ func (r *InstanceSnapshotReconciler) CreateSnapshottingJob(ctx context.Context,
 	r.EventsRecorder.Event(isnap, "Normal", "Validating", "Start validation of the request")
 
 	isnap.Status.Phase = crownlabsv1alpha2.Pending
+	setCondition(isnap, crownlabsv1alpha2.ConditionReady, metav1.ConditionFalse, crownlabsv1alpha2.ReasonJobPending, "Validating the request")
 	if err := r.Status().Update(ctx, isnap); err != nil {
 		return true, fmt.Errorf("error when updating status of InstanceSnapshot %s -> %w", isnap.Name, err)
 	}
@@ -41,7 +51,16 @@ func (r *InstanceSnapshotReconciler) CreateSnapshottingJob(ctx context.Context,
 		}
 
 		// Set the status as failed
+		reason := crownlabsv1alpha2.ReasonEnforcementFailed
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			reason = verr.Reason
+		}
 		isnap.Status.Phase = crownlabsv1alpha2.Failed
+		isnap.Status.CompletionTime = ptr.To(metav1.Now())
+		isnap.Status.FailureReason = err.Error()
+		setCondition(isnap, crownlabsv1alpha2.ConditionValidated, metav1.ConditionFalse, reason, err.Error())
+		setCondition(isnap, crownlabsv1alpha2.ConditionReady, metav1.ConditionFalse, reason, err.Error())
 		if uerr := r.Status().Update(ctx, isnap); uerr != nil {
 			return true, fmt.Errorf("error when updating status of InstanceSnapshot %s -> %w", isnap.Name, uerr)
 		}
@@ -60,12 +79,27 @@ func (r *InstanceSnapshotReconciler) CreateSnapshottingJob(ctx context.Context,
 		return true, err
 	}
 
+	// Add the finalizer in charge of deleting the image from the registry, once the InstanceSnapshot is deleted.
+	if r.Registry != nil && !ctrlUtil.ContainsFinalizer(isnap, crownlabsv1alpha2.InstanceSnapshotImageFinalizerName) {
+		status := isnap.Status.DeepCopy()
+		ctrlUtil.AddFinalizer(isnap, crownlabsv1alpha2.InstanceSnapshotImageFinalizerName)
+		if err := r.Update(ctx, isnap); err != nil {
+			return true, fmt.Errorf("error when adding the finalizer to InstanceSnapshot %s -> %w", isnap.Name, err)
+		}
+		// Restore the status fields configured by the job definition, which are overwritten by the update.
+		isnap.Status = *status
+	}
+
 	if err := r.Create(ctx, &snapjob); err != nil {
 		// It was not possible to create the job
 		return true, fmt.Errorf("error when creating the job for %s -> %w", isnap.Name, err)
 	}
 
 	isnap.Status.Phase = crownlabsv1alpha2.Processing
+	isnap.Status.StartTime = ptr.To(metav1.Now())
+	setCondition(isnap, crownlabsv1alpha2.ConditionValidated, metav1.ConditionTrue, crownlabsv1alpha2.ReasonSucceeded, "The request is valid")
+	setCondition(isnap, crownlabsv1alpha2.ConditionReady, metav1.ConditionFalse, crownlabsv1alpha2.ReasonJobRunning,
+		fmt.Sprintf("Job %s for snapshot creation started", snapjob.Name))
 	if err := r.Status().Update(ctx, isnap); err != nil {
 		return true, fmt.Errorf("error when updating status of InstanceSnapshot %s -> %w", isnap.Name, err)
 	}
@@ -77,15 +111,29 @@ func (r *InstanceSnapshotReconciler) CreateSnapshottingJob(ctx context.Context,
 func (r *InstanceSnapshotReconciler) HandleExistingJob(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot, snapjob *batch.Job) (batch.JobConditionType, error) {
 	completed, jstatus := r.GetJobStatus(snapjob)
 	if completed {
+		if snapjob.Status.StartTime != nil {
+			isnap.Status.StartTime = snapjob.Status.StartTime.DeepCopy()
+		}
+		isnap.Status.CompletionTime = ptr.To(metav1.Now())
+		if snapjob.Status.CompletionTime != nil {
+			isnap.Status.CompletionTime = snapjob.Status.CompletionTime.DeepCopy()
+		}
+
 		if jstatus == batch.JobComplete {
 			// The job is completed and the image has been uploaded to the registry
 			isnap.Status.Phase = crownlabsv1alpha2.Completed
+			isnap.Status.ImageDigest = r.RetrieveImageDigest(ctx, snapjob)
+			setCondition(isnap, crownlabsv1alpha2.ConditionReady, metav1.ConditionTrue, crownlabsv1alpha2.ReasonAvailable,
+				fmt.Sprintf("Image %s created and uploaded", isnap.Spec.ImageName))
 			if err := r.Status().Update(ctx, isnap); err != nil {
 				return "", fmt.Errorf("error when updating status of InstanceSnapshot %s -> %w", isnap.Name, err)
 			}
 		} else {
 			// The creation of the snapshot failed since the job failed
 			isnap.Status.Phase = crownlabsv1alpha2.Failed
+			isnap.Status.FailureReason = jobFailureMessage(snapjob)
+			setCondition(isnap, crownlabsv1alpha2.ConditionReady, metav1.ConditionFalse, crownlabsv1alpha2.ReasonJobFailed,
+				fmt.Sprintf("Job %s for snapshot creation failed", snapjob.Name))
 			if err := r.Status().Update(ctx, isnap); err != nil {
 				return "", fmt.Errorf("error when updating status of InstanceSnapshot %s -> %w", isnap.Name, err)
 			}
@@ -93,3 +141,47 @@ func (r *InstanceSnapshotReconciler) HandleExistingJob(ctx context.Context, isna
 	}
 	return jstatus, nil
 }
+
+// RetrieveImageDigest returns the digest of the image pushed by the given job, which is reported by Kaniko through
+// the termination message of the corresponding container. An empty string is returned if it cannot be retrieved.
+func (r *InstanceSnapshotReconciler) RetrieveImageDigest(ctx context.Context, snapjob *batch.Job) string {
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(snapjob.Namespace), client.MatchingLabels{"job-name": snapjob.Name}); err != nil {
+		klog.Errorf("Unable to retrieve the pods of job %s -> %s", snapjob.Name, err)
+		return ""
+	}
+
+	for i := range pods.Items {
+		for j := range pods.Items[i].Status.ContainerStatuses {
+			status := &pods.Items[i].Status.ContainerStatuses[j]
+			if status.Name != pusherContainerName || status.State.Terminated == nil || status.State.Terminated.ExitCode != 0 {
+				continue
+			}
+			if digest := strings.TrimSpace(status.State.Terminated.Message); strings.HasPrefix(digest, "sha256:") {
+				return digest
+			}
+		}
+	}
+	return ""
+}
+
+// jobFailureMessage returns the message explaining why the given job failed.
+func jobFailureMessage(snapjob *batch.Job) string {
+	for _, c := range snapjob.Status.Conditions {
+		if c.Type == batch.JobFailed && c.Status == corev1.ConditionTrue && c.Message != "" {
+			return c.Message
+		}
+	}
+	return fmt.Sprintf("Job %s for snapshot creation failed", snapjob.Name)
+}
+
+// isTerminated returns whether the creation of the given snapshot terminated (either successfully or not).
+func isTerminated(isnap *crownlabsv1alpha2.InstanceSnapshot) bool {
+	return isnap.Status.Phase == crownlabsv1alpha2.Completed || isnap.Status.Phase == crownlabsv1alpha2.Failed
+}
+
+// setCondition configures the given condition in the status of the InstanceSnapshot.
+func setCondition(isnap *crownlabsv1alpha2.InstanceSnapshot, conditionType crownlabsv1alpha2.ConditionType,
+	status metav1.ConditionStatus, reason crownlabsv1alpha2.ConditionReason, message string) {
+	utils.SetCondition(&isnap.Status.Conditions, isnap, conditionType, status, reason, message)
+}
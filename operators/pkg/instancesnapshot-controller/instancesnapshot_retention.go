@@ -0,0 +1,303 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancesnapshot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlUtil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	crownlabsv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/registry"
+)
+
+// CleanupCompletedSnapshot deletes the job of the given completed snapshot (if still present), since
+// its outcome has already been recorded, and enforces the retention policy for the corresponding instance.
+func (r *InstanceSnapshotReconciler) CleanupCompletedSnapshot(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot, snapjob *batch.Job) error {
+	if err := r.DeleteSnapshotJob(ctx, isnap, snapjob); err != nil {
+		return err
+	}
+
+	return r.EnforceRetention(ctx, isnap)
+}
+
+// DeleteSnapshotJob deletes the job of the given terminated snapshot (if still present), since its outcome
+// (including the failure reason, if any) has already been recorded in the status of the InstanceSnapshot.
+func (r *InstanceSnapshotReconciler) DeleteSnapshotJob(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot, snapjob *batch.Job) error {
+	if snapjob == nil {
+		return nil
+	}
+
+	if err := r.Delete(ctx, snapjob, client.PropagationPolicy("Background")); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("error when deleting the job of InstanceSnapshot %s -> %w", isnap.Name, err)
+	}
+	klog.Infof("Job of InstanceSnapshot %s deleted after termination", isnap.Name)
+	return nil
+}
+
+// EnforceRetention deletes the oldest completed snapshots of the same instance of the given one, so that at most
+// KeepLast are retained. Snapshots whose image is referenced by any template are never deleted.
+func (r *InstanceSnapshotReconciler) EnforceRetention(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot) error {
+	if r.KeepLast <= 0 {
+		return nil
+	}
+
+	var isnaps crownlabsv1alpha2.InstanceSnapshotList
+	if err := r.List(ctx, &isnaps, client.InNamespace(isnap.Namespace)); err != nil {
+		return fmt.Errorf("error when listing the InstanceSnapshots of instance %s -> %w", isnap.Spec.Instance.Name, err)
+	}
+
+	var completed []*crownlabsv1alpha2.InstanceSnapshot
+	for i := range isnaps.Items {
+		candidate := &isnaps.Items[i]
+		if candidate.Spec.Instance == isnap.Spec.Instance && candidate.Status.Phase == crownlabsv1alpha2.Completed &&
+			candidate.GetDeletionTimestamp().IsZero() {
+			completed = append(completed, candidate)
+		}
+	}
+
+	if len(completed) <= r.KeepLast {
+		return nil
+	}
+
+	// Sort the snapshots from the most recent to the oldest one.
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completionTime(completed[j]).Before(completionTime(completed[i]))
+	})
+
+	for _, expired := range completed[r.KeepLast:] {
+		templates, err := r.ImageReferencingTemplates(ctx, expired)
+		if err != nil {
+			return err
+		}
+		if len(templates) > 0 {
+			klog.Infof("InstanceSnapshot %s retained, since its image is referenced by templates %s", expired.Name, strings.Join(templates, ", "))
+			continue
+		}
+
+		if err := r.Delete(ctx, expired); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("error when deleting the expired InstanceSnapshot %s -> %w", expired.Name, err)
+		}
+		klog.Infof("InstanceSnapshot %s deleted, since more than %d snapshots of instance %s exist", expired.Name, r.KeepLast, isnap.Spec.Instance.Name)
+		r.EventsRecorder.Eventf(isnap, corev1.EventTypeNormal, "RetentionEnforced", "InstanceSnapshot %s deleted by the retention policy", expired.Name)
+	}
+
+	return nil
+}
+
+// HandleDeletion deletes the image produced by the given snapshot from the registry, unless it is still referenced
+// by any template, and then removes the finalizer to allow the deletion of the InstanceSnapshot to proceed.
+func (r *InstanceSnapshotReconciler) HandleDeletion(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot) error {
+	// Make sure that the job is no longer running, to prevent the image from being pushed after the deletion.
+	snapjob := batch.Job{}
+	snapjob.SetName(isnap.Name)
+	snapjob.SetNamespace(isnap.Namespace)
+	if err := r.Delete(ctx, &snapjob, client.PropagationPolicy("Background")); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("error when deleting the job of InstanceSnapshot %s -> %w", isnap.Name, err)
+	}
+
+	if isnap.Status.ImageRef != "" && r.Registry != nil {
+		if err := r.deleteImage(ctx, isnap); err != nil {
+			r.EventsRecorder.Eventf(isnap, corev1.EventTypeWarning, "ImageDeletionFailed", "Failed deleting image %s: %v", isnap.Status.ImageRef, err)
+			return err
+		}
+	}
+
+	ctrlUtil.RemoveFinalizer(isnap, crownlabsv1alpha2.InstanceSnapshotImageFinalizerName)
+	if err := r.Update(ctx, isnap); err != nil {
+		return fmt.Errorf("error when removing the finalizer of InstanceSnapshot %s -> %w", isnap.Name, err)
+	}
+	return nil
+}
+
+// deleteImage deletes the image produced by the given snapshot from the registry, unless it is referenced by any template.
+func (r *InstanceSnapshotReconciler) deleteImage(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot) error {
+	templates, err := r.ImageReferencingTemplates(ctx, isnap)
+	if err != nil {
+		return err
+	}
+	if len(templates) > 0 {
+		klog.Infof("Image %s retained, since it is referenced by templates %s", isnap.Status.ImageRef, strings.Join(templates, ", "))
+		r.EventsRecorder.Eventf(isnap, corev1.EventTypeNormal, "ImageRetained", "Image %s retained, since it is referenced by templates %s",
+			isnap.Status.ImageRef, strings.Join(templates, ", "))
+		return nil
+	}
+
+	ref, err := registry.ParseReference(isnap.Status.ImageRef)
+	if err != nil {
+		// The image cannot be deleted, as the registry is unknown.
+		klog.Warningf("Image %s of InstanceSnapshot %s not deleted -> %s", isnap.Status.ImageRef, isnap.Name, err)
+		return nil
+	}
+
+	credentials, err := r.registryCredentials(ctx, isnap.Namespace, ref.Host)
+	if err != nil {
+		return err
+	}
+
+	err = r.Registry.DeleteImage(ctx, ref, isnap.Status.ImageDigest, credentials)
+	switch {
+	case errors.Is(err, registry.ErrDeletionUnsupported):
+		// Retrying would not help, hence the image is retained and the deletion of the InstanceSnapshot proceeds.
+		klog.Warningf("Image %s of InstanceSnapshot %s not deleted -> %s", isnap.Status.ImageRef, isnap.Name, err)
+		r.EventsRecorder.Eventf(isnap, corev1.EventTypeWarning, "ImageRetained", "Image %s retained, since the registry does not allow deletions",
+			isnap.Status.ImageRef)
+		return nil
+	case err != nil:
+		return fmt.Errorf("error when deleting image %s -> %w", isnap.Status.ImageRef, err)
+	}
+	klog.Infof("Image %s of InstanceSnapshot %s deleted from the registry", isnap.Status.ImageRef, isnap.Name)
+	return nil
+}
+
+// registryCredentials retrieves the credentials to access the given registry from the secret used by the snapshot jobs.
+// Anonymous credentials are returned in case the secret does not exist.
+func (r *InstanceSnapshotReconciler) registryCredentials(ctx context.Context, namespace, host string) (registry.Credentials, error) {
+	if r.ContainersSnapshot.RegistrySecretName == "" {
+		return registry.Credentials{}, nil
+	}
+
+	var secret corev1.Secret
+	key := types.NamespacedName{Namespace: namespace, Name: r.ContainersSnapshot.RegistrySecretName}
+	if err := r.Get(ctx, key, &secret); kerrors.IsNotFound(err) {
+		return registry.Credentials{}, nil
+	} else if err != nil {
+		return registry.Credentials{}, fmt.Errorf("error when retrieving the registry secret %s -> %w", key, err)
+	}
+
+	return registry.CredentialsFromDockerConfig(secret.Data[corev1.DockerConfigJsonKey], host)
+}
+
+// ImageReferencingTemplates returns the names of the templates with at least one environment referencing the image
+// produced by the given snapshot. References to the same repository are compared by digest, in order to detect also
+// the ones through different tags or directly by digest. If the digests cannot be determined, the references to
+// the same repository are conservatively considered a match, to prevent deleting images still in use.
+func (r *InstanceSnapshotReconciler) ImageReferencingTemplates(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot) ([]string, error) {
+	if isnap.Status.ImageRef == "" {
+		return nil, nil
+	}
+
+	var templates crownlabsv1alpha2.TemplateList
+	if err := r.List(ctx, &templates); err != nil {
+		return nil, fmt.Errorf("error when listing the templates -> %w", err)
+	}
+
+	// Images hosted on unknown registries can only be compared textually.
+	target, targetErr := registry.ParseReference(isnap.Status.ImageRef)
+	resolver := digestResolver{reconciler: r, namespace: isnap.Namespace, digests: map[string]string{}}
+	if isnap.Status.ImageDigest != "" {
+		resolver.digests[target.String()] = isnap.Status.ImageDigest
+	}
+
+	var names []string
+	for i := range templates.Items {
+		for j := range templates.Items[i].Spec.EnvironmentList {
+			image := templates.Items[i].Spec.EnvironmentList[j].Image
+			referenced := image == isnap.Status.ImageRef
+			if !referenced && targetErr == nil {
+				var err error
+				if referenced, err = resolver.sameImage(ctx, target, image); err != nil {
+					return nil, err
+				}
+			}
+
+			if referenced {
+				names = append(names, fmt.Sprintf("%s/%s", templates.Items[i].Namespace, templates.Items[i].Name))
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// digestResolver retrieves the digests of the images from the registry, caching them for the duration of a reconciliation.
+type digestResolver struct {
+	reconciler *InstanceSnapshotReconciler
+	namespace  string
+	digests    map[string]string
+}
+
+// sameImage returns whether the given image identifies the same manifest of the target one.
+func (d *digestResolver) sameImage(ctx context.Context, target registry.Reference, image string) (bool, error) {
+	candidate, err := registry.ParseReference(image)
+	if err != nil || candidate.Host != target.Host || candidate.Repository != target.Repository {
+		return false, nil
+	}
+
+	targetDigest, err := d.digest(ctx, target)
+	if errors.Is(err, registry.ErrNotFound) {
+		// The target image no longer exists, hence it cannot be referenced.
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	candidateDigest, err := d.digest(ctx, candidate)
+	if errors.Is(err, registry.ErrNotFound) {
+		// The template references a tag which does not exist, hence not the target image.
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return targetDigest == "" || candidateDigest == "" || targetDigest == candidateDigest, nil
+}
+
+// digest returns the digest of the manifest identified by the given reference, or an empty string if it cannot be determined.
+func (d *digestResolver) digest(ctx context.Context, ref registry.Reference) (string, error) {
+	if strings.Contains(ref.Tag, ":") {
+		return ref.Tag, nil
+	}
+	if digest, found := d.digests[ref.String()]; found {
+		return digest, nil
+	}
+	if d.reconciler.Registry == nil {
+		return "", nil
+	}
+
+	credentials, err := d.reconciler.registryCredentials(ctx, d.namespace, ref.Host)
+	if err != nil {
+		return "", err
+	}
+
+	digest, err := d.reconciler.Registry.Digest(ctx, ref, credentials)
+	switch {
+	case errors.Is(err, registry.ErrNotFound):
+		return "", err
+	case err != nil:
+		return "", fmt.Errorf("error when retrieving the digest of image %s -> %w", ref, err)
+	}
+
+	d.digests[ref.String()] = digest
+	return digest, nil
+}
+
+// completionTime returns the time the given snapshot has been completed, falling back to the creation one.
+func completionTime(isnap *crownlabsv1alpha2.InstanceSnapshot) time.Time {
+	if isnap.Status.CompletionTime != nil {
+		return isnap.Status.CompletionTime.Time
+	}
+	return isnap.GetCreationTimestamp().Time
+}
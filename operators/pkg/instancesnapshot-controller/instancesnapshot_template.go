@@ -0,0 +1,118 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancesnapshot_controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	crownlabsv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+// EnforceSnapshotTemplate creates (or updates) the template requested by the given completed snapshot, which refers
+// to the produced image and copies the configuration of the snapshotted environment. Existing templates not created
+// from a snapshot are never overwritten. The outcome is recorded in the status of the InstanceSnapshot.
+func (r *InstanceSnapshotReconciler) EnforceSnapshotTemplate(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot) error {
+	if isnap.Spec.CreateTemplate == nil || isnap.Status.TemplateRef != nil {
+		return nil
+	}
+
+	source, reason, err := r.sourceEnvironment(ctx, isnap)
+	if err != nil {
+		if reason == "" {
+			return err
+		}
+		// The source environment no longer exists, hence it is not possible to try again.
+		return r.recordTemplateFailure(ctx, isnap, reason, err.Error())
+	}
+
+	key := forge.SnapshotTemplateNamespacedName(isnap)
+	template := crownlabsv1alpha2.Template{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+
+	if err := r.Get(ctx, key, &template); err == nil && !forge.IsSnapshotTemplate(&template) {
+		return r.recordTemplateFailure(ctx, isnap, crownlabsv1alpha2.ReasonTemplateConflict,
+			fmt.Sprintf("template %s already exists, and it has not been created from a snapshot", key))
+	} else if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error when retrieving the template %s for InstanceSnapshot %s -> %w", key, isnap.Name, err)
+	}
+
+	op, err := ctrl.CreateOrUpdate(ctx, r.Client, &template, func() error {
+		forge.ConfigureSnapshotTemplate(&template, isnap, source)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error when enforcing the template %s for InstanceSnapshot %s -> %w", key, isnap.Name, err)
+	}
+	klog.Infof("Template %s for InstanceSnapshot %s enforced: %s", key, isnap.Name, op)
+
+	isnap.Status.TemplateRef = &crownlabsv1alpha2.GenericRef{Name: key.Name, Namespace: key.Namespace}
+	setCondition(isnap, crownlabsv1alpha2.ConditionTemplateCreated, metav1.ConditionTrue, crownlabsv1alpha2.ReasonSucceeded,
+		fmt.Sprintf("Template %s created from the snapshot", key))
+	if err := r.Status().Update(ctx, isnap); err != nil {
+		return fmt.Errorf("error when updating status of InstanceSnapshot %s -> %w", isnap.Name, err)
+	}
+
+	r.EventsRecorder.Eventf(isnap, corev1.EventTypeNormal, "TemplateCreated", "Template %s created from the snapshot", key)
+	return nil
+}
+
+// sourceEnvironment retrieves the environment the given snapshot has been created from. In case the environment
+// cannot be retrieved because it no longer exists, the reason is returned along with the error.
+func (r *InstanceSnapshotReconciler) sourceEnvironment(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot) (
+	*crownlabsv1alpha2.Environment, crownlabsv1alpha2.ConditionReason, error) {
+	instance := &crownlabsv1alpha2.Instance{}
+	instanceName := types.NamespacedName{Namespace: isnap.Spec.Instance.Namespace, Name: isnap.Spec.Instance.Name}
+	if err := r.Get(ctx, instanceName, instance); errors.IsNotFound(err) {
+		return nil, crownlabsv1alpha2.ReasonInstanceNotFound, fmt.Errorf("instance %s no longer exists", instanceName)
+	} else if err != nil {
+		return nil, "", fmt.Errorf("error in retrieving the instance for InstanceSnapshot %s -> %w", isnap.Name, err)
+	}
+
+	template := &crownlabsv1alpha2.Template{}
+	templateName := types.NamespacedName{Namespace: instance.Spec.Template.Namespace, Name: instance.Spec.Template.Name}
+	if err := r.Get(ctx, templateName, template); errors.IsNotFound(err) {
+		return nil, crownlabsv1alpha2.ReasonTemplateNotFound, fmt.Errorf("template %s no longer exists", templateName)
+	} else if err != nil {
+		return nil, "", fmt.Errorf("error in retrieving the template for InstanceSnapshot %s -> %w", isnap.Name, err)
+	}
+
+	env := snapshotEnvironment(template, isnap)
+	if env == nil {
+		return nil, crownlabsv1alpha2.ReasonEnvironmentNotFound, fmt.Errorf("environment %s no longer exists in template %s",
+			isnap.Spec.Environment.Name, templateName)
+	}
+	return env, "", nil
+}
+
+// recordTemplateFailure records in the status of the given InstanceSnapshot that the requested template could not be created.
+func (r *InstanceSnapshotReconciler) recordTemplateFailure(ctx context.Context, isnap *crownlabsv1alpha2.InstanceSnapshot,
+	reason crownlabsv1alpha2.ConditionReason, message string) error {
+	klog.Infof("Template for InstanceSnapshot %s not created -> %s", isnap.Name, message)
+	setCondition(isnap, crownlabsv1alpha2.ConditionTemplateCreated, metav1.ConditionFalse, reason, message)
+	if err := r.Status().Update(ctx, isnap); err != nil {
+		return fmt.Errorf("error when updating status of InstanceSnapshot %s -> %w", isnap.Name, err)
+	}
+
+	r.EventsRecorder.Event(isnap, corev1.EventTypeWarning, "TemplateCreationFailed", message)
+	return nil
+}
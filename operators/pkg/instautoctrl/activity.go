@@ -0,0 +1,280 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instautoctrl contains the controllers for Instance Termination, Submission, Scheduling, Expiration and Idle automations.
+package instautoctrl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/instmetrics"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+// ActivitySource detects the usage of the environments of an instance, through a specific signal.
+type ActivitySource interface {
+	// Name returns the identifier of the signal.
+	Name() string
+	// Observes returns whether the usage of the given environment can be detected through the signal.
+	Observes(environment *clv1alpha2.Environment) bool
+	// LastActivity returns the most recent time the given environment was observed being used,
+	// or the zero time in case no activity has been detected.
+	LastActivity(ctx context.Context, instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) (time.Time, error)
+}
+
+// CPUActivitySource detects the usage of container environments through the CPU consumption of the application
+// container, as reported by the instmetrics server running on the same node (the corresponding service is node-local).
+type CPUActivitySource struct {
+	client.Client
+	// The service exposing the instmetrics servers.
+	Service types.NamespacedName
+	// The port the instmetrics gRPC servers are listening on.
+	Port int
+	// The CPU usage (percentage of a core) above which the environment is considered in use.
+	Threshold float32
+	Timeout   time.Duration
+}
+
+// NewCPUActivitySource returns a new CPUActivitySource, given the endpoint (i.e., service.namespace:port) of the instmetrics server.
+func NewCPUActivitySource(c client.Client, endpoint string, threshold float32, timeout time.Duration) (*CPUActivitySource, error) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instmetrics endpoint %q: %w", endpoint, err)
+	}
+
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instmetrics endpoint port %q: %w", port, err)
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid instmetrics endpoint %q: expected format is service.namespace:port", endpoint)
+	}
+
+	return &CPUActivitySource{
+		Client:    c,
+		Service:   types.NamespacedName{Name: parts[0], Namespace: parts[1]},
+		Port:      portNumber,
+		Threshold: threshold,
+		Timeout:   timeout,
+	}, nil
+}
+
+// Name returns the identifier of the signal.
+func (s *CPUActivitySource) Name() string {
+	return "cpu"
+}
+
+// Observes returns whether the usage of the given environment can be detected through the signal.
+func (s *CPUActivitySource) Observes(environment *clv1alpha2.Environment) bool {
+	return environment.EnvironmentType == clv1alpha2.ClassContainer || environment.EnvironmentType == clv1alpha2.ClassStandalone
+}
+
+// LastActivity returns the current time if the CPU usage of the environment is above the threshold, and the zero time otherwise.
+func (s *CPUActivitySource) LastActivity(ctx context.Context, instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) (time.Time, error) {
+	pods, err := environmentPods(ctx, s.Client, instance, environment)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var service v1.Service
+	if err := s.Get(ctx, s.Service, &service); err != nil {
+		return time.Time{}, fmt.Errorf("failed retrieving the instmetrics service: %w", err)
+	}
+
+	var servers v1.PodList
+	if err := s.List(ctx, &servers, client.InNamespace(s.Service.Namespace), client.MatchingLabels(service.Spec.Selector)); err != nil {
+		return time.Time{}, fmt.Errorf("failed retrieving the instmetrics servers: %w", err)
+	}
+
+	for i := range pods {
+		server := nodeLocalPod(servers.Items, pods[i].Spec.NodeName)
+		if server == nil {
+			return time.Time{}, fmt.Errorf("no instmetrics server available on node %q", pods[i].Spec.NodeName)
+		}
+
+		usage, err := s.cpuUsage(ctx, net.JoinHostPort(server.Status.PodIP, strconv.Itoa(s.Port)), pods[i].GetName())
+		if err != nil {
+			return time.Time{}, err
+		}
+		if usage >= s.Threshold {
+			return time.Now(), nil
+		}
+	}
+
+	return time.Time{}, nil
+}
+
+// cpuUsage retrieves the CPU usage of the application container of the given pod from the specified instmetrics server.
+func (s *CPUActivitySource) cpuUsage(ctx context.Context, address, pod string) (float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	connection, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return 0, fmt.Errorf("failed connecting to instmetrics server %q: %w", address, err)
+	}
+	defer connection.Close()
+
+	metrics, err := instmetrics.NewInstanceMetricsClient(connection).ContainerMetrics(ctx, &instmetrics.ContainerMetricsRequest{PodName: pod})
+	if err != nil {
+		return 0, fmt.Errorf("failed retrieving container metrics for pod %q: %w", pod, err)
+	}
+	return metrics.GetCpuPerc(), nil
+}
+
+// NoVNCActivitySource detects the usage of container environments through the remote desktop connections
+// tracked by the websockify sidecar.
+type NoVNCActivitySource struct {
+	client.Client
+	// The port the websockify sidecar exposes the activity endpoint on (forge.MetricsPortNumber, if zero).
+	Port    int
+	Timeout time.Duration
+}
+
+// Name returns the identifier of the signal.
+func (s *NoVNCActivitySource) Name() string {
+	return "novnc"
+}
+
+// Observes returns whether the usage of the given environment can be detected through the signal.
+func (s *NoVNCActivitySource) Observes(environment *clv1alpha2.Environment) bool {
+	return environment.EnvironmentType == clv1alpha2.ClassContainer
+}
+
+// LastActivity returns the current time if a remote desktop connection is active, and the time the last one terminated otherwise.
+func (s *NoVNCActivitySource) LastActivity(ctx context.Context, instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) (time.Time, error) {
+	pods, err := environmentPods(ctx, s.Client, instance, environment)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	port := s.Port
+	if port == 0 {
+		port = forge.MetricsPortNumber
+	}
+
+	var last time.Time
+	for i := range pods {
+		url := fmt.Sprintf("http://%s/activity", net.JoinHostPort(pods[i].Status.PodIP, strconv.Itoa(port)))
+
+		var response NoVNCActivityResponse
+		statusCode, err := utils.HTTPGetJSONIntoStruct(ctx, url, &response, s.Timeout)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed retrieving noVNC activity for pod %q: %w", pods[i].GetName(), err)
+		}
+		if statusCode != http.StatusOK {
+			return time.Time{}, fmt.Errorf("failed retrieving noVNC activity for pod %q: unexpected status code %d", pods[i].GetName(), statusCode)
+		}
+
+		if response.ActiveConnections > 0 {
+			return time.Now(), nil
+		}
+		last = latest(last, response.LastActivity)
+	}
+
+	return last, nil
+}
+
+// SSHActivitySource detects the usage of the environments through the SSH connections established from the bastion,
+// as reported by the bastion SSH tracker.
+type SSHActivitySource struct {
+	// The URL of the activity endpoint exposed by the bastion SSH tracker.
+	URL     string
+	Timeout time.Duration
+}
+
+// Name returns the identifier of the signal.
+func (s *SSHActivitySource) Name() string {
+	return "ssh"
+}
+
+// Observes returns whether the usage of the given environment can be detected through the signal.
+// Graphical VMs are excluded, since the remote desktop connections towards them cannot be observed.
+func (s *SSHActivitySource) Observes(environment *clv1alpha2.Environment) bool {
+	return (environment.EnvironmentType == clv1alpha2.ClassVM || environment.EnvironmentType == clv1alpha2.ClassCloudVM) && !environment.GuiEnabled
+}
+
+// LastActivity returns the time the last SSH connection towards the environment was detected.
+func (s *SSHActivitySource) LastActivity(ctx context.Context, instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) (time.Time, error) {
+	ip := instance.Status.IP
+	for i := range instance.Status.Environments {
+		if instance.Status.Environments[i].Name == environment.Name {
+			ip = instance.Status.Environments[i].IP
+		}
+	}
+	if ip == "" {
+		return time.Time{}, nil
+	}
+
+	var response SSHActivityResponse
+	statusCode, err := utils.HTTPGetJSONIntoStruct(ctx, s.URL, &response, s.Timeout)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed retrieving SSH activity: %w", err)
+	}
+	if statusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("failed retrieving SSH activity: unexpected status code %d", statusCode)
+	}
+
+	return response[ip], nil
+}
+
+// environmentPods returns the running pods associated with the given environment.
+func environmentPods(ctx context.Context, c client.Client, instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) ([]v1.Pod, error) {
+	var pods v1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(instance.GetNamespace()), client.MatchingLabels(forge.EnvironmentSelectorLabels(instance, environment))); err != nil {
+		return nil, fmt.Errorf("failed retrieving the environment pods: %w", err)
+	}
+
+	running := make([]v1.Pod, 0, len(pods.Items))
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == v1.PodRunning && pods.Items[i].Status.PodIP != "" {
+			running = append(running, pods.Items[i])
+		}
+	}
+	return running, nil
+}
+
+// nodeLocalPod returns the running pod scheduled on the given node, if any.
+func nodeLocalPod(pods []v1.Pod, node string) *v1.Pod {
+	for i := range pods {
+		if pods[i].Spec.NodeName == node && pods[i].Status.Phase == v1.PodRunning && pods[i].Status.PodIP != "" {
+			return &pods[i]
+		}
+	}
+	return nil
+}
+
+// latest returns the latest between the two times.
+func latest(first, second time.Time) time.Time {
+	if second.After(first) {
+		return second
+	}
+	return first
+}
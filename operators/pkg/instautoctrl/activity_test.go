@@ -0,0 +1,270 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instautoctrl_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/instautoctrl"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/instmetrics"
+)
+
+// fakeMetricsServer is an instmetrics server returning a predefined CPU usage.
+type fakeMetricsServer struct {
+	instmetrics.UnimplementedInstanceMetricsServer
+	cpu float32
+}
+
+func (s *fakeMetricsServer) ContainerMetrics(_ context.Context, _ *instmetrics.ContainerMetricsRequest) (*instmetrics.ContainerMetricsResponse, error) {
+	return &instmetrics.ContainerMetricsResponse{CpuPerc: s.cpu}, nil
+}
+
+// serveJSON starts an HTTP server returning the given object, and returns its address.
+func serveJSON(obj interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		Expect(json.NewEncoder(w).Encode(obj)).To(Succeed())
+	}))
+	DeferCleanup(server.Close)
+	return server
+}
+
+// serverPort returns the port the given test server is listening on.
+func serverPort(server *httptest.Server) int {
+	parsed, err := url.Parse(server.URL)
+	Expect(err).ToNot(HaveOccurred())
+	port, err := strconv.Atoi(parsed.Port())
+	Expect(err).ToNot(HaveOccurred())
+	return port
+}
+
+var _ = Describe("The instance activity sources", func() {
+	var (
+		ctx         context.Context
+		instance    clv1alpha2.Instance
+		environment clv1alpha2.Environment
+		pod         v1.Pod
+	)
+
+	BeforeEach(func() {
+		Expect(clv1alpha2.AddToScheme(scheme.Scheme)).To(Succeed())
+		ctx = context.Background()
+
+		environment = clv1alpha2.Environment{Name: "app", EnvironmentType: clv1alpha2.ClassContainer}
+		instance = clv1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "tenant-tester"},
+			Spec: clv1alpha2.InstanceSpec{
+				Template: clv1alpha2.GenericRef{Name: "template", Namespace: "workspace-netgroup"},
+				Tenant:   clv1alpha2.GenericRef{Name: "tester"},
+			},
+		}
+		pod = v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "instance-pod", Namespace: instance.Namespace, Labels: forge.EnvironmentSelectorLabels(&instance, &environment)},
+			Spec:       v1.PodSpec{NodeName: "worker"},
+			Status:     v1.PodStatus{Phase: v1.PodRunning, PodIP: "127.0.0.1"},
+		}
+	})
+
+	Describe("The SSH activity source", func() {
+		var (
+			source     instautoctrl.SSHActivitySource
+			lastActive time.Time
+		)
+
+		BeforeEach(func() {
+			lastActive = time.Now().Add(-time.Hour).Truncate(time.Second)
+			server := serveJSON(instautoctrl.SSHActivityResponse{"10.0.0.1": lastActive, "10.0.0.2": time.Now()})
+			source = instautoctrl.SSHActivitySource{URL: server.URL, Timeout: time.Second}
+
+			environment = clv1alpha2.Environment{Name: "vm", EnvironmentType: clv1alpha2.ClassVM}
+			instance.Status.IP = "10.0.0.1"
+		})
+
+		It("Should observe only the non graphical VMs", func() {
+			Expect(source.Observes(&environment)).To(BeTrue())
+			Expect(source.Observes(&clv1alpha2.Environment{EnvironmentType: clv1alpha2.ClassCloudVM})).To(BeTrue())
+			Expect(source.Observes(&clv1alpha2.Environment{EnvironmentType: clv1alpha2.ClassVM, GuiEnabled: true})).To(BeFalse())
+			Expect(source.Observes(&clv1alpha2.Environment{EnvironmentType: clv1alpha2.ClassContainer})).To(BeFalse())
+		})
+
+		It("Should return the last activity associated with the instance IP", func() {
+			Expect(source.LastActivity(ctx, &instance, &environment)).To(BeTemporally("==", lastActive))
+		})
+
+		It("Should prefer the IP of the specific environment", func() {
+			instance.Status.Environments = []clv1alpha2.InstanceEnvironmentStatus{{Name: environment.Name, IP: "10.0.0.3"}}
+			Expect(source.LastActivity(ctx, &instance, &environment)).To(BeZero())
+		})
+
+		It("Should return the zero time when the instance has no IP", func() {
+			instance.Status.IP = ""
+			Expect(source.LastActivity(ctx, &instance, &environment)).To(BeZero())
+		})
+
+		It("Should fail when the tracker returns an error", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte("{}"))
+			}))
+			DeferCleanup(server.Close)
+			source.URL = server.URL
+
+			_, err := source.LastActivity(ctx, &instance, &environment)
+			Expect(err).To(MatchError(ContainSubstring("unexpected status code 500")))
+		})
+	})
+
+	Describe("The noVNC activity source", func() {
+		var (
+			source   instautoctrl.NoVNCActivitySource
+			response instautoctrl.NoVNCActivityResponse
+		)
+
+		BeforeEach(func() {
+			response = instautoctrl.NoVNCActivityResponse{LastActivity: time.Now().Add(-time.Hour).Truncate(time.Second)}
+		})
+
+		JustBeforeEach(func() {
+			server := serveJSON(response)
+			source = instautoctrl.NoVNCActivitySource{
+				Client:  fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&pod).Build(),
+				Port:    serverPort(server),
+				Timeout: time.Second,
+			}
+		})
+
+		It("Should observe only the container environments", func() {
+			Expect(source.Observes(&environment)).To(BeTrue())
+			Expect(source.Observes(&clv1alpha2.Environment{EnvironmentType: clv1alpha2.ClassStandalone})).To(BeFalse())
+			Expect(source.Observes(&clv1alpha2.Environment{EnvironmentType: clv1alpha2.ClassVM})).To(BeFalse())
+		})
+
+		It("Should return the time the last connection terminated", func() {
+			Expect(source.LastActivity(ctx, &instance, &environment)).To(BeTemporally("==", response.LastActivity))
+		})
+
+		When("a connection is active", func() {
+			BeforeEach(func() { response.ActiveConnections = 1 })
+
+			It("Should return the current time", func() {
+				Expect(source.LastActivity(ctx, &instance, &environment)).To(BeTemporally("~", time.Now(), time.Second))
+			})
+		})
+
+		When("the environment pod is not running", func() {
+			BeforeEach(func() { pod.Status.Phase = v1.PodPending })
+
+			It("Should return the zero time", func() {
+				Expect(source.LastActivity(ctx, &instance, &environment)).To(BeZero())
+			})
+		})
+	})
+
+	Describe("The CPU activity source", func() {
+		var (
+			source  *instautoctrl.CPUActivitySource
+			metrics fakeMetricsServer
+			servers v1.Pod
+			c       client.Client
+		)
+
+		BeforeEach(func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			metrics = fakeMetricsServer{cpu: 10}
+			server := grpc.NewServer()
+			instmetrics.RegisterInstanceMetricsServer(server, &metrics)
+			go func() { _ = server.Serve(listener) }()
+			DeferCleanup(server.Stop)
+
+			servers = v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "instmetrics-worker", Namespace: "crownlabs", Labels: map[string]string{"app": "instmetrics"}},
+				Spec:       v1.PodSpec{NodeName: "worker"},
+				Status:     v1.PodStatus{Phase: v1.PodRunning, PodIP: "127.0.0.1"},
+			}
+
+			source = &instautoctrl.CPUActivitySource{
+				Service:   types.NamespacedName{Name: "instmetrics", Namespace: "crownlabs"},
+				Port:      listener.Addr().(*net.TCPAddr).Port,
+				Threshold: 5,
+				Timeout:   time.Second,
+			}
+		})
+
+		JustBeforeEach(func() {
+			service := v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "instmetrics", Namespace: "crownlabs"},
+				Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "instmetrics"}},
+			}
+			c = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&pod, &service, &servers).Build()
+			source.Client = c
+		})
+
+		It("Should parse the instmetrics endpoint", func() {
+			parsed, err := instautoctrl.NewCPUActivitySource(nil, "instmetrics.crownlabs:9090", 5, time.Second)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Service).To(Equal(types.NamespacedName{Name: "instmetrics", Namespace: "crownlabs"}))
+			Expect(parsed.Port).To(Equal(9090))
+
+			_, err = instautoctrl.NewCPUActivitySource(nil, "instmetrics:9090", 5, time.Second)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should observe the container and standalone environments", func() {
+			Expect(source.Observes(&environment)).To(BeTrue())
+			Expect(source.Observes(&clv1alpha2.Environment{EnvironmentType: clv1alpha2.ClassStandalone})).To(BeTrue())
+			Expect(source.Observes(&clv1alpha2.Environment{EnvironmentType: clv1alpha2.ClassVM})).To(BeFalse())
+		})
+
+		It("Should return the current time when the usage is above the threshold", func() {
+			Expect(source.LastActivity(ctx, &instance, &environment)).To(BeTemporally("~", time.Now(), time.Second))
+		})
+
+		When("the usage is below the threshold", func() {
+			BeforeEach(func() { metrics.cpu = 1 })
+
+			It("Should return the zero time", func() {
+				Expect(source.LastActivity(ctx, &instance, &environment)).To(BeZero())
+			})
+		})
+
+		When("no instmetrics server runs on the node of the environment", func() {
+			BeforeEach(func() { servers.Spec.NodeName = "other" })
+
+			It("Should fail", func() {
+				_, err := source.LastActivity(ctx, &instance, &environment)
+				Expect(err).To(MatchError(ContainSubstring(`no instmetrics server available on node "worker"`)))
+			})
+		})
+	})
+})
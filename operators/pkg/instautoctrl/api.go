@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package instautoctrl contains the controller for Instance Termination and Submission automations.
+// Package instautoctrl contains the controllers for Instance Termination, Submission, Scheduling, Expiration and Idle automations.
 package instautoctrl
 
 import "time"
@@ -23,3 +23,13 @@ type StatusCheckResponse struct {
 	Deadline time.Time `json:"deadline,omitempty"`
 	ID       string    `json:"idnumber,omitempty"`
 }
+
+// NoVNCActivityResponse is the expected response from the activity endpoint exposed by the websockify sidecar.
+type NoVNCActivityResponse struct {
+	ActiveConnections int       `json:"activeConnections"`
+	LastActivity      time.Time `json:"lastActivity,omitempty"`
+}
+
+// SSHActivityResponse is the expected response from the activity endpoint exposed by the bastion SSH tracker,
+// mapping each destination IP address to the last time an SSH connection towards it was detected.
+type SSHActivityResponse map[string]time.Time
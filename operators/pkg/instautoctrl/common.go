@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package instautoctrl contains the controller for Instance Termination and Submission automations.
+// Package instautoctrl contains the controllers for Instance Termination, Submission, Scheduling, Expiration and Idle automations.
 package instautoctrl
 
 import (
@@ -22,13 +22,16 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
 )
 
-// RetrieveEnvironment retrieves the template associated to the given instance.
-func RetrieveEnvironment(ctx context.Context, c client.Client, instance *clv1alpha2.Instance) (*clv1alpha2.Environment, error) {
+// RetrieveEnvironments retrieves the environments of the template associated to the given instance.
+func RetrieveEnvironments(ctx context.Context, c client.Client, instance *clv1alpha2.Instance) ([]clv1alpha2.Environment, error) {
 	log := ctrl.LoggerFrom(ctx).V(utils.LogDebugLevel)
 
 	templateName := types.NamespacedName{
@@ -41,16 +44,32 @@ func RetrieveEnvironment(ctx context.Context, c client.Client, instance *clv1alp
 		return nil, fmt.Errorf("failed retrieving the instance template")
 	}
 
-	log.Info("retrieved the instance environment", "template", templateName)
+	log.Info("retrieved the instance environments", "template", templateName, "count", len(template.Spec.EnvironmentList))
 
-	if len(template.Spec.EnvironmentList) != 1 {
-		return nil, fmt.Errorf("only one environment per template is supported")
+	if len(template.Spec.EnvironmentList) == 0 {
+		return nil, fmt.Errorf("no environments defined in the instance template")
 	}
 
-	return &template.Spec.EnvironmentList[0], nil
+	return template.Spec.EnvironmentList, nil
 }
 
-// CheckEnvironmentValidity checks whether the given environment is valid and returns it (there must be one environment that must be persistent and contestDestination within instance spec customization urls must be present).
+// SubmittableEnvironments returns the subset of the given environments which are eligible for submission.
+func SubmittableEnvironments(instance *clv1alpha2.Instance, environments []clv1alpha2.Environment) ([]*clv1alpha2.Environment, error) {
+	var submittable []*clv1alpha2.Environment
+	var err error
+	for i := range environments {
+		if err = CheckEnvironmentValidity(instance, &environments[i]); err == nil {
+			submittable = append(submittable, &environments[i])
+		}
+	}
+
+	if len(submittable) == 0 {
+		return nil, err
+	}
+	return submittable, nil
+}
+
+// CheckEnvironmentValidity checks whether the given environment is valid for submission (the environment must be persistent and contentDestination within instance spec customization urls must be present).
 func CheckEnvironmentValidity(instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) error {
 	if instance.Spec.CustomizationUrls == nil || instance.Spec.CustomizationUrls.ContentDestination == "" {
 		return fmt.Errorf("missing content-destination field for instance")
@@ -62,3 +81,25 @@ func CheckEnvironmentValidity(instance *clv1alpha2.Instance, environment *clv1al
 
 	return nil
 }
+
+// TemplateToInstances returns a function mapping a template to a reconcile request for each instance referencing it.
+func TemplateToInstances(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		template, ok := o.(*clv1alpha2.Template)
+		if !ok {
+			return nil
+		}
+
+		var instances clv1alpha2.InstanceList
+		if err := c.List(ctx, &instances, client.MatchingLabels(forge.TemplateInstancesSelectorLabels(template))); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed listing instances referencing template", "template", client.ObjectKeyFromObject(template))
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(instances.Items))
+		for i := range instances.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&instances.Items[i])})
+		}
+		return requests
+	}
+}
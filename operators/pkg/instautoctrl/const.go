@@ -0,0 +1,58 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instautoctrl contains the controllers for Instance Termination, Submission, Scheduling, Expiration and Idle automations.
+package instautoctrl
+
+const (
+	// EvScheduleInvalid -> the event key corresponding to an invalid instance schedule.
+	EvScheduleInvalid = "InvalidSchedule"
+	// EvScheduleInvalidMsg -> the event message corresponding to an invalid instance schedule.
+	EvScheduleInvalidMsg = "Invalid schedule: %v"
+
+	// EvScheduledStart -> the event key corresponding to a scheduled instance start.
+	EvScheduledStart = "ScheduledStart"
+	// EvScheduledStartMsg -> the event message corresponding to a scheduled instance start.
+	EvScheduledStartMsg = "Instance started according to its schedule"
+
+	// EvScheduledStop -> the event key corresponding to a scheduled instance stop.
+	EvScheduledStop = "ScheduledStop"
+	// EvScheduledStopMsg -> the event message corresponding to a scheduled instance stop.
+	EvScheduledStopMsg = "Instance stopped according to its schedule"
+
+	// EvExpirationInvalid -> the event key corresponding to an invalid instance lifetime.
+	EvExpirationInvalid = "InvalidLifetime"
+	// EvExpirationInvalidMsg -> the event message corresponding to an invalid instance lifetime.
+	EvExpirationInvalidMsg = "Invalid lifetime: %v"
+
+	// EvExpirationWarning -> the event key corresponding to the upcoming expiration of an instance.
+	EvExpirationWarning = "ExpiringSoon"
+	// EvExpirationWarningMsg -> the event message corresponding to the upcoming expiration of an instance.
+	EvExpirationWarningMsg = "Instance lifetime expires in %s, after which it will be %s"
+
+	// EvExpired -> the event key corresponding to the expiration of an instance.
+	EvExpired = "Expired"
+	// EvExpiredMsg -> the event message corresponding to the expiration of an instance.
+	EvExpiredMsg = "Instance lifetime expired, hence it has been %s"
+
+	// EvExpiredDryRun -> the event key corresponding to the expiration of an instance, detected in dry-run mode.
+	EvExpiredDryRun = "ExpiredDryRun"
+	// EvExpiredDryRunMsg -> the event message corresponding to the expiration of an instance, detected in dry-run mode.
+	EvExpiredDryRunMsg = "Instance lifetime expired, hence it would have been %s (dry-run mode)"
+
+	// EvIdleStop -> the event key corresponding to the stop of an idle instance.
+	EvIdleStop = "IdleStop"
+	// EvIdleStopMsg -> the event message corresponding to the stop of an idle instance.
+	EvIdleStopMsg = "Instance stopped since no activity has been detected for %s"
+)
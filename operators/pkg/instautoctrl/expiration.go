@@ -0,0 +1,254 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instautoctrl contains the controllers for Instance Termination, Submission, Scheduling, Expiration and Idle automations.
+package instautoctrl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+// InstanceExpirationReconciler deletes (or stops) the instances whose lifetime is expired.
+type InstanceExpirationReconciler struct {
+	client.Client
+	EventsRecorder     record.EventRecorder
+	Scheme             *runtime.Scheme
+	NamespaceWhitelist metav1.LabelSelector
+	// The maximum interval between two subsequent checks of the same instance,
+	// which also determines how often the remaining lifetime is refreshed.
+	CheckInterval time.Duration
+	// The remaining lifetimes at which a warning event is emitted before expiration.
+	WarningThresholds []time.Duration
+	// Whether expired instances are only reported through an event, rather than being deleted or stopped.
+	DryRun bool
+	// This function, if configured, is deferred at the beginning of the Reconcile.
+	// Specifically, it is meant to be set to GinkgoRecover during the tests,
+	// in order to lead to a controlled failure in case the Reconcile panics.
+	ReconcileDeferHook func()
+}
+
+// SetupWithManager registers a new controller for InstanceExpirationReconciler resources.
+func (r *InstanceExpirationReconciler) SetupWithManager(mgr ctrl.Manager, concurrency int) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clv1alpha2.Instance{}).
+		// Watch templates as well, to react to the modification of the lifetime configured therein.
+		Watches(&clv1alpha2.Template{}, handler.EnqueueRequestsFromMapFunc(TemplateToInstances(r.Client))).
+		Named("instance-expiration").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: concurrency,
+		}).
+		WithLogConstructor(utils.LogConstructor(mgr.GetLogger(), "InstanceExpiration")).
+		Complete(r)
+}
+
+// Reconcile deletes or stops an Instance once its lifetime is expired, warning in advance about the upcoming expiration.
+func (r *InstanceExpirationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.ReconcileDeferHook != nil {
+		defer r.ReconcileDeferHook()
+	}
+
+	log := ctrl.LoggerFrom(ctx, "instance", req.NamespacedName)
+	dbgLog := log.V(utils.LogDebugLevel)
+	tracer := trace.New("reconcile", trace.Field{Key: "instance", Value: req.NamespacedName})
+	ctx = ctrl.LoggerInto(trace.ContextWithTrace(ctx, tracer), log)
+
+	defer tracer.LogIfLong(utils.LongThreshold())
+
+	// Get the instance object.
+	var instance clv1alpha2.Instance
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Error(err, "failed retrieving instance")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	tracer.Step("instance retrieved")
+
+	if !instance.GetDeletionTimestamp().IsZero() {
+		dbgLog.Info("skipping instance", "reason", "being deleted")
+		return ctrl.Result{}, nil
+	}
+
+	// Check the selector label, in order to know whether to perform or not reconciliation.
+	if proceed, err := utils.CheckSelectorLabel(ctx, r.Client, instance.GetNamespace(), r.NamespaceWhitelist.MatchLabels); !proceed {
+		if err != nil {
+			err = fmt.Errorf("failed checking selector label: %w", err)
+		}
+		return ctrl.Result{}, err
+	}
+	tracer.Step("labels checked")
+
+	templateName := types.NamespacedName{
+		Namespace: instance.Spec.Template.Namespace,
+		Name:      instance.Spec.Template.Name,
+	}
+	var template clv1alpha2.Template
+	if err := r.Get(ctx, templateName, &template); err != nil {
+		log.Error(err, "failed retrieving the instance template", "template", templateName)
+		return ctrl.Result{}, err
+	}
+	tracer.Step("template retrieved")
+
+	lifetime, limited, err := forge.InstanceLifetime(&template, &instance)
+	if err != nil {
+		// The lifetime is invalid, and it makes no sense to retry until it is modified.
+		log.Error(err, "invalid lifetime")
+		r.EventsRecorder.Eventf(&instance, v1.EventTypeWarning, EvExpirationInvalid, EvExpirationInvalidMsg, err)
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	original := instance.DeepCopy()
+	status := &instance.Status.Automation
+
+	if !limited {
+		status.ExpirationTime, status.RemainingLifetime, status.ExpirationWarningTime = metav1.Time{}, "", metav1.Time{}
+		dbgLog.Info("instance lifetime is not limited")
+		return ctrl.Result{}, r.patchStatus(ctx, original, &instance)
+	}
+
+	expiration := instance.GetCreationTimestamp().Add(lifetime)
+	remaining := max(expiration.Sub(now), 0)
+	policy := forge.InstanceExpirationPolicy(&template)
+
+	status.ExpirationTime = metav1.NewTime(expiration)
+	status.RemainingLifetime = remaining.Truncate(time.Minute).String()
+
+	if remaining == 0 {
+		return ctrl.Result{}, r.enforceExpiration(ctx, original, &instance, policy)
+	}
+
+	// Warn about the upcoming expiration, once for each of the thresholds crossed since the last warning.
+	if threshold, found := r.currentWarningThreshold(remaining); found && status.ExpirationWarningTime.Time.Before(expiration.Add(-threshold)) {
+		status.ExpirationWarningTime = metav1.NewTime(now)
+		r.EventsRecorder.Eventf(&instance, v1.EventTypeWarning, EvExpirationWarning, EvExpirationWarningMsg,
+			status.RemainingLifetime, expirationActionDescription(policy))
+		log.Info("instance expiring soon", "remaining", status.RemainingLifetime)
+	}
+
+	if err := r.patchStatus(ctx, original, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	requeue := r.nextCheckInterval(remaining)
+	dbgLog.Info("requeueing instance", "remaining", status.RemainingLifetime, "after", requeue)
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// enforceExpiration deletes or stops the given instance, depending on the expiration policy.
+func (r *InstanceExpirationReconciler) enforceExpiration(ctx context.Context, original, instance *clv1alpha2.Instance, policy clv1alpha2.ExpirationPolicy) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if r.DryRun {
+		if err := r.patchStatus(ctx, original, instance); err != nil {
+			return err
+		}
+		log.Info("expired instance left untouched", "reason", "dry-run mode", "policy", policy)
+		r.EventsRecorder.Eventf(instance, v1.EventTypeNormal, EvExpiredDryRun, EvExpiredDryRunMsg, expirationActionDescription(policy))
+		return nil
+	}
+
+	if policy == clv1alpha2.ExpirationPolicyDelete {
+		if err := r.Delete(ctx, instance); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "failed deleting expired instance")
+			return err
+		}
+		log.Info("expired instance deleted")
+		r.EventsRecorder.Eventf(instance, v1.EventTypeNormal, EvExpired, EvExpiredMsg, expirationActionDescription(policy))
+		return nil
+	}
+
+	if err := r.patchStatus(ctx, original, instance); err != nil {
+		return err
+	}
+
+	// Stop the instance whenever it is running, as the lifetime is expired and it cannot be used anymore.
+	if instance.Spec.Running {
+		original = instance.DeepCopy()
+		instance.Spec.Running = false
+		if err := r.Patch(ctx, instance, client.MergeFrom(original)); err != nil {
+			log.Error(err, "failed stopping expired instance")
+			return err
+		}
+		log.Info("expired instance stopped")
+		r.EventsRecorder.Eventf(instance, v1.EventTypeNormal, EvExpired, EvExpiredMsg, expirationActionDescription(policy))
+	}
+	return nil
+}
+
+// patchStatus patches the status of the given instance, in case it has been modified.
+func (r *InstanceExpirationReconciler) patchStatus(ctx context.Context, original, instance *clv1alpha2.Instance) error {
+	if reflect.DeepEqual(original.Status, instance.Status) {
+		return nil
+	}
+
+	if err := r.Status().Patch(ctx, instance, client.MergeFrom(original)); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed updating instance status")
+		return err
+	}
+	return nil
+}
+
+// currentWarningThreshold returns the smallest warning threshold which is greater than or equal to the remaining lifetime, if any.
+func (r *InstanceExpirationReconciler) currentWarningThreshold(remaining time.Duration) (threshold time.Duration, found bool) {
+	for _, candidate := range r.WarningThresholds {
+		if candidate >= remaining && (!found || candidate < threshold) {
+			threshold, found = candidate, true
+		}
+	}
+	return threshold, found
+}
+
+// nextCheckInterval returns the time to wait before checking again the instance, to refresh its remaining lifetime,
+// emit the next warning or enforce the expiration, whichever comes first.
+func (r *InstanceExpirationReconciler) nextCheckInterval(remaining time.Duration) time.Duration {
+	interval := remaining
+	if r.CheckInterval > 0 {
+		interval = min(interval, r.CheckInterval)
+	}
+
+	for _, threshold := range r.WarningThresholds {
+		if threshold < remaining {
+			interval = min(interval, remaining-threshold)
+		}
+	}
+	return interval
+}
+
+// expirationActionDescription returns a human readable description of the action performed according to the given policy.
+func expirationActionDescription(policy clv1alpha2.ExpirationPolicy) string {
+	if policy == clv1alpha2.ExpirationPolicyStop {
+		return "stopped"
+	}
+	return "deleted"
+}
@@ -0,0 +1,88 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instautoctrl_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/instautoctrl"
+)
+
+var _ = Describe("The instance expiration controller", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		recorder   *record.FakeRecorder
+		reconciler *instautoctrl.InstanceExpirationReconciler
+		instance   clv1alpha2.Instance
+	)
+
+	BeforeEach(func() {
+		Expect(clv1alpha2.AddToScheme(scheme.Scheme)).To(Succeed())
+		ctx = context.Background()
+
+		template := clv1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "workspace-netgroup"},
+			Spec:       clv1alpha2.TemplateSpec{DeleteAfter: "1h"},
+		}
+		instance = clv1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "tenant-tester",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Spec: clv1alpha2.InstanceSpec{Running: true, Template: clv1alpha2.GenericRef{Name: template.Name, Namespace: template.Namespace}},
+		}
+
+		c = fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: instance.Namespace}}, &template, &instance).
+			WithStatusSubresource(&instance).Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &instautoctrl.InstanceExpirationReconciler{Client: c, EventsRecorder: recorder, CheckInterval: time.Hour}
+	})
+
+	JustBeforeEach(func() {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&instance)})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	When("the dry-run mode is enabled", func() {
+		BeforeEach(func() { reconciler.DryRun = true })
+
+		It("Should only emit an event for the expired instance", func() {
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(&instance), &instance)).To(Succeed())
+			Expect(instance.Status.Automation.RemainingLifetime).To(Equal("0s"))
+			Expect(recorder.Events).To(Receive(ContainSubstring(instautoctrl.EvExpiredDryRun)))
+		})
+	})
+
+	When("the dry-run mode is disabled", func() {
+		It("Should delete the expired instance", func() {
+			err := c.Get(ctx, client.ObjectKeyFromObject(&instance), &instance)
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring(instautoctrl.EvExpired)))
+		})
+	})
+})
@@ -0,0 +1,250 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instautoctrl contains the controllers for Instance Termination, Submission, Scheduling, Expiration and Idle automations.
+package instautoctrl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+// InstanceIdleReconciler stops the instances which have not been used for longer than the idle timeout.
+type InstanceIdleReconciler struct {
+	client.Client
+	EventsRecorder     record.EventRecorder
+	Scheme             *runtime.Scheme
+	NamespaceWhitelist metav1.LabelSelector
+	// The signals leveraged to detect the usage of the instances.
+	Sources []ActivitySource
+	// The interval between two subsequent checks of the activity of the same instance.
+	CheckInterval time.Duration
+	// This function, if configured, is deferred at the beginning of the Reconcile.
+	// Specifically, it is meant to be set to GinkgoRecover during the tests,
+	// in order to lead to a controlled failure in case the Reconcile panics.
+	ReconcileDeferHook func()
+}
+
+// SetupWithManager registers a new controller for InstanceIdleReconciler resources.
+func (r *InstanceIdleReconciler) SetupWithManager(mgr ctrl.Manager, concurrency int) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// Status changes are ignored, to prevent the updates of the last activity time from triggering a new
+		// reconciliation, while the status of the instance is anyhow checked periodically.
+		For(&clv1alpha2.Instance{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Watch templates as well, to react to the modification of the idle timeout configured therein.
+		Watches(&clv1alpha2.Template{}, handler.EnqueueRequestsFromMapFunc(TemplateToInstances(r.Client))).
+		Named("instance-idle").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: concurrency,
+		}).
+		WithLogConstructor(utils.LogConstructor(mgr.GetLogger(), "InstanceIdle")).
+		Complete(r)
+}
+
+// Reconcile tracks the activity of a running Instance, and stops it once idle for longer than the timeout configured in the Template.
+func (r *InstanceIdleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.ReconcileDeferHook != nil {
+		defer r.ReconcileDeferHook()
+	}
+
+	log := ctrl.LoggerFrom(ctx, "instance", req.NamespacedName)
+	dbgLog := log.V(utils.LogDebugLevel)
+	tracer := trace.New("reconcile", trace.Field{Key: "instance", Value: req.NamespacedName})
+	ctx = ctrl.LoggerInto(trace.ContextWithTrace(ctx, tracer), log)
+
+	defer tracer.LogIfLong(utils.LongThreshold())
+
+	// Get the instance object.
+	var instance clv1alpha2.Instance
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Error(err, "failed retrieving instance")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	tracer.Step("instance retrieved")
+
+	if !instance.Spec.Running || !instance.GetDeletionTimestamp().IsZero() {
+		dbgLog.Info("skipping instance", "reason", "not running")
+		return ctrl.Result{}, nil
+	}
+
+	// Check the selector label, in order to know whether to perform or not reconciliation.
+	if proceed, err := utils.CheckSelectorLabel(ctx, r.Client, instance.GetNamespace(), r.NamespaceWhitelist.MatchLabels); !proceed {
+		if err != nil {
+			err = fmt.Errorf("failed checking selector label: %w", err)
+		}
+		return ctrl.Result{}, err
+	}
+	tracer.Step("labels checked")
+
+	// The instance has been restarted since it was stopped due to inactivity, hence the reason is cleared,
+	// and the activity is tracked from scratch.
+	if instance.Status.Automation.StopReason != "" {
+		original := instance.DeepCopy()
+		instance.Status.Automation.StopReason = ""
+		instance.Status.Automation.LastActivityTime = metav1.Now()
+		if err := r.patchStatus(ctx, original, &instance); err != nil {
+			return ctrl.Result{}, err
+		}
+		tracer.Step("stop reason cleared")
+	}
+
+	templateName := types.NamespacedName{
+		Namespace: instance.Spec.Template.Namespace,
+		Name:      instance.Spec.Template.Name,
+	}
+	var template clv1alpha2.Template
+	if err := r.Get(ctx, templateName, &template); err != nil {
+		log.Error(err, "failed retrieving the instance template", "template", templateName)
+		return ctrl.Result{}, err
+	}
+	tracer.Step("template retrieved")
+
+	timeout, limited, err := forge.ParseLifetime(template.Spec.IdleTimeout)
+	if err != nil || !limited {
+		dbgLog.Info("skipping instance", "reason", "idle timeout not configured", "error", err)
+		return ctrl.Result{}, nil
+	}
+
+	// Instances are stopped only if the usage of all their environments can be detected,
+	// to prevent interrupting the ones accessed through unobservable channels.
+	signals, observable := r.observingSources(&template)
+	if !observable {
+		dbgLog.Info("skipping instance", "reason", "activity not observable")
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	original := instance.DeepCopy()
+	status := &instance.Status.Automation
+
+	status.LastActivityTime = metav1.NewTime(r.lastActivity(ctx, &instance, &template, now))
+	tracer.Step("activity retrieved")
+
+	idle := now.Sub(status.LastActivityTime.Time)
+	if idle < timeout {
+		if err := r.patchStatus(ctx, original, &instance); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		requeue := min(r.CheckInterval, timeout-idle)
+		dbgLog.Info("requeueing instance", "last-activity", status.LastActivityTime, "after", requeue)
+		return ctrl.Result{RequeueAfter: requeue}, nil
+	}
+
+	status.StopReason = fmt.Sprintf("No activity detected since %s (signals: %s)",
+		status.LastActivityTime.UTC().Format(time.RFC3339), strings.Join(signals, ", "))
+	if err := r.patchStatus(ctx, original, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	original = instance.DeepCopy()
+	instance.Spec.Running = false
+	if err := r.Patch(ctx, &instance, client.MergeFrom(original)); err != nil {
+		log.Error(err, "failed stopping idle instance")
+		return ctrl.Result{}, err
+	}
+	tracer.Step("instance stopped")
+
+	log.Info("idle instance stopped", "last-activity", status.LastActivityTime)
+	r.EventsRecorder.Eventf(&instance, v1.EventTypeNormal, EvIdleStop, EvIdleStopMsg, idle.Truncate(time.Minute))
+	return ctrl.Result{}, nil
+}
+
+// observingSources returns the names of the signals observing at least one of the environments of the given template,
+// and whether each environment is observed by at least one signal.
+func (r *InstanceIdleReconciler) observingSources(template *clv1alpha2.Template) (signals []string, observable bool) {
+	used := make(map[string]bool)
+	for i := range template.Spec.EnvironmentList {
+		observed := false
+		for _, source := range r.Sources {
+			if source.Observes(&template.Spec.EnvironmentList[i]) {
+				observed = true
+				if !used[source.Name()] {
+					used[source.Name()] = true
+					signals = append(signals, source.Name())
+				}
+			}
+		}
+		if !observed {
+			return nil, false
+		}
+	}
+	return signals, len(template.Spec.EnvironmentList) > 0
+}
+
+// lastActivity returns the most recent time the given instance was observed being used, according to all signals.
+// Instances which are not ready yet are considered in use, as well as those whose activity cannot be retrieved,
+// to prevent stopping them due to transient errors.
+func (r *InstanceIdleReconciler) lastActivity(ctx context.Context, instance *clv1alpha2.Instance, template *clv1alpha2.Template, now time.Time) time.Time {
+	log := ctrl.LoggerFrom(ctx)
+
+	last := instance.Status.Automation.LastActivityTime.Time
+	if last.IsZero() || instance.Status.Phase != clv1alpha2.EnvironmentPhaseReady {
+		return now
+	}
+
+	for i := range template.Spec.EnvironmentList {
+		environment := &template.Spec.EnvironmentList[i]
+		for _, source := range r.Sources {
+			if !source.Observes(environment) {
+				continue
+			}
+
+			activity, err := source.LastActivity(ctx, instance, environment)
+			if err != nil {
+				log.Error(err, "failed retrieving activity, assuming the instance is in use", "environment", environment.Name, "signal", source.Name())
+				return now
+			}
+			last = latest(last, activity)
+		}
+	}
+
+	return last
+}
+
+// patchStatus patches the status of the given instance, in case it has been modified.
+func (r *InstanceIdleReconciler) patchStatus(ctx context.Context, original, instance *clv1alpha2.Instance) error {
+	if reflect.DeepEqual(original.Status, instance.Status) {
+		return nil
+	}
+
+	if err := r.Status().Patch(ctx, instance, client.MergeFrom(original)); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed updating instance status")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instautoctrl_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/instautoctrl"
+)
+
+// fakeActivitySource is an ActivitySource returning a predefined activity for the container environments.
+type fakeActivitySource struct {
+	activity time.Time
+	err      error
+}
+
+func (s *fakeActivitySource) Name() string { return "fake" }
+
+func (s *fakeActivitySource) Observes(environment *clv1alpha2.Environment) bool {
+	return environment.EnvironmentType == clv1alpha2.ClassContainer
+}
+
+func (s *fakeActivitySource) LastActivity(_ context.Context, _ *clv1alpha2.Instance, _ *clv1alpha2.Environment) (time.Time, error) {
+	return s.activity, s.err
+}
+
+var _ = Describe("The instance idle controller", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		recorder   *record.FakeRecorder
+		source     *fakeActivitySource
+		template   clv1alpha2.Template
+		instance   clv1alpha2.Instance
+		reconciler *instautoctrl.InstanceIdleReconciler
+		lastActive time.Time
+	)
+
+	BeforeEach(func() {
+		Expect(clv1alpha2.AddToScheme(scheme.Scheme)).To(Succeed())
+		ctx = context.Background()
+		lastActive = time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+
+		template = clv1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "workspace-netgroup"},
+			Spec: clv1alpha2.TemplateSpec{IdleTimeout: "1h", EnvironmentList: []clv1alpha2.Environment{
+				{Name: "app", EnvironmentType: clv1alpha2.ClassContainer},
+			}},
+		}
+		instance = clv1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "tenant-tester"},
+			Spec:       clv1alpha2.InstanceSpec{Running: true, Template: clv1alpha2.GenericRef{Name: template.Name, Namespace: template.Namespace}},
+			Status: clv1alpha2.InstanceStatus{
+				Phase:      clv1alpha2.EnvironmentPhaseReady,
+				Automation: clv1alpha2.InstanceAutomationStatus{LastActivityTime: metav1.NewTime(lastActive)},
+			},
+		}
+		source = &fakeActivitySource{activity: lastActive}
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	JustBeforeEach(func() {
+		c = fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: instance.Namespace}}, &template, &instance).
+			WithStatusSubresource(&instance).Build()
+		reconciler = &instautoctrl.InstanceIdleReconciler{
+			Client: c, EventsRecorder: recorder, CheckInterval: 5 * time.Minute,
+			Sources: []instautoctrl.ActivitySource{source},
+		}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&instance)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(&instance), &instance)).To(Succeed())
+	})
+
+	expectRunning := func() {
+		Expect(instance.Spec.Running).To(BeTrue())
+		Expect(instance.Status.Automation.StopReason).To(BeEmpty())
+		Expect(recorder.Events).ToNot(Receive())
+	}
+
+	When("no activity is detected for longer than the idle timeout", func() {
+		It("Should stop the instance, reporting the reason", func() {
+			Expect(instance.Spec.Running).To(BeFalse())
+			Expect(instance.Status.Automation.StopReason).To(ContainSubstring("No activity detected"))
+			Expect(instance.Status.Automation.LastActivityTime.Time).To(BeTemporally("==", lastActive))
+			Expect(recorder.Events).To(Receive(ContainSubstring(instautoctrl.EvIdleStop)))
+		})
+	})
+
+	When("the instance has been recently used", func() {
+		BeforeEach(func() { source.activity = time.Now().Add(-10 * time.Minute).Truncate(time.Second) })
+
+		It("Should keep the instance running, updating the last activity", func() {
+			expectRunning()
+			Expect(instance.Status.Automation.LastActivityTime.Time).To(BeTemporally("==", source.activity))
+		})
+	})
+
+	When("the activity cannot be retrieved", func() {
+		BeforeEach(func() { source.err = errors.New("unavailable") })
+
+		It("Should consider the instance in use", expectRunning)
+	})
+
+	When("the instance is not ready", func() {
+		BeforeEach(func() { instance.Status.Phase = clv1alpha2.EnvironmentPhaseStarting })
+
+		It("Should consider the instance in use", expectRunning)
+	})
+
+	When("the activity is tracked for the first time", func() {
+		BeforeEach(func() { instance.Status.Automation.LastActivityTime = metav1.Time{} })
+
+		It("Should consider the instance in use", expectRunning)
+	})
+
+	When("an environment is not observed by any signal", func() {
+		BeforeEach(func() {
+			template.Spec.EnvironmentList = append(template.Spec.EnvironmentList,
+				clv1alpha2.Environment{Name: "vm", EnvironmentType: clv1alpha2.ClassVM, GuiEnabled: true})
+		})
+
+		It("Should not stop the instance", expectRunning)
+	})
+
+	When("the idle timeout is not configured", func() {
+		BeforeEach(func() { template.Spec.IdleTimeout = "" })
+
+		It("Should not stop the instance", expectRunning)
+	})
+
+	When("the instance is restarted after being stopped due to inactivity", func() {
+		BeforeEach(func() {
+			instance.Status.Automation.StopReason = "No activity detected"
+			instance.Status.Phase = clv1alpha2.EnvironmentPhaseStarting
+		})
+
+		It("Should clear the stop reason", func() {
+			expectRunning()
+			Expect(instance.Status.Automation.LastActivityTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+	})
+})
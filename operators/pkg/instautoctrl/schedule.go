@@ -0,0 +1,310 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instautoctrl contains the controllers for Instance Termination, Submission, Scheduling, Expiration and Idle automations.
+package instautoctrl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/cron"
+)
+
+// InstanceScheduleReconciler starts and stops instances according to their schedule.
+type InstanceScheduleReconciler struct {
+	client.Client
+	EventsRecorder     record.EventRecorder
+	Scheme             *runtime.Scheme
+	NamespaceWhitelist metav1.LabelSelector
+	// This function, if configured, is deferred at the beginning of the Reconcile.
+	// Specifically, it is meant to be set to GinkgoRecover during the tests,
+	// in order to lead to a controlled failure in case the Reconcile panics.
+	ReconcileDeferHook func()
+}
+
+// SetupWithManager registers a new controller for InstanceScheduleReconciler resources.
+func (r *InstanceScheduleReconciler) SetupWithManager(mgr ctrl.Manager, concurrency int) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clv1alpha2.Instance{}).
+		// Watch templates as well, to react to the modification of the schedule configured therein.
+		Watches(&clv1alpha2.Template{}, handler.EnqueueRequestsFromMapFunc(TemplateToInstances(r.Client))).
+		Named("instance-schedule").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: concurrency,
+		}).
+		WithLogConstructor(utils.LogConstructor(mgr.GetLogger(), "InstanceSchedule")).
+		Complete(r)
+}
+
+// Reconcile reconciles the running state of an Instance according to its schedule.
+func (r *InstanceScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.ReconcileDeferHook != nil {
+		defer r.ReconcileDeferHook()
+	}
+
+	log := ctrl.LoggerFrom(ctx, "instance", req.NamespacedName)
+	dbgLog := log.V(utils.LogDebugLevel)
+	tracer := trace.New("reconcile", trace.Field{Key: "instance", Value: req.NamespacedName})
+	ctx = ctrl.LoggerInto(trace.ContextWithTrace(ctx, tracer), log)
+
+	defer tracer.LogIfLong(utils.LongThreshold())
+
+	// Get the instance object.
+	var instance clv1alpha2.Instance
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Error(err, "failed retrieving instance")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	tracer.Step("instance retrieved")
+
+	// Check the selector label, in order to know whether to perform or not reconciliation.
+	if proceed, err := utils.CheckSelectorLabel(ctx, r.Client, instance.GetNamespace(), r.NamespaceWhitelist.MatchLabels); !proceed {
+		if err != nil {
+			err = fmt.Errorf("failed checking selector label: %w", err)
+		}
+		return ctrl.Result{}, err
+	}
+	tracer.Step("labels checked")
+
+	schedule, err := r.RetrieveSchedule(ctx, &instance)
+	if err != nil {
+		log.Error(err, "failed retrieving schedule")
+		return ctrl.Result{}, err
+	}
+	tracer.Step("schedule retrieved")
+
+	now := time.Now()
+	var nextStart, nextStop time.Time
+	if schedule != nil {
+		if nextStart, nextStop, err = NextScheduledTransitions(schedule, now); err != nil {
+			// The schedule is invalid, and it makes no sense to retry until it is modified.
+			log.Error(err, "invalid schedule")
+			r.EventsRecorder.Eventf(&instance, v1.EventTypeWarning, EvScheduleInvalid, EvScheduleInvalidMsg, err)
+			return ctrl.Result{}, nil
+		}
+
+		// Apply the transition which is due according to the previously computed times, if any.
+		running, due := DueScheduledTransition(&instance.Status.Automation, now)
+		if instance.Status.Automation.NextStartTime.IsZero() && instance.Status.Automation.NextStopTime.IsZero() {
+			// The schedule is evaluated for the first time (e.g. the instance has just been created), hence the
+			// instance is started in case it falls within a window. Instances outside any window are left untouched,
+			// to avoid stopping them as soon as they are created (e.g. with schedules specifying only the stop).
+			within, err := WithinScheduledWindow(schedule, now)
+			if err != nil {
+				log.Error(err, "invalid schedule")
+				r.EventsRecorder.Eventf(&instance, v1.EventTypeWarning, EvScheduleInvalid, EvScheduleInvalidMsg, err)
+				return ctrl.Result{}, nil
+			}
+			running, due = true, within
+		}
+
+		if due && instance.Spec.Running != running {
+			instance.Spec.Running = running
+			if err := r.Update(ctx, &instance); err != nil {
+				log.Error(err, "failed updating instance running state")
+				return ctrl.Result{}, err
+			}
+			tracer.Step("instance running state updated")
+			log.Info("instance running state updated according to schedule", "running", running)
+
+			if running {
+				r.EventsRecorder.Event(&instance, v1.EventTypeNormal, EvScheduledStart, EvScheduledStartMsg)
+			} else {
+				r.EventsRecorder.Event(&instance, v1.EventTypeNormal, EvScheduledStop, EvScheduledStopMsg)
+			}
+		}
+	}
+
+	// Report the upcoming transitions in the instance status.
+	if !instance.Status.Automation.NextStartTime.Equal(&metav1.Time{Time: nextStart}) ||
+		!instance.Status.Automation.NextStopTime.Equal(&metav1.Time{Time: nextStop}) {
+		instance.Status.Automation.NextStartTime = metav1.NewTime(nextStart)
+		instance.Status.Automation.NextStopTime = metav1.NewTime(nextStop)
+		if err := r.Status().Update(ctx, &instance); err != nil {
+			log.Error(err, "failed updating instance status")
+			return ctrl.Result{}, err
+		}
+		tracer.Step("instance status updated")
+	}
+
+	next := earliest(nextStart, nextStop)
+	if next.IsZero() {
+		dbgLog.Info("no scheduled transition")
+		return ctrl.Result{}, nil
+	}
+
+	dbgLog.Info("requeueing instance", "next-transition", next)
+	return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+}
+
+// RetrieveSchedule returns the schedule of the given instance, either configured in the instance itself or inherited from its template.
+func (r *InstanceScheduleReconciler) RetrieveSchedule(ctx context.Context, instance *clv1alpha2.Instance) (*clv1alpha2.InstanceSchedule, error) {
+	if instance.Spec.Schedule != nil {
+		return instance.Spec.Schedule, nil
+	}
+
+	templateName := types.NamespacedName{
+		Namespace: instance.Spec.Template.Namespace,
+		Name:      instance.Spec.Template.Name,
+	}
+
+	var template clv1alpha2.Template
+	if err := r.Get(ctx, templateName, &template); err != nil {
+		return nil, fmt.Errorf("failed retrieving the instance template: %w", err)
+	}
+
+	return template.Spec.Schedule, nil
+}
+
+// NextScheduledTransitions returns the first start and stop times strictly after the given one, according to
+// the specified schedule. Zero values are returned in case no transition of the given kind is configured.
+func NextScheduledTransitions(schedule *clv1alpha2.InstanceSchedule, now time.Time) (nextStart, nextStop time.Time, err error) {
+	location, err := scheduleLocation(schedule)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	now = now.In(location)
+	for i := range schedule.Windows {
+		window := &schedule.Windows[i]
+
+		if nextStart, err = nextActivation(window.Start, now, nextStart); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start of window %d: %w", i, err)
+		}
+		if nextStop, err = nextActivation(window.Stop, now, nextStop); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid stop of window %d: %w", i, err)
+		}
+	}
+
+	return nextStart, nextStop, nil
+}
+
+// WithinScheduledWindow returns whether the given time falls within one of the windows of the specified schedule,
+// i.e., whether a start occurred (up to the given time included), and it is more recent than the last stop.
+func WithinScheduledWindow(schedule *clv1alpha2.InstanceSchedule, now time.Time) (bool, error) {
+	location, err := scheduleLocation(schedule)
+	if err != nil {
+		return false, err
+	}
+
+	// The given time is included, since NextScheduledTransitions considers only the subsequent ones.
+	now = now.In(location).Add(time.Nanosecond)
+	var lastStart, lastStop time.Time
+	for i := range schedule.Windows {
+		window := &schedule.Windows[i]
+
+		if lastStart, err = prevActivation(window.Start, now, lastStart); err != nil {
+			return false, fmt.Errorf("invalid start of window %d: %w", i, err)
+		}
+		if lastStop, err = prevActivation(window.Stop, now, lastStop); err != nil {
+			return false, fmt.Errorf("invalid stop of window %d: %w", i, err)
+		}
+	}
+
+	return !lastStart.IsZero() && lastStart.After(lastStop), nil
+}
+
+// DueScheduledTransition returns whether a scheduled transition is due at the given time, according to the
+// upcoming transitions previously stored in the instance status, and the corresponding running state.
+// In case both a start and a stop are due (e.g. because the controller was temporarily unavailable),
+// the most recent one prevails.
+func DueScheduledTransition(status *clv1alpha2.InstanceAutomationStatus, now time.Time) (running, due bool) {
+	startDue := !status.NextStartTime.IsZero() && !status.NextStartTime.After(now)
+	stopDue := !status.NextStopTime.IsZero() && !status.NextStopTime.After(now)
+
+	switch {
+	case startDue && stopDue:
+		return status.NextStartTime.After(status.NextStopTime.Time), true
+	case startDue:
+		return true, true
+	case stopDue:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// nextActivation returns the earliest between the current value and the next activation of the given cron expression.
+func nextActivation(expr string, now, current time.Time) (time.Time, error) {
+	if expr == "" {
+		return current, nil
+	}
+
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return earliest(current, schedule.Next(now)), nil
+}
+
+// prevActivation returns the latest between the current value and the previous activation of the given cron expression.
+func prevActivation(expr string, now, current time.Time) (time.Time, error) {
+	if expr == "" {
+		return current, nil
+	}
+
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if prev := schedule.Prev(now); prev.After(current) {
+		return prev, nil
+	}
+	return current, nil
+}
+
+// scheduleLocation returns the location the cron expressions of the given schedule are evaluated in.
+func scheduleLocation(schedule *clv1alpha2.InstanceSchedule) (*time.Location, error) {
+	if schedule.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", schedule.Timezone, err)
+	}
+	return location, nil
+}
+
+// earliest returns the earliest between the two times, ignoring zero values.
+func earliest(first, second time.Time) time.Time {
+	switch {
+	case first.IsZero():
+		return second
+	case second.IsZero():
+		return first
+	case second.Before(first):
+		return second
+	default:
+		return first
+	}
+}
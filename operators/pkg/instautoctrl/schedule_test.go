@@ -0,0 +1,143 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instautoctrl_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/instautoctrl"
+)
+
+var _ = Describe("Instance schedules", func() {
+	// A window from 8:00 to 18:00 on working days, evaluated in UTC.
+	schedule := clv1alpha2.InstanceSchedule{Windows: []clv1alpha2.InstanceScheduleWindow{
+		{Start: "0 8 * * mon-fri", Stop: "0 18 * * mon-fri"},
+	}}
+
+	// Monday, March 10th 2025.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	Describe("The NextScheduledTransitions function", func() {
+		type TransitionsCase struct {
+			Now           time.Time
+			ExpectedStart time.Time
+			ExpectedStop  time.Time
+		}
+
+		DescribeTable("Correctly returns the upcoming transitions",
+			func(c TransitionsCase) {
+				start, stop, err := instautoctrl.NextScheduledTransitions(&schedule, c.Now)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(start).To(BeTemporally("==", c.ExpectedStart))
+				Expect(stop).To(BeTemporally("==", c.ExpectedStop))
+			},
+			Entry("Before the window", TransitionsCase{Now: at(10, 7, 59), ExpectedStart: at(10, 8, 0), ExpectedStop: at(10, 18, 0)}),
+			Entry("At the start of the window", TransitionsCase{Now: at(10, 8, 0), ExpectedStart: at(11, 8, 0), ExpectedStop: at(10, 18, 0)}),
+			Entry("Within the window", TransitionsCase{Now: at(10, 12, 0), ExpectedStart: at(11, 8, 0), ExpectedStop: at(10, 18, 0)}),
+			Entry("At the end of the window", TransitionsCase{Now: at(10, 18, 0), ExpectedStart: at(11, 8, 0), ExpectedStop: at(11, 18, 0)}),
+			Entry("During the weekend", TransitionsCase{Now: at(15, 12, 0), ExpectedStart: at(17, 8, 0), ExpectedStop: at(17, 18, 0)}),
+		)
+
+		It("Should consider the configured timezone", func() {
+			start, _, err := instautoctrl.NextScheduledTransitions(&clv1alpha2.InstanceSchedule{
+				Timezone: "Europe/Rome",
+				Windows:  []clv1alpha2.InstanceScheduleWindow{{Start: "0 8 * * *"}},
+			}, at(10, 6, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start).To(BeTemporally("==", at(10, 7, 0)))
+		})
+
+		It("Should return zero values for the missing transitions", func() {
+			start, stop, err := instautoctrl.NextScheduledTransitions(&clv1alpha2.InstanceSchedule{
+				Windows: []clv1alpha2.InstanceScheduleWindow{{Stop: "0 18 * * *"}},
+			}, at(10, 12, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start.IsZero()).To(BeTrue())
+			Expect(stop).To(BeTemporally("==", at(10, 18, 0)))
+		})
+
+		It("Should fail in case of invalid schedules", func() {
+			_, _, err := instautoctrl.NextScheduledTransitions(&clv1alpha2.InstanceSchedule{
+				Windows: []clv1alpha2.InstanceScheduleWindow{{Start: "invalid"}},
+			}, at(10, 12, 0))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("The DueScheduledTransition function", func() {
+		type DueCase struct {
+			NextStart       time.Time
+			NextStop        time.Time
+			Now             time.Time
+			ExpectedRunning bool
+			ExpectedDue     bool
+		}
+
+		DescribeTable("Correctly returns the transition which is due",
+			func(c DueCase) {
+				status := clv1alpha2.InstanceAutomationStatus{NextStartTime: metav1.NewTime(c.NextStart), NextStopTime: metav1.NewTime(c.NextStop)}
+				running, due := instautoctrl.DueScheduledTransition(&status, c.Now)
+				Expect(due).To(Equal(c.ExpectedDue))
+				Expect(running).To(Equal(c.ExpectedRunning))
+			},
+			Entry("When no transition is stored", DueCase{Now: at(10, 12, 0)}),
+			Entry("When no transition is due yet", DueCase{NextStart: at(10, 8, 0), NextStop: at(10, 18, 0), Now: at(10, 7, 59)}),
+			Entry("When the start is exactly due", DueCase{NextStart: at(10, 8, 0), NextStop: at(10, 18, 0), Now: at(10, 8, 0),
+				ExpectedRunning: true, ExpectedDue: true}),
+			Entry("When the stop is exactly due", DueCase{NextStart: at(11, 8, 0), NextStop: at(10, 18, 0), Now: at(10, 18, 0),
+				ExpectedRunning: false, ExpectedDue: true}),
+			Entry("When both are due and the start is the most recent", DueCase{NextStart: at(11, 8, 0), NextStop: at(10, 18, 0), Now: at(11, 9, 0),
+				ExpectedRunning: true, ExpectedDue: true}),
+			Entry("When both are due and the stop is the most recent", DueCase{NextStart: at(10, 8, 0), NextStop: at(10, 18, 0), Now: at(10, 19, 0),
+				ExpectedRunning: false, ExpectedDue: true}),
+		)
+	})
+
+	Describe("The WithinScheduledWindow function", func() {
+		DescribeTable("Correctly returns whether the instance is created within a window",
+			func(s clv1alpha2.InstanceSchedule, now time.Time, expected bool) {
+				Expect(instautoctrl.WithinScheduledWindow(&s, now)).To(Equal(expected))
+			},
+			Entry("Before the window", schedule, at(10, 7, 59), false),
+			Entry("At the start of the window", schedule, at(10, 8, 0), true),
+			Entry("Within the window", schedule, at(10, 12, 0), true),
+			Entry("Right before the end of the window", schedule, at(10, 17, 59), true),
+			Entry("At the end of the window", schedule, at(10, 18, 0), false),
+			Entry("During the weekend", schedule, at(15, 12, 0), false),
+			Entry("With a schedule specifying only the stop", clv1alpha2.InstanceSchedule{
+				Windows: []clv1alpha2.InstanceScheduleWindow{{Stop: "0 18 * * *"}},
+			}, at(10, 12, 0), false),
+			Entry("Within the second of multiple windows", clv1alpha2.InstanceSchedule{
+				Windows: []clv1alpha2.InstanceScheduleWindow{
+					{Start: "0 8 * * *", Stop: "0 10 * * *"},
+					{Start: "0 14 * * *", Stop: "0 16 * * *"},
+				},
+			}, at(10, 15, 0), true),
+			Entry("Between multiple windows", clv1alpha2.InstanceSchedule{
+				Windows: []clv1alpha2.InstanceScheduleWindow{
+					{Start: "0 8 * * *", Stop: "0 10 * * *"},
+					{Start: "0 14 * * *", Stop: "0 16 * * *"},
+				},
+			}, at(10, 12, 0), false),
+		)
+	})
+})
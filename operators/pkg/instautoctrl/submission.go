@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package instautoctrl contains the controller for Instance Termination and Submission automations.
+// Package instautoctrl contains the controllers for Instance Termination, Submission, Scheduling, Expiration and Idle automations.
 package instautoctrl
 
 import (
@@ -93,39 +93,46 @@ func (r *InstanceSubmissionReconciler) Reconcile(ctx context.Context, req ctrl.R
 	}
 	tracer.Step("labels checked")
 
-	environment, err := RetrieveEnvironment(ctx, r.Client, &instance)
+	environments, err := RetrieveEnvironments(ctx, r.Client, &instance)
 	if err != nil {
-		log.Error(err, "failed retrieving environment")
+		log.Error(err, "failed retrieving environments")
 		return ctrl.Result{}, err
 	}
-	tracer.Step("retrieved the instance environment")
+	tracer.Step("retrieved the instance environments")
 
-	if err := CheckEnvironmentValidity(&instance, environment); err != nil {
+	if submittable, err := SubmittableEnvironments(&instance, environments); err != nil {
 		instance.SetLabels(forge.InstanceAutomationLabelsOnSubmission(instance.GetLabels(), false))
 		dbgLog.Info("instance submission aborted")
 	} else {
-		jobStatus, err := r.EnforceInstanceSubmissionJob(ctx, &instance, environment)
-		switch {
-		case err == nil && jobStatus.Succeeded == 0: // the job hasn't been completed yet
-			tracer.Step("job enforced")
-			dbgLog.Info("waiting for job completion")
-			return ctrl.Result{}, nil
-		case err == nil && jobStatus.Succeeded > 0: // the job has been completed successfully
-			if jobStatus.CompletionTime != nil {
-				instance.Status.Automation.SubmissionTime = *jobStatus.CompletionTime
-			} else {
-				instance.Status.Automation.SubmissionTime = metav1.Now()
-			}
-			if err := r.Status().Update(ctx, &instance); err != nil {
-				log.Error(err, "failed updating instance status")
+		// Enforce one submission job for each eligible environment, and wait for all of them to be completed.
+		var completionTime metav1.Time
+		for _, environment := range submittable {
+			jobStatus, err := r.EnforceInstanceSubmissionJob(ctx, &instance, environment)
+			if err != nil {
 				return ctrl.Result{}, err
 			}
-			tracer.Step("instance status updated")
-			log.Info("instance submission completed")
-			instance.SetLabels(forge.InstanceAutomationLabelsOnSubmission(instance.GetLabels(), true))
-		default: // any other error occurred
+			if jobStatus.Succeeded == 0 { // the job hasn't been completed yet
+				tracer.Step("job enforced")
+				dbgLog.Info("waiting for job completion", "environment", environment.Name)
+				return ctrl.Result{}, nil
+			}
+			if jobStatus.CompletionTime != nil && completionTime.Before(jobStatus.CompletionTime) {
+				completionTime = *jobStatus.CompletionTime
+			}
+		}
+
+		// All the jobs have been completed successfully.
+		if completionTime.IsZero() {
+			completionTime = metav1.Now()
+		}
+		instance.Status.Automation.SubmissionTime = completionTime
+		if err := r.Status().Update(ctx, &instance); err != nil {
+			log.Error(err, "failed updating instance status")
 			return ctrl.Result{}, err
 		}
+		tracer.Step("instance status updated")
+		log.Info("instance submission completed")
+		instance.SetLabels(forge.InstanceAutomationLabelsOnSubmission(instance.GetLabels(), true))
 	}
 
 	if err := r.Update(ctx, &instance); err != nil {
@@ -138,11 +145,11 @@ func (r *InstanceSubmissionReconciler) Reconcile(ctx context.Context, req ctrl.R
 	return ctrl.Result{}, nil
 }
 
-// EnforceInstanceSubmissionJob ensures that the submission job for the given instance is present.
+// EnforceInstanceSubmissionJob ensures that the submission job for the given instance environment is present.
 func (r *InstanceSubmissionReconciler) EnforceInstanceSubmissionJob(ctx context.Context, instance *clv1alpha2.Instance, environment *clv1alpha2.Environment) (jobStatus *batch.JobStatus, err error) {
 	// Get the submission job.
 	submitterName := "submitter"
-	job := batch.Job{ObjectMeta: forge.ObjectMetaWithSuffix(instance, submitterName)}
+	job := batch.Job{ObjectMeta: forge.EnvironmentObjectMetaWithSuffix(instance, environment, submitterName)}
 
 	jobSpec := forge.SubmissionJobSpec(instance, environment, &r.ContainerEnvOpts)
 
@@ -150,7 +157,7 @@ func (r *InstanceSubmissionReconciler) EnforceInstanceSubmissionJob(ctx context.
 		if job.CreationTimestamp.IsZero() {
 			job.Spec = jobSpec
 		}
-		job.SetLabels(forge.InstanceComponentLabels(instance, submitterName))
+		job.SetLabels(forge.EnvironmentObjectLabels(forge.InstanceComponentLabels(instance, submitterName), instance, environment))
 		return ctrl.SetControllerReference(instance, &job, r.Scheme)
 	})
 
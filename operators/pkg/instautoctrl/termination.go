@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package instautoctrl contains the controller for Instance Termination and Submission automations.
+// Package instautoctrl contains the controllers for Instance Termination, Submission, Scheduling, Expiration and Idle automations.
 package instautoctrl
 
 import (
@@ -185,13 +185,13 @@ func (r *InstanceTerminationReconciler) TerminateInstance(ctx context.Context, i
 
 	submissionRequired := false
 
-	environment, err := RetrieveEnvironment(ctx, r.Client, instance)
+	environments, err := RetrieveEnvironments(ctx, r.Client, instance)
 	if err != nil {
-		log.Info("failed retrieving environment", "error", err)
+		log.Info("failed retrieving environments", "error", err)
 		return err
 	}
 
-	if err := CheckEnvironmentValidity(instance, environment); err != nil {
+	if _, err := SubmittableEnvironments(instance, environments); err != nil {
 		log.Info("instance not eligible for submission", "error", err)
 	} else {
 		submissionRequired = true
@@ -38,23 +38,38 @@ const (
 
 	// UserDataKey -> the key of the created secret containing the cloud-init userdata content.
 	UserDataKey = "userdata"
+	// SSHHostKeyKey -> the key of the created secret containing the private SSH host key of the environment.
+	SSHHostKeyKey = "ssh_host_ed25519_key"
 )
 
 // EnforceCloudInitSecret enforces the creation/update of a secret containing the cloud-init configuration,
 // based on the information retrieved for the tenant object and its associated WebDav credentials.
 func (r *InstanceReconciler) EnforceCloudInitSecret(ctx context.Context) error {
 	var nfsServerName, nfsPath string
+	var publicKeys []string
+	var trustedCA *forge.TrustedUserCA
+	var err error
 
 	log := ctrl.LoggerFrom(ctx)
 	env := clctx.EnvironmentFrom(ctx)
+	instance := clctx.InstanceFrom(ctx)
 
-	// Retrieve the public keys.
-	publicKeys, err := r.GetPublicKeys(ctx)
-	if err != nil {
-		log.Error(err, "unable to get public keys")
-		return err
+	if r.SSHUserCAKey != "" {
+		// Access is granted through the certificates issued for the instance, hence the public keys are not needed.
+		trustedCA = &forge.TrustedUserCA{PublicKey: r.SSHUserCAKey, Principals: []string{forge.SSHInstancePrincipal(instance)}}
+		if trustedCA.HostKey, err = r.GetSSHHostKey(ctx); err != nil {
+			log.Error(err, "unable to get SSH host key")
+			return err
+		}
+	} else {
+		// Retrieve the public keys.
+		publicKeys, err = r.GetPublicKeys(ctx)
+		if err != nil {
+			log.Error(err, "unable to get public keys")
+			return err
+		}
+		log.V(utils.LogDebugLevel).Info("public keys correctly retrieved")
 	}
-	log.V(utils.LogDebugLevel).Info("public keys correctly retrieved")
 
 	if env.MountMyDriveVolume {
 		nfsServerName, nfsPath, err = r.GetNFSSpecs(ctx)
@@ -71,17 +86,13 @@ func (r *InstanceReconciler) EnforceCloudInitSecret(ctx context.Context) error {
 		mountInfos = append(mountInfos, forge.MyDriveNFSVolumeMountInfo(nfsServerName, nfsPath))
 	}
 
-	for i, mount := range env.SharedVolumeMounts {
-		var shvol clv1alpha2.SharedVolume
-		if err := r.Get(ctx, forge.NamespacedNameFromMount(mount), &shvol); err != nil {
-			log.Error(err, "unable to retrieve shvol to mount")
-			return err
-		}
-
-		mountInfos = append(mountInfos, forge.ShVolNFSVolumeMountInfo(i, &shvol, mount))
+	shvolMountInfos, err := r.GetSharedVolumesMountInfos(ctx)
+	if err != nil {
+		return err
 	}
+	mountInfos = append(mountInfos, shvolMountInfos...)
 
-	userdata, err := forge.CloudInitUserData(publicKeys, mountInfos)
+	userdata, err := forge.CloudInitUserData(publicKeys, mountInfos, trustedCA)
 	if err != nil {
 		log.Error(err, "unable to marshal secret content")
 		return err
@@ -94,11 +105,13 @@ func (r *InstanceReconciler) EnforceCloudInitSecret(ctx context.Context) error {
 	}
 
 	// Enforce the cloud-init secret presence.
-	instance := clctx.InstanceFrom(ctx)
-	secret := corev1.Secret{ObjectMeta: forge.ObjectMeta(instance)}
+	secret := corev1.Secret{ObjectMeta: forge.EnvironmentObjectMeta(instance, env)}
 	res, err := ctrl.CreateOrUpdate(ctx, r.Client, &secret, func() error {
-		secret.SetLabels(forge.InstanceObjectLabels(secret.GetLabels(), instance))
+		secret.SetLabels(forge.EnvironmentObjectLabels(secret.GetLabels(), instance, env))
 		secret.Data = map[string][]byte{UserDataKey: userdata, "x-shellscript": userScriptData}
+		if trustedCA != nil {
+			secret.Data[SSHHostKeyKey] = []byte(trustedCA.HostKey.PrivateKey)
+		}
 		secret.Type = corev1.SecretTypeOpaque
 		return ctrl.SetControllerReference(instance, &secret, r.Scheme)
 	})
@@ -109,9 +122,58 @@ func (r *InstanceReconciler) EnforceCloudInitSecret(ctx context.Context) error {
 	}
 
 	log.V(utils.FromResult(res)).Info("cloud-init secret enforced", "secret", klog.KObj(&secret), "result", res)
+
+	if trustedCA != nil {
+		// The public host key is recorded in the status, for the bastion proxy to verify it.
+		updateEnvironmentStatus(ctx, func(status *clv1alpha2.InstanceEnvironmentStatus) {
+			status.SSHHostKey = trustedCA.HostKey.PublicKey
+		})
+	}
 	return nil
 }
 
+// GetSSHHostKey retrieves the SSH host key of the current environment from the cloud-init secret, generating
+// a new one in case it does not exist yet. The key shall not change afterwards, as cloud-init installs it only
+// during the first boot of the VM.
+func (r *InstanceReconciler) GetSSHHostKey(ctx context.Context) (*forge.SSHHostKey, error) {
+	instance := clctx.InstanceFrom(ctx)
+	env := clctx.EnvironmentFrom(ctx)
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, forge.EnvironmentNamespacedName(instance, env), &secret); client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+
+	if privateKey, found := secret.Data[SSHHostKeyKey]; found {
+		return forge.ParseSSHHostKey(privateKey)
+	}
+	return forge.GenerateSSHHostKey()
+}
+
+// GetSharedVolumesMountInfos retrieves the shared volumes mounted by the current environment, and forges the corresponding
+// NFSVolumeMountInfos, depending on the role the tenant owning the instance has in the workspace of the template.
+func (r *InstanceReconciler) GetSharedVolumesMountInfos(ctx context.Context) ([]forge.NFSVolumeMountInfo, error) {
+	log := ctrl.LoggerFrom(ctx)
+	env := clctx.EnvironmentFrom(ctx)
+	tenant := clctx.TenantFrom(ctx)
+	template := clctx.TemplateFrom(ctx)
+
+	// The role is empty (hence treated as a user one) in case the tenant is not subscribed to the workspace (e.g., service tenants).
+	role, _ := forge.TenantWorkspaceRole(tenant, template.Spec.WorkspaceRef.Name)
+
+	mountInfos := make([]forge.NFSVolumeMountInfo, 0, len(env.SharedVolumeMounts))
+	for i, mount := range env.SharedVolumeMounts {
+		var shvol clv1alpha2.SharedVolume
+		if err := r.Get(ctx, forge.NamespacedNameFromMount(mount), &shvol); err != nil {
+			log.Error(err, "unable to retrieve shvol to mount")
+			return nil, err
+		}
+
+		mountInfos = append(mountInfos, forge.ShVolNFSVolumeMountInfo(i, &shvol, mount, tenant.Name, role))
+	}
+	return mountInfos, nil
+}
+
 // GetNFSSpecs extracts the NFS server name and path for the user's personal NFS volume,
 // required to mount the MyDrive disk of a given tenant from the associated secret.
 func (r *InstanceReconciler) GetNFSSpecs(ctx context.Context) (nfsServerName, nfsPath string, err error) {
@@ -143,8 +205,8 @@ func (r *InstanceReconciler) GetNFSSpecs(ctx context.Context) (nfsServerName, nf
 }
 
 // GetPublicKeys extracts and returns the set of public keys associated with a
-// given tenant, along with the ones of the tenants having Manager role in the
-// corresponding workspace.
+// given tenant, along with the ones of the tenants having Manager or Assistant
+// role in the corresponding workspace.
 func (r *InstanceReconciler) GetPublicKeys(ctx context.Context) ([]string, error) {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -156,19 +218,22 @@ func (r *InstanceReconciler) GetPublicKeys(ctx context.Context) ([]string, error
 	// Retrieve the template associated with the instance to retrieve the name of the workspace.
 	template := clctx.TemplateFrom(ctx)
 	workspaceName := template.Spec.WorkspaceRef.Name
-	labelSelector := map[string]string{clv1alpha2.WorkspaceLabelPrefix + workspaceName: string(clv1alpha2.Manager)}
 
-	var managers clv1alpha2.TenantList
-	if err := r.List(ctx, &managers, client.MatchingLabels(labelSelector)); err != nil {
-		log.Error(err, "failed to retrieve managers for workspace", "workspace", workspaceName, "selector", labelSelector)
-		return nil, err
-	}
+	for _, role := range []clv1alpha2.WorkspaceUserRole{clv1alpha2.Manager, clv1alpha2.Assistant} {
+		labelSelector := map[string]string{clv1alpha2.WorkspaceLabelPrefix + workspaceName: string(role)}
+
+		var managers clv1alpha2.TenantList
+		if err := r.List(ctx, &managers, client.MatchingLabels(labelSelector)); err != nil {
+			log.Error(err, "failed to retrieve managers for workspace", "workspace", workspaceName, "selector", labelSelector)
+			return nil, err
+		}
 
-	log.V(utils.LogDebugLevel).Info("found managers for workspace", "number", len(managers.Items), "workspace", workspaceName)
-	for i := range managers.Items {
-		// Do not append if the instance owner is also a manager, to avoid duplicates.
-		if managers.Items[i].Name != tenant.Name {
-			publicKeys = append(publicKeys, managers.Items[i].Spec.PublicKeys...)
+		log.V(utils.LogDebugLevel).Info("found managers for workspace", "number", len(managers.Items), "workspace", workspaceName, "role", role)
+		for i := range managers.Items {
+			// Do not append if the instance owner is also a manager, to avoid duplicates.
+			if managers.Items[i].Name != tenant.Name {
+				publicKeys = append(publicKeys, managers.Items[i].Spec.PublicKeys...)
+			}
 		}
 	}
 
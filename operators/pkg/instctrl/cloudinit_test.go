@@ -53,6 +53,8 @@ var _ = Describe("Generation of the cloud-init configuration", func() {
 
 		ownerRef metav1.OwnerReference
 
+		sshUserCAKey string
+
 		err error
 	)
 
@@ -82,6 +84,7 @@ var _ = Describe("Generation of the cloud-init configuration", func() {
 	BeforeEach(func() {
 		ctx = ctrl.LoggerInto(context.Background(), logr.Discard())
 		clientBuilder = *fake.NewClientBuilder().WithScheme(scheme.Scheme)
+		sshUserCAKey = ""
 
 		instance = clv1alpha2.Instance{
 			ObjectMeta: metav1.ObjectMeta{Name: instanceName, Namespace: instanceNamespace},
@@ -127,7 +130,7 @@ var _ = Describe("Generation of the cloud-init configuration", func() {
 	JustBeforeEach(func() {
 		client := FakeClientWrapped{Client: clientBuilder.Build()}
 		reconciler = instctrl.InstanceReconciler{
-			Client: client, Scheme: scheme.Scheme,
+			Client: client, Scheme: scheme.Scheme, SSHUserCAKey: sshUserCAKey,
 		}
 
 		ctx, _ = clctx.InstanceInto(ctx, &instance)
@@ -148,7 +151,7 @@ var _ = Describe("Generation of the cloud-init configuration", func() {
 
 			expected, err = forge.CloudInitUserData(tenant.Spec.PublicKeys, []forge.NFSVolumeMountInfo{
 				forge.MyDriveNFSVolumeMountInfo(NFSServiceName, NFSServicePath),
-			})
+			}, nil)
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -161,7 +164,7 @@ var _ = Describe("Generation of the cloud-init configuration", func() {
 
 			It("Should be present and have the common attributes", func() {
 				Expect(reconciler.Get(ctx, objectName, &secret)).To(Succeed())
-				Expect(secret.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+				Expect(secret.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 				Expect(secret.GetOwnerReferences()).To(ContainElement(ownerRef))
 			})
 
@@ -182,7 +185,7 @@ var _ = Describe("Generation of the cloud-init configuration", func() {
 
 			It("Should still be present and have the common attributes", func() {
 				Expect(reconciler.Get(ctx, objectName, &secret)).To(Succeed())
-				Expect(secret.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+				Expect(secret.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 				Expect(secret.GetOwnerReferences()).To(ContainElement(ownerRef))
 			})
 
@@ -193,6 +196,49 @@ var _ = Describe("Generation of the cloud-init configuration", func() {
 			})
 		})
 
+		When("the SSH user CA is configured", func() {
+			var hostKey *forge.SSHHostKey
+
+			BeforeEach(func() { sshUserCAKey = "ssh-ed25519 ca-key" })
+
+			JustBeforeEach(func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reconciler.Get(ctx, objectName, &secret)).To(Succeed())
+				hostKey, err = forge.ParseSSHHostKey(secret.Data[instctrl.SSHHostKeyKey])
+				Expect(err).ToNot(HaveOccurred())
+
+				expected, err = forge.CloudInitUserData(nil, []forge.NFSVolumeMountInfo{
+					forge.MyDriveNFSVolumeMountInfo(NFSServiceName, NFSServicePath),
+				}, &forge.TrustedUserCA{PublicKey: sshUserCAKey, Principals: []string{forge.SSHInstancePrincipal(&instance)}, HostKey: hostKey})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("Should trust the CA rather than installing the public keys", func() {
+				Expect(secret.Data).To(WithTransform(Extractor, Equal(string(expected))))
+				Expect(secret.Data).To(WithTransform(Extractor, Not(ContainSubstring("tenant-key-1"))))
+			})
+
+			It("Should record the public host key in the status of the environment", func() {
+				Expect(instance.Status.Environments).To(ConsistOf(
+					clv1alpha2.InstanceEnvironmentStatus{Name: environmentName, SSHHostKey: hostKey.PublicKey}))
+			})
+
+			When("the host key has already been generated", func() {
+				var existing *forge.SSHHostKey
+
+				BeforeEach(func() {
+					existing, err = forge.GenerateSSHHostKey()
+					Expect(err).ToNot(HaveOccurred())
+					clientBuilder.WithObjects(&corev1.Secret{
+						ObjectMeta: forge.NamespacedNameToObjectMeta(objectName),
+						Data:       map[string][]byte{instctrl.SSHHostKeyKey: []byte(existing.PrivateKey)},
+					})
+				})
+
+				It("Should preserve the host key", func() { Expect(hostKey).To(Equal(existing)) })
+			})
+		})
+
 	})
 
 	Describe("The NFSSpecs function", func() {
@@ -263,6 +309,19 @@ var _ = Describe("Generation of the cloud-init configuration", func() {
 			})
 		})
 
+		When("there is an assistant associated with the instance workspace", func() {
+			BeforeEach(func() {
+				other = NewTenant("ta", workspaceName, clv1alpha2.Assistant, []string{"assistant-key-1"})
+				clientBuilder.WithObjects(&other)
+			})
+
+			It("Should succeed", func() { Expect(err).ToNot(HaveOccurred()) })
+			It("Should return both the tenant and the assistant public keys", func() {
+				Expect(keys).To(ContainElements(tenant.Spec.PublicKeys))
+				Expect(keys).To(ContainElements(other.Spec.PublicKeys))
+			})
+		})
+
 		When("there is a manager associated with another workspace", func() {
 			BeforeEach(func() {
 				other = NewTenant("mgr", "another", clv1alpha2.Manager, []string{"manager-key-1", "manager-key-2"})
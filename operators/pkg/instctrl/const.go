@@ -29,4 +29,9 @@ const (
 	EvEnvironmentErr = "EnvironmentEnforcementFailed"
 	// EvEnvironmentErrMsg -> the event message corresponding to a failed environment enforcement.
 	EvEnvironmentErrMsg = "Failed to enforce environment %v"
+
+	// EvPrivateNetworkErr -> the event key corresponding to a failed private network enforcement.
+	EvPrivateNetworkErr = "PrivateNetworkEnforcementFailed"
+	// EvPrivateNetworkErrMsg -> the event message corresponding to a failed private network enforcement.
+	EvPrivateNetworkErrMsg = "Failed to enforce the private network"
 )
@@ -57,14 +57,14 @@ func (r *InstanceReconciler) enforcePVC(ctx context.Context) error {
 	instance := clctx.InstanceFrom(ctx)
 	environment := clctx.EnvironmentFrom(ctx)
 
-	pvc := v1.PersistentVolumeClaim{ObjectMeta: forge.ObjectMeta(instance)}
+	pvc := v1.PersistentVolumeClaim{ObjectMeta: forge.EnvironmentObjectMeta(instance, environment)}
 
 	res, err := ctrl.CreateOrUpdate(ctx, r.Client, &pvc, func() error {
 		// PVC's spec is immutable, it has to be set at creation
 		if pvc.CreationTimestamp.IsZero() {
 			pvc.Spec = forge.InstancePVCSpec(environment)
 		}
-		pvc.SetLabels(forge.InstanceObjectLabels(pvc.GetLabels(), instance))
+		pvc.SetLabels(forge.EnvironmentObjectLabels(pvc.GetLabels(), instance, environment))
 		return ctrl.SetControllerReference(instance, &pvc, r.Scheme)
 	})
 	if err != nil {
@@ -83,7 +83,7 @@ func (r *InstanceReconciler) enforceContainer(ctx context.Context) error {
 	instance := clctx.InstanceFrom(ctx)
 	environment := clctx.EnvironmentFrom(ctx)
 
-	depl := appsv1.Deployment{ObjectMeta: forge.ObjectMeta(instance)}
+	depl := appsv1.Deployment{ObjectMeta: forge.EnvironmentObjectMeta(instance, environment)}
 
 	mountInfos := []forge.NFSVolumeMountInfo{}
 
@@ -98,15 +98,11 @@ func (r *InstanceReconciler) enforceContainer(ctx context.Context) error {
 		mountInfos = append(mountInfos, forge.MyDriveNFSVolumeMountInfo(nfsServerName, nfsPath))
 	}
 
-	for i, mount := range environment.SharedVolumeMounts {
-		var shvol clv1alpha2.SharedVolume
-		if err := r.Get(ctx, forge.NamespacedNameFromMount(mount), &shvol); err != nil {
-			log.Error(err, "unable to retrieve shvol to mount")
-			return err
-		}
-
-		mountInfos = append(mountInfos, forge.ShVolNFSVolumeMountInfo(i, &shvol, mount))
+	shvolMountInfos, err := r.GetSharedVolumesMountInfos(ctx)
+	if err != nil {
+		return err
 	}
+	mountInfos = append(mountInfos, shvolMountInfos...)
 
 	res, err := ctrl.CreateOrUpdate(ctx, r.Client, &depl, func() error {
 		// Deployment specifications are forged only at creation time, as changing them later may be
@@ -117,7 +113,7 @@ func (r *InstanceReconciler) enforceContainer(ctx context.Context) error {
 
 		depl.Spec.Replicas = forge.ReplicasCount(instance, environment, depl.CreationTimestamp.IsZero())
 
-		depl.SetLabels(forge.InstanceObjectLabels(depl.GetLabels(), instance))
+		depl.SetLabels(forge.EnvironmentObjectLabels(depl.GetLabels(), instance, environment))
 		return ctrl.SetControllerReference(instance, &depl, r.Scheme)
 	})
 
@@ -136,11 +132,6 @@ func (r *InstanceReconciler) enforceContainer(ctx context.Context) error {
 		phase = clv1alpha2.EnvironmentPhaseOff
 	}
 
-	if phase != instance.Status.Phase {
-		log.Info("phase changed", "deployment", klog.KObj(&depl),
-			"previous", string(instance.Status.Phase), "current", string(phase))
-		instance.Status.Phase = phase
-	}
-
+	setEnvironmentPhase(ctx, phase, "deployment", klog.KObj(&depl))
 	return nil
 }
@@ -61,6 +61,8 @@ var _ = Describe("Generation of the container based instances", func() {
 		reconciler    instctrl.InstanceReconciler
 
 		instance    clv1alpha2.Instance
+		template    clv1alpha2.Template
+		tenant      clv1alpha2.Tenant
 		environment clv1alpha2.Environment
 
 		objectName types.NamespacedName
@@ -89,6 +91,7 @@ var _ = Describe("Generation of the container based instances", func() {
 		templateNamespace = "workspace-netgroup"
 		environmentName   = "control-plane"
 		tenantName        = "tester"
+		workspaceName     = "netgroup"
 
 		image       = "internal/registry/image:v1.0"
 		cpu         = 2
@@ -150,6 +153,15 @@ var _ = Describe("Generation of the container based instances", func() {
 			},
 		}
 
+		template = clv1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: templateName, Namespace: templateNamespace},
+			Spec:       clv1alpha2.TemplateSpec{WorkspaceRef: clv1alpha2.GenericRef{Name: workspaceName}},
+		}
+		tenant = clv1alpha2.Tenant{
+			ObjectMeta: metav1.ObjectMeta{Name: tenantName},
+			Spec:       clv1alpha2.TenantSpec{Workspaces: []clv1alpha2.TenantWorkspaceEntry{{Name: workspaceName, Role: clv1alpha2.User}}},
+		}
+
 		objectName = forge.NamespacedName(&instance)
 
 		svc = corev1.Service{}
@@ -188,7 +200,7 @@ var _ = Describe("Generation of the container based instances", func() {
 		}
 		mountInfos = []forge.NFSVolumeMountInfo{
 			forge.MyDriveNFSVolumeMountInfo(nfsServerName, nfsMyDriveExpPath),
-			forge.ShVolNFSVolumeMountInfo(0, &shvol, shvolMounts[0]),
+			forge.ShVolNFSVolumeMountInfo(0, &shvol, shvolMounts[0], tenantName, clv1alpha2.User),
 		}
 	})
 
@@ -200,6 +212,8 @@ var _ = Describe("Generation of the container based instances", func() {
 		}
 
 		ctx, _ = clctx.InstanceInto(ctx, &instance)
+		ctx, _ = clctx.TemplateInto(ctx, &template)
+		ctx, _ = clctx.TenantInto(ctx, &tenant)
 		ctx, _ = clctx.EnvironmentInto(ctx, &environment)
 		errShVol = reconciler.Create(ctx, &shvol)
 		err = reconciler.EnforceContainerEnvironment(ctx)
@@ -219,7 +233,7 @@ var _ = Describe("Generation of the container based instances", func() {
 
 				It("The deployment should be present and have the common attributes", func() {
 					Expect(reconciler.Get(ctx, objectName, &deploy)).To(Succeed())
-					Expect(deploy.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+					Expect(deploy.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 					Expect(deploy.GetOwnerReferences()).To(ContainElement(ownerRef))
 				})
 
@@ -283,7 +297,7 @@ var _ = Describe("Generation of the container based instances", func() {
 
 				It("The deployment should still be present and have the common attributes", func() {
 					Expect(reconciler.Get(ctx, objectName, &deploy)).To(Succeed())
-					Expect(deploy.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+					Expect(deploy.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 					Expect(deploy.GetOwnerReferences()).To(ContainElement(ownerRef))
 				})
 
@@ -311,7 +325,7 @@ var _ = Describe("Generation of the container based instances", func() {
 
 				It("The deployment should still be present and have unmodified specs", func() {
 					Expect(reconciler.Get(ctx, objectName, &deploy)).To(Succeed())
-					Expect(deploy.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+					Expect(deploy.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 					// Here we overwrite the replicas value, as it is checked in a different It clause.
 					deploy.Spec.Replicas = nil
 					Expect(deploy.Spec).To(Equal(appsv1.DeploymentSpec{}))
@@ -335,7 +349,7 @@ var _ = Describe("Generation of the container based instances", func() {
 		When("the PVC is not yet present", func() {
 			It("The PVC should be present and have the common attributes", func() {
 				Expect(reconciler.Get(ctx, objectName, &pvc)).To(Succeed())
-				Expect(pvc.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+				Expect(pvc.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 				Expect(pvc.GetOwnerReferences()).To(ContainElement(ownerRef))
 			})
 
@@ -350,7 +364,7 @@ var _ = Describe("Generation of the container based instances", func() {
 
 			It("The deployment should be present and have the common attributes", func() {
 				Expect(reconciler.Get(ctx, objectName, &deploy)).To(Succeed())
-				Expect(deploy.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+				Expect(deploy.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 				Expect(deploy.GetOwnerReferences()).To(ContainElement(ownerRef))
 			})
 
@@ -395,7 +409,7 @@ var _ = Describe("Generation of the container based instances", func() {
 
 			It("The PVC should be present and have the common attributes", func() {
 				Expect(reconciler.Get(ctx, objectName, &pvc)).To(Succeed())
-				Expect(pvc.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+				Expect(pvc.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 				Expect(pvc.GetOwnerReferences()).To(ContainElement(ownerRef))
 			})
 
@@ -420,7 +434,7 @@ var _ = Describe("Generation of the container based instances", func() {
 
 			It("The deployment should still be present and have the common attributes", func() {
 				Expect(reconciler.Get(ctx, objectName, &deploy)).To(Succeed())
-				Expect(deploy.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+				Expect(deploy.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 				Expect(deploy.GetOwnerReferences()).To(ContainElement(ownerRef))
 			})
 
@@ -496,5 +510,22 @@ var _ = Describe("Generation of the container based instances", func() {
 				Expect(deploy.Spec.Template.Spec.Volumes).To(Equal(expected.Template.Spec.Volumes))
 			})
 		})
+
+		When("the shared volume is mounted in per-tenant subdirectory mode", func() {
+			BeforeEach(func() {
+				shvolMounts[0].PerTenantSubdirectory = true
+				shvolMounts[0].AccessPolicy = clv1alpha2.SharedVolumeAccessRoleBased
+			})
+
+			It("Should mount the subdirectory of the tenant, creating it if missing", func() {
+				var container corev1.Container
+				Expect(reconciler.Get(ctx, objectName, &deploy)).To(Succeed())
+				Expect(deploy.Spec.Template.Spec.Containers).To(ContainElement(HaveField("Name", environment.Name), &container))
+				Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name: nfsShVolName, MountPath: nfsShVolMountPath, SubPath: tenantName, ReadOnly: false,
+				}))
+				Expect(deploy.Spec.Template.Spec.InitContainers).To(ContainElement(HaveField("Name", forge.SubdirsCreatorName)))
+			})
+		})
 	})
 })
@@ -17,9 +17,9 @@ package instctrl
 
 import (
 	"context"
-	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -53,6 +53,10 @@ type InstanceReconciler struct {
 	ServiceUrls        ServiceUrls
 	ContainerEnvOpts   forge.ContainerEnvOpts
 
+	// SSHUserCAKey is the public key of the SSH user certificate authority trusted by the VMs.
+	// If configured, it replaces the installation of the public keys of the tenants.
+	SSHUserCAKey string
+
 	// This function, if configured, is deferred at the beginning of the Reconcile.
 	// Specifically, it is meant to be set to GinkgoRecover during the tests,
 	// in order to lead to a controlled failure in case the Reconcile panics.
@@ -110,6 +114,7 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 		if err != nil && !kerrors.IsConflict(err) {
 			instance.Status.Phase = clv1alpha2.EnvironmentPhaseCreationLoopBackoff
 		}
+		setReadyCondition(&instance)
 
 		// Avoid triggering the status update if not necessary.
 		if !reflect.DeepEqual(original.Status, updated.Status) {
@@ -132,6 +137,7 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 	if err := r.Get(ctx, templateName, &template); err != nil {
 		log.Error(err, "failed retrieving the instance template", "template", templateName)
 		r.EventsRecorder.Eventf(&instance, v1.EventTypeWarning, EvTmplNotFound, EvTmplNotFoundMsg, templateName.Namespace, templateName.Name)
+		setProvisionedCondition(&instance, notFoundReason(err, clv1alpha2.ReasonTemplateNotFound), err)
 		return ctrl.Result{}, err
 	}
 	ctx, log = clctx.TemplateInto(ctx, &template)
@@ -144,6 +150,7 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 	if err := r.Get(ctx, tenantName, &tenant); err != nil {
 		log.Error(err, "failed retrieving the instance tenant", "tenant", tenantName)
 		r.EventsRecorder.Eventf(&instance, v1.EventTypeWarning, EvTntNotFound, EvTntNotFoundMsg, tenantName.Name)
+		setProvisionedCondition(&instance, notFoundReason(err, clv1alpha2.ReasonTenantNotFound), err)
 		return ctrl.Result{}, err
 	}
 	ctx, log = clctx.TenantInto(ctx, &tenant)
@@ -151,6 +158,7 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 	log.Info("successfully retrieved the instance tenant")
 
 	// Patch the instance labels to allow for easier categorization.
+	// They are typically already configured by the defaulting webhook, if enabled.
 	labels, updated := forge.InstanceLabels(instance.GetLabels(), &template, &instance)
 	if updated || instance.Spec.PrettyName == "" {
 		original := instance.DeepCopy()
@@ -169,8 +177,10 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 	// Iterate over and enforce the instance environments.
 	if err := r.enforceEnvironments(ctx); err != nil {
 		log.Error(err, "failed to enforce instance environments")
+		setProvisionedCondition(&instance, clv1alpha2.ReasonEnforcementFailed, err)
 		return ctrl.Result{}, err
 	}
+	setProvisionedCondition(&instance, "", nil)
 
 	if err = r.podScheduleStatusIntoInstance(ctx, &instance); err != nil {
 		log.Error(err, "unable to retrieve pod schedule status")
@@ -186,19 +196,21 @@ func (r *InstanceReconciler) enforceEnvironments(ctx context.Context) error {
 	instance := clctx.InstanceFrom(ctx)
 	template := clctx.TemplateFrom(ctx)
 
+	// Align the status of the environments with the ones defined in the template,
+	// to guarantee a consistent ordering and to drop the ones no longer present.
+	instance.Status.Environments = alignEnvironmentsStatus(instance.Status.Environments, template.Spec.EnvironmentList)
+
+	// Enforce the private network shared by the environments, if requested.
+	if err := r.EnforcePrivateNetwork(ctx); err != nil {
+		r.EventsRecorder.Eventf(instance, v1.EventTypeWarning, EvPrivateNetworkErr, EvPrivateNetworkErrMsg)
+		return err
+	}
+
 	for i := range template.Spec.EnvironmentList {
 		environment := &template.Spec.EnvironmentList[i]
-		ctx, log := clctx.EnvironmentInto(ctx, environment)
-
-		// Currently, only instances composed of a single environment are supported.
-		// Nonetheless, we return nil in the end, since it is useless to retry later.
-		if i >= 1 {
-			err := fmt.Errorf("instances composed of multiple environments are currently not supported")
-			log.Error(err, "failed to process environment")
-			return nil
-		}
+		ctx, _ := clctx.EnvironmentInto(ctx, environment)
 
-		switch template.Spec.EnvironmentList[i].EnvironmentType {
+		switch environment.EnvironmentType {
 		case clv1alpha2.ClassVM, clv1alpha2.ClassCloudVM:
 			if err := r.EnforceVMEnvironment(ctx); err != nil {
 				r.EventsRecorder.Eventf(instance, v1.EventTypeWarning, EvEnvironmentErr, EvEnvironmentErrMsg, environment.Name)
@@ -210,9 +222,9 @@ func (r *InstanceReconciler) enforceEnvironments(ctx context.Context) error {
 				return err
 			}
 		}
-
-		r.setInitialReadyTimeIfNecessary(ctx)
 	}
+
+	r.setInitialReadyTimeIfNecessary(ctx)
 	return nil
 }
 
@@ -232,15 +244,25 @@ func (r *InstanceReconciler) setInitialReadyTimeIfNecessary(ctx context.Context)
 		return
 	}
 
+	// The instance is ready once all its environments are, hence the time is observed once per instance,
+	// labeled with the characteristics of the environments composing it (joined, in case of multiple ones).
 	template := clctx.TemplateFrom(ctx)
-	environment := clctx.EnvironmentFrom(ctx)
+	names := make([]string, 0, len(template.Spec.EnvironmentList))
+	types := make([]string, 0, len(template.Spec.EnvironmentList))
+	persistent := false
+	for i := range template.Spec.EnvironmentList {
+		environment := &template.Spec.EnvironmentList[i]
+		names = append(names, environment.Name)
+		types = append(types, string(environment.EnvironmentType))
+		persistent = persistent || environment.Persistent
+	}
 
 	metricInitialReadyTimes.With(prometheus.Labels{
 		metricInitialReadyTimesLabelWorkspace:   template.Spec.WorkspaceRef.Name,
 		metricInitialReadyTimesLabelTemplate:    template.GetName(),
-		metricInitialReadyTimesLabelEnvironment: environment.Name,
-		metricInitialReadyTimesLabelType:        string(environment.EnvironmentType),
-		metricInitialReadyTimesLabelPersistent:  strconv.FormatBool(environment.Persistent),
+		metricInitialReadyTimesLabelEnvironment: strings.Join(names, "+"),
+		metricInitialReadyTimesLabelType:        strings.Join(types, "+"),
+		metricInitialReadyTimesLabelPersistent:  strconv.FormatBool(persistent),
 	}).Observe(duration.Seconds())
 }
 
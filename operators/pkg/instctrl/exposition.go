@@ -47,7 +47,7 @@ func (r *InstanceReconciler) enforceInstanceExpositionPresence(ctx context.Conte
 	environment := clctx.EnvironmentFrom(ctx)
 
 	// Enforce the service presence
-	service := v1.Service{ObjectMeta: forge.ObjectMeta(instance)}
+	service := v1.Service{ObjectMeta: forge.EnvironmentObjectMeta(instance, environment)}
 	res, err := ctrl.CreateOrUpdate(ctx, r.Client, &service, func() error {
 		// Service specifications are forged only at creation time, to prevent issues in case of updates.
 		// Indeed, enforcing the specs may cause service disruption if they diverge from the backend
@@ -56,7 +56,7 @@ func (r *InstanceReconciler) enforceInstanceExpositionPresence(ctx context.Conte
 			service.Spec = forge.ServiceSpec(instance, environment)
 		}
 
-		labels := forge.InstanceObjectLabels(service.GetLabels(), instance)
+		labels := forge.EnvironmentObjectLabels(service.GetLabels(), instance, environment)
 		if environment.EnvironmentType == clv1alpha2.ClassContainer {
 			labels = forge.MonitorableServiceLabels(labels)
 		}
@@ -70,7 +70,9 @@ func (r *InstanceReconciler) enforceInstanceExpositionPresence(ctx context.Conte
 		return err
 	}
 	log.V(utils.FromResult(res)).Info("object enforced", "service", klog.KObj(&service), "result", res)
-	instance.Status.IP = service.Spec.ClusterIP
+	updateEnvironmentStatus(ctx, func(status *clv1alpha2.InstanceEnvironmentStatus) {
+		status.IP = service.Spec.ClusterIP
+	})
 
 	// No need to create ingress resources in case of gui-less VMs.
 	if (environment.EnvironmentType == clv1alpha2.ClassVM || environment.EnvironmentType == clv1alpha2.ClassCloudVM) && !environment.GuiEnabled {
@@ -81,7 +83,7 @@ func (r *InstanceReconciler) enforceInstanceExpositionPresence(ctx context.Conte
 
 	host := forge.HostName(r.ServiceUrls.WebsiteBaseURL, environment.Mode)
 
-	ingressGUI := netv1.Ingress{ObjectMeta: forge.ObjectMetaWithSuffix(instance, forge.IngressGUIName(environment))}
+	ingressGUI := netv1.Ingress{ObjectMeta: forge.EnvironmentObjectMetaWithSuffix(instance, environment, forge.IngressGUIName(environment))}
 
 	res, err = ctrl.CreateOrUpdate(ctx, r.Client, &ingressGUI, func() error {
 		// Ingress specifications are forged only at creation time, to prevent issues in case of updates.
@@ -90,7 +92,7 @@ func (r *InstanceReconciler) enforceInstanceExpositionPresence(ctx context.Conte
 			ingressGUI.Spec = forge.IngressSpec(host, forge.IngressGUIPath(instance, environment),
 				forge.IngressDefaultCertificateName, service.GetName(), forge.GUIPortName)
 		}
-		ingressGUI.SetLabels(forge.InstanceObjectLabels(ingressGUI.GetLabels(), instance))
+		ingressGUI.SetLabels(forge.EnvironmentObjectLabels(ingressGUI.GetLabels(), instance, environment))
 
 		ingressGUI.SetAnnotations(forge.IngressGUIAnnotations(environment, ingressGUI.GetAnnotations()))
 
@@ -107,7 +109,9 @@ func (r *InstanceReconciler) enforceInstanceExpositionPresence(ctx context.Conte
 	}
 
 	log.V(utils.FromResult(res)).Info("object enforced", "ingress", klog.KObj(&ingressGUI), "result", res)
-	instance.Status.URL = forge.IngressGuiStatusURL(host, environment, instance)
+	updateEnvironmentStatus(ctx, func(status *clv1alpha2.InstanceEnvironmentStatus) {
+		status.URL = forge.IngressGuiStatusURL(host, environment, instance)
+	})
 
 	return nil
 }
@@ -115,17 +119,20 @@ func (r *InstanceReconciler) enforceInstanceExpositionPresence(ctx context.Conte
 // enforceInstanceExpositionAbsence ensures the absence of the objects required to expose an environment (i.e. service, ingress).
 func (r *InstanceReconciler) enforceInstanceExpositionAbsence(ctx context.Context) error {
 	instance := clctx.InstanceFrom(ctx)
-	instance.Status.IP = ""
-	instance.Status.URL = ""
+	environment := clctx.EnvironmentFrom(ctx)
+	updateEnvironmentStatus(ctx, func(status *clv1alpha2.InstanceEnvironmentStatus) {
+		status.IP = ""
+		status.URL = ""
+	})
 
 	// Enforce service absence
-	service := v1.Service{ObjectMeta: forge.ObjectMeta(instance)}
+	service := v1.Service{ObjectMeta: forge.EnvironmentObjectMeta(instance, environment)}
 	if err := utils.EnforceObjectAbsence(ctx, r.Client, &service, "service"); err != nil {
 		return err
 	}
 
 	// Enforce gui ingress absence
-	ingressGUI := netv1.Ingress{ObjectMeta: forge.ObjectMetaWithSuffix(instance, forge.IngressGUINameSuffix)}
+	ingressGUI := netv1.Ingress{ObjectMeta: forge.EnvironmentObjectMetaWithSuffix(instance, environment, forge.IngressGUIName(environment))}
 	if err := utils.EnforceObjectAbsence(ctx, r.Client, &ingressGUI, "ingress"); err != nil {
 		return err
 	}
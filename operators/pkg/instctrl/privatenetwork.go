@@ -0,0 +1,101 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instctrl
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clctx "github.com/netgroup-polito/CrownLabs/operators/pkg/context"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+// EnforcePrivateNetwork ensures the presence of the objects required to attach the environments of
+// an instance to its private network (i.e. the NetworkAttachmentDefinition and the MultiNetworkPolicy),
+// in case it is requested by the template. The objects are not removed in case the private network is
+// later disabled, since they may still be referenced by the existing VMs: they are garbage collected
+// along with the instance.
+func (r *InstanceReconciler) EnforcePrivateNetwork(ctx context.Context) error {
+	template := clctx.TemplateFrom(ctx)
+	if template.Spec.PrivateNetwork == nil {
+		return nil
+	}
+
+	if err := r.enforceNetworkAttachmentDefinition(ctx); err != nil {
+		return err
+	}
+
+	return r.enforceMultiNetworkPolicy(ctx)
+}
+
+// enforceNetworkAttachmentDefinition ensures the presence of the NetworkAttachmentDefinition representing the private network.
+func (r *InstanceReconciler) enforceNetworkAttachmentDefinition(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+	instance := clctx.InstanceFrom(ctx)
+	template := clctx.TemplateFrom(ctx)
+
+	nad := unstructured.Unstructured{}
+	nad.SetGroupVersionKind(forge.NetworkAttachmentDefinitionGVK)
+	nad.SetName(forge.PrivateNetworkNamespacedName(instance).Name)
+	nad.SetNamespace(instance.GetNamespace())
+
+	res, err := ctrl.CreateOrUpdate(ctx, r.Client, &nad, func() error {
+		nad.Object["spec"] = forge.PrivateNetworkSpec(instance, template.Spec.PrivateNetwork)
+		nad.SetLabels(forge.InstanceObjectLabels(nad.GetLabels(), instance))
+		return ctrl.SetControllerReference(instance, &nad, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "failed to enforce object", "networkattachmentdefinition", klog.KObj(&nad))
+		return err
+	}
+	log.V(utils.FromResult(res)).Info("object enforced", "networkattachmentdefinition", klog.KObj(&nad), "result", res)
+	return nil
+}
+
+// enforceMultiNetworkPolicy ensures the presence of the MultiNetworkPolicy guarding the private network.
+func (r *InstanceReconciler) enforceMultiNetworkPolicy(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+	instance := clctx.InstanceFrom(ctx)
+
+	policySpec := forge.PrivateNetworkPolicySpec(instance)
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&policySpec)
+	if err != nil {
+		return fmt.Errorf("failed converting the multinetworkpolicy spec: %w", err)
+	}
+
+	policy := unstructured.Unstructured{}
+	policy.SetGroupVersionKind(forge.MultiNetworkPolicyGVK)
+	policy.SetName(forge.PrivateNetworkNamespacedName(instance).Name)
+	policy.SetNamespace(instance.GetNamespace())
+
+	res, err := ctrl.CreateOrUpdate(ctx, r.Client, &policy, func() error {
+		policy.Object["spec"] = spec
+		policy.SetLabels(forge.InstanceObjectLabels(policy.GetLabels(), instance))
+		policy.SetAnnotations(forge.PrivateNetworkPolicyAnnotations(policy.GetAnnotations(), instance))
+		return ctrl.SetControllerReference(instance, &policy, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "failed to enforce object", "multinetworkpolicy", klog.KObj(&policy))
+		return err
+	}
+	log.V(utils.FromResult(res)).Info("object enforced", "multinetworkpolicy", klog.KObj(&policy), "result", res)
+	return nil
+}
@@ -16,15 +16,23 @@ package instctrl
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	virtv1 "kubevirt.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	clctx "github.com/netgroup-polito/CrownLabs/operators/pkg/context"
 	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
 )
 
 // RetrievePhaseFromVM converts the VM phase to the corresponding one of the instance.
@@ -168,3 +176,142 @@ func (r *InstanceReconciler) podScheduleStatusIntoInstance(ctx context.Context,
 	delete(instance.Status.NodeSelector, "kubevirt.io/schedulable")
 	return
 }
+
+// environmentPhasePriorities defines the priority of each phase when aggregating the status of multiple environments.
+// Lower values take precedence, in order to report the least advanced (or most problematic) phase.
+var environmentPhasePriorities = map[clv1alpha2.EnvironmentPhase]int{
+	clv1alpha2.EnvironmentPhaseFailed:                0,
+	clv1alpha2.EnvironmentPhaseCreationLoopBackoff:   1,
+	clv1alpha2.EnvironmentPhaseResourceQuotaExceeded: 2,
+	clv1alpha2.EnvironmentPhaseUnset:                 3,
+	clv1alpha2.EnvironmentPhaseImporting:             4,
+	clv1alpha2.EnvironmentPhaseStarting:              5,
+	clv1alpha2.EnvironmentPhaseStopping:              6,
+	clv1alpha2.EnvironmentPhaseRunning:               7,
+	clv1alpha2.EnvironmentPhaseOff:                   8,
+	clv1alpha2.EnvironmentPhaseReady:                 9,
+}
+
+// AggregatePhase computes the phase of an instance starting from the ones of its environments.
+// The instance is Ready (or Off) only if all its environments are Ready (or Off), while a mix of
+// Ready and Off environments is reported as Running. Otherwise, the least advanced phase is returned.
+func AggregatePhase(environments []clv1alpha2.InstanceEnvironmentStatus) clv1alpha2.EnvironmentPhase {
+	if len(environments) == 0 {
+		return clv1alpha2.EnvironmentPhaseUnset
+	}
+
+	phase := environments[0].Phase
+	for i := range environments[1:] {
+		current := environments[i+1].Phase
+		if (phase == clv1alpha2.EnvironmentPhaseReady && current == clv1alpha2.EnvironmentPhaseOff) ||
+			(phase == clv1alpha2.EnvironmentPhaseOff && current == clv1alpha2.EnvironmentPhaseReady) {
+			current = clv1alpha2.EnvironmentPhaseRunning
+		}
+		if environmentPhasePriorities[current] < environmentPhasePriorities[phase] {
+			phase = current
+		}
+	}
+	return phase
+}
+
+// alignEnvironmentsStatus returns the status of the environments ordered as in the given list,
+// preserving the previously observed values and dropping the ones no longer part of the template.
+func alignEnvironmentsStatus(statuses []clv1alpha2.InstanceEnvironmentStatus, environments []clv1alpha2.Environment) []clv1alpha2.InstanceEnvironmentStatus {
+	aligned := make([]clv1alpha2.InstanceEnvironmentStatus, len(environments))
+	for i := range environments {
+		aligned[i].Name = environments[i].Name
+		for j := range statuses {
+			if statuses[j].Name == environments[i].Name {
+				aligned[i] = statuses[j]
+				break
+			}
+		}
+	}
+	return aligned
+}
+
+// updateEnvironmentStatus applies the given mutation to the status of the environment embedded in the context,
+// and propagates the changes to the aggregated status of the instance. Specifically, the phase of the instance
+// is computed from the ones of all its environments, while the URL and the IP refer to the first environment.
+func updateEnvironmentStatus(ctx context.Context, mutate func(status *clv1alpha2.InstanceEnvironmentStatus)) {
+	instance := clctx.InstanceFrom(ctx)
+	environment := clctx.EnvironmentFrom(ctx)
+
+	idx := slices.IndexFunc(instance.Status.Environments, func(status clv1alpha2.InstanceEnvironmentStatus) bool {
+		return status.Name == environment.Name
+	})
+	if idx < 0 {
+		instance.Status.Environments = append(instance.Status.Environments, clv1alpha2.InstanceEnvironmentStatus{Name: environment.Name})
+		idx = len(instance.Status.Environments) - 1
+	}
+	mutate(&instance.Status.Environments[idx])
+
+	instance.Status.Phase = AggregatePhase(instance.Status.Environments)
+	instance.Status.URL = instance.Status.Environments[0].URL
+	instance.Status.IP = instance.Status.Environments[0].IP
+}
+
+// setEnvironmentPhase configures the phase of the environment embedded in the context, logging the change if any.
+func setEnvironmentPhase(ctx context.Context, phase clv1alpha2.EnvironmentPhase, keysAndValues ...interface{}) {
+	updateEnvironmentStatus(ctx, func(status *clv1alpha2.InstanceEnvironmentStatus) {
+		if phase != status.Phase {
+			ctrl.LoggerFrom(ctx).Info("phase changed", append(keysAndValues,
+				"previous", string(status.Phase), "current", string(phase))...)
+			status.Phase = phase
+		}
+	})
+}
+
+// instanceReadyReasons maps the phase of an instance to the reason reported in the Ready condition.
+var instanceReadyReasons = map[clv1alpha2.EnvironmentPhase]clv1alpha2.ConditionReason{
+	clv1alpha2.EnvironmentPhaseReady:                 clv1alpha2.ReasonAvailable,
+	clv1alpha2.EnvironmentPhaseResourceQuotaExceeded: clv1alpha2.ReasonQuotaExceeded,
+	clv1alpha2.EnvironmentPhaseStopping:              clv1alpha2.ReasonStopping,
+	clv1alpha2.EnvironmentPhaseOff:                   clv1alpha2.ReasonStopped,
+	clv1alpha2.EnvironmentPhaseFailed:                clv1alpha2.ReasonEnvironmentFailed,
+}
+
+// setReadyCondition configures the Ready condition of the instance, depending on its phase. In case the instance
+// is in CreationLoopBackoff, the reason and the message are inherited from the Provisioned condition.
+func setReadyCondition(instance *clv1alpha2.Instance) {
+	phase := instance.Status.Phase
+	message := fmt.Sprintf("The instance is in phase %s", phase)
+	if phase == clv1alpha2.EnvironmentPhaseUnset {
+		message = "The instance environments are being created"
+	}
+
+	reason, found := instanceReadyReasons[phase]
+	if !found {
+		reason = clv1alpha2.ReasonStarting
+	}
+
+	if phase == clv1alpha2.EnvironmentPhaseCreationLoopBackoff {
+		reason = clv1alpha2.ReasonEnforcementFailed
+		if provisioned := meta.FindStatusCondition(instance.Status.Conditions, string(clv1alpha2.ConditionProvisioned)); provisioned != nil &&
+			provisioned.Status == metav1.ConditionFalse {
+			reason, message = clv1alpha2.ConditionReason(provisioned.Reason), provisioned.Message
+		}
+	}
+
+	utils.SetCondition(&instance.Status.Conditions, instance, clv1alpha2.ConditionReady,
+		utils.ConditionStatusFromBool(phase == clv1alpha2.EnvironmentPhaseReady), reason, message)
+}
+
+// setProvisionedCondition configures the Provisioned condition of the instance, reporting the given reason
+// in case an error prevented the enforcement of the environments.
+func setProvisionedCondition(instance *clv1alpha2.Instance, reason clv1alpha2.ConditionReason, err error) {
+	if err != nil {
+		utils.SetCondition(&instance.Status.Conditions, instance, clv1alpha2.ConditionProvisioned, metav1.ConditionFalse, reason, err.Error())
+		return
+	}
+	utils.SetCondition(&instance.Status.Conditions, instance, clv1alpha2.ConditionProvisioned, metav1.ConditionTrue,
+		clv1alpha2.ReasonSucceeded, "The instance environments have been enforced")
+}
+
+// notFoundReason returns the given reason in case of NotFound errors, and a generic one otherwise.
+func notFoundReason(err error, reason clv1alpha2.ConditionReason) clv1alpha2.ConditionReason {
+	if kerrors.IsNotFound(err) {
+		return reason
+	}
+	return clv1alpha2.ReasonEnforcementFailed
+}
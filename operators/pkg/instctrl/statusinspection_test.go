@@ -169,4 +169,34 @@ var _ = Describe("Status Inspection", func() {
 			Entry("When the deployment is being deleted", ForgeStoppingDeployment(), clv1alpha2.EnvironmentPhaseStopping),
 		)
 	})
+
+	Describe("The statusinspection.AggregatePhase function", func() {
+		ForgeEnvironments := func(phases ...clv1alpha2.EnvironmentPhase) []clv1alpha2.InstanceEnvironmentStatus {
+			environments := make([]clv1alpha2.InstanceEnvironmentStatus, len(phases))
+			for i := range phases {
+				environments[i].Phase = phases[i]
+			}
+			return environments
+		}
+
+		DescribeTable("Correctly returns the expected instance phase",
+			func(environments []clv1alpha2.InstanceEnvironmentStatus, expected clv1alpha2.EnvironmentPhase) {
+				Expect(instctrl.AggregatePhase(environments)).To(Equal(expected))
+			},
+			Entry("When no environment is present", ForgeEnvironments(), clv1alpha2.EnvironmentPhaseUnset),
+			Entry("When a single environment is present", ForgeEnvironments(clv1alpha2.EnvironmentPhaseStarting), clv1alpha2.EnvironmentPhaseStarting),
+			Entry("When all the environments are ready",
+				ForgeEnvironments(clv1alpha2.EnvironmentPhaseReady, clv1alpha2.EnvironmentPhaseReady), clv1alpha2.EnvironmentPhaseReady),
+			Entry("When all the environments are off",
+				ForgeEnvironments(clv1alpha2.EnvironmentPhaseOff, clv1alpha2.EnvironmentPhaseOff), clv1alpha2.EnvironmentPhaseOff),
+			Entry("When some environments are ready and others are off",
+				ForgeEnvironments(clv1alpha2.EnvironmentPhaseOff, clv1alpha2.EnvironmentPhaseReady, clv1alpha2.EnvironmentPhaseOff), clv1alpha2.EnvironmentPhaseRunning),
+			Entry("When an environment is still starting",
+				ForgeEnvironments(clv1alpha2.EnvironmentPhaseReady, clv1alpha2.EnvironmentPhaseStarting), clv1alpha2.EnvironmentPhaseStarting),
+			Entry("When an environment has exceeded the resource quota",
+				ForgeEnvironments(clv1alpha2.EnvironmentPhaseStarting, clv1alpha2.EnvironmentPhaseResourceQuotaExceeded), clv1alpha2.EnvironmentPhaseResourceQuotaExceeded),
+			Entry("When an environment has failed",
+				ForgeEnvironments(clv1alpha2.EnvironmentPhaseReady, clv1alpha2.EnvironmentPhaseFailed), clv1alpha2.EnvironmentPhaseFailed),
+		)
+	})
 })
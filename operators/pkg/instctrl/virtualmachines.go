@@ -65,7 +65,7 @@ func (r *InstanceReconciler) enforceVirtualMachine(ctx context.Context) error {
 	instance := clctx.InstanceFrom(ctx)
 	environment := clctx.EnvironmentFrom(ctx)
 
-	vm := virtv1.VirtualMachine{ObjectMeta: forge.ObjectMeta(instance)}
+	vm := virtv1.VirtualMachine{ObjectMeta: forge.EnvironmentObjectMeta(instance, environment)}
 	res, err := ctrl.CreateOrUpdate(ctx, r.Client, &vm, func() error {
 		// VirtualMachine specifications are forged only at creation time, as changing them later may be
 		// either rejected by the webhook or cause the restart of the child VMI, with consequent possible data loss.
@@ -74,7 +74,7 @@ func (r *InstanceReconciler) enforceVirtualMachine(ctx context.Context) error {
 		}
 		// Afterwards, the only modification to the specifications is performed to configure the running flag.
 		vm.Spec.Running = ptr.To(instance.Spec.Running)
-		vm.SetLabels(forge.InstanceObjectLabels(vm.GetLabels(), instance))
+		vm.SetLabels(forge.EnvironmentObjectLabels(vm.GetLabels(), instance, environment))
 		return ctrl.SetControllerReference(instance, &vm, r.Scheme)
 	})
 
@@ -86,21 +86,15 @@ func (r *InstanceReconciler) enforceVirtualMachine(ctx context.Context) error {
 
 	// It is necessary to retrieve the VMI object associated with the VM (if any), to correctly detect the ResourceQuotaExceeded phase.
 	// VM and VMI are characterized by the same resource name.
-	vmi := virtv1.VirtualMachineInstance{ObjectMeta: forge.ObjectMeta(instance)}
+	vmi := virtv1.VirtualMachineInstance{ObjectMeta: forge.EnvironmentObjectMeta(instance, environment)}
 	if err = r.Get(ctx, client.ObjectKeyFromObject(&vmi), &vmi); client.IgnoreNotFound(err) != nil {
 		log.Error(err, "failed to retrieve virtualmachineinstance", "virtualmachineinstance", klog.KObj(&vm))
 		return err
 	} else if err != nil {
-		klog.Infof("VMI %s doesn't exist", instance.Name)
-	}
-	phase := r.RetrievePhaseFromVM(&vm, &vmi)
-
-	if phase != instance.Status.Phase {
-		log.Info("phase changed", "virtualmachine", klog.KObj(&vm),
-			"previous", string(instance.Status.Phase), "current", string(phase))
-		instance.Status.Phase = phase
+		klog.Infof("VMI %s doesn't exist", vmi.Name)
 	}
 
+	setEnvironmentPhase(ctx, r.RetrievePhaseFromVM(&vm, &vmi), "virtualmachine", klog.KObj(&vm))
 	return nil
 }
 
@@ -110,7 +104,7 @@ func (r *InstanceReconciler) enforceVirtualMachineInstance(ctx context.Context)
 	instance := clctx.InstanceFrom(ctx)
 	environment := clctx.EnvironmentFrom(ctx)
 
-	vmi := virtv1.VirtualMachineInstance{ObjectMeta: forge.ObjectMeta(instance)}
+	vmi := virtv1.VirtualMachineInstance{ObjectMeta: forge.EnvironmentObjectMeta(instance, environment)}
 	var phase clv1alpha2.EnvironmentPhase
 
 	// If the Instance is not running, we do not enforce the VirtualMachineInstance presence.
@@ -123,7 +117,7 @@ func (r *InstanceReconciler) enforceVirtualMachineInstance(ctx context.Context)
 			if vmi.CreationTimestamp.IsZero() {
 				vmi.Spec = forge.VirtualMachineInstanceSpec(instance, environment)
 			}
-			vmi.SetLabels(forge.InstanceObjectLabels(vmi.GetLabels(), instance))
+			vmi.SetLabels(forge.EnvironmentObjectLabels(vmi.GetLabels(), instance, environment))
 			return ctrl.SetControllerReference(instance, &vmi, r.Scheme)
 		})
 
@@ -137,11 +131,6 @@ func (r *InstanceReconciler) enforceVirtualMachineInstance(ctx context.Context)
 		phase = clv1alpha2.EnvironmentPhaseOff
 	}
 
-	if phase != instance.Status.Phase {
-		log.Info("phase changed", "virtualmachineinstance", klog.KObj(&vmi),
-			"previous", string(instance.Status.Phase), "current", string(phase))
-		instance.Status.Phase = phase
-	}
-
+	setEnvironmentPhase(ctx, phase, "virtualmachineinstance", klog.KObj(&vmi))
 	return nil
 }
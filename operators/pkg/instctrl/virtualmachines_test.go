@@ -196,7 +196,7 @@ var _ = Describe("Generation of the virtual machine and virtual machine instance
 
 				It("The VMI should be present and have the common attributes", func() {
 					Expect(reconciler.Get(ctx, objectName, &vmi)).To(Succeed())
-					Expect(vmi.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+					Expect(vmi.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 					Expect(vmi.GetOwnerReferences()).To(ContainElement(ownerRef))
 				})
 
@@ -252,7 +252,7 @@ var _ = Describe("Generation of the virtual machine and virtual machine instance
 
 				It("The VMI should still be present and have the common attributes", func() {
 					Expect(reconciler.Get(ctx, objectName, &vmi)).To(Succeed())
-					Expect(vmi.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+					Expect(vmi.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 					Expect(vmi.GetOwnerReferences()).To(ContainElement(ownerRef))
 				})
 
@@ -298,7 +298,7 @@ var _ = Describe("Generation of the virtual machine and virtual machine instance
 
 				It("The VM should be present and have the common attributes", func() {
 					Expect(reconciler.Get(ctx, objectName, &vm)).To(Succeed())
-					Expect(vm.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+					Expect(vm.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 					Expect(vm.GetOwnerReferences()).To(ContainElement(ownerRef))
 				})
 
@@ -339,7 +339,7 @@ var _ = Describe("Generation of the virtual machine and virtual machine instance
 
 				It("The VM should still be present and have the common attributes", func() {
 					Expect(reconciler.Get(ctx, objectName, &vm)).To(Succeed())
-					Expect(vm.GetLabels()).To(Equal(forge.InstanceObjectLabels(nil, &instance)))
+					Expect(vm.GetLabels()).To(Equal(forge.EnvironmentObjectLabels(nil, &instance, &environment)))
 					Expect(vm.GetOwnerReferences()).To(ContainElement(ownerRef))
 				})
 
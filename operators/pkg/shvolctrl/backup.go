@@ -0,0 +1,246 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shvolctrl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/cron"
+)
+
+// SharedVolumeBackupReconciler takes the scheduled backups of SharedVolumes, creating the corresponding
+// SharedVolumeSnapshots according to the configured schedule, and deleting the oldest ones.
+type SharedVolumeBackupReconciler struct {
+	client.Client
+	EventsRecorder     record.EventRecorder
+	NamespaceWhitelist metav1.LabelSelector
+
+	// This function, if configured, is deferred at the beginning of the Reconcile.
+	// Specifically, it is meant to be set to GinkgoRecover during the tests,
+	// in order to lead to a controlled failure in case the Reconcile panics.
+	ReconcileDeferHook func()
+}
+
+// SetupWithManager registers a new controller for the backups of SharedVolume resources.
+func (r *SharedVolumeBackupReconciler) SetupWithManager(mgr ctrl.Manager, concurrency int) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clv1alpha2.SharedVolume{}).
+		Named("sharedvolume-backup").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: concurrency,
+		}).
+		WithLogConstructor(utils.LogConstructor(mgr.GetLogger(), "SharedVolumeBackup")).
+		Complete(r)
+}
+
+// Reconcile takes the scheduled backups of a SharedVolume resource.
+func (r *SharedVolumeBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.ReconcileDeferHook != nil {
+		defer r.ReconcileDeferHook()
+	}
+
+	log := ctrl.LoggerFrom(ctx, "sharedvolume", req.NamespacedName)
+
+	tracer := trace.New("reconcile", trace.Field{Key: "sharedvolume", Value: req.NamespacedName})
+	ctx = trace.ContextWithTrace(ctx, tracer)
+	defer tracer.LogIfLong(utils.LongThreshold())
+
+	var shvol clv1alpha2.SharedVolume
+	if err := r.Get(ctx, req.NamespacedName, &shvol); err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Error(err, "failed retrieving shared volume")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Check the selector label, in order to know whether to perform or not reconciliation.
+	if proceed, err := utils.CheckSelectorLabel(ctrl.LoggerInto(ctx, log), r.Client, shvol.GetNamespace(), r.NamespaceWhitelist.MatchLabels); !proceed {
+		// If there was an error while checking, show the error and try again.
+		if err != nil {
+			log.Error(err, "failed checking selector labels")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	original := shvol.DeepCopy()
+	if shvol.Spec.BackupSchedule == nil || !shvol.GetDeletionTimestamp().IsZero() {
+		shvol.Status.NextBackupTime = nil
+		return ctrl.Result{}, r.patchStatus(ctx, original, &shvol)
+	}
+
+	schedule, location, err := ParseBackupSchedule(shvol.Spec.BackupSchedule)
+	if err != nil {
+		// The schedule is invalid, and it makes no sense to retry until it is modified.
+		log.Error(err, "invalid backup schedule")
+		r.EventsRecorder.Eventf(&shvol, v1.EventTypeWarning, EvBackupScheduleInvalid, EvBackupScheduleInvalidMsg, err)
+		shvol.Status.NextBackupTime = nil
+		return ctrl.Result{}, r.patchStatus(ctx, original, &shvol)
+	}
+
+	var snapshots clv1alpha2.SharedVolumeSnapshotList
+	if err := r.List(ctx, &snapshots, client.InNamespace(shvol.GetNamespace()),
+		client.MatchingLabels{forge.SharedVolumeScheduledBackupLabel: shvol.GetName()}); err != nil {
+		log.Error(err, "failed listing scheduled backups")
+		return ctrl.Result{}, err
+	}
+	backups := snapshots.Items
+
+	now := time.Now()
+	// The previous backup is the most recent one; the first one is scheduled starting from the creation of the shared volume.
+	previous := shvol.GetCreationTimestamp().Time
+	if shvol.Status.LastBackupTime != nil {
+		previous = shvol.Status.LastBackupTime.Time
+	}
+	for i := range backups {
+		if created := backups[i].GetCreationTimestamp().Time; created.After(previous) {
+			previous = created
+		}
+	}
+
+	// A zero next activation time means that no further backup is scheduled (e.g., the schedule stopped activating).
+	if next := schedule.Next(previous.In(location)); !next.IsZero() && !next.After(now) && shvol.Status.Phase == clv1alpha2.SharedVolumePhaseReady {
+		backup := forge.ScheduledSharedVolumeSnapshot(&shvol, now)
+		if err := r.Create(ctx, &backup); client.IgnoreAlreadyExists(err) != nil {
+			log.Error(err, "failed creating scheduled backup")
+			return ctrl.Result{}, err
+		}
+
+		log.Info("scheduled backup created", "sharedvolumesnapshot", backup.GetName())
+		r.EventsRecorder.Eventf(&shvol, v1.EventTypeNormal, EvBackupCreated, EvBackupCreatedMsg, backup.GetName())
+		backup.SetCreationTimestamp(metav1.NewTime(now))
+		backups = append(backups, backup)
+		shvol.Status.LastBackupTime = &metav1.Time{Time: now}
+		previous = now
+	}
+	tracer.Step("scheduled backup enforced")
+
+	if err := r.enforceBackupRetention(ctx, &shvol, backups); err != nil {
+		log.Error(err, "failed enforcing backup retention")
+		return ctrl.Result{}, err
+	}
+	tracer.Step("backup retention enforced")
+
+	next := schedule.Next(previous.In(location))
+	if !next.IsZero() && !next.After(now) {
+		// The backup is overdue, but the shared volume is not yet ready: it will be triggered by the next status change.
+		next = schedule.Next(now.In(location))
+	}
+	if next.IsZero() {
+		log.Info("no further backup scheduled")
+		shvol.Status.NextBackupTime = nil
+		return ctrl.Result{}, r.patchStatus(ctx, original, &shvol)
+	}
+	shvol.Status.NextBackupTime = &metav1.Time{Time: next}
+	if err := r.patchStatus(ctx, original, &shvol); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+}
+
+// enforceBackupRetention deletes the oldest scheduled backups of the given shared volume, so that at most KeepLast ready ones
+// are retained. Only ready backups count towards the limit, so that failed or in progress ones do not cause the deletion of
+// valid ones: the former are deleted once a more recent backup is ready, while the latter are never deleted.
+func (r *SharedVolumeBackupReconciler) enforceBackupRetention(ctx context.Context, shvol *clv1alpha2.SharedVolume, backups []clv1alpha2.SharedVolumeSnapshot) error {
+	keepLast := shvol.Spec.BackupSchedule.KeepLast
+	if keepLast <= 0 {
+		return nil
+	}
+
+	// Sort the backups from the most recent to the oldest one.
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[j].GetCreationTimestamp().Time.Before(backups[i].GetCreationTimestamp().Time)
+	})
+
+	var ready int
+	for i := range backups {
+		backup := &backups[i]
+		switch backup.Status.Phase {
+		case clv1alpha2.SharedVolumeSnapshotPhaseReady:
+			if ready++; ready <= keepLast {
+				continue
+			}
+		case clv1alpha2.SharedVolumeSnapshotPhaseFailed:
+			if ready == 0 {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if err := r.Delete(ctx, backup); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed deleting expired backup %s: %w", backup.GetName(), err)
+		}
+		ctrl.LoggerFrom(ctx).Info("expired scheduled backup deleted", "sharedvolumesnapshot", backup.GetName(), "phase", backup.Status.Phase)
+	}
+	return nil
+}
+
+// patchStatus patches the status of the given shared volume, if modified.
+func (r *SharedVolumeBackupReconciler) patchStatus(ctx context.Context, original, updated *clv1alpha2.SharedVolume) error {
+	if timesEqual(original.Status.LastBackupTime, updated.Status.LastBackupTime) &&
+		timesEqual(original.Status.NextBackupTime, updated.Status.NextBackupTime) {
+		return nil
+	}
+
+	if err := r.Status().Patch(ctx, updated, client.MergeFrom(original)); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to update the sharedvolume backup status")
+		return err
+	}
+	return nil
+}
+
+// ParseBackupSchedule parses the cron expression and the time zone of the given backup schedule,
+// rejecting the expressions which never activate (e.g., "0 0 30 2 *").
+func ParseBackupSchedule(backup *clv1alpha2.SharedVolumeBackupSchedule) (*cron.Schedule, *time.Location, error) {
+	schedule, err := cron.Parse(backup.Schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	location, err := time.LoadLocation(backup.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid timezone %q: %w", backup.Timezone, err)
+	}
+
+	if schedule.Next(time.Now().In(location)).IsZero() {
+		return nil, nil, fmt.Errorf("cron expression %q never activates", backup.Schedule)
+	}
+	return schedule, location, nil
+}
+
+// timesEqual returns whether the given times are both nil, or they refer to the same second.
+func timesEqual(a, b *metav1.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Unix() == b.Unix()
+}
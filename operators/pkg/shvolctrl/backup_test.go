@@ -0,0 +1,163 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shvolctrl_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("The sharedvolume-backup-controller Reconcile method", func() {
+	ctx := context.Background()
+
+	const namespace = "test-backups"
+
+	var (
+		shvol          clv1alpha2.SharedVolume
+		lastBackupTime time.Time
+		existing       int
+		existingPhase  clv1alpha2.SharedVolumeSnapshotPhase
+		result         reconcile.Result
+	)
+
+	ListBackups := func() []clv1alpha2.SharedVolumeSnapshot {
+		var snapshots clv1alpha2.SharedVolumeSnapshotList
+		Expect(k8sClient.List(ctx, &snapshots, client.InNamespace(namespace),
+			client.MatchingLabels{forge.SharedVolumeScheduledBackupLabel: shvol.Name})).To(Succeed())
+		return snapshots.Items
+	}
+
+	BeforeEach(func() {
+		existing = 0
+		existingPhase = clv1alpha2.SharedVolumeSnapshotPhaseReady
+		ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: whiteListMap}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, &ns))).To(Succeed())
+
+		shvol = clv1alpha2.SharedVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "shvol-" + rand.String(6), Namespace: namespace},
+			Spec: clv1alpha2.SharedVolumeSpec{
+				PrettyName: "Course material",
+				Size:       resource.MustParse("1Gi"),
+				BackupSchedule: &clv1alpha2.SharedVolumeBackupSchedule{
+					Schedule: "0 3 * * *",
+					Timezone: "Europe/Rome",
+					KeepLast: 2,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &shvol)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		for i := range existing {
+			backup := forge.ScheduledSharedVolumeSnapshot(&shvol, time.Now().Add(-time.Duration(i+1)*24*time.Hour))
+			Expect(k8sClient.Create(ctx, &backup)).To(Succeed())
+			backup.Status.Phase = existingPhase
+			Expect(k8sClient.Status().Update(ctx, &backup)).To(Succeed())
+		}
+
+		shvol.Status.Phase = clv1alpha2.SharedVolumePhaseReady
+		shvol.Status.LastBackupTime = &metav1.Time{Time: lastBackupTime}
+		Expect(k8sClient.Status().Update(ctx, &shvol)).To(Succeed())
+
+		var err error
+		result, err = shvolBackupReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&shvol)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&shvol), &shvol)).To(Succeed())
+	})
+
+	When("a backup is due", func() {
+		BeforeEach(func() {
+			lastBackupTime = time.Now().Add(-48 * time.Hour)
+		})
+
+		It("Should create a new backup, and schedule the next one", func() {
+			backups := ListBackups()
+			Expect(backups).To(HaveLen(1))
+			Expect(backups[0].Spec.SharedVolumeRef.Name).To(Equal(shvol.Name))
+
+			Expect(shvol.Status.LastBackupTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(shvol.Status.NextBackupTime.Time).To(BeTemporally(">", time.Now()))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", 24*time.Hour))
+		})
+	})
+
+	When("the schedule never activates", func() {
+		BeforeEach(func() {
+			lastBackupTime = time.Now().Add(-48 * time.Hour)
+			shvol.Spec.BackupSchedule.Schedule = "0 0 30 2 *"
+			Expect(k8sClient.Update(ctx, &shvol)).To(Succeed())
+		})
+
+		It("Should not create any backup, nor schedule the next one", func() {
+			Expect(ListBackups()).To(BeEmpty())
+			Expect(shvol.Status.NextBackupTime).To(BeNil())
+			Expect(result.RequeueAfter).To(BeZero())
+		})
+	})
+
+	When("a backup is not due", func() {
+		BeforeEach(func() {
+			lastBackupTime = time.Now()
+		})
+
+		It("Should not create a new backup", func() {
+			Expect(ListBackups()).To(BeEmpty())
+			Expect(shvol.Status.NextBackupTime).ToNot(BeNil())
+		})
+
+		When("more backups than the retained ones exist", func() {
+			BeforeEach(func() {
+				existing = 3
+			})
+
+			It("Should delete the oldest ones", func() {
+				Expect(ListBackups()).To(HaveLen(2))
+			})
+
+			When("the backups are not ready", func() {
+				BeforeEach(func() {
+					existingPhase = clv1alpha2.SharedVolumeSnapshotPhaseProcessing
+				})
+
+				It("Should not delete them", func() {
+					Expect(ListBackups()).To(HaveLen(3))
+				})
+			})
+
+			When("the backups failed, and no ready one exists", func() {
+				BeforeEach(func() {
+					existingPhase = clv1alpha2.SharedVolumeSnapshotPhaseFailed
+				})
+
+				It("Should not delete them", func() {
+					Expect(ListBackups()).To(HaveLen(3))
+				})
+			})
+		})
+	})
+})
@@ -34,4 +34,42 @@ const (
 	EvDeletionBlocked = "DeletionBlocked"
 	// EvDeletionBlockedMsg -> the event message corresponding to blocked deletion.
 	EvDeletionBlockedMsg = "Cannot delete shvol since it is mounted on %v"
+
+	// EvSnapshotStarted -> the event key corresponding to the start of a snapshot.
+	EvSnapshotStarted = "SnapshotStarted"
+	// EvSnapshotStartedMsg -> the event message corresponding to the start of a snapshot.
+	EvSnapshotStartedMsg = "Started taking the snapshot of shvol %s"
+
+	// EvSnapshotReady -> the event key corresponding to a snapshot ready to be restored.
+	EvSnapshotReady = "SnapshotReady"
+	// EvSnapshotReadyMsg -> the event message corresponding to a snapshot ready to be restored.
+	EvSnapshotReadyMsg = "Snapshot of shvol %s ready to be restored"
+
+	// EvSnapshotError -> the event key corresponding to an error while taking a snapshot.
+	EvSnapshotError = "SnapshotError"
+	// EvSnapshotErrorMsg -> the event message corresponding to an error while taking a snapshot.
+	EvSnapshotErrorMsg = "Error while taking the snapshot: %s"
+
+	// EvRestoreStarted -> the event key corresponding to the start of a restore.
+	EvRestoreStarted = "RestoreStarted"
+	// EvRestoreStartedMsg -> the event message corresponding to the start of a restore.
+	EvRestoreStartedMsg = "Started restoring snapshot %s into shvol %s"
+
+	// EvRestoreCompleted -> the event key corresponding to the completion of a restore.
+	EvRestoreCompleted = "RestoreCompleted"
+	// EvRestoreCompletedMsg -> the event message corresponding to the completion of a restore.
+	EvRestoreCompletedMsg = "Snapshot %s restored into shvol %s"
+
+	// EvRestoreFailed -> the event key corresponding to the failure of a restore.
+	EvRestoreFailed = "RestoreFailed"
+
+	// EvBackupCreated -> the event key corresponding to the creation of a scheduled backup.
+	EvBackupCreated = "BackupCreated"
+	// EvBackupCreatedMsg -> the event message corresponding to the creation of a scheduled backup.
+	EvBackupCreatedMsg = "Scheduled backup %s created"
+
+	// EvBackupScheduleInvalid -> the event key corresponding to an invalid backup schedule.
+	EvBackupScheduleInvalid = "InvalidBackupSchedule"
+	// EvBackupScheduleInvalidMsg -> the event message corresponding to an invalid backup schedule.
+	EvBackupScheduleInvalidMsg = "Invalid backup schedule: %v"
 )
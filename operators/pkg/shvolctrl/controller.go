@@ -118,6 +118,7 @@ func (r *SharedVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	if !shvolume.GetDeletionTimestamp().IsZero() {
 		log.Info("Processing delete request")
 		shvolume.Status.Phase = clv1alpha2.SharedVolumePhaseDeleting
+		setReadyCondition(&shvolume, metav1.ConditionFalse, clv1alpha2.ReasonDeleting, "The shared volume is being deleted")
 
 		if ctrlUtil.ContainsFinalizer(&shvolume, clv1alpha2.ShVolCtrlFinalizerName) {
 			if err := r.handleDeletion(ctx, log, &shvolume); err != nil {
@@ -145,7 +146,7 @@ func (r *SharedVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	shvolume.Status.Phase = clv1alpha2.SharedVolumePhaseError
 
 	// Create or Update the PVC, reconciling it with the SharedVolume spec.
-	pvc := v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "shvol-" + shvolume.GetName(), Namespace: shvolume.GetNamespace()}}
+	pvc := v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: forge.SharedVolumePVCName(shvolume.GetName()), Namespace: shvolume.GetNamespace()}}
 
 	pvcOpRes, err := ctrl.CreateOrUpdate(ctx, r.Client, &pvc, func() error {
 		oldSize := *pvc.Spec.Resources.Requests.Storage()
@@ -164,6 +165,8 @@ func (r *SharedVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				"previous", oldSize, "current", shvolume.Spec.Size)
 		} else if sizeDiff < 0 {
 			shvolume.Status.Phase = clv1alpha2.SharedVolumePhaseError
+			setReadyCondition(&shvolume, metav1.ConditionFalse, clv1alpha2.ReasonSizeDecreaseForbidden,
+				fmt.Sprintf("The requested size %s is smaller than the current one %s", shvolume.Spec.Size.String(), oldSize.String()))
 			log.Error(fmt.Errorf("forbidden: size smaller than previous"), "Phase transitioned to Error")
 			r.EventsRecorder.Eventf(&shvolume, v1.EventTypeWarning, EvPVCSmaller, EvPVCSmallerMsg)
 
@@ -175,10 +178,12 @@ func (r *SharedVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	if err != nil {
 		if isResourceQuotaExceeded(err) {
 			shvolume.Status.Phase = clv1alpha2.SharedVolumePhaseResourceQuotaExceeded
+			setReadyCondition(&shvolume, metav1.ConditionFalse, clv1alpha2.ReasonQuotaExceeded, err.Error())
 			log.Error(fmt.Errorf("forbidden: resource quota exceeded"), "Phase transitioned to ResourceQuotaExceeded")
 			r.EventsRecorder.Eventf(&shvolume, v1.EventTypeWarning, EvPVCResQuotaExceeded, EvPVCResQuotaExceededMsg)
 			err = nil
 		} else {
+			setReadyCondition(&shvolume, metav1.ConditionFalse, clv1alpha2.ReasonEnforcementFailed, err.Error())
 			log.Error(err, "Unable to create or update PVC")
 		}
 
@@ -196,12 +201,15 @@ func (r *SharedVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		pv := v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: pvc.Spec.VolumeName}}
 		if err := r.Get(ctx, types.NamespacedName{Name: pv.Name}, &pv); err != nil {
 			log.Error(err, "Unable to get PV")
+			setReadyCondition(&shvolume, metav1.ConditionFalse, clv1alpha2.ReasonEnforcementFailed, err.Error())
 			return ctrl.Result{}, err
 		}
 
 		nfsServer, expPath := forge.NFSShVolSpec(&pv)
 		if nfsServer == "" || expPath == "" {
 			shvolume.Status.Phase = clv1alpha2.SharedVolumePhaseError
+			setReadyCondition(&shvolume, metav1.ConditionFalse, clv1alpha2.ReasonInvalidVolumeSource,
+				fmt.Sprintf("The persistent volume %s does not expose the NFS parameters", pv.Name))
 			log.Error(fmt.Errorf("pv does not have CSI params"), "Phase transitioned to Error")
 			r.EventsRecorder.Eventf(&shvolume, v1.EventTypeWarning, EvPVNoCSI, EvPVNoCSIMsg)
 			return ctrl.Result{}, nil
@@ -211,9 +219,11 @@ func (r *SharedVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		shvolume.Status.ExportPath = expPath
 
 		shvolume.Status.Phase = clv1alpha2.SharedVolumePhaseProvisioning
+		setReadyCondition(&shvolume, metav1.ConditionFalse, clv1alpha2.ReasonProvisioning, "The shared volume is being provisioned")
 
 		done, err := utils.NFSDriveProvisioning(ctx, log, r.Client, &pvc, &shvolume)
 		if err != nil {
+			setReadyCondition(&shvolume, metav1.ConditionFalse, clv1alpha2.ReasonEnforcementFailed, err.Error())
 			return ctrl.Result{}, err
 		} else if done {
 			original := shvolume.DeepCopy()
@@ -224,14 +234,22 @@ func (r *SharedVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			}
 
 			shvolume.Status.Phase = clv1alpha2.SharedVolumePhaseReady
+			setReadyCondition(&shvolume, metav1.ConditionTrue, clv1alpha2.ReasonAvailable, "The shared volume is ready to be mounted")
 		}
 	} else {
 		shvolume.Status.Phase = clv1alpha2.SharedVolumePhasePending
+		setReadyCondition(&shvolume, metav1.ConditionFalse, clv1alpha2.ReasonPVCPending,
+			fmt.Sprintf("Waiting for the persistent volume claim %s to be bound", pvc.Name))
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// setReadyCondition configures the Ready condition of the given SharedVolume.
+func setReadyCondition(shvol *clv1alpha2.SharedVolume, status metav1.ConditionStatus, reason clv1alpha2.ConditionReason, message string) {
+	utils.SetCondition(&shvol.Status.Conditions, shvol, clv1alpha2.ConditionReady, status, reason, message)
+}
+
 func isResourceQuotaExceeded(err error) bool {
 	return kerrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota")
 }
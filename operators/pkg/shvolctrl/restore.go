@@ -0,0 +1,416 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shvolctrl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"k8s.io/utils/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+// restoreInUseRequeueInterval -> the interval after which a restore waiting for the instances mounting the target to be stopped is reconsidered.
+const restoreInUseRequeueInterval = time.Minute
+
+// SharedVolumeRestoreReconciler reconciles a SharedVolumeRestore object, restoring a SharedVolumeSnapshot into
+// a new or existing SharedVolume. The snapshot is first restored into a temporary PVC, whose content is then
+// copied into the one of the target SharedVolume by a job, since CSI snapshots can only populate new volumes.
+type SharedVolumeRestoreReconciler struct {
+	client.Client
+	EventsRecorder     record.EventRecorder
+	NamespaceWhitelist metav1.LabelSelector
+	PVCStorageClass    string
+
+	// This function, if configured, is deferred at the beginning of the Reconcile.
+	// Specifically, it is meant to be set to GinkgoRecover during the tests,
+	// in order to lead to a controlled failure in case the Reconcile panics.
+	ReconcileDeferHook func()
+}
+
+// SetupWithManager registers a new controller for SharedVolumeRestore resources.
+func (r *SharedVolumeRestoreReconciler) SetupWithManager(mgr ctrl.Manager, concurrency int) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clv1alpha2.SharedVolumeRestore{}).
+		Owns(&v1.PersistentVolumeClaim{}).
+		Owns(&batchv1.Job{}).
+		// Pending restores proceed once the snapshot and the target shared volume become ready.
+		Watches(&clv1alpha2.SharedVolumeSnapshot{}, handler.EnqueueRequestsFromMapFunc(r.objectToRestores(snapshotRefMatches))).
+		Watches(&clv1alpha2.SharedVolume{}, handler.EnqueueRequestsFromMapFunc(r.objectToRestores(targetRefMatches))).
+		Named("sharedvolume-restore").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: concurrency,
+		}).
+		WithLogConstructor(utils.LogConstructor(mgr.GetLogger(), "SharedVolumeRestore")).
+		Complete(r)
+}
+
+// Reconcile reconciles the state of a SharedVolumeRestore resource.
+func (r *SharedVolumeRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	if r.ReconcileDeferHook != nil {
+		defer r.ReconcileDeferHook()
+	}
+
+	log := ctrl.LoggerFrom(ctx, "sharedvolumerestore", req.NamespacedName)
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	tracer := trace.New("reconcile", trace.Field{Key: "sharedvolumerestore", Value: req.NamespacedName})
+	ctx = trace.ContextWithTrace(ctx, tracer)
+	defer tracer.LogIfLong(utils.LongThreshold())
+
+	var restore clv1alpha2.SharedVolumeRestore
+	if err = r.Get(ctx, req.NamespacedName, &restore); err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Error(err, "failed retrieving shared volume restore")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Check the selector label, in order to know whether to perform or not reconciliation.
+	if proceed, err := utils.CheckSelectorLabel(ctx, r.Client, restore.GetNamespace(), r.NamespaceWhitelist.MatchLabels); !proceed {
+		// If there was an error while checking, show the error and try again.
+		if err != nil {
+			log.Error(err, "failed checking selector labels")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !restore.GetDeletionTimestamp().IsZero() {
+		// The temporary PVC and the job are deleted through the owner reference.
+		return ctrl.Result{}, nil
+	}
+
+	// Update the status at the end of the reconciliation, depending on the observed state.
+	defer func(original, updated *clv1alpha2.SharedVolumeRestore) {
+		if !reflect.DeepEqual(original.Status, updated.Status) {
+			if err2 := r.Status().Patch(ctx, updated, client.MergeFrom(original)); err2 != nil {
+				log.Error(err2, "failed to update the sharedvolumerestore status")
+				err = err2
+			} else {
+				tracer.Step("sharedvolumerestore status updated")
+				log.Info("sharedvolumerestore status correctly updated", "phase", updated.Status.Phase)
+			}
+		}
+	}(restore.DeepCopy(), &restore)
+
+	if restore.Status.Phase == clv1alpha2.SharedVolumeRestorePhaseCompleted || restore.Status.Phase == clv1alpha2.SharedVolumeRestorePhaseFailed {
+		// The temporary PVC is no longer necessary once the restore process terminated.
+		return ctrl.Result{}, r.deleteTemporaryPVC(ctx, &restore)
+	}
+
+	var shvolsnap clv1alpha2.SharedVolumeSnapshot
+	snapKey := types.NamespacedName{Namespace: restore.GetNamespace(), Name: restore.Spec.SnapshotRef.Name}
+	if err := r.Get(ctx, snapKey, &shvolsnap); kerrors.IsNotFound(err) {
+		r.fail(&restore, clv1alpha2.ReasonSnapshotNotFound, fmt.Sprintf("The shared volume snapshot %s does not exist", snapKey.Name))
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		log.Error(err, "failed retrieving shared volume snapshot", "sharedvolumesnapshot", snapKey)
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case shvolsnap.Status.Phase == clv1alpha2.SharedVolumeSnapshotPhaseFailed:
+		r.fail(&restore, clv1alpha2.ReasonSnapshotFailed, fmt.Sprintf("The shared volume snapshot %s failed", snapKey.Name))
+		return ctrl.Result{}, nil
+	case shvolsnap.Status.Phase != clv1alpha2.SharedVolumeSnapshotPhaseReady || shvolsnap.Status.RestoreSize == nil:
+		setShVolRestoreReadyCondition(&restore, clv1alpha2.SharedVolumeRestorePhasePending, metav1.ConditionFalse, clv1alpha2.ReasonSnapshotNotReady,
+			fmt.Sprintf("Waiting for the shared volume snapshot %s to be ready", snapKey.Name))
+		return ctrl.Result{}, nil
+	}
+
+	target, err := r.enforceTarget(ctx, &restore, &shvolsnap)
+	if err != nil || target == nil {
+		return ctrl.Result{}, err
+	}
+	tracer.Step("target shared volume enforced")
+
+	if target.Status.Phase != clv1alpha2.SharedVolumePhaseReady {
+		setShVolRestoreReadyCondition(&restore, clv1alpha2.SharedVolumeRestorePhasePending, metav1.ConditionFalse, clv1alpha2.ReasonSharedVolumeNotReady,
+			fmt.Sprintf("Waiting for the shared volume %s to be ready", target.GetName()))
+		return ctrl.Result{}, nil
+	}
+
+	if target.Spec.Size.Cmp(*shvolsnap.Status.RestoreSize) < 0 {
+		r.fail(&restore, clv1alpha2.ReasonInsufficientSize, fmt.Sprintf("The shared volume %s (%s) is smaller than the snapshot (%s)",
+			target.GetName(), target.Spec.Size.String(), shvolsnap.Status.RestoreSize.String()))
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.enforceTemporaryPVC(ctx, &restore, &shvolsnap); err != nil {
+		setShVolRestoreReadyCondition(&restore, restore.Status.Phase, metav1.ConditionFalse, clv1alpha2.ReasonEnforcementFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+	tracer.Step("temporary pvc enforced")
+
+	return r.enforceRestoreJob(ctx, &restore, target)
+}
+
+// enforceTarget retrieves the target shared volume of the given restore, creating it if it does not exist.
+// A nil shared volume is returned in case the restore cannot proceed.
+func (r *SharedVolumeRestoreReconciler) enforceTarget(ctx context.Context, restore *clv1alpha2.SharedVolumeRestore,
+	shvolsnap *clv1alpha2.SharedVolumeSnapshot) (*clv1alpha2.SharedVolume, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	target := clv1alpha2.SharedVolume{}
+	targetKey := types.NamespacedName{Namespace: restore.GetNamespace(), Name: restore.Spec.TargetRef.Name}
+	if err := r.Get(ctx, targetKey, &target); err == nil {
+		return &target, nil
+	} else if !kerrors.IsNotFound(err) {
+		log.Error(err, "failed retrieving target shared volume", "sharedvolume", targetKey)
+		return nil, err
+	}
+
+	if restore.Status.TargetCreated {
+		// The shared volume created by the restore process has been deleted in the meanwhile.
+		r.fail(restore, clv1alpha2.ReasonSharedVolumeNotFound, fmt.Sprintf("The shared volume %s no longer exists", targetKey.Name))
+		return nil, nil
+	}
+
+	prettyName := restore.Spec.PrettyName
+	if prettyName == "" {
+		var source clv1alpha2.SharedVolume
+		sourceKey := types.NamespacedName{Namespace: restore.GetNamespace(), Name: shvolsnap.Spec.SharedVolumeRef.Name}
+		if err := r.Get(ctx, sourceKey, &source); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "failed retrieving snapshotted shared volume", "sharedvolume", sourceKey)
+			return nil, err
+		}
+		prettyName = source.Spec.PrettyName
+		if prettyName == "" {
+			prettyName = targetKey.Name
+		}
+	}
+
+	target = clv1alpha2.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: targetKey.Name, Namespace: targetKey.Namespace},
+		Spec:       clv1alpha2.SharedVolumeSpec{PrettyName: prettyName, Size: *shvolsnap.Status.RestoreSize},
+	}
+	if err := r.Create(ctx, &target); err != nil {
+		log.Error(err, "failed creating target shared volume", "sharedvolume", targetKey)
+		setShVolRestoreReadyCondition(restore, clv1alpha2.SharedVolumeRestorePhasePending, metav1.ConditionFalse, clv1alpha2.ReasonEnforcementFailed, err.Error())
+		return nil, err
+	}
+
+	log.Info("target shared volume created", "sharedvolume", targetKey)
+	restore.Status.TargetCreated = true
+	return &target, nil
+}
+
+// enforceTemporaryPVC creates the temporary PVC populated with the content of the given snapshot, if not already present.
+func (r *SharedVolumeRestoreReconciler) enforceTemporaryPVC(ctx context.Context, restore *clv1alpha2.SharedVolumeRestore,
+	shvolsnap *clv1alpha2.SharedVolumeSnapshot) error {
+	pvc := v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: forge.SharedVolumeRestoreObjectName(restore), Namespace: restore.GetNamespace()}}
+
+	res, err := ctrl.CreateOrUpdate(ctx, r.Client, &pvc, func() error {
+		// The specification of the PVC is immutable once created.
+		if pvc.CreationTimestamp.IsZero() {
+			pvc.Spec = forge.SharedVolumeRestorePVCSpec(&r.PVCStorageClass, *shvolsnap.Status.RestoreSize, shvolsnap.Status.VolumeSnapshotName)
+		}
+		pvc.SetLabels(forge.SharedVolumeObjectLabels(pvc.GetLabels()))
+		return ctrl.SetControllerReference(restore, &pvc, r.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed enforcing temporary pvc: %w", err)
+	}
+
+	ctrl.LoggerFrom(ctx).V(utils.LogDebugLevel).Info("temporary pvc enforced", "pvc", pvc.GetName(), "result", res)
+	return nil
+}
+
+// enforceRestoreJob creates the job copying the content of the temporary PVC into the one of the target
+// shared volume, and updates the status of the restore depending on the status of the job.
+func (r *SharedVolumeRestoreReconciler) enforceRestoreJob(ctx context.Context, restore *clv1alpha2.SharedVolumeRestore,
+	target *clv1alpha2.SharedVolume) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	job := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: forge.SharedVolumeRestoreObjectName(restore), Namespace: restore.GetNamespace()}}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(&job), &job); kerrors.IsNotFound(err) {
+		// Overwriting the content of a shared volume while mounted would lead to inconsistencies.
+		instances, err := r.mountingInstances(ctx, target)
+		if err != nil {
+			log.Error(err, "failed retrieving the instances mounting the target shared volume")
+			return ctrl.Result{}, err
+		}
+		if len(instances) > 0 {
+			setShVolRestoreReadyCondition(restore, clv1alpha2.SharedVolumeRestorePhasePending, metav1.ConditionFalse, clv1alpha2.ReasonSharedVolumeInUse,
+				fmt.Sprintf("Waiting for the instances mounting the shared volume %s to be stopped: %s", target.GetName(), strings.Join(instances, ", ")))
+			return ctrl.Result{RequeueAfter: restoreInUseRequeueInterval}, nil
+		}
+
+		job.SetLabels(forge.SharedVolumeObjectLabels(nil))
+		job.Spec = forge.SharedVolumeRestoreJobSpec(forge.SharedVolumeRestoreObjectName(restore), forge.SharedVolumePVCName(target.GetName()))
+		if err := ctrl.SetControllerReference(restore, &job, r.Scheme()); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, &job); err != nil {
+			log.Error(err, "failed creating restore job")
+			setShVolRestoreReadyCondition(restore, clv1alpha2.SharedVolumeRestorePhasePending, metav1.ConditionFalse, clv1alpha2.ReasonEnforcementFailed, err.Error())
+			return ctrl.Result{}, err
+		}
+
+		log.Info("restore job created", "job", job.GetName())
+		r.EventsRecorder.Eventf(restore, v1.EventTypeNormal, EvRestoreStarted, EvRestoreStartedMsg, restore.Spec.SnapshotRef.Name, target.GetName())
+		restore.Status.StartTime = ptr.To(metav1.Now())
+		setShVolRestoreReadyCondition(restore, clv1alpha2.SharedVolumeRestorePhaseRestoring, metav1.ConditionFalse, clv1alpha2.ReasonJobPending,
+			"The restore job has been created")
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		log.Error(err, "failed retrieving restore job")
+		return ctrl.Result{}, err
+	}
+
+	if restore.Status.StartTime == nil {
+		restore.Status.StartTime = job.Status.StartTime
+	}
+
+	switch {
+	case jobHasCondition(&job, batchv1.JobComplete):
+		restore.Status.CompletionTime = ptr.To(metav1.Now())
+		setShVolRestoreReadyCondition(restore, clv1alpha2.SharedVolumeRestorePhaseCompleted, metav1.ConditionTrue, clv1alpha2.ReasonSucceeded,
+			fmt.Sprintf("The snapshot has been restored into the shared volume %s", target.GetName()))
+		r.EventsRecorder.Eventf(restore, v1.EventTypeNormal, EvRestoreCompleted, EvRestoreCompletedMsg, restore.Spec.SnapshotRef.Name, target.GetName())
+		return ctrl.Result{}, r.deleteTemporaryPVC(ctx, restore)
+	case jobHasCondition(&job, batchv1.JobFailed):
+		r.fail(restore, clv1alpha2.ReasonJobFailed, "The restore job failed")
+		return ctrl.Result{}, r.deleteTemporaryPVC(ctx, restore)
+	case job.Status.Active > 0:
+		setShVolRestoreReadyCondition(restore, clv1alpha2.SharedVolumeRestorePhaseRestoring, metav1.ConditionFalse, clv1alpha2.ReasonJobRunning,
+			"The content of the snapshot is being copied")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// mountingInstances returns the names of the instances which are not stopped, and whose template mounts the given shared volume.
+func (r *SharedVolumeRestoreReconciler) mountingInstances(ctx context.Context, shvol *clv1alpha2.SharedVolume) ([]string, error) {
+	var templates clv1alpha2.TemplateList
+	if err := r.List(ctx, &templates); err != nil {
+		return nil, fmt.Errorf("failed listing templates: %w", err)
+	}
+
+	mounting := make(map[types.NamespacedName]bool)
+	for i := range templates.Items {
+		for j := range templates.Items[i].Spec.EnvironmentList {
+			for _, mount := range templates.Items[i].Spec.EnvironmentList[j].SharedVolumeMounts {
+				if mount.SharedVolumeRef.Name == shvol.GetName() && mount.SharedVolumeRef.Namespace == shvol.GetNamespace() {
+					mounting[client.ObjectKeyFromObject(&templates.Items[i])] = true
+				}
+			}
+		}
+	}
+	if len(mounting) == 0 {
+		return nil, nil
+	}
+
+	var instances clv1alpha2.InstanceList
+	if err := r.List(ctx, &instances); err != nil {
+		return nil, fmt.Errorf("failed listing instances: %w", err)
+	}
+
+	var names []string
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		template := types.NamespacedName{Namespace: instance.Spec.Template.Namespace, Name: instance.Spec.Template.Name}
+		// Instances being stopped are considered until they are actually turned off.
+		stopped := !instance.Spec.Running && (instance.Status.Phase == clv1alpha2.EnvironmentPhaseOff || instance.Status.Phase == "")
+		if mounting[template] && !stopped {
+			names = append(names, fmt.Sprintf("%s/%s", instance.GetNamespace(), instance.GetName()))
+		}
+	}
+	return names, nil
+}
+
+// deleteTemporaryPVC deletes the temporary PVC used by the given restore, if still present.
+func (r *SharedVolumeRestoreReconciler) deleteTemporaryPVC(ctx context.Context, restore *clv1alpha2.SharedVolumeRestore) error {
+	pvc := v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: forge.SharedVolumeRestoreObjectName(restore), Namespace: restore.GetNamespace()}}
+	if err := r.Delete(ctx, &pvc); client.IgnoreNotFound(err) != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed deleting temporary pvc")
+		return err
+	}
+	return nil
+}
+
+// fail marks the given restore as failed, for the given reason.
+func (r *SharedVolumeRestoreReconciler) fail(restore *clv1alpha2.SharedVolumeRestore, reason clv1alpha2.ConditionReason, message string) {
+	restore.Status.CompletionTime = ptr.To(metav1.Now())
+	setShVolRestoreReadyCondition(restore, clv1alpha2.SharedVolumeRestorePhaseFailed, metav1.ConditionFalse, reason, message)
+	r.EventsRecorder.Event(restore, v1.EventTypeWarning, EvRestoreFailed, message)
+}
+
+// objectToRestores returns a function mapping an object to a reconcile request for each non-terminated
+// SharedVolumeRestore in the same namespace referring to it, according to the given matcher.
+func (r *SharedVolumeRestoreReconciler) objectToRestores(matches func(*clv1alpha2.SharedVolumeRestore, string) bool) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		var restores clv1alpha2.SharedVolumeRestoreList
+		if err := r.List(ctx, &restores, client.InNamespace(o.GetNamespace())); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed listing shared volume restores", "object", client.ObjectKeyFromObject(o))
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for i := range restores.Items {
+			restore := &restores.Items[i]
+			if matches(restore, o.GetName()) && restore.Status.CompletionTime == nil {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(restore)})
+			}
+		}
+		return requests
+	}
+}
+
+// snapshotRefMatches returns whether the given restore refers to the snapshot with the given name.
+func snapshotRefMatches(restore *clv1alpha2.SharedVolumeRestore, name string) bool {
+	return restore.Spec.SnapshotRef.Name == name
+}
+
+// targetRefMatches returns whether the given restore targets the shared volume with the given name.
+func targetRefMatches(restore *clv1alpha2.SharedVolumeRestore, name string) bool {
+	return restore.Spec.TargetRef.Name == name
+}
+
+// jobHasCondition returns whether the given condition is true for the given job.
+func jobHasCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == conditionType && job.Status.Conditions[i].Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// setShVolRestoreReadyCondition configures the phase and the Ready condition of the given SharedVolumeRestore.
+func setShVolRestoreReadyCondition(restore *clv1alpha2.SharedVolumeRestore, phase clv1alpha2.SharedVolumeRestorePhase,
+	status metav1.ConditionStatus, reason clv1alpha2.ConditionReason, message string) {
+	restore.Status.Phase = phase
+	utils.SetCondition(&restore.Status.Conditions, restore, clv1alpha2.ConditionReady, status, reason, message)
+}
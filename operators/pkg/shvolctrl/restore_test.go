@@ -0,0 +1,234 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shvolctrl_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("The sharedvolume-restore-controller Reconcile method", func() {
+	ctx := context.Background()
+
+	const namespace = "test-restores"
+
+	var (
+		shvolsnap clv1alpha2.SharedVolumeSnapshot
+		restore   clv1alpha2.SharedVolumeRestore
+		target    clv1alpha2.SharedVolume
+	)
+
+	RunReconciler := func() error {
+		_, err := shvolRestoreReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&restore)})
+		if err != nil {
+			return err
+		}
+		return k8sClient.Get(ctx, client.ObjectKeyFromObject(&restore), &restore)
+	}
+
+	ObjectNamespacedName := func() types.NamespacedName {
+		return types.NamespacedName{Namespace: namespace, Name: forge.SharedVolumeRestoreObjectName(&restore)}
+	}
+
+	TargetNamespacedName := func() types.NamespacedName {
+		return types.NamespacedName{Namespace: namespace, Name: restore.Spec.TargetRef.Name}
+	}
+
+	BeforeEach(func() {
+		ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: whiteListMap}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, &ns))).To(Succeed())
+
+		shvolsnap = clv1alpha2.SharedVolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "snap-" + rand.String(6), Namespace: namespace},
+			Spec:       clv1alpha2.SharedVolumeSnapshotSpec{SharedVolumeRef: clv1alpha2.GenericRef{Name: "source"}},
+		}
+		Expect(k8sClient.Create(ctx, &shvolsnap)).To(Succeed())
+
+		restore = clv1alpha2.SharedVolumeRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore-" + rand.String(6), Namespace: namespace},
+			Spec: clv1alpha2.SharedVolumeRestoreSpec{
+				SnapshotRef: clv1alpha2.GenericRef{Name: shvolsnap.Name},
+				TargetRef:   clv1alpha2.GenericRef{Name: "target-" + rand.String(6)},
+				PrettyName:  "Restored material",
+			},
+		}
+		Expect(k8sClient.Create(ctx, &restore)).To(Succeed())
+	})
+
+	When("the snapshot is not ready", func() {
+		It("Should wait, without creating the target shared volume", func() {
+			Expect(RunReconciler()).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(clv1alpha2.SharedVolumeRestorePhasePending))
+			Expect(kerrors.IsNotFound(k8sClient.Get(ctx, TargetNamespacedName(), &target))).To(BeTrue())
+		})
+	})
+
+	When("the snapshot does not exist", func() {
+		BeforeEach(func() {
+			Expect(k8sClient.Delete(ctx, &shvolsnap)).To(Succeed())
+		})
+
+		It("Should fail", func() {
+			Expect(RunReconciler()).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(clv1alpha2.SharedVolumeRestorePhaseFailed))
+			Expect(restore.Status.CompletionTime).ToNot(BeNil())
+		})
+	})
+
+	When("the snapshot is ready", func() {
+		BeforeEach(func() {
+			shvolsnap.Status.Phase = clv1alpha2.SharedVolumeSnapshotPhaseReady
+			shvolsnap.Status.VolumeSnapshotName = forge.SharedVolumeSnapshotVolumeSnapshotName(&shvolsnap)
+			shvolsnap.Status.RestoreSize = resource.NewScaledQuantity(2, resource.Giga)
+			Expect(k8sClient.Status().Update(ctx, &shvolsnap)).To(Succeed())
+		})
+
+		Context("The target shared volume does not exist", func() {
+			BeforeEach(func() {
+				Expect(RunReconciler()).To(Succeed())
+			})
+
+			It("Should create the target shared volume, with the size of the snapshot", func() {
+				Expect(restore.Status.Phase).To(Equal(clv1alpha2.SharedVolumeRestorePhasePending))
+				Expect(restore.Status.TargetCreated).To(BeTrue())
+
+				Expect(k8sClient.Get(ctx, TargetNamespacedName(), &target)).To(Succeed())
+				Expect(target.Spec.PrettyName).To(Equal("Restored material"))
+				Expect(target.Spec.Size.Cmp(*shvolsnap.Status.RestoreSize)).To(BeZero())
+			})
+
+			When("the target shared volume becomes ready", func() {
+				var job batchv1.Job
+
+				BeforeEach(func() {
+					Expect(k8sClient.Get(ctx, TargetNamespacedName(), &target)).To(Succeed())
+					target.Status.Phase = clv1alpha2.SharedVolumePhaseReady
+					Expect(k8sClient.Status().Update(ctx, &target)).To(Succeed())
+					Expect(RunReconciler()).To(Succeed())
+				})
+
+				It("Should create the temporary PVC from the snapshot, and the restore job", func() {
+					Expect(restore.Status.Phase).To(Equal(clv1alpha2.SharedVolumeRestorePhaseRestoring))
+					Expect(restore.Status.StartTime).ToNot(BeNil())
+
+					var pvc corev1.PersistentVolumeClaim
+					Expect(k8sClient.Get(ctx, ObjectNamespacedName(), &pvc)).To(Succeed())
+					Expect(pvc.Spec.DataSource).ToNot(BeNil())
+					Expect(pvc.Spec.DataSource.Name).To(Equal(shvolsnap.Status.VolumeSnapshotName))
+					Expect(pvc.Spec.StorageClassName).To(HaveValue(Equal(pvcStorageClass)))
+
+					Expect(k8sClient.Get(ctx, ObjectNamespacedName(), &job)).To(Succeed())
+					Expect(job.Spec.Template.Spec.Volumes).To(HaveLen(2))
+					Expect(job.Spec.Template.Spec.Volumes[1].PersistentVolumeClaim.ClaimName).To(Equal(forge.SharedVolumePVCName(target.Name)))
+				})
+
+				When("the restore job completes", func() {
+					BeforeEach(func() {
+						Expect(k8sClient.Get(ctx, ObjectNamespacedName(), &job)).To(Succeed())
+						job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+						Expect(k8sClient.Status().Update(ctx, &job)).To(Succeed())
+						Expect(RunReconciler()).To(Succeed())
+					})
+
+					It("Should complete the restore, and delete the temporary PVC", func() {
+						Expect(restore.Status.Phase).To(Equal(clv1alpha2.SharedVolumeRestorePhaseCompleted))
+						Expect(restore.Status.CompletionTime).ToNot(BeNil())
+
+						var pvc corev1.PersistentVolumeClaim
+						err := k8sClient.Get(ctx, ObjectNamespacedName(), &pvc)
+						Expect(kerrors.IsNotFound(err) || !pvc.GetDeletionTimestamp().IsZero()).To(BeTrue())
+					})
+				})
+			})
+		})
+
+		Context("The target shared volume is mounted by a running instance", func() {
+			BeforeEach(func() {
+				target = clv1alpha2.SharedVolume{
+					ObjectMeta: metav1.ObjectMeta{Name: restore.Spec.TargetRef.Name, Namespace: namespace},
+					Spec:       clv1alpha2.SharedVolumeSpec{PrettyName: "Mounted", Size: *resource.NewScaledQuantity(2, resource.Giga)},
+				}
+				Expect(k8sClient.Create(ctx, &target)).To(Succeed())
+				target.Status.Phase = clv1alpha2.SharedVolumePhaseReady
+				Expect(k8sClient.Status().Update(ctx, &target)).To(Succeed())
+
+				template := clv1alpha2.Template{
+					ObjectMeta: metav1.ObjectMeta{Name: "template-" + rand.String(6), Namespace: namespace},
+					Spec: clv1alpha2.TemplateSpec{
+						PrettyName:   "Mounting template",
+						WorkspaceRef: clv1alpha2.GenericRef{Name: "netgroup"},
+						EnvironmentList: []clv1alpha2.Environment{{
+							Name: "app", EnvironmentType: clv1alpha2.ClassContainer, Image: "crownlabs/app",
+							SharedVolumeMounts: []clv1alpha2.SharedVolumeMountInfo{{
+								SharedVolumeRef: clv1alpha2.GenericRef{Name: target.Name, Namespace: namespace}, MountPath: "/data",
+							}},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, &template)).To(Succeed())
+
+				instance := clv1alpha2.Instance{
+					ObjectMeta: metav1.ObjectMeta{Name: "instance-" + rand.String(6), Namespace: namespace},
+					Spec: clv1alpha2.InstanceSpec{
+						Running:  true,
+						Template: clv1alpha2.GenericRef{Name: template.Name, Namespace: namespace},
+						Tenant:   clv1alpha2.GenericRef{Name: "tester"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, &instance)).To(Succeed())
+				Expect(RunReconciler()).To(Succeed())
+			})
+
+			It("Should wait for the instance to be stopped, without starting the restore job", func() {
+				Expect(restore.Status.Phase).To(Equal(clv1alpha2.SharedVolumeRestorePhasePending))
+				Expect(restore.Status.Conditions).To(ContainElement(HaveField("Reason", string(clv1alpha2.ReasonSharedVolumeInUse))))
+				Expect(k8sClient.Get(ctx, ObjectNamespacedName(), &batchv1.Job{})).ToNot(Succeed())
+			})
+		})
+
+		Context("The target shared volume is smaller than the snapshot", func() {
+			BeforeEach(func() {
+				target = clv1alpha2.SharedVolume{
+					ObjectMeta: metav1.ObjectMeta{Name: restore.Spec.TargetRef.Name, Namespace: namespace},
+					Spec:       clv1alpha2.SharedVolumeSpec{PrettyName: "Small", Size: *resource.NewScaledQuantity(1, resource.Giga)},
+				}
+				Expect(k8sClient.Create(ctx, &target)).To(Succeed())
+				target.Status.Phase = clv1alpha2.SharedVolumePhaseReady
+				Expect(k8sClient.Status().Update(ctx, &target)).To(Succeed())
+				Expect(RunReconciler()).To(Succeed())
+			})
+
+			It("Should fail, without modifying the shared volume", func() {
+				Expect(restore.Status.Phase).To(Equal(clv1alpha2.SharedVolumeRestorePhaseFailed))
+				Expect(restore.Status.TargetCreated).To(BeFalse())
+				Expect(k8sClient.Get(ctx, ObjectNamespacedName(), &batchv1.Job{})).ToNot(Succeed())
+			})
+		})
+	})
+})
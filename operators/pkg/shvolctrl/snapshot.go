@@ -0,0 +1,241 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shvolctrl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+// SharedVolumeSnapshotReconciler reconciles a SharedVolumeSnapshot object, taking the corresponding CSI VolumeSnapshot.
+type SharedVolumeSnapshotReconciler struct {
+	client.Client
+	EventsRecorder     record.EventRecorder
+	NamespaceWhitelist metav1.LabelSelector
+	// The VolumeSnapshotClass used when not specified by the SharedVolumeSnapshot (empty for the default one).
+	VolumeSnapshotClass string
+
+	// This function, if configured, is deferred at the beginning of the Reconcile.
+	// Specifically, it is meant to be set to GinkgoRecover during the tests,
+	// in order to lead to a controlled failure in case the Reconcile panics.
+	ReconcileDeferHook func()
+}
+
+// SetupWithManager registers a new controller for SharedVolumeSnapshot resources.
+func (r *SharedVolumeSnapshotReconciler) SetupWithManager(mgr ctrl.Manager, concurrency int) error {
+	volumeSnapshot := unstructured.Unstructured{}
+	volumeSnapshot.SetGroupVersionKind(forge.VolumeSnapshotGVK)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clv1alpha2.SharedVolumeSnapshot{}).
+		Owns(&volumeSnapshot).
+		// Pending snapshots are taken once the corresponding shared volume becomes ready.
+		Watches(&clv1alpha2.SharedVolume{}, handler.EnqueueRequestsFromMapFunc(r.sharedVolumeToSnapshots)).
+		Named("sharedvolume-snapshot").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: concurrency,
+		}).
+		WithLogConstructor(utils.LogConstructor(mgr.GetLogger(), "SharedVolumeSnapshot")).
+		Complete(r)
+}
+
+// Reconcile reconciles the state of a SharedVolumeSnapshot resource.
+func (r *SharedVolumeSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	if r.ReconcileDeferHook != nil {
+		defer r.ReconcileDeferHook()
+	}
+
+	log := ctrl.LoggerFrom(ctx, "sharedvolumesnapshot", req.NamespacedName)
+
+	tracer := trace.New("reconcile", trace.Field{Key: "sharedvolumesnapshot", Value: req.NamespacedName})
+	ctx = trace.ContextWithTrace(ctx, tracer)
+	defer tracer.LogIfLong(utils.LongThreshold())
+
+	var shvolsnap clv1alpha2.SharedVolumeSnapshot
+	if err = r.Get(ctx, req.NamespacedName, &shvolsnap); err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Error(err, "failed retrieving shared volume snapshot")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Check the selector label, in order to know whether to perform or not reconciliation.
+	if proceed, err := utils.CheckSelectorLabel(ctrl.LoggerInto(ctx, log), r.Client, shvolsnap.GetNamespace(), r.NamespaceWhitelist.MatchLabels); !proceed {
+		// If there was an error while checking, show the error and try again.
+		if err != nil {
+			log.Error(err, "failed checking selector labels")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !shvolsnap.GetDeletionTimestamp().IsZero() {
+		// The CSI VolumeSnapshot is deleted through the owner reference.
+		return ctrl.Result{}, nil
+	}
+
+	// Update the status at the end of the reconciliation, depending on the observed state.
+	defer func(original, updated *clv1alpha2.SharedVolumeSnapshot) {
+		if !reflect.DeepEqual(original.Status, updated.Status) {
+			if err2 := r.Status().Patch(ctx, updated, client.MergeFrom(original)); err2 != nil {
+				log.Error(err2, "failed to update the sharedvolumesnapshot status")
+				err = err2
+			} else {
+				tracer.Step("sharedvolumesnapshot status updated")
+				log.Info("sharedvolumesnapshot status correctly updated", "phase", updated.Status.Phase)
+			}
+		}
+	}(shvolsnap.DeepCopy(), &shvolsnap)
+
+	if shvolsnap.Status.Phase == clv1alpha2.SharedVolumeSnapshotPhaseFailed {
+		return ctrl.Result{}, nil
+	}
+
+	snapshot := unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(forge.VolumeSnapshotGVK)
+	snapshot.SetName(forge.SharedVolumeSnapshotVolumeSnapshotName(&shvolsnap))
+	snapshot.SetNamespace(shvolsnap.GetNamespace())
+
+	// The CSI VolumeSnapshot is created only once, as its source cannot be modified afterwards.
+	if err := r.Get(ctx, client.ObjectKeyFromObject(&snapshot), &snapshot); kerrors.IsNotFound(err) {
+		if shvolsnap.Status.VolumeSnapshotName != "" {
+			// The snapshot has been externally deleted, and its content is lost.
+			setShVolSnapReadyCondition(&shvolsnap, clv1alpha2.SharedVolumeSnapshotPhaseFailed, metav1.ConditionFalse, clv1alpha2.ReasonSnapshotFailed,
+				fmt.Sprintf("The volume snapshot %s no longer exists", shvolsnap.Status.VolumeSnapshotName))
+			return ctrl.Result{}, nil
+		}
+		return r.createVolumeSnapshot(ctx, &shvolsnap, &snapshot)
+	} else if err != nil {
+		log.Error(err, "failed retrieving volume snapshot")
+		return ctrl.Result{}, err
+	}
+
+	state := forge.VolumeSnapshotStatus(&snapshot)
+	shvolsnap.Status.VolumeSnapshotName = snapshot.GetName()
+	shvolsnap.Status.CreationTime = state.CreationTime
+	shvolsnap.Status.RestoreSize = state.RestoreSize
+
+	switch {
+	case state.ReadyToUse:
+		if shvolsnap.Status.Phase != clv1alpha2.SharedVolumeSnapshotPhaseReady {
+			r.EventsRecorder.Eventf(&shvolsnap, v1.EventTypeNormal, EvSnapshotReady, EvSnapshotReadyMsg, shvolsnap.Spec.SharedVolumeRef.Name)
+		}
+		setShVolSnapReadyCondition(&shvolsnap, clv1alpha2.SharedVolumeSnapshotPhaseReady, metav1.ConditionTrue, clv1alpha2.ReasonAvailable,
+			"The snapshot is ready to be restored")
+	case state.Error != "":
+		// Errors may be transient, hence the phase is not set to failed, and the snapshot is retried by the CSI snapshotter.
+		setShVolSnapReadyCondition(&shvolsnap, clv1alpha2.SharedVolumeSnapshotPhaseProcessing, metav1.ConditionFalse, clv1alpha2.ReasonSnapshotFailed, state.Error)
+		r.EventsRecorder.Eventf(&shvolsnap, v1.EventTypeWarning, EvSnapshotError, EvSnapshotErrorMsg, state.Error)
+	default:
+		setShVolSnapReadyCondition(&shvolsnap, clv1alpha2.SharedVolumeSnapshotPhaseProcessing, metav1.ConditionFalse, clv1alpha2.ReasonSnapshotNotReady,
+			"The snapshot is being taken")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// createVolumeSnapshot creates the CSI VolumeSnapshot of the shared volume targeted by the given SharedVolumeSnapshot,
+// once the shared volume is ready.
+func (r *SharedVolumeSnapshotReconciler) createVolumeSnapshot(ctx context.Context, shvolsnap *clv1alpha2.SharedVolumeSnapshot,
+	snapshot *unstructured.Unstructured) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var shvol clv1alpha2.SharedVolume
+	shvolKey := types.NamespacedName{Namespace: shvolsnap.GetNamespace(), Name: shvolsnap.Spec.SharedVolumeRef.Name}
+	if err := r.Get(ctx, shvolKey, &shvol); kerrors.IsNotFound(err) {
+		setShVolSnapReadyCondition(shvolsnap, clv1alpha2.SharedVolumeSnapshotPhaseFailed, metav1.ConditionFalse, clv1alpha2.ReasonSharedVolumeNotFound,
+			fmt.Sprintf("The shared volume %s does not exist", shvolKey.Name))
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		log.Error(err, "failed retrieving shared volume", "sharedvolume", shvolKey)
+		return ctrl.Result{}, err
+	}
+
+	if shvol.Status.Phase != clv1alpha2.SharedVolumePhaseReady {
+		setShVolSnapReadyCondition(shvolsnap, clv1alpha2.SharedVolumeSnapshotPhasePending, metav1.ConditionFalse, clv1alpha2.ReasonSharedVolumeNotReady,
+			fmt.Sprintf("Waiting for the shared volume %s to be ready", shvolKey.Name))
+		return ctrl.Result{}, nil
+	}
+
+	className := shvolsnap.Spec.VolumeSnapshotClassName
+	if className == "" {
+		className = r.VolumeSnapshotClass
+	}
+
+	snapshot.SetLabels(forge.SharedVolumeObjectLabels(snapshot.GetLabels()))
+	if err := unstructured.SetNestedField(snapshot.Object, forge.VolumeSnapshotSpec(forge.SharedVolumePVCName(shvol.GetName()), className), "spec"); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := ctrl.SetControllerReference(shvolsnap, snapshot, r.Scheme()); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.Create(ctx, snapshot); err != nil {
+		log.Error(err, "failed creating volume snapshot")
+		setShVolSnapReadyCondition(shvolsnap, clv1alpha2.SharedVolumeSnapshotPhasePending, metav1.ConditionFalse, clv1alpha2.ReasonEnforcementFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	log.Info("volume snapshot created", "volumesnapshot", snapshot.GetName())
+	r.EventsRecorder.Eventf(shvolsnap, v1.EventTypeNormal, EvSnapshotStarted, EvSnapshotStartedMsg, shvol.GetName())
+
+	shvolsnap.Status.VolumeSnapshotName = snapshot.GetName()
+	setShVolSnapReadyCondition(shvolsnap, clv1alpha2.SharedVolumeSnapshotPhaseProcessing, metav1.ConditionFalse, clv1alpha2.ReasonSnapshotNotReady,
+		"The snapshot is being taken")
+	return ctrl.Result{}, nil
+}
+
+// sharedVolumeToSnapshots returns a reconcile request for each pending SharedVolumeSnapshot of the given shared volume.
+func (r *SharedVolumeSnapshotReconciler) sharedVolumeToSnapshots(ctx context.Context, o client.Object) []reconcile.Request {
+	var snapshots clv1alpha2.SharedVolumeSnapshotList
+	if err := r.List(ctx, &snapshots, client.InNamespace(o.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed listing shared volume snapshots", "sharedvolume", client.ObjectKeyFromObject(o))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range snapshots.Items {
+		snapshot := &snapshots.Items[i]
+		if snapshot.Spec.SharedVolumeRef.Name == o.GetName() && snapshot.Status.VolumeSnapshotName == "" {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(snapshot)})
+		}
+	}
+	return requests
+}
+
+// setShVolSnapReadyCondition configures the phase and the Ready condition of the given SharedVolumeSnapshot.
+func setShVolSnapReadyCondition(shvolsnap *clv1alpha2.SharedVolumeSnapshot, phase clv1alpha2.SharedVolumeSnapshotPhase,
+	status metav1.ConditionStatus, reason clv1alpha2.ConditionReason, message string) {
+	shvolsnap.Status.Phase = phase
+	utils.SetCondition(&shvolsnap.Status.Conditions, shvolsnap, clv1alpha2.ConditionReady, status, reason, message)
+}
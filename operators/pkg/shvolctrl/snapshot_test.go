@@ -0,0 +1,133 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shvolctrl_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+var _ = Describe("The sharedvolume-snapshot-controller Reconcile method", func() {
+	ctx := context.Background()
+
+	const namespace = "test-snapshots"
+
+	var (
+		shvol     clv1alpha2.SharedVolume
+		shvolsnap clv1alpha2.SharedVolumeSnapshot
+		snapshot  unstructured.Unstructured
+	)
+
+	RunReconciler := func() error {
+		_, err := shvolSnapReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&shvolsnap)})
+		if err != nil {
+			return err
+		}
+		return k8sClient.Get(ctx, client.ObjectKeyFromObject(&shvolsnap), &shvolsnap)
+	}
+
+	VolumeSnapshotNamespacedName := func() types.NamespacedName {
+		return types.NamespacedName{Namespace: namespace, Name: forge.SharedVolumeSnapshotVolumeSnapshotName(&shvolsnap)}
+	}
+
+	BeforeEach(func() {
+		ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: whiteListMap}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, &ns))).To(Succeed())
+
+		shvol = clv1alpha2.SharedVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "shvol-" + rand.String(6), Namespace: namespace},
+			Spec:       clv1alpha2.SharedVolumeSpec{PrettyName: "Course material", Size: resource.MustParse("1Gi")},
+		}
+		Expect(k8sClient.Create(ctx, &shvol)).To(Succeed())
+
+		shvolsnap = clv1alpha2.SharedVolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "snap-" + rand.String(6), Namespace: namespace},
+			Spec:       clv1alpha2.SharedVolumeSnapshotSpec{SharedVolumeRef: clv1alpha2.GenericRef{Name: shvol.Name}},
+		}
+		Expect(k8sClient.Create(ctx, &shvolsnap)).To(Succeed())
+
+		snapshot = unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(forge.VolumeSnapshotGVK)
+	})
+
+	When("the shared volume is not ready", func() {
+		It("Should wait, without creating the volume snapshot", func() {
+			Expect(RunReconciler()).To(Succeed())
+			Expect(shvolsnap.Status.Phase).To(Equal(clv1alpha2.SharedVolumeSnapshotPhasePending))
+			Expect(k8sClient.Get(ctx, VolumeSnapshotNamespacedName(), &snapshot)).ToNot(Succeed())
+		})
+	})
+
+	When("the shared volume does not exist", func() {
+		BeforeEach(func() {
+			Expect(k8sClient.Delete(ctx, &shvol)).To(Succeed())
+		})
+
+		It("Should fail", func() {
+			Expect(RunReconciler()).To(Succeed())
+			Expect(shvolsnap.Status.Phase).To(Equal(clv1alpha2.SharedVolumeSnapshotPhaseFailed))
+		})
+	})
+
+	When("the shared volume is ready", func() {
+		BeforeEach(func() {
+			shvol.Status.Phase = clv1alpha2.SharedVolumePhaseReady
+			Expect(k8sClient.Status().Update(ctx, &shvol)).To(Succeed())
+			Expect(RunReconciler()).To(Succeed())
+		})
+
+		It("Should create the volume snapshot of the PVC of the shared volume", func() {
+			Expect(shvolsnap.Status.Phase).To(Equal(clv1alpha2.SharedVolumeSnapshotPhaseProcessing))
+			Expect(shvolsnap.Status.VolumeSnapshotName).To(Equal(VolumeSnapshotNamespacedName().Name))
+
+			Expect(k8sClient.Get(ctx, VolumeSnapshotNamespacedName(), &snapshot)).To(Succeed())
+			Expect(snapshot.Object["spec"]).To(Equal(forge.VolumeSnapshotSpec(forge.SharedVolumePVCName(shvol.Name), "csi-snapclass")))
+			Expect(snapshot.GetOwnerReferences()).To(ContainElement(HaveField("UID", shvolsnap.UID)))
+		})
+
+		When("the volume snapshot is ready to use", func() {
+			BeforeEach(func() {
+				Expect(k8sClient.Get(ctx, VolumeSnapshotNamespacedName(), &snapshot)).To(Succeed())
+				snapshot.Object["status"] = map[string]interface{}{
+					"readyToUse":   true,
+					"creationTime": "2025-01-01T10:00:00Z",
+					"restoreSize":  "1Gi",
+				}
+				Expect(k8sClient.Update(ctx, &snapshot)).To(Succeed())
+				Expect(RunReconciler()).To(Succeed())
+			})
+
+			It("Should report the snapshot as ready", func() {
+				Expect(shvolsnap.Status.Phase).To(Equal(clv1alpha2.SharedVolumeSnapshotPhaseReady))
+				Expect(shvolsnap.Status.RestoreSize).ToNot(BeNil())
+				Expect(shvolsnap.Status.RestoreSize.String()).To(Equal("1Gi"))
+				Expect(shvolsnap.Status.CreationTime).ToNot(BeNil())
+			})
+		})
+	})
+})
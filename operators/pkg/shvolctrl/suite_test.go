@@ -45,9 +45,12 @@ func TestAPIs(t *testing.T) {
 }
 
 var (
-	shvolReconciler shvolctrl.SharedVolumeReconciler
-	k8sClient       client.Client
-	testEnv         = envtest.Environment{
+	shvolReconciler        shvolctrl.SharedVolumeReconciler
+	shvolSnapReconciler    shvolctrl.SharedVolumeSnapshotReconciler
+	shvolRestoreReconciler shvolctrl.SharedVolumeRestoreReconciler
+	shvolBackupReconciler  shvolctrl.SharedVolumeBackupReconciler
+	k8sClient              client.Client
+	testEnv                = envtest.Environment{
 		CRDDirectoryPaths: []string{
 			filepath.Join("..", "..", "deploy", "crds"),
 			filepath.Join("..", "..", "tests", "crds"),
@@ -83,6 +86,29 @@ var _ = BeforeSuite(func() {
 		ReconcileDeferHook: GinkgoRecover,
 		PVCStorageClass:    pvcStorageClass,
 	}
+
+	shvolSnapReconciler = shvolctrl.SharedVolumeSnapshotReconciler{
+		Client:              k8sClient,
+		EventsRecorder:      record.NewFakeRecorder(1024),
+		NamespaceWhitelist:  metav1.LabelSelector{MatchLabels: whiteListMap},
+		ReconcileDeferHook:  GinkgoRecover,
+		VolumeSnapshotClass: "csi-snapclass",
+	}
+
+	shvolRestoreReconciler = shvolctrl.SharedVolumeRestoreReconciler{
+		Client:             k8sClient,
+		EventsRecorder:     record.NewFakeRecorder(1024),
+		NamespaceWhitelist: metav1.LabelSelector{MatchLabels: whiteListMap},
+		ReconcileDeferHook: GinkgoRecover,
+		PVCStorageClass:    pvcStorageClass,
+	}
+
+	shvolBackupReconciler = shvolctrl.SharedVolumeBackupReconciler{
+		Client:             k8sClient,
+		EventsRecorder:     record.NewFakeRecorder(1024),
+		NamespaceWhitelist: metav1.LabelSelector{MatchLabels: whiteListMap},
+		ReconcileDeferHook: GinkgoRecover,
+	}
 })
 
 var _ = AfterSuite(func() {
@@ -0,0 +1,106 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sshca implements the CrownLabs SSH certificate authority, which issues
+// short-lived user certificates granting access to the instances through the bastion.
+package sshca
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// clockSkewTolerance is subtracted from the beginning of the validity of the certificates,
+// to tolerate small clock differences between the authority and the hosts verifying them.
+const clockSkewTolerance = time.Minute
+
+// Authority issues the SSH user certificates, signing them with the key of the certificate authority.
+type Authority struct {
+	signer   ssh.Signer
+	validity time.Duration
+}
+
+// NewAuthority creates a new Authority, given the PEM encoded private key of the certificate
+// authority and the validity of the issued certificates.
+func NewAuthority(privateKey []byte, validity time.Duration) (*Authority, error) {
+	if validity <= 0 {
+		return nil, errors.New("the validity of the certificates must be positive")
+	}
+
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing the private key of the certificate authority: %w", err)
+	}
+
+	return &Authority{signer: signer, validity: validity}, nil
+}
+
+// PublicKey returns the public key of the certificate authority, in authorized_keys format.
+func (a *Authority) PublicKey() string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(a.signer.PublicKey())))
+}
+
+// SignUserKey issues a user certificate for the given public key, valid for the given principals.
+// The certificate permits the allocation of a pty and the port forwarding, which is required to
+// reach the instances through the bastion (further restrictions are enforced by the hosts).
+func (a *Authority) SignUserKey(publicKey ssh.PublicKey, keyID string, principals []string) (*ssh.Certificate, error) {
+	if _, ok := publicKey.(*ssh.Certificate); ok {
+		return nil, errors.New("certificates cannot be signed")
+	}
+	if len(principals) == 0 {
+		return nil, errors.New("at least one principal must be specified")
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	certificate := &ssh.Certificate{
+		Key:             publicKey,
+		Serial:          serial,
+		CertType:        ssh.UserCert,
+		KeyId:           keyID,
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(now.Add(-clockSkewTolerance).Unix()),
+		ValidBefore:     uint64(now.Add(a.validity).Unix()),
+		Permissions: ssh.Permissions{
+			Extensions: map[string]string{
+				"permit-pty":             "",
+				"permit-port-forwarding": "",
+			},
+		},
+	}
+
+	if err := certificate.SignCert(rand.Reader, a.signer); err != nil {
+		return nil, fmt.Errorf("failed signing the certificate: %w", err)
+	}
+	return certificate, nil
+}
+
+// randomSerial generates a random serial number for a certificate.
+func randomSerial() (uint64, error) {
+	var buffer [8]byte
+	if _, err := rand.Read(buffer[:]); err != nil {
+		return 0, fmt.Errorf("failed generating the serial number: %w", err)
+	}
+	return binary.BigEndian.Uint64(buffer[:]), nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshca_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/sshca"
+)
+
+var _ = Describe("The SSH certificate authority", func() {
+	var (
+		authority   *sshca.Authority
+		caPublicKey ssh.PublicKey
+		userKey     ssh.PublicKey
+	)
+
+	BeforeEach(func() {
+		var caPrivateKey []byte
+		caPrivateKey, caPublicKey = generateKey()
+		_, userKey = generateKey()
+
+		var err error
+		authority, err = sshca.NewAuthority(caPrivateKey, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Should reject invalid configurations", func() {
+		_, err := sshca.NewAuthority([]byte("invalid"), time.Hour)
+		Expect(err).To(HaveOccurred())
+
+		caPrivateKey, _ := generateKey()
+		_, err = sshca.NewAuthority(caPrivateKey, 0)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should expose its public key in authorized_keys format", func() {
+		Expect(authority.PublicKey()).To(Equal(strings.TrimSpace(string(ssh.MarshalAuthorizedKey(caPublicKey)))))
+	})
+
+	It("Should issue short-lived certificates, which are accepted for the given principals", func() {
+		certificate, err := authority.SignUserKey(userKey, "tester@tenant-tester/instance", []string{"tenant:tester"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(certificate.CertType).To(BeEquivalentTo(ssh.UserCert))
+		Expect(certificate.KeyId).To(Equal("tester@tenant-tester/instance"))
+		Expect(time.Unix(int64(certificate.ValidBefore), 0)).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+		Expect(certificate.Permissions.Extensions).To(HaveKey("permit-port-forwarding"))
+
+		checker := ssh.CertChecker{IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return string(auth.Marshal()) == string(caPublicKey.Marshal())
+		}}
+		Expect(checker.CheckCert("tenant:tester", certificate)).To(Succeed())
+		Expect(checker.CheckCert("tenant:other", certificate)).ToNot(Succeed())
+	})
+
+	It("Should refuse to sign without principals or a certificate", func() {
+		_, err := authority.SignUserKey(userKey, "tester", nil)
+		Expect(err).To(HaveOccurred())
+
+		certificate, err := authority.SignUserKey(userKey, "tester", []string{"tenant:tester"})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = authority.SignUserKey(certificate, "tester", []string{"tenant:tester"})
+		Expect(err).To(HaveOccurred())
+	})
+})
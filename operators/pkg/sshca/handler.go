@@ -0,0 +1,179 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshca
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/crypto/ssh"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+)
+
+// maxRequestSize is the maximum size of the body of a signing request.
+const maxRequestSize = 16 * 1024
+
+// SignRequest is the body of a request to sign the public key of a tenant.
+type SignRequest struct {
+	// PublicKey is the public key to be signed, in authorized_keys format.
+	PublicKey string `json:"publicKey"`
+	// Instance is the reference to the Instance the certificate shall grant access to.
+	Instance clv1alpha2.GenericRef `json:"instance"`
+}
+
+// SignResponse is the body of the response to a successful signing request.
+type SignResponse struct {
+	// Certificate is the issued certificate, in authorized_keys format.
+	Certificate string `json:"certificate"`
+	// ValidBefore is the instant the certificate expires.
+	ValidBefore time.Time `json:"validBefore"`
+}
+
+// SignHandler issues the certificates to the tenants authenticated through their bearer token.
+// The tenants are authorized to obtain a certificate for a given Instance according to the same
+// rules enforced by the bastion, i.e., in case they own it, or they are managers or assistants
+// of the corresponding workspace.
+type SignHandler struct {
+	Log       logr.Logger
+	Client    client.Client
+	Authority *Authority
+}
+
+// ServeHTTP handles the signing requests.
+func (h *SignHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := h.Log.WithValues("remote-addr", r.RemoteAddr, "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodPost {
+		writeError(w, log, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		writeError(w, log, http.StatusUnauthorized, "Missing bearer token")
+		return
+	}
+
+	var request SignRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize)).Decode(&request); err != nil {
+		log.Error(err, "failed decoding request")
+		writeError(w, log, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if request.Instance.Name == "" || request.Instance.Namespace == "" {
+		writeError(w, log, http.StatusBadRequest, "The instance name and namespace are required")
+		return
+	}
+
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(request.PublicKey))
+	if err != nil {
+		log.Error(err, "failed parsing public key")
+		writeError(w, log, http.StatusBadRequest, "Invalid public key")
+		return
+	}
+
+	user, err := h.authenticate(r, token)
+	if err != nil {
+		log.Error(err, "failed reviewing token")
+		writeError(w, log, http.StatusInternalServerError, "Cannot authenticate the request")
+		return
+	}
+	if user == nil {
+		writeError(w, log, http.StatusUnauthorized, "Invalid bearer token")
+		return
+	}
+	log = log.WithValues("tenant", user.Username, "instance", request.Instance)
+
+	var instance clv1alpha2.Instance
+	if err := h.Client.Get(r.Context(), types.NamespacedName{Name: request.Instance.Name, Namespace: request.Instance.Namespace}, &instance); err != nil {
+		if kerrors.IsNotFound(err) {
+			writeError(w, log, http.StatusNotFound, "The requested instance does not exist")
+			return
+		}
+		log.Error(err, "failed retrieving instance")
+		writeError(w, log, http.StatusInternalServerError, "Cannot retrieve the requested instance")
+		return
+	}
+
+	// The same rules enforced by the bastion apply, hence the tenant shall either own the instance,
+	// or be a manager or an assistant of the corresponding workspace.
+	var tenant clv1alpha2.Tenant
+	if err := h.Client.Get(r.Context(), types.NamespacedName{Name: user.Username}, &tenant); err != nil {
+		if kerrors.IsNotFound(err) {
+			writeError(w, log, http.StatusForbidden, "Access to the requested instance is not allowed")
+			return
+		}
+		log.Error(err, "failed retrieving tenant")
+		writeError(w, log, http.StatusInternalServerError, "Cannot authorize the request")
+		return
+	}
+	if !forge.TenantCanAccessInstance(&tenant, &instance) {
+		writeError(w, log, http.StatusForbidden, "Access to the requested instance is not allowed")
+		return
+	}
+
+	keyID := fmt.Sprintf("%s@%s/%s", user.Username, instance.GetNamespace(), instance.GetName())
+	principals := []string{forge.SSHTenantPrincipal(user.Username), forge.SSHInstancePrincipal(&instance)}
+	certificate, err := h.Authority.SignUserKey(publicKey, keyID, principals)
+	if err != nil {
+		log.Error(err, "failed signing public key")
+		writeError(w, log, http.StatusBadRequest, "Cannot sign the provided public key")
+		return
+	}
+
+	response := SignResponse{
+		Certificate: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(certificate))),
+		ValidBefore: time.Unix(int64(certificate.ValidBefore), 0).UTC(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err, "failed encoding response")
+		return
+	}
+	log.Info("certificate issued", "key-id", keyID, "serial", certificate.Serial, "valid-before", response.ValidBefore)
+}
+
+// authenticate reviews the given bearer token, returning the corresponding user (nil if not authenticated).
+func (h *SignHandler) authenticate(r *http.Request, token string) (*authenticationv1.UserInfo, error) {
+	review := authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := h.Client.Create(r.Context(), &review); err != nil {
+		return nil, err
+	}
+
+	if !review.Status.Authenticated || review.Status.User.Username == "" {
+		return nil, nil
+	}
+	return &review.Status.User, nil
+}
+
+// writeError writes an error response with the given status code and message.
+func writeError(w http.ResponseWriter, log logr.Logger, code int, message string) {
+	log.Info("request rejected", "code", code, "reason", message)
+	w.WriteHeader(code)
+	fmt.Fprint(w, message)
+}
@@ -0,0 +1,170 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshca_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/sshca"
+)
+
+var _ = Describe("The signing handler", func() {
+	const (
+		validToken   = "valid-token"
+		tenantName   = "tester"
+		instanceName = "instance"
+		namespace    = "tenant-tester"
+		workspace    = "netgroup"
+	)
+
+	var (
+		handler  *sshca.SignHandler
+		userKey  ssh.PublicKey
+		request  sshca.SignRequest
+		token    string
+		tenant   clv1alpha2.Tenant
+		instance clv1alpha2.Instance
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		Expect(clv1alpha2.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		_, userKey = generateKey()
+		request = sshca.SignRequest{
+			PublicKey: string(ssh.MarshalAuthorizedKey(userKey)),
+			Instance:  clv1alpha2.GenericRef{Name: instanceName, Namespace: namespace},
+		}
+		token = validToken
+
+		tenant = clv1alpha2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: tenantName}}
+		instance = clv1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: instanceName, Namespace: namespace,
+				Labels: map[string]string{"crownlabs.polito.it/workspace": workspace}},
+			Spec: clv1alpha2.InstanceSpec{Tenant: clv1alpha2.GenericRef{Name: tenantName}},
+		}
+	})
+
+	JustBeforeEach(func() {
+		caPrivateKey, _ := generateKey()
+		authority, err := sshca.NewAuthority(caPrivateKey, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&tenant, &instance).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if review, ok := obj.(*authenticationv1.TokenReview); ok {
+						if review.Spec.Token == validToken {
+							review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: tenantName}}
+						}
+						return nil
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).Build()
+
+		handler = &sshca.SignHandler{Log: logr.Discard(), Client: fakeClient, Authority: authority}
+
+		body, err := json.Marshal(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		req := httptest.NewRequest(http.MethodPost, "/sign", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+	})
+
+	When("the request is valid", func() {
+		It("Should issue a certificate for the tenant and the instance", func() {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var response sshca.SignResponse
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+
+			parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(response.Certificate))
+			Expect(err).ToNot(HaveOccurred())
+			certificate, ok := parsed.(*ssh.Certificate)
+			Expect(ok).To(BeTrue())
+			Expect(certificate.Key.Marshal()).To(Equal(userKey.Marshal()))
+			Expect(certificate.ValidPrincipals).To(ConsistOf("tenant:tester", "instance:tenant-tester/instance"))
+			Expect(response.ValidBefore).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+		})
+	})
+
+	When("the token is not valid", func() {
+		BeforeEach(func() { token = "invalid-token" })
+		It("Should reject the request", func() { Expect(recorder.Code).To(Equal(http.StatusUnauthorized)) })
+	})
+
+	When("the tenant is a manager of the workspace of the instance", func() {
+		BeforeEach(func() {
+			instance.Spec.Tenant.Name = "owner"
+			tenant.Spec.Workspaces = []clv1alpha2.TenantWorkspaceEntry{{Name: workspace, Role: clv1alpha2.Manager}}
+		})
+		It("Should issue the certificate", func() { Expect(recorder.Code).To(Equal(http.StatusOK)) })
+	})
+
+	When("the tenant is a manager of a different workspace", func() {
+		BeforeEach(func() {
+			instance.Spec.Tenant.Name = "owner"
+			tenant.Spec.Workspaces = []clv1alpha2.TenantWorkspaceEntry{{Name: "other", Role: clv1alpha2.Manager}}
+		})
+		It("Should reject the request", func() { Expect(recorder.Code).To(Equal(http.StatusForbidden)) })
+	})
+
+	When("the tenant is a user of the workspace of the instance", func() {
+		BeforeEach(func() {
+			instance.Spec.Tenant.Name = "owner"
+			tenant.Spec.Workspaces = []clv1alpha2.TenantWorkspaceEntry{{Name: workspace, Role: clv1alpha2.User}}
+		})
+		It("Should reject the request", func() { Expect(recorder.Code).To(Equal(http.StatusForbidden)) })
+	})
+
+	When("the tenant does not exist", func() {
+		BeforeEach(func() { tenant.Name = "another" })
+		It("Should reject the request", func() { Expect(recorder.Code).To(Equal(http.StatusForbidden)) })
+	})
+
+	When("the instance does not exist", func() {
+		BeforeEach(func() { request.Instance.Name = "missing" })
+		It("Should reject the request", func() { Expect(recorder.Code).To(Equal(http.StatusNotFound)) })
+	})
+
+	When("the public key is not valid", func() {
+		BeforeEach(func() { request.PublicKey = "invalid" })
+		It("Should reject the request", func() {
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(strings.TrimSpace(recorder.Body.String())).To(Equal("Invalid public key"))
+		})
+	})
+})
@@ -0,0 +1,45 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshca_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHCA(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SSH CA Suite")
+}
+
+// generateKey generates a new ed25519 key pair, returning the PEM encoded private key and the public key.
+func generateKey() ([]byte, ssh.PublicKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	block, err := ssh.MarshalPrivateKey(private, "")
+	Expect(err).ToNot(HaveOccurred())
+
+	publicKey, err := ssh.NewPublicKey(public)
+	Expect(err).ToNot(HaveOccurred())
+
+	return pem.EncodeToMemory(block), publicKey
+}
@@ -0,0 +1,362 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tmplctrl groups the functionalities related to the Template controller.
+package tmplctrl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clv1alpha1 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils"
+)
+
+// TemplateReconciler reconciles the status of Template objects, reporting their usage
+// and whether they are expected to work correctly once instantiated.
+type TemplateReconciler struct {
+	client.Client
+	NamespaceWhitelist metav1.LabelSelector
+
+	// This function, if configured, is deferred at the beginning of the Reconcile.
+	// Specifically, it is meant to be set to GinkgoRecover during the tests,
+	// in order to lead to a controlled failure in case the Reconcile panics.
+	ReconcileDeferHook func()
+}
+
+// SetupWithManager registers a new controller for Template resources.
+func (r *TemplateReconciler) SetupWithManager(mgr ctrl.Manager, concurrency int) error {
+	mgr.GetLogger().Info("setup manager")
+	return ctrl.NewControllerManagedBy(mgr).
+		// The generation changed predicate prevents reconciling the templates as a consequence of their own status updates.
+		For(&clv1alpha2.Template{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Instances are considered only upon creation, deletion, changes of their specifications (e.g. started/stopped)
+		// and of their last activity time, which is propagated to the last used time of the template.
+		Watches(&clv1alpha2.Instance{}, handler.EnqueueRequestsFromMapFunc(instanceToTemplate),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, instanceActivityChanged))).
+		Watches(&clv1alpha2.SharedVolume{}, handler.EnqueueRequestsFromMapFunc(r.sharedVolumeToTemplates)).
+		Watches(&clv1alpha1.ImageList{}, handler.EnqueueRequestsFromMapFunc(r.imageListToTemplates)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: concurrency,
+		}).
+		Named("template-status").
+		WithLogConstructor(utils.LogConstructor(mgr.GetLogger(), "Template")).
+		Complete(r)
+}
+
+// Reconcile reconciles the status of a Template resource.
+func (r *TemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.ReconcileDeferHook != nil {
+		defer r.ReconcileDeferHook()
+	}
+
+	log := ctrl.LoggerFrom(ctx, "template", req.NamespacedName)
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	tracer := trace.New("reconcile", trace.Field{Key: "template", Value: req.NamespacedName})
+	ctx = trace.ContextWithTrace(ctx, tracer)
+	defer tracer.LogIfLong(utils.LongThreshold())
+
+	var template clv1alpha2.Template
+	if err := r.Get(ctx, req.NamespacedName, &template); err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Error(err, "failed retrieving template")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Check the selector label, in order to know whether to perform or not reconciliation.
+	if proceed, err := utils.CheckSelectorLabel(ctx, r.Client, template.GetNamespace(), r.NamespaceWhitelist.MatchLabels); !proceed {
+		// If there was an error while checking, show the error and try again.
+		if err != nil {
+			log.Error(err, "failed checking selector labels")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !template.GetDeletionTimestamp().IsZero() {
+		log.V(utils.LogDebugLevel).Info("template is being deleted, skipping")
+		return ctrl.Result{}, nil
+	}
+
+	original := template.DeepCopy()
+
+	if err := r.inspectInstances(ctx, &template); err != nil {
+		log.Error(err, "failed inspecting the instances referencing the template")
+		return ctrl.Result{}, err
+	}
+	tracer.Step("instances inspected")
+
+	if err := r.inspectEnvironments(ctx, &template); err != nil {
+		log.Error(err, "failed inspecting the template environments")
+		return ctrl.Result{}, err
+	}
+	tracer.Step("environments inspected")
+
+	template.Status.ValidationErrors = ValidateTemplate(&template)
+	setConditions(&template)
+
+	// Avoid triggering the status update if not necessary.
+	if !reflect.DeepEqual(original.Status, template.Status) {
+		if err := r.Status().Patch(ctx, &template, client.MergeFrom(original)); err != nil {
+			log.Error(err, "failed to update the template status")
+			return ctrl.Result{}, err
+		}
+		tracer.Step("template status updated")
+		log.Info("template status correctly updated", "instances", template.Status.TotalInstances,
+			"running", template.Status.RunningInstances, "problems", len(template.Status.ValidationErrors))
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// inspectInstances computes the usage statistics of the template, starting from the instances referencing it.
+func (r *TemplateReconciler) inspectInstances(ctx context.Context, template *clv1alpha2.Template) error {
+	var instances clv1alpha2.InstanceList
+	if err := r.List(ctx, &instances, client.MatchingLabels(forge.TemplateInstancesSelectorLabels(template))); err != nil {
+		return fmt.Errorf("failed listing instances: %w", err)
+	}
+
+	var total, running int32
+	lastUsed := template.Status.LastUsedTime
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if instance.Spec.Template.Name != template.Name || instance.Spec.Template.Namespace != template.Namespace {
+			continue
+		}
+
+		total++
+		lastUsed = latestTime(lastUsed, instance.GetCreationTimestamp())
+		if instance.Spec.Running {
+			running++
+			lastUsed = latestTime(lastUsed, instance.Status.Automation.LastActivityTime)
+		}
+	}
+
+	template.Status.TotalInstances = total
+	template.Status.RunningInstances = running
+	template.Status.LastUsedTime = lastUsed
+	return nil
+}
+
+// inspectEnvironments checks the availability of the images and of the shared volumes referenced by the template environments.
+func (r *TemplateReconciler) inspectEnvironments(ctx context.Context, template *clv1alpha2.Template) error {
+	var imageLists clv1alpha1.ImageListList
+	if err := r.List(ctx, &imageLists); err != nil {
+		return fmt.Errorf("failed listing image lists: %w", err)
+	}
+
+	statuses := make([]clv1alpha2.TemplateEnvironmentStatus, 0, len(template.Spec.EnvironmentList))
+	for i := range template.Spec.EnvironmentList {
+		environment := &template.Spec.EnvironmentList[i]
+		status := clv1alpha2.TemplateEnvironmentStatus{
+			Name:  environment.Name,
+			Image: EnvironmentImageAvailability(environment, imageLists.Items),
+		}
+
+		for j := range environment.SharedVolumeMounts {
+			ref := environment.SharedVolumeMounts[j].SharedVolumeRef
+			shvolStatus := clv1alpha2.TemplateSharedVolumeStatus{SharedVolumeRef: ref}
+
+			var shvol clv1alpha2.SharedVolume
+			err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &shvol)
+			switch {
+			case kerrors.IsNotFound(err):
+			case err != nil:
+				return fmt.Errorf("failed retrieving shared volume %s/%s: %w", ref.Namespace, ref.Name, err)
+			default:
+				shvolStatus.Exists = true
+				shvolStatus.Ready = shvol.Status.Phase == clv1alpha2.SharedVolumePhaseReady
+			}
+			status.SharedVolumes = append(status.SharedVolumes, shvolStatus)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	template.Status.Environments = statuses
+	return nil
+}
+
+// setConditions configures the conditions of the template, depending on the outcome of the inspection.
+func setConditions(template *clv1alpha2.Template) {
+	var notFoundImages, untrackedImages, missingVolumes, unreadyVolumes []string
+	for i := range template.Status.Environments {
+		status := &template.Status.Environments[i]
+		switch status.Image {
+		case clv1alpha2.ImageNotFound:
+			notFoundImages = append(notFoundImages, status.Name)
+		case clv1alpha2.ImageUnknown:
+			untrackedImages = append(untrackedImages, status.Name)
+		}
+
+		for j := range status.SharedVolumes {
+			name := status.SharedVolumes[j].SharedVolumeRef.Namespace + "/" + status.SharedVolumes[j].SharedVolumeRef.Name
+			switch {
+			case !status.SharedVolumes[j].Exists:
+				missingVolumes = append(missingVolumes, name)
+			case !status.SharedVolumes[j].Ready:
+				unreadyVolumes = append(unreadyVolumes, name)
+			}
+		}
+	}
+
+	var notReadyReason clv1alpha2.ConditionReason
+	var notReadyMessage string
+	notReady := func(reason clv1alpha2.ConditionReason, message string) {
+		if notReadyReason == "" {
+			notReadyReason, notReadyMessage = reason, message
+		}
+	}
+
+	if len(template.Status.ValidationErrors) > 0 {
+		message := strings.Join(template.Status.ValidationErrors, "; ")
+		setCondition(template, clv1alpha2.ConditionValidated, metav1.ConditionFalse, clv1alpha2.ReasonValidationFailed, message)
+		notReady(clv1alpha2.ReasonValidationFailed, message)
+	} else {
+		setCondition(template, clv1alpha2.ConditionValidated, metav1.ConditionTrue, clv1alpha2.ReasonSucceeded, "The template specification is valid")
+	}
+
+	switch {
+	case len(notFoundImages) > 0:
+		message := fmt.Sprintf("The image of environments %s is not available", strings.Join(notFoundImages, ", "))
+		setCondition(template, clv1alpha2.ConditionImagesAvailable, metav1.ConditionFalse, clv1alpha2.ReasonImageNotFound, message)
+		notReady(clv1alpha2.ReasonImageNotFound, message)
+	case len(untrackedImages) > 0:
+		setCondition(template, clv1alpha2.ConditionImagesAvailable, metav1.ConditionUnknown, clv1alpha2.ReasonImageNotTracked,
+			fmt.Sprintf("The availability of the image of environments %s cannot be determined", strings.Join(untrackedImages, ", ")))
+	default:
+		setCondition(template, clv1alpha2.ConditionImagesAvailable, metav1.ConditionTrue, clv1alpha2.ReasonSucceeded, "All images are available")
+	}
+
+	switch {
+	case len(missingVolumes) > 0:
+		message := fmt.Sprintf("Shared volumes %s do not exist", strings.Join(missingVolumes, ", "))
+		setCondition(template, clv1alpha2.ConditionSharedVolumesReady, metav1.ConditionFalse, clv1alpha2.ReasonSharedVolumeNotFound, message)
+		notReady(clv1alpha2.ReasonSharedVolumeNotFound, message)
+	case len(unreadyVolumes) > 0:
+		message := fmt.Sprintf("Shared volumes %s are not ready", strings.Join(unreadyVolumes, ", "))
+		setCondition(template, clv1alpha2.ConditionSharedVolumesReady, metav1.ConditionFalse, clv1alpha2.ReasonSharedVolumeNotReady, message)
+		notReady(clv1alpha2.ReasonSharedVolumeNotReady, message)
+	default:
+		setCondition(template, clv1alpha2.ConditionSharedVolumesReady, metav1.ConditionTrue, clv1alpha2.ReasonSucceeded, "All shared volumes are ready")
+	}
+
+	if notReadyReason != "" {
+		setCondition(template, clv1alpha2.ConditionReady, metav1.ConditionFalse, notReadyReason, notReadyMessage)
+	} else {
+		setCondition(template, clv1alpha2.ConditionReady, metav1.ConditionTrue, clv1alpha2.ReasonAvailable, "The template can be instantiated")
+	}
+}
+
+// setCondition configures the given condition in the status of the template.
+func setCondition(template *clv1alpha2.Template, conditionType clv1alpha2.ConditionType,
+	status metav1.ConditionStatus, reason clv1alpha2.ConditionReason, message string) {
+	utils.SetCondition(&template.Status.Conditions, template, conditionType, status, reason, message)
+}
+
+// latestTime returns the most recent between the given times, ignoring the zero one.
+func latestTime(current *metav1.Time, candidate metav1.Time) *metav1.Time {
+	if candidate.IsZero() || (current != nil && !current.Before(&candidate)) {
+		return current
+	}
+	return candidate.DeepCopy()
+}
+
+// instanceActivityChanged is a predicate accepting the Instance updates modifying the last activity time.
+var instanceActivityChanged = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldInstance, oldOk := e.ObjectOld.(*clv1alpha2.Instance)
+		newInstance, newOk := e.ObjectNew.(*clv1alpha2.Instance)
+		return !oldOk || !newOk || !oldInstance.Status.Automation.LastActivityTime.Equal(&newInstance.Status.Automation.LastActivityTime)
+	},
+}
+
+// instanceToTemplate returns a reconcile request for the template referenced by the given instance.
+func instanceToTemplate(_ context.Context, o client.Object) []reconcile.Request {
+	instance, ok := o.(*clv1alpha2.Instance)
+	if !ok {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: instance.Spec.Template.Namespace,
+		Name:      instance.Spec.Template.Name,
+	}}}
+}
+
+// sharedVolumeToTemplates returns a reconcile request for each template mounting the given shared volume.
+func (r *TemplateReconciler) sharedVolumeToTemplates(ctx context.Context, o client.Object) []reconcile.Request {
+	var templates clv1alpha2.TemplateList
+	if err := r.List(ctx, &templates); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed listing templates mounting shared volume", "sharedvolume", client.ObjectKeyFromObject(o))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range templates.Items {
+		if mountsSharedVolume(&templates.Items[i], o) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&templates.Items[i])})
+		}
+	}
+	return requests
+}
+
+// imageListToTemplates returns a reconcile request for each template, since all of them may reference the images of the given list.
+func (r *TemplateReconciler) imageListToTemplates(ctx context.Context, o client.Object) []reconcile.Request {
+	var templates clv1alpha2.TemplateList
+	if err := r.List(ctx, &templates); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed listing templates", "imagelist", o.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(templates.Items))
+	for i := range templates.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&templates.Items[i])})
+	}
+	return requests
+}
+
+// mountsSharedVolume returns whether any of the environments of the given template mounts the given shared volume.
+func mountsSharedVolume(template *clv1alpha2.Template, shvol client.Object) bool {
+	for i := range template.Spec.EnvironmentList {
+		for _, mount := range template.Spec.EnvironmentList[i].SharedVolumeMounts {
+			if mount.SharedVolumeRef.Name == shvol.GetName() && mount.SharedVolumeRef.Namespace == shvol.GetNamespace() {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmplctrl_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clv1alpha1 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/tmplctrl"
+)
+
+var _ = Describe("The Template controller", func() {
+	const (
+		namespace    = "workspace-netgroup"
+		templateName = "ubuntu"
+		shvolName    = "shared-data"
+	)
+
+	var (
+		ctx      context.Context
+		template *clv1alpha2.Template
+		objects  []client.Object
+		cl       client.Client
+		err      error
+	)
+
+	newInstance := func(name string, running bool, created time.Time) *clv1alpha2.Instance {
+		return &clv1alpha2.Instance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "tenant-john-doe",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					"crownlabs.polito.it/workspace": "netgroup",
+					"crownlabs.polito.it/template":  templateName,
+				},
+			},
+			Spec: clv1alpha2.InstanceSpec{
+				Template: clv1alpha2.GenericRef{Name: templateName, Namespace: namespace},
+				Running:  running,
+			},
+		}
+	}
+
+	condition := func(conditionType clv1alpha2.ConditionType) *metav1.Condition {
+		return meta.FindStatusCondition(template.Status.Conditions, string(conditionType))
+	}
+
+	BeforeEach(func() {
+		ctx = ctrl.LoggerInto(context.Background(), logr.Discard())
+		template = &clv1alpha2.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: templateName, Namespace: namespace},
+			Spec: clv1alpha2.TemplateSpec{
+				WorkspaceRef: clv1alpha2.GenericRef{Name: "netgroup"},
+				EnvironmentList: []clv1alpha2.Environment{{
+					Name:            "app",
+					EnvironmentType: clv1alpha2.ClassContainer,
+					Image:           "registry.crownlabs.polito.it/netgroup/app:v1",
+					Resources:       clv1alpha2.EnvironmentResources{CPU: 1, ReservedCPUPercentage: 50},
+					SharedVolumeMounts: []clv1alpha2.SharedVolumeMountInfo{{
+						SharedVolumeRef: clv1alpha2.GenericRef{Name: shvolName, Namespace: namespace},
+						MountPath:       "/mnt/data",
+					}},
+				}},
+				DeleteAfter: "never",
+			},
+		}
+		objects = []client.Object{
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}},
+			&clv1alpha1.ImageList{
+				ObjectMeta: metav1.ObjectMeta{Name: "images"},
+				Spec: clv1alpha1.ImageListSpec{
+					RegistryName: "registry.crownlabs.polito.it",
+					Images:       []clv1alpha1.ImageListItem{{Name: "netgroup/app", Versions: []string{"v1"}}},
+				},
+			},
+			&clv1alpha2.SharedVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: shvolName, Namespace: namespace},
+				Status:     clv1alpha2.SharedVolumeStatus{Phase: clv1alpha2.SharedVolumePhaseReady},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(clv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(clv1alpha2.AddToScheme(scheme)).To(Succeed())
+
+		objects = append(objects, template)
+		cl = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(template).Build()
+
+		reconciler := tmplctrl.TemplateReconciler{Client: cl, ReconcileDeferHook: GinkgoRecover}
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(template)})
+
+		template = &clv1alpha2.Template{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: templateName, Namespace: namespace}, template)).To(Succeed())
+	})
+
+	When("the template is valid and all its dependencies are available", func() {
+		It("Should not return an error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Should report the template as ready", func() {
+			Expect(template.Status.ValidationErrors).To(BeEmpty())
+			Expect(template.Status.Environments).To(ConsistOf(clv1alpha2.TemplateEnvironmentStatus{
+				Name:  "app",
+				Image: clv1alpha2.ImageAvailable,
+				SharedVolumes: []clv1alpha2.TemplateSharedVolumeStatus{{
+					SharedVolumeRef: clv1alpha2.GenericRef{Name: shvolName, Namespace: namespace},
+					Exists:          true,
+					Ready:           true,
+				}},
+			}))
+			Expect(condition(clv1alpha2.ConditionValidated).Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition(clv1alpha2.ConditionImagesAvailable).Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition(clv1alpha2.ConditionSharedVolumesReady).Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition(clv1alpha2.ConditionReady).Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition(clv1alpha2.ConditionReady).Reason).To(BeEquivalentTo(clv1alpha2.ReasonAvailable))
+		})
+
+		It("Should report no instances", func() {
+			Expect(template.Status.TotalInstances).To(BeZero())
+			Expect(template.Status.RunningInstances).To(BeZero())
+			Expect(template.Status.LastUsedTime).To(BeNil())
+		})
+	})
+
+	When("some instances refer to the template", func() {
+		var lastActivity time.Time
+
+		BeforeEach(func() {
+			lastActivity = time.Now().Add(-time.Minute).Truncate(time.Second)
+			running := newInstance("running", true, time.Now().Add(-2*time.Hour))
+			running.Status.Automation.LastActivityTime = metav1.NewTime(lastActivity)
+			other := newInstance("other-template", true, time.Now())
+			other.Spec.Template.Name = "another"
+
+			objects = append(objects,
+				running,
+				newInstance("stopped", false, time.Now().Add(-time.Hour)),
+				other,
+			)
+		})
+
+		It("Should report the number of instances", func() {
+			Expect(template.Status.TotalInstances).To(BeEquivalentTo(2))
+			Expect(template.Status.RunningInstances).To(BeEquivalentTo(1))
+		})
+
+		It("Should report the last used time", func() {
+			Expect(template.Status.LastUsedTime).ToNot(BeNil())
+			Expect(template.Status.LastUsedTime.Time).To(BeTemporally("==", lastActivity))
+		})
+	})
+
+	When("the image is not listed in the ImageLists", func() {
+		BeforeEach(func() {
+			template.Spec.EnvironmentList[0].Image = "registry.crownlabs.polito.it/netgroup/app:v2"
+		})
+
+		It("Should report the template as not ready", func() {
+			Expect(template.Status.Environments[0].Image).To(Equal(clv1alpha2.ImageNotFound))
+			Expect(condition(clv1alpha2.ConditionImagesAvailable).Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition(clv1alpha2.ConditionReady).Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition(clv1alpha2.ConditionReady).Reason).To(BeEquivalentTo(clv1alpha2.ReasonImageNotFound))
+		})
+	})
+
+	When("the shared volume does not exist", func() {
+		BeforeEach(func() {
+			template.Spec.EnvironmentList[0].SharedVolumeMounts[0].SharedVolumeRef.Name = "missing"
+		})
+
+		It("Should report the template as not ready", func() {
+			Expect(template.Status.Environments[0].SharedVolumes[0].Exists).To(BeFalse())
+			Expect(condition(clv1alpha2.ConditionSharedVolumesReady).Reason).To(BeEquivalentTo(clv1alpha2.ReasonSharedVolumeNotFound))
+			Expect(condition(clv1alpha2.ConditionReady).Status).To(Equal(metav1.ConditionFalse))
+		})
+	})
+
+	When("the template specification is not valid", func() {
+		BeforeEach(func() {
+			template.Spec.EnvironmentList[0].SharedVolumeMounts[0].MountPath = "data"
+		})
+
+		It("Should report the validation errors", func() {
+			Expect(template.Status.ValidationErrors).To(HaveLen(1))
+			Expect(condition(clv1alpha2.ConditionValidated).Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition(clv1alpha2.ConditionReady).Reason).To(BeEquivalentTo(clv1alpha2.ReasonValidationFailed))
+		})
+	})
+})
@@ -0,0 +1,69 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmplctrl
+
+import (
+	"slices"
+	"strings"
+
+	clv1alpha1 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+const defaultImageTag = "latest"
+
+// EnvironmentImageAvailability checks whether the image of the given environment is listed in any of the given ImageLists.
+// Images qualified by a registry host match only the entries of the ImageLists of that registry, while unqualified
+// ones are compared with the bare repository names. The availability of CloudVM images, which are downloaded
+// from HTTP URLs, is unknown.
+func EnvironmentImageAvailability(environment *clv1alpha2.Environment, imageLists []clv1alpha1.ImageList) clv1alpha2.ImageAvailability {
+	if environment.EnvironmentType == clv1alpha2.ClassCloudVM || len(imageLists) == 0 {
+		return clv1alpha2.ImageUnknown
+	}
+
+	repository, tag := parseImage(environment.Image)
+	for i := range imageLists {
+		registry := strings.Trim(imageLists[i].Spec.RegistryName, "/")
+		for j := range imageLists[i].Spec.Images {
+			item := &imageLists[i].Spec.Images[j]
+			matches := repository == registry+"/"+item.Name || (!hasRegistryHost(repository) && repository == item.Name)
+			if matches && slices.Contains(item.Versions, tag) {
+				return clv1alpha2.ImageAvailable
+			}
+		}
+	}
+
+	return clv1alpha2.ImageNotFound
+}
+
+// parseImage splits the given image reference into the repository (including the registry host, if any) and the tag.
+func parseImage(image string) (repository, tag string) {
+	// Digests are not tracked by the ImageLists, hence they are dropped.
+	image, _, _ = strings.Cut(image, "@")
+
+	tag = defaultImageTag
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		image, tag = image[:idx], image[idx+1:]
+	}
+
+	return image, tag
+}
+
+// hasRegistryHost returns whether the given repository is qualified by a registry host,
+// i.e., its first component contains a dot or a port, or it is localhost.
+func hasRegistryHost(repository string) bool {
+	host, _, found := strings.Cut(repository, "/")
+	return found && (strings.ContainsAny(host, ".:") || host == "localhost")
+}
@@ -0,0 +1,65 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmplctrl_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clv1alpha1 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha1"
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/tmplctrl"
+)
+
+var _ = Describe("Image availability", func() {
+	imageLists := []clv1alpha1.ImageList{{
+		ObjectMeta: metav1.ObjectMeta{Name: "crownlabs-container-envs"},
+		Spec: clv1alpha1.ImageListSpec{
+			RegistryName: "registry.internal.crownlabs.polito.it",
+			Images: []clv1alpha1.ImageListItem{
+				{Name: "crownlabs/pycharm", Versions: []string{"v1.0", "latest"}},
+				{Name: "netgroup/vscode", Versions: []string{"v2.3"}},
+			},
+		},
+	}}
+
+	DescribeTable("Should correctly determine whether the image is available",
+		func(environmentType clv1alpha2.EnvironmentType, image string, lists []clv1alpha1.ImageList, expected clv1alpha2.ImageAvailability) {
+			environment := clv1alpha2.Environment{EnvironmentType: environmentType, Image: image}
+			Expect(tmplctrl.EnvironmentImageAvailability(&environment, lists)).To(Equal(expected))
+		},
+		Entry("When the image is listed with the registry host", clv1alpha2.ClassContainer,
+			"registry.internal.crownlabs.polito.it/crownlabs/pycharm:v1.0", imageLists, clv1alpha2.ImageAvailable),
+		Entry("When the image is listed without the registry host", clv1alpha2.ClassStandalone,
+			"netgroup/vscode:v2.3", imageLists, clv1alpha2.ImageAvailable),
+		Entry("When the image has no tag and the latest one is listed", clv1alpha2.ClassContainer,
+			"crownlabs/pycharm", imageLists, clv1alpha2.ImageAvailable),
+		Entry("When the image is referenced by digest", clv1alpha2.ClassContainer,
+			"netgroup/vscode:v2.3@sha256:0123456789abcdef", imageLists, clv1alpha2.ImageAvailable),
+		Entry("When the image is hosted by a different registry", clv1alpha2.ClassContainer,
+			"docker.io/crownlabs/pycharm:v1.0", imageLists, clv1alpha2.ImageNotFound),
+		Entry("When the image is hosted by a registry exposed on a custom port", clv1alpha2.ClassContainer,
+			"registry.internal.crownlabs.polito.it:5000/crownlabs/pycharm:v1.0", imageLists, clv1alpha2.ImageNotFound),
+		Entry("When the image tag is not listed", clv1alpha2.ClassContainer,
+			"netgroup/vscode:v9.9", imageLists, clv1alpha2.ImageNotFound),
+		Entry("When the image repository is not listed", clv1alpha2.ClassVM,
+			"crownlabs/ubuntu:22.04", imageLists, clv1alpha2.ImageNotFound),
+		Entry("When the environment is a CloudVM", clv1alpha2.ClassCloudVM,
+			"https://cloud-images.ubuntu.com/jammy.img", imageLists, clv1alpha2.ImageUnknown),
+		Entry("When no ImageList exists", clv1alpha2.ClassContainer,
+			"netgroup/vscode:v2.3", nil, clv1alpha2.ImageUnknown),
+	)
+})
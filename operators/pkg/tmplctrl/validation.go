@@ -0,0 +1,154 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmplctrl
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/forge"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/cron"
+)
+
+// ValidateTemplate checks the specification of the given template, and returns the list of the
+// detected problems which would prevent the corresponding instances from working correctly.
+func ValidateTemplate(template *clv1alpha2.Template) []string {
+	var problems []string
+
+	if len(template.Spec.EnvironmentList) == 0 {
+		problems = append(problems, "the template does not define any environment")
+	}
+
+	names := make(map[string]bool, len(template.Spec.EnvironmentList))
+	for i := range template.Spec.EnvironmentList {
+		environment := &template.Spec.EnvironmentList[i]
+		if names[environment.Name] {
+			problems = append(problems, fmt.Sprintf("environment name %q is duplicated", environment.Name))
+		}
+		names[environment.Name] = true
+
+		problems = append(problems, validateEnvironment(environment)...)
+	}
+
+	problems = append(problems, validateLifetime(template)...)
+	problems = append(problems, validateSchedule(template.Spec.Schedule)...)
+
+	return problems
+}
+
+// validateEnvironment checks the specification of the given environment.
+func validateEnvironment(environment *clv1alpha2.Environment) []string {
+	var problems []string
+
+	switch environment.EnvironmentType {
+	case clv1alpha2.ClassContainer, clv1alpha2.ClassStandalone:
+		if environment.Persistent && environment.StorageClassName == "" {
+			problems = append(problems, fmt.Sprintf("persistent %s environment %q does not specify a storage class",
+				environment.EnvironmentType, environment.Name))
+		}
+		if environment.Persistent && environment.Resources.Disk.IsZero() {
+			problems = append(problems, fmt.Sprintf("persistent %s environment %q does not specify the disk size",
+				environment.EnvironmentType, environment.Name))
+		}
+	case clv1alpha2.ClassCloudVM:
+		if !strings.HasPrefix(environment.Image, "http://") && !strings.HasPrefix(environment.Image, "https://") {
+			problems = append(problems, fmt.Sprintf("CloudVM environment %q does not refer to an HTTP(S) image URL", environment.Name))
+		}
+	}
+
+	if environment.Resources.CPU == 0 {
+		problems = append(problems, fmt.Sprintf("environment %q does not request any CPU core", environment.Name))
+	}
+	if environment.Resources.ReservedCPUPercentage == 0 || environment.Resources.ReservedCPUPercentage > 100 {
+		problems = append(problems, fmt.Sprintf("environment %q specifies an invalid reserved CPU percentage (%d), which should range between 1 and 100",
+			environment.Name, environment.Resources.ReservedCPUPercentage))
+	}
+
+	mountPaths := make([]string, 0, len(environment.SharedVolumeMounts))
+	for i := range environment.SharedVolumeMounts {
+		mountPath := environment.SharedVolumeMounts[i].MountPath
+		if !path.IsAbs(mountPath) {
+			problems = append(problems, fmt.Sprintf("environment %q mounts a shared volume on the relative path %q", environment.Name, mountPath))
+			continue
+		}
+
+		cleaned := path.Clean(mountPath)
+		for _, other := range mountPaths {
+			if pathsOverlap(cleaned, other) {
+				problems = append(problems, fmt.Sprintf("environment %q mounts multiple shared volumes on overlapping paths %q and %q", environment.Name, other, cleaned))
+			}
+		}
+		mountPaths = append(mountPaths, cleaned)
+	}
+
+	return problems
+}
+
+// pathsOverlap returns whether the given (absolute and cleaned) paths are equal, or one is nested in the other.
+func pathsOverlap(a, b string) bool {
+	within := func(inner, outer string) bool {
+		return inner == outer || strings.HasPrefix(inner, strings.TrimSuffix(outer, "/")+"/")
+	}
+	return within(a, b) || within(b, a)
+}
+
+// validateLifetime checks that the default lifetime of the instances does not exceed the maximum one.
+func validateLifetime(template *clv1alpha2.Template) []string {
+	lifetime, limited, err := forge.ParseLifetime(template.Spec.DeleteAfter)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid deleteAfter: %v", err)}
+	}
+
+	if template.Spec.MaxDeleteAfter == "" {
+		return nil
+	}
+
+	maxLifetime, maxLimited, err := forge.ParseLifetime(template.Spec.MaxDeleteAfter)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid maxDeleteAfter: %v", err)}
+	}
+
+	if maxLimited && (!limited || lifetime > maxLifetime) {
+		return []string{fmt.Sprintf("deleteAfter (%s) exceeds maxDeleteAfter (%s)", template.Spec.DeleteAfter, template.Spec.MaxDeleteAfter)}
+	}
+	return nil
+}
+
+// validateSchedule checks the cron expressions and the time zone of the given schedule.
+func validateSchedule(schedule *clv1alpha2.InstanceSchedule) []string {
+	if schedule == nil {
+		return nil
+	}
+
+	var problems []string
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid schedule timezone %q", schedule.Timezone))
+	}
+
+	for i := range schedule.Windows {
+		for _, expr := range []string{schedule.Windows[i].Start, schedule.Windows[i].Stop} {
+			if expr == "" {
+				continue
+			}
+			if _, err := cron.Parse(expr); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid schedule expression %q: %v", expr, err))
+			}
+		}
+	}
+	return problems
+}
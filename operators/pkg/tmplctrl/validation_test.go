@@ -0,0 +1,109 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmplctrl_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/tmplctrl"
+)
+
+var _ = Describe("Template validation", func() {
+	var template clv1alpha2.Template
+
+	BeforeEach(func() {
+		template = clv1alpha2.Template{
+			Spec: clv1alpha2.TemplateSpec{
+				EnvironmentList: []clv1alpha2.Environment{{
+					Name:            "app",
+					EnvironmentType: clv1alpha2.ClassContainer,
+					Image:           "registry.example.com/app:v1",
+					Resources:       clv1alpha2.EnvironmentResources{CPU: 1, ReservedCPUPercentage: 50},
+				}},
+				DeleteAfter:    "7d",
+				MaxDeleteAfter: "30d",
+			},
+		}
+	})
+
+	It("Should not report any problem for a valid template", func() {
+		Expect(tmplctrl.ValidateTemplate(&template)).To(BeEmpty())
+	})
+
+	It("Should not report any problem for shared volumes mounted on sibling paths", func() {
+		template.Spec.EnvironmentList[0].SharedVolumeMounts = []clv1alpha2.SharedVolumeMountInfo{{MountPath: "/data"}, {MountPath: "/database"}}
+		Expect(tmplctrl.ValidateTemplate(&template)).To(BeEmpty())
+	})
+
+	DescribeTable("Should report the problems of an invalid template",
+		func(mutate func(*clv1alpha2.Template), expected string) {
+			mutate(&template)
+			Expect(tmplctrl.ValidateTemplate(&template)).To(ContainElement(ContainSubstring(expected)))
+		},
+		Entry("When no environments are defined", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList = nil
+		}, "does not define any environment"),
+		Entry("When environment names are duplicated", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList = append(t.Spec.EnvironmentList, t.Spec.EnvironmentList[0])
+		}, `environment name "app" is duplicated`),
+		Entry("When a persistent container does not specify the storage class", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList[0].Persistent = true
+			t.Spec.EnvironmentList[0].Resources.Disk = resource.MustParse("10Gi")
+		}, "does not specify a storage class"),
+		Entry("When a persistent container does not specify the disk size", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList[0].Persistent = true
+			t.Spec.EnvironmentList[0].StorageClassName = "rook-ceph-block"
+		}, "does not specify the disk size"),
+		Entry("When a CloudVM does not refer to an HTTP URL", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList[0].EnvironmentType = clv1alpha2.ClassCloudVM
+		}, "HTTP(S) image URL"),
+		Entry("When a shared volume is mounted on a relative path", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList[0].SharedVolumeMounts = []clv1alpha2.SharedVolumeMountInfo{{MountPath: "data"}}
+		}, "relative path"),
+		Entry("When multiple shared volumes are mounted on the same path", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList[0].SharedVolumeMounts = []clv1alpha2.SharedVolumeMountInfo{{MountPath: "/data"}, {MountPath: "/data/"}}
+		}, "multiple shared volumes"),
+		Entry("When multiple shared volumes are mounted on nested paths", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList[0].SharedVolumeMounts = []clv1alpha2.SharedVolumeMountInfo{{MountPath: "/data/inner"}, {MountPath: "/data"}}
+		}, `overlapping paths "/data/inner" and "/data"`),
+		Entry("When the environment does not request any CPU", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList[0].Resources.CPU = 0
+		}, "does not request any CPU core"),
+		Entry("When the reserved CPU percentage exceeds 100", func(t *clv1alpha2.Template) {
+			t.Spec.EnvironmentList[0].Resources.ReservedCPUPercentage = 150
+		}, "invalid reserved CPU percentage (150)"),
+		Entry("When deleteAfter is not valid", func(t *clv1alpha2.Template) {
+			t.Spec.DeleteAfter = "tomorrow"
+		}, "invalid deleteAfter"),
+		Entry("When deleteAfter overflows", func(t *clv1alpha2.Template) {
+			t.Spec.DeleteAfter = "200000d"
+		}, "invalid deleteAfter"),
+		Entry("When deleteAfter exceeds maxDeleteAfter", func(t *clv1alpha2.Template) {
+			t.Spec.DeleteAfter = "60d"
+		}, "exceeds maxDeleteAfter"),
+		Entry("When deleteAfter is never but maxDeleteAfter is limited", func(t *clv1alpha2.Template) {
+			t.Spec.DeleteAfter = "never"
+		}, "exceeds maxDeleteAfter"),
+		Entry("When the schedule timezone is invalid", func(t *clv1alpha2.Template) {
+			t.Spec.Schedule = &clv1alpha2.InstanceSchedule{Timezone: "Mars/Olympus"}
+		}, "invalid schedule timezone"),
+		Entry("When a schedule expression is invalid", func(t *clv1alpha2.Template) {
+			t.Spec.Schedule = &clv1alpha2.InstanceSchedule{Windows: []clv1alpha2.InstanceScheduleWindow{{Start: "0 25 * * *"}}}
+		}, "invalid schedule expression"),
+	)
+})
@@ -0,0 +1,43 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clv1alpha2 "github.com/netgroup-polito/CrownLabs/operators/api/v1alpha2"
+)
+
+// SetCondition configures the given condition in the list, recording the generation of the object
+// it refers to. The transition time is updated only in case the status of the condition changes.
+func SetCondition(conditions *[]metav1.Condition, obj metav1.Object, conditionType clv1alpha2.ConditionType,
+	status metav1.ConditionStatus, reason clv1alpha2.ConditionReason, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               string(conditionType),
+		Status:             status,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             string(reason),
+		Message:            message,
+	})
+}
+
+// ConditionStatusFromBool converts a boolean value into the corresponding condition status.
+func ConditionStatusFromBool(value bool) metav1.ConditionStatus {
+	if value {
+		return metav1.ConditionTrue
+	}
+	return metav1.ConditionFalse
+}
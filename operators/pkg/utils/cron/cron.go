@@ -0,0 +1,278 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimitYears -> the maximum number of years in the future (past) to look for the next (previous) activation time.
+const searchLimitYears = 5
+
+// field describes the characteristics of one of the fields composing a cron expression.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minutes = field{name: "minute", min: 0, max: 59}
+	hours   = field{name: "hour", min: 0, max: 23}
+	days    = field{name: "day of month", min: 1, max: 31}
+	months  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Both 0 and 7 identify Sunday, as in most cron implementations.
+	weekdays = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	// macros maps the supported shorthands to the corresponding expressions.
+	macros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// Schedule represents a parsed cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// Whether the day of month and day of week fields are unrestricted (i.e. "*"),
+	// as they are combined in OR when both restricted, and in AND otherwise.
+	domStar, dowStar bool
+}
+
+// Parse parses a standard cron expression (minute, hour, day of month, month and day of week),
+// supporting lists, ranges, steps, month and weekday names, as well as the common @-prefixed macros.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, found := macros[strings.ToLower(expr)]; found {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, found %d", expr, len(fields))
+	}
+
+	var schedule Schedule
+	var err error
+	if schedule.minute, err = parseField(fields[0], minutes); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseField(fields[1], hours); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = parseField(fields[2], days); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseField(fields[3], months); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = parseField(fields[4], weekdays); err != nil {
+		return nil, err
+	}
+
+	// Normalize Sunday, which can be expressed as either 0 or 7.
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow = (schedule.dow | 1) &^ (1 << 7)
+	}
+
+	schedule.domStar = strings.HasPrefix(fields[2], "*")
+	schedule.dowStar = strings.HasPrefix(fields[4], "*")
+	return &schedule, nil
+}
+
+// Next returns the first activation time strictly after the given one, evaluated in its location.
+// The zero time is returned in case no activation time exists in the following years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	start := t.Truncate(time.Minute).Add(time.Minute)
+
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	limit := day.AddDate(searchLimitYears, 0, 0)
+
+	for ; day.Before(limit); day = day.AddDate(0, 0, 1) {
+		if !s.matchesDay(day) {
+			continue
+		}
+
+		for hour := hours.min; hour <= hours.max; hour++ {
+			if !has(s.hour, hour) {
+				continue
+			}
+			for minute := minutes.min; minute <= minutes.max; minute++ {
+				if !has(s.minute, minute) {
+					continue
+				}
+
+				candidate := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+				// Skip the local times which do not exist (e.g. because of daylight saving time transitions).
+				if candidate.Hour() != hour || candidate.Minute() != minute {
+					continue
+				}
+				if !candidate.Before(start) {
+					return candidate
+				}
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// Prev returns the last activation time strictly before the given one, evaluated in its location.
+// The zero time is returned in case no activation time exists in the previous years.
+func (s *Schedule) Prev(t time.Time) time.Time {
+	loc := t.Location()
+	// Activation times are aligned to the minute, hence the ones strictly before t are not after end.
+	end := t.Add(-time.Nanosecond).Truncate(time.Minute)
+
+	day := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+	limit := day.AddDate(-searchLimitYears, 0, 0)
+
+	for ; day.After(limit); day = day.AddDate(0, 0, -1) {
+		if !s.matchesDay(day) {
+			continue
+		}
+
+		for hour := hours.max; hour >= hours.min; hour-- {
+			if !has(s.hour, hour) {
+				continue
+			}
+			for minute := minutes.max; minute >= minutes.min; minute-- {
+				if !has(s.minute, minute) {
+					continue
+				}
+
+				candidate := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+				// Skip the local times which do not exist (e.g. because of daylight saving time transitions).
+				if candidate.Hour() != hour || candidate.Minute() != minute {
+					continue
+				}
+				if !candidate.After(end) {
+					return candidate
+				}
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay returns whether the given day matches the day of month, month and day of week fields.
+func (s *Schedule) matchesDay(day time.Time) bool {
+	if !has(s.month, int(day.Month())) {
+		return false
+	}
+
+	domMatch := has(s.dom, day.Day())
+	dowMatch := has(s.dow, int(day.Weekday()))
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// has returns whether the given value is part of the bitset.
+func has(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
+
+// parseField parses a comma-separated list of values, ranges and steps into the corresponding bitset.
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		itemBits, err := parseItem(item, f)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %v field %q: %w", f.name, expr, err)
+		}
+		bits |= itemBits
+	}
+	return bits, nil
+}
+
+// parseItem parses a single item of a cron field (e.g. "*", "5", "1-5", "*/15", "mon-fri/2").
+func parseItem(item string, f field) (uint64, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(item, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q", stepExpr)
+		}
+	}
+
+	var low, high int
+	switch lowExpr, highExpr, isRange := strings.Cut(rangeExpr, "-"); {
+	case rangeExpr == "*":
+		low, high = f.min, f.max
+	case isRange:
+		var err error
+		if low, err = parseValue(lowExpr, f); err != nil {
+			return 0, err
+		}
+		if high, err = parseValue(highExpr, f); err != nil {
+			return 0, err
+		}
+		if low > high {
+			return 0, fmt.Errorf("invalid range %q", rangeExpr)
+		}
+	default:
+		var err error
+		if low, err = parseValue(rangeExpr, f); err != nil {
+			return 0, err
+		}
+		high = low
+		// A single value followed by a step corresponds to the range up to the maximum.
+		if hasStep {
+			high = f.max
+		}
+	}
+
+	var bits uint64
+	for value := low; value <= high; value += step {
+		bits |= 1 << uint(value)
+	}
+	return bits, nil
+}
+
+// parseValue parses a single numeric (or named) value, checking it is within the allowed bounds.
+func parseValue(expr string, f field) (int, error) {
+	if value, found := f.names[strings.ToLower(expr)]; found {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", expr)
+	}
+	if value < f.min || value > f.max {
+		return 0, fmt.Errorf("value %d out of bounds [%d, %d]", value, f.min, f.max)
+	}
+	return value, nil
+}
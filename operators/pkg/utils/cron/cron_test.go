@@ -0,0 +1,164 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/cron"
+)
+
+var _ = Describe("Cron expressions", func() {
+
+	Describe("The cron.Parse function", func() {
+		DescribeTable("Correctly accepts the valid expressions",
+			func(expr string) {
+				Expect(cron.Parse(expr)).ToNot(BeNil())
+			},
+			Entry("When all fields are unrestricted", "* * * * *"),
+			Entry("When fields contain single values", "30 8 1 9 1"),
+			Entry("When fields contain lists", "0,30 8,14 * * *"),
+			Entry("When fields contain ranges", "0 8-18 * * 1-5"),
+			Entry("When fields contain steps", "*/15 8-18/2 * * *"),
+			Entry("When fields contain names", "0 8 * feb-jun MON-FRI"),
+			Entry("When Sunday is expressed as 7", "0 8 * * 7"),
+			Entry("When a macro is used", "@daily"),
+		)
+
+		DescribeTable("Correctly rejects the invalid expressions",
+			func(expr string) {
+				_, err := cron.Parse(expr)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("When the expression is empty", ""),
+			Entry("When fields are missing", "0 8 * *"),
+			Entry("When fields are in excess", "0 0 8 * * *"),
+			Entry("When a value is out of bounds", "60 8 * * *"),
+			Entry("When a range is reversed", "0 18-8 * * *"),
+			Entry("When a step is invalid", "*/0 * * * *"),
+			Entry("When a name is unknown", "0 8 * * foo"),
+		)
+	})
+
+	Describe("The cron.Schedule.Next function", func() {
+		var rome *time.Location
+
+		BeforeEach(func() {
+			var err error
+			rome, err = time.LoadLocation("Europe/Rome")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		type NextCase struct {
+			Expr     string
+			From     func() time.Time
+			Expected func() time.Time
+		}
+
+		DescribeTable("Correctly returns the next activation time",
+			func(c NextCase) {
+				schedule, err := cron.Parse(c.Expr)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(schedule.Next(c.From())).To(BeTemporally("==", c.Expected()))
+			},
+			Entry("When the activation time is later the same day", NextCase{
+				Expr:     "30 8 * * *",
+				From:     func() time.Time { return time.Date(2025, 3, 10, 7, 0, 0, 0, time.UTC) },
+				Expected: func() time.Time { return time.Date(2025, 3, 10, 8, 30, 0, 0, time.UTC) },
+			}),
+			Entry("When the activation time coincides with the given one", NextCase{
+				Expr:     "30 8 * * *",
+				From:     func() time.Time { return time.Date(2025, 3, 10, 8, 30, 0, 0, time.UTC) },
+				Expected: func() time.Time { return time.Date(2025, 3, 11, 8, 30, 0, 0, time.UTC) },
+			}),
+			Entry("When the activation time is restricted to working days", NextCase{
+				Expr:     "0 8 * * mon-fri",
+				From:     func() time.Time { return time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC) }, // Friday
+				Expected: func() time.Time { return time.Date(2025, 3, 17, 8, 0, 0, 0, time.UTC) },
+			}),
+			Entry("When both day of month and day of week are restricted", NextCase{
+				Expr:     "0 0 20 * 1",
+				From:     func() time.Time { return time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC) }, // Friday
+				Expected: func() time.Time { return time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC) },
+			}),
+			Entry("When the activation time is in the following year", NextCase{
+				Expr:     "0 0 1 1 *",
+				From:     func() time.Time { return time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC) },
+				Expected: func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) },
+			}),
+			Entry("When the activation time is evaluated in a different time zone", NextCase{
+				Expr:     "0 8 * * *",
+				From:     func() time.Time { return time.Date(2025, 3, 10, 7, 30, 0, 0, rome) },
+				Expected: func() time.Time { return time.Date(2025, 3, 10, 7, 0, 0, 0, time.UTC) },
+			}),
+			Entry("When the activation time does not exist because of daylight saving time", NextCase{
+				Expr:     "30 2 * * *",
+				From:     func() time.Time { return time.Date(2025, 3, 30, 0, 0, 0, 0, rome) },
+				Expected: func() time.Time { return time.Date(2025, 3, 31, 2, 30, 0, 0, rome) },
+			}),
+		)
+
+		It("Should return the zero time when no activation time exists", func() {
+			schedule, err := cron.Parse("0 0 31 2 *")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule.Next(time.Now()).IsZero()).To(BeTrue())
+		})
+	})
+
+	Describe("The cron.Schedule.Prev function", func() {
+		type PrevCase struct {
+			Expr     string
+			From     time.Time
+			Expected time.Time
+		}
+
+		DescribeTable("Correctly returns the previous activation time",
+			func(c PrevCase) {
+				schedule, err := cron.Parse(c.Expr)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(schedule.Prev(c.From)).To(BeTemporally("==", c.Expected))
+			},
+			Entry("When the activation time is earlier the same day", PrevCase{
+				Expr:     "30 8 * * *",
+				From:     time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC),
+				Expected: time.Date(2025, 3, 10, 8, 30, 0, 0, time.UTC),
+			}),
+			Entry("When the activation time coincides with the given one", PrevCase{
+				Expr:     "30 8 * * *",
+				From:     time.Date(2025, 3, 10, 8, 30, 0, 0, time.UTC),
+				Expected: time.Date(2025, 3, 9, 8, 30, 0, 0, time.UTC),
+			}),
+			Entry("When the given time is not aligned to the minute", PrevCase{
+				Expr:     "30 8 * * *",
+				From:     time.Date(2025, 3, 10, 8, 30, 15, 0, time.UTC),
+				Expected: time.Date(2025, 3, 10, 8, 30, 0, 0, time.UTC),
+			}),
+			Entry("When the activation time is restricted to working days", PrevCase{
+				Expr:     "0 8 * * mon-fri",
+				From:     time.Date(2025, 3, 16, 9, 0, 0, 0, time.UTC), // Sunday
+				Expected: time.Date(2025, 3, 14, 8, 0, 0, 0, time.UTC),
+			}),
+		)
+
+		It("Should return the zero time when no activation time exists", func() {
+			schedule, err := cron.Parse("0 0 31 2 *")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule.Prev(time.Now()).IsZero()).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,232 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// manifestMediaTypes are the media types of the manifests accepted when retrieving the digest of an image.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+var (
+	// ErrNotFound is returned in case the requested image does not exist in the registry.
+	ErrNotFound = errors.New("image not found")
+	// ErrDeletionUnsupported is returned in case the registry does not allow deleting images.
+	ErrDeletionUnsupported = errors.New("image deletion not supported by the registry")
+)
+
+// Client interacts with a registry through the Docker Registry HTTP API V2.
+// Both basic and bearer token authentication are supported.
+type Client struct {
+	// The HTTP client used to perform the requests.
+	HTTPClient *http.Client
+	// Whether to contact the registry through plain HTTP rather than HTTPS.
+	Insecure bool
+}
+
+// Digest returns the digest of the manifest identified by the given reference.
+func (c *Client) Digest(ctx context.Context, ref Reference, credentials Credentials) (string, error) {
+	resp, err := c.do(ctx, http.MethodHead, ref, "manifests/"+ref.Tag, credentials)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("failed retrieving the digest of %s: unexpected status %s", ref, resp.Status)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("failed retrieving the digest of %s: header not returned", ref)
+	}
+	return digest, nil
+}
+
+// DeleteImage deletes the manifest identified by the given reference from the registry, and thus all the tags referring to it.
+// The digest of the manifest is retrieved from the registry if not specified. Images already deleted are not considered an error,
+// while ErrDeletionUnsupported is returned in case the registry has been configured to disallow deletions.
+func (c *Client) DeleteImage(ctx context.Context, ref Reference, digest string, credentials Credentials) error {
+	if digest == "" {
+		var err error
+		if digest, err = c.Digest(ctx, ref, credentials); errors.Is(err, ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.do(ctx, http.MethodDelete, ref, "manifests/"+digest, credentials)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNotFound:
+		return nil
+	case http.StatusMethodNotAllowed:
+		return ErrDeletionUnsupported
+	default:
+		return fmt.Errorf("failed deleting %s: unexpected status %s", ref, resp.Status)
+	}
+}
+
+// do performs the given request towards the registry, handling the authentication challenge if necessary.
+func (c *Client) do(ctx context.Context, method string, ref Reference, path string, credentials Credentials) (*http.Response, error) {
+	scheme := "https"
+	if c.Insecure {
+		scheme = "http"
+	}
+	endpoint := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, ref.Host, ref.Repository, path)
+
+	request := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, http.NoBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed creating request: %w", err)
+		}
+		req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		} else if credentials.Username != "" {
+			req.SetBasicAuth(credentials.Username, credentials.Password)
+		}
+
+		resp, err := c.httpClient().Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed contacting registry %s: %w", ref.Host, err)
+		}
+		return resp, nil
+	}
+
+	resp, err := request("")
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// The registry requires a bearer token, which is retrieved according to the received challenge.
+	challenge := resp.Header.Get("WWW-Authenticate")
+	_ = resp.Body.Close()
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, fmt.Errorf("failed authenticating to registry %s: unsupported challenge %q", ref.Host, challenge)
+	}
+
+	token, err := c.token(ctx, parseChallenge(challenge[len("bearer "):]), credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed authenticating to registry %s: %w", ref.Host, err)
+	}
+	return request("Bearer " + token)
+}
+
+// token retrieves a bearer token from the authorization server described by the given challenge parameters.
+func (c *Client) token(ctx context.Context, params map[string]string, credentials Credentials) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid realm %q", params["realm"])
+	}
+
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("failed creating token request: %w", err)
+	}
+	if credentials.Username != "" {
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed requesting token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed requesting token: unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed decoding token: %w", err)
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", errors.New("empty token returned")
+}
+
+// httpClient returns the configured HTTP client, or the default one if not set.
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// parseChallenge parses the comma separated key="value" parameters of an authentication challenge.
+func parseChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	for challenge != "" {
+		key, rest, found := strings.Cut(strings.TrimLeft(challenge, ", "), "=")
+		if !found {
+			break
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+		challenge = rest
+	}
+	return params
+}
@@ -0,0 +1,80 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Credentials are the credentials used to authenticate towards a registry.
+// Empty credentials correspond to anonymous access.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// dockerConfig is the subset of the .dockerconfigjson file format relevant to retrieve the credentials.
+type dockerConfig struct {
+	Auths map[string]struct {
+		Username string `json:"username,omitempty"`
+		Password string `json:"password,omitempty"`
+		Auth     string `json:"auth,omitempty"`
+	} `json:"auths"`
+}
+
+// CredentialsFromDockerConfig extracts the credentials associated with the given registry host from the content
+// of a .dockerconfigjson file (e.g., the one of a kubernetes.io/dockerconfigjson secret). Anonymous credentials
+// are returned in case no entry matches the given host.
+func CredentialsFromDockerConfig(data []byte, host string) (Credentials, error) {
+	var config dockerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Credentials{}, fmt.Errorf("failed parsing docker config: %w", err)
+	}
+
+	for server, auth := range config.Auths {
+		if normalizeHost(server) != normalizeHost(host) {
+			continue
+		}
+
+		if auth.Username != "" || auth.Auth == "" {
+			return Credentials{Username: auth.Username, Password: auth.Password}, nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("failed decoding auth for registry %q: %w", server, err)
+		}
+		username, password, found := strings.Cut(string(decoded), ":")
+		if !found {
+			return Credentials{}, fmt.Errorf("invalid auth format for registry %q", server)
+		}
+		return Credentials{Username: username, Password: password}, nil
+	}
+
+	return Credentials{}, nil
+}
+
+// normalizeHost strips the scheme and the path (if any) from the given registry server.
+func normalizeHost(server string) string {
+	if parsed, err := url.Parse(server); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	host, _, _ := strings.Cut(server, "/")
+	return host
+}
@@ -0,0 +1,61 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+const defaultTag = "latest"
+
+// Reference identifies an image hosted on a given registry.
+type Reference struct {
+	// The host (and optionally the port) of the registry.
+	Host string
+	// The repository of the image, within the registry.
+	Repository string
+	// The tag (or the digest) identifying the image within the repository.
+	Tag string
+}
+
+// ParseReference parses an image reference (e.g., registry.example.com/namespace/image:tag), which is
+// required to explicitly specify the registry host. If the tag is omitted, latest is assumed.
+func ParseReference(ref string) (Reference, error) {
+	host, remainder, found := strings.Cut(ref, "/")
+	if !found || !(strings.ContainsAny(host, ".:") || host == "localhost") {
+		return Reference{}, fmt.Errorf("image reference %q does not specify the registry host", ref)
+	}
+
+	repository, tag := remainder, defaultTag
+	if name, digest, found := strings.Cut(remainder, "@"); found {
+		repository, tag = name, digest
+	} else if idx := strings.LastIndex(remainder, ":"); idx > strings.LastIndex(remainder, "/") {
+		repository, tag = remainder[:idx], remainder[idx+1:]
+	}
+
+	if repository == "" || tag == "" {
+		return Reference{}, fmt.Errorf("image reference %q is not valid", ref)
+	}
+	return Reference{Host: host, Repository: repository, Tag: tag}, nil
+}
+
+// String returns the textual representation of the reference.
+func (r Reference) String() string {
+	if strings.Contains(r.Tag, ":") {
+		return fmt.Sprintf("%s/%s@%s", r.Host, r.Repository, r.Tag)
+	}
+	return fmt.Sprintf("%s/%s:%s", r.Host, r.Repository, r.Tag)
+}
@@ -0,0 +1,210 @@
+// Copyright 2020-2025 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/netgroup-polito/CrownLabs/operators/pkg/utils/registry"
+)
+
+var _ = Describe("Registry", func() {
+
+	Describe("The registry.ParseReference function", func() {
+		DescribeTable("Correctly parses the valid references",
+			func(ref string, expected registry.Reference) {
+				Expect(registry.ParseReference(ref)).To(Equal(expected))
+			},
+			Entry("When the tag is specified", "registry.example.com/tenant/image:v1",
+				registry.Reference{Host: "registry.example.com", Repository: "tenant/image", Tag: "v1"}),
+			Entry("When the tag is omitted", "registry.example.com/image",
+				registry.Reference{Host: "registry.example.com", Repository: "image", Tag: "latest"}),
+			Entry("When the host includes the port", "localhost:5000/tenant/image:20250101t100000",
+				registry.Reference{Host: "localhost:5000", Repository: "tenant/image", Tag: "20250101t100000"}),
+			Entry("When the digest is specified", "registry.example.com/image@sha256:abcd",
+				registry.Reference{Host: "registry.example.com", Repository: "image", Tag: "sha256:abcd"}),
+		)
+
+		DescribeTable("Correctly rejects the invalid references",
+			func(ref string) {
+				_, err := registry.ParseReference(ref)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("When the host is omitted", "tenant/image:v1"),
+			Entry("When only the host is specified", "registry.example.com/"),
+			Entry("When the tag is empty", "registry.example.com/image:"),
+		)
+
+		It("Should format the references back to text", func() {
+			Expect(registry.Reference{Host: "r.io", Repository: "a/b", Tag: "v1"}.String()).To(Equal("r.io/a/b:v1"))
+			Expect(registry.Reference{Host: "r.io", Repository: "a/b", Tag: "sha256:ab"}.String()).To(Equal("r.io/a/b@sha256:ab"))
+		})
+	})
+
+	Describe("The registry.CredentialsFromDockerConfig function", func() {
+		const config = `{"auths": {
+			"https://registry.example.com/v1/": {"username": "john", "password": "secret"},
+			"other.example.com": {"auth": "ZG9lOnBhc3N3b3Jk"}
+		}}`
+
+		DescribeTable("Correctly retrieves the credentials",
+			func(host string, expected registry.Credentials) {
+				Expect(registry.CredentialsFromDockerConfig([]byte(config), host)).To(Equal(expected))
+			},
+			Entry("When the entry specifies username and password", "registry.example.com", registry.Credentials{Username: "john", Password: "secret"}),
+			Entry("When the entry specifies the encoded auth", "other.example.com", registry.Credentials{Username: "doe", Password: "password"}),
+			Entry("When no entry matches the host", "unknown.example.com", registry.Credentials{}),
+		)
+
+		It("Should fail if the configuration is malformed", func() {
+			_, err := registry.CredentialsFromDockerConfig([]byte("{"), "registry.example.com")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("The registry.Client", func() {
+		const (
+			repository = "tenant/image"
+			digest     = "sha256:0123456789"
+		)
+
+		var (
+			ctx         context.Context
+			server      *httptest.Server
+			client      registry.Client
+			ref         registry.Reference
+			credentials registry.Credentials
+			bearer      bool
+			exists      bool
+			deletable   bool
+
+			lock    sync.Mutex
+			deleted []string
+		)
+
+		authorized := func(r *http.Request) bool {
+			if bearer {
+				return r.Header.Get("Authorization") == "Bearer token"
+			}
+			username, password, ok := r.BasicAuth()
+			return ok && username == "john" && password == "secret"
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			credentials = registry.Credentials{Username: "john", Password: "secret"}
+			bearer = false
+			exists = true
+			deletable = true
+			deleted = nil
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				if !ok || username != "john" || password != "secret" || !strings.Contains(r.URL.Query().Get("scope"), repository) {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprint(w, `{"token": "token"}`)
+			})
+			mux.HandleFunc("/v2/"+repository+"/manifests/", func(w http.ResponseWriter, r *http.Request) {
+				if !authorized(r) {
+					if bearer {
+						w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:%s:pull,delete"`, server.URL, repository))
+					}
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				switch {
+				case !exists:
+					w.WriteHeader(http.StatusNotFound)
+				case r.Method == http.MethodHead && strings.HasSuffix(r.URL.Path, "/v1"):
+					w.Header().Set("Docker-Content-Digest", digest)
+				case r.Method == http.MethodDelete && !deletable:
+					w.WriteHeader(http.StatusMethodNotAllowed)
+				case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/"+digest):
+					lock.Lock()
+					deleted = append(deleted, digest)
+					lock.Unlock()
+					w.WriteHeader(http.StatusAccepted)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			server = httptest.NewServer(mux)
+			DeferCleanup(server.Close)
+
+			client = registry.Client{HTTPClient: server.Client(), Insecure: true}
+			ref = registry.Reference{Host: strings.TrimPrefix(server.URL, "http://"), Repository: repository, Tag: "v1"}
+		})
+
+		When("basic authentication is used", func() {
+			It("Should retrieve the digest of the image", func() {
+				Expect(client.Digest(ctx, ref, credentials)).To(Equal(digest))
+			})
+
+			It("Should delete the image", func() {
+				Expect(client.DeleteImage(ctx, ref, "", credentials)).To(Succeed())
+				Expect(deleted).To(ConsistOf(digest))
+			})
+
+			It("Should fail with invalid credentials", func() {
+				Expect(client.DeleteImage(ctx, ref, "", registry.Credentials{Username: "john"})).ToNot(Succeed())
+				Expect(deleted).To(BeEmpty())
+			})
+		})
+
+		When("bearer token authentication is used", func() {
+			BeforeEach(func() { bearer = true })
+
+			It("Should delete the image given its digest", func() {
+				Expect(client.DeleteImage(ctx, ref, digest, credentials)).To(Succeed())
+				Expect(deleted).To(ConsistOf(digest))
+			})
+		})
+
+		When("the image does not exist", func() {
+			BeforeEach(func() { exists = false })
+
+			It("Should report the image as not found", func() {
+				_, err := client.Digest(ctx, ref, credentials)
+				Expect(err).To(MatchError(registry.ErrNotFound))
+			})
+
+			It("Should not return an error upon deletion", func() {
+				Expect(client.DeleteImage(ctx, ref, "", credentials)).To(Succeed())
+			})
+		})
+
+		When("the registry does not allow deletions", func() {
+			BeforeEach(func() { deletable = false })
+
+			It("Should report the deletion as unsupported", func() {
+				Expect(client.DeleteImage(ctx, ref, digest, credentials)).To(MatchError(registry.ErrDeletionUnsupported))
+				Expect(deleted).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,57 @@
+// Copyright 2020-2024 Politecnico di Torino
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ActivityInfo summarizes the usage of the remote desktop, to allow detecting idle instances.
+type ActivityInfo struct {
+	ActiveConnections int       `json:"activeConnections"`
+	LastActivity      time.Time `json:"lastActivity,omitempty"`
+}
+
+// ActivityHandler exposes the activity information derived from the tracked connections.
+type ActivityHandler struct {
+	// <uid, ConnInfo>
+	connectionsTracking *sync.Map
+}
+
+// ServeHTTP returns the number of active connections and the last time a connection was active.
+func (h *ActivityHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	now := time.Now()
+	info := ActivityInfo{}
+
+	h.connectionsTracking.Range(func(_, v interface{}) bool {
+		connInfo := v.(ConnInfo)
+		if connInfo.Active {
+			info.ActiveConnections++
+			info.LastActivity = now
+		} else if connInfo.DisconnTime.After(info.LastActivity) {
+			info.LastActivity = connInfo.DisconnTime
+		}
+		return true
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		log.Println("activity write error:", err)
+	}
+}
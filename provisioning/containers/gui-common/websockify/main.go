@@ -64,12 +64,12 @@ func main() {
 		log.Println("Instance metrics will not be available")
 	}
 
-	go runMetricsServer(*metricsAddr, "/metrics")
+	// SyncMap shared between NoVncHandler, InstanceMetricsHandler and ActivityHandler
+	var connectionsTracking sync.Map
 
-	log.Printf("Websockify listening on %s%s", *httpAddr, *basePath)
+	go runMetricsServer(*metricsAddr, "/metrics", "/activity", &ActivityHandler{connectionsTracking: &connectionsTracking})
 
-	// SyncMap shared between NoVncHandler and InstanceMetricsHandler
-	var connectionsTracking sync.Map
+	log.Printf("Websockify listening on %s%s", *httpAddr, *basePath)
 
 	mux := http.NewServeMux()
 
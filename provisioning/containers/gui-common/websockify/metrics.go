@@ -35,9 +35,10 @@ var (
 	)
 )
 
-func runMetricsServer(addr, metricsEndpoint string) {
+func runMetricsServer(addr, metricsEndpoint, activityEndpoint string, activityHandler http.Handler) {
 	mux := http.NewServeMux()
 	mux.Handle(metricsEndpoint, promhttp.Handler())
+	mux.Handle(activityEndpoint, activityHandler)
 
 	server := &http.Server{
 		Addr:              addr,